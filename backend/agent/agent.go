@@ -35,6 +35,7 @@ import (
 	bind9config "isc.org/stork/daemoncfg/bind9"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/daemondata/bind9xfr"
+	keadata "isc.org/stork/daemondata/kea"
 	dnsmodel "isc.org/stork/datamodel/dns"
 	"isc.org/stork/pki"
 	storkutil "isc.org/stork/util"
@@ -589,6 +590,28 @@ func (sa *StorkAgent) GetPowerDNSServerInfo(ctx context.Context, req *agentapi.G
 	return rsp, nil
 }
 
+// Returns the configuration of the PowerDNS server with the secrets
+// redacted. It is the configuration parsed by the agent when the server was
+// detected.
+func (sa *StorkAgent) GetPowerDNSConfig(ctx context.Context, req *agentapi.GetPowerDNSConfigReq) (*agentapi.GetPowerDNSConfigRsp, error) {
+	daemon := sa.Monitor.GetDaemonByAccessPoint(AccessPointControl, req.WebserverAddress, req.WebserverPort)
+	if daemon == nil {
+		return nil, status.Newf(codes.FailedPrecondition, "PowerDNS server %s:%d not found", req.WebserverAddress, req.WebserverPort).Err()
+	}
+	pdnsDaemon, ok := daemon.(*pdnsDaemon)
+	if !ok {
+		return nil, status.Newf(codes.InvalidArgument, "attempted to get PowerDNS configuration from daemon %s instead of PowerDNS", daemon.GetName()).Err()
+	}
+	if pdnsDaemon.config == nil {
+		return nil, status.Newf(codes.NotFound, "PowerDNS configuration not found for server %s:%d", req.WebserverAddress, req.WebserverPort).Err()
+	}
+	rsp := &agentapi.GetPowerDNSConfigRsp{
+		ConfigPath: pdnsDaemon.getDetectedFiles().getFirstFilePathByType(detectedFileTypeConfig),
+		Config:     pdnsDaemon.config.GetRedactedText(),
+	}
+	return rsp, nil
+}
+
 // Forwards one or more Kea commands sent by the Stork Server to the appropriate Kea instance over
 // HTTP (via Control Agent).
 func (sa *StorkAgent) ForwardToKeaOverHTTP(ctx context.Context, in *agentapi.ForwardToKeaOverHTTPReq) (*agentapi.ForwardToKeaOverHTTPRsp, error) {
@@ -1018,6 +1041,91 @@ func (sa *StorkAgent) ReceiveKeaLeases(req *agentapi.ReceiveKeaLeasesReq, server
 	return nil
 }
 
+// Stream the lease lifecycle events observed by the agent for the specific
+// requested Kea daemon after the specified time.
+func (sa *StorkAgent) ReceiveKeaLeaseEvents(req *agentapi.ReceiveKeaLeaseEventsReq, server grpc.ServerStreamingServer[agentapi.ReceiveKeaLeaseEventsRsp]) error {
+	if !sa.isLeaseTrackingAllowed {
+		return errors.New("This feature is not enabled. Pass `--enable-lease-tracking` or set `STORK_AGENT_ENABLE_LEASE_TRACKING` when starting the agent.")
+	}
+	daemon := sa.Monitor.GetDaemonByAccessPoint(AccessPointControl, req.ControlAddress, req.ControlPort)
+	if daemon == nil {
+		return status.Newf(codes.FailedPrecondition, "Kea server %s:%d not found", req.ControlAddress, req.ControlPort).Err()
+	}
+	keadaemon, ok := daemon.(*keaDaemon)
+	if !ok {
+		return status.Newf(codes.InvalidArgument, "attempted to get lease events from daemon %s instead of Kea", daemon.GetName()).Err()
+	}
+	events, err := keadaemon.GetLeaseEvents(req.MinObservedAt)
+	if err != nil {
+		log.WithError(err).
+			WithField("daemon", daemon.String()).
+			Error("unable to get lease events from daemon")
+		return status.New(codes.Internal, "unable to get lease events from daemon").Err()
+	}
+	for _, event := range events {
+		err := server.Send(&agentapi.ReceiveKeaLeaseEventsRsp{
+			Event: event.ToGRPC(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the differences between the running configuration of the Kea
+// server and its configuration file found during the last state refresh.
+func (sa *StorkAgent) GetKeaConfigDrift(ctx context.Context, req *agentapi.GetKeaConfigDriftReq) (*agentapi.GetKeaConfigDriftRsp, error) {
+	daemon := sa.Monitor.GetDaemonByAccessPoint(AccessPointControl, req.ControlAddress, req.ControlPort)
+	if daemon == nil {
+		return nil, status.Newf(codes.FailedPrecondition, "Kea server %s:%d not found", req.ControlAddress, req.ControlPort).Err()
+	}
+	keadaemon, ok := daemon.(*keaDaemon)
+	if !ok {
+		return nil, status.Newf(codes.InvalidArgument, "attempted to get configuration drift of daemon %s instead of Kea", daemon.GetName()).Err()
+	}
+	rsp, err := newGetKeaConfigDriftRsp(keadaemon.getConfigDrift())
+	if err != nil {
+		log.WithError(err).WithField("daemon", daemon.String()).
+			Error("Cannot serialize the configuration drift")
+		return nil, status.New(codes.Internal, "cannot serialize the configuration drift").Err()
+	}
+	return rsp, nil
+}
+
+// Searches the forensic (legal) log files written by the Kea server and
+// streams the matching entries.
+func (sa *StorkAgent) SearchKeaLegalLog(req *agentapi.SearchKeaLegalLogReq, server grpc.ServerStreamingServer[agentapi.SearchKeaLegalLogRsp]) error {
+	daemon := sa.Monitor.GetDaemonByAccessPoint(AccessPointControl, req.ControlAddress, req.ControlPort)
+	if daemon == nil {
+		return status.Newf(codes.FailedPrecondition, "Kea server %s:%d not found", req.ControlAddress, req.ControlPort).Err()
+	}
+	keadaemon, ok := daemon.(*keaDaemon)
+	if !ok {
+		return status.Newf(codes.InvalidArgument, "attempted to search legal log of daemon %s instead of Kea", daemon.GetName()).Err()
+	}
+	filter, err := newLegalLogFilter(req)
+	if err != nil {
+		return status.New(codes.InvalidArgument, err.Error()).Err()
+	}
+	pattern, err := keadaemon.getLegalLogFilePattern()
+	if err != nil {
+		return status.New(codes.FailedPrecondition, err.Error()).Err()
+	}
+	err = searchLegalLogFiles(server.Context(), pattern, filter, req.Limit, func(entry *keadata.LegalLogEntry) error {
+		return server.Send(&agentapi.SearchKeaLegalLogRsp{
+			Entry: entry.ToGRPC(),
+		})
+	})
+	if err != nil {
+		log.WithError(err).
+			WithField("daemon", daemon.String()).
+			Error("Unable to search the legal log of the daemon")
+		return status.New(codes.Internal, "unable to search the legal log of the daemon").Err()
+	}
+	return nil
+}
+
 // Converts the zone transfer state to the gRPC message. It is extracted into
 // a separate function of unit testing convenience.
 func convertZoneTransferStateToAPI(state bind9xfr.State) *agentapi.ZoneTransfer {
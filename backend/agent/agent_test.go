@@ -12,6 +12,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
@@ -31,6 +32,8 @@ import (
 	"isc.org/stork"
 	agentapi "isc.org/stork/api"
 	bind9config "isc.org/stork/daemoncfg/bind9"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
 	"isc.org/stork/daemondata/bind9xfr"
 	keadata "isc.org/stork/daemondata/kea"
 	pdnsdata "isc.org/stork/daemondata/pdns"
@@ -2479,6 +2482,64 @@ func TestGetPowerDNSServerInfoNoAPIKey(t *testing.T) {
 	require.Equal(t, "API_KEY_NOT_CONFIGURED", info.Reason)
 }
 
+// Test getting the PowerDNS server configuration with the secrets redacted.
+func TestGetPowerDNSConfig(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	_, err := sa.GetPowerDNSConfig(context.Background(), &agentapi.GetPowerDNSConfigReq{
+		WebserverAddress: "localhost",
+		WebserverPort:    1234,
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	executor := newTestCommandExecutor().
+		addFileInfo("/etc/powerdns/pdns.conf", &testFileInfo{})
+	detectedFiles := newDetectedDaemonFiles("")
+	err = detectedFiles.addFile(detectedFileTypeConfig, "/etc/powerdns/pdns.conf", executor)
+	require.NoError(t, err)
+
+	config, err := pdnsconfig.NewParser().Parse("pdns.conf", strings.NewReader("api=yes\napi-key=stork\nwebserver=yes\n"))
+	require.NoError(t, err)
+
+	daemon := &pdnsDaemon{
+		dnsDaemonImpl: dnsDaemonImpl{
+			daemon: daemon{
+				Name: daemonname.PDNS,
+				AccessPoints: []AccessPoint{{
+					Type:     AccessPointControl,
+					Address:  "localhost",
+					Port:     1234,
+					Key:      "stork",
+					Protocol: protocoltype.HTTP,
+				}},
+			},
+			detectedFiles: detectedFiles,
+		},
+	}
+	fdm, _ := sa.Monitor.(*FakeMonitor)
+	fdm.Daemons = []Daemon{daemon}
+
+	// The configuration is not available.
+	_, err = sa.GetPowerDNSConfig(context.Background(), &agentapi.GetPowerDNSConfigReq{
+		WebserverAddress: "localhost",
+		WebserverPort:    1234,
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	daemon.config = config
+	rsp, err := sa.GetPowerDNSConfig(context.Background(), &agentapi.GetPowerDNSConfigReq{
+		WebserverAddress: "localhost",
+		WebserverPort:    1234,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, rsp)
+	require.Equal(t, "/etc/powerdns/pdns.conf", rsp.ConfigPath)
+	require.Equal(t, "api=yes\napi-key=********\nwebserver=yes\n", rsp.Config)
+}
+
 // Test that the correct error is returned when the PowerDNS server
 // returns an error response.
 func TestGetPowerDNSServerInfoErrorResponse(t *testing.T) {
@@ -3227,6 +3288,174 @@ func TestReceiveKeaLeasesSnapshotFnReturnsError(t *testing.T) {
 	require.ErrorContains(t, errNilSnooper, "unable to get lease snapshot")
 }
 
+// Test that ReceiveKeaLeaseEvents streams the lease events observed by the
+// MemfileSnooper after the specified time.
+func TestReceiveKeaLeaseEvents(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	sa.isLeaseTrackingAllowed = true
+	defer teardown()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	snooper := NewMockMemfileSnooper(ctrl)
+	snooper.EXPECT().GetLeaseEvents(int64(100)).Return([]*keadata.LeaseEvent{
+		{
+			Type:       keadata.LeaseEventAssigned,
+			Lease:      keadata.NewLease4("192.0.2.1", "01:02:03:04:05:06", "", 1000, 3600, 1, false, false, "", 0, nil),
+			OccurredAt: 1000,
+			ObservedAt: 101,
+		},
+	})
+	daemon := &keaDaemon{
+		daemon: daemon{
+			Name: daemonname.DHCPv4,
+			AccessPoints: []AccessPoint{
+				{Address: "127.0.0.1", Port: 8080, Type: AccessPointControl},
+			},
+		},
+		snooper: snooper,
+	}
+	fdm, _ := sa.Monitor.(*FakeMonitor)
+	fdm.Daemons = []Daemon{daemon}
+
+	sss := NewMockServerStreamingServer[agentapi.ReceiveKeaLeaseEventsRsp](ctrl)
+	var received []*agentapi.LeaseEvent
+	sss.EXPECT().Send(gomock.Any()).AnyTimes().DoAndReturn(func(rsp *agentapi.ReceiveKeaLeaseEventsRsp) error {
+		received = append(received, rsp.Event)
+		return nil
+	})
+
+	err := sa.ReceiveKeaLeaseEvents(&agentapi.ReceiveKeaLeaseEventsReq{
+		MinObservedAt:  100,
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+	}, sss)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, agentapi.LeaseEvent_ASSIGNED, received[0].Type)
+	require.Equal(t, "192.0.2.1", received[0].Lease.IpAddress)
+	require.EqualValues(t, 101, received[0].ObservedAt)
+}
+
+// Test that ReceiveKeaLeaseEvents returns an error when lease tracking is not
+// enabled or the daemon has no snooper.
+func TestReceiveKeaLeaseEventsErrors(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+	err := sa.ReceiveKeaLeaseEvents(&agentapi.ReceiveKeaLeaseEventsReq{}, nil)
+	require.ErrorContains(t, err, "STORK_AGENT_ENABLE_LEASE_TRACKING")
+
+	sa.isLeaseTrackingAllowed = true
+	err = sa.ReceiveKeaLeaseEvents(&agentapi.ReceiveKeaLeaseEventsReq{
+		ControlAddress: "203.0.113.1",
+		ControlPort:    9001,
+	}, nil)
+	require.ErrorContains(t, err, "not found")
+
+	daemon := &keaDaemon{
+		daemon: daemon{
+			Name: daemonname.DHCPv4,
+			AccessPoints: []AccessPoint{
+				{Address: "127.0.0.1", Port: 8080, Type: AccessPointControl},
+			},
+		},
+	}
+	fdm, _ := sa.Monitor.(*FakeMonitor)
+	fdm.Daemons = []Daemon{daemon}
+	err = sa.ReceiveKeaLeaseEvents(&agentapi.ReceiveKeaLeaseEventsReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+	}, nil)
+	require.ErrorContains(t, err, "unable to get lease events")
+}
+
+// Test searching the legal log of the Kea server over gRPC.
+func TestSearchKeaLegalLog(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	directory := t.TempDir()
+	err := os.WriteFile(filepath.Join(directory, "kea-legal.20250101.txt"), []byte(
+		"2025-01-01 10:00:00 UTC Address: 192.0.2.1 has been assigned for 1 hrs 0 mins 0 secs to a device with hardware address: hwtype=1 01:02:03:04:05:06\n"+
+			"2025-01-01 10:05:00 UTC Address: 192.0.2.2 has been assigned for 1 hrs 0 mins 0 secs to a device with hardware address: hwtype=1 0a:0b:0c:0d:0e:0f\n",
+	), 0o600)
+	require.NoError(t, err)
+
+	daemon := &keaDaemon{
+		daemon: daemon{
+			Name: daemonname.DHCPv4,
+			AccessPoints: []AccessPoint{
+				{Address: "127.0.0.1", Port: 8080, Type: AccessPointControl},
+			},
+		},
+		legalLog: &keaconfig.LegalLogHookParams{
+			Database: keaconfig.Database{Path: directory},
+		},
+	}
+	fdm, _ := sa.Monitor.(*FakeMonitor)
+	fdm.Daemons = []Daemon{daemon}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sss := NewMockServerStreamingServer[agentapi.SearchKeaLegalLogRsp](ctrl)
+	sss.EXPECT().Context().AnyTimes().Return(t.Context())
+	var received []*agentapi.LegalLogEntry
+	sss.EXPECT().Send(gomock.Any()).AnyTimes().DoAndReturn(func(rsp *agentapi.SearchKeaLegalLogRsp) error {
+		received = append(received, rsp.Entry)
+		return nil
+	})
+
+	err = sa.SearchKeaLegalLog(&agentapi.SearchKeaLegalLogReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+		HwAddress:      "0a0b0c0d0e0f",
+	}, sss)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, "192.0.2.2", received[0].Address)
+	require.Equal(t, "assigned", received[0].Action)
+	require.EqualValues(t, 3600, received[0].Duration)
+}
+
+// Test that SearchKeaLegalLog returns an error when the daemon is not
+// found, the filter is invalid or the legal log is not configured.
+func TestSearchKeaLegalLogErrors(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	err := sa.SearchKeaLegalLog(&agentapi.SearchKeaLegalLogReq{
+		ControlAddress: "203.0.113.1",
+		ControlPort:    9001,
+	}, nil)
+	require.ErrorContains(t, err, "not found")
+
+	daemon := &keaDaemon{
+		daemon: daemon{
+			Name: daemonname.DHCPv4,
+			AccessPoints: []AccessPoint{
+				{Address: "127.0.0.1", Port: 8080, Type: AccessPointControl},
+			},
+		},
+	}
+	fdm, _ := sa.Monitor.(*FakeMonitor)
+	fdm.Daemons = []Daemon{daemon}
+
+	err = sa.SearchKeaLegalLog(&agentapi.SearchKeaLegalLogReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+		Address:        "foo",
+	}, nil)
+	require.ErrorContains(t, err, "invalid IP address")
+
+	err = sa.SearchKeaLegalLog(&agentapi.SearchKeaLegalLogReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+	}, nil)
+	require.ErrorContains(t, err, "hook library is not loaded")
+}
+
 // Test that a request to receive zone transfers over gRPC can be cancelled.
 func TestReceiveZoneTransfersCancelContext(t *testing.T) {
 	sa, _, teardown := setupAgentTest()
@@ -3886,3 +4115,67 @@ func TestReceiveZoneTransferDataUnspecifiedTimeAndDuration(t *testing.T) {
 	require.Zero(t, converted.StartTime)
 	require.Zero(t, converted.CompletionTime)
 }
+
+// Test that the configuration drift found by the agent is returned to the
+// server.
+func TestGetKeaConfigDrift(t *testing.T) {
+	sa, _, teardown := setupAgentTest()
+	defer teardown()
+
+	_, err := sa.GetKeaConfigDrift(context.Background(), &agentapi.GetKeaConfigDriftReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+	})
+	require.ErrorContains(t, err, "not found")
+
+	daemon := &keaDaemon{
+		daemon: daemon{
+			Name: daemonname.DHCPv4,
+			AccessPoints: []AccessPoint{
+				{Address: "127.0.0.1", Port: 8080, Type: AccessPointControl},
+			},
+		},
+	}
+	fdm, _ := sa.Monitor.(*FakeMonitor)
+	fdm.Daemons = []Daemon{daemon}
+
+	// The configurations have not been compared yet.
+	rsp, err := sa.GetKeaConfigDrift(context.Background(), &agentapi.GetKeaConfigDriftReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+	})
+	require.NoError(t, err)
+	require.False(t, rsp.Checked)
+
+	daemon.configDrift = &keaconfig.ConfigDrift{
+		ConfigPath: "/etc/kea/kea-dhcp4.conf",
+		CheckedAt:  time.Unix(1700000000, 0),
+		Entries: []keaconfig.ConfigDiffEntry{
+			{
+				Path:      "/Dhcp4/valid-lifetime",
+				Operation: keaconfig.ConfigDiffOperationModified,
+				OldValue:  3600,
+				NewValue:  7200,
+			},
+			{
+				Path:      "/Dhcp4/subnet4[id=1]",
+				Operation: keaconfig.ConfigDiffOperationAdded,
+				NewValue:  map[string]any{"id": 1},
+			},
+		},
+	}
+	rsp, err = sa.GetKeaConfigDrift(context.Background(), &agentapi.GetKeaConfigDriftReq{
+		ControlAddress: "127.0.0.1",
+		ControlPort:    8080,
+	})
+	require.NoError(t, err)
+	require.True(t, rsp.Checked)
+	require.Equal(t, "/etc/kea/kea-dhcp4.conf", rsp.ConfigPath)
+	require.EqualValues(t, 1700000000, rsp.CheckedAt)
+	require.Len(t, rsp.Entries, 2)
+	require.Equal(t, "modified", rsp.Entries[0].Operation)
+	require.Equal(t, "3600", rsp.Entries[0].FileValue)
+	require.Equal(t, "7200", rsp.Entries[0].RunningValue)
+	require.Empty(t, rsp.Entries[1].FileValue)
+	require.JSONEq(t, `{"id": 1}`, rsp.Entries[1].RunningValue)
+}
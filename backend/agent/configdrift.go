@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"encoding/json"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	agentapi "isc.org/stork/api"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+)
+
+// Compares the configuration file of the daemon with the running
+// configuration fetched with the config-get command and remembers the
+// differences. The file is read with the includes resolved. The drift is
+// cleared if the file cannot be read, so the server doesn't report the
+// outdated differences.
+func (d *keaDaemon) refreshConfigDrift(runningConfig *keaconfig.Config) {
+	if d.configPath == "" {
+		return
+	}
+	var drift *keaconfig.ConfigDrift
+	fileConfig, err := readKeaConfig(d.configPath)
+	if err == nil {
+		drift, err = keaconfig.DiffConfigDrift(d.configPath, fileConfig, runningConfig)
+	}
+	if err != nil {
+		log.WithError(err).WithField("daemon", d.String()).
+			Warn("Cannot compare the running Kea configuration with the configuration file")
+		drift = nil
+	} else {
+		drift.CheckedAt = time.Now().UTC()
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.configDrift = drift
+}
+
+// Returns the differences between the running configuration and the
+// configuration file found during the last state refresh, or nil if the
+// configurations have not been compared.
+func (d *keaDaemon) getConfigDrift() *keaconfig.ConfigDrift {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.configDrift
+}
+
+// Converts the configuration drift to the gRPC response.
+func newGetKeaConfigDriftRsp(drift *keaconfig.ConfigDrift) (*agentapi.GetKeaConfigDriftRsp, error) {
+	rsp := &agentapi.GetKeaConfigDriftRsp{}
+	if drift == nil {
+		return rsp, nil
+	}
+	rsp.Checked = true
+	rsp.ConfigPath = drift.ConfigPath
+	rsp.CheckedAt = drift.CheckedAt.Unix()
+	for _, entry := range drift.Entries {
+		grpcEntry := &agentapi.KeaConfigDriftEntry{
+			Path:      entry.Path,
+			Operation: string(entry.Operation),
+		}
+		if entry.OldValue != nil {
+			value, err := json.Marshal(entry.OldValue)
+			if err != nil {
+				return nil, err
+			}
+			grpcEntry.FileValue = string(value)
+		}
+		if entry.NewValue != nil {
+			value, err := json.Marshal(entry.NewValue)
+			if err != nil {
+				return nil, err
+			}
+			grpcEntry.RunningValue = string(value)
+		}
+		rsp.Entries = append(rsp.Entries, grpcEntry)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+)
+
+// Test that the differences between the configuration file and the running
+// configuration are found and cleared when the file cannot be read.
+func TestRefreshConfigDrift(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kea-dhcp4.conf")
+	err := os.WriteFile(configPath, []byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 3600
+		}
+	}`), 0o600)
+	require.NoError(t, err)
+
+	daemon := &keaDaemon{
+		daemon:     daemon{Name: daemonname.DHCPv4},
+		configPath: configPath,
+	}
+	require.Nil(t, daemon.getConfigDrift())
+
+	runningConfig, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 7200
+		}
+	}`))
+	require.NoError(t, err)
+
+	daemon.refreshConfigDrift(runningConfig)
+	drift := daemon.getConfigDrift()
+	require.NotNil(t, drift)
+	require.Equal(t, configPath, drift.ConfigPath)
+	require.False(t, drift.CheckedAt.IsZero())
+	require.Len(t, drift.Entries, 1)
+	require.Equal(t, "/Dhcp4/valid-lifetime", drift.Entries[0].Path)
+
+	// The outdated drift must not be reported when the file is gone.
+	err = os.Remove(configPath)
+	require.NoError(t, err)
+	daemon.refreshConfigDrift(runningConfig)
+	require.Nil(t, daemon.getConfigDrift())
+}
+
+// Test that the configuration drift is not checked when the configuration
+// file is unknown.
+func TestRefreshConfigDriftNoConfigPath(t *testing.T) {
+	daemon := &keaDaemon{
+		daemon: daemon{Name: daemonname.DHCPv4},
+	}
+	runningConfig, err := keaconfig.NewConfig([]byte(`{ "Dhcp4": { } }`))
+	require.NoError(t, err)
+
+	daemon.refreshConfigDrift(runningConfig)
+	require.Nil(t, daemon.getConfigDrift())
+}
@@ -11,6 +11,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -30,6 +31,19 @@ type keaDaemon struct {
 	daemon
 	connector keaConnector // to communicate with Kea daemon
 	snooper   MemfileSnooper
+	// Legal logging hook library configuration or nil if the hook
+	// library is not loaded. It is protected by the mutex because it
+	// is updated when the state is refreshed and read by the gRPC calls.
+	legalLog *keaconfig.LegalLogHookParams
+	// Path to the configuration file. It is empty if the daemon was
+	// detected via the Kea Control Agent and its configuration file is
+	// unknown.
+	configPath string
+	// Differences between the running configuration and the configuration
+	// file found during the last state refresh. It is nil if the
+	// configurations could not be compared. It is protected by the mutex.
+	configDrift *keaconfig.ConfigDrift
+	mutex       sync.RWMutex
 }
 
 // Interface to a Kea command that allows overriding the daemon list.
@@ -437,7 +451,8 @@ func (sm *monitor) detectKeaDaemons(ctx context.Context, p supportedProcess) ([]
 			Name:         daemonName,
 			AccessPoints: accessPoints,
 		},
-		connector: newMultiConnector(accessPoints, httpClientConfigs),
+		connector:  newMultiConnector(accessPoints, httpClientConfigs),
+		configPath: configPath,
 	}
 
 	detectedDaemons := []Daemon{thisDaemon}
@@ -585,6 +600,8 @@ func (d *keaDaemon) RefreshState(ctx context.Context, agent agentManager) error
 		return errors.WithMessage(err, "cannot fetch Kea configuration")
 	}
 	paths := collectKeaAllowedLogs(config)
+	d.setLegalLogConfig(config)
+	d.refreshConfigDrift(config)
 	allowed, maxLeaseUpdates := agent.allowLeaseTracking()
 	if allowed {
 		err = d.ensureWatchingLeasefile(ctx, config, maxLeaseUpdates)
@@ -599,6 +616,36 @@ func (d *keaDaemon) RefreshState(ctx context.Context, agent agentManager) error
 	return nil
 }
 
+// Remembers the legal logging hook library configuration, so the legal
+// log files can be searched.
+func (d *keaDaemon) setLegalLogConfig(config *keaconfig.Config) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.legalLog = nil
+	if _, params, ok := config.GetHookLibraries().GetLegalLogHookLibrary(); ok {
+		d.legalLog = &params
+	}
+}
+
+// Returns the glob pattern matching the legal log files written by the
+// daemon. It returns an error if the daemon does not write the legal
+// log to the files that the agent can locate.
+func (d *keaDaemon) getLegalLogFilePattern() (string, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.legalLog == nil {
+		return "", errors.New("the legal log hook library is not loaded")
+	}
+	if !d.legalLog.IsLoggingToFile() {
+		return "", errors.Errorf("the legal log is not written to files but to %s", d.legalLog.Type)
+	}
+	pattern, ok := d.legalLog.GetFilePattern()
+	if !ok {
+		return "", errors.Errorf("the legal log path %q is not absolute; configure an absolute path to search the legal log", d.legalLog.Path)
+	}
+	return pattern, nil
+}
+
 // getLeasefileConfigSettings retreives all the pieces of configuration about the
 // lease memfile from the Kea daemon's config.
 func getLeasefileConfigSettings(config *keaconfig.Config) (leaseDBType string, persist bool, configPath string) {
@@ -692,6 +739,15 @@ func (d *keaDaemon) GetLeaseSnapshot() ([]*keadata.Lease, error) {
 	return nil, errors.New("cannot provide lease snapshot from a daemon with no lease snooper configured")
 }
 
+// Get the lease lifecycle events observed after the specified Unix time
+// (nanoseconds) for this daemon.
+func (d *keaDaemon) GetLeaseEvents(minObservedAt int64) ([]*keadata.LeaseEvent, error) {
+	if d.snooper != nil {
+		return d.snooper.GetLeaseEvents(minObservedAt), nil
+	}
+	return nil, errors.New("cannot provide lease events from a daemon with no lease snooper configured")
+}
+
 // Called once before the daemon is removed.
 func (d *keaDaemon) Cleanup() error {
 	if d.snooper != nil {
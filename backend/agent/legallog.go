@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	agentapi "isc.org/stork/api"
+	keadata "isc.org/stork/daemondata/kea"
+	storkutil "isc.org/stork/util"
+)
+
+// Maximum length of a single legal log entry.
+const legalLogMaxLineLength = 1024 * 1024
+
+// Filter selecting the legal log entries. The entries must match all
+// specified criteria. The unspecified criteria match all entries.
+type legalLogFilter struct {
+	address   *storkutil.ParsedIP
+	hwAddress []byte
+	duid      []byte
+	from      time.Time
+	to        time.Time
+}
+
+// Creates the legal log filter from the gRPC request. It returns an
+// error if any of the specified criteria is invalid.
+func newLegalLogFilter(req *agentapi.SearchKeaLegalLogReq) (*legalLogFilter, error) {
+	filter := &legalLogFilter{}
+	if req.Address != "" {
+		filter.address = storkutil.ParseIP(req.Address)
+		if filter.address == nil {
+			return nil, errors.Errorf("invalid IP address or prefix %s", req.Address)
+		}
+	}
+	if req.HwAddress != "" {
+		if !storkutil.IsHexIdentifier(req.HwAddress) {
+			return nil, errors.Errorf("invalid hardware address %s", req.HwAddress)
+		}
+		filter.hwAddress = storkutil.HexToBytes(req.HwAddress)
+	}
+	if req.Duid != "" {
+		if !storkutil.IsHexIdentifier(req.Duid) {
+			return nil, errors.Errorf("invalid DUID %s", req.Duid)
+		}
+		filter.duid = storkutil.HexToBytes(req.Duid)
+	}
+	if req.From != 0 {
+		filter.from = time.Unix(req.From, 0)
+	}
+	if req.To != 0 {
+		filter.to = time.Unix(req.To, 0)
+	}
+	return filter, nil
+}
+
+// Checks if the legal log entry matches the filter. The entries without
+// the timestamp do not match the filter limiting the time range.
+func (filter *legalLogFilter) matches(entry *keadata.LegalLogEntry) bool {
+	if filter.address != nil {
+		address := storkutil.ParseIP(entry.Address)
+		if address == nil || !address.IP.Equal(filter.address.IP) {
+			return false
+		}
+		// Compare the prefix lengths if the delegated prefix was specified.
+		if filter.address.CIDR && address.PrefixLength != filter.address.PrefixLength {
+			return false
+		}
+	}
+	if filter.hwAddress != nil && !bytes.Equal(filter.hwAddress, storkutil.HexToBytes(entry.HWAddress)) {
+		return false
+	}
+	if filter.duid != nil && !bytes.Equal(filter.duid, storkutil.HexToBytes(entry.DUID)) {
+		return false
+	}
+	if !filter.from.IsZero() && (entry.Timestamp.IsZero() || entry.Timestamp.Before(filter.from)) {
+		return false
+	}
+	if !filter.to.IsZero() && (entry.Timestamp.IsZero() || entry.Timestamp.After(filter.to)) {
+		return false
+	}
+	return true
+}
+
+// A legal log file with the time when the logging to this file began.
+type legalLogFile struct {
+	path  string
+	start time.Time
+}
+
+// Returns the time when the logging to the legal log file began. Kea
+// includes the date (CCYYMMDD) or the Unix timestamp prefixed with T in
+// the names of the legal log files. It returns zero time if the file name
+// does not contain any of them.
+func getLegalLogFileStart(path string) time.Time {
+	name := strings.TrimSuffix(filepath.Base(path), ".txt")
+	suffix := name[strings.LastIndex(name, ".")+1:]
+	if seconds, found := strings.CutPrefix(suffix, "T"); found {
+		if timestamp, err := strconv.ParseInt(seconds, 10, 64); err == nil {
+			return time.Unix(timestamp, 0)
+		}
+		return time.Time{}
+	}
+	if date, err := time.ParseInLocation("20060102", suffix, time.Local); err == nil {
+		return date
+	}
+	return time.Time{}
+}
+
+// Returns the legal log files matching the pattern that may contain the
+// entries in the time range of the filter. The files are ordered from the
+// oldest to the newest.
+func findLegalLogFiles(pattern string, filter *legalLogFilter) ([]string, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid legal log file pattern %s", pattern)
+	}
+	files := make([]legalLogFile, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, legalLogFile{path: path, start: getLegalLogFileStart(path)})
+	}
+	// The files without the recognized start time are searched last.
+	slices.SortFunc(files, func(a, b legalLogFile) int {
+		switch {
+		case a.start.IsZero() != b.start.IsZero():
+			if a.start.IsZero() {
+				return 1
+			}
+			return -1
+		case !a.start.Equal(b.start):
+			return a.start.Compare(b.start)
+		default:
+			return strings.Compare(a.path, b.path)
+		}
+	})
+	var selected []string
+	for i, file := range files {
+		if !file.start.IsZero() {
+			// Skip the files started after the end of the time range.
+			if !filter.to.IsZero() && file.start.After(filter.to) {
+				continue
+			}
+			// Skip the files completed before the beginning of the time range.
+			if !filter.from.IsZero() && i+1 < len(files) && !files[i+1].start.IsZero() && !files[i+1].start.After(filter.from) {
+				continue
+			}
+		}
+		selected = append(selected, file.path)
+	}
+	return selected, nil
+}
+
+// Searches a single legal log file for the entries matching the filter.
+// It returns the number of entries passed to the callback. The search stops
+// when the limit is reached. The zero limit means no limit.
+func searchLegalLogFile(ctx context.Context, path string, filter *legalLogFilter, limit int64, callback func(*keadata.LegalLogEntry) error) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open the legal log file %s", path)
+	}
+	defer file.Close()
+
+	var count int64
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), legalLogMaxLineLength)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+		entry, err := keadata.ParseLegalLogEntry(scanner.Text(), time.Local)
+		if err != nil || !filter.matches(entry) {
+			continue
+		}
+		if err = callback(entry); err != nil {
+			return count, err
+		}
+		count++
+		if limit > 0 && count >= limit {
+			return count, nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		// Return the partial results. They are more useful than no results.
+		log.WithError(err).WithField("file", path).Warn("Failed to read the legal log file")
+	}
+	return count, nil
+}
+
+// Searches the legal log files matching the pattern for the entries
+// matching the filter. The matching entries are passed to the callback
+// in the chronological order until the limit is reached or the callback
+// returns an error. The zero limit means no limit.
+func searchLegalLogFiles(ctx context.Context, pattern string, filter *legalLogFilter, limit int64, callback func(*keadata.LegalLogEntry) error) error {
+	paths, err := findLegalLogFiles(pattern, filter)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		count, err := searchLegalLogFile(ctx, path, filter, limit, callback)
+		if err != nil {
+			return err
+		}
+		if limit > 0 {
+			limit -= count
+			if limit == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	agentapi "isc.org/stork/api"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	keadata "isc.org/stork/daemondata/kea"
+)
+
+// Writes the legal log files with the specified contents to the directory.
+func writeLegalLogFiles(t *testing.T, directory string, files map[string]string) {
+	for name, contents := range files {
+		err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0o600)
+		require.NoError(t, err)
+	}
+}
+
+// Collects the addresses of the legal log entries returned by the search.
+func searchLegalLogAddresses(t *testing.T, pattern string, req *agentapi.SearchKeaLegalLogReq) []string {
+	filter, err := newLegalLogFilter(req)
+	require.NoError(t, err)
+	var addresses []string
+	err = searchLegalLogFiles(context.Background(), pattern, filter, req.Limit, func(entry *keadata.LegalLogEntry) error {
+		addresses = append(addresses, entry.Address)
+		return nil
+	})
+	require.NoError(t, err)
+	return addresses
+}
+
+// Test that the invalid filtering criteria are rejected.
+func TestNewLegalLogFilterInvalid(t *testing.T) {
+	_, err := newLegalLogFilter(&agentapi.SearchKeaLegalLogReq{Address: "192.0.2.x"})
+	require.ErrorContains(t, err, "invalid IP address")
+
+	_, err = newLegalLogFilter(&agentapi.SearchKeaLegalLogReq{HwAddress: "zz:01"})
+	require.ErrorContains(t, err, "invalid hardware address")
+
+	_, err = newLegalLogFilter(&agentapi.SearchKeaLegalLogReq{Duid: "zz:01"})
+	require.ErrorContains(t, err, "invalid DUID")
+}
+
+// Test matching the legal log entries against the filter.
+func TestLegalLogFilterMatches(t *testing.T) {
+	entry := &keadata.LegalLogEntry{
+		Timestamp: time.Unix(1000, 0),
+		Address:   "2001:db8:1::/64",
+		HWAddress: "01:02:03:04:05:06",
+		DUID:      "0a:0b:0c",
+	}
+
+	for _, req := range []*agentapi.SearchKeaLegalLogReq{
+		{},
+		{Address: "2001:db8:1::"},
+		{Address: "2001:db8:1:0::/64"},
+		{HwAddress: "010203040506", Duid: "0a:0b:0c"},
+		{From: 1000, To: 1000},
+	} {
+		filter, err := newLegalLogFilter(req)
+		require.NoError(t, err)
+		require.True(t, filter.matches(entry), "%+v", req)
+	}
+
+	for _, req := range []*agentapi.SearchKeaLegalLogReq{
+		{Address: "2001:db8:2::"},
+		{Address: "2001:db8:1::/56"},
+		{HwAddress: "010203040507"},
+		{Duid: "0a:0b"},
+		{From: 1001},
+		{To: 999},
+	} {
+		filter, err := newLegalLogFilter(req)
+		require.NoError(t, err)
+		require.False(t, filter.matches(entry), "%+v", req)
+	}
+
+	// The entries without the timestamp do not match the time range.
+	filter, err := newLegalLogFilter(&agentapi.SearchKeaLegalLogReq{From: 1})
+	require.NoError(t, err)
+	require.False(t, filter.matches(&keadata.LegalLogEntry{}))
+}
+
+// Test recognizing the start time of the legal log files.
+func TestGetLegalLogFileStart(t *testing.T) {
+	require.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local), getLegalLogFileStart("/var/log/kea-legal.20250102.txt"))
+	require.Equal(t, time.Unix(1735776000, 0), getLegalLogFileStart("/var/log/kea-legal.T1735776000.txt"))
+	require.Zero(t, getLegalLogFileStart("/var/log/kea-legal.foo.txt"))
+	require.Zero(t, getLegalLogFileStart("/var/log/kea-legal.Tfoo.txt"))
+}
+
+// Test that only the legal log files covering the time range are searched
+// and they are searched in the chronological order.
+func TestFindLegalLogFiles(t *testing.T) {
+	directory := t.TempDir()
+	writeLegalLogFiles(t, directory, map[string]string{
+		"kea-legal.20250103.txt": "",
+		"kea-legal.20250101.txt": "",
+		"kea-legal.20250102.txt": "",
+		"kea-legal.custom.txt":   "",
+		"other.20250101.txt":     "",
+	})
+	pattern, ok := keaconfig.LegalLogHookParams{Database: keaconfig.Database{Path: directory}}.GetFilePattern()
+	require.True(t, ok)
+
+	files, err := findLegalLogFiles(pattern, &legalLogFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(directory, "kea-legal.20250101.txt"),
+		filepath.Join(directory, "kea-legal.20250102.txt"),
+		filepath.Join(directory, "kea-legal.20250103.txt"),
+		filepath.Join(directory, "kea-legal.custom.txt"),
+	}, files)
+
+	files, err = findLegalLogFiles(pattern, &legalLogFilter{
+		from: time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local),
+		to:   time.Date(2025, 1, 2, 13, 0, 0, 0, time.Local),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(directory, "kea-legal.20250102.txt"),
+		filepath.Join(directory, "kea-legal.custom.txt"),
+	}, files)
+}
+
+// Test searching the legal log files.
+func TestSearchLegalLogFiles(t *testing.T) {
+	directory := t.TempDir()
+	writeLegalLogFiles(t, directory, map[string]string{
+		"kea-legal.20250101.txt": "2025-01-01 10:00:00 UTC Address: 192.0.2.1 has been assigned for 1 hrs 0 mins 0 secs to a device with hardware address: hwtype=1 01:02:03:04:05:06\n" +
+			"unrelated line\n" +
+			"2025-01-01 11:00:00 UTC Address: 192.0.2.1 has been renewed for 1 hrs 0 mins 0 secs to a device with hardware address: hwtype=1 01:02:03:04:05:06\n",
+		"kea-legal.20250102.txt": "2025-01-02 10:00:00 UTC Address: 192.0.2.2 has been assigned for 1 hrs 0 mins 0 secs to a device with hardware address: hwtype=1 01:02:03:04:05:06\n" +
+			"2025-01-02 11:00:00 UTC Address: 192.0.2.1 has been assigned for 1 hrs 0 mins 0 secs to a device with hardware address: hwtype=1 0a:0b:0c:0d:0e:0f\n",
+	})
+	pattern := filepath.Join(directory, "kea-legal.*.txt")
+
+	t.Run("all entries", func(t *testing.T) {
+		addresses := searchLegalLogAddresses(t, pattern, &agentapi.SearchKeaLegalLogReq{})
+		require.Equal(t, []string{"192.0.2.1", "192.0.2.1", "192.0.2.2", "192.0.2.1"}, addresses)
+	})
+
+	t.Run("hardware address", func(t *testing.T) {
+		addresses := searchLegalLogAddresses(t, pattern, &agentapi.SearchKeaLegalLogReq{HwAddress: "01:02:03:04:05:06"})
+		require.Equal(t, []string{"192.0.2.1", "192.0.2.1", "192.0.2.2"}, addresses)
+	})
+
+	t.Run("limit across files", func(t *testing.T) {
+		addresses := searchLegalLogAddresses(t, pattern, &agentapi.SearchKeaLegalLogReq{Address: "192.0.2.1", Limit: 3})
+		require.Len(t, addresses, 3)
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		addresses := searchLegalLogAddresses(t, pattern, &agentapi.SearchKeaLegalLogReq{
+			From: time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC).Unix(),
+			To:   time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC).Unix(),
+		})
+		require.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, addresses)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		err := searchLegalLogFiles(context.Background(), pattern, &legalLogFilter{}, 0, func(entry *keadata.LegalLogEntry) error {
+			return errors.New("test error")
+		})
+		require.ErrorContains(t, err, "test error")
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := searchLegalLogFiles(ctx, pattern, &legalLogFilter{}, 0, func(entry *keadata.LegalLogEntry) error {
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// Test getting the legal log file pattern of the Kea daemon.
+func TestKeaDaemonGetLegalLogFilePattern(t *testing.T) {
+	daemon := &keaDaemon{}
+	_, err := daemon.getLegalLogFilePattern()
+	require.ErrorContains(t, err, "not loaded")
+
+	daemon.legalLog = &keaconfig.LegalLogHookParams{Database: keaconfig.Database{Type: "syslog"}}
+	_, err = daemon.getLegalLogFilePattern()
+	require.ErrorContains(t, err, "syslog")
+
+	daemon.legalLog = &keaconfig.LegalLogHookParams{Database: keaconfig.Database{Path: "relative"}}
+	_, err = daemon.getLegalLogFilePattern()
+	require.ErrorContains(t, err, "not absolute")
+
+	daemon.legalLog = &keaconfig.LegalLogHookParams{Database: keaconfig.Database{Path: "/var/log/kea"}, BaseName: "forensic"}
+	pattern, err := daemon.getLegalLogFilePattern()
+	require.NoError(t, err)
+	require.Equal(t, "/var/log/kea/forensic.*.txt", pattern)
+}
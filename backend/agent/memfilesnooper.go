@@ -1,15 +1,18 @@
 package agent
 
 import (
+	"cmp"
 	"encoding/csv"
 	"encoding/json"
 	"io"
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	keadata "isc.org/stork/daemondata/kea"
 	"isc.org/stork/datamodel/daemonname"
@@ -496,6 +499,7 @@ type MemfileSnooper interface {
 	Stop()
 	EnsureWatching(path string) error
 	GetSnapshot() []*keadata.Lease
+	GetLeaseEvents(minObservedAt int64) []*keadata.LeaseEvent
 }
 
 // The underlying real type for MemfileSnooper, which does actual work (as distinct from a mock one, generated for tests).
@@ -505,10 +509,21 @@ type RealMemfileSnooper struct {
 	lastCLTT            uint64
 	leaseUpdates        []*keadata.Lease
 	leaseUpdateCountMax int
-	running             bool
-	stop                chan bool
-	mutex               sync.Mutex
-	parser              func([]string, uint64) (*keadata.Lease, error)
+	// The most recent state of each lease, by IP address. It is used to
+	// detect the transitions in the lease lifecycle.
+	leaseStates map[string]*keadata.Lease
+	// The lease lifecycle events, oldest first. The number of events is
+	// limited by leaseUpdateCountMax.
+	leaseEvents []*keadata.LeaseEvent
+	// Observation time of the most recent lease event.
+	lastObservedAt int64
+	// Indicates that the oldest lease events have been dropped since the
+	// server last fetched them.
+	leaseEventsDropped bool
+	running            bool
+	stop               chan bool
+	mutex              sync.Mutex
+	parser             func([]string, uint64) (*keadata.Lease, error)
 }
 
 // Create a new MemfileSnooper (the Real kind) for a given daemon and using a
@@ -519,6 +534,8 @@ func NewMemfileSnooper(updateLimit int, kind daemonname.Name, rs RowSource) (Mem
 		rs:                  rs,
 		leaseUpdates:        make([]*keadata.Lease, 0),
 		leaseUpdateCountMax: updateLimit,
+		leaseStates:         make(map[string]*keadata.Lease),
+		leaseEvents:         make([]*keadata.LeaseEvent, 0),
 		stop:                make(chan bool),
 	}
 	switch kind {
@@ -590,6 +607,146 @@ func (ms *RealMemfileSnooper) GetSnapshot() []*keadata.Lease {
 	return snapshot
 }
 
+// Checks whether the leases are held by the same client.
+func isSameLeaseClient(lease1, lease2 *keadata.Lease) bool {
+	return lease1.HWAddress == lease2.HWAddress &&
+		lease1.ClientID.String() == lease2.ClientID.String() &&
+		lease1.DUID.String() == lease2.DUID.String()
+}
+
+// Checks whether the lease file rows represent the same state of the lease.
+// The lease file contains the same rows multiple times after it is rewritten
+// by the lease file cleanup.
+func isSameLeaseState(lease1, lease2 *keadata.Lease) bool {
+	return lease1.CLTT == lease2.CLTT &&
+		lease1.ValidLifetime == lease2.ValidLifetime &&
+		lease1.State == lease2.State &&
+		lease1.Hostname == lease2.Hostname &&
+		isSameLeaseClient(lease1, lease2)
+}
+
+// Determines the transition in the lease lifecycle between the previous and
+// the current state of the lease having the same IP address. The previous
+// state is nil if the lease has not been seen before. It returns the event
+// type and the Unix time when the transition occurred. The last value is
+// false if the current state is not a transition.
+//
+// The time of the release is not recorded in the lease file, so the release
+// is assumed to occur at the specified observation time.
+func classifyLeaseTransition(previous, current *keadata.Lease, observedAt uint64) (keadata.LeaseEventType, uint64, bool) {
+	if previous != nil && isSameLeaseState(previous, current) {
+		return "", 0, false
+	}
+	wasHeld := previous != nil &&
+		(previous.State == keadata.LeaseStateDefault || previous.State == keadata.LeaseStateRegistered)
+	switch {
+	case current.ValidLifetime == 0:
+		// Kea writes the lease with a zero valid lifetime when it deletes
+		// the lease. The deleted lease which was not held by any client
+		// (e.g., an expired one) is not a transition.
+		if !wasHeld {
+			return "", 0, false
+		}
+		return keadata.LeaseEventReleased, observedAt, true
+	case current.State == keadata.LeaseStateDeclined:
+		if previous != nil && previous.State == current.State {
+			return "", 0, false
+		}
+		return keadata.LeaseEventDeclined, current.CLTT, true
+	case current.State == keadata.LeaseStateExpiredReclaimed:
+		if previous != nil && previous.State == current.State {
+			return "", 0, false
+		}
+		return keadata.LeaseEventExpired, current.CLTT + uint64(current.ValidLifetime), true
+	case current.State == keadata.LeaseStateReleased:
+		if previous != nil && previous.State == current.State {
+			return "", 0, false
+		}
+		return keadata.LeaseEventReleased, observedAt, true
+	case current.State == keadata.LeaseStateDefault || current.State == keadata.LeaseStateRegistered:
+		if !wasHeld || !isSameLeaseClient(previous, current) {
+			return keadata.LeaseEventAssigned, current.CLTT, true
+		}
+		if current.CLTT <= previous.CLTT {
+			// The lease was updated without contacting the client, e.g.,
+			// by the DNS update.
+			return "", 0, false
+		}
+		return keadata.LeaseEventRenewed, current.CLTT, true
+	default:
+		return "", 0, false
+	}
+}
+
+// Compares the lease with its most recent state and records the lease event
+// if the lease underwent a transition.
+func (ms *RealMemfileSnooper) recordLeaseEvent(lease *keadata.Lease) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	if ms.leaseStates == nil {
+		ms.leaseStates = make(map[string]*keadata.Lease)
+	}
+	previous := ms.leaseStates[lease.IPAddress]
+	if lease.ValidLifetime == 0 {
+		delete(ms.leaseStates, lease.IPAddress)
+	} else {
+		ms.leaseStates[lease.IPAddress] = lease
+	}
+
+	// Ensure the observation times are unique so the server can use them
+	// to fetch only the new events.
+	observedAt := time.Now().UnixNano()
+	if observedAt <= ms.lastObservedAt {
+		observedAt = ms.lastObservedAt + 1
+	}
+	eventType, occurredAt, ok := classifyLeaseTransition(previous, lease, uint64(observedAt/int64(time.Second)))
+	if !ok {
+		return
+	}
+	ms.lastObservedAt = observedAt
+
+	event := &keadata.LeaseEvent{
+		Type:       eventType,
+		Lease:      *lease,
+		OccurredAt: occurredAt,
+		ObservedAt: observedAt,
+	}
+	// Kea clears the client identifiers when the lease is declined or
+	// deleted. Keep the identifiers of the client that held the lease.
+	if previous != nil && lease.HWAddress == "" && lease.ClientID.String() == "" && lease.DUID.String() == "" {
+		event.Lease.HWAddress = previous.HWAddress
+		event.Lease.ClientID = previous.ClientID
+		event.Lease.DUID = previous.DUID
+	}
+	if len(ms.leaseEvents) >= ms.leaseEventCountMax() {
+		if !ms.leaseEventsDropped {
+			log.Warnf("The number of stored lease events has exceeded the configured memory limit of %d; the oldest events are dropped before the server fetches them", ms.leaseUpdateCountMax)
+			ms.leaseEventsDropped = true
+		}
+		ms.leaseEvents = ms.leaseEvents[1:]
+	}
+	ms.leaseEvents = append(ms.leaseEvents, event)
+}
+
+// Returns the maximum number of stored lease events. It is the same as the
+// maximum number of the stored lease updates.
+func (ms *RealMemfileSnooper) leaseEventCountMax() int {
+	return max(ms.leaseUpdateCountMax, 1)
+}
+
+// Retrieve the lease lifecycle events observed after the specified Unix
+// time (nanoseconds), oldest first.
+func (ms *RealMemfileSnooper) GetLeaseEvents(minObservedAt int64) []*keadata.LeaseEvent {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.leaseEventsDropped = false
+	// The events are ordered by the observation time.
+	index, _ := slices.BinarySearchFunc(ms.leaseEvents, minObservedAt, func(event *keadata.LeaseEvent, target int64) int {
+		return cmp.Compare(event.ObservedAt, target+1)
+	})
+	return slices.Clone(ms.leaseEvents[index:])
+}
+
 // Helper function to ensure the RowSource is watching the right path. Passes
 // its arguments directly to RowSource.EnsureWatching() and returns the result
 // unmodified.
@@ -647,7 +804,10 @@ func (ms *RealMemfileSnooper) Start() error {
 				if !ok {
 					return
 				}
-				parsed, err := ms.parser(row, ms.lastCLTT)
+				// Parse all rows to track the lease lifecycle. The rows
+				// older than the most recent CLTT are not included in the
+				// snapshot.
+				parsed, err := ms.parser(row, 0)
 				if err != nil {
 					// #2522: don't log a warning about headers. It happens every time LFC
 					// runs, and therefore creates log noise that makes finding other issues
@@ -658,6 +818,10 @@ func (ms *RealMemfileSnooper) Start() error {
 					continue
 				}
 				if parsed == nil {
+					continue
+				}
+				ms.recordLeaseEvent(parsed)
+				if parsed.CLTT < ms.lastCLTT {
 					// This is normal; it happens when the row is older than the most recent CLTT.
 					continue
 				}
@@ -1262,3 +1262,112 @@ func TestMemfileSnooperEnsureWatchingCallsRowSource(t *testing.T) {
 
 	// Assert (ctrl.Finish does the work)
 }
+
+// Test classifying the transitions in the lease lifecycle.
+func TestClassifyLeaseTransition(t *testing.T) {
+	newLease := func(hwAddress string, cltt uint64, validLifetime, state uint32) *keadata.Lease {
+		lease := keadata.NewLease4("192.0.2.1", hwAddress, "", cltt, validLifetime, 1, false, false, "", state, nil)
+		return &lease
+	}
+	held := newLease("01:01:01:01:01:01", 1000, 3600, keadata.LeaseStateDefault)
+
+	testCases := []struct {
+		name               string
+		previous           *keadata.Lease
+		current            *keadata.Lease
+		expectedType       keadata.LeaseEventType
+		expectedOccurredAt uint64
+	}{
+		{"new lease", nil, held, keadata.LeaseEventAssigned, 1000},
+		{"same state", held, newLease("01:01:01:01:01:01", 1000, 3600, keadata.LeaseStateDefault), "", 0},
+		{"renewal", held, newLease("01:01:01:01:01:01", 2000, 3600, keadata.LeaseStateDefault), keadata.LeaseEventRenewed, 2000},
+		{"other client", held, newLease("02:02:02:02:02:02", 2000, 3600, keadata.LeaseStateDefault), keadata.LeaseEventAssigned, 2000},
+		{"reassignment after expiration", newLease("01:01:01:01:01:01", 1000, 3600, keadata.LeaseStateExpiredReclaimed), newLease("01:01:01:01:01:01", 9000, 3600, keadata.LeaseStateDefault), keadata.LeaseEventAssigned, 9000},
+		{"expiration", held, newLease("01:01:01:01:01:01", 1000, 3600, keadata.LeaseStateExpiredReclaimed), keadata.LeaseEventExpired, 4600},
+		{"decline", held, newLease("", 1500, 86400, keadata.LeaseStateDeclined), keadata.LeaseEventDeclined, 1500},
+		{"release", held, newLease("01:01:01:01:01:01", 1000, 3600, keadata.LeaseStateReleased), keadata.LeaseEventReleased, 5000},
+		{"deletion", held, newLease("01:01:01:01:01:01", 1000, 0, keadata.LeaseStateDefault), keadata.LeaseEventReleased, 5000},
+		{"deletion of unknown lease", nil, newLease("01:01:01:01:01:01", 1000, 0, keadata.LeaseStateDefault), "", 0},
+		{"deletion of expired lease", newLease("01:01:01:01:01:01", 1000, 3600, keadata.LeaseStateExpiredReclaimed), newLease("01:01:01:01:01:01", 1000, 0, keadata.LeaseStateExpiredReclaimed), "", 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			eventType, occurredAt, ok := classifyLeaseTransition(testCase.previous, testCase.current, 5000)
+			require.Equal(t, testCase.expectedType != "", ok)
+			require.Equal(t, testCase.expectedType, eventType)
+			require.Equal(t, testCase.expectedOccurredAt, occurredAt)
+		})
+	}
+}
+
+// Ensure that the MemfileSnooper records the lease lifecycle events,
+// including the rows with the CLTT older than the most recent one, and
+// returns the events observed after the specified time.
+func TestMemfileSnooperCollectsLeaseEvents(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	rows := [][]string{
+		{"192.168.1.1", "00:00:00:00:00:00", "", "3600", "1761257849", "123", "0", "0", "", "0", "", "0"},
+		{"192.168.1.2", "00:00:00:00:00:01", "", "3600", "1761257850", "123", "0", "0", "", "0", "", "0"},
+		{"192.168.1.1", "00:00:00:00:00:00", "", "3600", "1761257900", "123", "0", "0", "", "0", "", "0"},
+		// Reclaimed lease having an older CLTT.
+		{"192.168.1.2", "00:00:00:00:00:01", "", "3600", "1761257850", "123", "0", "0", "", "2", "", "0"},
+		// Duplicate row after the lease file cleanup.
+		{"192.168.1.1", "00:00:00:00:00:00", "", "3600", "1761257900", "123", "0", "0", "", "0", "", "0"},
+	}
+	rowSource, wg := makeMockRowSource(ctrl, rows)
+
+	memfileSnooper, err := NewMemfileSnooper(10, daemonname.DHCPv4, rowSource)
+	require.NoError(t, err)
+
+	// Act
+	err = memfileSnooper.Start()
+	require.NoError(t, err)
+	wg.Wait()
+	memfileSnooper.Stop()
+
+	// Assert
+	events := memfileSnooper.GetLeaseEvents(0)
+	require.Len(t, events, 4)
+	require.Equal(t, keadata.LeaseEventAssigned, events[0].Type)
+	require.Equal(t, keadata.LeaseEventAssigned, events[1].Type)
+	require.Equal(t, keadata.LeaseEventRenewed, events[2].Type)
+	require.Equal(t, keadata.LeaseEventExpired, events[3].Type)
+	require.EqualValues(t, 1761257850, events[3].OccurredAt)
+	for i := 1; i < len(events); i++ {
+		require.Greater(t, events[i].ObservedAt, events[i-1].ObservedAt)
+	}
+
+	require.Equal(t, events[2:], memfileSnooper.GetLeaseEvents(events[1].ObservedAt))
+	require.Empty(t, memfileSnooper.GetLeaseEvents(events[3].ObservedAt))
+}
+
+// Ensure that the MemfileSnooper drops the oldest lease events when the limit
+// is exceeded.
+func TestMemfileSnooperLeaseEventsLimit(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	rows := [][]string{
+		{"192.168.1.1", "00:00:00:00:00:00", "", "3600", "1761257849", "123", "0", "0", "", "0", "", "0"},
+		{"192.168.1.2", "00:00:00:00:00:01", "", "3600", "1761257850", "123", "0", "0", "", "0", "", "0"},
+		{"192.168.1.3", "00:00:00:00:00:02", "", "3600", "1761257851", "123", "0", "0", "", "0", "", "0"},
+	}
+	rowSource, wg := makeMockRowSource(ctrl, rows)
+
+	memfileSnooper, err := NewMemfileSnooper(2, daemonname.DHCPv4, rowSource)
+	require.NoError(t, err)
+
+	// Act
+	err = memfileSnooper.Start()
+	require.NoError(t, err)
+	wg.Wait()
+	memfileSnooper.Stop()
+
+	// Assert
+	events := memfileSnooper.GetLeaseEvents(0)
+	require.Len(t, events, 2)
+	require.Equal(t, "192.168.1.2", events[0].Lease.IPAddress)
+	require.Equal(t, "192.168.1.3", events[1].Lease.IPAddress)
+}
@@ -118,6 +118,8 @@ type pdnsConfigParser interface {
 // Implements the Daemon interface for PowerDNS.
 type pdnsDaemon struct {
 	dnsDaemonImpl
+	// Parsed configuration file of the daemon.
+	config *pdnsconfig.Config
 }
 
 // Checks if the current daemon instance is the same as the other daemon instance.
@@ -375,6 +377,7 @@ func (sm *monitor) configurePowerDNSDaemon(detectedFiles *detectedDaemonFiles) (
 			zoneInventory: inventory,
 			detectedFiles: detectedFiles,
 		},
+		config: parsedConfig,
 	}
 	return daemon, nil
 }
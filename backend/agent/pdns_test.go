@@ -842,6 +842,8 @@ func TestConfigurePowerDNSDaemon(t *testing.T) {
 	require.Equal(t, "127.0.0.1", daemon.GetAccessPoints()[0].Address)
 	require.Equal(t, "stork", daemon.GetAccessPoints()[0].Key)
 	require.NotNil(t, daemon.getZoneInventory())
+	require.NotNil(t, daemon.config)
+	require.Equal(t, "stork", daemon.config.GetAPIKey())
 }
 
 // Test that an error is returned when parsing the configuration file fails.
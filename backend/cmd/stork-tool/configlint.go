@@ -0,0 +1,171 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/cli"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/configreportio"
+	"isc.org/stork/server/configreview"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// The CLI flags for the config-lint command.
+type configLintSettings struct {
+	cli.CommandSettings
+	File       string `long:"file" short:"i" description:"The Kea, BIND 9 or PowerDNS configuration file to review" env:"STORK_TOOL_CONFIG_FILE" required:"true"`
+	Type       string `long:"type" short:"t" description:"The type of the configuration file; if not provided, then it is determined from the file name" env:"STORK_TOOL_CONFIG_TYPE" choice:"kea" choice:"bind9" choice:"pdns"`
+	Format     string `long:"format" short:"f" description:"The format of the review reports" env:"STORK_TOOL_CONFIG_REPORT_FORMAT" choice:"text" choice:"json" choice:"sarif" choice:"junit" default:"sarif"`
+	Output     string `long:"output" short:"o" description:"The file location where the review reports should be saved; if not provided, then they are printed to stdout" env:"STORK_TOOL_CONFIG_REPORT_FILE"`
+	KeaVersion string `long:"kea-version" description:"The Kea version the configuration is intended for; the latest version is assumed if not provided" env:"STORK_TOOL_KEA_VERSION"`
+}
+
+// Creates the daemon with the Kea configuration read from the file. The
+// daemon name is determined from the configuration. The daemon has a
+// non-zero ID required by the config review.
+func newKeaDaemonFromFile(file, version string) (*dbmodel.Daemon, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the file %s", file)
+	}
+	config, err := keaconfig.NewConfig(content)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse the Kea configuration file %s", file)
+	}
+	var name daemonname.Name
+	switch {
+	case config.IsDHCPv4():
+		name = daemonname.DHCPv4
+	case config.IsDHCPv6():
+		name = daemonname.DHCPv6
+	case config.IsD2():
+		name = daemonname.D2
+	case config.IsCtrlAgent():
+		name = daemonname.CA
+	default:
+		return nil, errors.Errorf("unsupported Kea configuration in the file %s", file)
+	}
+	daemon := dbmodel.NewDaemon(&dbmodel.Machine{}, name, true, nil)
+	daemon.ID = 1
+	daemon.Version = version
+	if err = daemon.SetKeaConfigFromJSON(content); err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse the Kea configuration file %s", file)
+	}
+	return daemon, nil
+}
+
+// Creates the BIND 9 daemon with the configuration read from the file. The
+// included files are read and merged into the configuration like the
+// agent does before sending the configuration to the server.
+func newBind9DaemonFromFile(file string) (*dbmodel.Daemon, error) {
+	config, err := bind9config.NewParser().ParseFile(file, "")
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse the BIND 9 configuration file %s", file)
+	}
+	config, _, err = config.Expand()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to expand the BIND 9 configuration file %s", file)
+	}
+	daemon := dbmodel.NewDaemon(&dbmodel.Machine{}, daemonname.Bind9, true, nil)
+	daemon.ID = 1
+	daemon.Bind9Daemon.Config = config
+	return daemon, nil
+}
+
+// Creates the PowerDNS daemon with the configuration read from the file.
+func newPDNSDaemonFromFile(file string) (*dbmodel.Daemon, error) {
+	config, err := pdnsconfig.NewParser().ParseFile(file)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse the PowerDNS configuration file %s", file)
+	}
+	daemon := dbmodel.NewDaemon(&dbmodel.Machine{}, daemonname.PDNS, true, nil)
+	daemon.ID = 1
+	daemon.PDNSDaemon.Config = config
+	return daemon, nil
+}
+
+// Creates the daemon with the configuration read from the file. If the
+// configuration type is not specified, it is determined from the file
+// name. The BIND 9 configuration files are typically named named.conf
+// and the PowerDNS configuration files pdns.conf. The other files are
+// assumed to hold the Kea configuration.
+func newDaemonFromFile(settings *configLintSettings) (*dbmodel.Daemon, error) {
+	configType := settings.Type
+	if configType == "" {
+		name := strings.ToLower(filepath.Base(settings.File))
+		switch {
+		case strings.Contains(name, "named"):
+			configType = "bind9"
+		case strings.Contains(name, "pdns"):
+			configType = "pdns"
+		default:
+			configType = "kea"
+		}
+	}
+	switch configType {
+	case "bind9":
+		return newBind9DaemonFromFile(settings.File)
+	case "pdns":
+		return newPDNSDaemonFromFile(settings.File)
+	default:
+		return newKeaDaemonFromFile(settings.File, settings.KeaVersion)
+	}
+}
+
+// Reviews the configuration file with the config review checkers that
+// don't require the Stork server database. The reports are written to
+// the output file or to stdout. It returns the number of found issues.
+func runConfigLint(settings *configLintSettings) (int, error) {
+	format, err := configreportio.ParseFormat(settings.Format)
+	if err != nil {
+		return 0, err
+	}
+	daemon, err := newDaemonFromFile(settings)
+	if err != nil {
+		return 0, err
+	}
+	reports, err := configreview.ReviewOffline(daemon)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "failed to review the configuration file %s", settings.File)
+	}
+	issues := 0
+	for _, report := range reports {
+		if report.IsIssueFound() {
+			issues++
+		}
+	}
+
+	var writer io.Writer = os.Stdout
+	if settings.Output != "" {
+		file, err := os.Create(settings.Output)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to create the file %s", settings.Output)
+		}
+		defer file.Close()
+		writer = file
+	}
+	err = configreportio.Write(writer, format, []configreportio.DaemonReports{{
+		DaemonName: string(daemon.Name),
+		Label:      string(daemon.Name),
+		File:       settings.File,
+		Reports:    reports,
+	}})
+	if err != nil {
+		return 0, err
+	}
+	log.WithFields(log.Fields{
+		"config": settings.File,
+		"checks": len(reports),
+		"issues": issues,
+	}).Info("Configuration review finished")
+	return issues, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"isc.org/stork/testutil"
+)
+
+// Kea DHCPv4 configuration with overlapping subnets.
+const configLintDHCPv4Config = `{
+	// Comments are allowed in the Kea configuration files.
+	"Dhcp4": {
+		"hooks-libraries": [
+			{ "library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so" }
+		],
+		"subnet4": [
+			{ "id": 1, "subnet": "192.0.2.0/24" },
+			{ "id": 2, "subnet": "192.0.2.0/25" }
+		]
+	}
+}`
+
+// Test that the Kea configuration file is reviewed and the reports are
+// written in the SARIF format.
+func TestRunConfigLintSARIF(t *testing.T) {
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	file, err := sb.Write("kea-dhcp4.conf", configLintDHCPv4Config)
+	require.NoError(t, err)
+	output := filepath.Join(filepath.Dir(file), "review.sarif")
+
+	issues, err := runConfigLint(&configLintSettings{
+		File:   file,
+		Format: "sarif",
+		Output: output,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, issues)
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var sarif map[string]any
+	err = json.Unmarshal(content, &sarif)
+	require.NoError(t, err)
+	results := sarif["runs"].([]any)[0].(map[string]any)["results"].([]any)
+	require.Len(t, results, 1)
+	result := results[0].(map[string]any)
+	require.Equal(t, "overlapping_subnet", result["ruleId"])
+	require.Contains(t, result["message"].(map[string]any)["text"], "dhcp4")
+	location := result["locations"].([]any)[0].(map[string]any)
+	require.Equal(t, file, location["physicalLocation"].(map[string]any)["artifactLocation"].(map[string]any)["uri"])
+}
+
+// Test that the reports of the configuration without issues are written in
+// the JUnit format.
+func TestRunConfigLintJUnit(t *testing.T) {
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	file, err := sb.Write("kea-ctrl-agent.conf", `{
+		"Control-agent": {
+			"http-host": "127.0.0.1",
+			"http-port": 8000,
+			"control-sockets": {
+				"dhcp4": {
+					"socket-type": "unix",
+					"socket-name": "/tmp/kea-dhcp4-ctrl.sock"
+				}
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	output := filepath.Join(filepath.Dir(file), "review.xml")
+
+	issues, err := runConfigLint(&configLintSettings{
+		File:   file,
+		Format: "junit",
+		Output: output,
+	})
+	require.NoError(t, err)
+	require.Zero(t, issues)
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `failures="0"`)
+	require.Contains(t, string(content), `<testcase name="agent_credentials_over_https" classname="ca"`)
+}
+
+// Test that the BIND 9 configuration file is reviewed with the included
+// files and the reports are printed as text.
+func TestRunConfigLintBind9Text(t *testing.T) {
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	_, err := sb.Write("named.conf.options", `
+		options {
+			allow-recursion { any; };
+		};
+	`)
+	require.NoError(t, err)
+	file, err := sb.Write("named.conf", `include "named.conf.options";`)
+	require.NoError(t, err)
+	output := filepath.Join(filepath.Dir(file), "review.txt")
+
+	issues, err := runConfigLint(&configLintSettings{
+		File:   file,
+		Format: "text",
+		Output: output,
+	})
+	require.NoError(t, err)
+	require.Positive(t, issues)
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(content), "named ("+file+"): "))
+	require.Contains(t, string(content), "[bind9_open_recursion]")
+}
+
+// Test that the PowerDNS configuration file is reviewed and the reports
+// are written in the JSON format.
+func TestRunConfigLintPDNSJSON(t *testing.T) {
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	file, err := sb.Write("server.conf", "api=yes\nwebserver=yes\nloglevel=5\n")
+	require.NoError(t, err)
+	output := filepath.Join(filepath.Dir(file), "review.json")
+
+	issues, err := runConfigLint(&configLintSettings{
+		File:   file,
+		Type:   "pdns",
+		Format: "json",
+		Output: output,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, issues)
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var daemons []struct {
+		Daemon  string
+		File    string
+		Reports []struct {
+			Checker string
+			Content *string
+		}
+	}
+	err = json.Unmarshal(content, &daemons)
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	require.Equal(t, "pdns", daemons[0].Daemon)
+	require.Equal(t, file, daemons[0].File)
+	var found []string
+	for _, report := range daemons[0].Reports {
+		if report.Content != nil {
+			found = append(found, report.Checker)
+		}
+	}
+	require.Equal(t, []string{"pdns_api_key_missing"}, found)
+}
+
+// Test that reviewing an invalid or unsupported configuration file fails.
+func TestRunConfigLintInvalidFile(t *testing.T) {
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+
+	_, err := runConfigLint(&configLintSettings{
+		File:   filepath.Join(sb.BasePath, "missing.conf"),
+		Format: "sarif",
+	})
+	require.ErrorContains(t, err, "failed to read the file")
+
+	file, err := sb.Write("invalid.conf", `{ "Dhcp4": `)
+	require.NoError(t, err)
+	_, err = runConfigLint(&configLintSettings{
+		File:   file,
+		Format: "sarif",
+	})
+	require.ErrorContains(t, err, "failed to parse the Kea configuration file")
+
+	file, err = sb.Write("unknown.conf", `{ "Dhcp5": {} }`)
+	require.NoError(t, err)
+	_, err = runConfigLint(&configLintSettings{
+		File:   file,
+		Format: "sarif",
+	})
+	require.ErrorContains(t, err, "unsupported Kea configuration")
+
+	file, err = sb.Write("named.conf", `options {`)
+	require.NoError(t, err)
+	_, err = runConfigLint(&configLintSettings{
+		File:   file,
+		Format: "text",
+	})
+	require.ErrorContains(t, err, "failed to parse the BIND 9 configuration file")
+
+	_, err = runConfigLint(&configLintSettings{
+		File:   filepath.Join(sb.BasePath, "pdns.conf"),
+		Format: "text",
+	})
+	require.ErrorContains(t, err, "failed to parse the PowerDNS configuration file")
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/cli"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/hostsio"
+	storkutil "isc.org/stork/util"
+)
+
+// Environment variable holding the password of the Stork server user.
+const serverPasswordEnvVar = "STORK_TOOL_SERVER_PASSWORD" //nolint:gosec
+
+// The CLI flags specifying how to connect to the Stork server.
+type serverSettings struct {
+	ServerURL string `long:"server-url" short:"u" description:"The URL of the Stork server" env:"STORK_TOOL_SERVER_URL" default:"http://localhost:8080"`
+	User      string `long:"server-user" short:"l" description:"The login of the Stork server user; the password is read from the STORK_TOOL_SERVER_PASSWORD environment variable" env:"STORK_TOOL_SERVER_USER" default:"admin"`
+}
+
+// The CLI flags for the hosts-import command.
+type hostsImportSettings struct {
+	cli.CommandSettings
+	ServerSettings serverSettings
+	File           string  `long:"file" short:"i" description:"The file with the host reservations to import" env:"STORK_TOOL_HOSTS_FILE" required:"true"`
+	Format         string  `long:"format" short:"f" description:"The format of the file; if not provided, it is determined from the file extension" env:"STORK_TOOL_HOSTS_FORMAT" choice:"csv" choice:"json"`
+	DaemonIDs      []int64 `long:"daemon-id" short:"d" description:"The ID of the Kea server to which the host reservations are added; it can be specified multiple times" required:"true"`
+	BatchSize      int64   `long:"batch-size" short:"b" description:"The number of host reservations added in a single transaction" env:"STORK_TOOL_HOSTS_BATCH_SIZE" default:"100"`
+	DryRun         bool    `long:"dry-run" short:"n" description:"Validate the host reservations without adding them" env:"STORK_TOOL_HOSTS_DRY_RUN"`
+}
+
+// The CLI flags for the hosts-export command.
+type hostsExportSettings struct {
+	cli.CommandSettings
+	ServerSettings serverSettings
+	File           string `long:"file" short:"o" description:"The file location where the host reservations should be saved; if not provided, then they are printed to stdout" env:"STORK_TOOL_HOSTS_FILE"`
+	Format         string `long:"format" short:"f" description:"The format of the file" env:"STORK_TOOL_HOSTS_FORMAT" choice:"csv" choice:"json" default:"csv"`
+	MachineID      int64  `long:"machine-id" description:"Export only the host reservations from the specified machine"`
+	DaemonID       int64  `long:"daemon-id" description:"Export only the host reservations from the specified daemon"`
+	SubnetID       int64  `long:"subnet-id" description:"Export only the host reservations from the specified subnet"`
+	Text           string `long:"text" description:"Export only the host reservations containing the specified text"`
+	Global         bool   `long:"global" description:"Export only the global host reservations"`
+}
+
+// A client of the Stork server REST API. It keeps the session cookie
+// returned by the server after the successful login.
+type serverClient struct {
+	url    string
+	client *http.Client
+}
+
+// Creates a new client and logs in to the Stork server.
+func newServerClient(settings serverSettings, password string) (*serverClient, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the cookie jar")
+	}
+	client := &serverClient{
+		url: strings.TrimRight(settings.ServerURL, "/"),
+		client: &http.Client{
+			Jar: jar,
+		},
+	}
+	credentials := models.SessionCredentials{
+		AuthenticationMethodID: storkutil.Ptr("internal"),
+		Identifier:             &settings.User,
+		Secret:                 &password,
+	}
+	rsp, err := client.send(http.MethodPost, "/sessions", nil, credentials)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to log in to the Stork server")
+	}
+	rsp.Body.Close()
+	return client, nil
+}
+
+// Sends a request to the Stork server REST API. The body is serialized
+// to JSON. It returns an error if the server responds with a non-success
+// status code.
+func (c *serverClient) send(method, path string, query url.Values, body any) (*http.Response, error) {
+	endpoint := c.url + "/api" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to serialize the request")
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the request to %s", endpoint)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send the request to %s", endpoint)
+	}
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		defer rsp.Body.Close()
+		apiError := models.APIError{}
+		if err = json.NewDecoder(rsp.Body).Decode(&apiError); err == nil && apiError.Message != nil {
+			return nil, errors.Errorf("server returned status %d: %s", rsp.StatusCode, *apiError.Message)
+		}
+		return nil, errors.Errorf("server returned status %d", rsp.StatusCode)
+	}
+	return rsp, nil
+}
+
+// Returns the format of the host reservations file. If the format is not
+// specified explicitly it is determined from the file extension.
+func getHostsFileFormat(format, file string) (hostsio.Format, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+	return hostsio.ParseFormat(format)
+}
+
+// Imports the host reservations from the file to the Kea servers via
+// the Stork server. The errors for the invalid host reservations are
+// logged. It returns an error if any of the host reservations could not
+// be imported.
+func runHostsImport(settings *hostsImportSettings) error {
+	format, err := getHostsFileFormat(settings.Format, settings.File)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(settings.File)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the file %s", settings.File)
+	}
+	client, err := newServerClient(settings.ServerSettings, os.Getenv(serverPasswordEnvVar))
+	if err != nil {
+		return err
+	}
+	hostsImport := models.HostsImport{
+		Format:    storkutil.Ptr(string(format)),
+		Content:   storkutil.Ptr(string(content)),
+		DaemonIds: settings.DaemonIDs,
+		BatchSize: settings.BatchSize,
+		DryRun:    settings.DryRun,
+	}
+	rsp, err := client.send(http.MethodPost, "/hosts/import", nil, hostsImport)
+	if err != nil {
+		return errors.WithMessage(err, "failed to import the host reservations")
+	}
+	defer rsp.Body.Close()
+	result := models.HostsImportResult{}
+	if err = json.NewDecoder(rsp.Body).Decode(&result); err != nil {
+		return errors.Wrap(err, "failed to parse the host reservations import result")
+	}
+	for _, importErr := range result.Errors {
+		log.WithField("row", importErr.Row).Error(importErr.Message)
+	}
+	log.WithFields(log.Fields{
+		"total":     result.Total,
+		"valid":     result.Valid,
+		"imported":  result.Imported,
+		"submitted": result.Submitted,
+	}).Info("Host reservations import finished")
+	if result.Submitted > 0 {
+		log.WithField("requests", result.ConfigChangeRequestIds).
+			Info("Some host reservations have been submitted for approval; they will be added when the config change requests are approved")
+	}
+	if len(result.Errors) > 0 {
+		return errors.Errorf("%d of %d host reservations could not be imported", len(result.Errors), result.Total)
+	}
+	return nil
+}
+
+// Exports the host reservations from the Stork server to the file or
+// to stdout.
+func runHostsExport(settings *hostsExportSettings) error {
+	format, err := hostsio.ParseFormat(settings.Format)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("format", string(format))
+	for name, value := range map[string]int64{
+		"machineId": settings.MachineID,
+		"daemonId":  settings.DaemonID,
+		"subnetId":  settings.SubnetID,
+	} {
+		if value != 0 {
+			query.Set(name, strconv.FormatInt(value, 10))
+		}
+	}
+	if settings.Text != "" {
+		query.Set("text", settings.Text)
+	}
+	if settings.Global {
+		query.Set("global", "true")
+	}
+	client, err := newServerClient(settings.ServerSettings, os.Getenv(serverPasswordEnvVar))
+	if err != nil {
+		return err
+	}
+	rsp, err := client.send(http.MethodGet, "/hosts/export", query, nil)
+	if err != nil {
+		return errors.WithMessage(err, "failed to export the host reservations")
+	}
+	defer rsp.Body.Close()
+
+	var writer io.Writer = os.Stdout
+	if settings.File != "" {
+		file, err := os.Create(settings.File)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the file %s", settings.File)
+		}
+		defer file.Close()
+		writer = file
+	}
+	if _, err = io.Copy(writer, rsp.Body); err != nil {
+		return errors.Wrap(err, "failed to write the host reservations")
+	}
+	if settings.File != "" {
+		log.WithField("file", settings.File).Info("Host reservations exported")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/testutil"
+)
+
+// Creates a fake Stork server handling the login and the host reservations
+// import and export calls. The imported host reservations are stored in the
+// returned pointer.
+func newFakeHostsServer(t *testing.T) (*httptest.Server, *models.HostsImport) {
+	hostsImport := &models.HostsImport{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/sessions", func(w http.ResponseWriter, r *http.Request) {
+		credentials := models.SessionCredentials{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&credentials))
+		if *credentials.Identifier != "admin" || *credentials.Secret != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message": "Cannot authenticate the user"}`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "1234"})
+	})
+	mux.HandleFunc("/api/hosts/import", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(hostsImport))
+		_, _ = w.Write([]byte(`{"total": 2, "valid": 1, "imported": 1, "errors": [{"row": 2, "message": "invalid"}]}`))
+	})
+	mux.HandleFunc("/api/hosts/export", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "json", r.URL.Query().Get("format"))
+		require.Equal(t, "3", r.URL.Query().Get("subnetId"))
+		require.Empty(t, r.URL.Query().Get("machineId"))
+		_, _ = w.Write([]byte(`[{"identifier-type": "hw-address", "identifier": "01:02:03:04:05:06"}]`))
+	})
+	server := httptest.NewServer(mux)
+	return server, hostsImport
+}
+
+// Test that the host reservations are sent to the server and the import
+// errors are reported.
+func TestRunHostsImport(t *testing.T) {
+	server, hostsImport := newFakeHostsServer(t)
+	defer server.Close()
+	t.Setenv(serverPasswordEnvVar, "secret")
+
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	file, err := sb.Write("hosts.csv", "identifier-type,identifier\nhw-address,01:02:03:04:05:06\nduid,zz\n")
+	require.NoError(t, err)
+
+	settings := &hostsImportSettings{
+		ServerSettings: serverSettings{
+			ServerURL: server.URL,
+			User:      "admin",
+		},
+		File:      file,
+		DaemonIDs: []int64{1, 2},
+		BatchSize: 10,
+	}
+	err = runHostsImport(settings)
+	require.ErrorContains(t, err, "1 of 2 host reservations could not be imported")
+
+	require.Equal(t, "csv", *hostsImport.Format)
+	require.Contains(t, *hostsImport.Content, "hw-address,01:02:03:04:05:06")
+	require.Equal(t, []int64{1, 2}, hostsImport.DaemonIds)
+	require.EqualValues(t, 10, hostsImport.BatchSize)
+}
+
+// Test that the import fails when the user cannot log in.
+func TestRunHostsImportInvalidCredentials(t *testing.T) {
+	server, _ := newFakeHostsServer(t)
+	defer server.Close()
+	t.Setenv(serverPasswordEnvVar, "wrong")
+
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	file, err := sb.Write("hosts.json", "[]")
+	require.NoError(t, err)
+
+	settings := &hostsImportSettings{
+		ServerSettings: serverSettings{
+			ServerURL: server.URL,
+			User:      "admin",
+		},
+		File:      file,
+		DaemonIDs: []int64{1},
+	}
+	err = runHostsImport(settings)
+	require.ErrorContains(t, err, "Cannot authenticate the user")
+}
+
+// Test that the file format must be specified or recognized from the
+// file extension.
+func TestGetHostsFileFormat(t *testing.T) {
+	format, err := getHostsFileFormat("", "/tmp/hosts.json")
+	require.NoError(t, err)
+	require.EqualValues(t, "json", format)
+
+	format, err = getHostsFileFormat("csv", "/tmp/hosts.txt")
+	require.NoError(t, err)
+	require.EqualValues(t, "csv", format)
+
+	_, err = getHostsFileFormat("", "/tmp/hosts.txt")
+	require.Error(t, err)
+}
+
+// Test that the host reservations are exported to the file.
+func TestRunHostsExport(t *testing.T) {
+	server, _ := newFakeHostsServer(t)
+	defer server.Close()
+	t.Setenv(serverPasswordEnvVar, "secret")
+
+	sb := testutil.NewSandbox()
+	defer sb.Close()
+	file, err := sb.JoinDir("export")
+	require.NoError(t, err)
+	file += "/hosts.json"
+
+	settings := &hostsExportSettings{
+		ServerSettings: serverSettings{
+			ServerURL: server.URL,
+			User:      "admin",
+		},
+		File:     file,
+		Format:   "json",
+		SubnetID: 3,
+	}
+	err = runHostsExport(settings)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"identifier-type": "hw-address", "identifier": "01:02:03:04:05:06"}]`, string(data))
+}
@@ -407,7 +407,7 @@ func newApp() *cli.App {
 	parser.Name = "stork-tool"
 	parser.SubcommandsOptional = true
 	parser.ShortDescription = "A tool for managing Stork Server."
-	parser.LongDescription = `The tool operates in four areas:
+	parser.LongDescription = `The tool operates in six areas:
 
    - Certificate Management - it allows for exporting Stork Server keys, certificates,
      and tokens that are used to secure communication between the Stork Server
@@ -420,7 +420,16 @@ func newApp() *cli.App {
      overwriting the db schema version and getting its current value;
 
    - Static Views Deployment - it allows for setting custom content in selected
-     Stork views (e.g., custom welcome message on the login page).`
+     Stork views (e.g., custom welcome message on the login page);
+
+   - Host Reservations Import and Export - it allows for adding host reservations
+     from a CSV or JSON file to the Kea servers and for exporting the host
+     reservations to such a file using the Stork Server REST API;
+
+   - Configuration Review - it allows for reviewing a Kea, BIND 9 or PowerDNS
+     configuration file with the configuration checkers without the Stork
+     Server, and for printing the review reports as text or in the JSON,
+     SARIF or JUnit XML format.`
 
 	app := cli.NewApp(parser)
 
@@ -569,6 +578,44 @@ func newApp() *cli.App {
 		},
 	)
 
+	// Host reservations import and export commands.
+	hostsImportSettings := &hostsImportSettings{}
+	app.RegisterCommand(
+		"hosts-import", "Import host reservations from a CSV or JSON file",
+		hostsImportSettings, func() {
+			err := runHostsImport(hostsImportSettings)
+			if err != nil {
+				log.WithError(err).Fatal("Failed to import the host reservations")
+			}
+		},
+	)
+
+	hostsExportSettings := &hostsExportSettings{}
+	app.RegisterCommand(
+		"hosts-export", "Export host reservations to a CSV or JSON file",
+		hostsExportSettings, func() {
+			err := runHostsExport(hostsExportSettings)
+			if err != nil {
+				log.WithError(err).Fatal("Failed to export the host reservations")
+			}
+		},
+	)
+
+	// Configuration review command.
+	configLintSettings := &configLintSettings{}
+	app.RegisterCommand(
+		"config-lint", "Review a Kea, BIND 9 or PowerDNS configuration file with the configuration checkers",
+		configLintSettings, func() {
+			issues, err := runConfigLint(configLintSettings)
+			if err != nil {
+				log.WithError(err).Fatal("Failed to review the configuration")
+			}
+			if issues > 0 {
+				log.WithField("issues", issues).Fatal("Configuration review found issues")
+			}
+		},
+	)
+
 	return app
 }
 
@@ -55,6 +55,9 @@ func getExpectedMainFragments() []string {
 		"db-reset",
 		"db-version",
 		"db-set-version",
+		"hosts-import",
+		"hosts-export",
+		"config-lint",
 	}
 }
 
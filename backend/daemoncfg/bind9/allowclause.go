@@ -0,0 +1,42 @@
+package bind9config
+
+var _ formattedElement = (*AllowClause)(nil)
+
+// AllowClause is one of the clauses specifying the clients allowed to
+// query the server: allow-query, allow-query-cache or allow-recursion, or
+// to dynamically update the zones: allow-update.
+//
+// The clause has the following format:
+//
+//	allow-recursion { <address_match_element>; ... };
+//
+// See: https://bind9.readthedocs.io/en/latest/reference.html#namedconf-statement-allow-recursion
+type AllowClause struct {
+	Variant          string            `parser:"@( 'allow-query' | 'allow-query-cache' | 'allow-recursion' | 'allow-update' )"`
+	AddressMatchList *AddressMatchList `parser:"'{' @@ '}'"`
+}
+
+// Checks if the clause includes the specified IP address or ACL name.
+func (a *AllowClause) Includes(ipAddressOrACLName string) bool {
+	if a.AddressMatchList == nil {
+		return false
+	}
+	for _, element := range a.AddressMatchList.Elements {
+		if element.IPAddressOrACLName == ipAddressOrACLName && !element.Negation {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the serialized BIND 9 configuration for the allow clause.
+func (a *AllowClause) getFormattedOutput(filter *Filter) formatterOutput {
+	clause := newFormatterClause(a.Variant)
+	clauseScope := clause.addScope()
+	if a.AddressMatchList != nil {
+		for _, element := range a.AddressMatchList.Elements {
+			clauseScope.add(element.getFormattedOutput(filter))
+		}
+	}
+	return clause
+}
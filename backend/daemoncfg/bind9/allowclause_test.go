@@ -0,0 +1,84 @@
+package bind9config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the allow clauses are parsed in the options and views.
+func TestParseAllowClause(t *testing.T) {
+	config := `
+		options {
+			allow-query { any; };
+			allow-recursion { localhost; !192.0.2.1; };
+			recursion yes;
+		};
+		view "internal" {
+			allow-query-cache { 10.0.0.0/8; };
+			recursion no;
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	options := cfg.GetOptions()
+	require.NotNil(t, options)
+
+	allowQuery := options.GetAllowClause("allow-query")
+	require.NotNil(t, allowQuery)
+	require.True(t, allowQuery.Includes("any"))
+
+	allowRecursion := options.GetAllowClause("allow-recursion")
+	require.NotNil(t, allowRecursion)
+	require.Len(t, allowRecursion.AddressMatchList.Elements, 2)
+	require.True(t, allowRecursion.Includes("localhost"))
+	require.False(t, allowRecursion.Includes("192.0.2.1"))
+
+	require.Nil(t, options.GetAllowClause("allow-query-cache"))
+	require.NotNil(t, options.GetRecursion())
+	require.True(t, *options.GetRecursion())
+
+	view := cfg.GetView("internal")
+	require.NotNil(t, view)
+	allowQueryCache := view.GetAllowClause("allow-query-cache")
+	require.NotNil(t, allowQueryCache)
+	require.True(t, allowQueryCache.Includes("10.0.0.0/8"))
+	require.Nil(t, view.GetAllowClause("allow-query"))
+	require.NotNil(t, view.GetRecursion())
+	require.False(t, *view.GetRecursion())
+}
+
+// Test that the recursion option is nil when it is not specified.
+func TestGetRecursionNotSpecified(t *testing.T) {
+	require.Nil(t, (&Options{}).GetRecursion())
+	require.Nil(t, (&View{}).GetRecursion())
+}
+
+// Test that the allow clause is formatted correctly.
+func TestAllowClauseFormat(t *testing.T) {
+	allowClause := &AllowClause{
+		Variant: "allow-recursion",
+		AddressMatchList: &AddressMatchList{
+			Elements: []*AddressMatchListElement{
+				{
+					IPAddressOrACLName: "127.0.0.1",
+				},
+				{
+					KeyID: "test-key",
+				},
+			},
+		},
+	}
+	output := allowClause.getFormattedOutput(nil)
+	require.NotNil(t, output)
+	requireConfigEq(t, `allow-recursion { "127.0.0.1"; key "test-key"; };`, output)
+}
+
+// Test that serializing the allow clause with nil values does not panic.
+func TestAllowClauseFormatNilValues(t *testing.T) {
+	allowClause := &AllowClause{}
+	require.NotPanics(t, func() { allowClause.getFormattedOutput(nil) })
+}
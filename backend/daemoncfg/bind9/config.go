@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
 
@@ -417,12 +418,179 @@ func (c *Config) GetAXFRCredentials(viewName string, zoneName string) (address s
 	return c.getAXFRCredentialsForDefaultView(zoneName)
 }
 
+// Recursively collects the keys referenced in the address-match-list. If
+// the list contains references to other ACLs, it collects the keys from the
+// referenced ACLs. The negated elements are skipped. It protects against
+// infinite recursion by limiting the depth of the search to 5 levels.
+func (c *Config) getKeysFromAddressMatchList(level int, addressMatchList *AddressMatchList) ([]*Key, error) {
+	if level > 5 {
+		// Too much recursion.
+		return nil, errors.New("too much recursion in address-match-list")
+	}
+	var keys []*Key
+	for _, element := range addressMatchList.Elements {
+		var (
+			nested []*Key
+			err    error
+		)
+		switch {
+		case element.Negation:
+			continue
+		case element.KeyID != "":
+			if key := c.GetKey(element.KeyID); key != nil {
+				keys = append(keys, key)
+			}
+		case element.ACL != nil:
+			nested, err = c.getKeysFromAddressMatchList(level+1, element.ACL.AddressMatchList)
+		case element.IPAddressOrACLName != "":
+			if acl := c.GetACL(element.IPAddressOrACLName); acl != nil {
+				nested, err = c.getKeysFromAddressMatchList(level+1, acl.AddressMatchList)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, nested...)
+	}
+	return keys, nil
+}
+
+// Returns the keys allowed to dynamically update the zone. The zone-level
+// update-policy takes precedence over the allow-update clauses. The
+// allow-update clause may be specified at the zone, view or global level.
+func (c *Config) getUpdateKeys(view *View, zone *Zone) ([]*Key, error) {
+	if updatePolicy := zone.GetUpdatePolicy(); updatePolicy != nil {
+		if updatePolicy.Local {
+			return nil, errors.New("update-policy local allows only the updates signed with the session key")
+		}
+		var keys []*Key
+		for _, identity := range updatePolicy.GetGrantedIdentities() {
+			if key := c.GetKey(identity); key != nil {
+				keys = append(keys, key)
+			}
+		}
+		return keys, nil
+	}
+	allowUpdate := zone.GetAllowClause("allow-update")
+	if allowUpdate == nil && view != nil {
+		allowUpdate = view.GetAllowClause("allow-update")
+	}
+	if allowUpdate == nil {
+		if options := c.GetOptions(); options != nil {
+			allowUpdate = options.GetAllowClause("allow-update")
+		}
+	}
+	if allowUpdate == nil || allowUpdate.AddressMatchList == nil {
+		return nil, errors.New("dynamic updates are disabled")
+	}
+	return c.getKeysFromAddressMatchList(0, allowUpdate.AddressMatchList)
+}
+
+// Gets the port and the TSIG key required to send the dynamic updates
+// (RFC 2136) for the given zone. The zone must be defined in the
+// configuration file in the specified view (or outside of the views for
+// the default view), and it must be a primary zone. The key is discovered
+// from the update-policy or allow-update clauses. Stork requires the
+// updates to be signed, so the function returns an error if the updates
+// are allowed only for the IP addresses.
+//
+// If non-default view is specified and its match-clients clause refers to
+// a key, this key is returned if it is allowed to update the zone. It
+// guarantees that the server selects the view when it receives the update.
+//
+// The port is determined from the listen-on and listen-on-v6 clauses. The
+// default port 53 is preferred if any of the clauses uses it.
+func (c *Config) GetUpdateCredentials(viewName string, zoneName string) (port int64, keyName string, algorithm string, secret string, err error) {
+	var (
+		view *View
+		zone *Zone
+	)
+	if viewName != DefaultViewName {
+		if view = c.GetView(viewName); view == nil {
+			return 0, "", "", "", errors.Errorf("failed to get update credentials for view %s, zone %s: view does not exist", viewName, zoneName)
+		}
+		zone = view.GetZone(zoneName)
+	} else {
+		zone = c.GetZone(zoneName)
+	}
+	if zone == nil {
+		return 0, "", "", "", errors.Errorf("failed to get update credentials for view %s, zone %s: zone does not exist", viewName, zoneName)
+	}
+	if zoneType := zone.GetType(); zoneType != "" && zoneType != "primary" {
+		return 0, "", "", "", errors.Errorf("failed to get update credentials for view %s, zone %s: zone type is %s", viewName, zoneName, zoneType)
+	}
+	keys, err := c.getUpdateKeys(view, zone)
+	if err != nil {
+		return 0, "", "", "", errors.WithMessagef(err, "failed to get update credentials for view %s, zone %s", viewName, zoneName)
+	}
+	if len(keys) == 0 {
+		return 0, "", "", "", errors.Errorf("failed to get update credentials for view %s, zone %s: no key found", viewName, zoneName)
+	}
+	key := keys[0]
+	if view != nil {
+		if matchClients := view.GetMatchClients(); matchClients != nil {
+			viewKey, err := c.getKeyFromAddressMatchList(0, matchClients.AddressMatchList)
+			if err != nil {
+				return 0, "", "", "", errors.WithMessagef(err, "failed to get update credentials for view %s, zone %s", viewName, zoneName)
+			}
+			if viewKey != nil {
+				index := slices.IndexFunc(keys, func(k *Key) bool { return k.Name == viewKey.Name })
+				if index < 0 {
+					return 0, "", "", "", errors.Errorf("failed to get update credentials for view %s, zone %s: key %s selecting the view is not allowed to update the zone", viewName, zoneName, viewKey.Name)
+				}
+				key = keys[index]
+			}
+		}
+	}
+	keyName = key.Name
+	if algorithm, secret, err = key.GetAlgorithmSecret(); err != nil {
+		return 0, "", "", "", errors.WithMessagef(err, "failed to get update credentials for view %s, zone %s", viewName, zoneName)
+	}
+
+	port = 53
+	if options := c.GetOptions(); options != nil {
+		listenOnSet := *options.GetListenOnSet()
+		if len(listenOnSet) > 0 && listenOnSet.GetMatchingListenOnClause(53) == nil {
+			for _, listenOn := range listenOnSet {
+				if !listenOn.Includes("none") {
+					port = listenOn.GetPort()
+					break
+				}
+			}
+		}
+	}
+	return port, keyName, algorithm, secret, nil
+}
+
 // Returns the API key for the statistics channel. This key is included in
 // the X-API-Key header. It is unused for BIND 9.
 func (c *Config) GetAPIKey() string {
 	return ""
 }
 
+// Checks if the zones can be added at runtime with rndc addzone in the
+// specified view (or outside of the views for the default view). The
+// allow-new-zones option specified in the view takes precedence over the
+// global option. The option is disabled by default. It returns an error
+// if the specified view does not exist.
+func (c *Config) IsAllowNewZones(viewName string) (bool, error) {
+	if viewName != DefaultViewName {
+		view := c.GetView(viewName)
+		if view == nil {
+			return false, errors.Errorf("view %s does not exist", viewName)
+		}
+		if allowNewZones := view.GetAllowNewZones(); allowNewZones != nil {
+			return *allowNewZones, nil
+		}
+	}
+	if options := c.GetOptions(); options != nil {
+		if allowNewZones := options.GetAllowNewZones(); allowNewZones != nil {
+			return *allowNewZones, nil
+		}
+	}
+	return false, nil
+}
+
 // Checks if the zone is RPZ.
 func (c *Config) IsRPZ(viewName string, zoneName string) bool {
 	var responsePolicy *ResponsePolicy
@@ -1363,3 +1363,200 @@ func TestConfigGetDirectoryNoOptionsStatement(t *testing.T) {
 	require.NotNil(t, cfg)
 	require.Nil(t, cfg.GetDirectory())
 }
+
+// Test getting the dynamic update credentials from the zone-level
+// allow-update clause.
+func TestGetUpdateCredentialsAllowUpdate(t *testing.T) {
+	config := `
+		key "ddns-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		acl "updaters" { !192.0.2.1; key ddns-key; };
+		zone "example.com" {
+			type master;
+			allow-update { 192.0.2.2; updaters; };
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	port, keyName, algorithm, secret, err := cfg.GetUpdateCredentials(DefaultViewName, "example.com")
+	require.NoError(t, err)
+	require.EqualValues(t, 53, port)
+	require.Equal(t, "ddns-key", keyName)
+	require.Equal(t, "hmac-sha256", algorithm)
+	require.Equal(t, "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=", secret)
+}
+
+// Test that the update-policy clause takes precedence over the global
+// allow-update clause and that the port is taken from the listen-on clause.
+func TestGetUpdateCredentialsUpdatePolicy(t *testing.T) {
+	config := `
+		options {
+			listen-on port 5353 { any; };
+			allow-update { key global-key; };
+		};
+		key "global-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		key "ddns-key" {
+			algorithm hmac-sha512;
+			secret "6L8DwXFboA7FDQJQP051hjFV/n9B3IR/SwDLX7y5czE=";
+		};
+		zone "example.com" {
+			type primary;
+			update-policy {
+				deny global-key zonesub ANY;
+				grant ddns-key zonesub ANY;
+			};
+		};
+		zone "example.org" {
+			type primary;
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	port, keyName, algorithm, secret, err := cfg.GetUpdateCredentials(DefaultViewName, "example.com")
+	require.NoError(t, err)
+	require.EqualValues(t, 5353, port)
+	require.Equal(t, "ddns-key", keyName)
+	require.Equal(t, "hmac-sha512", algorithm)
+	require.Equal(t, "6L8DwXFboA7FDQJQP051hjFV/n9B3IR/SwDLX7y5czE=", secret)
+
+	// The global allow-update clause applies to the other zone.
+	_, keyName, _, _, err = cfg.GetUpdateCredentials(DefaultViewName, "example.org")
+	require.NoError(t, err)
+	require.Equal(t, "global-key", keyName)
+}
+
+// Test that the key selecting the view is used to update the zone in
+// this view.
+func TestGetUpdateCredentialsForView(t *testing.T) {
+	config := `
+		key "trusted-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		key "ddns-key" {
+			algorithm hmac-sha256;
+			secret "6L8DwXFboA7FDQJQP051hjFV/n9B3IR/SwDLX7y5czE=";
+		};
+		view "trusted" {
+			match-clients { key trusted-key; };
+			allow-update { key ddns-key; key trusted-key; };
+			zone "example.com" {
+				type primary;
+			};
+			zone "example.org" {
+				type primary;
+				allow-update { key ddns-key; };
+			};
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	_, keyName, _, _, err := cfg.GetUpdateCredentials("trusted", "example.com")
+	require.NoError(t, err)
+	require.Equal(t, "trusted-key", keyName)
+
+	_, _, _, _, err = cfg.GetUpdateCredentials("trusted", "example.org")
+	require.ErrorContains(t, err, "key trusted-key selecting the view is not allowed to update the zone")
+}
+
+// Test the errors returned when the dynamic updates are not possible.
+func TestGetUpdateCredentialsErrors(t *testing.T) {
+	config := `
+		key "ddns-key" {
+			algorithm hmac-sha256;
+			secret "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU=";
+		};
+		zone "disabled.example.com" {
+			type primary;
+		};
+		zone "local.example.com" {
+			type primary;
+			update-policy local;
+		};
+		zone "address.example.com" {
+			type primary;
+			allow-update { 192.0.2.1; };
+		};
+		zone "secondary.example.com" {
+			type secondary;
+			allow-update { key ddns-key; };
+		};
+		view "trusted" {
+			zone "example.com" {
+				type primary;
+			};
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	_, _, _, _, err = cfg.GetUpdateCredentials(DefaultViewName, "disabled.example.com")
+	require.ErrorContains(t, err, "dynamic updates are disabled")
+
+	_, _, _, _, err = cfg.GetUpdateCredentials(DefaultViewName, "local.example.com")
+	require.ErrorContains(t, err, "update-policy local")
+
+	_, _, _, _, err = cfg.GetUpdateCredentials(DefaultViewName, "address.example.com")
+	require.ErrorContains(t, err, "no key found")
+
+	_, _, _, _, err = cfg.GetUpdateCredentials(DefaultViewName, "secondary.example.com")
+	require.ErrorContains(t, err, "zone type is secondary")
+
+	_, _, _, _, err = cfg.GetUpdateCredentials(DefaultViewName, "example.org")
+	require.ErrorContains(t, err, "zone does not exist")
+
+	_, _, _, _, err = cfg.GetUpdateCredentials("guest", "example.com")
+	require.ErrorContains(t, err, "view does not exist")
+}
+
+// Test checking if the new zones can be added in the views.
+func TestIsAllowNewZones(t *testing.T) {
+	config := `
+		options {
+			allow-new-zones yes;
+		};
+		view "trusted" {
+			allow-new-zones no;
+		};
+		view "guest" {
+			match-clients { any; };
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	allowed, err := cfg.IsAllowNewZones(DefaultViewName)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// The view setting takes precedence.
+	allowed, err = cfg.IsAllowNewZones("trusted")
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// The global setting is inherited.
+	allowed, err = cfg.IsAllowNewZones("guest")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	_, err = cfg.IsAllowNewZones("other")
+	require.ErrorContains(t, err, "view other does not exist")
+}
+
+// Test that the new zones are not allowed by default.
+func TestIsAllowNewZonesDefault(t *testing.T) {
+	cfg, err := NewParser().Parse("", "", strings.NewReader(`options { directory "/var/cache/bind"; };`))
+	require.NoError(t, err)
+
+	allowed, err := cfg.IsAllowNewZones(DefaultViewName)
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
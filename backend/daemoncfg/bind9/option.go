@@ -37,6 +37,25 @@ func (o *Option) getFormattedOutput(filter *Filter) formatterOutput {
 	return clause
 }
 
+// Returns the boolean value of the option's first switch (e.g., recursion
+// no). It returns nil if the option has no switches or the switch is not
+// a boolean value.
+func (o *Option) getBoolSwitch() *bool {
+	if len(o.Switches) == 0 {
+		return nil
+	}
+	var value bool
+	switch o.Switches[0].GetStringValue() {
+	case "yes", "true", "1":
+		value = true
+	case "no", "false", "0":
+		value = false
+	default:
+		return nil
+	}
+	return &value
+}
+
 // An option switch is a string or identifier switch.
 // TODO: Remove it and migrate to String: see https://gitlab.isc.org/isc-projects/stork/-/issues/2459.
 type OptionSwitch struct {
@@ -10,6 +10,9 @@ type OptionClause struct {
 	NoParse *NoParse `parser:"@@"`
 	// The allow-transfer clause restricting who can perform AXFR.
 	AllowTransfer *AllowTransfer `parser:"| 'allow-transfer' @@"`
+	// The allow-query, allow-query-cache or allow-recursion clause
+	// restricting who can query the server.
+	AllowClause *AllowClause `parser:"| @@"`
 	// The directory clause specifying absolute path prepended to all
 	// relative paths in the configuration.
 	Directory *Directory `parser:"| 'directory' @@"`
@@ -83,6 +83,37 @@ func (o *Options) GetAllowTransfer() *AllowTransfer {
 	return nil
 }
 
+// Gets the allow-query, allow-query-cache or allow-recursion clause from
+// options or nil if it is not found.
+func (o *Options) GetAllowClause(variant string) *AllowClause {
+	for _, clause := range o.Clauses {
+		if clause.AllowClause != nil && clause.AllowClause.Variant == variant {
+			return clause.AllowClause
+		}
+	}
+	return nil
+}
+
+// Gets the value of the recursion option or nil if it is not specified.
+func (o *Options) GetRecursion() *bool {
+	for _, clause := range o.Clauses {
+		if clause.Option != nil && clause.Option.Identifier == "recursion" {
+			return clause.Option.getBoolSwitch()
+		}
+	}
+	return nil
+}
+
+// Gets the value of the allow-new-zones option or nil if it is not specified.
+func (o *Options) GetAllowNewZones() *bool {
+	for _, clause := range o.Clauses {
+		if clause.Option != nil && clause.Option.Identifier == "allow-new-zones" {
+			return clause.Option.getBoolSwitch()
+		}
+	}
+	return nil
+}
+
 // Gets the listen-on and listen-on-v6 clauses from options. The result is
 // combined into a single slice.
 func (o *Options) GetListenOnSet() *ListenOnClauses {
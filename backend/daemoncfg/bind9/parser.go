@@ -144,3 +144,17 @@ func (p *Parser) ParseFile(filename string, chrootDir string) (*Config, error) {
 func (p *Parser) Parse(filename string, chrootDir string, fileReader io.Reader) (*Config, error) {
 	return p.parse(filename, chrootDir, fileReader, bind9Parser)
 }
+
+// Parses a single zone statement. It is used to validate the zone
+// definitions specified by a user before they are sent to the server.
+// It returns an error if the text contains anything but the zone statement.
+func (p *Parser) ParseZone(zoneText string) (*Zone, error) {
+	config, err := bind9Parser.ParseString("", zoneText)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse zone statement")
+	}
+	if len(config.Statements) != 1 || config.Statements[0].Zone == nil {
+		return nil, errors.New("expected exactly one zone statement")
+	}
+	return config.Statements[0].Zone, nil
+}
@@ -86,8 +86,11 @@ func testParsedConfig(t *testing.T, cfg *Config) {
 	statement, _ = next()
 	require.NotNil(t, statement.Options)
 	require.Len(t, statement.Options.Clauses, 11)
-	require.NotNil(t, statement.Options.Clauses[0].Option)
-	require.Equal(t, "allow-query", statement.Options.Clauses[0].Option.Identifier)
+	require.NotNil(t, statement.Options.Clauses[0].AllowClause)
+	require.Equal(t, "allow-query", statement.Options.Clauses[0].AllowClause.Variant)
+	require.NotNil(t, statement.Options.Clauses[0].AllowClause.AddressMatchList)
+	require.Len(t, statement.Options.Clauses[0].AllowClause.AddressMatchList.Elements, 1)
+	require.True(t, statement.Options.Clauses[0].AllowClause.Includes("any"))
 	require.NotNil(t, statement.Options.Clauses[1].AllowTransfer)
 	require.Nil(t, statement.Options.Clauses[1].AllowTransfer.Port)
 	require.Nil(t, statement.Options.Clauses[1].AllowTransfer.Transport)
@@ -1664,3 +1667,25 @@ func TestParseLoggingCategoryEmpty(t *testing.T) {
 	require.Equal(t, "xfer-out", cfg.Statements[0].Logging.Clauses[0].Category.Name.GetValue())
 	require.Empty(t, cfg.Statements[0].Logging.Clauses[0].Category.Channels)
 }
+
+// Test parsing a single zone statement.
+func TestParseZone(t *testing.T) {
+	zone, err := NewParser().ParseZone(`zone "example.com" { type secondary; primaries { 192.0.2.1; }; };`)
+	require.NoError(t, err)
+	require.NotNil(t, zone)
+	require.Equal(t, "example.com", zone.Name)
+	require.Equal(t, "secondary", zone.GetType())
+}
+
+// Test that parsing the zone statement fails when the text contains
+// other statements or is malformed.
+func TestParseZoneInvalid(t *testing.T) {
+	_, err := NewParser().ParseZone(`zone "example.com" { type primary; file "db.example.com"; }; options { allow-new-zones yes; };`)
+	require.ErrorContains(t, err, "expected exactly one zone statement")
+
+	_, err = NewParser().ParseZone(`options { allow-new-zones yes; };`)
+	require.ErrorContains(t, err, "expected exactly one zone statement")
+
+	_, err = NewParser().ParseZone(`zone "example.com" { type primary;`)
+	require.ErrorContains(t, err, "failed to parse zone statement")
+}
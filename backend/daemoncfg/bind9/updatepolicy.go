@@ -0,0 +1,68 @@
+package bind9config
+
+var (
+	_ formattedElement = (*UpdatePolicy)(nil)
+	_ formattedElement = (*UpdatePolicyRule)(nil)
+)
+
+// UpdatePolicy is the zone clause specifying the rules for the dynamic
+// updates (RFC 2136). It is either the local policy allowing the updates
+// signed with the session key generated by named or a list of rules.
+//
+// The update-policy clause has the following format:
+//
+//	update-policy ( local | { ( grant | deny ) <string> <rule-type> [ <string> ] <rrtypelist>; ... } );
+//
+// See: https://bind9.readthedocs.io/en/latest/reference.html#namedconf-statement-update-policy
+type UpdatePolicy struct {
+	Local bool                `parser:"( @'local'"`
+	Rules []*UpdatePolicyRule `parser:"| '{' ( @@ ';'+ )* '}' )"`
+}
+
+// Returns the names of the keys granted to update the zone. The rules of
+// the local policy are not specified explicitly, so no key names are
+// returned in this case. The identities of the deny rules are skipped.
+func (up *UpdatePolicy) GetGrantedIdentities() (identities []string) {
+	for _, rule := range up.Rules {
+		if rule.Permission == "grant" {
+			identities = append(identities, rule.Identity)
+		}
+	}
+	return
+}
+
+// Returns the serialized BIND 9 configuration for the update-policy clause.
+func (up *UpdatePolicy) getFormattedOutput(filter *Filter) formatterOutput {
+	clause := newFormatterClause("update-policy")
+	if up.Local {
+		clause.addToken("local")
+		return clause
+	}
+	scope := clause.addScope()
+	for _, rule := range up.Rules {
+		scope.add(rule.getFormattedOutput(filter))
+	}
+	return clause
+}
+
+// UpdatePolicyRule is a single rule of the update-policy clause. The
+// identity is typically a key name. The rule type (e.g., name, zonesub)
+// specifies how the arguments are interpreted. The arguments are the
+// optional name and the list of RR types the rule applies to.
+type UpdatePolicyRule struct {
+	Permission string   `parser:"@( 'grant' | 'deny' )"`
+	Identity   string   `parser:"( @String | @Ident )"`
+	RuleType   string   `parser:"@Ident"`
+	Arguments  []string `parser:"( @String | @Ident )*"`
+}
+
+// Returns the serialized BIND 9 configuration for the update-policy rule.
+func (upr *UpdatePolicyRule) getFormattedOutput(filter *Filter) formatterOutput {
+	clause := newFormatterClause(upr.Permission)
+	clause.addQuotedToken(upr.Identity)
+	clause.addToken(upr.RuleType)
+	for _, argument := range upr.Arguments {
+		clause.addToken(argument)
+	}
+	return clause
+}
@@ -0,0 +1,86 @@
+package bind9config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the update-policy clause with the rules is parsed and the
+// granted identities are returned.
+func TestParseUpdatePolicy(t *testing.T) {
+	config := `
+		zone "example.com" {
+			type primary;
+			update-policy {
+				grant ddns-key zonesub ANY;
+				deny "guest-key" name www.example.com. A AAAA;
+				grant admin-key. name host.example.com.;
+			};
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	zone := cfg.GetZone("example.com")
+	require.NotNil(t, zone)
+	updatePolicy := zone.GetUpdatePolicy()
+	require.NotNil(t, updatePolicy)
+	require.False(t, updatePolicy.Local)
+	require.Len(t, updatePolicy.Rules, 3)
+
+	require.Equal(t, "deny", updatePolicy.Rules[1].Permission)
+	require.Equal(t, "guest-key", updatePolicy.Rules[1].Identity)
+	require.Equal(t, "name", updatePolicy.Rules[1].RuleType)
+	require.Equal(t, []string{"www.example.com.", "A", "AAAA"}, updatePolicy.Rules[1].Arguments)
+
+	require.Equal(t, []string{"ddns-key", "admin-key."}, updatePolicy.GetGrantedIdentities())
+}
+
+// Test that the local update-policy is parsed.
+func TestParseUpdatePolicyLocal(t *testing.T) {
+	config := `
+		zone "example.com" {
+			update-policy local;
+		};
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+
+	updatePolicy := cfg.GetZone("example.com").GetUpdatePolicy()
+	require.NotNil(t, updatePolicy)
+	require.True(t, updatePolicy.Local)
+	require.Empty(t, updatePolicy.GetGrantedIdentities())
+}
+
+// Test formatting the update-policy clause.
+func TestUpdatePolicyFormat(t *testing.T) {
+	updatePolicy := &UpdatePolicy{
+		Rules: []*UpdatePolicyRule{
+			{
+				Permission: "grant",
+				Identity:   "ddns-key",
+				RuleType:   "zonesub",
+				Arguments:  []string{"ANY"},
+			},
+			{
+				Permission: "deny",
+				Identity:   "guest-key",
+				RuleType:   "name",
+				Arguments:  []string{"www.example.com.", "A"},
+			},
+		},
+	}
+	output := updatePolicy.getFormattedOutput(nil)
+	require.NotNil(t, output)
+	requireConfigEq(t, `
+		update-policy {
+			grant "ddns-key" zonesub ANY;
+			deny "guest-key" name www.example.com. A;
+		};
+	`, output)
+
+	output = (&UpdatePolicy{Local: true}).getFormattedOutput(nil)
+	requireConfigEq(t, `update-policy local;`, output)
+}
@@ -41,6 +41,9 @@ type ViewClause struct {
 	MatchClients *MatchClients `parser:"| 'match-clients' @@" filter:"view"`
 	// The allow-transfer clause restricting who can perform AXFR.
 	AllowTransfer *AllowTransfer `parser:"| 'allow-transfer' @@" filter:"view"`
+	// The allow-query, allow-query-cache or allow-recursion clause
+	// restricting who can query the server within the view.
+	AllowClause *AllowClause `parser:"| @@" filter:"view"`
 	// The response-policy clause specifying the response policy zones.
 	ResponsePolicy *ResponsePolicy `parser:"| 'response-policy' @@" filter:"view"`
 	// The zone clause associating the zone with a view.
@@ -115,6 +118,39 @@ func (v *View) GetResponsePolicy() *ResponsePolicy {
 	return v.responsePolicy
 }
 
+// Returns the allow-query, allow-query-cache or allow-recursion clause for
+// the view or nil if it is not found.
+func (v *View) GetAllowClause(variant string) *AllowClause {
+	for _, clause := range v.Clauses {
+		if clause.AllowClause != nil && clause.AllowClause.Variant == variant {
+			return clause.AllowClause
+		}
+	}
+	return nil
+}
+
+// Returns the value of the recursion option for the view or nil if it is
+// not specified.
+func (v *View) GetRecursion() *bool {
+	for _, clause := range v.Clauses {
+		if clause.Option != nil && clause.Option.Identifier == "recursion" {
+			return clause.Option.getBoolSwitch()
+		}
+	}
+	return nil
+}
+
+// Returns the value of the allow-new-zones option for the view or nil if
+// it is not specified.
+func (v *View) GetAllowNewZones() *bool {
+	for _, clause := range v.Clauses {
+		if clause.Option != nil && clause.Option.Identifier == "allow-new-zones" {
+			return clause.Option.getBoolSwitch()
+		}
+	}
+	return nil
+}
+
 // Returns the zone with the specified name or nil if the zone is not found.
 func (v *View) GetZone(zoneName string) *Zone {
 	for _, clause := range v.Clauses {
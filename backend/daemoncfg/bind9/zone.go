@@ -1,5 +1,12 @@
 package bind9config
 
+import (
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 var (
 	_ formattedElement = (*Zone)(nil)
 	_ formattedElement = (*ZoneClause)(nil)
@@ -57,11 +64,121 @@ func (z *Zone) GetAllowTransfer() *AllowTransfer {
 	return nil
 }
 
+// Returns the allow clause of the specified variant (e.g., allow-update)
+// for the zone or nil if it is not found.
+func (z *Zone) GetAllowClause(variant string) *AllowClause {
+	for _, clause := range z.Clauses {
+		if clause.AllowClause != nil && clause.AllowClause.Variant == variant {
+			return clause.AllowClause
+		}
+	}
+	return nil
+}
+
+// Returns the update-policy clause for the zone or nil if it is not found.
+func (z *Zone) GetUpdatePolicy() *UpdatePolicy {
+	for _, clause := range z.Clauses {
+		if clause.UpdatePolicy != nil {
+			return clause.UpdatePolicy
+		}
+	}
+	return nil
+}
+
+// Returns the zone type (e.g., primary, secondary) or an empty string if
+// the type is not specified. The deprecated master and slave types are
+// returned as primary and secondary respectively.
+func (z *Zone) GetType() string {
+	for _, clause := range z.Clauses {
+		if clause.Option != nil && clause.Option.Identifier == "type" && len(clause.Option.Switches) > 0 {
+			switch zoneType := clause.Option.Switches[0].GetStringValue(); zoneType {
+			case "master":
+				return "primary"
+			case "slave":
+				return "secondary"
+			default:
+				return zoneType
+			}
+		}
+	}
+	return ""
+}
+
+// Returns the option with the specified identifier or nil if the option
+// is not found.
+func (z *Zone) getOption(identifiers ...string) *Option {
+	for _, clause := range z.Clauses {
+		if clause.Option != nil && slices.Contains(identifiers, clause.Option.Identifier) {
+			return clause.Option
+		}
+	}
+	return nil
+}
+
+// Checks if the zone can be added to the server with rndc addzone or
+// modified with rndc modzone. Only the primary and secondary zones are
+// supported. The primary zone must specify the file. The secondary zone
+// must specify the primary servers. The zones included in other views
+// (in-view) and with the no-parse directives are not supported.
+func (z *Zone) ValidateForProvisioning() error {
+	if z.HasNoParse() {
+		return errors.Errorf("zone %s contains no-parse directives", z.Name)
+	}
+	if z.getOption("in-view") != nil {
+		return errors.Errorf("zone %s must not contain the in-view clause", z.Name)
+	}
+	switch zoneType := z.GetType(); zoneType {
+	case "primary":
+		if z.getOption("file") == nil {
+			return errors.Errorf("primary zone %s must specify the file", z.Name)
+		}
+	case "secondary":
+		if z.getOption("primaries", "masters") == nil {
+			return errors.Errorf("secondary zone %s must specify the primaries", z.Name)
+		}
+	case "":
+		return errors.Errorf("zone %s must specify the type", z.Name)
+	default:
+		return errors.Errorf("zone %s has unsupported type %s; it must be primary or secondary", z.Name, zoneType)
+	}
+	return nil
+}
+
+// Returns the zone clauses serialized in a single line, e.g.:
+//
+//	{ type secondary; primaries { 192.0.2.1; }; };
+//
+// This is the format of the zone configuration expected by the rndc
+// addzone and modzone commands.
+func (z *Zone) GetFormattedClauses() (string, error) {
+	clause := newFormatterClause()
+	scope := clause.addScope()
+	for _, zoneClause := range z.Clauses {
+		scope.add(zoneClause.getFormattedOutput(nil))
+	}
+	formatter := newFormatter(0)
+	formatter.addClause(clause)
+	var lines []string
+	if err := formatter.getFormattedTextFunc(func(line string) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, " "), nil
+}
+
 // ZoneClause is a single clause of a zone statement.
 type ZoneClause struct {
 	NoParse *NoParse `parser:"@@"`
 	// The allow-transfer clause restricting who can perform AXFR.
 	AllowTransfer *AllowTransfer `parser:"| 'allow-transfer' @@"`
+	// The allow-query or allow-update clause restricting who can query
+	// or dynamically update the zone.
+	AllowClause *AllowClause `parser:"| @@"`
+	// The update-policy clause specifying the rules for the dynamic updates.
+	UpdatePolicy *UpdatePolicy `parser:"| 'update-policy' @@"`
 	// Any option clause.
 	Option *Option `parser:"| @@"`
 }
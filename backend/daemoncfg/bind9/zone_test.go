@@ -1,6 +1,7 @@
 package bind9config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -80,6 +81,24 @@ func TestZoneGetFormattedOutput(t *testing.T) {
 	};`, output)
 }
 
+// Test getting the zone type.
+func TestZoneGetType(t *testing.T) {
+	config := `
+		zone "primary.example.com" { type primary; };
+		zone "master.example.com" { type master; };
+		zone "slave.example.com" { type slave; };
+		zone "forward.example.com" { type forward; };
+		zone "untyped.example.com" { file "db.untyped"; };
+	`
+	cfg, err := NewParser().Parse("", "", strings.NewReader(config))
+	require.NoError(t, err)
+	require.Equal(t, "primary", cfg.GetZone("primary.example.com").GetType())
+	require.Equal(t, "primary", cfg.GetZone("master.example.com").GetType())
+	require.Equal(t, "secondary", cfg.GetZone("slave.example.com").GetType())
+	require.Equal(t, "forward", cfg.GetZone("forward.example.com").GetType())
+	require.Empty(t, cfg.GetZone("untyped.example.com").GetType())
+}
+
 // Test that serializing a zone with nil values does not panic.
 func TestZoneFormatNilValues(t *testing.T) {
 	zone := &Zone{}
@@ -91,3 +110,78 @@ func TestZoneClauseFormatNilValues(t *testing.T) {
 	zoneClause := &ZoneClause{}
 	require.NotPanics(t, func() { zoneClause.getFormattedOutput(nil) })
 }
+
+// Test getting the allow clauses from the zone.
+func TestZoneGetAllowClause(t *testing.T) {
+	zone := &Zone{
+		Clauses: []*ZoneClause{
+			{
+				AllowClause: &AllowClause{
+					Variant: "allow-query",
+				},
+			},
+			{
+				AllowClause: &AllowClause{
+					Variant: "allow-update",
+				},
+			},
+		},
+	}
+	allowUpdate := zone.GetAllowClause("allow-update")
+	require.NotNil(t, allowUpdate)
+	require.Equal(t, "allow-update", allowUpdate.Variant)
+	require.Nil(t, zone.GetAllowClause("allow-query-cache"))
+	require.Nil(t, zone.GetUpdatePolicy())
+}
+
+// Test validating the zones to be added with rndc addzone.
+func TestZoneValidateForProvisioning(t *testing.T) {
+	testCases := []struct {
+		name  string
+		zone  string
+		error string
+	}{
+		{"primary", `zone "example.com" { type primary; file "db.example.com"; };`, ""},
+		{"master", `zone "example.com" { type master; file "db.example.com"; };`, ""},
+		{"secondary", `zone "example.com" { type secondary; primaries { 192.0.2.1; }; };`, ""},
+		{"slave", `zone "example.com" { type slave; masters { 192.0.2.1; }; };`, ""},
+		{"no type", `zone "example.com" { file "db.example.com"; };`, "must specify the type"},
+		{"unsupported type", `zone "example.com" { type forward; forwarders { 192.0.2.1; }; };`, "unsupported type forward"},
+		{"primary without file", `zone "example.com" { type primary; };`, "must specify the file"},
+		{"secondary without primaries", `zone "example.com" { type secondary; file "db.example.com"; };`, "must specify the primaries"},
+		{"in-view", `zone "example.com" { type primary; file "db.example.com"; in-view "trusted"; };`, "must not contain the in-view clause"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			zone, err := NewParser().ParseZone(testCase.zone)
+			require.NoError(t, err)
+			err = zone.ValidateForProvisioning()
+			if testCase.error == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, testCase.error)
+			}
+		})
+	}
+}
+
+// Test serializing the zone clauses in the format used by rndc addzone.
+func TestZoneGetFormattedClauses(t *testing.T) {
+	zone, err := NewParser().ParseZone(`
+		zone "example.com" {
+			type secondary;
+			primaries { 192.0.2.1; 192.0.2.2 port 5353; };
+			allow-transfer { key "xfr-key"; };
+		};
+	`)
+	require.NoError(t, err)
+
+	clauses, err := zone.GetFormattedClauses()
+	require.NoError(t, err)
+	require.Equal(t, `{ type secondary; primaries { 192.0.2.1; 192.0.2.2 port 5353; }; allow-transfer { key "xfr-key"; }; };`, clauses)
+
+	// The serialized clauses should be parsable.
+	parsed, err := NewParser().ParseZone(`zone "example.com" ` + clauses)
+	require.NoError(t, err)
+	require.Equal(t, "secondary", parsed.GetType())
+}
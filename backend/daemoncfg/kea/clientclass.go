@@ -1,9 +1,79 @@
 package keaconfig
 
-// Represents a client class in Kea configuration.
-// todo: it currently only contains class name because it is
-// all we need for current use cases. It will have extra fields
-// when we need them.
+import "encoding/json"
+
+// Represents known (supported by Stork) configuration parameters of a
+// client class in Kea. The next-server, server-hostname and
+// boot-file-name parameters are only valid for the DHCPv4 server. The
+// preferred lifetime parameters are only valid for the DHCPv6 server.
+type ClientClassKnownParameters struct {
+	PreferredLifetimeParameters
+	ValidLifetimeParameters
+	Name                 string             `json:"name"`
+	Test                 *string            `json:"test,omitempty"`
+	TemplateTest         *string            `json:"template-test,omitempty"`
+	OnlyIfRequired       *bool              `json:"only-if-required,omitempty"`
+	OnlyInAdditionalList *bool              `json:"only-in-additional-list,omitempty"`
+	OptionData           []SingleOptionData `json:"option-data,omitempty"`
+	NextServer           *string            `json:"next-server,omitempty"`
+	ServerHostname       *string            `json:"server-hostname,omitempty"`
+	BootFileName         *string            `json:"boot-file-name,omitempty"`
+	OfferLifetime        *int64             `json:"offer-lifetime,omitempty"`
+	UserContext          map[string]any     `json:"user-context,omitempty"`
+}
+
+// Represents a client class in Kea. It holds a structure with known
+// (supported by Stork) configuration parameters, and a map of unknown
+// (unsupported by Stork) configuration parameters.
 type ClientClass struct {
-	Name string `json:"name"`
+	ClientClassKnownParameters
+	UnknownParameters map[string]any `json:"-"`
+}
+
+// Unmarshals the JSON data into the ClientClass structure. The output
+// contains the known parameters and a map of unknown parameters.
+func (c *ClientClass) UnmarshalJSON(data []byte) error {
+	classWithUnknown := WithUnknown[ClientClassKnownParameters]{}
+	if err := json.Unmarshal(data, &classWithUnknown); err != nil {
+		return err
+	}
+	*c = ClientClass{
+		ClientClassKnownParameters: classWithUnknown.Known,
+		UnknownParameters:          classWithUnknown.Unknown,
+	}
+	return nil
+}
+
+// Marshals the ClientClass structure into JSON. The output contains the
+// known parameters and a map of unknown parameters.
+func (c ClientClass) MarshalJSON() ([]byte, error) {
+	classWithUnknown := WithUnknown[ClientClassKnownParameters]{
+		Known:   c.ClientClassKnownParameters,
+		Unknown: c.UnknownParameters,
+	}
+	return json.Marshal(classWithUnknown)
+}
+
+// Returns the test expression of the class or an empty string if the
+// class has no test expression.
+func (c ClientClass) GetTest() string {
+	if c.Test == nil {
+		return ""
+	}
+	return *c.Test
+}
+
+// Checks if the class is a template class, i.e., a class spawning
+// subclasses using the template-test expression.
+func (c ClientClass) IsTemplate() bool {
+	return c.TemplateTest != nil
+}
+
+// Checks if the class is only evaluated when explicitly required by
+// a subnet, shared network or pool. Older Kea versions use the
+// only-if-required parameter and newer ones use the
+// only-in-additional-list parameter for this purpose.
+func (c ClientClass) IsOnlyInAdditionalList() bool {
+	return (c.OnlyIfRequired != nil && *c.OnlyIfRequired) ||
+		(c.OnlyInAdditionalList != nil && *c.OnlyInAdditionalList)
 }
@@ -0,0 +1,126 @@
+package keaconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	storkutil "isc.org/stork/util"
+)
+
+// Test that a client class with all supported parameters is parsed.
+func TestParseClientClass(t *testing.T) {
+	input := `{
+		"name": "foo",
+		"test": "member('HA_server1')",
+		"only-if-required": true,
+		"option-data": [
+			{
+				"name": "domain-name-servers",
+				"data": "192.0.2.1"
+			}
+		],
+		"next-server": "192.0.2.2",
+		"server-hostname": "hal9000",
+		"boot-file-name": "/dev/null",
+		"valid-lifetime": 1000,
+		"min-valid-lifetime": 500,
+		"max-valid-lifetime": 2000,
+		"offer-lifetime": 60,
+		"user-context": {
+			"comment": "class foo"
+		}
+	}`
+	var class ClientClass
+	err := json.Unmarshal([]byte(input), &class)
+	require.NoError(t, err)
+	require.Equal(t, "foo", class.Name)
+	require.Equal(t, "member('HA_server1')", class.GetTest())
+	require.True(t, class.IsOnlyInAdditionalList())
+	require.False(t, class.IsTemplate())
+	require.Len(t, class.OptionData, 1)
+	require.Equal(t, "domain-name-servers", class.OptionData[0].Name)
+	require.Equal(t, "192.0.2.1", class.OptionData[0].Data)
+	require.Equal(t, "192.0.2.2", *class.NextServer)
+	require.Equal(t, "hal9000", *class.ServerHostname)
+	require.Equal(t, "/dev/null", *class.BootFileName)
+	require.EqualValues(t, 1000, *class.ValidLifetime)
+	require.EqualValues(t, 500, *class.MinValidLifetime)
+	require.EqualValues(t, 2000, *class.MaxValidLifetime)
+	require.EqualValues(t, 60, *class.OfferLifetime)
+	require.Nil(t, class.PreferredLifetime)
+	require.Equal(t, "class foo", class.UserContext["comment"])
+	require.Empty(t, class.UnknownParameters)
+}
+
+// Test that a DHCPv6 client class with the template test and the
+// preferred lifetimes is parsed.
+func TestParseClientClass6(t *testing.T) {
+	input := `{
+		"name": "bar",
+		"template-test": "substring(option[1].hex, 0, 4)",
+		"only-in-additional-list": false,
+		"preferred-lifetime": 100,
+		"min-preferred-lifetime": 50,
+		"max-preferred-lifetime": 200
+	}`
+	var class ClientClass
+	err := json.Unmarshal([]byte(input), &class)
+	require.NoError(t, err)
+	require.Equal(t, "bar", class.Name)
+	require.Empty(t, class.GetTest())
+	require.True(t, class.IsTemplate())
+	require.False(t, class.IsOnlyInAdditionalList())
+	require.EqualValues(t, 100, *class.PreferredLifetime)
+	require.EqualValues(t, 50, *class.MinPreferredLifetime)
+	require.EqualValues(t, 200, *class.MaxPreferredLifetime)
+}
+
+// Test that unknown client class parameters are parsed correctly.
+func TestParseClientClassWithUnknownParameters(t *testing.T) {
+	input := `{
+		"name": "foo",
+		"option-def": [
+			{
+				"name": "configfile",
+				"code": 224,
+				"type": "string"
+			}
+		],
+		"comment": "baz"
+	}`
+	var class ClientClass
+	err := json.Unmarshal([]byte(input), &class)
+	require.NoError(t, err)
+	require.Equal(t, "foo", class.Name)
+	require.Len(t, class.UnknownParameters, 2)
+	require.Contains(t, class.UnknownParameters, "option-def")
+	require.Equal(t, "baz", class.UnknownParameters["comment"])
+}
+
+// Test that a client class is marshalled together with the unknown
+// parameters.
+func TestMarshalClientClassWithUnknownParameters(t *testing.T) {
+	class := ClientClass{
+		ClientClassKnownParameters: ClientClassKnownParameters{
+			Name: "foo",
+			Test: storkutil.Ptr("option[93].hex == 0x0009"),
+			ValidLifetimeParameters: ValidLifetimeParameters{
+				ValidLifetime: storkutil.Ptr(int64(3600)),
+			},
+			NextServer: storkutil.Ptr("192.0.2.1"),
+		},
+		UnknownParameters: map[string]any{
+			"comment": "baz",
+		},
+	}
+	marshalled, err := json.Marshal(class)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "foo",
+		"test": "option[93].hex == 0x0009",
+		"valid-lifetime": 3600,
+		"next-server": "192.0.2.1",
+		"comment": "baz"
+	}`, string(marshalled))
+}
@@ -1,5 +1,7 @@
 package keaconfig
 
+import "slices"
+
 var _ commonConfigAccessor = (*D2Config)(nil)
 
 // Represents a D2 (DHCP-DDNS) Kea configuration.
@@ -9,6 +11,38 @@ type D2Config struct {
 	// Replaced by ControlSockets in Kea 2.7.2.
 	ControlSocket  *ControlSocket  `json:"control-socket,omitempty"`
 	ControlSockets []ControlSocket `json:"control-sockets,omitempty"`
+	ForwardDDNS    *DDNSDomains    `json:"forward-ddns,omitempty"`
+	ReverseDDNS    *DDNSDomains    `json:"reverse-ddns,omitempty"`
+	TSIGKeys       []TSIGKey       `json:"tsig-keys,omitempty"`
+}
+
+// Represents a list of the forward or reverse DDNS domains.
+type DDNSDomains struct {
+	DDNSDomains []DDNSDomain `json:"ddns-domains,omitempty"`
+}
+
+// Represents a DDNS domain for which D2 sends the DNS updates.
+type DDNSDomain struct {
+	Name       string      `json:"name"`
+	KeyName    *string     `json:"key-name,omitempty"`
+	DNSServers []DNSServer `json:"dns-servers,omitempty"`
+}
+
+// Represents a DNS server to which D2 sends the DNS updates for a
+// DDNS domain.
+type DNSServer struct {
+	Hostname  *string `json:"hostname,omitempty"`
+	IPAddress *string `json:"ip-address,omitempty"`
+	Port      *int64  `json:"port,omitempty"`
+	KeyName   *string `json:"key-name,omitempty"`
+}
+
+// Represents a TSIG key used by D2 to sign the DNS updates. The key
+// secret is intentionally not parsed.
+type TSIGKey struct {
+	Name       string `json:"name"`
+	Algorithm  string `json:"algorithm"`
+	DigestBits *int64 `json:"digest-bits,omitempty"`
 }
 
 // Represents settable D2 (DHCP-DDNS) Kea configuration.
@@ -36,3 +70,57 @@ func (c *D2Config) GetListeningControlSockets() []ControlSocket {
 	}
 	return nil
 }
+
+// Returns the forward DDNS domains configured in the D2 server.
+func (c *D2Config) GetForwardDDNSDomains() []DDNSDomain {
+	if c.ForwardDDNS == nil {
+		return nil
+	}
+	return c.ForwardDDNS.DDNSDomains
+}
+
+// Returns the reverse DDNS domains configured in the D2 server.
+func (c *D2Config) GetReverseDDNSDomains() []DDNSDomain {
+	if c.ReverseDDNS == nil {
+		return nil
+	}
+	return c.ReverseDDNS.DDNSDomains
+}
+
+// Returns the TSIG keys configured in the D2 server.
+func (c *D2Config) GetTSIGKeys() []TSIGKey {
+	return c.TSIGKeys
+}
+
+// Returns the TSIG key with the specified name or nil if such key
+// is not configured.
+func (c *D2Config) GetTSIGKey(name string) *TSIGKey {
+	for i := range c.TSIGKeys {
+		if c.TSIGKeys[i].Name == name {
+			return &c.TSIGKeys[i]
+		}
+	}
+	return nil
+}
+
+// Returns the names of the TSIG keys used to sign the updates for the
+// domain. The key specified for a DNS server takes precedence over the key
+// specified for the domain. The returned names are unique.
+func (d DDNSDomain) GetKeyNames() (names []string) {
+	appendName := func(name *string) {
+		if name != nil && len(*name) > 0 && !slices.Contains(names, *name) {
+			names = append(names, *name)
+		}
+	}
+	for _, server := range d.DNSServers {
+		if server.KeyName != nil {
+			appendName(server.KeyName)
+		} else {
+			appendName(d.KeyName)
+		}
+	}
+	if len(d.DNSServers) == 0 {
+		appendName(d.KeyName)
+	}
+	return
+}
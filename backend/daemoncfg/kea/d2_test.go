@@ -102,3 +102,82 @@ func TestGetD2ControlSockets(t *testing.T) {
 		require.Nil(t, sockets)
 	})
 }
+
+// Test that the DDNS domains and TSIG keys are parsed from the D2 server
+// configuration.
+func TestGetD2DDNSDomainsAndKeys(t *testing.T) {
+	cfg, err := NewConfig([]byte(`{
+		"DhcpDdns": {
+			"forward-ddns": {
+				"ddns-domains": [
+					{
+						"name": "example.org.",
+						"key-name": "key1",
+						"dns-servers": [
+							{ "ip-address": "192.0.2.1", "port": 53 },
+							{ "ip-address": "192.0.2.2", "key-name": "key2" }
+						]
+					}
+				]
+			},
+			"reverse-ddns": {
+				"ddns-domains": [
+					{
+						"name": "2.0.192.in-addr.arpa.",
+						"dns-servers": [
+							{ "hostname": "ns.example.org" }
+						]
+					}
+				]
+			},
+			"tsig-keys": [
+				{
+					"name": "key1",
+					"algorithm": "HMAC-SHA256",
+					"digest-bits": 256,
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				},
+				{
+					"name": "key2",
+					"algorithm": "HMAC-MD5",
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.True(t, cfg.IsD2())
+
+	forward := cfg.GetForwardDDNSDomains()
+	require.Len(t, forward, 1)
+	require.Equal(t, "example.org.", forward[0].Name)
+	require.Equal(t, "key1", *forward[0].KeyName)
+	require.Len(t, forward[0].DNSServers, 2)
+	require.Equal(t, "192.0.2.1", *forward[0].DNSServers[0].IPAddress)
+	require.EqualValues(t, 53, *forward[0].DNSServers[0].Port)
+	require.Equal(t, []string{"key1", "key2"}, forward[0].GetKeyNames())
+
+	reverse := cfg.GetReverseDDNSDomains()
+	require.Len(t, reverse, 1)
+	require.Equal(t, "2.0.192.in-addr.arpa.", reverse[0].Name)
+	require.Nil(t, reverse[0].KeyName)
+	require.Equal(t, "ns.example.org", *reverse[0].DNSServers[0].Hostname)
+	require.Empty(t, reverse[0].GetKeyNames())
+
+	keys := cfg.GetTSIGKeys()
+	require.Len(t, keys, 2)
+	require.Equal(t, "HMAC-SHA256", keys[0].Algorithm)
+	require.EqualValues(t, 256, *keys[0].DigestBits)
+	require.Nil(t, keys[1].DigestBits)
+
+	require.NotNil(t, cfg.GetTSIGKey("key2"))
+	require.Nil(t, cfg.GetTSIGKey("key3"))
+}
+
+// Test that no DDNS domains are returned when they are not configured.
+func TestGetD2DDNSDomainsNone(t *testing.T) {
+	cfg := &D2Config{}
+	require.Empty(t, cfg.GetForwardDDNSDomains())
+	require.Empty(t, cfg.GetReverseDDNSDomains())
+	require.Empty(t, cfg.GetTSIGKeys())
+}
@@ -51,7 +51,9 @@ func TestGetDHCPv4CommonConfig(t *testing.T) {
 		CommonDHCPConfig: CommonDHCPConfig{
 			ClientClasses: []ClientClass{
 				{
-					Name: "foo",
+					ClientClassKnownParameters: ClientClassKnownParameters{
+						Name: "foo",
+					},
 				},
 			},
 		},
@@ -740,7 +742,9 @@ func TestGetDHCPv6CommonConfig(t *testing.T) {
 		CommonDHCPConfig: CommonDHCPConfig{
 			ClientClasses: []ClientClass{
 				{
-					Name: "bar",
+					ClientClassKnownParameters: ClientClassKnownParameters{
+						Name: "bar",
+					},
 				},
 			},
 		},
@@ -0,0 +1,209 @@
+package keaconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Type of the difference between two configurations.
+type ConfigDiffOperation string
+
+const (
+	// The value exists only in the new configuration.
+	ConfigDiffOperationAdded ConfigDiffOperation = "added"
+	// The value exists only in the old configuration.
+	ConfigDiffOperationRemoved ConfigDiffOperation = "removed"
+	// The value exists in both configurations but differs.
+	ConfigDiffOperationModified ConfigDiffOperation = "modified"
+)
+
+// The keys identifying the list elements (e.g., subnets, shared networks,
+// client classes) in the order of preference. The list elements are
+// matched by these keys rather than by their positions in the lists.
+// Thus, reordering the elements doesn't produce differences.
+var configDiffListElementKeys = []string{"id", "name"}
+
+// Describes a single difference between two configurations.
+type ConfigDiffEntry struct {
+	// Location of the differing value in the configuration. It is a
+	// sequence of the map keys separated by slashes. The list elements are
+	// denoted with their identifying key and value in square brackets
+	// (e.g., /Dhcp4/subnet4[id=1]/valid-lifetime) or with their index if
+	// they lack an identifying key (e.g., /Dhcp4/interfaces-config/interfaces[0]).
+	Path      string
+	Operation ConfigDiffOperation
+	OldValue  any
+	NewValue  any
+}
+
+// Computes the semantic differences between two configurations. It returns
+// an empty list when the configurations are equal. The maps are compared
+// regardless of the keys' order, and the lists of the configuration elements
+// having identifiers (e.g., subnets) regardless of the elements' order. The
+// hash of the configuration is ignored. The differences are sorted by paths.
+func DiffConfigs(oldConfig, newConfig RawConfigAccessor) ([]ConfigDiffEntry, error) {
+	oldRawConfig, err := oldConfig.GetRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	newRawConfig, err := newConfig.GetRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	// The hash changes with every configuration change, so it doesn't
+	// carry any useful information.
+	oldRawConfig = withoutConfigHash(oldRawConfig)
+	newRawConfig = withoutConfigHash(newRawConfig)
+
+	return DiffValues(map[string]any(oldRawConfig), map[string]any(newRawConfig)), nil
+}
+
+// Computes the differences between two arbitrary configuration values
+// (e.g., subnets or host reservations in the Kea format) decoded from JSON.
+// The nil value denotes a non-existing value, so the difference between
+// nil and non-nil value is reported as a single addition or removal. The
+// values are compared using the same rules as in DiffConfigs.
+func DiffValues(oldValue, newValue any) []ConfigDiffEntry {
+	entries := []ConfigDiffEntry{}
+	diffConfigValues("", oldValue, newValue, &entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+// Returns a shallow copy of the raw configuration without the hash.
+func withoutConfigHash(config RawConfig) RawConfig {
+	if _, ok := config["hash"]; !ok {
+		return config
+	}
+	copied := make(RawConfig, len(config))
+	for key, value := range config {
+		if key != "hash" {
+			copied[key] = value
+		}
+	}
+	return copied
+}
+
+// Recursively compares two configuration values and appends the differences
+// to the entries.
+func diffConfigValues(path string, oldValue, newValue any, entries *[]ConfigDiffEntry) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		*entries = append(*entries, ConfigDiffEntry{Path: path, Operation: ConfigDiffOperationAdded, NewValue: newValue})
+		return
+	case newValue == nil:
+		*entries = append(*entries, ConfigDiffEntry{Path: path, Operation: ConfigDiffOperationRemoved, OldValue: oldValue})
+		return
+	}
+	switch oldTyped := oldValue.(type) {
+	case map[string]any:
+		if newTyped, ok := newValue.(map[string]any); ok {
+			diffConfigMaps(path, oldTyped, newTyped, entries)
+			return
+		}
+	case []any:
+		if newTyped, ok := newValue.([]any); ok {
+			diffConfigLists(path, oldTyped, newTyped, entries)
+			return
+		}
+	}
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*entries = append(*entries, ConfigDiffEntry{Path: path, Operation: ConfigDiffOperationModified, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// Compares two maps by their keys.
+func diffConfigMaps(path string, oldMap, newMap map[string]any, entries *[]ConfigDiffEntry) {
+	keys := make(map[string]bool)
+	for key := range oldMap {
+		keys[key] = true
+	}
+	for key := range newMap {
+		keys[key] = true
+	}
+	for key := range keys {
+		diffConfigValues(fmt.Sprintf("%s/%s", path, key), oldMap[key], newMap[key], entries)
+	}
+}
+
+// Compares two lists. The lists of the maps sharing an identifying key
+// are compared by this key. Other lists are compared by the elements'
+// positions.
+func diffConfigLists(path string, oldList, newList []any, entries *[]ConfigDiffEntry) {
+	if key := getConfigListElementKey(oldList, newList); key != "" {
+		oldElements := indexConfigListElements(oldList, key)
+		newElements := indexConfigListElements(newList, key)
+		ids := make(map[string]bool)
+		for id := range oldElements {
+			ids[id] = true
+		}
+		for id := range newElements {
+			ids[id] = true
+		}
+		for id := range ids {
+			diffConfigValues(fmt.Sprintf("%s[%s=%s]", path, key, id), oldElements[id], newElements[id], entries)
+		}
+		return
+	}
+	for i := 0; i < len(oldList) || i < len(newList); i++ {
+		var oldValue, newValue any
+		if i < len(oldList) {
+			oldValue = oldList[i]
+		}
+		if i < len(newList) {
+			newValue = newList[i]
+		}
+		diffConfigValues(fmt.Sprintf("%s[%d]", path, i), oldValue, newValue, entries)
+	}
+}
+
+// Returns the key identifying the elements of both lists. It returns an
+// empty string if the lists contain non-map elements or if any of the
+// elements lacks the key or the key values are not unique.
+func getConfigListElementKey(oldList, newList []any) string {
+	if len(oldList) == 0 && len(newList) == 0 {
+		return ""
+	}
+	for _, key := range configDiffListElementKeys {
+		if isConfigListElementKey(oldList, key) && isConfigListElementKey(newList, key) {
+			return key
+		}
+	}
+	return ""
+}
+
+// Checks if all list elements are maps having a unique value of the key.
+func isConfigListElementKey(list []any, key string) bool {
+	ids := make(map[string]bool)
+	for _, element := range list {
+		elementMap, ok := element.(map[string]any)
+		if !ok {
+			return false
+		}
+		value, ok := elementMap[key]
+		if !ok {
+			return false
+		}
+		id := fmt.Sprint(value)
+		if ids[id] {
+			return false
+		}
+		ids[id] = true
+	}
+	return true
+}
+
+// Returns the list elements indexed by the key values.
+func indexConfigListElements(list []any, key string) map[string]any {
+	elements := make(map[string]any)
+	for _, element := range list {
+		id := fmt.Sprint(element.(map[string]any)[key])
+		elements[id] = element
+	}
+	return elements
+}
@@ -0,0 +1,161 @@
+package keaconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that no differences are found for equal configurations, even when
+// the subnets are ordered differently and the hashes differ.
+func TestDiffConfigsEqual(t *testing.T) {
+	oldConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"subnet4": [
+				{ "id": 1, "subnet": "192.0.2.0/24" },
+				{ "id": 2, "subnet": "192.0.3.0/24" }
+			]
+		},
+		"hash": "1234"
+	}`))
+	require.NoError(t, err)
+	newConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"subnet4": [
+				{ "id": 2, "subnet": "192.0.3.0/24" },
+				{ "id": 1, "subnet": "192.0.2.0/24" }
+			]
+		},
+		"hash": "5678"
+	}`))
+	require.NoError(t, err)
+
+	entries, err := DiffConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// Test that the added, removed and modified values are found.
+func TestDiffConfigs(t *testing.T) {
+	oldConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 1000,
+			"renew-timer": 500,
+			"interfaces-config": {
+				"interfaces": [ "eth0", "eth1" ]
+			},
+			"subnet4": [
+				{ "id": 1, "subnet": "192.0.2.0/24" },
+				{ "id": 2, "subnet": "192.0.3.0/24" }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	newConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 2000,
+			"rebind-timer": 800,
+			"interfaces-config": {
+				"interfaces": [ "eth0" ]
+			},
+			"subnet4": [
+				{ "id": 3, "subnet": "192.0.4.0/24" },
+				{ "id": 1, "subnet": "192.0.2.0/24", "valid-lifetime": 3000 }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	entries, err := DiffConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	require.Len(t, entries, 7)
+
+	require.Equal(t, "/Dhcp4/interfaces-config/interfaces[1]", entries[0].Path)
+	require.Equal(t, ConfigDiffOperationRemoved, entries[0].Operation)
+	require.Equal(t, "eth1", entries[0].OldValue)
+	require.Nil(t, entries[0].NewValue)
+
+	require.Equal(t, "/Dhcp4/rebind-timer", entries[1].Path)
+	require.Equal(t, ConfigDiffOperationAdded, entries[1].Operation)
+	require.EqualValues(t, 800, entries[1].NewValue)
+
+	require.Equal(t, "/Dhcp4/renew-timer", entries[2].Path)
+	require.Equal(t, ConfigDiffOperationRemoved, entries[2].Operation)
+
+	require.Equal(t, "/Dhcp4/subnet4[id=1]/valid-lifetime", entries[3].Path)
+	require.Equal(t, ConfigDiffOperationAdded, entries[3].Operation)
+
+	require.Equal(t, "/Dhcp4/subnet4[id=2]", entries[4].Path)
+	require.Equal(t, ConfigDiffOperationRemoved, entries[4].Operation)
+	require.NotNil(t, entries[4].OldValue)
+
+	require.Equal(t, "/Dhcp4/subnet4[id=3]", entries[5].Path)
+	require.Equal(t, ConfigDiffOperationAdded, entries[5].Operation)
+
+	require.Equal(t, "/Dhcp4/valid-lifetime", entries[6].Path)
+	require.Equal(t, ConfigDiffOperationModified, entries[6].Operation)
+	require.EqualValues(t, 1000, entries[6].OldValue)
+	require.EqualValues(t, 2000, entries[6].NewValue)
+}
+
+// Test that the list elements lacking the identifiers are compared by
+// their positions.
+func TestDiffConfigsListWithoutIdentifiers(t *testing.T) {
+	oldConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"option-data": [
+				{ "code": 6, "data": "192.0.2.1" },
+				{ "code": 15, "data": "example.org" }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	newConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"option-data": [
+				{ "code": 15, "data": "example.org" },
+				{ "code": 6, "data": "192.0.2.1" }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	entries, err := DiffConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, "/Dhcp4/option-data[0]/code", entries[0].Path)
+	require.Equal(t, ConfigDiffOperationModified, entries[0].Operation)
+}
+
+// Test computing the differences between arbitrary values.
+func TestDiffValues(t *testing.T) {
+	// Added value.
+	entries := DiffValues(nil, map[string]any{"id": 1})
+	require.Len(t, entries, 1)
+	require.Empty(t, entries[0].Path)
+	require.Equal(t, ConfigDiffOperationAdded, entries[0].Operation)
+	require.Nil(t, entries[0].OldValue)
+	require.Equal(t, map[string]any{"id": 1}, entries[0].NewValue)
+
+	// Removed value.
+	entries = DiffValues(map[string]any{"id": 1}, nil)
+	require.Len(t, entries, 1)
+	require.Equal(t, ConfigDiffOperationRemoved, entries[0].Operation)
+
+	// Modified values.
+	entries = DiffValues(
+		map[string]any{"id": 1, "valid-lifetime": 1000, "hostname": "foo"},
+		map[string]any{"id": 1, "valid-lifetime": 2000, "next-server": "192.0.2.1"},
+	)
+	require.Len(t, entries, 3)
+	require.Equal(t, "/hostname", entries[0].Path)
+	require.Equal(t, ConfigDiffOperationRemoved, entries[0].Operation)
+	require.Equal(t, "/next-server", entries[1].Path)
+	require.Equal(t, ConfigDiffOperationAdded, entries[1].Operation)
+	require.Equal(t, "/valid-lifetime", entries[2].Path)
+	require.Equal(t, ConfigDiffOperationModified, entries[2].Operation)
+
+	// Equal values.
+	require.Empty(t, DiffValues(map[string]any{"id": 1}, map[string]any{"id": 1}))
+	require.Empty(t, DiffValues(nil, nil))
+}
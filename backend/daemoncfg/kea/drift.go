@@ -0,0 +1,155 @@
+package keaconfig
+
+import (
+	"strings"
+	"time"
+)
+
+// Describes the differences between the configuration used by the running
+// Kea daemon and its configuration file. The configuration drifts when the
+// configuration is modified with the config-set command without the
+// config-write command, or when the file is edited without the
+// config-reload command. The daemon uses the configuration from the file
+// after restart, so the running configuration changes are lost then.
+type ConfigDrift struct {
+	// Path to the configuration file.
+	ConfigPath string
+	// Time when the configurations were compared.
+	CheckedAt time.Time
+	// Differences between the configuration file (old value) and the
+	// running configuration (new value).
+	Entries []ConfigDiffEntry
+}
+
+// Indicates if the running configuration differs from the file.
+func (drift *ConfigDrift) IsDrifted() bool {
+	return drift != nil && len(drift.Entries) > 0
+}
+
+// Hides the sensitive data in the configuration drift. The values of the
+// password, secret and token parameters are nullified, including the ones
+// nested in the added, removed or modified configuration elements. The
+// paths of the differences are preserved, so the changed passwords are
+// still reported. The values are copied before hiding the data.
+func (drift *ConfigDrift) HideSensitiveData() {
+	if drift == nil {
+		return
+	}
+	for i := range drift.Entries {
+		entry := &drift.Entries[i]
+		key := entry.Path[strings.LastIndex(entry.Path, "/")+1:]
+		if !strings.HasSuffix(key, "]") && isSensitiveConfigKey(key) {
+			entry.OldValue = nil
+			entry.NewValue = nil
+			continue
+		}
+		entry.OldValue = copyWithoutSensitiveData(entry.OldValue)
+		entry.NewValue = copyWithoutSensitiveData(entry.NewValue)
+	}
+}
+
+// Returns a copy of the configuration value with the values of the
+// sensitive parameters nullified.
+func copyWithoutSensitiveData(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(typed))
+		for key, childValue := range typed {
+			if isSensitiveConfigKey(key) {
+				copied[key] = nil
+				continue
+			}
+			copied[key] = copyWithoutSensitiveData(childValue)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(typed))
+		for i, element := range typed {
+			copied[i] = copyWithoutSensitiveData(element)
+		}
+		return copied
+	}
+	return value
+}
+
+// Compares the configuration read from the file (with the includes
+// resolved) with the configuration returned by the config-get command.
+//
+// Kea returns the running configuration with the default values of the
+// unspecified parameters filled in. Therefore, the parameters absent in
+// the file but present in the running configuration are not reported
+// unless they are new list elements (e.g., subnets or client classes added
+// with config-set). Kea also transforms some parameters when parsing the
+// configuration (e.g., comments are moved to user contexts). These
+// transformations are applied to the file before the comparison, so they
+// don't produce differences. The sensitive data are hidden in the returned
+// differences.
+func DiffConfigDrift(configPath string, fileConfig, runningConfig RawConfigAccessor) (*ConfigDrift, error) {
+	fileRawConfig, err := fileConfig.GetRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	runningRawConfig, err := runningConfig.GetRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	fileValue := normalizeDriftValue("", map[string]any(withoutConfigHash(fileRawConfig)))
+	runningValue := normalizeDriftValue("", map[string]any(withoutConfigHash(runningRawConfig)))
+
+	drift := &ConfigDrift{
+		ConfigPath: configPath,
+		Entries:    []ConfigDiffEntry{},
+	}
+	for _, entry := range DiffValues(fileValue, runningValue) {
+		if entry.Operation == ConfigDiffOperationAdded && !strings.HasSuffix(entry.Path, "]") {
+			// Most likely a default value filled in by Kea.
+			continue
+		}
+		drift.Entries = append(drift.Entries, entry)
+	}
+	drift.HideSensitiveData()
+	return drift, nil
+}
+
+// Returns a copy of the configuration value with the transformations made
+// by Kea when parsing the configuration applied. The key is the name of
+// the map entry holding the value.
+func normalizeDriftValue(key string, value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(typed))
+		for childKey, childValue := range typed {
+			// Older Kea versions return the output options with the
+			// underscore.
+			if childKey == "output_options" {
+				childKey = "output-options"
+			}
+			normalized[childKey] = normalizeDriftValue(childKey, childValue)
+		}
+		// Kea moves the comments to the user contexts.
+		if comment, ok := normalized["comment"]; ok && key != "user-context" {
+			userContext, ok := normalized["user-context"].(map[string]any)
+			if !ok {
+				userContext = map[string]any{}
+			}
+			if _, ok := userContext["comment"]; !ok {
+				userContext["comment"] = comment
+			}
+			normalized["user-context"] = userContext
+			delete(normalized, "comment")
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(typed))
+		for i, element := range typed {
+			normalized[i] = normalizeDriftValue(key, element)
+		}
+		return normalized
+	case string:
+		// Kea returns the address ranges without spaces around the dash.
+		if key == "pool" {
+			return strings.Join(strings.Fields(typed), "")
+		}
+	}
+	return value
+}
@@ -0,0 +1,159 @@
+package keaconfig
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the default values filled in by Kea and the transformations
+// made by Kea when parsing the configuration are not reported as drift.
+func TestDiffConfigDriftNone(t *testing.T) {
+	fileConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24",
+					"comment": "foo",
+					"pools": [ { "pool": "192.0.2.10 - 192.0.2.20" } ]
+				}
+			],
+			"loggers": [
+				{ "name": "kea-dhcp4", "output_options": [ { "output": "stdout" } ] }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	runningConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 7200,
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24",
+					"user-context": { "comment": "foo" },
+					"pools": [ { "pool": "192.0.2.10-192.0.2.20", "option-data": [] } ]
+				}
+			],
+			"loggers": [
+				{ "name": "kea-dhcp4", "output-options": [ { "output": "stdout" } ], "severity": "INFO" }
+			]
+		},
+		"hash": "1234"
+	}`))
+	require.NoError(t, err)
+
+	drift, err := DiffConfigDrift("/etc/kea/kea-dhcp4.conf", fileConfig, runningConfig)
+	require.NoError(t, err)
+	require.Equal(t, "/etc/kea/kea-dhcp4.conf", drift.ConfigPath)
+	require.Empty(t, drift.Entries)
+	require.False(t, drift.IsDrifted())
+}
+
+// Test that the modified, removed and added list elements are reported
+// as drift.
+func TestDiffConfigDrift(t *testing.T) {
+	fileConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 3600,
+			"subnet4": [
+				{ "id": 1, "subnet": "192.0.2.0/24" },
+				{ "id": 2, "subnet": "192.0.3.0/24" }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	runningConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 7200,
+			"subnet4": [
+				{ "id": 1, "subnet": "192.0.2.0/24" },
+				{ "id": 3, "subnet": "192.0.4.0/24" }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	drift, err := DiffConfigDrift("/etc/kea/kea-dhcp4.conf", fileConfig, runningConfig)
+	require.NoError(t, err)
+	require.True(t, drift.IsDrifted())
+	require.Len(t, drift.Entries, 3)
+
+	require.Equal(t, "/Dhcp4/subnet4[id=2]", drift.Entries[0].Path)
+	require.Equal(t, ConfigDiffOperationRemoved, drift.Entries[0].Operation)
+	require.Equal(t, "/Dhcp4/subnet4[id=3]", drift.Entries[1].Path)
+	require.Equal(t, ConfigDiffOperationAdded, drift.Entries[1].Operation)
+	require.Equal(t, "/Dhcp4/valid-lifetime", drift.Entries[2].Path)
+	require.Equal(t, ConfigDiffOperationModified, drift.Entries[2].Operation)
+	require.EqualValues(t, 3600, drift.Entries[2].OldValue)
+	require.EqualValues(t, 7200, drift.Entries[2].NewValue)
+}
+
+// Test that the sensitive data are hidden in the configuration drift.
+func TestDiffConfigDriftHideSensitiveData(t *testing.T) {
+	fileConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"lease-database": {
+				"type": "mysql",
+				"name": "kea",
+				"password": "file-secret"
+			},
+			"hosts-databases": [
+				{
+					"type": "mysql",
+					"name": "hosts1",
+					"password": "hosts-secret"
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	runningConfig, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"lease-database": {
+				"type": "mysql",
+				"name": "kea",
+				"password": "running-secret"
+			},
+			"hosts-databases": [
+				{
+					"type": "mysql",
+					"name": "hosts1",
+					"password": "hosts-secret"
+				},
+				{
+					"type": "mysql",
+					"name": "hosts2",
+					"password": "hosts-secret"
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	drift, err := DiffConfigDrift("/etc/kea/kea-dhcp4.conf", fileConfig, runningConfig)
+	require.NoError(t, err)
+	require.Len(t, drift.Entries, 2)
+
+	// The added element is reported without the password.
+	require.Equal(t, "/Dhcp4/hosts-databases[name=hosts2]", drift.Entries[0].Path)
+	require.Equal(t, ConfigDiffOperationAdded, drift.Entries[0].Operation)
+	require.Equal(t, map[string]any{
+		"type":     "mysql",
+		"name":     "hosts2",
+		"password": nil,
+	}, drift.Entries[0].NewValue)
+
+	// The changed password is reported without the values.
+	require.Equal(t, "/Dhcp4/lease-database/password", drift.Entries[1].Path)
+	require.Equal(t, ConfigDiffOperationModified, drift.Entries[1].Operation)
+	require.Nil(t, drift.Entries[1].OldValue)
+	require.Nil(t, drift.Entries[1].NewValue)
+
+	// The running configuration is not modified.
+	rawConfig, err := runningConfig.GetRawConfig()
+	require.NoError(t, err)
+	require.Contains(t, fmt.Sprint(rawConfig), "running-secret")
+}
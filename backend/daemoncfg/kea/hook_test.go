@@ -95,7 +95,8 @@ func TestGetLegalLogHookLibrary(t *testing.T) {
 			Parameters: (json.RawMessage)(`{
 				"name": "kea",
 				"host": "localhost",
-				"path": "/tmp/path"
+				"path": "/tmp/path",
+				"base-name": "forensic"
 			}`),
 		},
 	}
@@ -107,6 +108,29 @@ func TestGetLegalLogHookLibrary(t *testing.T) {
 	require.Equal(t, "localhost", params.Host)
 	require.Equal(t, "kea", params.Name)
 	require.Equal(t, "/tmp/path", params.Path)
+	require.True(t, params.IsLoggingToFile())
+
+	pattern, ok := params.GetFilePattern()
+	require.True(t, ok)
+	require.Equal(t, "/tmp/path/forensic.*.txt", pattern)
+}
+
+// Test getting the legal log file pattern with the default base name and
+// a relative path.
+func TestLegalLogHookParamsGetFilePattern(t *testing.T) {
+	params := LegalLogHookParams{
+		Database: Database{Path: "/var/log/kea"},
+	}
+	pattern, ok := params.GetFilePattern()
+	require.True(t, ok)
+	require.Equal(t, "/var/log/kea/kea-legal.*.txt", pattern)
+
+	params.Path = "log"
+	_, ok = params.GetFilePattern()
+	require.False(t, ok)
+
+	params.Type = "syslog"
+	require.False(t, params.IsLoggingToFile())
 }
 
 // Tests that the missing subnet-altering hook library is correctly reported.
@@ -199,6 +223,35 @@ func TestConfigGetSubnetAndSharedNetworkAlteringHookLibrary(t *testing.T) {
 			}`,
 			expected: SubnetAndSharedNetworkAlteringHookLibraryCBCmds,
 		},
+		{
+			name: "subnet_cmds hook with config backend",
+			config: `{
+				"Dhcp4": {
+					"hooks-libraries": [
+						{"library": "libdhcp_subnet_cmds"}
+					],
+					"config-control": {
+						"config-databases": [{"name": "kea", "type": "mysql"}]
+					}
+				}
+			}`,
+			expected: SubnetAndSharedNetworkAlteringHookLibraryNone,
+		},
+		{
+			name: "both hooks with config backend",
+			config: `{
+				"Dhcp4": {
+					"hooks-libraries": [
+						{"library": "libdhcp_subnet_cmds"},
+						{"library": "libdhcp_cb_cmds"}
+					],
+					"config-control": {
+						"config-databases": [{"name": "kea", "type": "mysql"}]
+					}
+				}
+			}`,
+			expected: SubnetAndSharedNetworkAlteringHookLibraryCBCmds,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -206,6 +259,36 @@ func TestConfigGetSubnetAndSharedNetworkAlteringHookLibrary(t *testing.T) {
 			cfg, err := NewConfig([]byte(tc.config))
 			require.NoError(t, err)
 			require.Equal(t, tc.expected, cfg.GetSubnetAndSharedNetworkAlteringHookLibrary())
+			require.Equal(t, tc.expected == SubnetAndSharedNetworkAlteringHookLibraryCBCmds, cfg.IsConfigBackendAltering())
 		})
 	}
 }
+
+// Tests that the subnet_cmds hook library used along with the configuration
+// backend is detected.
+func TestConfigIsSubnetCmdsUsedWithConfigBackend(t *testing.T) {
+	cfg, err := NewConfig([]byte(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{"library": "libdhcp_subnet_cmds"}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.False(t, cfg.HasConfigBackend())
+	require.False(t, cfg.IsSubnetCmdsUsedWithConfigBackend())
+
+	cfg, err = NewConfig([]byte(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{"library": "libdhcp_subnet_cmds"}
+			],
+			"config-control": {
+				"config-databases": [{"name": "kea", "type": "mysql"}]
+			}
+		}
+	}`))
+	require.NoError(t, err)
+	require.True(t, cfg.HasConfigBackend())
+	require.True(t, cfg.IsSubnetCmdsUsedWithConfigBackend())
+}
@@ -4,6 +4,7 @@ package keaconfig
 
 import (
 	"encoding/json"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -417,12 +418,43 @@ func (c *Config) GetHookLibrary(name string) (path string, params map[string]any
 	return
 }
 
-// Returns the hook library type used to alter subnets.
+// Checks if the server fetches its configuration from the configuration
+// backend, i.e., at least one config database is specified.
+func (c *Config) HasConfigBackend() bool {
+	return len(c.GetAllDatabases().Config) > 0
+}
+
+// Checks if the subnet_cmds hook library is used along with the configuration
+// backend. The subnet_cmds hook library modifies the configuration in the
+// server's memory, not in the database. Such changes are lost when the server
+// fetches the configuration from the database.
+func (c *Config) IsSubnetCmdsUsedWithConfigBackend() bool {
+	_, _, present := c.GetHookLibrary("libdhcp_subnet_cmds")
+	return present && c.HasConfigBackend()
+}
+
+// Returns the hook library type used to alter subnets. If the server uses
+// the configuration backend and the cb_cmds hook library is not loaded, the
+// subnet_cmds hook library is not returned because the changes applied with
+// it would not be stored in the database.
 func (c *Config) GetSubnetAndSharedNetworkAlteringHookLibrary() SubnetAndSharedNetworkAlteringHookLibrary {
-	if accessor := c.getCommonConfigAccessor(); accessor != nil {
-		return accessor.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+	accessor := c.getCommonConfigAccessor()
+	if accessor == nil {
+		return SubnetAndSharedNetworkAlteringHookLibraryNone
+	}
+	library := accessor.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+	if library == SubnetAndSharedNetworkAlteringHookLibrarySubnetCmds && c.IsSubnetCmdsUsedWithConfigBackend() {
+		return SubnetAndSharedNetworkAlteringHookLibraryNone
 	}
-	return SubnetAndSharedNetworkAlteringHookLibraryNone
+	return library
+}
+
+// Checks if the server configuration should be altered in the configuration
+// backend using the cb_cmds hook library rather than locally using the
+// hook libraries modifying the server's memory (e.g., subnet_cmds or
+// class_cmds).
+func (c *Config) IsConfigBackendAltering() bool {
+	return c.GetSubnetAndSharedNetworkAlteringHookLibrary() == SubnetAndSharedNetworkAlteringHookLibraryCBCmds
 }
 
 // Returns configured loggers.
@@ -433,6 +465,114 @@ func (c *Config) GetLoggers() (loggers []Logger) {
 	return
 }
 
+// Returns the name of the top-level configuration entry of the server,
+// e.g., Dhcp4. It returns an empty string if the configuration type is
+// not recognized.
+func (c *Config) getRootName() string {
+	switch {
+	case c.IsCtrlAgent():
+		return "Control-agent"
+	case c.IsD2():
+		return "DhcpDdns"
+	case c.IsDHCPv4():
+		return "Dhcp4"
+	case c.IsDHCPv6():
+		return "Dhcp6"
+	default:
+		return ""
+	}
+}
+
+// Applies the modification to the raw list of the configured loggers and
+// refreshes the parsed configuration. The modification returns the new
+// list of loggers. The configuration hash is removed because it is no
+// longer valid.
+func (c *Config) modifyRawLoggers(modify func(loggers []any) ([]any, error)) error {
+	root, ok := c.raw[c.getRootName()].(map[string]any)
+	if !ok {
+		return errors.New("unable to modify loggers in unsupported configuration")
+	}
+	loggers, _ := root["loggers"].([]any)
+	loggers, err := modify(loggers)
+	if err != nil {
+		return err
+	}
+	root["loggers"] = loggers
+	delete(c.raw, "hash")
+	data, err := json.Marshal(c.raw)
+	if err != nil {
+		return errors.Wrap(err, "problem serializing Kea configuration with modified loggers")
+	}
+	err = c.unmarshalIntoAccessibleConfig(data)
+	return errors.WithMessage(err, "problem parsing Kea configuration with modified loggers")
+}
+
+// Returns the index of the raw logger having the specified name or -1
+// if there is no such logger.
+func findRawLogger(loggers []any, name string) int {
+	for i, logger := range loggers {
+		if logger, ok := logger.(map[string]any); ok && logger["name"] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sets the severity and the debug level of the logger having the specified
+// name. If the logger is not configured, it is added with the output options
+// of the closest configured parent logger (e.g., kea-dhcp4 for the
+// kea-dhcp4.packets logger). It returns the logger configuration before the
+// change or nil if the logger has been added. Other loggers and parameters
+// are not modified.
+func (c *Config) SetLoggerSeverity(name, severity string, debugLevel int) (previous *Logger, err error) {
+	for _, logger := range c.GetLoggers() {
+		if logger.Name == name {
+			previous = &logger
+			break
+		}
+	}
+	err = c.modifyRawLoggers(func(loggers []any) ([]any, error) {
+		if index := findRawLogger(loggers, name); index >= 0 {
+			logger := loggers[index].(map[string]any)
+			logger["severity"] = severity
+			logger["debuglevel"] = debugLevel
+			return loggers, nil
+		}
+		logger := map[string]any{
+			"name":       name,
+			"severity":   severity,
+			"debuglevel": debugLevel,
+		}
+		for parent := name; strings.Contains(parent, "."); {
+			parent = parent[:strings.LastIndex(parent, ".")]
+			if index := findRawLogger(loggers, parent); index >= 0 {
+				for _, key := range []string{"output_options", "output-options"} {
+					if options, ok := loggers[index].(map[string]any)[key]; ok {
+						logger[key] = options
+					}
+				}
+				break
+			}
+		}
+		return append(loggers, logger), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// Removes the logger having the specified name from the configuration.
+// It is not an error if the logger is not configured.
+func (c *Config) DeleteLogger(name string) error {
+	return c.modifyRawLoggers(func(loggers []any) ([]any, error) {
+		if index := findRawLogger(loggers, name); index >= 0 {
+			loggers = slices.Delete(loggers, index, index+1)
+		}
+		return loggers, nil
+	})
+}
+
 // Finds and returns a subnet (i.e., Subnet4 or Subnet6) having the specified
 // prefix. The type of the returned object behind the interface depends on
 // the type of the configured DHCP server. It always returns a nil interface
@@ -493,8 +633,7 @@ func (c *Config) HideSensitiveData() {
 func hideSensitiveData(obj *map[string]any) {
 	for entryKey, entryValue := range *obj {
 		// Check if the value holds sensitive data.
-		entryKeyNormalized := strings.ToLower(entryKey)
-		if entryKeyNormalized == "password" || entryKeyNormalized == "secret" || entryKeyNormalized == "token" {
+		if isSensitiveConfigKey(entryKey) {
 			(*obj)[entryKey] = nil
 			continue
 		}
@@ -518,6 +657,13 @@ func hideSensitiveData(obj *map[string]any) {
 	}
 }
 
+// Checks if the configuration parameter with the specified name holds
+// sensitive data, i.e., a password, secret or token.
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	return key == "password" || key == "secret" || key == "token"
+}
+
 // Merges raw configuration into current configuration.
 func (c *Config) Merge(source RawConfigAccessor) error {
 	// Get source and destination raw configurations. The merge is performed
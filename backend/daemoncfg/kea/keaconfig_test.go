@@ -2631,3 +2631,67 @@ func TestSettingDHCPv6ExpiredLeasesProcessing(t *testing.T) {
 		}
 	}`, serializedConfig)
 }
+
+// Test setting the severity of the configured logger.
+func TestSetLoggerSeverity(t *testing.T) {
+	cfg := getTestConfigWithLoggers(t, "Dhcp4")
+
+	previous, err := cfg.SetLoggerSeverity("kea-dhcp4", "DEBUG", 99)
+	require.NoError(t, err)
+	require.NotNil(t, previous)
+	require.Equal(t, "WARN", previous.Severity)
+	require.Zero(t, previous.DebugLevel)
+
+	loggers := cfg.GetLoggers()
+	require.Len(t, loggers, 2)
+	require.Equal(t, "kea-dhcp4", loggers[0].Name)
+	require.Equal(t, "DEBUG", loggers[0].Severity)
+	require.Equal(t, 99, loggers[0].DebugLevel)
+	require.Equal(t, "stdout", loggers[0].GetAllOutputOptions()[0].Output)
+	// Other loggers are not modified.
+	require.Equal(t, "/tmp/badpackets.log", loggers[1].GetAllOutputOptions()[0].Output)
+}
+
+// Test that the logger which is not configured is added with the output
+// options of the parent logger and removed.
+func TestSetLoggerSeverityAddAndDeleteLogger(t *testing.T) {
+	cfg := getTestConfigWithLoggers(t, "Dhcp4")
+	cfg.raw["hash"] = "foo"
+
+	previous, err := cfg.SetLoggerSeverity("kea-dhcp4.packets.detail", "DEBUG", 50)
+	require.NoError(t, err)
+	require.Nil(t, previous)
+	require.NotContains(t, cfg.raw, "hash")
+
+	loggers := cfg.GetLoggers()
+	require.Len(t, loggers, 3)
+	require.Equal(t, "kea-dhcp4.packets.detail", loggers[2].Name)
+	require.Equal(t, "DEBUG", loggers[2].Severity)
+	require.Equal(t, 50, loggers[2].DebugLevel)
+	require.Len(t, loggers[2].GetAllOutputOptions(), 1)
+	require.Equal(t, "stdout", loggers[2].GetAllOutputOptions()[0].Output)
+
+	err = cfg.DeleteLogger("kea-dhcp4.packets.detail")
+	require.NoError(t, err)
+	require.Len(t, cfg.GetLoggers(), 2)
+
+	// Deleting the non-existing logger is no-op.
+	err = cfg.DeleteLogger("kea-dhcp4.packets.detail")
+	require.NoError(t, err)
+	require.Len(t, cfg.GetLoggers(), 2)
+}
+
+// Test that the logger is added to the configuration without loggers.
+func TestSetLoggerSeverityNoLoggers(t *testing.T) {
+	cfg, err := NewConfig([]byte(`{"Dhcp6": {}}`))
+	require.NoError(t, err)
+
+	previous, err := cfg.SetLoggerSeverity("kea-dhcp6", "INFO", 0)
+	require.NoError(t, err)
+	require.Nil(t, previous)
+
+	loggers := cfg.GetLoggers()
+	require.Len(t, loggers, 1)
+	require.Equal(t, "kea-dhcp6", loggers[0].Name)
+	require.Empty(t, loggers[0].GetAllOutputOptions())
+}
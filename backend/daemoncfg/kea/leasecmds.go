@@ -2,3 +2,43 @@ package keaconfig
 
 // A structure representing lease cmds hook library configuration.
 type LeaseCmdsHookParams struct{}
+
+// Represents a lease sent to Kea in the lease4-add, lease6-add, lease4-update
+// and lease6-update commands of the lease_cmds hook library. The DHCPv4
+// specific and DHCPv6 specific parameters are mutually exclusive and are
+// omitted when not set.
+type LeaseCmdsLease struct {
+	IPAddress         string         `json:"ip-address"`
+	SubnetID          int64          `json:"subnet-id,omitempty"`
+	HWAddress         string         `json:"hw-address,omitempty"`
+	ClientID          string         `json:"client-id,omitempty"`
+	DUID              string         `json:"duid,omitempty"`
+	IAID              *int64         `json:"iaid,omitempty"`
+	Type              string         `json:"type,omitempty"`
+	PrefixLength      int64          `json:"prefix-len,omitempty"`
+	ValidLifetime     *int64         `json:"valid-lft,omitempty"`
+	PreferredLifetime *int64         `json:"preferred-lft,omitempty"`
+	Hostname          string         `json:"hostname,omitempty"`
+	FqdnFwd           bool           `json:"fqdn-fwd,omitempty"`
+	FqdnRev           bool           `json:"fqdn-rev,omitempty"`
+	State             *int64         `json:"state,omitempty"`
+	UserContext       map[string]any `json:"user-context,omitempty"`
+	// Instructs Kea to create the lease in the lease4-update and
+	// lease6-update commands when it does not exist.
+	ForceCreate bool `json:"force-create,omitempty"`
+}
+
+// Represents a lease deleted with the lease4-del or lease6-del command. The
+// lease is identified by its IP address (or delegated prefix) and, in the
+// DHCPv6 case, by the lease type.
+type LeaseCmdsDeletedLease struct {
+	IPAddress string `json:"ip-address"`
+	Type      string `json:"type,omitempty"`
+}
+
+// Represents the arguments of the lease4-wipe and lease6-wipe commands.
+// The subnet ID is optional. If it is not specified, the leases are
+// wiped from all subnets.
+type LeaseCmdsWipedLeases struct {
+	SubnetID int64 `json:"subnet-id,omitempty"`
+}
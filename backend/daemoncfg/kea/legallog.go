@@ -1,6 +1,55 @@
 package keaconfig
 
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+// Default base name of the legal log files.
+const DefaultLegalLogBaseName = "kea-legal"
+
 // A structure representing legal logging hook library configuration.
 type LegalLogHookParams struct {
 	Database
+	// Base name of the legal log files. The files are named
+	// <path>/<base-name>.<date or timestamp>.txt.
+	BaseName string `json:"base-name,omitempty"`
+}
+
+// Parses the legal logging hook library parameters. The custom unmarshaller
+// is required because the embedded Database structure has its own
+// unmarshaller which would otherwise ignore the legal log specific
+// parameters.
+func (params *LegalLogHookParams) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &params.Database); err != nil {
+		return err
+	}
+	var fileParams struct {
+		BaseName string `json:"base-name"`
+	}
+	if err := json.Unmarshal(data, &fileParams); err != nil {
+		return err
+	}
+	params.BaseName = fileParams.BaseName
+	return nil
+}
+
+// Checks if the legal log entries are written to files rather than
+// to syslog or to a database.
+func (params LegalLogHookParams) IsLoggingToFile() bool {
+	return params.Type == "" || params.Type == "logfile"
+}
+
+// Returns the glob pattern matching the current and rotated legal log
+// files. It returns false if the files are not in an absolute location
+// because the Kea data directory they are relative to is not known.
+func (params LegalLogHookParams) GetFilePattern() (string, bool) {
+	if !filepath.IsAbs(params.Path) {
+		return "", false
+	}
+	baseName := params.BaseName
+	if baseName == "" {
+		baseName = DefaultLegalLogBaseName
+	}
+	return filepath.Join(params.Path, baseName+".*.txt"), true
 }
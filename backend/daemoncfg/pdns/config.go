@@ -3,6 +3,9 @@ package pdnsconfig
 import (
 	"fmt"
 	"net"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	storkutil "isc.org/stork/util"
@@ -122,6 +125,41 @@ func (c *Config) IsRPZ(viewName string, zoneName string) bool {
 	return false
 }
 
+// Returns the configuration in the key=values format with the values of the
+// parameters holding secrets (e.g., api-key, webserver-password and the
+// database passwords) replaced with asterisks. The parameters are sorted by
+// name, so the output is stable for the same configuration. The output can
+// be parsed with the Parser. It is sent to the Stork server which doesn't
+// need the secrets to review the configuration.
+func (c *Config) GetRedactedText() string {
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var builder strings.Builder
+	for _, key := range keys {
+		values := make([]string, 0, len(c.values[key]))
+		for _, value := range c.values[key] {
+			if isSecretKey(key) {
+				values = append(values, redactedValue)
+				continue
+			}
+			values = append(values, value.String())
+		}
+		fmt.Fprintf(&builder, "%s=%s\n", key, strings.Join(values, ","))
+	}
+	return builder.String()
+}
+
+// The value replacing the secrets in the redacted configuration.
+const redactedValue = "********"
+
+// Checks if the parameter holds a secret.
+func isSecretKey(key string) bool {
+	return key == "api-key" || strings.HasSuffix(key, "password") || strings.HasSuffix(key, "secret")
+}
+
 // ParsedValue represents a parsed value from a PowerDNS configuration.
 // It is one of the values specified after equal sign for a given key.
 type ParsedValue struct {
@@ -153,3 +191,20 @@ func (v *ParsedValue) GetString() *string {
 	}
 	return nil
 }
+
+// Returns the value in the format used in the configuration file.
+func (v *ParsedValue) String() string {
+	switch {
+	case v.boolValue != nil:
+		if *v.boolValue {
+			return "yes"
+		}
+		return "no"
+	case v.int64Value != nil:
+		return strconv.FormatInt(*v.int64Value, 10)
+	case v.stringValue != nil:
+		return *v.stringValue
+	default:
+		return ""
+	}
+}
@@ -1,6 +1,7 @@
 package pdnsconfig
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -336,3 +337,43 @@ func TestConfigIsRPZ(t *testing.T) {
 	require.NotNil(t, config)
 	require.False(t, config.IsRPZ("", "example.com"))
 }
+
+// Test that the configuration is formatted with the secrets redacted and
+// that the formatted configuration can be parsed.
+func TestConfigGetRedactedText(t *testing.T) {
+	config, err := NewParser().Parse("pdns.conf", strings.NewReader(`
+		webserver
+		api=yes
+		api-key=stork
+		webserver-password=secret
+		gmysql-password=db-secret
+		allow-axfr-ips=127.0.0.1,192.0.2.0/24
+		loglevel=6
+	`))
+	require.NoError(t, err)
+
+	text := config.GetRedactedText()
+	require.Equal(t, "allow-axfr-ips=127.0.0.1,192.0.2.0/24\n"+
+		"api=yes\n"+
+		"api-key=********\n"+
+		"gmysql-password=********\n"+
+		"loglevel=6\n"+
+		"webserver=yes\n"+
+		"webserver-password=********\n", text)
+
+	parsed, err := NewParser().Parse("pdns.conf", strings.NewReader(text))
+	require.NoError(t, err)
+	require.Equal(t, "********", parsed.GetAPIKey())
+	require.EqualValues(t, 6, *parsed.GetInt64("loglevel"))
+	require.True(t, *parsed.GetBool("webserver"))
+	require.Len(t, parsed.GetValues("allow-axfr-ips"), 2)
+}
+
+// Test formatting the parsed values.
+func TestParsedValueString(t *testing.T) {
+	require.Equal(t, "yes", (&ParsedValue{boolValue: storkutil.Ptr(true)}).String())
+	require.Equal(t, "no", (&ParsedValue{boolValue: storkutil.Ptr(false)}).String())
+	require.Equal(t, "8081", (&ParsedValue{int64Value: storkutil.Ptr(int64(8081))}).String())
+	require.Equal(t, "foo", (&ParsedValue{stringValue: storkutil.Ptr("foo")}).String())
+	require.Empty(t, (&ParsedValue{}).String())
+}
@@ -6,12 +6,103 @@ import (
 )
 
 const (
-	RemoteSubnet4Set CommandName = "remote-subnet4-set"
-	RemoteSubnet6Set CommandName = "remote-subnet6-set"
-	RemoteSubnet4Del CommandName = "remote-subnet4-del-by-id"
-	RemoteSubnet6Del CommandName = "remote-subnet6-del-by-id"
+	RemoteClass4Del              CommandName = "remote-class4-del"
+	RemoteClass6Del              CommandName = "remote-class6-del"
+	RemoteClass4Get              CommandName = "remote-class4-get"
+	RemoteClass6Get              CommandName = "remote-class6-get"
+	RemoteClass4GetAll           CommandName = "remote-class4-get-all"
+	RemoteClass6GetAll           CommandName = "remote-class6-get-all"
+	RemoteClass4Set              CommandName = "remote-class4-set"
+	RemoteClass6Set              CommandName = "remote-class6-set"
+	RemoteGlobalParameter4Del    CommandName = "remote-global-parameter4-del"
+	RemoteGlobalParameter6Del    CommandName = "remote-global-parameter6-del"
+	RemoteGlobalParameter4Get    CommandName = "remote-global-parameter4-get"
+	RemoteGlobalParameter6Get    CommandName = "remote-global-parameter6-get"
+	RemoteGlobalParameter4GetAll CommandName = "remote-global-parameter4-get-all"
+	RemoteGlobalParameter6GetAll CommandName = "remote-global-parameter6-get-all"
+	RemoteGlobalParameter4Set    CommandName = "remote-global-parameter4-set"
+	RemoteGlobalParameter6Set    CommandName = "remote-global-parameter6-set"
+	RemoteNetwork4Del            CommandName = "remote-network4-del"
+	RemoteNetwork6Del            CommandName = "remote-network6-del"
+	RemoteNetwork4Get            CommandName = "remote-network4-get"
+	RemoteNetwork6Get            CommandName = "remote-network6-get"
+	RemoteNetwork4List           CommandName = "remote-network4-list"
+	RemoteNetwork6List           CommandName = "remote-network6-list"
+	RemoteNetwork4Set            CommandName = "remote-network4-set"
+	RemoteNetwork6Set            CommandName = "remote-network6-set"
+	RemoteOption4GlobalDel       CommandName = "remote-option4-global-del"
+	RemoteOption6GlobalDel       CommandName = "remote-option6-global-del"
+	RemoteOption4GlobalGet       CommandName = "remote-option4-global-get"
+	RemoteOption6GlobalGet       CommandName = "remote-option6-global-get"
+	RemoteOption4GlobalGetAll    CommandName = "remote-option4-global-get-all"
+	RemoteOption6GlobalGetAll    CommandName = "remote-option6-global-get-all"
+	RemoteOption4GlobalSet       CommandName = "remote-option4-global-set"
+	RemoteOption6GlobalSet       CommandName = "remote-option6-global-set"
+	RemoteOption4NetworkDel      CommandName = "remote-option4-network-del"
+	RemoteOption6NetworkDel      CommandName = "remote-option6-network-del"
+	RemoteOption4NetworkSet      CommandName = "remote-option4-network-set"
+	RemoteOption6NetworkSet      CommandName = "remote-option6-network-set"
+	RemoteOption4PoolDel         CommandName = "remote-option4-pool-del"
+	RemoteOption6PoolDel         CommandName = "remote-option6-pool-del"
+	RemoteOption4PoolSet         CommandName = "remote-option4-pool-set"
+	RemoteOption6PoolSet         CommandName = "remote-option6-pool-set"
+	RemoteOption6PDPoolDel       CommandName = "remote-option6-pd-pool-del"
+	RemoteOption6PDPoolSet       CommandName = "remote-option6-pd-pool-set"
+	RemoteOption4SubnetDel       CommandName = "remote-option4-subnet-del"
+	RemoteOption6SubnetDel       CommandName = "remote-option6-subnet-del"
+	RemoteOption4SubnetSet       CommandName = "remote-option4-subnet-set"
+	RemoteOption6SubnetSet       CommandName = "remote-option6-subnet-set"
+	RemoteOptionDef4Del          CommandName = "remote-option-def4-del"
+	RemoteOptionDef6Del          CommandName = "remote-option-def6-del"
+	RemoteOptionDef4Get          CommandName = "remote-option-def4-get"
+	RemoteOptionDef6Get          CommandName = "remote-option-def6-get"
+	RemoteOptionDef4GetAll       CommandName = "remote-option-def4-get-all"
+	RemoteOptionDef6GetAll       CommandName = "remote-option-def6-get-all"
+	RemoteOptionDef4Set          CommandName = "remote-option-def4-set"
+	RemoteOptionDef6Set          CommandName = "remote-option-def6-set"
+	RemoteServer4Del             CommandName = "remote-server4-del"
+	RemoteServer6Del             CommandName = "remote-server6-del"
+	RemoteServer4Get             CommandName = "remote-server4-get"
+	RemoteServer6Get             CommandName = "remote-server6-get"
+	RemoteServer4GetAll          CommandName = "remote-server4-get-all"
+	RemoteServer6GetAll          CommandName = "remote-server6-get-all"
+	RemoteServer4Set             CommandName = "remote-server4-set"
+	RemoteServer6Set             CommandName = "remote-server6-set"
+	RemoteSubnet4Set             CommandName = "remote-subnet4-set"
+	RemoteSubnet6Set             CommandName = "remote-subnet6-set"
+	RemoteSubnet4Del             CommandName = "remote-subnet4-del-by-id"
+	RemoteSubnet6Del             CommandName = "remote-subnet6-del-by-id"
+	RemoteSubnet4DelByPrefix     CommandName = "remote-subnet4-del-by-prefix"
+	RemoteSubnet6DelByPrefix     CommandName = "remote-subnet6-del-by-prefix"
+	RemoteSubnet4GetByID         CommandName = "remote-subnet4-get-by-id"
+	RemoteSubnet6GetByID         CommandName = "remote-subnet6-get-by-id"
+	RemoteSubnet4GetByPrefix     CommandName = "remote-subnet4-get-by-prefix"
+	RemoteSubnet6GetByPrefix     CommandName = "remote-subnet6-get-by-prefix"
+	RemoteSubnet4List            CommandName = "remote-subnet4-list"
+	RemoteSubnet6List            CommandName = "remote-subnet6-list"
 )
 
+// Server tag designating the configuration elements shared by all servers
+// using the configuration backend.
+const ServerTagAll = "all"
+
+// Selects the DHCPv4 or DHCPv6 variant of the remote command depending on
+// the daemon receiving the command.
+func selectRemoteCommand(daemonName daemonname.Name, command4, command6 CommandName) CommandName {
+	if daemonName == daemonname.DHCPv6 {
+		return command6
+	}
+	return command4
+}
+
+// Creates an argument identifying an option in the remote-option* commands.
+func createRemoteOptionKey(code uint16, space string) map[string]any {
+	return map[string]any{
+		"code":  code,
+		"space": space,
+	}
+}
+
 // Creates a remote-subnet4-set command. The command updates or inserts an IPv4
 // subnet in the Kea configuration backend database. It does not include the
 // remote parameter, so Kea uses the first configured config database by default.
@@ -47,3 +138,301 @@ func NewCommandRemoteSubnet6DelByID(subnetID int64, daemonName daemonname.Name)
 	return NewCommandBase(RemoteSubnet6Del, daemonName).
 		WithArrayArgument("subnets", map[string]int64{"id": subnetID})
 }
+
+// Creates a remote-subnet4-del-by-prefix or remote-subnet6-del-by-prefix
+// command depending on the daemon name.
+func NewCommandRemoteSubnetDelByPrefix(prefix string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteSubnet4DelByPrefix, RemoteSubnet6DelByPrefix), daemonName).
+		WithArrayArgument("subnets", map[string]string{"subnet": prefix})
+}
+
+// Creates a remote-subnet4-get-by-id or remote-subnet6-get-by-id command
+// depending on the daemon name.
+func NewCommandRemoteSubnetGetByID(subnetID int64, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteSubnet4GetByID, RemoteSubnet6GetByID), daemonName).
+		WithArrayArgument("subnets", map[string]int64{"id": subnetID})
+}
+
+// Creates a remote-subnet4-get-by-prefix or remote-subnet6-get-by-prefix
+// command depending on the daemon name.
+func NewCommandRemoteSubnetGetByPrefix(prefix string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteSubnet4GetByPrefix, RemoteSubnet6GetByPrefix), daemonName).
+		WithArrayArgument("subnets", map[string]string{"subnet": prefix})
+}
+
+// Creates a remote-subnet4-list or remote-subnet6-list command depending on
+// the daemon name. It lists the subnets associated with the specified
+// server tags.
+func NewCommandRemoteSubnetList(serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteSubnet4List, RemoteSubnet6List), daemonName).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-global-parameter4-set or remote-global-parameter6-set
+// command depending on the daemon name. The parameters map holds the
+// names and the scalar values of the set parameters.
+func NewCommandRemoteGlobalParameterSet(parameters map[string]any, serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteGlobalParameter4Set, RemoteGlobalParameter6Set), daemonName).
+		WithArgument("parameters", parameters).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-global-parameter4-del or remote-global-parameter6-del
+// command depending on the daemon name. Kea accepts exactly one server tag
+// in this command.
+func NewCommandRemoteGlobalParameterDel(parameterNames []string, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteGlobalParameter4Del, RemoteGlobalParameter6Del), daemonName).
+		WithArgument("parameters", parameterNames).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-global-parameter4-get or remote-global-parameter6-get
+// command depending on the daemon name.
+func NewCommandRemoteGlobalParameterGet(parameterName string, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteGlobalParameter4Get, RemoteGlobalParameter6Get), daemonName).
+		WithArrayArgument("parameters", parameterName).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-global-parameter4-get-all or
+// remote-global-parameter6-get-all command depending on the daemon name.
+func NewCommandRemoteGlobalParameterGetAll(serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteGlobalParameter4GetAll, RemoteGlobalParameter6GetAll), daemonName).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option4-global-set or remote-option6-global-set command
+// depending on the daemon name.
+func NewCommandRemoteOptionGlobalSet(option *keaconfig.SingleOptionData, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4GlobalSet, RemoteOption6GlobalSet), daemonName).
+		WithArrayArgument("options", option).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option4-global-del or remote-option6-global-del command
+// depending on the daemon name. The option is identified by code and space.
+func NewCommandRemoteOptionGlobalDel(code uint16, space string, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4GlobalDel, RemoteOption6GlobalDel), daemonName).
+		WithArrayArgument("options", createRemoteOptionKey(code, space)).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option4-global-get or remote-option6-global-get command
+// depending on the daemon name. The option is identified by code and space.
+func NewCommandRemoteOptionGlobalGet(code uint16, space string, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4GlobalGet, RemoteOption6GlobalGet), daemonName).
+		WithArrayArgument("options", createRemoteOptionKey(code, space)).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option4-global-get-all or remote-option6-global-get-all
+// command depending on the daemon name.
+func NewCommandRemoteOptionGlobalGetAll(serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4GlobalGetAll, RemoteOption6GlobalGetAll), daemonName).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option4-network-set or remote-option6-network-set command
+// depending on the daemon name. It sets the option in the shared network.
+func NewCommandRemoteOptionNetworkSet(sharedNetworkName string, option *keaconfig.SingleOptionData, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4NetworkSet, RemoteOption6NetworkSet), daemonName).
+		WithArrayArgument("shared-networks", map[string]string{"name": sharedNetworkName}).
+		WithArrayArgument("options", option)
+}
+
+// Creates a remote-option4-network-del or remote-option6-network-del command
+// depending on the daemon name. It deletes the option from the shared network.
+func NewCommandRemoteOptionNetworkDel(sharedNetworkName string, code uint16, space string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4NetworkDel, RemoteOption6NetworkDel), daemonName).
+		WithArrayArgument("shared-networks", map[string]string{"name": sharedNetworkName}).
+		WithArrayArgument("options", createRemoteOptionKey(code, space))
+}
+
+// Creates a remote-option4-subnet-set or remote-option6-subnet-set command
+// depending on the daemon name. It sets the option in the subnet.
+func NewCommandRemoteOptionSubnetSet(subnetID int64, option *keaconfig.SingleOptionData, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4SubnetSet, RemoteOption6SubnetSet), daemonName).
+		WithArrayArgument("subnets", map[string]int64{"id": subnetID}).
+		WithArrayArgument("options", option)
+}
+
+// Creates a remote-option4-subnet-del or remote-option6-subnet-del command
+// depending on the daemon name. It deletes the option from the subnet.
+func NewCommandRemoteOptionSubnetDel(subnetID int64, code uint16, space string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4SubnetDel, RemoteOption6SubnetDel), daemonName).
+		WithArrayArgument("subnets", map[string]int64{"id": subnetID}).
+		WithArrayArgument("options", createRemoteOptionKey(code, space))
+}
+
+// Creates a remote-option4-pool-set or remote-option6-pool-set command
+// depending on the daemon name. The pool is specified as a range (e.g.,
+// 192.0.2.10-192.0.2.20).
+func NewCommandRemoteOptionPoolSet(pool string, option *keaconfig.SingleOptionData, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4PoolSet, RemoteOption6PoolSet), daemonName).
+		WithArrayArgument("pools", map[string]string{"pool": pool}).
+		WithArrayArgument("options", option)
+}
+
+// Creates a remote-option4-pool-del or remote-option6-pool-del command
+// depending on the daemon name.
+func NewCommandRemoteOptionPoolDel(pool string, code uint16, space string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOption4PoolDel, RemoteOption6PoolDel), daemonName).
+		WithArrayArgument("pools", map[string]string{"pool": pool}).
+		WithArrayArgument("options", createRemoteOptionKey(code, space))
+}
+
+// Creates a remote-option6-pd-pool-set command. The delegated prefix pool
+// is identified by the prefix.
+func NewCommandRemoteOption6PDPoolSet(prefix string, option *keaconfig.SingleOptionData) *Command {
+	return NewCommandBase(RemoteOption6PDPoolSet, daemonname.DHCPv6).
+		WithArrayArgument("pd-pools", map[string]string{"prefix": prefix}).
+		WithArrayArgument("options", option)
+}
+
+// Creates a remote-option6-pd-pool-del command.
+func NewCommandRemoteOption6PDPoolDel(prefix string, code uint16, space string) *Command {
+	return NewCommandBase(RemoteOption6PDPoolDel, daemonname.DHCPv6).
+		WithArrayArgument("pd-pools", map[string]string{"prefix": prefix}).
+		WithArrayArgument("options", createRemoteOptionKey(code, space))
+}
+
+// Creates a remote-option-def4-set or remote-option-def6-set command
+// depending on the daemon name.
+func NewCommandRemoteOptionDefSet(def keaconfig.DHCPOptionDefinition, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOptionDef4Set, RemoteOptionDef6Set), daemonName).
+		WithArrayArgument("option-defs", map[string]any{
+			"array":        def.GetArray(),
+			"code":         def.GetCode(),
+			"encapsulate":  def.GetEncapsulate(),
+			"name":         def.GetName(),
+			"record-types": def.GetRecordTypes(),
+			"space":        def.GetSpace(),
+			"type":         def.GetType(),
+		}).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option-def4-del or remote-option-def6-del command
+// depending on the daemon name. The option definition is identified by
+// code and space.
+func NewCommandRemoteOptionDefDel(code uint16, space string, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOptionDef4Del, RemoteOptionDef6Del), daemonName).
+		WithArrayArgument("option-defs", createRemoteOptionKey(code, space)).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option-def4-get or remote-option-def6-get command
+// depending on the daemon name.
+func NewCommandRemoteOptionDefGet(code uint16, space string, serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOptionDef4Get, RemoteOptionDef6Get), daemonName).
+		WithArrayArgument("option-defs", createRemoteOptionKey(code, space)).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-option-def4-get-all or remote-option-def6-get-all
+// command depending on the daemon name.
+func NewCommandRemoteOptionDefGetAll(serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteOptionDef4GetAll, RemoteOptionDef6GetAll), daemonName).
+		WithArrayArgument("server-tags", serverTag)
+}
+
+// Creates a remote-network4-set command. The shared network must not
+// contain subnets. The subnets are associated with the shared network
+// using the shared-network-name parameter of the remote-subnet4-set command.
+func NewCommandRemoteNetwork4Set(sharedNetwork *keaconfig.SharedNetwork4, serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(RemoteNetwork4Set, daemonName).
+		WithArrayArgument("shared-networks", sharedNetwork).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-network6-set command. The shared network must not
+// contain subnets. The subnets are associated with the shared network
+// using the shared-network-name parameter of the remote-subnet6-set command.
+func NewCommandRemoteNetwork6Set(sharedNetwork *keaconfig.SharedNetwork6, serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(RemoteNetwork6Set, daemonName).
+		WithArrayArgument("shared-networks", sharedNetwork).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-network4-del or remote-network6-del command depending
+// on the daemon name. The subnets action specifies whether the subnets
+// belonging to the shared network are deleted or preserved.
+func NewCommandRemoteNetworkDel(sharedNetworkName string, subnetsAction keaconfig.SharedNetworkSubnetsAction, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteNetwork4Del, RemoteNetwork6Del), daemonName).
+		WithArrayArgument("shared-networks", map[string]string{"name": sharedNetworkName}).
+		WithArgument("subnets-action", subnetsAction)
+}
+
+// Creates a remote-network4-get or remote-network6-get command depending
+// on the daemon name. The subnets are not included in the response.
+func NewCommandRemoteNetworkGet(sharedNetworkName string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteNetwork4Get, RemoteNetwork6Get), daemonName).
+		WithArrayArgument("shared-networks", map[string]string{"name": sharedNetworkName}).
+		WithArgument("subnets-include", "no")
+}
+
+// Creates a remote-network4-list or remote-network6-list command depending
+// on the daemon name.
+func NewCommandRemoteNetworkList(serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteNetwork4List, RemoteNetwork6List), daemonName).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-class4-set or remote-class6-set command depending on
+// the daemon name.
+func NewCommandRemoteClassSet(class *keaconfig.ClientClass, serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteClass4Set, RemoteClass6Set), daemonName).
+		WithArrayArgument("client-classes", class).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-class4-del or remote-class6-del command depending on
+// the daemon name.
+func NewCommandRemoteClassDel(className string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteClass4Del, RemoteClass6Del), daemonName).
+		WithArrayArgument("client-classes", map[string]string{"name": className})
+}
+
+// Creates a remote-class4-get or remote-class6-get command depending on
+// the daemon name.
+func NewCommandRemoteClassGet(className string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteClass4Get, RemoteClass6Get), daemonName).
+		WithArrayArgument("client-classes", map[string]string{"name": className})
+}
+
+// Creates a remote-class4-get-all or remote-class6-get-all command
+// depending on the daemon name.
+func NewCommandRemoteClassGetAll(serverTags []string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteClass4GetAll, RemoteClass6GetAll), daemonName).
+		WithArgument("server-tags", serverTags)
+}
+
+// Creates a remote-server4-set or remote-server6-set command depending on
+// the daemon name. It creates or updates the server tag in the database.
+func NewCommandRemoteServerSet(serverTag, description string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteServer4Set, RemoteServer6Set), daemonName).
+		WithArrayArgument("servers", map[string]string{
+			"server-tag":  serverTag,
+			"description": description,
+		})
+}
+
+// Creates a remote-server4-del or remote-server6-del command depending on
+// the daemon name.
+func NewCommandRemoteServerDel(serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteServer4Del, RemoteServer6Del), daemonName).
+		WithArrayArgument("servers", map[string]string{"server-tag": serverTag})
+}
+
+// Creates a remote-server4-get or remote-server6-get command depending on
+// the daemon name.
+func NewCommandRemoteServerGet(serverTag string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteServer4Get, RemoteServer6Get), daemonName).
+		WithArrayArgument("servers", map[string]string{"server-tag": serverTag})
+}
+
+// Creates a remote-server4-get-all or remote-server6-get-all command
+// depending on the daemon name.
+func NewCommandRemoteServerGetAll(daemonName daemonname.Name) *Command {
+	return NewCommandBase(selectRemoteCommand(daemonName, RemoteServer4GetAll, RemoteServer6GetAll), daemonName)
+}
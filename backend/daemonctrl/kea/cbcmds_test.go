@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 	keaconfig "isc.org/stork/daemoncfg/kea"
 	"isc.org/stork/datamodel/daemonname"
+	storkutil "isc.org/stork/util"
 )
 
 // Tests that remote-subnet4-set command is constructed correctly.
@@ -170,3 +171,201 @@ func TestNewCommandRemoteSubnet6DelByID(t *testing.T) {
 		}
 	}`, string(marshalled))
 }
+
+// Tests that the DHCPv4 or DHCPv6 variant of the remote command is selected
+// depending on the daemon name.
+func TestRemoteCommandFamilySelection(t *testing.T) {
+	command := NewCommandRemoteSubnetList([]string{"all"}, daemonname.DHCPv4)
+	require.Equal(t, RemoteSubnet4List, command.Command)
+	command = NewCommandRemoteSubnetList([]string{"all"}, daemonname.DHCPv6)
+	require.Equal(t, RemoteSubnet6List, command.Command)
+	require.Equal(t, []daemonname.Name{daemonname.DHCPv6}, command.Daemons)
+}
+
+// Tests that remote-global-parameter4-set command is constructed correctly.
+func TestNewCommandRemoteGlobalParameterSet(t *testing.T) {
+	command := NewCommandRemoteGlobalParameterSet(map[string]any{
+		"valid-lifetime": 3600,
+		"authoritative":  true,
+	}, []string{"server1", "server2"}, daemonname.DHCPv4)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"parameters": {
+				"valid-lifetime": 3600,
+				"authoritative": true
+			},
+			"server-tags": ["server1", "server2"]
+		}
+	}`, string(marshalled))
+}
+
+// Tests that remote-global-parameter6-del command is constructed correctly.
+func TestNewCommandRemoteGlobalParameterDel(t *testing.T) {
+	command := NewCommandRemoteGlobalParameterDel([]string{"valid-lifetime"}, "all", daemonname.DHCPv6)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"parameters": ["valid-lifetime"],
+			"server-tags": ["all"]
+		}
+	}`, string(marshalled))
+}
+
+// Tests that remote-option4-global-set and remote-option4-global-del
+// commands are constructed correctly.
+func TestNewCommandRemoteOptionGlobal(t *testing.T) {
+	option := &keaconfig.SingleOptionData{
+		SingleOptionDataKnownParameters: keaconfig.SingleOptionDataKnownParameters{
+			Code:      6,
+			CSVFormat: true,
+			Data:      "192.0.2.1",
+			Space:     "dhcp4",
+		},
+	}
+	command := NewCommandRemoteOptionGlobalSet(option, "server1", daemonname.DHCPv4)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"options": [
+				{
+					"code": 6,
+					"csv-format": true,
+					"data": "192.0.2.1",
+					"space": "dhcp4"
+				}
+			],
+			"server-tags": ["server1"]
+		}
+	}`, string(marshalled))
+
+	command = NewCommandRemoteOptionGlobalDel(6, "dhcp4", "server1", daemonname.DHCPv4)
+	marshalled, err = command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"options": [
+				{
+					"code": 6,
+					"space": "dhcp4"
+				}
+			],
+			"server-tags": ["server1"]
+		}
+	}`, string(marshalled))
+}
+
+// Tests that remote-network4-set command is constructed correctly.
+func TestNewCommandRemoteNetwork4Set(t *testing.T) {
+	sharedNetwork := &keaconfig.SharedNetwork4{
+		SharedNetwork4KnownParameters: keaconfig.SharedNetwork4KnownParameters{
+			Name: "foo",
+		},
+	}
+	command := NewCommandRemoteNetwork4Set(sharedNetwork, []string{"all"}, daemonname.DHCPv4)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-network4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"shared-networks": [
+				{
+					"name": "foo"
+				}
+			],
+			"server-tags": ["all"]
+		}
+	}`, string(marshalled))
+}
+
+// Tests that remote-network6-del command is constructed correctly.
+func TestNewCommandRemoteNetworkDel(t *testing.T) {
+	command := NewCommandRemoteNetworkDel("foo", keaconfig.SharedNetworkSubnetsActionDelete, daemonname.DHCPv6)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-network6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"shared-networks": [
+				{
+					"name": "foo"
+				}
+			],
+			"subnets-action": "delete"
+		}
+	}`, string(marshalled))
+}
+
+// Tests that remote-class4-set and remote-class4-del commands are
+// constructed correctly.
+func TestNewCommandRemoteClass(t *testing.T) {
+	class := &keaconfig.ClientClass{
+		ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+			Name: "foo",
+			Test: storkutil.Ptr("member('KNOWN')"),
+		},
+	}
+	command := NewCommandRemoteClassSet(class, []string{"server1"}, daemonname.DHCPv4)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-class4-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('KNOWN')"
+				}
+			],
+			"server-tags": ["server1"]
+		}
+	}`, string(marshalled))
+
+	command = NewCommandRemoteClassDel("foo", daemonname.DHCPv4)
+	marshalled, err = command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-class4-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo"
+				}
+			]
+		}
+	}`, string(marshalled))
+}
+
+// Tests that remote-server6-set command is constructed correctly.
+func TestNewCommandRemoteServerSet(t *testing.T) {
+	command := NewCommandRemoteServerSet("server1", "first server", daemonname.DHCPv6)
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-server6-set",
+		"service": ["dhcp6"],
+		"arguments": {
+			"servers": [
+				{
+					"server-tag": "server1",
+					"description": "first server"
+				}
+			]
+		}
+	}`, string(marshalled))
+}
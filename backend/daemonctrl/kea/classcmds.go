@@ -0,0 +1,27 @@
+package keactrl
+
+import (
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+)
+
+const (
+	ClassAdd    CommandName = "class-add"
+	ClassDel    CommandName = "class-del"
+	ClassUpdate CommandName = "class-update"
+)
+
+// Creates class-add command.
+func NewCommandClassAdd(class *keaconfig.ClientClass, daemonName daemonname.Name) *Command {
+	return NewCommandBase(ClassAdd, daemonName).WithArrayArgument("client-classes", class)
+}
+
+// Creates class-update command.
+func NewCommandClassUpdate(class *keaconfig.ClientClass, daemonName daemonname.Name) *Command {
+	return NewCommandBase(ClassUpdate, daemonName).WithArrayArgument("client-classes", class)
+}
+
+// Creates class-del command.
+func NewCommandClassDel(className string, daemonName daemonname.Name) *Command {
+	return NewCommandBase(ClassDel, daemonName).WithArgument("name", className)
+}
@@ -0,0 +1,80 @@
+package keactrl
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	storkutil "isc.org/stork/util"
+)
+
+// Tests class-add command.
+func TestNewCommandClassAdd(t *testing.T) {
+	command := NewCommandClassAdd(&keaconfig.ClientClass{
+		ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+			Name: "foo",
+			Test: storkutil.Ptr("member('ALL')"),
+		},
+	}, daemonname.DHCPv4)
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "class-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('ALL')"
+				}
+			]
+		}
+	}`, string(bytes))
+}
+
+// Tests class-update command.
+func TestNewCommandClassUpdate(t *testing.T) {
+	command := NewCommandClassUpdate(&keaconfig.ClientClass{
+		ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+			Name: "foo",
+			PreferredLifetimeParameters: keaconfig.PreferredLifetimeParameters{
+				PreferredLifetime: storkutil.Ptr(int64(3000)),
+			},
+		},
+	}, daemonname.DHCPv6)
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "class-update",
+		"service": ["dhcp6"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"preferred-lifetime": 3000
+				}
+			]
+		}
+	}`, string(bytes))
+}
+
+// Tests class-del command.
+func TestNewCommandClassDel(t *testing.T) {
+	command := NewCommandClassDel("foo", daemonname.DHCPv4)
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "class-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"name": "foo"
+		}
+	}`, string(bytes))
+}
@@ -0,0 +1,67 @@
+package keactrl
+
+import "isc.org/stork/datamodel/daemonname"
+
+const (
+	HAContinue          CommandName = "ha-continue"
+	HAMaintenanceCancel CommandName = "ha-maintenance-cancel"
+	HAMaintenanceStart  CommandName = "ha-maintenance-start"
+	HAReset             CommandName = "ha-reset"
+	HAScopes            CommandName = "ha-scopes"
+	HASync              CommandName = "ha-sync"
+)
+
+// Creates an HA command with an optional server-name argument. The server
+// name selects the HA relationship in the hub-and-spoke configuration. It
+// is not included in the command when it is empty.
+func newHACommand(command CommandName, daemonName daemonname.Name, serverName string) *Command {
+	cmd := NewCommandBase(command, daemonName)
+	if len(serverName) > 0 {
+		cmd = cmd.WithArgument("server-name", serverName)
+	}
+	return cmd
+}
+
+// Creates ha-maintenance-start command. It transitions the partner of the
+// server receiving the command to the in-maintenance state.
+func NewCommandHAMaintenanceStart(daemonName daemonname.Name, serverName string) *Command {
+	return newHACommand(HAMaintenanceStart, daemonName, serverName)
+}
+
+// Creates ha-maintenance-cancel command. It transitions the server from
+// the partner-in-maintenance state to its previous state.
+func NewCommandHAMaintenanceCancel(daemonName daemonname.Name, serverName string) *Command {
+	return newHACommand(HAMaintenanceCancel, daemonName, serverName)
+}
+
+// Creates ha-continue command. It resumes the paused HA state machine.
+func NewCommandHAContinue(daemonName daemonname.Name, serverName string) *Command {
+	return newHACommand(HAContinue, daemonName, serverName)
+}
+
+// Creates ha-reset command. It resets the HA state machine to the waiting
+// state.
+func NewCommandHAReset(daemonName daemonname.Name, serverName string) *Command {
+	return newHACommand(HAReset, daemonName, serverName)
+}
+
+// Creates ha-scopes command. It enables the specified HA scopes and disables
+// the other scopes. The empty list of scopes disables all scopes.
+func NewCommandHAScopes(daemonName daemonname.Name, serverName string, scopes []string) *Command {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return newHACommand(HAScopes, daemonName, serverName).WithArgument("scopes", scopes)
+}
+
+// Creates ha-sync command. It fetches the leases from the partner with the
+// specified name. The maxPeriod specifies the maximum duration in seconds
+// for which the partner's DHCP service is disabled during the
+// synchronization. It is not included in the command when nil.
+func NewCommandHASync(daemonName daemonname.Name, partnerName string, maxPeriod *int64) *Command {
+	cmd := NewCommandBase(HASync, daemonName).WithArgument("server-name", partnerName)
+	if maxPeriod != nil {
+		cmd = cmd.WithArgument("max-period", *maxPeriod)
+	}
+	return cmd
+}
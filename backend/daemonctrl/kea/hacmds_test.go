@@ -0,0 +1,122 @@
+package keactrl
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	"isc.org/stork/datamodel/daemonname"
+	storkutil "isc.org/stork/util"
+)
+
+// Tests ha-maintenance-start command.
+func TestNewCommandHAMaintenanceStart(t *testing.T) {
+	command := NewCommandHAMaintenanceStart(daemonname.DHCPv4, "")
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-maintenance-start",
+		"service": ["dhcp4"]
+	}`, string(bytes))
+}
+
+// Tests ha-maintenance-cancel command with the server name.
+func TestNewCommandHAMaintenanceCancel(t *testing.T) {
+	command := NewCommandHAMaintenanceCancel(daemonname.DHCPv6, "server2")
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-maintenance-cancel",
+		"service": ["dhcp6"],
+		"arguments": {
+			"server-name": "server2"
+		}
+	}`, string(bytes))
+}
+
+// Tests ha-continue command.
+func TestNewCommandHAContinue(t *testing.T) {
+	command := NewCommandHAContinue(daemonname.DHCPv4, "server2")
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-continue",
+		"service": ["dhcp4"],
+		"arguments": {
+			"server-name": "server2"
+		}
+	}`, string(bytes))
+}
+
+// Tests ha-reset command.
+func TestNewCommandHAReset(t *testing.T) {
+	command := NewCommandHAReset(daemonname.DHCPv4, "")
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-reset",
+		"service": ["dhcp4"]
+	}`, string(bytes))
+}
+
+// Tests ha-scopes command.
+func TestNewCommandHAScopes(t *testing.T) {
+	command := NewCommandHAScopes(daemonname.DHCPv4, "", []string{"server1", "server2"})
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-scopes",
+		"service": ["dhcp4"],
+		"arguments": {
+			"scopes": [ "server1", "server2" ]
+		}
+	}`, string(bytes))
+}
+
+// Tests that ha-scopes command contains an empty list of scopes when
+// the scopes are not specified.
+func TestNewCommandHAScopesEmpty(t *testing.T) {
+	command := NewCommandHAScopes(daemonname.DHCPv6, "server1", nil)
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-scopes",
+		"service": ["dhcp6"],
+		"arguments": {
+			"server-name": "server1",
+			"scopes": []
+		}
+	}`, string(bytes))
+}
+
+// Tests ha-sync command.
+func TestNewCommandHASync(t *testing.T) {
+	command := NewCommandHASync(daemonname.DHCPv4, "server2", storkutil.Ptr(int64(60)))
+	require.NotNil(t, command)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-sync",
+		"service": ["dhcp4"],
+		"arguments": {
+			"server-name": "server2",
+			"max-period": 60
+		}
+	}`, string(bytes))
+
+	command = NewCommandHASync(daemonname.DHCPv4, "server2", nil)
+	bytes, err = command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-sync",
+		"service": ["dhcp4"],
+		"arguments": {
+			"server-name": "server2"
+		}
+	}`, string(bytes))
+}
@@ -3,6 +3,7 @@ package keactrl
 import (
 	errors "github.com/pkg/errors"
 
+	keaconfig "isc.org/stork/daemoncfg/kea"
 	"isc.org/stork/datamodel/daemonname"
 )
 
@@ -24,6 +25,14 @@ const (
 	Lease4GetByHWAddress CommandName = "lease4-get-by-hw-address"
 	Lease4GetByState     CommandName = "lease4-get-by-state"
 	Lease6GetByState     CommandName = "lease6-get-by-state"
+	Lease4Add            CommandName = "lease4-add"
+	Lease6Add            CommandName = "lease6-add"
+	Lease4Update         CommandName = "lease4-update"
+	Lease6Update         CommandName = "lease6-update"
+	Lease4Del            CommandName = "lease4-del"
+	Lease6Del            CommandName = "lease6-del"
+	Lease4Wipe           CommandName = "lease4-wipe"
+	Lease6Wipe           CommandName = "lease6-wipe"
 )
 
 type LeaseState int
@@ -96,6 +105,59 @@ func NewCommandLease6GetByState(state LeaseState) *Command {
 	})
 }
 
+// Creates lease4-add command.
+func NewCommandLease4Add(lease *keaconfig.LeaseCmdsLease) *Command {
+	return newCommand(Lease4Add, daemonname.DHCPv4, lease)
+}
+
+// Creates lease6-add command.
+func NewCommandLease6Add(lease *keaconfig.LeaseCmdsLease) *Command {
+	return newCommand(Lease6Add, daemonname.DHCPv6, lease)
+}
+
+// Creates lease4-update command.
+func NewCommandLease4Update(lease *keaconfig.LeaseCmdsLease) *Command {
+	return newCommand(Lease4Update, daemonname.DHCPv4, lease)
+}
+
+// Creates lease6-update command.
+func NewCommandLease6Update(lease *keaconfig.LeaseCmdsLease) *Command {
+	return newCommand(Lease6Update, daemonname.DHCPv6, lease)
+}
+
+// Creates lease4-del command.
+func NewCommandLease4Del(ipAddress string) *Command {
+	return newCommand(Lease4Del, daemonname.DHCPv4, &keaconfig.LeaseCmdsDeletedLease{
+		IPAddress: ipAddress,
+	})
+}
+
+// Creates lease6-del command.
+func NewCommandLease6Del(leaseType LeaseType, ipAddress string) *Command {
+	return newCommand(Lease6Del, daemonname.DHCPv6, &keaconfig.LeaseCmdsDeletedLease{
+		IPAddress: ipAddress,
+		Type:      string(leaseType),
+	})
+}
+
+// Creates lease4-wipe command. The subnet-id argument is only included
+// when the local subnet ID is greater than 0. Otherwise, the command
+// wipes the leases from all subnets.
+func NewCommandLease4Wipe(localSubnetID int64) *Command {
+	return newCommand(Lease4Wipe, daemonname.DHCPv4, &keaconfig.LeaseCmdsWipedLeases{
+		SubnetID: localSubnetID,
+	})
+}
+
+// Creates lease6-wipe command. The subnet-id argument is only included
+// when the local subnet ID is greater than 0. Otherwise, the command
+// wipes the leases from all subnets.
+func NewCommandLease6Wipe(localSubnetID int64) *Command {
+	return newCommand(Lease6Wipe, daemonname.DHCPv6, &keaconfig.LeaseCmdsWipedLeases{
+		SubnetID: localSubnetID,
+	})
+}
+
 func ParseLeaseState(input string) (LeaseState, error) {
 	switch input {
 	case LeaseStateAssignedStr:
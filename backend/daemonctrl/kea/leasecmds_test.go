@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	require "github.com/stretchr/testify/require"
+
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	storkutil "isc.org/stork/util"
 )
 
 // Tests lease4-get command.
@@ -153,6 +156,195 @@ func TestNewCommandLease6GetByState(t *testing.T) {
 	}`, string(bytes))
 }
 
+// Tests lease4-add command.
+func TestNewCommandLease4Add(t *testing.T) {
+	command := NewCommandLease4Add(&keaconfig.LeaseCmdsLease{
+		IPAddress:     "192.0.2.1",
+		SubnetID:      1,
+		HWAddress:     "01:02:03:04:05:06",
+		ValidLifetime: storkutil.Ptr(int64(3600)),
+		Hostname:      "myhost.example.org",
+	})
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"subnet-id": 1,
+			"hw-address": "01:02:03:04:05:06",
+			"valid-lft": 3600,
+			"hostname": "myhost.example.org"
+		}
+	}`, string(bytes))
+}
+
+// Tests lease6-add command.
+func TestNewCommandLease6Add(t *testing.T) {
+	command := NewCommandLease6Add(&keaconfig.LeaseCmdsLease{
+		IPAddress:    "2001:db8:1::",
+		DUID:         "01:02:03:04",
+		IAID:         storkutil.Ptr(int64(0)),
+		Type:         string(LeaseTypePD),
+		PrefixLength: 64,
+	})
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-add",
+		"service": ["dhcp6"],
+		"arguments": {
+			"ip-address": "2001:db8:1::",
+			"duid": "01:02:03:04",
+			"iaid": 0,
+			"type": "IA_PD",
+			"prefix-len": 64
+		}
+	}`, string(bytes))
+}
+
+// Tests lease4-update command.
+func TestNewCommandLease4Update(t *testing.T) {
+	command := NewCommandLease4Update(&keaconfig.LeaseCmdsLease{
+		IPAddress:   "192.0.2.1",
+		HWAddress:   "01:02:03:04:05:06",
+		State:       storkutil.Ptr(int64(LeaseStateDeclined)),
+		ForceCreate: true,
+	})
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-update",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.1",
+			"hw-address": "01:02:03:04:05:06",
+			"state": 1,
+			"force-create": true
+		}
+	}`, string(bytes))
+}
+
+// Tests lease6-update command.
+func TestNewCommandLease6Update(t *testing.T) {
+	command := NewCommandLease6Update(&keaconfig.LeaseCmdsLease{
+		IPAddress:   "2001:db8:1::1",
+		DUID:        "01:02:03:04",
+		IAID:        storkutil.Ptr(int64(1234)),
+		FqdnFwd:     true,
+		UserContext: map[string]any{"foo": "bar"},
+	})
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-update",
+		"service": ["dhcp6"],
+		"arguments": {
+			"ip-address": "2001:db8:1::1",
+			"duid": "01:02:03:04",
+			"iaid": 1234,
+			"fqdn-fwd": true,
+			"user-context": {
+				"foo": "bar"
+			}
+		}
+	}`, string(bytes))
+}
+
+// Tests lease4-del command.
+func TestNewCommandLease4Del(t *testing.T) {
+	command := NewCommandLease4Del("192.0.2.1")
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.1"
+		}
+	}`, string(bytes))
+}
+
+// Tests lease6-del command.
+func TestNewCommandLease6Del(t *testing.T) {
+	command := NewCommandLease6Del(LeaseTypeNA, "2001:db8:1::1")
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"type": "IA_NA",
+			"ip-address": "2001:db8:1::1"
+		}
+	}`, string(bytes))
+}
+
+// Tests lease4-wipe command for a selected subnet and all subnets.
+func TestNewCommandLease4Wipe(t *testing.T) {
+	command := NewCommandLease4Wipe(123)
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": ["dhcp4"],
+		"arguments": {
+			"subnet-id": 123
+		}
+	}`, string(bytes))
+
+	command = NewCommandLease4Wipe(0)
+	require.NotNil(t, command)
+	bytes, err = command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": ["dhcp4"],
+		"arguments": {}
+	}`, string(bytes))
+}
+
+// Tests lease6-wipe command for a selected subnet and all subnets.
+func TestNewCommandLease6Wipe(t *testing.T) {
+	command := NewCommandLease6Wipe(234)
+	require.NotNil(t, command)
+	require.Len(t, command.Daemons, 1)
+	bytes, err := command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-wipe",
+		"service": ["dhcp6"],
+		"arguments": {
+			"subnet-id": 234
+		}
+	}`, string(bytes))
+
+	command = NewCommandLease6Wipe(0)
+	require.NotNil(t, command)
+	bytes, err = command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-wipe",
+		"service": ["dhcp6"],
+		"arguments": {}
+	}`, string(bytes))
+}
+
 // Tests ParseLeaseState to ensure it handles all valid lease state names.
 func TestParseLeaseState(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,66 @@
+package keadata
+
+import (
+	agentapi "isc.org/stork/api"
+)
+
+// Type of the transition in the lease lifecycle.
+type LeaseEventType string
+
+const (
+	// A lease was assigned to a new client.
+	LeaseEventAssigned LeaseEventType = "assigned"
+	// A client extended its lease.
+	LeaseEventRenewed LeaseEventType = "renewed"
+	// A client released its lease.
+	LeaseEventReleased LeaseEventType = "released"
+	// A lease expired and was reclaimed by the server.
+	LeaseEventExpired LeaseEventType = "expired"
+	// A client declined the leased address.
+	LeaseEventDeclined LeaseEventType = "declined"
+)
+
+// Mapping between the lease event types and their gRPC counterparts.
+var leaseEventTypesToGRPC = map[LeaseEventType]agentapi.LeaseEvent_LeaseEventType{
+	LeaseEventAssigned: agentapi.LeaseEvent_ASSIGNED,
+	LeaseEventRenewed:  agentapi.LeaseEvent_RENEWED,
+	LeaseEventReleased: agentapi.LeaseEvent_RELEASED,
+	LeaseEventExpired:  agentapi.LeaseEvent_EXPIRED,
+	LeaseEventDeclined: agentapi.LeaseEvent_DECLINED,
+}
+
+// Converts the gRPC lease event type to the lease event type. It returns
+// false if the type is unknown.
+func NewLeaseEventTypeFromGRPC(grpcType agentapi.LeaseEvent_LeaseEventType) (LeaseEventType, bool) {
+	for eventType, t := range leaseEventTypesToGRPC {
+		if t == grpcType {
+			return eventType, true
+		}
+	}
+	return "", false
+}
+
+// Represents a transition in the lease lifecycle, e.g., assigning a lease
+// to a client or its expiration.
+type LeaseEvent struct {
+	Type LeaseEventType
+	// The lease state after the transition.
+	Lease Lease
+	// The Unix timestamp (seconds) when the transition occurred.
+	OccurredAt uint64
+	// The Unix timestamp (nanoseconds) when the agent observed the
+	// transition. It is unique for each event observed by the agent.
+	ObservedAt int64
+}
+
+// Convert the LeaseEvent into the LeaseEvent Protobuf structure returned by
+// the agent's gRPC API.
+func (event *LeaseEvent) ToGRPC() *agentapi.LeaseEvent {
+	lease := event.Lease.ToGRPC()
+	return &agentapi.LeaseEvent{
+		Type:       leaseEventTypesToGRPC[event.Type],
+		Lease:      &lease,
+		OccurredAt: event.OccurredAt,
+		ObservedAt: event.ObservedAt,
+	}
+}
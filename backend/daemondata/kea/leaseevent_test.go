@@ -0,0 +1,38 @@
+package keadata
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+
+	agentapi "isc.org/stork/api"
+)
+
+// Test converting the lease event to the gRPC structure and back.
+func TestLeaseEventToGRPC(t *testing.T) {
+	// Arrange
+	event := LeaseEvent{
+		Type:       LeaseEventDeclined,
+		Lease:      NewLease4("192.0.2.1", "01:02:03:04:05:06", "", 1000, 3600, 1, false, false, "", LeaseStateDeclined, nil),
+		OccurredAt: 1000,
+		ObservedAt: 2000,
+	}
+
+	// Act
+	grpcEvent := event.ToGRPC()
+	eventType, ok := NewLeaseEventTypeFromGRPC(grpcEvent.Type)
+
+	// Assert
+	require.Equal(t, agentapi.LeaseEvent_DECLINED, grpcEvent.Type)
+	require.Equal(t, "192.0.2.1", grpcEvent.Lease.IpAddress)
+	require.EqualValues(t, 1000, grpcEvent.OccurredAt)
+	require.EqualValues(t, 2000, grpcEvent.ObservedAt)
+	require.True(t, ok)
+	require.Equal(t, LeaseEventDeclined, eventType)
+}
+
+// Test that the unknown gRPC lease event type is not converted.
+func TestNewLeaseEventTypeFromGRPCUnknown(t *testing.T) {
+	_, ok := NewLeaseEventTypeFromGRPC(agentapi.LeaseEvent_UNKNOWN)
+	require.False(t, ok)
+}
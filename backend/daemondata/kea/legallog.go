@@ -0,0 +1,123 @@
+package keadata
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	agentapi "isc.org/stork/api"
+)
+
+// The layout of the default timestamp format used by the Kea legal log
+// hook (%Y-%m-%d %H:%M:%S %Z).
+const legalLogTimestampLayout = "2006-01-02 15:04:05 MST"
+
+var (
+	// Matches the logged address or prefix and the action, e.g.,
+	// "Address: 192.0.2.1 has been assigned".
+	legalLogActionPattern = regexp.MustCompile(`(?:Address|Prefix): (\S+) has been (\w+)`)
+	// Matches the lease lifetime, e.g., "for 1 hrs 52 min 15 secs".
+	legalLogDurationPattern = regexp.MustCompile(`for (\d+) hrs (\d+) mins? (\d+) secs`)
+	// Matches the hardware address, e.g., "hardware address: hwtype=1 08:00:2b:02:3f:4e".
+	legalLogHWAddressPattern = regexp.MustCompile(`hardware address: hwtype=\d+ ([[:xdigit:]:]+)`)
+	// Matches the DHCPv4 client identifier.
+	legalLogClientIDPattern = regexp.MustCompile(`client-id: ([[:xdigit:]:]+)`)
+	// Matches the DHCPv6 client DUID.
+	legalLogDUIDPattern = regexp.MustCompile(`DUID: ([[:xdigit:]:]+)`)
+)
+
+// Represents a single entry of the forensic (legal) log written by the
+// Kea legal log hook library.
+type LegalLogEntry struct {
+	// The time when the entry was logged. It is zero if the entry uses
+	// a custom timestamp format.
+	Timestamp time.Time
+	// Logged action, e.g., assigned, renewed or released.
+	Action string
+	// IP address or delegated prefix.
+	Address string
+	// Lease lifetime.
+	Duration  time.Duration
+	HWAddress string
+	ClientID  string
+	DUID      string
+	// Full text of the log entry.
+	Text string
+}
+
+// Parses a line of the Kea legal log. The timestamp is interpreted in the
+// specified location if it does not match the zone abbreviation. It returns
+// an error if the line does not describe a lease action.
+func ParseLegalLogEntry(line string, location *time.Location) (*LegalLogEntry, error) {
+	line = strings.TrimSpace(line)
+	match := legalLogActionPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return nil, errors.Errorf("line does not contain a lease action: %s", line)
+	}
+	entry := &LegalLogEntry{
+		Address: line[match[2]:match[3]],
+		Action:  line[match[4]:match[5]],
+		Text:    line,
+	}
+	// The timestamp precedes the action.
+	if timestamp, err := time.ParseInLocation(legalLogTimestampLayout, strings.TrimSpace(line[:match[0]]), location); err == nil {
+		entry.Timestamp = timestamp
+	}
+	if duration := legalLogDurationPattern.FindStringSubmatch(line); duration != nil {
+		var seconds int64
+		for i, unit := range []int64{3600, 60, 1} {
+			value, _ := strconv.ParseInt(duration[i+1], 10, 64)
+			seconds += value * unit
+		}
+		entry.Duration = time.Duration(seconds) * time.Second
+	}
+	if hwAddress := legalLogHWAddressPattern.FindStringSubmatch(line); hwAddress != nil {
+		entry.HWAddress = hwAddress[1]
+	}
+	if clientID := legalLogClientIDPattern.FindStringSubmatch(line); clientID != nil {
+		entry.ClientID = clientID[1]
+	}
+	if duid := legalLogDUIDPattern.FindStringSubmatch(line); duid != nil {
+		entry.DUID = duid[1]
+	}
+	return entry, nil
+}
+
+// Convert the LegalLogEntry into the LegalLogEntry Protobuf structure
+// returned by the agent's gRPC API.
+func (entry *LegalLogEntry) ToGRPC() *agentapi.LegalLogEntry {
+	var timestamp int64
+	if !entry.Timestamp.IsZero() {
+		timestamp = entry.Timestamp.Unix()
+	}
+	return &agentapi.LegalLogEntry{
+		Timestamp: timestamp,
+		Action:    entry.Action,
+		Address:   entry.Address,
+		Duration:  int64(entry.Duration.Seconds()),
+		HwAddress: entry.HWAddress,
+		ClientId:  entry.ClientID,
+		Duid:      entry.DUID,
+		Text:      entry.Text,
+	}
+}
+
+// Creates a LegalLogEntry from the gRPC LegalLogEntry structure.
+func NewLegalLogEntryFromGRPC(grpc *agentapi.LegalLogEntry) *LegalLogEntry {
+	entry := &LegalLogEntry{
+		Action:    grpc.Action,
+		Address:   grpc.Address,
+		Duration:  time.Duration(grpc.Duration) * time.Second,
+		HWAddress: grpc.HwAddress,
+		ClientID:  grpc.ClientId,
+		DUID:      grpc.Duid,
+		Text:      grpc.Text,
+	}
+	if grpc.Timestamp != 0 {
+		entry.Timestamp = time.Unix(grpc.Timestamp, 0).UTC()
+	}
+	return entry
+}
@@ -0,0 +1,79 @@
+package keadata
+
+import (
+	"testing"
+	"time"
+
+	require "github.com/stretchr/testify/require"
+)
+
+// Test parsing the DHCPv4 legal log entry.
+func TestParseLegalLogEntry4(t *testing.T) {
+	line := "2018-01-06 01:02:03 UTC Address: 192.2.1.100 has been renewed for 1 hrs 52 min 15 secs to a device with hardware address: hwtype=1 08:00:2b:02:3f:4e, client-id: 17:34:e2:ff:09:92:54 connected via relay at address: 192.2.16.33"
+
+	entry, err := ParseLegalLogEntry(line, time.UTC)
+
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2018, 1, 6, 1, 2, 3, 0, time.UTC), entry.Timestamp)
+	require.Equal(t, "renewed", entry.Action)
+	require.Equal(t, "192.2.1.100", entry.Address)
+	require.Equal(t, time.Hour+52*time.Minute+15*time.Second, entry.Duration)
+	require.Equal(t, "08:00:2b:02:3f:4e", entry.HWAddress)
+	require.Equal(t, "17:34:e2:ff:09:92:54", entry.ClientID)
+	require.Empty(t, entry.DUID)
+	require.Equal(t, line, entry.Text)
+}
+
+// Test parsing the DHCPv6 legal log entry for a delegated prefix.
+func TestParseLegalLogEntry6(t *testing.T) {
+	line := "2018-01-06 01:02:03 UTC Prefix: 2001:db8:1::/64 has been assigned for 0 hrs 11 mins 53 secs to a device with DUID: 17:34:e2:ff:09:92:54 and hardware address: hwtype=1 08:00:2b:02:3f:4e (from Raw Socket)"
+
+	entry, err := ParseLegalLogEntry(line, time.UTC)
+
+	require.NoError(t, err)
+	require.Equal(t, "assigned", entry.Action)
+	require.Equal(t, "2001:db8:1::/64", entry.Address)
+	require.Equal(t, 11*time.Minute+53*time.Second, entry.Duration)
+	require.Equal(t, "17:34:e2:ff:09:92:54", entry.DUID)
+	require.Equal(t, "08:00:2b:02:3f:4e", entry.HWAddress)
+}
+
+// Test that the entry with a custom timestamp format is parsed without
+// the timestamp.
+func TestParseLegalLogEntryCustomTimestamp(t *testing.T) {
+	entry, err := ParseLegalLogEntry("06/01/2018 Address: 192.2.1.100 has been released from a device with hardware address: hwtype=1 08:00:2b:02:3f:4e", time.UTC)
+
+	require.NoError(t, err)
+	require.Zero(t, entry.Timestamp)
+	require.Equal(t, "released", entry.Action)
+	require.Zero(t, entry.Duration)
+}
+
+// Test that the lines without a lease action are rejected.
+func TestParseLegalLogEntryInvalid(t *testing.T) {
+	_, err := ParseLegalLogEntry("2018-01-06 01:02:03 UTC foo", time.UTC)
+	require.Error(t, err)
+}
+
+// Test converting the legal log entry to the gRPC structure and back.
+func TestLegalLogEntryToGRPC(t *testing.T) {
+	entry := &LegalLogEntry{
+		Timestamp: time.Date(2018, 1, 6, 1, 2, 3, 0, time.UTC),
+		Action:    "assigned",
+		Address:   "192.0.2.1",
+		Duration:  time.Hour,
+		HWAddress: "01:02:03:04:05:06",
+		ClientID:  "01:02",
+		Text:      "foo",
+	}
+
+	grpcEntry := entry.ToGRPC()
+	require.EqualValues(t, 3600, grpcEntry.Duration)
+
+	require.Equal(t, entry, NewLegalLogEntryFromGRPC(grpcEntry))
+
+	// The zero timestamp is preserved.
+	entry.Timestamp = time.Time{}
+	require.Zero(t, entry.ToGRPC().Timestamp)
+	require.Zero(t, NewLegalLogEntryFromGRPC(entry.ToGRPC()).Timestamp)
+}
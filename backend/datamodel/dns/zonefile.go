@@ -0,0 +1,78 @@
+package dnsmodel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// Writes the zone RRs in the master file format (RFC 1035, section 5).
+// The file begins with the $ORIGIN and $TTL directives. The $TTL is taken
+// from the first written RR which is expected to be the SOA. The owner
+// names belonging to the zone are written relative to the origin. Any
+// subsequent SOA RRs (e.g., the trailing SOA returned in the zone transfer)
+// are skipped, so the file contains exactly one SOA.
+type ZoneFileWriter struct {
+	writer     io.Writer
+	origin     string
+	hasHeader  bool
+	soaWritten bool
+}
+
+// Instantiates the writer for the specified zone.
+func NewZoneFileWriter(writer io.Writer, zoneName string) *ZoneFileWriter {
+	return &ZoneFileWriter{
+		writer: writer,
+		origin: dns.Fqdn(strings.ToLower(zoneName)),
+	}
+}
+
+// Returns the owner name relative to the origin. The names outside of
+// the zone are returned unchanged.
+func (w *ZoneFileWriter) getRelativeName(name string) string {
+	fqdn := dns.Fqdn(name)
+	lowerFqdn := strings.ToLower(fqdn)
+	switch {
+	case lowerFqdn == w.origin:
+		return "@"
+	case w.origin == ".":
+		return fqdn
+	case strings.HasSuffix(lowerFqdn, "."+w.origin):
+		return fqdn[:len(fqdn)-len(w.origin)-1]
+	default:
+		return fqdn
+	}
+}
+
+// Writes a single RR. The directives are written before the first RR.
+func (w *ZoneFileWriter) WriteRR(rr *RR) error {
+	if rr.Type == "SOA" {
+		if w.soaWritten {
+			return nil
+		}
+		w.soaWritten = true
+	}
+	if !w.hasHeader {
+		if _, err := fmt.Fprintf(w.writer, "$ORIGIN %s\n$TTL %d\n", w.origin, rr.TTL); err != nil {
+			return errors.Wrap(err, "failed to write the zone file directives")
+		}
+		w.hasHeader = true
+	}
+	if _, err := fmt.Fprintf(w.writer, "%s\t%d\t%s\t%s\t%s\n", w.getRelativeName(rr.Name), rr.TTL, rr.Class, rr.Type, rr.Rdata); err != nil {
+		return errors.Wrapf(err, "failed to write the RR: %s", rr.GetString())
+	}
+	return nil
+}
+
+// Writes the RRs.
+func (w *ZoneFileWriter) WriteRRs(rrs []*RR) error {
+	for _, rr := range rrs {
+		if err := w.WriteRR(rr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package dnsmodel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns the RRs parsed from the strings.
+func newTestZoneFileRRs(t *testing.T, rrTexts ...string) (rrs []*RR) {
+	for _, rrText := range rrTexts {
+		rr, err := NewRR(rrText)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+	return
+}
+
+// Test that the RRs are written in the master file format.
+func TestZoneFileWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewZoneFileWriter(&buffer, "Example.com")
+	err := writer.WriteRRs(newTestZoneFileRRs(t,
+		"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 2025071700 1800 900 604800 86400",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"ns1.example.com. 300 IN A 192.0.2.1",
+		"a.b.EXAMPLE.com. 300 IN TXT \"some text\"",
+		"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 2025071700 1800 900 604800 86400",
+	))
+	require.NoError(t, err)
+
+	require.Equal(t, strings.Join([]string{
+		"$ORIGIN example.com.",
+		"$TTL 3600",
+		"@\t3600\tIN\tSOA\tns1.example.com. hostmaster.example.com. 2025071700 1800 900 604800 86400",
+		"@\t3600\tIN\tNS\tns1.example.com.",
+		"ns1\t300\tIN\tA\t192.0.2.1",
+		"a.b\t300\tIN\tTXT\t\"some text\"",
+		"",
+	}, "\n"), buffer.String())
+
+	// The file should be accepted by the zone file parser.
+	parser := dns.NewZoneParser(&buffer, "", "")
+	var parsed []dns.RR
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		parsed = append(parsed, rr)
+	}
+	require.NoError(t, parser.Err())
+	require.Len(t, parsed, 4)
+	require.True(t, strings.EqualFold("a.b.example.com.", parsed[3].Header().Name))
+}
+
+// Test that the names are not shortened for the root zone.
+func TestZoneFileWriterRootZone(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewZoneFileWriter(&buffer, ".")
+	err := writer.WriteRRs(newTestZoneFileRRs(t,
+		". 86400 IN SOA a.root-servers.net. nstld.verisign-grs.com. 2025071700 1800 900 604800 86400",
+		"com. 172800 IN NS a.gtld-servers.net.",
+	))
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"$ORIGIN .",
+		"$TTL 86400",
+		"@\t86400\tIN\tSOA\ta.root-servers.net. nstld.verisign-grs.com. 2025071700 1800 900 604800 86400",
+		"com.\t172800\tIN\tNS\ta.gtld-servers.net.",
+		"",
+	}, "\n"), buffer.String())
+}
+
+// Test that nothing is written when there are no RRs.
+func TestZoneFileWriterNoRRs(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewZoneFileWriter(&buffer, "example.com")
+	require.NoError(t, writer.WriteRRs(nil))
+	require.Empty(t, buffer.String())
+}
@@ -18,8 +18,10 @@ import (
 
 	agentapi "isc.org/stork/api"
 	bind9config "isc.org/stork/daemoncfg/bind9"
+	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/daemondata/bind9xfr"
+	keadata "isc.org/stork/daemondata/kea"
 	pdnsdata "isc.org/stork/daemondata/pdns"
 	dnsmodel "isc.org/stork/datamodel/dns"
 	dbmodel "isc.org/stork/server/database/model"
@@ -44,11 +46,15 @@ type ConnectedAgents interface {
 	ForwardToNamedStats(ctx context.Context, daemon ControlledDaemon, requestType ForwardToNamedStatsRequestType, statsOutput any) error
 	ForwardToKeaOverHTTP(ctx context.Context, daemon ControlledDaemon, commands []keactrl.SerializableCommand, cmdResponses ...any) (*KeaCmdsResult, error)
 	GetPowerDNSServerInfo(ctx context.Context, daemon ControlledDaemon) (*pdnsdata.ServerInfo, error)
+	GetPowerDNSConfig(ctx context.Context, daemon ControlledDaemon) (*agentapi.GetPowerDNSConfigRsp, error)
 	TailTextFile(ctx context.Context, machine dbmodel.MachineTag, path string, offset int64) ([]string, error)
 	ReceiveZones(ctx context.Context, daemon ControlledDaemon, filter *dnsmodel.ZoneFilter, forcePopulate bool) iter.Seq2[*dnsmodel.ExtendedZone, error]
 	ReceiveZoneRRs(ctx context.Context, daemon ControlledDaemon, zoneName string, viewName string) iter.Seq2[[]*dnsmodel.RR, error]
 	ReceiveBind9FormattedConfig(ctx context.Context, daemon ControlledDaemon, fileSelector *bind9config.FileTypeSelector, filter *bind9config.Filter) iter.Seq2[*agentapi.ReceiveBind9ConfigRsp, error]
 	ReceiveKeaLeases(ctx context.Context, daemon ControlledDaemon, minCLTT uint64) iter.Seq2[*agentapi.ReceiveKeaLeasesRsp, error]
+	ReceiveKeaLeaseEvents(ctx context.Context, daemon ControlledDaemon, minObservedAt int64) iter.Seq2[*agentapi.ReceiveKeaLeaseEventsRsp, error]
+	SearchKeaLegalLog(ctx context.Context, daemon ControlledDaemon, filter *LegalLogFilter) iter.Seq2[*keadata.LegalLogEntry, error]
+	GetKeaConfigDrift(ctx context.Context, daemon ControlledDaemon) (*keaconfig.ConfigDrift, error)
 	ReceiveZoneTransfers(ctx context.Context, daemon ControlledDaemon, follow bool) iter.Seq2[*bind9xfr.State, error]
 }
 
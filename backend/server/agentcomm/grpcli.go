@@ -18,8 +18,10 @@ import (
 
 	agentapi "isc.org/stork/api"
 	bind9config "isc.org/stork/daemoncfg/bind9"
+	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/daemondata/bind9xfr"
+	keadata "isc.org/stork/daemondata/kea"
 	pdnsdata "isc.org/stork/daemondata/pdns"
 	"isc.org/stork/datamodel/daemonname"
 	dnsmodel "isc.org/stork/datamodel/dns"
@@ -988,6 +990,81 @@ func (agents *connectedAgentsImpl) GetPowerDNSServerInfo(ctx context.Context, da
 	return serverInfo, nil
 }
 
+// Returns the PowerDNS server configuration parsed by the agent. The values
+// of the parameters holding secrets are redacted by the agent.
+func (agents *connectedAgentsImpl) GetPowerDNSConfig(ctx context.Context, daemon ControlledDaemon) (*agentapi.GetPowerDNSConfigRsp, error) {
+	addrPort := net.JoinHostPort(daemon.GetMachineTag().GetAddress(), strconv.FormatInt(daemon.GetMachineTag().GetAgentPort(), 10))
+
+	accessPoint, err := daemon.GetAccessPoint(dbmodel.AccessPointControl)
+	if err != nil {
+		return nil, err
+	}
+	req := &agentapi.GetPowerDNSConfigReq{
+		WebserverAddress: accessPoint.Address,
+		WebserverPort:    accessPoint.Port,
+	}
+	agentResponse, err := agents.sendAndRecvViaQueue(addrPort, req)
+	if err != nil {
+		return nil, err
+	}
+	response, ok := agentResponse.(*agentapi.GetPowerDNSConfigRsp)
+	if !ok || response == nil {
+		return nil, errors.Errorf("wrong response to getting PowerDNS configuration from the Stork agent %s", addrPort)
+	}
+	return response, nil
+}
+
+// Returns the differences between the running configuration of the Kea
+// daemon and its configuration file found by the Stork agent. It returns
+// nil if the agent has not compared the configurations, e.g., because it
+// cannot read the configuration file.
+func (agents *connectedAgentsImpl) GetKeaConfigDrift(ctx context.Context, daemon ControlledDaemon) (*keaconfig.ConfigDrift, error) {
+	addrPort := net.JoinHostPort(daemon.GetMachineTag().GetAddress(), strconv.FormatInt(daemon.GetMachineTag().GetAgentPort(), 10))
+
+	accessPoint, err := daemon.GetAccessPoint(dbmodel.AccessPointControl)
+	if err != nil {
+		return nil, err
+	}
+	req := &agentapi.GetKeaConfigDriftReq{
+		ControlAddress: accessPoint.Address,
+		ControlPort:    accessPoint.Port,
+	}
+	agentResponse, err := agents.sendAndRecvViaQueue(addrPort, req)
+	if err != nil {
+		return nil, err
+	}
+	response, ok := agentResponse.(*agentapi.GetKeaConfigDriftRsp)
+	if !ok || response == nil {
+		return nil, errors.Errorf("wrong response to getting Kea configuration drift from the Stork agent %s", addrPort)
+	}
+	if !response.Checked {
+		return nil, nil
+	}
+	drift := &keaconfig.ConfigDrift{
+		ConfigPath: response.ConfigPath,
+		CheckedAt:  time.Unix(response.CheckedAt, 0).UTC(),
+		Entries:    []keaconfig.ConfigDiffEntry{},
+	}
+	for _, entry := range response.Entries {
+		diffEntry := keaconfig.ConfigDiffEntry{
+			Path:      entry.Path,
+			Operation: keaconfig.ConfigDiffOperation(entry.Operation),
+		}
+		if entry.FileValue != "" {
+			if err := json.Unmarshal([]byte(entry.FileValue), &diffEntry.OldValue); err != nil {
+				return nil, errors.Wrapf(err, "invalid value of %s in Kea configuration drift", entry.Path)
+			}
+		}
+		if entry.RunningValue != "" {
+			if err := json.Unmarshal([]byte(entry.RunningValue), &diffEntry.NewValue); err != nil {
+				return nil, errors.Wrapf(err, "invalid value of %s in Kea configuration drift", entry.Path)
+			}
+		}
+		drift.Entries = append(drift.Entries, diffEntry)
+	}
+	return drift, nil
+}
+
 // Get the tail of the remote text file.
 func (agents *connectedAgentsImpl) TailTextFile(ctx context.Context, machine dbmodel.MachineTag, path string, offset int64) ([]string, error) {
 	addrPort := net.JoinHostPort(machine.GetAddress(), strconv.FormatInt(machine.GetAgentPort(), 10))
@@ -1364,6 +1441,138 @@ func (agents *connectedAgentsImpl) ReceiveKeaLeases(ctx context.Context, daemon
 	}
 }
 
+// Make a request to the agent to fetch the lease lifecycle events observed
+// for the Kea daemon after the specified Unix time (nanoseconds). The events
+// are streamed back to the server one at a time, oldest first.
+func (agents *connectedAgentsImpl) ReceiveKeaLeaseEvents(ctx context.Context, daemon ControlledDaemon, minObservedAt int64) iter.Seq2[*agentapi.ReceiveKeaLeaseEventsRsp, error] {
+	return func(yield func(*agentapi.ReceiveKeaLeaseEventsRsp, error) bool) {
+		// Get control access point for the specified daemon. It will be sent
+		// in the request to the agent, so the agent can identify the correct
+		// lease events.
+		accessPoint, err := daemon.GetAccessPoint(dbmodel.AccessPointControl)
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+
+		request := &agentapi.ReceiveKeaLeaseEventsReq{
+			MinObservedAt:  minObservedAt,
+			ControlAddress: accessPoint.Address,
+			ControlPort:    accessPoint.Port,
+		}
+
+		// Get the agent's state. It holds the connection with the agent.
+		agentAddressPort := net.JoinHostPort(daemon.GetMachineTag().GetAddress(), strconv.FormatInt(daemon.GetMachineTag().GetAgentPort(), 10))
+		agent, err := agents.getConnectedAgent(agentAddressPort)
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+
+		var stream grpc.ServerStreamingClient[agentapi.ReceiveKeaLeaseEventsRsp]
+		err = callAgentClientWithRetry(agent, func(client agentapi.AgentClient) (err error) {
+			stream, err = client.ReceiveKeaLeaseEvents(ctx, request)
+			return errors.WithStack(err)
+		})
+		if err != nil {
+			_ = yield(nil, errors.WithMessage(err, "failed to open gRPC connection for receiving Kea lease events from the agent"))
+			return
+		}
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					// Report the error excluding the EOF which is just the end of the stream.
+					_ = yield(nil, errors.Wrap(err, "failed to receive Kea lease events from the agent"))
+				}
+				return
+			}
+			if !yield(response, nil) {
+				// Stop if the caller no longer iterates over the events.
+				return
+			}
+		}
+	}
+}
+
+// Filter selecting the Kea legal log entries. The entries must match all
+// specified criteria. The empty values match all entries. The zero limit
+// means no limit.
+type LegalLogFilter struct {
+	Address   string
+	HWAddress string
+	DUID      string
+	From      time.Time
+	To        time.Time
+	Limit     int64
+}
+
+// Makes a request to search the forensic (legal) log files of the specified
+// Kea daemon. The agent returns the matching entries in the chronological
+// order.
+func (agents *connectedAgentsImpl) SearchKeaLegalLog(ctx context.Context, daemon ControlledDaemon, filter *LegalLogFilter) iter.Seq2[*keadata.LegalLogEntry, error] {
+	return func(yield func(*keadata.LegalLogEntry, error) bool) {
+		// Get control access point for the specified daemon. It will be sent
+		// in the request to the agent, so the agent can identify the correct
+		// Kea server.
+		accessPoint, err := daemon.GetAccessPoint(dbmodel.AccessPointControl)
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+
+		request := &agentapi.SearchKeaLegalLogReq{
+			ControlAddress: accessPoint.Address,
+			ControlPort:    accessPoint.Port,
+			Address:        filter.Address,
+			HwAddress:      filter.HWAddress,
+			Duid:           filter.DUID,
+			Limit:          filter.Limit,
+		}
+		if !filter.From.IsZero() {
+			request.From = filter.From.Unix()
+		}
+		if !filter.To.IsZero() {
+			request.To = filter.To.Unix()
+		}
+
+		// Get the agent's state. It holds the connection with the agent.
+		agentAddressPort := net.JoinHostPort(daemon.GetMachineTag().GetAddress(), strconv.FormatInt(daemon.GetMachineTag().GetAgentPort(), 10))
+		agent, err := agents.getConnectedAgent(agentAddressPort)
+		if err != nil {
+			_ = yield(nil, err)
+			return
+		}
+
+		var stream grpc.ServerStreamingClient[agentapi.SearchKeaLegalLogRsp]
+		err = callAgentClientWithRetry(agent, func(client agentapi.AgentClient) (err error) {
+			stream, err = client.SearchKeaLegalLog(ctx, request)
+			return errors.WithStack(err)
+		})
+		if err != nil {
+			_ = yield(nil, errors.WithMessage(err, "failed to open gRPC connection for searching Kea legal log on the agent"))
+			return
+		}
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					// Report the error excluding the EOF which is just the end of the stream.
+					_ = yield(nil, errors.Wrap(err, "failed to receive Kea legal log entries from the agent"))
+				}
+				return
+			}
+			if response.Entry == nil {
+				continue
+			}
+			if !yield(keadata.NewLegalLogEntryFromGRPC(response.Entry), nil) {
+				// Stop if the caller no longer iterates over the entries.
+				return
+			}
+		}
+	}
+}
+
 // Makes a request to receive the zone transfers recorded by the specified agent.
 // The follow parameter indicates whether the stream should remain open after
 // receiving the existing zone transfers, and used to receive new zone transfers
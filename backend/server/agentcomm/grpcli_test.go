@@ -21,8 +21,10 @@ import (
 	"google.golang.org/grpc/status"
 	agentapi "isc.org/stork/api"
 	bind9config "isc.org/stork/daemoncfg/bind9"
+	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/daemondata/bind9xfr"
+	keadata "isc.org/stork/daemondata/kea"
 	"isc.org/stork/datamodel/daemonname"
 	dnsmodel "isc.org/stork/datamodel/dns"
 	"isc.org/stork/datamodel/protocoltype"
@@ -2462,6 +2464,68 @@ func TestGetPowerDNSServerInfoErrorResponse(t *testing.T) {
 	require.Nil(t, serverInfo)
 }
 
+// Test getting the PowerDNS server configuration.
+func TestGetPowerDNSConfig(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	rsp := &agentapi.GetPowerDNSConfigRsp{
+		ConfigPath: "/etc/powerdns/pdns.conf",
+		Config:     "api=yes\napi-key=********\n",
+	}
+	mockAgentClient.EXPECT().GetPowerDNSConfig(gomock.Any(), &agentapi.GetPowerDNSConfigReq{
+		WebserverAddress: "localhost",
+		WebserverPort:    8000,
+	}, newGZIPMatcher()).Return(rsp, nil)
+
+	config, err := agents.GetPowerDNSConfig(context.Background(), daemon)
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	require.Equal(t, "/etc/powerdns/pdns.conf", config.ConfigPath)
+	require.Equal(t, "api=yes\napi-key=********\n", config.Config)
+}
+
+// Test that an error is returned when getting the PowerDNS server
+// configuration fails.
+func TestGetPowerDNSConfigErrorResponse(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockAgentClient.EXPECT().GetPowerDNSConfig(gomock.Any(), gomock.Any(), newGZIPMatcher()).AnyTimes().Return(nil, &testError{})
+
+	config, err := agents.GetPowerDNSConfig(context.Background(), daemon)
+	require.ErrorContains(t, err, "test error")
+	require.Nil(t, config)
+}
+
 // Test successfully receiving BIND 9 configuration over the stream for
 // a single file type.
 func TestReceiveBind9FormattedConfigOneFile(t *testing.T) {
@@ -3555,3 +3619,241 @@ func TestReceiveZoneTransfersStreamReturnsError(t *testing.T) {
 		require.ErrorContains(t, err, "test error")
 	}
 }
+
+// Verify that ReceiveKeaLeaseEvents streams the lease events received from
+// the agent and sends the observation time in the request.
+func TestReceiveKeaLeaseEvents(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+	mockStreamingClient := NewMockServerStreamingClient[agentapi.ReceiveKeaLeaseEventsRsp](ctrl)
+	gomock.InOrder(
+		mockStreamingClient.EXPECT().Recv().Return(&agentapi.ReceiveKeaLeaseEventsRsp{
+			Event: &agentapi.LeaseEvent{
+				Type:       agentapi.LeaseEvent_ASSIGNED,
+				ObservedAt: 43,
+			},
+		}, nil),
+		mockStreamingClient.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	mockAgentClient.EXPECT().ReceiveKeaLeaseEvents(gomock.Any(), gomock.Cond(func(req *agentapi.ReceiveKeaLeaseEventsReq) bool {
+		return req.MinObservedAt == 42 && req.ControlAddress == "localhost" && req.ControlPort == 8000
+	})).Return(mockStreamingClient, nil)
+
+	var events []*agentapi.LeaseEvent
+	for rsp, err := range agents.ReceiveKeaLeaseEvents(context.Background(), daemon, 42) {
+		require.NoError(t, err)
+		events = append(events, rsp.Event)
+	}
+	require.Len(t, events, 1)
+	require.Equal(t, agentapi.LeaseEvent_ASSIGNED, events[0].Type)
+	require.EqualValues(t, 43, events[0].ObservedAt)
+}
+
+// Verify that ReceiveKeaLeaseEvents propagates the error returned when
+// opening the stream.
+func TestReceiveKeaLeaseEventsGRPCError(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+	mockAgentClient.EXPECT().ReceiveKeaLeaseEvents(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil, &testError{})
+
+	next, cancel := iter.Pull2(agents.ReceiveKeaLeaseEvents(context.Background(), daemon, 0))
+	defer cancel()
+
+	rsp, err, ok := next()
+	require.True(t, ok)
+	require.ErrorContains(t, err, "failed to open gRPC connection for receiving Kea lease events from the agent: test error")
+	require.Nil(t, rsp)
+}
+
+// Verify that the legal log entries are received from the agent and the
+// filter is passed in the request.
+func TestSearchKeaLegalLog(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+	mockStreamingClient := NewMockServerStreamingClient[agentapi.SearchKeaLegalLogRsp](ctrl)
+	gomock.InOrder(
+		mockStreamingClient.EXPECT().Recv().Return(&agentapi.SearchKeaLegalLogRsp{
+			Entry: &agentapi.LegalLogEntry{
+				Timestamp: 1000,
+				Action:    "assigned",
+				Address:   "192.0.2.1",
+			},
+		}, nil),
+		mockStreamingClient.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	mockAgentClient.EXPECT().SearchKeaLegalLog(gomock.Any(), gomock.Cond(func(req *agentapi.SearchKeaLegalLogReq) bool {
+		return req.ControlAddress == "localhost" && req.ControlPort == 8000 &&
+			req.Address == "192.0.2.1" && req.HwAddress == "01:02:03:04:05:06" &&
+			req.From == 900 && req.To == 0 && req.Limit == 10
+	})).Return(mockStreamingClient, nil)
+
+	var entries []*keadata.LegalLogEntry
+	for entry, err := range agents.SearchKeaLegalLog(context.Background(), daemon, &LegalLogFilter{
+		Address:   "192.0.2.1",
+		HWAddress: "01:02:03:04:05:06",
+		From:      time.Unix(900, 0),
+		Limit:     10,
+	}) {
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 1)
+	require.Equal(t, "assigned", entries[0].Action)
+	require.Equal(t, time.Unix(1000, 0).UTC(), entries[0].Timestamp)
+}
+
+// Verify that SearchKeaLegalLog propagates the error returned when
+// opening the stream.
+func TestSearchKeaLegalLogGRPCError(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+	mockAgentClient.EXPECT().SearchKeaLegalLog(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil, &testError{})
+
+	next, cancel := iter.Pull2(agents.SearchKeaLegalLog(context.Background(), daemon, &LegalLogFilter{}))
+	defer cancel()
+
+	entry, err, ok := next()
+	require.True(t, ok)
+	require.ErrorContains(t, err, "failed to open gRPC connection for searching Kea legal log on the agent: test error")
+	require.Nil(t, entry)
+}
+
+// Test that the Kea configuration drift is fetched from the agent and
+// the configuration values are decoded.
+func TestGetKeaConfigDrift(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	rsp := &agentapi.GetKeaConfigDriftRsp{
+		Checked:    true,
+		ConfigPath: "/etc/kea/kea-dhcp4.conf",
+		CheckedAt:  1000,
+		Entries: []*agentapi.KeaConfigDriftEntry{
+			{
+				Path:         "/Dhcp4/valid-lifetime",
+				Operation:    string(keaconfig.ConfigDiffOperationModified),
+				FileValue:    "3600",
+				RunningValue: "7200",
+			},
+			{
+				Path:         "/Dhcp4/subnet4[id=2]",
+				Operation:    string(keaconfig.ConfigDiffOperationAdded),
+				RunningValue: `{"id":2}`,
+			},
+		},
+	}
+	mockAgentClient.EXPECT().GetKeaConfigDrift(gomock.Any(), gomock.Cond(func(req *agentapi.GetKeaConfigDriftReq) bool {
+		return req.ControlAddress == "localhost" && req.ControlPort == 8000
+	}), newGZIPMatcher()).Return(rsp, nil)
+
+	drift, err := agents.GetKeaConfigDrift(context.Background(), daemon)
+	require.NoError(t, err)
+	require.NotNil(t, drift)
+	require.Equal(t, "/etc/kea/kea-dhcp4.conf", drift.ConfigPath)
+	require.Equal(t, time.Unix(1000, 0).UTC(), drift.CheckedAt)
+	require.Len(t, drift.Entries, 2)
+	require.Equal(t, "/Dhcp4/valid-lifetime", drift.Entries[0].Path)
+	require.Equal(t, keaconfig.ConfigDiffOperationModified, drift.Entries[0].Operation)
+	require.EqualValues(t, 3600, drift.Entries[0].OldValue)
+	require.EqualValues(t, 7200, drift.Entries[0].NewValue)
+	require.Equal(t, keaconfig.ConfigDiffOperationAdded, drift.Entries[1].Operation)
+	require.Nil(t, drift.Entries[1].OldValue)
+	require.Equal(t, map[string]any{"id": float64(2)}, drift.Entries[1].NewValue)
+}
+
+// Test that no drift is returned when the agent has not compared the
+// configurations yet.
+func TestGetKeaConfigDriftNotChecked(t *testing.T) {
+	daemon := &dbmodel.Daemon{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockAgentClient, agents := setupGrpcliTestCase(ctrl)
+	defer ctrl.Finish()
+
+	mockAgentClient.EXPECT().GetKeaConfigDrift(gomock.Any(), gomock.Any(), newGZIPMatcher()).Return(&agentapi.GetKeaConfigDriftRsp{}, nil)
+
+	drift, err := agents.GetKeaConfigDrift(context.Background(), daemon)
+	require.NoError(t, err)
+	require.Nil(t, drift)
+}
@@ -85,8 +85,12 @@ func doCall(ctx context.Context, agent *agentState, in interface{}) (interface{}
 		response, err = client.ForwardToKeaOverHTTP(ctx, inData, bigMessageOptions...)
 	case *agentapi.GetPowerDNSServerInfoReq:
 		response, err = client.GetPowerDNSServerInfo(ctx, inData, bigMessageOptions...)
+	case *agentapi.GetPowerDNSConfigReq:
+		response, err = client.GetPowerDNSConfig(ctx, inData, bigMessageOptions...)
 	case *agentapi.TailTextFileReq:
 		response, err = client.TailTextFile(ctx, inData, bigMessageOptions...)
+	case *agentapi.GetKeaConfigDriftReq:
+		response, err = client.GetKeaConfigDrift(ctx, inData, bigMessageOptions...)
 	default:
 		err = errors.New("doCall: unsupported request type")
 	}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"iter"
+	"strings"
 
 	"github.com/pkg/errors"
 	agentapi "isc.org/stork/api"
 	bind9config "isc.org/stork/daemoncfg/bind9"
+	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/daemondata/bind9xfr"
+	keadata "isc.org/stork/daemondata/kea"
 	pdnsdata "isc.org/stork/daemondata/pdns"
 	dnsmodel "isc.org/stork/datamodel/dns"
 	"isc.org/stork/server/agentcomm"
@@ -37,6 +40,17 @@ type FakeAgents struct {
 
 	MachineState   *agentcomm.State
 	GetStateCalled bool
+
+	// Configuration drift returned for all Kea daemons.
+	KeaConfigDrift *keaconfig.ConfigDrift
+
+	// Contents of the named.conf file returned for all BIND 9 daemons.
+	// No configuration file is returned when it is empty.
+	Bind9Config string
+
+	// Contents of the pdns.conf file returned for all PowerDNS daemons.
+	// An error is returned when it is empty.
+	PDNSConfig string
 }
 
 // mockRndcOutput returns some mocked named response.
@@ -242,6 +256,18 @@ func (fa *FakeAgents) GetPowerDNSServerInfo(ctx context.Context, daemon agentcom
 	}, nil
 }
 
+// Returns the configuration specified in the PDNSConfig field as the
+// configuration of the PowerDNS daemon.
+func (fa *FakeAgents) GetPowerDNSConfig(ctx context.Context, daemon agentcomm.ControlledDaemon) (*agentapi.GetPowerDNSConfigRsp, error) {
+	if fa.PDNSConfig == "" {
+		return nil, errors.New("PowerDNS configuration not found")
+	}
+	return &agentapi.GetPowerDNSConfigRsp{
+		ConfigPath: "/etc/powerdns/pdns.conf",
+		Config:     fa.PDNSConfig,
+	}, nil
+}
+
 // Mimics tailing text file.
 func (fa *FakeAgents) TailTextFile(ctx context.Context, machine dbmodel.MachineTag, path string, offset int64) ([]string, error) {
 	return []string{"lorem ipsum"}, nil
@@ -257,8 +283,35 @@ func (fa *FakeAgents) ReceiveZoneRRs(ctx context.Context, daemon agentcomm.Contr
 	return nil
 }
 
+// Returns the configuration specified in the Bind9Config field as the
+// named.conf file.
 func (fa *FakeAgents) ReceiveBind9FormattedConfig(ctx context.Context, daemon agentcomm.ControlledDaemon, fileSelector *bind9config.FileTypeSelector, filter *bind9config.Filter) iter.Seq2[*agentapi.ReceiveBind9ConfigRsp, error] {
-	return nil
+	return func(yield func(*agentapi.ReceiveBind9ConfigRsp, error) bool) {
+		if fa.Bind9Config == "" {
+			return
+		}
+		file := &agentapi.ReceiveBind9ConfigRsp{
+			Response: &agentapi.ReceiveBind9ConfigRsp_File{
+				File: &agentapi.ReceiveBind9ConfigFile{
+					FileType:   agentapi.Bind9ConfigFileType_CONFIG,
+					SourcePath: "/etc/bind/named.conf",
+				},
+			},
+		}
+		if !yield(file, nil) {
+			return
+		}
+		for _, line := range strings.Split(fa.Bind9Config, "\n") {
+			rsp := &agentapi.ReceiveBind9ConfigRsp{
+				Response: &agentapi.ReceiveBind9ConfigRsp_Line{
+					Line: line,
+				},
+			}
+			if !yield(rsp, nil) {
+				return
+			}
+		}
+	}
 }
 
 // Stub function for ReceiveKeaLeases in the interface. The tests do not use
@@ -271,6 +324,31 @@ func (fa *FakeAgents) ReceiveKeaLeases(
 	return nil
 }
 
+// Stub function for ReceiveKeaLeaseEvents in the interface. The tests do not
+// use this method in the interface, so it does not need an implementation.
+func (fa *FakeAgents) ReceiveKeaLeaseEvents(
+	ctx context.Context,
+	daemon agentcomm.ControlledDaemon,
+	minObservedAt int64,
+) iter.Seq2[*agentapi.ReceiveKeaLeaseEventsRsp, error] {
+	return nil
+}
+
+// Stub function for SearchKeaLegalLog in the interface. The tests do not
+// use this method in the interface, so it does not need an implementation.
+func (fa *FakeAgents) SearchKeaLegalLog(
+	ctx context.Context,
+	daemon agentcomm.ControlledDaemon,
+	filter *agentcomm.LegalLogFilter,
+) iter.Seq2[*keadata.LegalLogEntry, error] {
+	return nil
+}
+
+// Returns the configuration drift specified in the KeaConfigDrift field.
+func (fa *FakeAgents) GetKeaConfigDrift(ctx context.Context, daemon agentcomm.ControlledDaemon) (*keaconfig.ConfigDrift, error) {
+	return fa.KeaConfigDrift, nil
+}
+
 func (fa *FakeAgents) ReceiveZoneTransfers(ctx context.Context, daemon agentcomm.ControlledDaemon, follow bool) iter.Seq2[*bind9xfr.State, error] {
 	return func(yield func(*bind9xfr.State, error) bool) {
 	}
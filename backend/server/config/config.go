@@ -9,6 +9,7 @@ import (
 	keaconfig "isc.org/stork/daemoncfg/kea"
 	agentcomm "isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
 )
 
 var _ TransactionStateAccessor = (*TransactionState[any])(nil)
@@ -94,6 +95,8 @@ type KeaModule interface {
 	ApplyHostUpdate(context.Context, *dbmodel.Host) (context.Context, error)
 	BeginHostDelete(context.Context) (context.Context, error)
 	ApplyHostDelete(context.Context, *dbmodel.Host) (context.Context, error)
+	BeginHostImport(context.Context) (context.Context, error)
+	ApplyHostImport(context.Context, []*dbmodel.Host) (context.Context, error)
 	BeginSharedNetworkAdd(context.Context) (context.Context, error)
 	ApplySharedNetworkAdd(context.Context, *dbmodel.SharedNetwork) (context.Context, error)
 	BeginSharedNetworkUpdate(context.Context, int64) (context.Context, error)
@@ -104,6 +107,18 @@ type KeaModule interface {
 	BeginSubnetUpdate(context.Context, int64) (context.Context, error)
 	ApplySubnetUpdate(context.Context, *dbmodel.Subnet) (context.Context, error)
 	ApplySubnetDelete(context.Context, *dbmodel.Subnet) (context.Context, error)
+	ApplyLeaseAdd(context.Context, *dbmodel.Lease) (context.Context, error)
+	ApplyLeaseUpdate(context.Context, *dbmodel.Lease, bool) (context.Context, error)
+	ApplyLeaseDelete(context.Context, *dbmodel.Lease) (context.Context, error)
+	ApplyLeaseWipe(context.Context, *dbmodel.Daemon, int64) (context.Context, error)
+	BeginClientClassAdd(context.Context) (context.Context, error)
+	ApplyClientClassAdd(context.Context, *dbmodel.ClientClass) (context.Context, error)
+	BeginClientClassUpdate(context.Context, int64) (context.Context, error)
+	ApplyClientClassUpdate(context.Context, *dbmodel.ClientClass) (context.Context, error)
+	ApplyClientClassDelete(context.Context, *dbmodel.ClientClass) (context.Context, error)
+	BeginConfigRollback(context.Context, int64) (context.Context, error)
+	ApplyConfigRollback(context.Context, *dbmodel.KeaConfigVersion) (context.Context, error)
+	ComputeConfigChangeDiff(context.Context) ([]dbmodel.ConfigChangeDaemonDiff, error)
 }
 
 // Interface of the Kea configuration module used by the manager to
@@ -131,6 +146,10 @@ type Manager interface {
 	CommitDue() error
 	// Schedules configuration changes to apply them in the future.
 	Schedule(context.Context, time.Time) (context.Context, error)
+	// Submits configuration changes for approval by another user.
+	RequestApproval(context.Context, *time.Time) (context.Context, error)
+	// Commits or schedules configuration changes from the approved request.
+	CommitApproved(*dbmodel.ConfigChangeRequest) error
 }
 
 // Configuration manager interface exposing functions used for getting
@@ -146,6 +165,9 @@ type ManagerAccessors interface {
 	// Returns an interface to the instance providing the daemon
 	// configurations' locking mechanism.
 	GetDaemonLocker() DaemonLocker
+	// Returns an interface to the event center used to notify about
+	// the results of the scheduled configuration changes.
+	GetEventCenter() eventcenter.EventCenter
 }
 
 // Configuration manager interface exposing functions available to the
@@ -23,6 +23,12 @@ const (
 	LockContextKey
 	// A context key for accessing a list of daemon IDs.
 	DaemonsContextKey
+	// A context key for accessing an ID of the scheduled config change
+	// created for the config change transaction.
+	ScheduledConfigChangeIDKey
+	// A context key for accessing an ID of the config change request
+	// created for the config change transaction.
+	ConfigChangeRequestIDKey
 )
 
 // Convenience function retrieving a value from the context. If the context
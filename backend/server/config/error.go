@@ -106,6 +106,23 @@ func (e SubnetNotFoundError) Error() string {
 	return fmt.Sprintf("subnet with ID %d not found", e.subnetID)
 }
 
+// An error returned when specified client class is not found in the database.
+type ClientClassNotFoundError struct {
+	classID int64
+}
+
+// Create new instance of the ClientClassNotFoundError.
+func NewClientClassNotFoundError(classID int64) error {
+	return &ClientClassNotFoundError{
+		classID: classID,
+	}
+}
+
+// Returns error string.
+func (e ClientClassNotFoundError) Error() string {
+	return fmt.Sprintf("client class with ID %d not found", e.classID)
+}
+
 // An error returned when some of the daemons have no libdhcp_subnet_cmds hook
 // library configured.
 type NoSubnetCmdsHookError struct{}
@@ -120,6 +137,53 @@ func (e NoSubnetCmdsHookError) Error() string {
 	return "libdhcp_subnet_cmds hook library not configured for some of the daemons"
 }
 
+// An error returned when some of the daemons have no libdhcp_lease_cmds hook
+// library configured.
+type NoLeaseCmdsHookError struct{}
+
+// Create new instance of the NoLeaseCmdsHookError.
+func NewNoLeaseCmdsHookError() error {
+	return &NoLeaseCmdsHookError{}
+}
+
+// Returns error string.
+func (e NoLeaseCmdsHookError) Error() string {
+	return "libdhcp_lease_cmds hook library not configured for some of the daemons"
+}
+
+// An error returned when some of the daemons have no libdhcp_class_cmds hook
+// library configured.
+type NoClassCmdsHookError struct{}
+
+// Create new instance of the NoClassCmdsHookError.
+func NewNoClassCmdsHookError() error {
+	return &NoClassCmdsHookError{}
+}
+
+// Returns error string.
+func (e NoClassCmdsHookError) Error() string {
+	return "libdhcp_class_cmds hook library not configured for some of the daemons"
+}
+
+// An error returned when the configuration of a daemon using the config
+// backend cannot be rolled back because the stored configuration includes
+// the entities fetched from the database.
+type ConfigBackendRollbackError struct {
+	daemonID int64
+}
+
+// Create new instance of the ConfigBackendRollbackError.
+func NewConfigBackendRollbackError(daemonID int64) error {
+	return &ConfigBackendRollbackError{
+		daemonID: daemonID,
+	}
+}
+
+// Returns error string.
+func (e ConfigBackendRollbackError) Error() string {
+	return fmt.Sprintf("configuration of daemon %d using the config backend cannot be rolled back", e.daemonID)
+}
+
 // An error returned when it was not possible to lock daemons' configuration.
 type LockError struct{}
 
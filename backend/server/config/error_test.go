@@ -52,12 +52,30 @@ func TestSubnetNotFoundError(t *testing.T) {
 	require.EqualError(t, err, "subnet with ID 234 not found")
 }
 
+// Test creation of an error which indicates that client class was not found.
+func TestClientClassNotFoundError(t *testing.T) {
+	err := NewClientClassNotFoundError(345)
+	require.EqualError(t, err, "client class with ID 345 not found")
+}
+
 // Test creation of an error which indicates that libdhcp_subnet_cmds was not configured.
 func TestNoSubnetCmdsHookError(t *testing.T) {
 	err := NewNoSubnetCmdsHookError()
 	require.EqualError(t, err, "libdhcp_subnet_cmds hook library not configured for some of the daemons")
 }
 
+// Test creation of an error which indicates that libdhcp_lease_cmds was not configured.
+func TestNoLeaseCmdsHookError(t *testing.T) {
+	err := NewNoLeaseCmdsHookError()
+	require.EqualError(t, err, "libdhcp_lease_cmds hook library not configured for some of the daemons")
+}
+
+// Test creation of an error which indicates that libdhcp_class_cmds was not configured.
+func TestNoClassCmdsHookError(t *testing.T) {
+	err := NewNoClassCmdsHookError()
+	require.EqualError(t, err, "libdhcp_class_cmds hook library not configured for some of the daemons")
+}
+
 // Test creation of an error which indicates a problem with locking
 // configuration.
 func TestLockError(t *testing.T) {
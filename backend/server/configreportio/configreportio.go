@@ -0,0 +1,394 @@
+// Package configreportio implements writing the configuration review
+// reports in the formats consumed by the CI pipelines. The SARIF (Static
+// Analysis Results Interchange Format) output holds a single run with a
+// rule per config review checker and a result per found issue. The JUnit
+// XML output holds a test suite per daemon and a test case per checker;
+// the test case fails when the checker found an issue. The plain text and
+// JSON outputs are meant for the engineers reviewing the configurations
+// with stork-tool.
+package configreportio
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"isc.org/stork"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Format of the exported config review reports.
+type Format string
+
+const (
+	FormatSARIF Format = "sarif"
+	FormatJUnit Format = "junit"
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+)
+
+// Pattern of the daemon tags inserted into the report contents when the
+// reports are fetched from the database (see dbmodel.ConfigReport).
+//
+//nolint:gochecknoglobals
+var daemonTagPattern = regexp.MustCompile(`<daemon id="(\d+)" name="([^"]*)" machineId="\d+">`)
+
+// Converts a string to the format. It returns an error if the format
+// is not supported.
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(format)) {
+	case FormatSARIF:
+		return FormatSARIF, nil
+	case FormatJUnit:
+		return FormatJUnit, nil
+	case FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", errors.Errorf("unsupported config review reports format %s", format)
+	}
+}
+
+// Returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJUnit:
+		return "application/xml"
+	case FormatText:
+		return "text/plain"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "application/sarif+json"
+	}
+}
+
+// Returns the file extension typically used for the format.
+func (f Format) Extension() string {
+	switch f {
+	case FormatJUnit:
+		return "xml"
+	case FormatText:
+		return "txt"
+	case FormatJSON:
+		return "json"
+	default:
+		return "sarif"
+	}
+}
+
+// Config review reports of a single daemon. The label identifies the
+// daemon in the output, e.g., the daemon name and the machine address.
+// The file is the path to the reviewed configuration file. It is empty
+// when the configuration was fetched from the daemon by the server.
+type DaemonReports struct {
+	DaemonName string
+	Label      string
+	File       string
+	Reports    []dbmodel.ConfigReport
+}
+
+// Creates the config review reports of the daemon fetched from the
+// database. The daemon's machine is used in the label if present.
+func NewDaemonReports(daemon *dbmodel.Daemon, reports []dbmodel.ConfigReport) DaemonReports {
+	label := fmt.Sprintf("%s (ID %d)", daemon.Name, daemon.ID)
+	if daemon.Machine != nil {
+		label = fmt.Sprintf("%s@%s (ID %d)", daemon.Name, daemon.Machine.Address, daemon.ID)
+	}
+	return DaemonReports{
+		DaemonName: string(daemon.Name),
+		Label:      label,
+		Reports:    reports,
+	}
+}
+
+// Returns the report content in the plain text. The daemon tags and the
+// daemon placeholders are replaced with the daemon names.
+func (d DaemonReports) getContent(report *dbmodel.ConfigReport) string {
+	content := daemonTagPattern.ReplaceAllString(*report.Content, "$2 (ID $1)")
+	return strings.ReplaceAll(content, "{daemon}", d.DaemonName)
+}
+
+// Writes the config review reports in the specified format.
+func Write(writer io.Writer, format Format, daemons []DaemonReports) error {
+	switch format {
+	case FormatSARIF:
+		return writeSARIF(writer, daemons)
+	case FormatJUnit:
+		return writeJUnit(writer, daemons)
+	case FormatText:
+		return writeText(writer, daemons)
+	case FormatJSON:
+		return writeJSON(writer, daemons)
+	default:
+		return errors.Errorf("unsupported config review reports format %s", format)
+	}
+}
+
+// SARIF log structures. Only the properties used by the export are
+// defined. See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// Writes the config review reports in the SARIF format. The checkers are
+// the rules. The checkers that found no issues are also listed as rules,
+// so the consumers can tell which checks were performed.
+func writeSARIF(writer io.Writer, daemons []DaemonReports) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "Stork",
+				Version:        stork.Version,
+				InformationURI: "https://stork.readthedocs.io",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+	ruleIndexes := make(map[string]int)
+	for _, daemon := range daemons {
+		for i := range daemon.Reports {
+			report := &daemon.Reports[i]
+			ruleIndex, ok := ruleIndexes[report.CheckerName]
+			if !ok {
+				ruleIndex = len(run.Tool.Driver.Rules)
+				ruleIndexes[report.CheckerName] = ruleIndex
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: report.CheckerName})
+			}
+			if !report.IsIssueFound() {
+				continue
+			}
+			location := sarifLocation{
+				LogicalLocations: []sarifLogicalLocation{{
+					Name: daemon.Label,
+					Kind: "module",
+				}},
+			}
+			if daemon.File != "" {
+				location.PhysicalLocation = &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: daemon.File},
+				}
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    report.CheckerName,
+				RuleIndex: ruleIndex,
+				Level:     "warning",
+				Message:   sarifMessage{Text: daemon.getContent(report)},
+				Locations: []sarifLocation{location},
+			})
+		}
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+	return errors.Wrap(err, "failed to write the config review reports in the SARIF format")
+}
+
+// JUnit XML structures.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Writes the config review reports in the JUnit XML format. The first
+// line of the report content is used as the failure message.
+func writeJUnit(writer io.Writer, daemons []DaemonReports) error {
+	suites := junitTestSuites{
+		Name:   "Stork configuration review",
+		Suites: []junitTestSuite{},
+	}
+	for _, daemon := range daemons {
+		suite := junitTestSuite{
+			Name:      daemon.Label,
+			TestCases: []junitTestCase{},
+		}
+		for i := range daemon.Reports {
+			report := &daemon.Reports[i]
+			testCase := junitTestCase{
+				Name:      report.CheckerName,
+				ClassName: daemon.Label,
+				File:      daemon.File,
+			}
+			if report.IsIssueFound() {
+				content := daemon.getContent(report)
+				message, _, _ := strings.Cut(content, "\n")
+				testCase.Failure = &junitFailure{
+					Message: message,
+					Type:    report.CheckerName,
+					Text:    content,
+				}
+				suite.Failures++
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return errors.Wrap(err, "failed to write the config review reports in the JUnit format")
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return errors.Wrap(err, "failed to write the config review reports in the JUnit format")
+	}
+	_, err := io.WriteString(writer, "\n")
+	return errors.Wrap(err, "failed to write the config review reports in the JUnit format")
+}
+
+// Writes the config review reports in the plain text. Each daemon is
+// followed by the found issues. The subsequent lines of the multi-line
+// report contents are indented.
+func writeText(writer io.Writer, daemons []DaemonReports) error {
+	var builder strings.Builder
+	for _, daemon := range daemons {
+		issues := 0
+		for i := range daemon.Reports {
+			if daemon.Reports[i].IsIssueFound() {
+				issues++
+			}
+		}
+		builder.WriteString(daemon.Label)
+		if daemon.File != "" {
+			fmt.Fprintf(&builder, " (%s)", daemon.File)
+		}
+		fmt.Fprintf(&builder, ": %d checks, %d issues\n", len(daemon.Reports), issues)
+		for i := range daemon.Reports {
+			report := &daemon.Reports[i]
+			if !report.IsIssueFound() {
+				continue
+			}
+			content := strings.ReplaceAll(daemon.getContent(report), "\n", "\n    ")
+			fmt.Fprintf(&builder, "  [%s] %s\n", report.CheckerName, content)
+		}
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return errors.Wrap(err, "failed to write the config review reports in the text format")
+}
+
+// JSON output structures.
+type jsonDaemonReports struct {
+	Daemon  string       `json:"daemon"`
+	File    string       `json:"file,omitempty"`
+	Reports []jsonReport `json:"reports"`
+}
+
+type jsonReport struct {
+	Checker string  `json:"checker"`
+	Content *string `json:"content"`
+}
+
+// Writes the config review reports in the JSON format. The output holds
+// a list of the daemons with the reports of all checkers. The content is
+// null when the checker found no issues.
+func writeJSON(writer io.Writer, daemons []DaemonReports) error {
+	output := []jsonDaemonReports{}
+	for _, daemon := range daemons {
+		daemonReports := jsonDaemonReports{
+			Daemon:  daemon.Label,
+			File:    daemon.File,
+			Reports: []jsonReport{},
+		}
+		for i := range daemon.Reports {
+			report := &daemon.Reports[i]
+			jsonReport := jsonReport{Checker: report.CheckerName}
+			if report.IsIssueFound() {
+				content := daemon.getContent(report)
+				jsonReport.Content = &content
+			}
+			daemonReports.Reports = append(daemonReports.Reports, jsonReport)
+		}
+		output = append(output, daemonReports)
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(output)
+	return errors.Wrap(err, "failed to write the config review reports in the JSON format")
+}
@@ -0,0 +1,227 @@
+package configreportio
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/datamodel/daemonname"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns the config review reports of two daemons used in the tests. The
+// reports of the first daemon come from the database, and the reports of
+// the second daemon come from the offline review of the configuration file.
+func getTestDaemonReports() []DaemonReports {
+	return []DaemonReports{
+		NewDaemonReports(&dbmodel.Daemon{
+			ID:      1,
+			Name:    daemonname.DHCPv4,
+			Machine: &dbmodel.Machine{Address: "192.0.2.1"},
+		}, []dbmodel.ConfigReport{
+			{
+				CheckerName: "lease_cmds_presence",
+			},
+			{
+				CheckerName: "overlapping_subnet",
+				Content:     storkutil.Ptr("Kea <daemon id=\"1\" name=\"dhcp4\" machineId=\"1\"> configuration includes 2 overlapping subnets.\n1. 192.0.2.0/24 and 192.0.2.0/25"),
+			},
+		}),
+		{
+			DaemonName: "dhcp6",
+			Label:      "dhcp6",
+			File:       "kea-dhcp6.conf",
+			Reports: []dbmodel.ConfigReport{
+				{
+					CheckerName: "overlapping_subnet",
+				},
+				{
+					CheckerName: "canonical_prefix",
+					Content:     storkutil.Ptr("Kea {daemon} configuration contains 1 non-canonical prefix."),
+				},
+			},
+		},
+	}
+}
+
+// Test parsing the config review reports format.
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("sarif")
+	require.NoError(t, err)
+	require.Equal(t, FormatSARIF, format)
+	require.Equal(t, "application/sarif+json", format.ContentType())
+	require.Equal(t, "sarif", format.Extension())
+
+	format, err = ParseFormat("JUnit")
+	require.NoError(t, err)
+	require.Equal(t, FormatJUnit, format)
+	require.Equal(t, "application/xml", format.ContentType())
+	require.Equal(t, "xml", format.Extension())
+
+	format, err = ParseFormat("text")
+	require.NoError(t, err)
+	require.Equal(t, FormatText, format)
+	require.Equal(t, "text/plain", format.ContentType())
+	require.Equal(t, "txt", format.Extension())
+
+	format, err = ParseFormat("json")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, format)
+	require.Equal(t, "application/json", format.ContentType())
+	require.Equal(t, "json", format.Extension())
+
+	_, err = ParseFormat("csv")
+	require.ErrorContains(t, err, "unsupported config review reports format csv")
+}
+
+// Test that the daemon label includes the machine address.
+func TestNewDaemonReports(t *testing.T) {
+	daemon := &dbmodel.Daemon{ID: 2, Name: daemonname.Bind9}
+	require.Equal(t, "named (ID 2)", NewDaemonReports(daemon, nil).Label)
+
+	daemon.Machine = &dbmodel.Machine{Address: "ns1.example.org"}
+	daemonReports := NewDaemonReports(daemon, nil)
+	require.Equal(t, "named@ns1.example.org (ID 2)", daemonReports.Label)
+	require.Equal(t, "named", daemonReports.DaemonName)
+}
+
+// Test writing the config review reports in the SARIF format.
+func TestWriteSARIF(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatSARIF, getTestDaemonReports())
+	require.NoError(t, err)
+
+	var sarif map[string]any
+	err = json.Unmarshal(buffer.Bytes(), &sarif)
+	require.NoError(t, err)
+	require.Equal(t, "2.1.0", sarif["version"])
+
+	runs := sarif["runs"].([]any)
+	require.Len(t, runs, 1)
+	run := runs[0].(map[string]any)
+
+	driver := run["tool"].(map[string]any)["driver"].(map[string]any)
+	require.Equal(t, "Stork", driver["name"])
+	require.Equal(t, []any{
+		map[string]any{"id": "lease_cmds_presence"},
+		map[string]any{"id": "overlapping_subnet"},
+		map[string]any{"id": "canonical_prefix"},
+	}, driver["rules"])
+
+	results := run["results"].([]any)
+	require.Len(t, results, 2)
+
+	result := results[0].(map[string]any)
+	require.Equal(t, "overlapping_subnet", result["ruleId"])
+	require.EqualValues(t, 1, result["ruleIndex"])
+	require.Equal(t, "warning", result["level"])
+	require.Equal(t, map[string]any{
+		"text": "Kea dhcp4 (ID 1) configuration includes 2 overlapping subnets.\n1. 192.0.2.0/24 and 192.0.2.0/25",
+	}, result["message"])
+	require.Equal(t, []any{
+		map[string]any{
+			"logicalLocations": []any{
+				map[string]any{"name": "dhcp4@192.0.2.1 (ID 1)", "kind": "module"},
+			},
+		},
+	}, result["locations"])
+
+	result = results[1].(map[string]any)
+	require.Equal(t, "canonical_prefix", result["ruleId"])
+	require.EqualValues(t, 2, result["ruleIndex"])
+	require.Equal(t, map[string]any{
+		"text": "Kea dhcp6 configuration contains 1 non-canonical prefix.",
+	}, result["message"])
+	location := result["locations"].([]any)[0].(map[string]any)
+	require.Equal(t, map[string]any{
+		"artifactLocation": map[string]any{"uri": "kea-dhcp6.conf"},
+	}, location["physicalLocation"])
+}
+
+// Test writing an empty list of the config review reports in the SARIF
+// format.
+func TestWriteSARIFEmpty(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatSARIF, nil)
+	require.NoError(t, err)
+	require.Contains(t, buffer.String(), `"results": []`)
+	require.Contains(t, buffer.String(), `"rules": []`)
+}
+
+// Test writing the config review reports in the JUnit format.
+func TestWriteJUnit(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatJUnit, getTestDaemonReports())
+	require.NoError(t, err)
+
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="Stork configuration review" tests="4" failures="2">
+  <testsuite name="dhcp4@192.0.2.1 (ID 1)" tests="2" failures="1">
+    <testcase name="lease_cmds_presence" classname="dhcp4@192.0.2.1 (ID 1)"></testcase>
+    <testcase name="overlapping_subnet" classname="dhcp4@192.0.2.1 (ID 1)">
+      <failure message="Kea dhcp4 (ID 1) configuration includes 2 overlapping subnets." type="overlapping_subnet">Kea dhcp4 (ID 1) configuration includes 2 overlapping subnets.&#xA;1. 192.0.2.0/24 and 192.0.2.0/25</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="dhcp6" tests="2" failures="1">
+    <testcase name="overlapping_subnet" classname="dhcp6" file="kea-dhcp6.conf"></testcase>
+    <testcase name="canonical_prefix" classname="dhcp6" file="kea-dhcp6.conf">
+      <failure message="Kea dhcp6 configuration contains 1 non-canonical prefix." type="canonical_prefix">Kea dhcp6 configuration contains 1 non-canonical prefix.</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buffer.String())
+}
+
+// Test writing the config review reports in the plain text.
+func TestWriteText(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatText, getTestDaemonReports())
+	require.NoError(t, err)
+
+	require.Equal(t, `dhcp4@192.0.2.1 (ID 1): 2 checks, 1 issues
+  [overlapping_subnet] Kea dhcp4 (ID 1) configuration includes 2 overlapping subnets.
+    1. 192.0.2.0/24 and 192.0.2.0/25
+dhcp6 (kea-dhcp6.conf): 2 checks, 1 issues
+  [canonical_prefix] Kea dhcp6 configuration contains 1 non-canonical prefix.
+`, buffer.String())
+}
+
+// Test writing the config review reports in the JSON format.
+func TestWriteJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, FormatJSON, getTestDaemonReports())
+	require.NoError(t, err)
+
+	require.JSONEq(t, `[
+		{
+			"daemon": "dhcp4@192.0.2.1 (ID 1)",
+			"reports": [
+				{ "checker": "lease_cmds_presence", "content": null },
+				{
+					"checker": "overlapping_subnet",
+					"content": "Kea dhcp4 (ID 1) configuration includes 2 overlapping subnets.\n1. 192.0.2.0/24 and 192.0.2.0/25"
+				}
+			]
+		},
+		{
+			"daemon": "dhcp6",
+			"file": "kea-dhcp6.conf",
+			"reports": [
+				{ "checker": "overlapping_subnet", "content": null },
+				{
+					"checker": "canonical_prefix",
+					"content": "Kea dhcp6 configuration contains 1 non-canonical prefix."
+				}
+			]
+		}
+	]`, buffer.String())
+}
+
+// Test that writing in an unsupported format fails.
+func TestWriteUnsupportedFormat(t *testing.T) {
+	var buffer bytes.Buffer
+	err := Write(&buffer, Format("csv"), nil)
+	require.ErrorContains(t, err, "unsupported config review reports format csv")
+}
@@ -0,0 +1,487 @@
+package configreview
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	storkutil "isc.org/stork/util"
+)
+
+// Maximum number of issues enumerated in a single DNS config report.
+const maxReportedIssues = 10
+
+// Maximum depth of the ACL references followed when evaluating the address
+// match lists. It protects against the ACLs referencing each other.
+const bind9MaxACLNestingLevel = 5
+
+// Returns the BIND 9 configuration of the reviewed daemon or nil if the
+// configuration has not been fetched from the agent.
+func getBind9Config(ctx *ReviewContext) *bind9config.Config {
+	if ctx.subjectDaemon.Bind9Daemon == nil {
+		return nil
+	}
+	return ctx.subjectDaemon.Bind9Daemon.Config
+}
+
+// Returns the views defined in the BIND 9 configuration in the order of
+// their appearance.
+func getBind9Views(config *bind9config.Config) (views []*bind9config.View) {
+	for _, statement := range config.Statements {
+		if statement.View != nil {
+			views = append(views, statement.View)
+		}
+	}
+	return
+}
+
+// Returns the zones defined in the view or the zones defined at the global
+// level if the view is nil.
+func getBind9Zones(config *bind9config.Config, view *bind9config.View) (zones []*bind9config.Zone) {
+	if view == nil {
+		for _, statement := range config.Statements {
+			if statement.Zone != nil {
+				zones = append(zones, statement.Zone)
+			}
+		}
+		return
+	}
+	for _, clause := range view.Clauses {
+		if clause.Zone != nil {
+			zones = append(zones, clause.Zone)
+		}
+	}
+	return
+}
+
+// Checks if the zone is a reference to the zone defined in another view
+// with the in-view clause.
+func isBind9ZoneInView(zone *bind9config.Zone) bool {
+	for _, clause := range zone.Clauses {
+		if clause.Option != nil && clause.Option.Identifier == "in-view" {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the description of the configuration scope used in the reports.
+func describeBind9Scope(view *bind9config.View, zone *bind9config.Zone) string {
+	switch {
+	case zone != nil && view != nil:
+		return fmt.Sprintf(`zone "%s" in view "%s"`, zone.Name, view.Name)
+	case zone != nil:
+		return fmt.Sprintf(`zone "%s"`, zone.Name)
+	case view != nil:
+		return fmt.Sprintf(`view "%s"`, view.Name)
+	default:
+		return "global options"
+	}
+}
+
+// Formats the list of the issues found by a checker. It returns the issues
+// count description (e.g., "2 zones") and the enumerated issues. Only the
+// first few issues are enumerated to keep the report concise.
+func formatIssues(issues []string, noun, postfix string) (string, string) {
+	count := storkutil.FormatNoun(int64(len(issues)), noun, postfix)
+	var enumerated []string
+	for i, issue := range issues {
+		if i == maxReportedIssues {
+			enumerated = append(enumerated, fmt.Sprintf("and %d more", len(issues)-maxReportedIssues))
+			break
+		}
+		enumerated = append(enumerated, fmt.Sprintf("%d. %s", i+1, issue))
+	}
+	return count, strings.Join(enumerated, "; ")
+}
+
+// Checks if the address match list allows any client. The elements are
+// evaluated in order and the first matching element wins, so the list is
+// open when it contains the non-negated any element (directly or in the
+// referenced ACL) before the negated one. Negated specific addresses don't
+// close the list because the remaining clients still match any.
+func isBind9AddressMatchListOpen(config *bind9config.Config, list *bind9config.AddressMatchList, level int) bool {
+	if list == nil || level > bind9MaxACLNestingLevel {
+		return false
+	}
+	for _, element := range list.Elements {
+		open := false
+		switch {
+		case element.KeyID != "":
+			// Only the requests signed with the key match this element.
+			continue
+		case element.ACL != nil:
+			open = isBind9AddressMatchListOpen(config, element.ACL.AddressMatchList, level+1)
+		case element.IPAddressOrACLName == "any":
+			open = true
+		default:
+			if acl := config.GetACL(element.IPAddressOrACLName); acl != nil {
+				open = isBind9AddressMatchListOpen(config, acl.AddressMatchList, level+1)
+			}
+		}
+		if open {
+			// The negated element matching all clients rejects them.
+			return !element.Negation
+		}
+	}
+	return false
+}
+
+// Checks if the address match list allows some clients by their addresses
+// rather than by the TSIG keys. The elements referencing ACLs are evaluated
+// recursively.
+func hasBind9AddressMatchListAddressElements(config *bind9config.Config, list *bind9config.AddressMatchList, level int) bool {
+	if list == nil || level > bind9MaxACLNestingLevel {
+		return false
+	}
+	for _, element := range list.Elements {
+		switch {
+		case element.Negation, element.KeyID != "", element.IPAddressOrACLName == "none":
+			continue
+		case element.ACL != nil:
+			if hasBind9AddressMatchListAddressElements(config, element.ACL.AddressMatchList, level+1) {
+				return true
+			}
+		default:
+			acl := config.GetACL(element.IPAddressOrACLName)
+			if acl == nil {
+				// An IP address, prefix or a built-in ACL (e.g., localnets).
+				return true
+			}
+			if hasBind9AddressMatchListAddressElements(config, acl.AddressMatchList, level+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks if two match-clients clauses may match the same client. The view
+// without the match-clients clause matches all clients. The clauses overlap
+// when any of them matches all clients or they share a non-negated element.
+func areBind9MatchClientsOverlapping(config *bind9config.Config, first, second *bind9config.MatchClients) bool {
+	if first == nil || second == nil ||
+		isBind9AddressMatchListOpen(config, first.AddressMatchList, 0) ||
+		isBind9AddressMatchListOpen(config, second.AddressMatchList, 0) {
+		return true
+	}
+	if first.AddressMatchList == nil || second.AddressMatchList == nil {
+		return false
+	}
+	getElementKey := func(element *bind9config.AddressMatchListElement) string {
+		if element.KeyID != "" {
+			return "key " + element.KeyID
+		}
+		return element.IPAddressOrACLName
+	}
+	firstElements := make(map[string]bool)
+	for _, element := range first.AddressMatchList.Elements {
+		if !element.Negation && element.ACL == nil {
+			firstElements[getElementKey(element)] = true
+		}
+	}
+	for _, element := range second.AddressMatchList.Elements {
+		if !element.Negation && element.ACL == nil && firstElements[getElementKey(element)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks if the recursion is enabled in the view. The view inherits the
+// setting from the global options. Recursion is enabled by default.
+func isBind9RecursionEnabled(options *bind9config.Options, view *bind9config.View) bool {
+	if view != nil {
+		if recursion := view.GetRecursion(); recursion != nil {
+			return *recursion
+		}
+	}
+	if options != nil {
+		if recursion := options.GetRecursion(); recursion != nil {
+			return *recursion
+		}
+	}
+	return true
+}
+
+// Returns the clause controlling which clients can send recursive queries
+// in the view. Each clause is inherited from the global options. When the
+// allow-recursion clause is not specified, BIND 9 uses the
+// allow-query-cache clause, and then the allow-query clause. It returns nil
+// when none of them is specified, i.e., the default (localnets and
+// localhost) is used.
+func getBind9EffectiveAllowRecursion(options *bind9config.Options, view *bind9config.View) *bind9config.AllowClause {
+	for _, variant := range []string{"allow-recursion", "allow-query-cache", "allow-query"} {
+		if view != nil {
+			if clause := view.GetAllowClause(variant); clause != nil {
+				return clause
+			}
+		}
+		if options != nil {
+			if clause := options.GetAllowClause(variant); clause != nil {
+				return clause
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the allow-transfer clause effective for the zone. The zone
+// inherits the clause from the view and the view from the global options.
+func getBind9EffectiveAllowTransfer(options *bind9config.Options, view *bind9config.View, zone *bind9config.Zone) *bind9config.AllowTransfer {
+	if allowTransfer := zone.GetAllowTransfer(); allowTransfer != nil {
+		return allowTransfer
+	}
+	if view != nil {
+		if allowTransfer := view.GetAllowTransfer(); allowTransfer != nil {
+			return allowTransfer
+		}
+	}
+	if options != nil {
+		return options.GetAllowTransfer()
+	}
+	return nil
+}
+
+// The checker verifying if the BIND 9 server allows recursive queries from
+// any client. Open resolvers are commonly abused in DNS amplification
+// attacks.
+func bind9OpenRecursion(ctx *ReviewContext) (*Report, error) {
+	config := getBind9Config(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	options := config.GetOptions()
+	views := getBind9Views(config)
+	if len(views) == 0 {
+		// The global options apply to the default view.
+		views = []*bind9config.View{nil}
+	}
+	var issues []string
+	for _, view := range views {
+		if !isBind9RecursionEnabled(options, view) {
+			continue
+		}
+		allowRecursion := getBind9EffectiveAllowRecursion(options, view)
+		if allowRecursion == nil || !isBind9AddressMatchListOpen(config, allowRecursion.AddressMatchList, 0) {
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("%s (%s)", describeBind9Scope(view, nil), allowRecursion.Variant))
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	count, details := formatIssues(issues, "configuration scope", "s")
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} allows recursive queries "+
+		"from any client in %s. Open resolvers are abused in DNS amplification "+
+		"attacks and expose the cache to poisoning attempts. Restrict recursion "+
+		"to trusted clients with the allow-recursion clause or disable it with "+
+		"recursion no when the server is authoritative only.\n%s", count, details)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the BIND 9 server allows zone transfers to any
+// client. It checks the global options, views and zones.
+func bind9AllowTransferAny(ctx *ReviewContext) (*Report, error) {
+	config := getBind9Config(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	var issues []string
+	isOpen := func(allowTransfer *bind9config.AllowTransfer) bool {
+		return allowTransfer != nil && isBind9AddressMatchListOpen(config, allowTransfer.AddressMatchList, 0)
+	}
+	if options := config.GetOptions(); options != nil && isOpen(options.GetAllowTransfer()) {
+		issues = append(issues, describeBind9Scope(nil, nil))
+	}
+	for _, zone := range getBind9Zones(config, nil) {
+		if isOpen(zone.GetAllowTransfer()) {
+			issues = append(issues, describeBind9Scope(nil, zone))
+		}
+	}
+	for _, view := range getBind9Views(config) {
+		if isOpen(view.GetAllowTransfer()) {
+			issues = append(issues, describeBind9Scope(view, nil))
+		}
+		for _, zone := range getBind9Zones(config, view) {
+			if isOpen(zone.GetAllowTransfer()) {
+				issues = append(issues, describeBind9Scope(view, zone))
+			}
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	count, details := formatIssues(issues, "configuration scope", "s")
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} allows zone transfers "+
+		"to any client in %s. Anyone can download the complete contents of "+
+		"the affected zones, which reveals the internal network structure. "+
+		"Limit the allow-transfer clause to the secondary servers, preferably "+
+		"authenticated with TSIG keys.\n%s", count, details)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the zone transfers from the primary zones are
+// protected with TSIG keys. The zones allowing transfers to any client are
+// not reported because they are reported by the bind9_allow_transfer_any
+// checker.
+func bind9ZoneTransferWithoutTSIG(ctx *ReviewContext) (*Report, error) {
+	config := getBind9Config(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	options := config.GetOptions()
+	var issues []string
+	checkZones := func(view *bind9config.View) {
+		for _, zone := range getBind9Zones(config, view) {
+			if zone.GetType() != "primary" {
+				continue
+			}
+			allowTransfer := getBind9EffectiveAllowTransfer(options, view, zone)
+			if allowTransfer == nil || allowTransfer.AddressMatchList == nil || allowTransfer.IsDisabled() ||
+				isBind9AddressMatchListOpen(config, allowTransfer.AddressMatchList, 0) {
+				continue
+			}
+			if hasBind9AddressMatchListAddressElements(config, allowTransfer.AddressMatchList, 0) {
+				issues = append(issues, describeBind9Scope(view, zone))
+			}
+		}
+	}
+	checkZones(nil)
+	for _, view := range getBind9Views(config) {
+		checkZones(view)
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	count, details := formatIssues(issues, "primary zone", "s")
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} allows transfers of %s "+
+		"based on the client addresses only. The source addresses can be "+
+		"spoofed and the transferred data is not authenticated. Use TSIG keys "+
+		"in the allow-transfer clauses (e.g., allow-transfer { key xfr-key; };) "+
+		"to authenticate the secondary servers.\n%s", count, details)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the BIND 9 statistics channel is enabled. Stork
+// fetches the BIND 9 statistics over this channel.
+func bind9StatisticsChannelsPresence(ctx *ReviewContext) (*Report, error) {
+	config := getBind9Config(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	if _, _, enabled := config.GetStatisticsChannelConnParams(); enabled {
+		return nil, nil
+	}
+	return NewReport(ctx, "The BIND 9 statistics channel provides the server "+
+		"statistics, such as the cache hits and misses, in the JSON format. "+
+		"Stork fetches these statistics from the channel to present them in "+
+		"the UI and to export them to Prometheus. Stork found that {daemon} has "+
+		"no statistics-channels statement with an inet clause. The statistics "+
+		"will not be available until the statistics channel is enabled.").
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// Checks if the address the rndc control channel listens on is a loopback
+// address.
+func isBind9LoopbackAddress(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// The checker verifying if the rndc control channels listening on the
+// non-loopback addresses require the keys.
+func bind9RndcControlsWithoutKey(ctx *ReviewContext) (*Report, error) {
+	config := getBind9Config(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	controls := config.GetControls()
+	if controls == nil {
+		// The default control channel listens on the loopback address.
+		return nil, nil
+	}
+	var issues []string
+	for _, clause := range controls.Clauses {
+		inetClause := clause.InetClause
+		if inetClause == nil || isBind9LoopbackAddress(inetClause.Address) {
+			continue
+		}
+		if inetClause.Keys != nil && len(inetClause.Keys.KeyNames) > 0 {
+			continue
+		}
+		issue := fmt.Sprintf("inet %s", inetClause.Address)
+		if inetClause.Port != nil {
+			issue = fmt.Sprintf("%s port %s", issue, *inetClause.Port)
+		}
+		issues = append(issues, issue)
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	count, details := formatIssues(issues, "control channel", "s")
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} accepts rndc commands on "+
+		"%s listening on non-loopback addresses without the keys specified. "+
+		"The rndc commands can stop the server, reload zones and modify the "+
+		"configuration. Specify the keys in the inet clauses of the controls "+
+		"statement or limit the control channels to the loopback addresses.\n%s", count, details)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the same zone is defined in multiple views
+// which may match the same clients. BIND 9 selects the first matching
+// view, so the zone definitions in the subsequent views are never used
+// for the clients matching multiple views. It is often a mistake. The
+// in-view clause should be used to share the zone between the views.
+func bind9DuplicateZoneOverlappingViews(ctx *ReviewContext) (*Report, error) {
+	config := getBind9Config(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	views := getBind9Views(config)
+	getZoneNames := func(view *bind9config.View) map[string]bool {
+		names := make(map[string]bool)
+		for _, zone := range getBind9Zones(config, view) {
+			if !isBind9ZoneInView(zone) {
+				names[strings.TrimSuffix(strings.ToLower(zone.Name), ".")] = true
+			}
+		}
+		return names
+	}
+	var issues []string
+	for i := range views {
+		firstZones := getZoneNames(views[i])
+		for j := i + 1; j < len(views); j++ {
+			if !areBind9MatchClientsOverlapping(config, views[i].GetMatchClients(), views[j].GetMatchClients()) {
+				continue
+			}
+			for _, zone := range getBind9Zones(config, views[j]) {
+				name := strings.TrimSuffix(strings.ToLower(zone.Name), ".")
+				if firstZones[name] && !isBind9ZoneInView(zone) {
+					issues = append(issues, fmt.Sprintf(`zone "%s" in views "%s" and "%s"`, zone.Name, views[i].Name, views[j].Name))
+				}
+			}
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	count, details := formatIssues(issues, "zone definition", "s")
+	return NewReport(ctx, fmt.Sprintf("BIND 9 {daemon} configuration contains "+
+		"%s duplicated in views with overlapping match-clients clauses. BIND 9 "+
+		"serves the clients from the first matching view, so the zone "+
+		"definitions in the subsequent views are not used for the clients "+
+		"matching both views. Make the match-clients clauses disjoint or use "+
+		"the in-view clause to share the zone between the views.\n%s", count, details)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
@@ -0,0 +1,349 @@
+package configreview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	"isc.org/stork/datamodel/daemonname"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Creates review context for the BIND 9 daemon with the specified
+// named.conf contents.
+func createBind9ReviewContext(t *testing.T, configStr string) *ReviewContext {
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{
+		{
+			Type:    dbmodel.AccessPointControl,
+			Address: "127.0.0.1",
+			Port:    953,
+		},
+	})
+	daemon.ID = 1
+	config, err := bind9config.NewParser().Parse("named.conf", "", strings.NewReader(configStr))
+	require.NoError(t, err)
+	daemon.Bind9Daemon.Config = config
+
+	ctx := newReviewContext(nil, daemon, []Trigger{ManualRun}, nil)
+	require.NotNil(t, ctx)
+
+	return ctx
+}
+
+// Tests that the BIND 9 checkers return no reports when the configuration
+// has not been fetched from the agent.
+func TestBind9CheckersNoConfig(t *testing.T) {
+	ctx := createBind9ReviewContext(t, `options { };`)
+	ctx.subjectDaemon.Bind9Daemon.Config = nil
+
+	for _, checker := range []func(*ReviewContext) (*Report, error){
+		bind9OpenRecursion,
+		bind9AllowTransferAny,
+		bind9ZoneTransferWithoutTSIG,
+		bind9StatisticsChannelsPresence,
+		bind9RndcControlsWithoutKey,
+		bind9DuplicateZoneOverlappingViews,
+	} {
+		report, err := checker(ctx)
+		require.NoError(t, err)
+		require.Nil(t, report)
+	}
+}
+
+// Tests that the open recursion is reported when recursion is enabled by
+// default and allow-query includes any.
+func TestBind9OpenRecursion(t *testing.T) {
+	configStr := `
+		options {
+			allow-query { any; };
+		};
+	`
+	report, err := bind9OpenRecursion(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows recursive queries from any client in 1 configuration scope")
+	require.Contains(t, *report.content, "1. global options (allow-query)")
+}
+
+// Tests that the open recursion is reported for the views and the
+// allow-recursion clause takes precedence over allow-query.
+func TestBind9OpenRecursionViews(t *testing.T) {
+	configStr := `
+		acl "everyone" { any; };
+		options {
+			allow-query { any; };
+			allow-recursion { localnets; };
+		};
+		view "internal" {
+			match-clients { 10.0.0.0/8; };
+		};
+		view "external" {
+			allow-recursion { everyone; };
+		};
+		view "no-recursion" {
+			recursion no;
+			allow-recursion { any; };
+		};
+	`
+	report, err := bind9OpenRecursion(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "1 configuration scope")
+	require.Contains(t, *report.content, `1. view "external" (allow-recursion)`)
+	require.NotContains(t, *report.content, "internal")
+	require.NotContains(t, *report.content, "no-recursion")
+}
+
+// Tests that the open recursion is not reported when the recursion is
+// disabled, restricted or the any element is negated.
+func TestBind9OpenRecursionRestricted(t *testing.T) {
+	for _, configStr := range []string{
+		`options { allow-query { any; }; recursion no; };`,
+		`options { allow-query { any; }; allow-recursion { 192.0.2.0/24; }; };`,
+		`options { allow-recursion { !any; any; }; };`,
+		`options { directory "/var/cache/bind"; };`,
+	} {
+		report, err := bind9OpenRecursion(createBind9ReviewContext(t, configStr))
+		require.NoError(t, err)
+		require.Nil(t, report, configStr)
+	}
+}
+
+// Tests that the allow-transfer clauses allowing any client are reported
+// at all configuration levels.
+func TestBind9AllowTransferAny(t *testing.T) {
+	configStr := `
+		acl "everyone" { any; };
+		options {
+			allow-transfer { any; };
+		};
+		zone "example.com" {
+			type primary;
+			allow-transfer { 192.0.2.1; };
+		};
+		zone "example.org" {
+			type primary;
+			allow-transfer { everyone; };
+		};
+		view "internal" {
+			allow-transfer { none; };
+			zone "example.net" {
+				type primary;
+				allow-transfer { !192.0.2.1; any; };
+			};
+		};
+	`
+	report, err := bind9AllowTransferAny(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows zone transfers to any client in 3 configuration scopes")
+	require.Contains(t, *report.content, "1. global options")
+	require.Contains(t, *report.content, `2. zone "example.org"`)
+	require.Contains(t, *report.content, `3. zone "example.net" in view "internal"`)
+	require.NotContains(t, *report.content, "example.com")
+}
+
+// Tests that no report is generated when the zone transfers are restricted.
+func TestBind9AllowTransferRestricted(t *testing.T) {
+	configStr := `
+		options {
+			allow-transfer { key "xfr"; };
+		};
+		zone "example.com" {
+			type primary;
+			allow-transfer { !any; any; };
+		};
+	`
+	report, err := bind9AllowTransferAny(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the primary zones allowing transfers based on the addresses
+// only are reported.
+func TestBind9ZoneTransferWithoutTSIG(t *testing.T) {
+	configStr := `
+		key "xfr" {
+			algorithm hmac-sha256;
+			secret "LNDU2XlZ4cI5ZXqYBA5XiA==";
+		};
+		acl "secondaries" { 192.0.2.1; };
+		acl "signed" { key "xfr"; };
+		options {
+			allow-transfer { secondaries; };
+		};
+		zone "inherited.example.com" {
+			type primary;
+		};
+		zone "signed.example.com" {
+			type primary;
+			allow-transfer { signed; };
+		};
+		zone "disabled.example.com" {
+			type master;
+			allow-transfer { none; };
+		};
+		zone "secondary.example.com" {
+			type secondary;
+			primaries { 192.0.2.2; };
+		};
+		view "internal" {
+			allow-transfer { key "xfr"; };
+			zone "view.example.com" {
+				type primary;
+			};
+			zone "mixed.example.com" {
+				type primary;
+				allow-transfer { key "xfr"; 192.0.2.3; };
+			};
+		};
+	`
+	report, err := bind9ZoneTransferWithoutTSIG(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows transfers of 2 primary zones")
+	require.Contains(t, *report.content, `1. zone "inherited.example.com"`)
+	require.Contains(t, *report.content, `2. zone "mixed.example.com" in view "internal"`)
+	require.NotContains(t, *report.content, "signed.example.com")
+	require.NotContains(t, *report.content, "disabled.example.com")
+	require.NotContains(t, *report.content, "secondary.example.com")
+	require.NotContains(t, *report.content, "view.example.com")
+}
+
+// Tests that the zones open to any client are not reported by the TSIG
+// checker because they are reported by the allow-transfer checker.
+func TestBind9ZoneTransferWithoutTSIGOpen(t *testing.T) {
+	configStr := `
+		zone "example.com" {
+			type primary;
+			allow-transfer { any; };
+		};
+	`
+	report, err := bind9ZoneTransferWithoutTSIG(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the missing statistics channel is reported.
+func TestBind9StatisticsChannelsPresence(t *testing.T) {
+	report, err := bind9StatisticsChannelsPresence(createBind9ReviewContext(t, `options { };`))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "no statistics-channels statement")
+	require.Len(t, report.refDaemonIDs, 1)
+
+	// Empty statistics-channels statement disables the channel.
+	report, err = bind9StatisticsChannelsPresence(createBind9ReviewContext(t, `statistics-channels { };`))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+
+	report, err = bind9StatisticsChannelsPresence(createBind9ReviewContext(t, `
+		statistics-channels {
+			inet 127.0.0.1 port 8053 allow { 127.0.0.1; };
+		};
+	`))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the control channels listening on non-loopback addresses
+// without the keys are reported.
+func TestBind9RndcControlsWithoutKey(t *testing.T) {
+	configStr := `
+		controls {
+			inet 127.0.0.1 allow { localhost; };
+			inet ::1 allow { localhost; };
+			inet * port 953 allow { any; };
+			inet 192.0.2.1 allow { 192.0.2.0/24; } keys { "rndc-key"; };
+			inet 192.0.2.2 allow { 192.0.2.0/24; };
+		};
+	`
+	report, err := bind9RndcControlsWithoutKey(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "accepts rndc commands on 2 control channels")
+	require.Contains(t, *report.content, "1. inet * port 953")
+	require.Contains(t, *report.content, "2. inet 192.0.2.2")
+	require.NotContains(t, *report.content, "192.0.2.1")
+}
+
+// Tests that the default control channel is not reported.
+func TestBind9RndcControlsDefault(t *testing.T) {
+	report, err := bind9RndcControlsWithoutKey(createBind9ReviewContext(t, `options { };`))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the zones duplicated in the views with overlapping
+// match-clients are reported.
+func TestBind9DuplicateZoneOverlappingViews(t *testing.T) {
+	configStr := `
+		acl "internal-nets" { 10.0.0.0/8; };
+		view "internal" {
+			match-clients { internal-nets; };
+			zone "example.com" { type primary; file "internal.db"; };
+			zone "internal.example.com" { type primary; file "internal-only.db"; };
+		};
+		view "lab" {
+			match-clients { internal-nets; 192.0.2.0/24; };
+			zone "Example.com." { type primary; file "lab.db"; };
+		};
+		view "guest" {
+			match-clients { 198.51.100.0/24; };
+			zone "example.com" { type primary; file "guest.db"; };
+			zone "internal.example.com" { in-view "internal"; };
+		};
+		view "external" {
+			zone "example.com" { type primary; file "external.db"; };
+			zone "internal.example.com" { in-view "internal"; };
+		};
+	`
+	report, err := bind9DuplicateZoneOverlappingViews(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "contains 4 zone definitions duplicated")
+	require.Contains(t, *report.content, `1. zone "Example.com." in views "internal" and "lab"`)
+	require.Contains(t, *report.content, `2. zone "example.com" in views "internal" and "external"`)
+	require.Contains(t, *report.content, `3. zone "example.com" in views "lab" and "external"`)
+	require.Contains(t, *report.content, `4. zone "example.com" in views "guest" and "external"`)
+	require.NotContains(t, *report.content, "internal.example.com")
+}
+
+// Tests that the zones duplicated in the views with disjoint match-clients
+// are not reported.
+func TestBind9DuplicateZoneDisjointViews(t *testing.T) {
+	configStr := `
+		view "internal" {
+			match-clients { 10.0.0.0/8; };
+			zone "example.com" { type primary; file "internal.db"; };
+		};
+		view "external" {
+			match-clients { !10.0.0.0/8; 192.0.2.0/24; };
+			zone "example.com" { type primary; file "external.db"; };
+		};
+	`
+	report, err := bind9DuplicateZoneOverlappingViews(createBind9ReviewContext(t, configStr))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that only the first issues are enumerated in the report.
+func TestFormatBind9Issues(t *testing.T) {
+	issues := make([]string, 12)
+	for i := range issues {
+		issues[i] = "issue"
+	}
+	count, details := formatIssues(issues, "zone", "s")
+	require.Equal(t, "12 zones", count)
+	require.True(t, strings.HasPrefix(details, "1. issue; 2. issue;"))
+	require.True(t, strings.HasSuffix(details, "10. issue; and 2 more"))
+
+	count, details = formatIssues([]string{"issue"}, "zone", "s")
+	require.Equal(t, "1 zone", count)
+	require.Equal(t, "1. issue", details)
+}
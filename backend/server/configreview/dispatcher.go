@@ -71,6 +71,10 @@ const (
 	// Config review is triggered as a result of the configuration change of
 	// the Stork agent.
 	StorkAgentConfigModified Trigger = "Stork agent config change"
+	// Config review is triggered as a result of the change of the
+	// differences between the running configuration and the configuration
+	// file.
+	ConfigDriftModified Trigger = "config drift change"
 )
 
 // Collection of triggers.
@@ -181,11 +185,23 @@ func (s DispatchGroupSelector) String() string {
 		return "kea-dhcp-ddns-daemon"
 	case Bind9Daemon:
 		return "bind9-daemon"
+	case PDNSDaemon:
+		return "pdns-daemon"
 	}
 	log.WithField("selector", fmt.Sprintf("%d", s)).Error("Config review dispatcher was unable to recognize the dispatch group selector and assign any string representation. Please notify the ISC Stork Development Team about this issue.")
 	return "unknown"
 }
 
+// Returns the dispatch group selector by its string representation.
+func ParseDispatchGroupSelector(name string) (DispatchGroupSelector, error) {
+	for selector := EachDaemon; selector <= PDNSDaemon; selector++ {
+		if selector.String() == name {
+			return selector, nil
+		}
+	}
+	return EachDaemon, pkgerrors.Errorf("unknown dispatch group selector %s", name)
+}
+
 // A slice of the DispatchGroupSelector values.
 type DispatchGroupSelectors []DispatchGroupSelector
 
@@ -203,6 +219,7 @@ const (
 	KeaDHCPv6Daemon
 	KeaD2Daemon
 	Bind9Daemon
+	PDNSDaemon
 )
 
 // Returns group selectors for selecting registered checkers appropriate
@@ -225,7 +242,7 @@ func getDispatchGroupSelectors(daemonName daemonname.Name) DispatchGroupSelector
 	case daemonname.Bind9:
 		return DispatchGroupSelectors{EachDaemon, Bind9Daemon}
 	case daemonname.PDNS:
-		return DispatchGroupSelectors{EachDaemon}
+		return DispatchGroupSelectors{EachDaemon, PDNSDaemon}
 	}
 	log.WithFields(log.Fields{
 		"daemon_name": daemonName,
@@ -289,8 +306,10 @@ func (g *dispatchGroup) hasCheckersForTrigger(trigger Trigger) bool {
 // They are segregated into dispatch groups invoked for different daemon
 // types. Checkers are registered and associated with the dispatch
 // groups by their implementers, and the registration takes place before
-// the dispatcher start. The checkers perform specific checks on the
-// selected part of the configuration and generate reports about issues.
+// the dispatcher start. The checkers implementing the user-defined rules
+// can also be registered and unregistered while the dispatcher is
+// running. The checkers perform specific checks on the selected part of
+// the configuration and generate reports about issues.
 // The configuration review can end with a list of reports or an empty
 // list when no issues are found. A caller schedules a review by calling
 // the BeginReview function. It schedules the review and returns
@@ -313,6 +332,10 @@ type dispatcherImpl struct {
 	reviewWg *sync.WaitGroup
 	// Dispatcher main mutex.
 	mutex *sync.RWMutex
+	// Mutex protecting the dispatch groups. The checkers can be registered
+	// and unregistered while the dispatcher is running (e.g., when the
+	// user-defined rules are modified).
+	groupsMutex *sync.RWMutex
 	// Channel for passing ready review reports to the worker
 	// goroutine populating the reports into the database.
 	reviewDoneChan chan *ReviewContext
@@ -457,34 +480,32 @@ func (d *dispatcherImpl) runForDaemon(daemon *dbmodel.Daemon, triggers Triggers,
 	}
 
 	for _, selector := range selectors {
-		if group := d.getGroup(selector); group != nil {
-			for _, checker := range group.checkers {
-				if !d.checkerController.isCheckerEnabledForDaemon(daemon.ID, checker.name) {
-					// Skip disabled checker.
-					continue
-				}
+		for _, checker := range d.getCheckers(selector) {
+			if !d.checkerController.isCheckerEnabledForDaemon(daemon.ID, checker.name) {
+				// Skip disabled checker.
+				continue
+			}
 
-				// Execute checker.
-				report, err := checker.checkFn(ctx)
-				if err != nil {
-					log.WithError(err).Errorf("Malformed report created by the config review checker %s",
-						checker.name)
-				}
+			// Execute checker.
+			report, err := checker.checkFn(ctx)
+			if err != nil {
+				log.WithError(err).Errorf("Malformed report created by the config review checker %s",
+					checker.name)
+			}
 
-				if report == nil {
-					// Create a success report.
-					report, err = newEmptyReport(ctx)
-					if err != nil {
-						log.WithError(err).Errorf("Malformed empty report created for a successful config review")
-					}
+			if report == nil {
+				// Create a success report.
+				report, err = newEmptyReport(ctx)
+				if err != nil {
+					log.WithError(err).Errorf("Malformed empty report created for a successful config review")
 				}
-
-				// Accumulate reports.
-				ctx.reports = append(ctx.reports, taggedReport{
-					checkerName: checker.name,
-					report:      report,
-				})
 			}
+
+			// Accumulate reports.
+			ctx.reports = append(ctx.reports, taggedReport{
+				checkerName: checker.name,
+				report:      report,
+			})
 		}
 	}
 	d.reviewDoneChan <- ctx
@@ -518,6 +539,7 @@ func (d *dispatcherImpl) beginReview(daemon *dbmodel.Daemon, triggers Triggers,
 	if !shouldRun {
 		// Not an internal run. See if there are any checkers for this trigger.
 		dispatchGroupSelectors = getDispatchGroupSelectors(daemon.Name)
+		d.groupsMutex.RLock()
 		for _, selector := range dispatchGroupSelectors {
 			if group := d.getGroup(selector); group != nil {
 				for _, trigger := range triggers {
@@ -528,6 +550,7 @@ func (d *dispatcherImpl) beginReview(daemon *dbmodel.Daemon, triggers Triggers,
 				}
 			}
 		}
+		d.groupsMutex.RUnlock()
 	}
 	if !shouldRun {
 		return false
@@ -662,12 +685,21 @@ func (d *dispatcherImpl) populateReports(ctx *ReviewContext) (err error) {
 		}
 	}
 
-	// Add configuration review summary.
-	// todo: add config review summary for BIND9. Currently we don't because
-	// BIND 9 does not include a config hash.
-	if ctx.subjectDaemon.KeaDaemon != nil {
+	// Add configuration review summary. The BIND 9 and PowerDNS
+	// configurations are not stored in the database, so the hash of the
+	// reviewed configuration is taken from the fetched configuration.
+	var configHash *string
+	switch {
+	case ctx.subjectDaemon.KeaDaemon != nil:
+		configHash = &ctx.subjectDaemon.KeaDaemon.ConfigHash
+	case ctx.subjectDaemon.Bind9Daemon != nil && ctx.subjectDaemon.Bind9Daemon.Config != nil:
+		configHash = &ctx.subjectDaemon.Bind9Daemon.ConfigHash
+	case ctx.subjectDaemon.PDNSDaemon != nil && ctx.subjectDaemon.PDNSDaemon.Config != nil:
+		configHash = &ctx.subjectDaemon.PDNSDaemon.ConfigHash
+	}
+	if configHash != nil {
 		configReview := &dbmodel.ConfigReview{
-			ConfigHash: ctx.subjectDaemon.KeaDaemon.ConfigHash,
+			ConfigHash: *configHash,
 			Signature:  d.GetSignature(),
 			DaemonID:   ctx.subjectDaemon.ID,
 		}
@@ -699,7 +731,7 @@ func (d *dispatcherImpl) populateReports(ctx *ReviewContext) (err error) {
 }
 
 // Returns dispatch group indicated by the selector or nil when such group
-// does not exist.
+// does not exist. The caller must hold the groups mutex.
 func (d *dispatcherImpl) getGroup(selector DispatchGroupSelector) *dispatchGroup {
 	if g, ok := d.groups[selector]; ok {
 		return g
@@ -707,6 +739,18 @@ func (d *dispatcherImpl) getGroup(selector DispatchGroupSelector) *dispatchGroup
 	return nil
 }
 
+// Returns a copy of the checkers registered in the dispatch group indicated
+// by the selector. The copy can be safely used while the checkers are
+// registered or unregistered.
+func (d *dispatcherImpl) getCheckers(selector DispatchGroupSelector) []*checker {
+	d.groupsMutex.RLock()
+	defer d.groupsMutex.RUnlock()
+	if group := d.getGroup(selector); group != nil {
+		return append([]*checker{}, group.checkers...)
+	}
+	return nil
+}
+
 // Creates new dispatcher instance.
 func NewDispatcher(db *dbops.PgDB) Dispatcher {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -716,6 +760,7 @@ func NewDispatcher(db *dbops.PgDB) Dispatcher {
 		shutdownWg:        &sync.WaitGroup{},
 		reviewWg:          &sync.WaitGroup{},
 		mutex:             &sync.RWMutex{},
+		groupsMutex:       &sync.RWMutex{},
 		reviewDoneChan:    make(chan *ReviewContext),
 		dispatchCtx:       ctx,
 		cancelDispatch:    cancel,
@@ -732,6 +777,9 @@ func NewDispatcher(db *dbops.PgDB) Dispatcher {
 // Each checker is assigned a unique name so it will be possible to
 // list available checkers and/or selectively disable them.
 func (d *dispatcherImpl) RegisterChecker(selector DispatchGroupSelector, checkerName string, triggers Triggers, checkFn func(*ReviewContext) (*Report, error)) {
+	d.groupsMutex.Lock()
+	defer d.groupsMutex.Unlock()
+
 	group := d.getGroup(selector)
 	if group == nil {
 		group = newDispatchGroup()
@@ -750,6 +798,9 @@ func (d *dispatcherImpl) RegisterChecker(selector DispatchGroupSelector, checker
 // Unregisters a checker from a dispatch group. It returns a boolean
 // value indicating if the matching checker was found and removed (if true).
 func (d *dispatcherImpl) UnregisterChecker(selector DispatchGroupSelector, checkerName string) bool {
+	d.groupsMutex.Lock()
+	defer d.groupsMutex.Unlock()
+
 	if group := d.getGroup(selector); group != nil {
 		for i := range group.checkers {
 			if group.checkers[i].name == checkerName {
@@ -798,6 +849,9 @@ func (d *dispatcherImpl) GetCheckersMetadata(daemon *dbmodel.Daemon) ([]*Checker
 		}
 	}
 
+	d.groupsMutex.RLock()
+	defer d.groupsMutex.RUnlock()
+
 	for selector, group := range d.groups {
 		if daemon != nil {
 			// Skips the unavailable selector.
@@ -855,6 +909,8 @@ func (d *dispatcherImpl) GetCheckersMetadata(daemon *dbmodel.Daemon) ([]*Checker
 // In this case, bump up the enforceDispatchSeq constant value to enforce
 // generation of a new signature and new config reviews.
 func (d *dispatcherImpl) GetSignature() string {
+	d.groupsMutex.RLock()
+	defer d.groupsMutex.RUnlock()
 	return d.hasher.Hash(d.groups)
 }
 
@@ -862,11 +918,7 @@ func (d *dispatcherImpl) GetSignature() string {
 func (d *dispatcherImpl) isCheckerAvailableForDaemon(checkerName string, daemon *dbmodel.Daemon) bool {
 	selectors := getDispatchGroupSelectors(daemon.Name)
 	for _, selector := range selectors {
-		group := d.getGroup(selector)
-		if group == nil {
-			continue
-		}
-		for _, checker := range group.checkers {
+		for _, checker := range d.getCheckers(selector) {
 			if checker.name == checkerName {
 				return true
 			}
@@ -943,12 +995,24 @@ func RegisterDefaultCheckers(dispatcher Dispatcher) {
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "canonical_prefix", GetDefaultTriggers(), canonicalPrefixes)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_mt_presence", GetDefaultTriggers(), highAvailabilityMultiThreadingMode)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_dedicated_ports", GetDefaultTriggers(), highAvailabilityDedicatedPorts)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_peer_consistency", GetDefaultTriggers(), highAvailabilityPeerConsistency)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "address_pools_exhausted_by_reservations", ExtendDefaultTriggers(DBHostsModified), addressPoolsExhaustedByReservations)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "pd_pools_exhausted_by_reservations", ExtendDefaultTriggers(DBHostsModified), delegatedPrefixPoolsExhaustedByReservations)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "subnet_cmds_and_cb_mutual_exclusion", GetDefaultTriggers(), subnetCmdsAndConfigBackendMutualExclusion)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "statistics_unavailable_due_to_number_overflow", GetDefaultTriggers(), gatheringStatisticsUnavailableDueToNumberOverflow)
 	dispatcher.RegisterChecker(KeaCADaemon, "agent_credentials_over_https", GetDefaultTriggers(), credentialsOverHTTPS)
 	dispatcher.RegisterChecker(KeaCADaemon, "ca_control_sockets", GetDefaultTriggers(), controlSocketsCA)
+	dispatcher.RegisterChecker(KeaDaemon, "config_drift", ExtendDefaultTriggers(ConfigDriftModified), configDrift)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_open_recursion", GetDefaultTriggers(), bind9OpenRecursion)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_allow_transfer_any", GetDefaultTriggers(), bind9AllowTransferAny)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_zone_transfer_without_tsig", GetDefaultTriggers(), bind9ZoneTransferWithoutTSIG)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_statistics_channels_presence", GetDefaultTriggers(), bind9StatisticsChannelsPresence)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_rndc_controls_without_key", GetDefaultTriggers(), bind9RndcControlsWithoutKey)
+	dispatcher.RegisterChecker(Bind9Daemon, "bind9_duplicate_zone_overlapping_views", GetDefaultTriggers(), bind9DuplicateZoneOverlappingViews)
+	dispatcher.RegisterChecker(PDNSDaemon, "pdns_api_key_missing", GetDefaultTriggers(), pdnsAPIKeyMissing)
+	dispatcher.RegisterChecker(PDNSDaemon, "pdns_webserver_public_without_allow_from", GetDefaultTriggers(), pdnsWebserverPublicWithoutAllowFrom)
+	dispatcher.RegisterChecker(PDNSDaemon, "pdns_axfr_open_to_remote", GetDefaultTriggers(), pdnsAXFROpenToRemote)
+	dispatcher.RegisterChecker(PDNSDaemon, "pdns_loglevel_too_low", GetDefaultTriggers(), pdnsLogLevelTooLow)
 }
 
 // Fetches all checker preferences from the database and loads them into
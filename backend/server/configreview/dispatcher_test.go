@@ -677,6 +677,7 @@ func TestRegisterDefaultCheckers(t *testing.T) {
 	require.Contains(t, checkerNames, "out_of_pool_reservation")
 	require.Contains(t, checkerNames, "ha_mt_presence")
 	require.Contains(t, checkerNames, "ha_dedicated_ports")
+	require.Contains(t, checkerNames, "ha_peer_consistency")
 	require.Contains(t, checkerNames, "address_pools_exhausted_by_reservations")
 	require.Contains(t, checkerNames, "pd_pools_exhausted_by_reservations")
 	require.Contains(t, checkerNames, "overlapping_subnet")
@@ -692,20 +693,62 @@ func TestRegisterDefaultCheckers(t *testing.T) {
 	require.Contains(t, checkerNames, "agent_credentials_over_https")
 	require.Contains(t, checkerNames, "ca_control_sockets")
 
+	checkerNames = []string{}
+	for _, p := range dispatcher.groups[KeaDaemon].checkers {
+		checkerNames = append(checkerNames, p.name)
+	}
+
+	require.Contains(t, checkerNames, "config_drift")
+
+	// Bind9Daemon group.
+	require.Contains(t, dispatcher.groups, Bind9Daemon)
+	checkerNames = []string{}
+	for _, p := range dispatcher.groups[Bind9Daemon].checkers {
+		checkerNames = append(checkerNames, p.name)
+	}
+
+	require.Contains(t, checkerNames, "bind9_open_recursion")
+	require.Contains(t, checkerNames, "bind9_allow_transfer_any")
+	require.Contains(t, checkerNames, "bind9_zone_transfer_without_tsig")
+	require.Contains(t, checkerNames, "bind9_statistics_channels_presence")
+	require.Contains(t, checkerNames, "bind9_rndc_controls_without_key")
+	require.Contains(t, checkerNames, "bind9_duplicate_zone_overlapping_views")
+
+	// PDNSDaemon group.
+	require.Contains(t, dispatcher.groups, PDNSDaemon)
+	checkerNames = []string{}
+	for _, p := range dispatcher.groups[PDNSDaemon].checkers {
+		checkerNames = append(checkerNames, p.name)
+	}
+
+	require.Contains(t, checkerNames, "pdns_api_key_missing")
+	require.Contains(t, checkerNames, "pdns_webserver_public_without_allow_from")
+	require.Contains(t, checkerNames, "pdns_axfr_open_to_remote")
+	require.Contains(t, checkerNames, "pdns_loglevel_too_low")
+
 	// Ensure that the appropriate triggers were registered for the
 	// default checkers.
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, ManualRun)
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, ConfigModified)
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, DBHostsModified)
 
-	require.EqualValues(t, 13, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ManualRun])
-	require.EqualValues(t, 13, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 14, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 14, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ConfigModified])
 	require.EqualValues(t, 4, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[DBHostsModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[StorkAgentConfigModified])
 	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[ManualRun])
 	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[ConfigModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaCADaemon].triggerRefCounts[DBHostsModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaCADaemon].triggerRefCounts[StorkAgentConfigModified])
+	require.EqualValues(t, 1, dispatcher.groups[KeaDaemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 1, dispatcher.groups[KeaDaemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 1, dispatcher.groups[KeaDaemon].triggerRefCounts[ConfigDriftModified])
+	require.EqualValues(t, 6, dispatcher.groups[Bind9Daemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 6, dispatcher.groups[Bind9Daemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 0, dispatcher.groups[Bind9Daemon].triggerRefCounts[DBHostsModified])
+	require.EqualValues(t, 4, dispatcher.groups[PDNSDaemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 4, dispatcher.groups[PDNSDaemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 0, dispatcher.groups[PDNSDaemon].triggerRefCounts[DBHostsModified])
 }
 
 // Verifies that registering new checkers and bumping up the
@@ -782,9 +825,23 @@ func TestDispatchGroupSelectorToString(t *testing.T) {
 	require.EqualValues(t, "kea-dhcp-v6-daemon", KeaDHCPv6Daemon.String())
 	require.EqualValues(t, "kea-dhcp-ddns-daemon", KeaD2Daemon.String())
 	require.EqualValues(t, "bind9-daemon", Bind9Daemon.String())
+	require.EqualValues(t, "pdns-daemon", PDNSDaemon.String())
 	require.EqualValues(t, "unknown", DispatchGroupSelector(42).String())
 }
 
+// Test that the dispatch group selectors are parsed from their string
+// representations.
+func TestParseDispatchGroupSelector(t *testing.T) {
+	for _, selector := range []DispatchGroupSelector{EachDaemon, KeaDaemon, KeaDHCPv4Daemon, Bind9Daemon, PDNSDaemon} {
+		parsed, err := ParseDispatchGroupSelector(selector.String())
+		require.NoError(t, err)
+		require.Equal(t, selector, parsed)
+	}
+
+	_, err := ParseDispatchGroupSelector("unknown")
+	require.ErrorContains(t, err, "unknown dispatch group selector unknown")
+}
+
 // Test that the config checkers metadata are returned properly..
 func TestGetCheckersMetadata(t *testing.T) {
 	// Arrange
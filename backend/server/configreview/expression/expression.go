@@ -0,0 +1,627 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/pkg/errors"
+)
+
+// Name of the variable holding the current list element in the predicates
+// of the list functions (e.g., any(list, it > 1)).
+const elementVariable = "it"
+
+var (
+	// The lexer tokenizing the expressions. The lowercase rules are elided.
+	//nolint:gochecknoglobals
+	expressionLexer = lexer.MustSimple([]lexer.SimpleRule{
+		{Name: "whitespace", Pattern: `[ \t\r\n]+`},
+		{Name: "Number", Pattern: `[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?`},
+		{Name: "String", Pattern: `"(\\.|[^"\\])*"|'(\\.|[^'\\])*'`},
+		{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z0-9_]*`},
+		{Name: "Operator", Pattern: `==|!=|<=|>=|&&|\|\||[-+*/%<>!.,()\[\]]`},
+	})
+
+	// The expression parser.
+	//nolint:gochecknoglobals
+	expressionParser = participle.MustBuild[orExpr](
+		participle.Lexer(expressionLexer),
+		participle.Unquote("String"),
+		// The function calls and variables both begin with an identifier.
+		participle.UseLookahead(2),
+	)
+)
+
+// Logical alternative of the expressions.
+type orExpr struct {
+	Left  *andExpr   `parser:"@@"`
+	Right []*andExpr `parser:"( '||' @@ )*"`
+}
+
+// Logical conjunction of the expressions.
+type andExpr struct {
+	Left  *comparisonExpr   `parser:"@@"`
+	Right []*comparisonExpr `parser:"( '&&' @@ )*"`
+}
+
+// Comparison of two values or a membership test (in operator).
+type comparisonExpr struct {
+	Left  *additiveExpr `parser:"@@"`
+	Op    string        `parser:"( @( '==' | '!=' | '<=' | '>=' | '<' | '>' | 'in' )"`
+	Right *additiveExpr `parser:"  @@ )?"`
+}
+
+// Addition or subtraction of the values.
+type additiveExpr struct {
+	Left  *multiplicativeExpr `parser:"@@"`
+	Right []*additiveOperand  `parser:"@@*"`
+}
+
+// Right-hand operand of the addition or subtraction.
+type additiveOperand struct {
+	Op      string              `parser:"@( '+' | '-' )"`
+	Operand *multiplicativeExpr `parser:"@@"`
+}
+
+// Multiplication, division or modulo of the values.
+type multiplicativeExpr struct {
+	Left  *unaryExpr               `parser:"@@"`
+	Right []*multiplicativeOperand `parser:"@@*"`
+}
+
+// Right-hand operand of the multiplication, division or modulo.
+type multiplicativeOperand struct {
+	Op      string     `parser:"@( '*' | '/' | '%' )"`
+	Operand *unaryExpr `parser:"@@"`
+}
+
+// Negation of the value or a postfix expression.
+type unaryExpr struct {
+	Op      string       `parser:"  ( @( '!' | '-' )"`
+	Operand *unaryExpr   `parser:"    @@ )"`
+	Postfix *postfixExpr `parser:"| @@"`
+}
+
+// Primary expression followed by the field accesses and indexes.
+type postfixExpr struct {
+	Primary   *primaryExpr `parser:"@@"`
+	Accessors []*accessor  `parser:"@@*"`
+}
+
+// Field access (e.g., subnet.id) or index (e.g., subnet["valid-lifetime"]).
+type accessor struct {
+	Field *string `parser:"  '.' @Ident"`
+	Index *orExpr `parser:"| '[' @@ ']'"`
+}
+
+// Literal, function call, variable, list or parenthesized expression.
+type primaryExpr struct {
+	Number   *float64  `parser:"  @Number"`
+	String   *string   `parser:"| @String"`
+	Keyword  *string   `parser:"| @( 'true' | 'false' | 'null' )"`
+	Call     *callExpr `parser:"| @@"`
+	Variable *string   `parser:"| @Ident"`
+	List     *listExpr `parser:"| @@"`
+	Group    *orExpr   `parser:"| '(' @@ ')'"`
+}
+
+// Function call.
+type callExpr struct {
+	Function string    `parser:"@Ident '('"`
+	Args     []*orExpr `parser:"( @@ ( ',' @@ )* )? ')'"`
+}
+
+// List literal.
+type listExpr struct {
+	Items []*orExpr `parser:"'[' ( @@ ( ',' @@ )* )? ']'"`
+}
+
+// Compiled expression. The expression language supports:
+//
+//   - literals: numbers, strings in single or double quotes, true, false,
+//     null and lists (e.g., [1, 2, 3]),
+//   - variables supplied by the caller and the field accesses (e.g.,
+//     subnet.id or subnet["valid-lifetime"]),
+//   - arithmetic operators: +, -, *, /, %,
+//   - comparison operators: ==, !=, <, <=, >, >=,
+//   - membership operator: in (an element of a list, a key of a map or
+//     a substring),
+//   - logical operators: &&, ||, !,
+//   - functions listed in the functions map.
+//
+// Accessing a missing field or a list element out of range yields null.
+type Expression struct {
+	source string
+	root   *orExpr
+}
+
+// Compiles the expression. The variables are the names of the variables
+// the expression may refer to. It returns an error if the expression is
+// malformed, refers to an unknown variable or calls an unknown function.
+func Compile(source string, variables ...string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, errors.New("expression must not be empty")
+	}
+	root, err := expressionParser.ParseString("", source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse expression %s", source)
+	}
+	known := make(map[string]bool, len(variables))
+	for _, variable := range variables {
+		known[variable] = true
+	}
+	if err = root.validate(known); err != nil {
+		return nil, errors.WithMessagef(err, "invalid expression %s", source)
+	}
+	return &Expression{
+		source: source,
+		root:   root,
+	}, nil
+}
+
+// Returns the source text of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluates the expression for the specified variable values.
+func (e *Expression) Evaluate(variables map[string]any) (any, error) {
+	return e.root.eval(newScope(variables, nil))
+}
+
+// Evaluates the expression and ensures that it returns a boolean value.
+func (e *Expression) EvaluateBool(variables map[string]any) (bool, error) {
+	value, err := e.Evaluate(variables)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, errors.Errorf("expression %s returned %s instead of a boolean value", e.source, Format(value))
+	}
+	return result, nil
+}
+
+// Variables available during the evaluation. The nested scopes are created
+// for the list function predicates.
+type scope struct {
+	variables map[string]any
+	parent    *scope
+}
+
+// Creates a new scope.
+func newScope(variables map[string]any, parent *scope) *scope {
+	return &scope{
+		variables: variables,
+		parent:    parent,
+	}
+}
+
+// Returns the value of the variable. It looks up the variable in the
+// parent scopes if it is not defined in this scope.
+func (s *scope) lookup(name string) (any, error) {
+	for current := s; current != nil; current = current.parent {
+		if value, ok := current.variables[name]; ok {
+			return normalize(value), nil
+		}
+	}
+	return nil, errors.Errorf("variable %s is not set", name)
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *orExpr) validate(known map[string]bool) error {
+	if err := e.Left.validate(known); err != nil {
+		return err
+	}
+	for _, right := range e.Right {
+		if err := right.validate(known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *andExpr) validate(known map[string]bool) error {
+	if err := e.Left.validate(known); err != nil {
+		return err
+	}
+	for _, right := range e.Right {
+		if err := right.validate(known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *comparisonExpr) validate(known map[string]bool) error {
+	if err := e.Left.validate(known); err != nil {
+		return err
+	}
+	if e.Right != nil {
+		return e.Right.validate(known)
+	}
+	return nil
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *additiveExpr) validate(known map[string]bool) error {
+	if err := e.Left.validate(known); err != nil {
+		return err
+	}
+	for _, right := range e.Right {
+		if err := right.Operand.validate(known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *multiplicativeExpr) validate(known map[string]bool) error {
+	if err := e.Left.validate(known); err != nil {
+		return err
+	}
+	for _, right := range e.Right {
+		if err := right.Operand.validate(known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *unaryExpr) validate(known map[string]bool) error {
+	if e.Operand != nil {
+		return e.Operand.validate(known)
+	}
+	return e.Postfix.validate(known)
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *postfixExpr) validate(known map[string]bool) error {
+	if err := e.Primary.validate(known); err != nil {
+		return err
+	}
+	for _, accessor := range e.Accessors {
+		if accessor.Index != nil {
+			if err := accessor.Index.validate(known); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Checks that the expression refers to the known variables and functions.
+func (e *primaryExpr) validate(known map[string]bool) error {
+	switch {
+	case e.Call != nil:
+		return e.Call.validate(known)
+	case e.Variable != nil:
+		if !known[*e.Variable] {
+			return errors.Errorf("unknown variable %s", *e.Variable)
+		}
+	case e.List != nil:
+		for _, item := range e.List.Items {
+			if err := item.validate(known); err != nil {
+				return err
+			}
+		}
+	case e.Group != nil:
+		return e.Group.validate(known)
+	}
+	return nil
+}
+
+// Checks that the function exists, it is called with the correct number
+// of arguments and the arguments refer to the known variables and
+// functions. The predicate of the list functions may also refer to the
+// current list element.
+func (e *callExpr) validate(known map[string]bool) error {
+	function, ok := functions[e.Function]
+	if !ok {
+		return errors.Errorf("unknown function %s", e.Function)
+	}
+	if len(e.Args) < function.minArgs || len(e.Args) > function.maxArgs {
+		if function.minArgs == function.maxArgs {
+			return errors.Errorf("function %s expects %d argument(s) but %d were specified",
+				e.Function, function.minArgs, len(e.Args))
+		}
+		return errors.Errorf("function %s expects %d to %d arguments but %d were specified",
+			e.Function, function.minArgs, function.maxArgs, len(e.Args))
+	}
+	for i, arg := range e.Args {
+		argKnown := known
+		if function.predicate != nil && i == 1 {
+			argKnown = make(map[string]bool, len(known)+1)
+			for name := range known {
+				argKnown[name] = true
+			}
+			argKnown[elementVariable] = true
+		}
+		if err := arg.validate(argKnown); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluates the logical alternative. The right-hand operands are not
+// evaluated if the left-hand operand is true.
+func (e *orExpr) eval(s *scope) (any, error) {
+	left, err := e.Left.eval(s)
+	if err != nil || len(e.Right) == 0 {
+		return left, err
+	}
+	operands := append([]*andExpr{e.Left}, e.Right...)
+	for i, operand := range operands {
+		value := left
+		if i > 0 {
+			if value, err = operand.eval(s); err != nil {
+				return nil, err
+			}
+		}
+		result, ok := value.(bool)
+		if !ok {
+			return nil, errors.Errorf("operator || expects boolean operands but got %s", Format(value))
+		}
+		if result {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Evaluates the logical conjunction. The right-hand operands are not
+// evaluated if the left-hand operand is false.
+func (e *andExpr) eval(s *scope) (any, error) {
+	left, err := e.Left.eval(s)
+	if err != nil || len(e.Right) == 0 {
+		return left, err
+	}
+	operands := append([]*comparisonExpr{e.Left}, e.Right...)
+	for i, operand := range operands {
+		value := left
+		if i > 0 {
+			if value, err = operand.eval(s); err != nil {
+				return nil, err
+			}
+		}
+		result, ok := value.(bool)
+		if !ok {
+			return nil, errors.Errorf("operator && expects boolean operands but got %s", Format(value))
+		}
+		if !result {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Evaluates the comparison or the membership test.
+func (e *comparisonExpr) eval(s *scope) (any, error) {
+	left, err := e.Left.eval(s)
+	if err != nil || e.Right == nil {
+		return left, err
+	}
+	right, err := e.Right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	default:
+		return compare(e.Op, left, right)
+	}
+}
+
+// Evaluates the addition or subtraction. The strings are concatenated
+// with the + operator.
+func (e *additiveExpr) eval(s *scope) (any, error) {
+	result, err := e.Left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, right := range e.Right {
+		operand, err := right.Operand.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if leftString, ok := result.(string); ok && right.Op == "+" {
+			if rightString, ok := operand.(string); ok {
+				result = leftString + rightString
+				continue
+			}
+		}
+		if result, err = arithmetic(right.Op, result, operand); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Evaluates the multiplication, division or modulo.
+func (e *multiplicativeExpr) eval(s *scope) (any, error) {
+	result, err := e.Left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, right := range e.Right {
+		operand, err := right.Operand.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = arithmetic(right.Op, result, operand); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Evaluates the negation.
+func (e *unaryExpr) eval(s *scope) (any, error) {
+	if e.Operand == nil {
+		return e.Postfix.eval(s)
+	}
+	value, err := e.Operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case "!":
+		if result, ok := value.(bool); ok {
+			return !result, nil
+		}
+	case "-":
+		if result, ok := value.(float64); ok {
+			return -result, nil
+		}
+	}
+	return nil, errors.Errorf("operator %s cannot be applied to %s", e.Op, Format(value))
+}
+
+// Evaluates the field accesses and indexes.
+func (e *postfixExpr) eval(s *scope) (any, error) {
+	value, err := e.Primary.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, accessor := range e.Accessors {
+		var key any
+		if accessor.Field != nil {
+			key = *accessor.Field
+		} else if key, err = accessor.Index.eval(s); err != nil {
+			return nil, err
+		}
+		if value, err = index(value, key); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// Evaluates the primary expression.
+func (e *primaryExpr) eval(s *scope) (any, error) {
+	switch {
+	case e.Number != nil:
+		return *e.Number, nil
+	case e.String != nil:
+		return *e.String, nil
+	case e.Keyword != nil:
+		switch *e.Keyword {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, nil
+	case e.Call != nil:
+		return e.Call.eval(s)
+	case e.Variable != nil:
+		return s.lookup(*e.Variable)
+	case e.List != nil:
+		list := []any{}
+		for _, item := range e.List.Items {
+			value, err := item.eval(s)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	default:
+		return e.Group.eval(s)
+	}
+}
+
+// Evaluates the function call. The predicate of the list functions is
+// evaluated for each list element.
+func (e *callExpr) eval(s *scope) (any, error) {
+	function, ok := functions[e.Function]
+	if !ok {
+		return nil, errors.Errorf("unknown function %s", e.Function)
+	}
+	var args []any
+	for i, arg := range e.Args {
+		if function.predicate != nil && i == 1 {
+			break
+		}
+		value, err := arg.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	if function.predicate == nil {
+		result, err := function.call(args)
+		return result, errors.WithMessagef(err, "function %s failed", e.Function)
+	}
+	var list []any
+	switch value := args[0].(type) {
+	case nil:
+	case []any:
+		list = value
+	default:
+		return nil, errors.Errorf("function %s expects a list but got %s", e.Function, Format(value))
+	}
+	var matches []bool
+	for _, element := range list {
+		value, err := e.Args[1].eval(newScope(map[string]any{elementVariable: element}, s))
+		if err != nil {
+			return nil, err
+		}
+		match, ok := value.(bool)
+		if !ok {
+			return nil, errors.Errorf("predicate of the function %s returned %s instead of a boolean value",
+				e.Function, Format(value))
+		}
+		matches = append(matches, match)
+	}
+	return function.predicate(matches), nil
+}
+
+// Returns the text representation of the value. The strings are returned
+// as is, the lists and maps in the JSON-like format.
+func Format(value any) string {
+	switch v := normalize(value).(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case []any:
+		var items []string
+		for _, item := range v {
+			items = append(items, formatNested(item))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+	case map[string]any:
+		var items []string
+		for _, key := range sortedKeys(v) {
+			items = append(items, fmt.Sprintf("%q: %s", key, formatNested(v[key])))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(items, ", "))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Returns the text representation of the value nested in a list or map.
+// The strings are quoted.
+func formatNested(value any) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return Format(value)
+}
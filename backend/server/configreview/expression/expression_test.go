@@ -0,0 +1,176 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Returns the variables used in the tests.
+func getTestVariables() map[string]any {
+	return map[string]any{
+		"subnet": map[string]any{
+			"id":             float64(1),
+			"subnet":         "192.0.2.0/24",
+			"valid-lifetime": float64(7200),
+			"option-data": []any{
+				map[string]any{
+					"code": float64(6),
+					"data": "192.0.2.1",
+				},
+			},
+			"pools": []any{
+				map[string]any{"pool": "192.0.2.10-192.0.2.20"},
+				map[string]any{"pool": "192.0.2.30-192.0.2.40"},
+			},
+		},
+		"name":  "guest",
+		"count": 3,
+	}
+}
+
+// Tests that the valid expressions are evaluated correctly.
+func TestEvaluate(t *testing.T) {
+	testCases := map[string]any{
+		`1 + 2 * 3`:                        float64(7),
+		`(1 + 2) * 3`:                      float64(9),
+		`-1 - -2`:                          float64(1),
+		`7 % 4`:                            float64(3),
+		`10 / 4`:                           2.5,
+		`"a" + 'b'`:                        "ab",
+		`1 < 2`:                            true,
+		`2 <= 1`:                           false,
+		`"b" > "a"`:                        true,
+		`3 >= 3`:                           true,
+		`null == null`:                     true,
+		`[1, "a"] == [1, "a"]`:             true,
+		`1 != 2`:                           true,
+		`!true || false && true`:           false,
+		`true && !false`:                   true,
+		`subnet.id`:                        float64(1),
+		`subnet["valid-lifetime"] < 86400`: true,
+		`subnet.missing`:                   nil,
+		`subnet.missing.nested`:            nil,
+		`subnet.pools[1].pool`:             "192.0.2.30-192.0.2.40",
+		`subnet.pools[5]`:                  nil,
+		`count`:                            float64(3),
+		`"valid-lifetime" in subnet`:       true,
+		`"relay" in subnet`:                false,
+		`"ue" in name`:                     true,
+		`2 in [1, 2, 3]`:                   true,
+		`1 in null`:                        false,
+		`len(subnet.pools)`:                float64(2),
+		`len(null)`:                        float64(0),
+		`any(subnet["option-data"], it.code == 6)`:           true,
+		`all(subnet.pools, startsWith(it.pool, "192.0.2."))`: true,
+		`count(subnet.pools, endsWith(it.pool, ".40"))`:      float64(1),
+		`any(null, true)`:                             false,
+		`default(subnet["renew-timer"], 3600)`:        float64(3600),
+		`default(subnet.id, 3600)`:                    float64(1),
+		`upper(name) == "GUEST" && lower("A") == "a"`: true,
+		`matches(subnet.subnet, "^192\\.0\\.2\\.")`:   true,
+	}
+	for source, expected := range testCases {
+		t.Run(source, func(t *testing.T) {
+			expression, err := Compile(source, "subnet", "name", "count")
+			require.NoError(t, err)
+			value, err := expression.Evaluate(getTestVariables())
+			require.NoError(t, err)
+			require.Equal(t, expected, value)
+		})
+	}
+}
+
+// Tests that the malformed expressions or the expressions referring to the
+// unknown variables and functions are rejected.
+func TestCompileError(t *testing.T) {
+	testCases := map[string]string{
+		``:                                 "expression must not be empty",
+		`1 +`:                              "failed to parse expression",
+		`foo(`:                             "failed to parse expression",
+		`unknown == 1`:                     "unknown variable unknown",
+		`it == 1`:                          "unknown variable it",
+		`foo(1)`:                           "unknown function foo",
+		`len(1, 2)`:                        "function len expects 1 argument(s) but 2 were specified",
+		`any(subnet.pools, it.x == other)`: "unknown variable other",
+	}
+	for source, expected := range testCases {
+		t.Run(source, func(t *testing.T) {
+			expression, err := Compile(source, "subnet")
+			require.ErrorContains(t, err, expected)
+			require.Nil(t, expression)
+		})
+	}
+}
+
+// Tests that the evaluation errors are returned.
+func TestEvaluateError(t *testing.T) {
+	testCases := map[string]string{
+		`1 + "a"`:              `operator + cannot be applied to 1 and a`,
+		`1 / 0`:                `division by zero`,
+		`subnet.missing < 1`:   `cannot compare null with 1`,
+		`1 && true`:            `operator && expects boolean operands but got 1`,
+		`false || "a"`:         `operator || expects boolean operands but got a`,
+		`!1`:                   `operator ! cannot be applied to 1`,
+		`subnet.id.field`:      `cannot get field from 1`,
+		`subnet.pools["a"]`:    `list indexes are integers but got a`,
+		`any(subnet.id, true)`: `function any expects a list but got 1`,
+		`any(subnet.pools, 1)`: `predicate of the function any returned 1 instead of a boolean value`,
+		`lower(1)`:             `argument 1 must be a string but got 1`,
+		`matches("a", "(")`:    `invalid regular expression (`,
+		`name`:                 `variable name is not set`,
+	}
+	for source, expected := range testCases {
+		t.Run(source, func(t *testing.T) {
+			expression, err := Compile(source, "subnet", "name")
+			require.NoError(t, err)
+			variables := getTestVariables()
+			delete(variables, "name")
+			_, err = expression.Evaluate(variables)
+			require.ErrorContains(t, err, expected)
+		})
+	}
+}
+
+// Tests that the right-hand operands of the logical operators are not
+// evaluated when the result is determined by the left-hand operand.
+func TestEvaluateShortCircuit(t *testing.T) {
+	expression, err := Compile(`subnet.missing == null || subnet.missing < 1`, "subnet")
+	require.NoError(t, err)
+	value, err := expression.Evaluate(getTestVariables())
+	require.NoError(t, err)
+	require.Equal(t, true, value)
+
+	expression, err = Compile(`subnet.missing != null && subnet.missing < 1`, "subnet")
+	require.NoError(t, err)
+	value, err = expression.Evaluate(getTestVariables())
+	require.NoError(t, err)
+	require.Equal(t, false, value)
+}
+
+// Tests that the expression returning a boolean value is evaluated.
+func TestEvaluateBool(t *testing.T) {
+	expression, err := Compile(`subnet.id == 1`, "subnet")
+	require.NoError(t, err)
+	require.Equal(t, `subnet.id == 1`, expression.String())
+	result, err := expression.EvaluateBool(getTestVariables())
+	require.NoError(t, err)
+	require.True(t, result)
+
+	expression, err = Compile(`subnet.id`, "subnet")
+	require.NoError(t, err)
+	_, err = expression.EvaluateBool(getTestVariables())
+	require.ErrorContains(t, err, "expression subnet.id returned 1 instead of a boolean value")
+}
+
+// Tests the text representation of the values.
+func TestFormat(t *testing.T) {
+	require.Equal(t, "null", Format(nil))
+	require.Equal(t, "true", Format(true))
+	require.Equal(t, "86400", Format(float64(86400)))
+	require.Equal(t, "1000000", Format(1000000))
+	require.Equal(t, "0.5", Format(0.5))
+	require.Equal(t, "text", Format("text"))
+	require.Equal(t, `[1, "a", null]`, Format([]any{1, "a", nil}))
+	require.Equal(t, `{"a": 1, "b": ["c"]}`, Format(map[string]any{"b": []string{"c"}, "a": 1}))
+}
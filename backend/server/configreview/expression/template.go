@@ -0,0 +1,76 @@
+package expression
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Delimiters of the expressions embedded in the template.
+const (
+	templateOpen  = "{{"
+	templateClose = "}}"
+)
+
+// Compiled message template. The template is a text with embedded
+// expressions in double braces, e.g., "Subnet {{ subnet.subnet }} has no
+// DNS servers". The expressions are replaced with the evaluation results
+// when the template is rendered.
+type Template struct {
+	source string
+	// Text fragments between the expressions. It has one element more
+	// than the expressions slice.
+	texts       []string
+	expressions []*Expression
+}
+
+// Compiles the template. The variables are the names of the variables the
+// embedded expressions may refer to.
+func CompileTemplate(source string, variables ...string) (*Template, error) {
+	template := &Template{
+		source: source,
+	}
+	rest := source
+	for {
+		start := strings.Index(rest, templateOpen)
+		if start < 0 {
+			template.texts = append(template.texts, rest)
+			break
+		}
+		end := strings.Index(rest[start:], templateClose)
+		if end < 0 {
+			return nil, errors.Errorf("unterminated expression in template %s", source)
+		}
+		end += start
+		expression, err := Compile(rest[start+len(templateOpen):end], variables...)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid template %s", source)
+		}
+		template.texts = append(template.texts, rest[:start])
+		template.expressions = append(template.expressions, expression)
+		rest = rest[end+len(templateClose):]
+	}
+	return template, nil
+}
+
+// Returns the source text of the template.
+func (t *Template) String() string {
+	return t.source
+}
+
+// Renders the template for the specified variable values.
+func (t *Template) Render(variables map[string]any) (string, error) {
+	var builder strings.Builder
+	for i, text := range t.texts {
+		builder.WriteString(text)
+		if i == len(t.expressions) {
+			break
+		}
+		value, err := t.expressions[i].Evaluate(variables)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(Format(value))
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,50 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that the template with embedded expressions is rendered.
+func TestRenderTemplate(t *testing.T) {
+	template, err := CompileTemplate("Subnet {{ subnet.subnet }} has the valid lifetime of {{subnet[\"valid-lifetime\"]}} s.", "subnet")
+	require.NoError(t, err)
+	require.Equal(t, "Subnet {{ subnet.subnet }} has the valid lifetime of {{subnet[\"valid-lifetime\"]}} s.", template.String())
+
+	text, err := template.Render(getTestVariables())
+	require.NoError(t, err)
+	require.Equal(t, "Subnet 192.0.2.0/24 has the valid lifetime of 7200 s.", text)
+}
+
+// Tests that the template without expressions is rendered as is.
+func TestRenderTemplateNoExpressions(t *testing.T) {
+	template, err := CompileTemplate("The {daemon} has an issue.")
+	require.NoError(t, err)
+
+	text, err := template.Render(nil)
+	require.NoError(t, err)
+	require.Equal(t, "The {daemon} has an issue.", text)
+}
+
+// Tests that the malformed templates are rejected.
+func TestCompileTemplateError(t *testing.T) {
+	_, err := CompileTemplate("Subnet {{ subnet.subnet ", "subnet")
+	require.ErrorContains(t, err, "unterminated expression in template")
+
+	_, err = CompileTemplate("Subnet {{ unknown }}", "subnet")
+	require.ErrorContains(t, err, "unknown variable unknown")
+
+	_, err = CompileTemplate("Subnet {{ }}", "subnet")
+	require.ErrorContains(t, err, "expression must not be empty")
+}
+
+// Tests that the evaluation errors are returned when the template is
+// rendered.
+func TestRenderTemplateError(t *testing.T) {
+	template, err := CompileTemplate("Subnet {{ subnet.id + 'a' }}", "subnet")
+	require.NoError(t, err)
+
+	_, err = template.Render(getTestVariables())
+	require.ErrorContains(t, err, "operator + cannot be applied to 1 and a")
+}
@@ -0,0 +1,378 @@
+package expression
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Describes a function available in the expressions. The predicate
+// functions take a list and an expression evaluated for each list element.
+// The predicate receives the evaluation results and returns the function
+// result. The other functions receive the evaluated arguments.
+type function struct {
+	minArgs   int
+	maxArgs   int
+	call      func(args []any) (any, error)
+	predicate func(matches []bool) any
+}
+
+// Functions available in the expressions.
+//
+//nolint:gochecknoglobals
+var functions = map[string]function{
+	// Returns true if the predicate is true for any list element.
+	"any": {
+		minArgs: 2, maxArgs: 2,
+		predicate: func(matches []bool) any {
+			for _, match := range matches {
+				if match {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	// Returns true if the predicate is true for all list elements.
+	"all": {
+		minArgs: 2, maxArgs: 2,
+		predicate: func(matches []bool) any {
+			for _, match := range matches {
+				if !match {
+					return false
+				}
+			}
+			return true
+		},
+	},
+	// Returns the number of list elements for which the predicate is true.
+	"count": {
+		minArgs: 2, maxArgs: 2,
+		predicate: func(matches []bool) any {
+			count := 0.
+			for _, match := range matches {
+				if match {
+					count++
+				}
+			}
+			return count
+		},
+	},
+	// Returns the length of a string, list or map. The length of null is 0.
+	"len": {
+		minArgs: 1, maxArgs: 1,
+		call: func(args []any) (any, error) {
+			switch value := args[0].(type) {
+			case nil:
+				return 0., nil
+			case string:
+				return float64(len(value)), nil
+			case []any:
+				return float64(len(value)), nil
+			case map[string]any:
+				return float64(len(value)), nil
+			}
+			return nil, errors.Errorf("cannot get length of %s", Format(args[0]))
+		},
+	},
+	// Returns the first argument or the second argument if the first one
+	// is null. It is useful for specifying the default values of the
+	// optional parameters.
+	"default": {
+		minArgs: 2, maxArgs: 2,
+		call: func(args []any) (any, error) {
+			if args[0] == nil {
+				return args[1], nil
+			}
+			return args[0], nil
+		},
+	},
+	// Converts a string to lower case.
+	"lower": {
+		minArgs: 1, maxArgs: 1,
+		call: func(args []any) (any, error) {
+			value, err := stringArg(args, 0)
+			return strings.ToLower(value), err
+		},
+	},
+	// Converts a string to upper case.
+	"upper": {
+		minArgs: 1, maxArgs: 1,
+		call: func(args []any) (any, error) {
+			value, err := stringArg(args, 0)
+			return strings.ToUpper(value), err
+		},
+	},
+	// Checks if a string begins with a prefix.
+	"startsWith": {
+		minArgs: 2, maxArgs: 2,
+		call: func(args []any) (any, error) {
+			values, err := stringArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return strings.HasPrefix(values[0], values[1]), nil
+		},
+	},
+	// Checks if a string ends with a suffix.
+	"endsWith": {
+		minArgs: 2, maxArgs: 2,
+		call: func(args []any) (any, error) {
+			values, err := stringArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return strings.HasSuffix(values[0], values[1]), nil
+		},
+	},
+	// Checks if a string matches a regular expression.
+	"matches": {
+		minArgs: 2, maxArgs: 2,
+		call: func(args []any) (any, error) {
+			values, err := stringArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			pattern, err := regexp.Compile(values[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regular expression %s", values[1])
+			}
+			return pattern.MatchString(values[0]), nil
+		},
+	},
+}
+
+// Returns the function argument at the specified position as a string.
+func stringArg(args []any, position int) (string, error) {
+	value, ok := args[position].(string)
+	if !ok {
+		return "", errors.Errorf("argument %d must be a string but got %s", position+1, Format(args[position]))
+	}
+	return value, nil
+}
+
+// Returns all function arguments as strings.
+func stringArgs(args []any) ([]string, error) {
+	values := make([]string, len(args))
+	for i := range args {
+		value, err := stringArg(args, i)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Converts the numbers to float64 and the slices and maps of specific
+// types to the generic ones. The expressions operate on the nil, bool,
+// float64, string, []any and map[string]any values.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case nil, bool, float64, string, []any, map[string]any:
+		return v
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Pointer:
+		if reflected.IsNil() {
+			return nil
+		}
+		return normalize(reflected.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		list := make([]any, reflected.Len())
+		for i := range list {
+			list[i] = reflected.Index(i).Interface()
+		}
+		return list
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			break
+		}
+		values := make(map[string]any, reflected.Len())
+		iter := reflected.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = iter.Value().Interface()
+		}
+		return values
+	}
+	return value
+}
+
+// Returns the sorted keys of the map.
+func sortedKeys(values map[string]any) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Checks if the values are equal. The nested values are compared
+// recursively.
+func equal(left, right any) bool {
+	left = normalize(left)
+	right = normalize(right)
+	switch l := left.(type) {
+	case []any:
+		r, ok := right.([]any)
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !equal(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		r, ok := right.(map[string]any)
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for key, value := range l {
+			other, ok := r[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// Checks if the container includes the value. The container can be a list
+// (the value is one of the elements), a map (the value is one of the keys)
+// or a string (the value is a substring). Null doesn't include any values.
+func contains(container, value any) (bool, error) {
+	switch c := container.(type) {
+	case nil:
+		return false, nil
+	case []any:
+		for _, element := range c {
+			if equal(element, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]any:
+		key, ok := value.(string)
+		if !ok {
+			return false, errors.Errorf("map keys are strings but got %s", Format(value))
+		}
+		_, ok = c[key]
+		return ok, nil
+	case string:
+		substring, ok := value.(string)
+		if !ok {
+			return false, errors.Errorf("cannot search for %s in a string", Format(value))
+		}
+		return strings.Contains(c, substring), nil
+	}
+	return false, errors.Errorf("operator in cannot be applied to %s", Format(container))
+}
+
+// Compares two numbers or two strings using the specified operator.
+func compare(op string, left, right any) (bool, error) {
+	var result int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false, errors.Errorf("cannot compare %s with %s", Format(left), Format(right))
+		}
+		switch {
+		case l < r:
+			result = -1
+		case l > r:
+			result = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false, errors.Errorf("cannot compare %s with %s", Format(left), Format(right))
+		}
+		result = strings.Compare(l, r)
+	default:
+		return false, errors.Errorf("cannot compare %s with %s", Format(left), Format(right))
+	}
+	switch op {
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	default:
+		return result >= 0, nil
+	}
+}
+
+// Performs the arithmetic operation on two numbers.
+func arithmetic(op string, left, right any) (any, error) {
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, errors.Errorf("operator %s cannot be applied to %s and %s", op, Format(left), Format(right))
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+	if r == 0 {
+		return nil, errors.New("division by zero")
+	}
+	if op == "/" {
+		return l / r, nil
+	}
+	return math.Mod(l, r), nil
+}
+
+// Returns the map value for the key or the list element at the index.
+// It returns null if the map doesn't contain the key, the index is out
+// of range or the indexed value is null.
+func index(value, key any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		k, ok := key.(string)
+		if !ok {
+			return nil, errors.Errorf("map keys are strings but got %s", Format(key))
+		}
+		return normalize(v[k]), nil
+	case []any:
+		i, ok := key.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, errors.Errorf("list indexes are integers but got %s", Format(key))
+		}
+		if i < 0 || int(i) >= len(v) {
+			return nil, nil
+		}
+		return normalize(v[int(i)]), nil
+	}
+	return nil, errors.Errorf("cannot get %s from %s", Format(key), Format(value))
+}
@@ -1,9 +1,12 @@
 package configreview
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/url"
+	"path"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -1150,16 +1153,8 @@ func subnetCmdsAndConfigBackendMutualExclusion(ctx *ReviewContext) (*Report, err
 		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
 	}
 
-	config := ctx.subjectDaemon.KeaDaemon.Config
-
-	if _, _, present := config.GetHookLibrary("libdhcp_subnet_cmds"); !present {
-		// Missing subnet commands hook.
-		return nil, nil
-	}
-
-	databases := config.GetAllDatabases()
-	if len(databases.Config) == 0 {
-		// Missing a config backend.
+	if !ctx.subjectDaemon.KeaDaemon.Config.IsSubnetCmdsUsedWithConfigBackend() {
+		// Missing subnet commands hook or config backend.
 		return nil, nil
 	}
 
@@ -1417,3 +1412,520 @@ func findSharedNetworkExceedingDelegatedPrefixLimit(sharedNetworks []keaconfig.S
 	}
 	return false, ""
 }
+
+// Formats the configuration value presented in the configuration drift
+// report. The long values (e.g., whole subnets) are shortened.
+func formatConfigDriftValue(value any) string {
+	const maxLength = 80
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		formatted = []byte(fmt.Sprint(value))
+	}
+	if len(formatted) > maxLength {
+		return string(formatted[:maxLength]) + "..."
+	}
+	return string(formatted)
+}
+
+// The checker verifying that the running configuration of the Kea daemon
+// doesn't differ from its configuration file. The differences are found
+// by the Stork agent. They appear when the configuration is modified with
+// the config-set command without the config-write command, or when the
+// configuration file is edited without the config-reload command. The
+// daemon uses the configuration from the file after restart, so the
+// running configuration changes are lost then.
+func configDrift(ctx *ReviewContext) (*Report, error) {
+	if !ctx.subjectDaemon.KeaDaemon.ConfigDrift.IsDrifted() {
+		return nil, nil
+	}
+	// The drift reported by the older agents may include the sensitive
+	// data. Hide them in a copy to avoid modifying the daemon.
+	drift := *ctx.subjectDaemon.KeaDaemon.ConfigDrift
+	drift.Entries = slices.Clone(drift.Entries)
+	drift.HideSensitiveData()
+
+	const maxIssues = 10
+	messages := []string{}
+	for i, entry := range drift.Entries {
+		if i == maxIssues {
+			break
+		}
+		var message string
+		switch entry.Operation {
+		case keaconfig.ConfigDiffOperationAdded:
+			message = fmt.Sprintf("'%s' exists only in the running configuration: %s",
+				entry.Path, formatConfigDriftValue(entry.NewValue))
+		case keaconfig.ConfigDiffOperationRemoved:
+			message = fmt.Sprintf("'%s' exists only in the configuration file: %s",
+				entry.Path, formatConfigDriftValue(entry.OldValue))
+		default:
+			if entry.OldValue == nil && entry.NewValue == nil {
+				// The sensitive values have been hidden.
+				message = fmt.Sprintf("'%s' differs between the configuration file and the running configuration",
+					entry.Path)
+				break
+			}
+			message = fmt.Sprintf("'%s' is %s in the configuration file and %s in the running configuration",
+				entry.Path, formatConfigDriftValue(entry.OldValue), formatConfigDriftValue(entry.NewValue))
+		}
+		messages = append(messages, fmt.Sprintf("%d. %s.", i+1, message))
+	}
+
+	countMessage := fmt.Sprintf("Found %s", storkutil.FormatNoun(int64(len(drift.Entries)), "difference", "s"))
+	if len(drift.Entries) > maxIssues {
+		countMessage = fmt.Sprintf("First %d of %d differences", maxIssues, len(drift.Entries))
+	}
+
+	return NewReport(ctx, fmt.Sprintf("The running configuration of {daemon} "+
+		"differs from its configuration file %s. It happens when the "+
+		"configuration is modified with the config-set command without "+
+		"writing it to the file, or when the file is edited without "+
+		"reloading the configuration. The daemon will use the configuration "+
+		"from the file after restart, so the differences will be lost. Write "+
+		"the running configuration to the file or reload the configuration "+
+		"from the file to resolve the discrepancy. %s:\n%s",
+		drift.ConfigPath, countMessage, strings.Join(messages, "\n"))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// Checks if the HA relationships configured in two servers pertain to
+// each other, i.e., each of them lists the other server as a peer.
+func areHARelationshipPeers(relationship, peerRelationship keaconfig.HA) bool {
+	if relationship.ThisServerName == nil || peerRelationship.ThisServerName == nil {
+		return false
+	}
+	if *relationship.ThisServerName == *peerRelationship.ThisServerName {
+		return false
+	}
+	hasPeer := func(relationship keaconfig.HA, name string) bool {
+		for _, peer := range relationship.Peers {
+			if peer.Name != nil && *peer.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	return hasPeer(relationship, *peerRelationship.ThisServerName) &&
+		hasPeer(peerRelationship, *relationship.ThisServerName)
+}
+
+// Returns the string value or a placeholder if the value is not specified.
+func formatHAPeerConsistencyValue[T any](value *T) string {
+	if value == nil {
+		return "unspecified"
+	}
+	return fmt.Sprintf("'%v'", *value)
+}
+
+// Compares the HA relationship configurations of the peers. It returns
+// the descriptions of the differences in the HA mode and the peer lists.
+func findHARelationshipDifferences(name string, relationship keaconfig.HA, peerName string, peerRelationship keaconfig.HA) (differences []string) {
+	if formatHAPeerConsistencyValue(relationship.Mode) != formatHAPeerConsistencyValue(peerRelationship.Mode) {
+		differences = append(differences, fmt.Sprintf("HA mode is %s in '%s' and %s in '%s'",
+			formatHAPeerConsistencyValue(relationship.Mode), name,
+			formatHAPeerConsistencyValue(peerRelationship.Mode), peerName))
+	}
+
+	indexPeers := func(relationship keaconfig.HA) map[string]keaconfig.Peer {
+		peers := make(map[string]keaconfig.Peer)
+		for _, peer := range relationship.Peers {
+			if peer.Name != nil {
+				peers[*peer.Name] = peer
+			}
+		}
+		return peers
+	}
+	peers := indexPeers(relationship)
+	otherPeers := indexPeers(peerRelationship)
+
+	for _, peerListName := range getSortedUnionOfKeys(peers, otherPeers) {
+		peer, ok := peers[peerListName]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("HA peer '%s' is configured only in '%s'", peerListName, peerName))
+			continue
+		}
+		otherPeer, ok := otherPeers[peerListName]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("HA peer '%s' is configured only in '%s'", peerListName, name))
+			continue
+		}
+		for _, parameter := range []struct {
+			name       string
+			value      string
+			otherValue string
+		}{
+			{"URL", formatHAPeerConsistencyValue(peer.URL), formatHAPeerConsistencyValue(otherPeer.URL)},
+			{"role", formatHAPeerConsistencyValue(peer.Role), formatHAPeerConsistencyValue(otherPeer.Role)},
+			{"auto-failover", formatHAPeerConsistencyValue(peer.AutoFailover), formatHAPeerConsistencyValue(otherPeer.AutoFailover)},
+		} {
+			if parameter.value != parameter.otherValue {
+				differences = append(differences, fmt.Sprintf("HA peer '%s' has %s %s in '%s' and %s in '%s'",
+					peerListName, parameter.name, parameter.value, name, parameter.otherValue, peerName))
+			}
+		}
+	}
+	return differences
+}
+
+// Returns the sorted keys present in any of the maps.
+func getSortedUnionOfKeys[T, U any](first map[string]T, second map[string]U) []string {
+	keys := []string{}
+	for key := range first {
+		keys = append(keys, key)
+	}
+	for key := range second {
+		if _, ok := first[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Returns the subnets served by the servers in the HA relationship indexed
+// by their prefixes. If the server has only one relationship, all subnets
+// are returned. Otherwise (e.g., the hub in the hub-and-spoke topology),
+// the subnets are associated with the relationships using the
+// ha-server-name parameter in their user contexts.
+func getHARelationshipSubnets(config *keaconfig.Config, relationship keaconfig.HA, relationshipsCount int) map[string]keaconfig.Subnet {
+	serverNames := make(map[string]bool)
+	for _, peer := range relationship.Peers {
+		if peer.Name != nil {
+			serverNames[*peer.Name] = true
+		}
+	}
+	subnets := make(map[string]keaconfig.Subnet)
+	for _, subnet := range config.GetSubnets() {
+		if relationshipsCount > 1 {
+			serverName, ok := subnet.GetUserContext()["ha-server-name"].(string)
+			if !ok || !serverNames[serverName] {
+				continue
+			}
+		}
+		prefix, err := subnet.GetCanonicalPrefix()
+		if err != nil {
+			prefix = subnet.GetPrefix()
+		}
+		subnets[prefix] = subnet
+	}
+	return subnets
+}
+
+// Returns the pools in a subnet formatted for comparison.
+func formatHAPeerConsistencyPools(subnet keaconfig.Subnet) (pools, pdPools string) {
+	poolList := []string{}
+	for _, pool := range subnet.GetPools() {
+		poolList = append(poolList, pool.Pool)
+	}
+	sort.Strings(poolList)
+	pdPoolList := []string{}
+	for _, pdPool := range subnet.GetPDPools() {
+		pdPoolList = append(pdPoolList, fmt.Sprintf("%s (delegated length %d)", pdPool.GetCanonicalPrefix(), pdPool.DelegatedLen))
+	}
+	sort.Strings(pdPoolList)
+	return strings.Join(poolList, ", "), strings.Join(pdPoolList, ", ")
+}
+
+// Returns the reservations indexed by the host identifiers. The values are
+// the reserved addresses and prefixes formatted for comparison.
+func indexHAPeerConsistencyReservations(reservations []keaconfig.Reservation) map[string]string {
+	indexed := make(map[string]string)
+	for _, reservation := range reservations {
+		var identifier string
+		for _, candidate := range []struct {
+			name  string
+			value string
+		}{
+			{"hw-address", reservation.HWAddress},
+			{"duid", reservation.DUID},
+			{"client-id", reservation.ClientID},
+			{"circuit-id", reservation.CircuitID},
+			{"flex-id", reservation.FlexID},
+		} {
+			if candidate.value != "" {
+				identifier = fmt.Sprintf("%s %s", candidate.name, candidate.value)
+				break
+			}
+		}
+		if identifier == "" {
+			continue
+		}
+		resources := []string{}
+		if reservation.IPAddress != "" {
+			resources = append(resources, reservation.IPAddress)
+		}
+		resources = append(resources, reservation.IPAddresses...)
+		resources = append(resources, reservation.Prefixes...)
+		sort.Strings(resources)
+		indexed[identifier] = strings.Join(resources, ", ")
+	}
+	return indexed
+}
+
+// Compares the reservations configured in the peers. The location
+// describes where the reservations are configured (e.g., in a subnet).
+func findReservationDifferences(location, name string, reservations []keaconfig.Reservation, peerName string, peerReservations []keaconfig.Reservation) (differences []string) {
+	indexed := indexHAPeerConsistencyReservations(reservations)
+	peerIndexed := indexHAPeerConsistencyReservations(peerReservations)
+	for _, identifier := range getSortedUnionOfKeys(indexed, peerIndexed) {
+		resources, ok := indexed[identifier]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("reservation for %s %s is configured only in '%s'", identifier, location, peerName))
+			continue
+		}
+		peerResources, ok := peerIndexed[identifier]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("reservation for %s %s is configured only in '%s'", identifier, location, name))
+			continue
+		}
+		if resources != peerResources {
+			differences = append(differences, fmt.Sprintf("reservation for %s %s reserves '%s' in '%s' and '%s' in '%s'",
+				identifier, location, resources, name, peerResources, peerName))
+		}
+	}
+	return differences
+}
+
+// Compares the subnets served by the HA relationship in the peers. It
+// returns the descriptions of the missing subnets, mismatched subnet IDs,
+// pools and reservations.
+func findHASubnetDifferences(name string, subnets map[string]keaconfig.Subnet, peerName string, peerSubnets map[string]keaconfig.Subnet) (differences []string) {
+	for _, prefix := range getSortedUnionOfKeys(subnets, peerSubnets) {
+		subnet, ok := subnets[prefix]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("subnet %s is configured only in '%s'", prefix, peerName))
+			continue
+		}
+		peerSubnet, ok := peerSubnets[prefix]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("subnet %s is configured only in '%s'", prefix, name))
+			continue
+		}
+		if subnet.GetID() != peerSubnet.GetID() {
+			differences = append(differences, fmt.Sprintf("subnet %s has ID %d in '%s' and ID %d in '%s'",
+				prefix, subnet.GetID(), name, peerSubnet.GetID(), peerName))
+		}
+		pools, pdPools := formatHAPeerConsistencyPools(subnet)
+		peerPools, peerPDPools := formatHAPeerConsistencyPools(peerSubnet)
+		if pools != peerPools {
+			differences = append(differences, fmt.Sprintf("subnet %s has pools '%s' in '%s' and '%s' in '%s'",
+				prefix, pools, name, peerPools, peerName))
+		}
+		if pdPools != peerPDPools {
+			differences = append(differences, fmt.Sprintf("subnet %s has delegated prefix pools '%s' in '%s' and '%s' in '%s'",
+				prefix, pdPools, name, peerPDPools, peerName))
+		}
+		differences = append(differences, findReservationDifferences(fmt.Sprintf("in subnet %s", prefix),
+			name, subnet.GetReservations(), peerName, peerSubnet.GetReservations())...)
+	}
+	return differences
+}
+
+// Compares the server-wide configurations of the peers. It returns the
+// descriptions of the differences in the global reservations, client
+// classes, loaded hook libraries and their parameters.
+func findHAGlobalDifferences(name string, config *keaconfig.Config, peerName string, peerConfig *keaconfig.Config) (differences []string) {
+	differences = append(differences, findReservationDifferences("in the global scope",
+		name, config.GetReservations(), peerName, peerConfig.GetReservations())...)
+
+	indexClasses := func(config *keaconfig.Config) map[string]string {
+		classes := make(map[string]string)
+		for _, class := range config.GetClientClasses() {
+			classes[class.Name] = formatHAPeerConsistencyValue(class.Test)
+		}
+		return classes
+	}
+	classes := indexClasses(config)
+	peerClasses := indexClasses(peerConfig)
+	for _, className := range getSortedUnionOfKeys(classes, peerClasses) {
+		test, ok := classes[className]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("client class '%s' is configured only in '%s'", className, peerName))
+			continue
+		}
+		peerTest, ok := peerClasses[className]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("client class '%s' is configured only in '%s'", className, name))
+			continue
+		}
+		if test != peerTest {
+			differences = append(differences, fmt.Sprintf("client class '%s' has test expression %s in '%s' and %s in '%s'",
+				className, test, name, peerTest, peerName))
+		}
+	}
+
+	indexHooks := func(config *keaconfig.Config) map[string]map[string]any {
+		hooks := make(map[string]map[string]any)
+		for _, hook := range config.GetHookLibraries() {
+			hooks[path.Base(hook.Library)] = getHAPeerConsistencyHookParameters(hook)
+		}
+		return hooks
+	}
+	hooks := indexHooks(config)
+	peerHooks := indexHooks(peerConfig)
+	for _, hook := range getSortedUnionOfKeys(hooks, peerHooks) {
+		params, ok := hooks[hook]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("hook library %s is loaded only in '%s'", hook, peerName))
+			continue
+		}
+		peerParams, ok := peerHooks[hook]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("hook library %s is loaded only in '%s'", hook, name))
+			continue
+		}
+		// The parameter values are not included in the description because
+		// they may contain credentials.
+		var paths []string
+		for _, entry := range keaconfig.DiffValues(params, peerParams) {
+			paths = append(paths, fmt.Sprintf("'%s'", entry.Path))
+		}
+		if len(paths) > 0 {
+			differences = append(differences, fmt.Sprintf("hook library %s has different parameters in '%s' and '%s': %s",
+				hook, name, peerName, strings.Join(paths, ", ")))
+		}
+	}
+	return differences
+}
+
+// Returns the parameters of the hook library compared between the HA
+// peers. The HA relationships are excluded from the HA hook library
+// parameters because they are compared separately and the peers have
+// different this-server-name values.
+func getHAPeerConsistencyHookParameters(hook keaconfig.HookLibrary) map[string]any {
+	var params map[string]any
+	if len(hook.Parameters) > 0 {
+		// The parameters of the unparsable hook libraries are compared
+		// as empty.
+		_ = json.Unmarshal(hook.Parameters, &params)
+	}
+	if strings.HasPrefix(path.Base(hook.Library), "libdhcp_ha") {
+		delete(params, "high-availability")
+	}
+	return params
+}
+
+// Fetches the Kea daemons belonging to the same HA services as the subject
+// daemon. The daemons without configurations are skipped. The daemons are
+// added to the referenced daemons in the review context because the
+// subject daemon's configuration change can affect their reviews.
+func getHAPeerDaemons(ctx *ReviewContext) ([]*dbmodel.Daemon, error) {
+	services, err := dbmodel.GetDetailedServicesByDaemonID(ctx.db, ctx.subjectDaemon.ID)
+	if err != nil {
+		return nil, err
+	}
+	var peers []*dbmodel.Daemon
+	presentDaemons := map[int64]bool{ctx.subjectDaemon.ID: true}
+	for _, daemon := range ctx.refDaemons {
+		presentDaemons[daemon.ID] = true
+	}
+	for _, service := range services {
+		if service.HAService == nil {
+			continue
+		}
+		for _, daemon := range service.Daemons {
+			if daemon.ID == ctx.subjectDaemon.ID || daemon.Name != ctx.subjectDaemon.Name ||
+				daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil ||
+				slices.ContainsFunc(peers, func(peer *dbmodel.Daemon) bool { return peer.ID == daemon.ID }) {
+				continue
+			}
+			peers = append(peers, daemon)
+			if !presentDaemons[daemon.ID] {
+				ctx.refDaemons = append(ctx.refDaemons, daemon)
+				presentDaemons[daemon.ID] = true
+			}
+		}
+	}
+	return peers, nil
+}
+
+// The checker validates that the configurations of the High Availability
+// peers are consistent. The peers must have the same subnets with the same
+// IDs, pools and reservations, the same client classes and hook libraries,
+// and the matching peer lists in the HA hook configuration. Otherwise, the
+// server taking over the service of its failed partner may allocate
+// different leases, or the peers may not communicate at all. In the
+// hub-and-spoke topology, only the subnets associated with the shared
+// relationship are compared.
+func highAvailabilityPeerConsistency(ctx *ReviewContext) (*Report, error) {
+	config := ctx.subjectDaemon.KeaDaemon.Config.Config
+
+	_, haConfig, ok := config.GetHookLibraries().GetHAHookLibrary()
+	if !ok {
+		// There is no HA configured.
+		return nil, nil
+	}
+
+	peers, err := getHAPeerDaemons(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const maxIssues = 10
+	var sections []string
+	var inconsistentPeers []*dbmodel.Daemon
+	relationships := haConfig.GetAllRelationships()
+	for _, peer := range peers {
+		peerConfig := peer.KeaDaemon.Config.Config
+		_, peerHAConfig, ok := peerConfig.GetHookLibraries().GetHAHookLibrary()
+		if !ok {
+			// The HA hook has been removed from the peer's configuration.
+			continue
+		}
+		peerRelationships := peerHAConfig.GetAllRelationships()
+
+		var name, peerName string
+		var differences []string
+		for _, relationship := range relationships {
+			for _, peerRelationship := range peerRelationships {
+				if !areHARelationshipPeers(relationship, peerRelationship) {
+					continue
+				}
+				name = *relationship.ThisServerName
+				peerName = *peerRelationship.ThisServerName
+				differences = append(differences, findHARelationshipDifferences(name, relationship, peerName, peerRelationship)...)
+				differences = append(differences, findHASubnetDifferences(
+					name, getHARelationshipSubnets(config, relationship, len(relationships)),
+					peerName, getHARelationshipSubnets(peerConfig, peerRelationship, len(peerRelationships)))...)
+			}
+		}
+		if name == "" {
+			// The servers don't list each other as peers.
+			continue
+		}
+		differences = append(differences, findHAGlobalDifferences(name, config, peerName, peerConfig)...)
+		if len(differences) == 0 {
+			continue
+		}
+
+		countMessage := fmt.Sprintf("Found %s", storkutil.FormatNoun(int64(len(differences)), "difference", "s"))
+		if len(differences) > maxIssues {
+			countMessage = fmt.Sprintf("First %d of %d differences", maxIssues, len(differences))
+			differences = differences[:maxIssues]
+		}
+		messages := []string{}
+		for i, difference := range differences {
+			messages = append(messages, fmt.Sprintf("%d. %s.", i+1, difference))
+		}
+		sections = append(sections, fmt.Sprintf("%s between '%s' and its HA peer '%s' in {daemon}:\n%s",
+			countMessage, name, peerName, strings.Join(messages, "\n")))
+		inconsistentPeers = append(inconsistentPeers, peer)
+	}
+	if len(sections) == 0 {
+		return nil, nil
+	}
+
+	report := NewReport(ctx, fmt.Sprintf("The configuration of {daemon} is "+
+		"inconsistent with the configurations of its High Availability peers. "+
+		"The HA peers should have the same subnets, pools, reservations, "+
+		"client classes and hook libraries, and the same peer lists in the HA "+
+		"hook configuration. Otherwise, the server taking over the service of "+
+		"its failed partner may assign different leases or the servers may "+
+		"fail to communicate. %s", strings.Join(sections, "\n\n"))).
+		referencingDaemon(ctx.subjectDaemon)
+	for _, peer := range inconsistentPeers {
+		report = report.referencingDaemon(peer)
+	}
+	return report.create()
+}
@@ -5158,3 +5158,444 @@ func BenchmarkOverlapsBinaryPrefixesOnly(b *testing.B) {
 		_ = findOverlaps(subnets, maximumOverlaps)
 	}
 }
+
+// Tests that the config drift checker returns no report when the running
+// configuration doesn't differ from the configuration file or when the
+// configurations have not been compared.
+func TestConfigDriftNone(t *testing.T) {
+	ctx := createReviewContext(t, nil, `{"Dhcp4": { }}`, "2.6.0")
+
+	report, err := configDrift(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	ctx.subjectDaemon.KeaDaemon.ConfigDrift = &keaconfig.ConfigDrift{
+		ConfigPath: "/etc/kea/kea-dhcp4.conf",
+	}
+	report, err = configDrift(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the config drift checker reports the differences between the
+// running configuration and the configuration file.
+func TestConfigDrift(t *testing.T) {
+	ctx := createReviewContext(t, nil, `{"Dhcp4": { }}`, "2.6.0")
+	ctx.subjectDaemon.KeaDaemon.ConfigDrift = &keaconfig.ConfigDrift{
+		ConfigPath: "/etc/kea/kea-dhcp4.conf",
+		Entries: []keaconfig.ConfigDiffEntry{
+			{
+				Path:      "/Dhcp4/subnet4[id=2]",
+				Operation: keaconfig.ConfigDiffOperationAdded,
+				NewValue:  map[string]any{"id": 2, "subnet": "192.0.3.0/24"},
+			},
+			{
+				Path:      "/Dhcp4/subnet4[id=3]",
+				Operation: keaconfig.ConfigDiffOperationRemoved,
+				OldValue:  map[string]any{"id": 3, "subnet": "192.0.4.0/24"},
+			},
+			{
+				Path:      "/Dhcp4/valid-lifetime",
+				Operation: keaconfig.ConfigDiffOperationModified,
+				OldValue:  3600,
+				NewValue:  7200,
+			},
+		},
+	}
+
+	report, err := configDrift(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "differs from its configuration file /etc/kea/kea-dhcp4.conf")
+	require.Contains(t, *report.content, "Found 3 differences:")
+	require.Contains(t, *report.content, `1. '/Dhcp4/subnet4[id=2]' exists only in the running configuration: {"id":2,"subnet":"192.0.3.0/24"}.`)
+	require.Contains(t, *report.content, `2. '/Dhcp4/subnet4[id=3]' exists only in the configuration file: {"id":3,"subnet":"192.0.4.0/24"}.`)
+	require.Contains(t, *report.content, "3. '/Dhcp4/valid-lifetime' is 3600 in the configuration file and 7200 in the running configuration.")
+	require.Equal(t, []int64{1}, report.refDaemonIDs)
+}
+
+// Tests that the config drift checker doesn't include the sensitive data
+// in the report, e.g., the database passwords and the HA basic auth
+// credentials.
+func TestConfigDriftSensitiveData(t *testing.T) {
+	ctx := createReviewContext(t, nil, `{"Dhcp4": { }}`, "2.6.0")
+	drift := &keaconfig.ConfigDrift{
+		ConfigPath: "/etc/kea/kea-dhcp4.conf",
+		Entries: []keaconfig.ConfigDiffEntry{
+			{
+				Path:      "/Dhcp4/hooks-libraries[1]",
+				Operation: keaconfig.ConfigDiffOperationAdded,
+				NewValue: map[string]any{
+					"library": "libdhcp_ha.so",
+					"parameters": map[string]any{
+						"high-availability": []any{
+							map[string]any{
+								"peers": []any{
+									map[string]any{
+										"name":     "server1",
+										"user":     "admin",
+										"password": "ha-secret",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Path:      "/Dhcp4/lease-database/password",
+				Operation: keaconfig.ConfigDiffOperationModified,
+				OldValue:  "file-secret",
+				NewValue:  "running-secret",
+			},
+		},
+	}
+	ctx.subjectDaemon.KeaDaemon.ConfigDrift = drift
+
+	report, err := configDrift(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Found 2 differences:")
+	require.Contains(t, *report.content, "2. '/Dhcp4/lease-database/password' differs between the configuration file and the running configuration.")
+	require.NotContains(t, *report.content, "secret")
+
+	// The daemon's drift is not modified.
+	require.Equal(t, "running-secret", drift.Entries[1].NewValue)
+}
+
+// Tests that the config drift checker limits the number of the reported
+// differences.
+func TestConfigDriftManyDifferences(t *testing.T) {
+	ctx := createReviewContext(t, nil, `{"Dhcp4": { }}`, "2.6.0")
+	drift := &keaconfig.ConfigDrift{
+		ConfigPath: "/etc/kea/kea-dhcp4.conf",
+	}
+	for i := 0; i < 15; i++ {
+		drift.Entries = append(drift.Entries, keaconfig.ConfigDiffEntry{
+			Path:      fmt.Sprintf("/Dhcp4/subnet4[id=%d]/valid-lifetime", i),
+			Operation: keaconfig.ConfigDiffOperationModified,
+			OldValue:  3600,
+			NewValue:  strings.Repeat("x", 100),
+		})
+	}
+	ctx.subjectDaemon.KeaDaemon.ConfigDrift = drift
+
+	report, err := configDrift(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "First 10 of 15 differences:")
+	require.Contains(t, *report.content, "10. '/Dhcp4/subnet4[id=9]/valid-lifetime'")
+	require.NotContains(t, *report.content, "11.")
+	// The long values are shortened.
+	require.Contains(t, *report.content, "...")
+}
+
+// Returns the DHCPv4 server configuration used in the HA peer consistency
+// tests. The server belongs to the HA relationship of server1 and server2.
+func getHAPeerConsistencyTestConfig(thisServerName, secondaryURL, subnets, classes string) string {
+	return fmt.Sprintf(`{
+		"Dhcp4": {
+			"client-classes": [ %s ],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so"
+				},
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_ha.so",
+					"parameters": {
+						"high-availability": [{
+							"this-server-name": "%s",
+							"mode": "hot-standby",
+							"peers": [
+								{
+									"name": "server1",
+									"url": "http://192.0.2.1:8001",
+									"role": "primary"
+								},
+								{
+									"name": "server2",
+									"url": "%s",
+									"role": "standby"
+								}
+							]
+						}]
+					}
+				}
+			],
+			"subnet4": [ %s ]
+		}
+	}`, classes, thisServerName, secondaryURL, subnets)
+}
+
+// Test that the differences between the configurations of the HA peers
+// are found.
+func TestHighAvailabilityPeerConsistencyDifferences(t *testing.T) {
+	config, err := keaconfig.NewConfig([]byte(getHAPeerConsistencyTestConfig("server1", "http://192.0.2.2:8001",
+		`{
+			"id": 1,
+			"subnet": "192.0.2.0/24",
+			"pools": [ { "pool": "192.0.2.10 - 192.0.2.100" } ],
+			"reservations": [
+				{ "hw-address": "01:02:03:04:05:06", "ip-address": "192.0.2.200" },
+				{ "hw-address": "01:02:03:04:05:07", "ip-address": "192.0.2.201" }
+			]
+		},
+		{
+			"id": 2,
+			"subnet": "192.0.3.0/24"
+		},
+		{
+			"id": 3,
+			"subnet": "192.0.4.0/24"
+		}`,
+		`{ "name": "foo", "test": "member('ALL')" }, { "name": "bar" }`)))
+	require.NoError(t, err)
+
+	peerConfig, err := keaconfig.NewConfig([]byte(getHAPeerConsistencyTestConfig("server2", "http://192.0.2.2:8002",
+		`{
+			"id": 1,
+			"subnet": "192.0.2.0/24",
+			"pools": [ { "pool": "192.0.2.10-192.0.2.200" } ],
+			"reservations": [
+				{ "hw-address": "01:02:03:04:05:06", "ip-address": "192.0.2.210" }
+			]
+		},
+		{
+			"id": 4,
+			"subnet": "192.0.3.0/24"
+		}`,
+		`{ "name": "foo", "test": "member('KNOWN')" }, { "name": "baz" }`)))
+	require.NoError(t, err)
+
+	_, haConfig, ok := config.GetHookLibraries().GetHAHookLibrary()
+	require.True(t, ok)
+	_, peerHAConfig, ok := peerConfig.GetHookLibraries().GetHAHookLibrary()
+	require.True(t, ok)
+	relationship := haConfig.GetAllRelationships()[0]
+	peerRelationship := peerHAConfig.GetAllRelationships()[0]
+	require.True(t, areHARelationshipPeers(relationship, peerRelationship))
+	require.False(t, areHARelationshipPeers(relationship, relationship))
+
+	t.Run("relationships", func(t *testing.T) {
+		differences := findHARelationshipDifferences("server1", relationship, "server2", peerRelationship)
+		require.Equal(t, []string{
+			"HA peer 'server2' has URL 'http://192.0.2.2:8001' in 'server1' and 'http://192.0.2.2:8002' in 'server2'",
+		}, differences)
+	})
+
+	t.Run("subnets", func(t *testing.T) {
+		differences := findHASubnetDifferences(
+			"server1", getHARelationshipSubnets(config, relationship, 1),
+			"server2", getHARelationshipSubnets(peerConfig, peerRelationship, 1))
+		require.Equal(t, []string{
+			"subnet 192.0.2.0/24 has pools '192.0.2.10-192.0.2.100' in 'server1' and '192.0.2.10-192.0.2.200' in 'server2'",
+			"reservation for hw-address 01:02:03:04:05:06 in subnet 192.0.2.0/24 reserves '192.0.2.200' in 'server1' and '192.0.2.210' in 'server2'",
+			"reservation for hw-address 01:02:03:04:05:07 in subnet 192.0.2.0/24 is configured only in 'server1'",
+			"subnet 192.0.3.0/24 has ID 2 in 'server1' and ID 4 in 'server2'",
+			"subnet 192.0.4.0/24 is configured only in 'server1'",
+		}, differences)
+	})
+
+	t.Run("global", func(t *testing.T) {
+		differences := findHAGlobalDifferences("server1", config, "server2", peerConfig)
+		require.Equal(t, []string{
+			"client class 'bar' is configured only in 'server1'",
+			"client class 'baz' is configured only in 'server2'",
+			"client class 'foo' has test expression 'member('ALL')' in 'server1' and 'member('KNOWN')' in 'server2'",
+		}, differences)
+	})
+}
+
+// Test that the differences between the hook library parameters of the
+// HA peers are found. The HA relationships are not compared as the hook
+// library parameters.
+func TestHighAvailabilityPeerConsistencyHookParameters(t *testing.T) {
+	getConfig := func(thisServerName, libraries string) *keaconfig.Config {
+		config, err := keaconfig.NewConfig([]byte(fmt.Sprintf(`{
+			"Dhcp4": {
+				"hooks-libraries": [
+					%s,
+					{
+						"library": "/usr/lib/kea/hooks/libdhcp_ha.so",
+						"parameters": {
+							"high-availability": [{
+								"this-server-name": "%s",
+								"mode": "hot-standby",
+								"peers": [
+									{ "name": "server1", "url": "http://192.0.2.1:8001", "role": "primary" },
+									{ "name": "server2", "url": "http://192.0.2.2:8001", "role": "standby" }
+								]
+							}]
+						}
+					}
+				]
+			}
+		}`, libraries, thisServerName)))
+		require.NoError(t, err)
+		return config
+	}
+
+	t.Run("same parameters", func(t *testing.T) {
+		libraries := `{
+			"library": "/usr/lib/kea/hooks/libdhcp_legal_log.so",
+			"parameters": { "path": "/var/lib/kea", "base-name": "kea-forensic4" }
+		}`
+		config := getConfig("server1", libraries)
+		peerConfig := getConfig("server2", libraries)
+		require.Empty(t, findHAGlobalDifferences("server1", config, "server2", peerConfig))
+	})
+
+	t.Run("different parameters", func(t *testing.T) {
+		config := getConfig("server1", `{
+			"library": "/usr/lib/kea/hooks/libdhcp_legal_log.so",
+			"parameters": { "path": "/var/lib/kea", "base-name": "kea-forensic4", "password": "secret1" }
+		}`)
+		peerConfig := getConfig("server2", `{
+			"library": "/opt/kea/lib/libdhcp_legal_log.so",
+			"parameters": { "path": "/var/log/kea", "password": "secret2" }
+		}`)
+		differences := findHAGlobalDifferences("server1", config, "server2", peerConfig)
+		require.Equal(t, []string{
+			"hook library libdhcp_legal_log.so has different parameters in 'server1' and 'server2': '/base-name', '/password', '/path'",
+		}, differences)
+		require.NotContains(t, differences[0], "secret")
+	})
+
+	t.Run("parameters only in one peer", func(t *testing.T) {
+		config := getConfig("server1", `{
+			"library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so"
+		}`)
+		peerConfig := getConfig("server2", `{
+			"library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so",
+			"parameters": { "foo": "bar" }
+		}`)
+		differences := findHAGlobalDifferences("server1", config, "server2", peerConfig)
+		require.Equal(t, []string{
+			"hook library libdhcp_lease_cmds.so has different parameters in 'server1' and 'server2': '/foo'",
+		}, differences)
+	})
+}
+
+// Test that only the subnets associated with the HA relationship are
+// compared when the server has multiple relationships.
+func TestGetHARelationshipSubnetsHubAndSpoke(t *testing.T) {
+	config, err := keaconfig.NewConfig([]byte(getHAPeerConsistencyTestConfig("server1", "http://192.0.2.2:8001",
+		`{
+			"id": 1,
+			"subnet": "192.0.2.0/24",
+			"user-context": { "ha-server-name": "server1" }
+		},
+		{
+			"id": 2,
+			"subnet": "192.0.3.0/24",
+			"user-context": { "ha-server-name": "server3" }
+		},
+		{
+			"id": 3,
+			"subnet": "192.0.4.0/24"
+		}`, "")))
+	require.NoError(t, err)
+	_, haConfig, ok := config.GetHookLibraries().GetHAHookLibrary()
+	require.True(t, ok)
+	relationship := haConfig.GetAllRelationships()[0]
+
+	subnets := getHARelationshipSubnets(config, relationship, 2)
+	require.Len(t, subnets, 1)
+	require.Contains(t, subnets, "192.0.2.0/24")
+
+	subnets = getHARelationshipSubnets(config, relationship, 1)
+	require.Len(t, subnets, 3)
+}
+
+// Adds a DHCPv4 daemon with the specified configuration used in the HA
+// peer consistency checker tests.
+func addHAPeerConsistencyTestDaemon(t *testing.T, db *dbops.PgDB, address, config string) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   address,
+		AgentPort: 8080,
+	}
+	require.NoError(t, dbmodel.AddMachine(db, machine))
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.DHCPv4, true, []*dbmodel.AccessPoint{
+		{
+			Type:    dbmodel.AccessPointControl,
+			Address: address,
+			Port:    8000,
+		},
+	})
+	require.NoError(t, daemon.SetKeaConfigFromJSON([]byte(config)))
+	require.NoError(t, dbmodel.AddDaemon(db, daemon))
+	return daemon
+}
+
+// Test that the HA peer consistency checker reports the differences between
+// the configurations of the daemons in the HA service.
+func TestHighAvailabilityPeerConsistencyChecker(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addHAPeerConsistencyTestDaemon(t, db, "192.0.2.1", getHAPeerConsistencyTestConfig(
+		"server1", "http://192.0.2.2:8001", `{ "id": 1, "subnet": "192.0.2.0/24" }`, ""))
+	peerDaemon := addHAPeerConsistencyTestDaemon(t, db, "192.0.2.2", getHAPeerConsistencyTestConfig(
+		"server2", "http://192.0.2.2:8001", `{ "id": 2, "subnet": "192.0.2.0/24" }`, ""))
+
+	service := &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Daemons: []*dbmodel.Daemon{daemon, peerDaemon},
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:      daemonname.DHCPv4,
+			HAMode:      "hot-standby",
+			PrimaryID:   daemon.ID,
+			SecondaryID: peerDaemon.ID,
+		},
+	}
+	require.NoError(t, dbmodel.AddService(db, service))
+
+	ctx := newReviewContext(db, daemon, Triggers{ManualRun}, nil)
+
+	report, err := highAvailabilityPeerConsistency(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "The configuration of {daemon} is inconsistent with the configurations of its High Availability peers.")
+	require.Contains(t, *report.content, "Found 1 difference between 'server1' and its HA peer 'server2' in {daemon}:\n"+
+		"1. subnet 192.0.2.0/24 has ID 1 in 'server1' and ID 2 in 'server2'.")
+	require.Equal(t, []int64{daemon.ID, peerDaemon.ID}, report.refDaemonIDs)
+
+	// The peer is referenced, so its reports are updated when the subject
+	// daemon's configuration changes.
+	require.Len(t, ctx.refDaemons, 1)
+	require.Equal(t, peerDaemon.ID, ctx.refDaemons[0].ID)
+}
+
+// Test that the HA peer consistency checker doesn't produce a report when
+// the configurations of the HA peers are consistent.
+func TestHighAvailabilityPeerConsistencyCheckerConsistent(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addHAPeerConsistencyTestDaemon(t, db, "192.0.2.1", getHAPeerConsistencyTestConfig(
+		"server1", "http://192.0.2.2:8001", `{ "id": 1, "subnet": "192.0.2.0/24" }`, ""))
+	peerDaemon := addHAPeerConsistencyTestDaemon(t, db, "192.0.2.2", getHAPeerConsistencyTestConfig(
+		"server2", "http://192.0.2.2:8001", `{ "id": 1, "subnet": "192.0.2.0/24" }`, ""))
+
+	service := &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Daemons: []*dbmodel.Daemon{daemon, peerDaemon},
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:      daemonname.DHCPv4,
+			HAMode:      "hot-standby",
+			PrimaryID:   daemon.ID,
+			SecondaryID: peerDaemon.ID,
+		},
+	}
+	require.NoError(t, dbmodel.AddService(db, service))
+
+	ctx := newReviewContext(db, daemon, Triggers{ManualRun}, nil)
+
+	report, err := highAvailabilityPeerConsistency(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
@@ -0,0 +1,62 @@
+package configreview
+
+import (
+	pkgerrors "github.com/pkg/errors"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Checks if the default checker reviews the daemon configuration alone.
+// The other checkers use the data stored in the database (e.g., the host
+// reservations, machines or HA services) or the daemon state
+// gathered by the server (e.g., the configuration drift), so they cannot
+// be run offline.
+func isOfflineChecker(checkerName string) bool {
+	switch checkerName {
+	case "dispensable_subnet",
+		"out_of_pool_reservation",
+		"address_pools_exhausted_by_reservations",
+		"pd_pools_exhausted_by_reservations",
+		"ha_dedicated_ports",
+		"ha_peer_consistency",
+		"config_drift":
+		return false
+	default:
+		return true
+	}
+}
+
+// Reviews the daemon configuration without the database, e.g., to
+// validate a configuration file before it is deployed. It runs the
+// default checkers registered for the daemon which review the
+// configuration alone. The reports are returned in the order in which the
+// checkers were run. They include the reports of the checkers which found
+// no issues. The report contents include the {daemon} placeholders. The
+// daemon must have a non-zero ID.
+func ReviewOffline(daemon *dbmodel.Daemon) ([]dbmodel.ConfigReport, error) {
+	dispatcher := NewDispatcher(nil).(*dispatcherImpl)
+	RegisterDefaultCheckers(dispatcher)
+
+	ctx := dispatcher.newContext(nil, daemon, Triggers{ManualRun}, nil)
+
+	reports := []dbmodel.ConfigReport{}
+	for _, selector := range getDispatchGroupSelectors(daemon.Name) {
+		for _, checker := range dispatcher.getCheckers(selector) {
+			if !isOfflineChecker(checker.name) {
+				continue
+			}
+			report, err := checker.checkFn(ctx)
+			if err != nil {
+				return nil, pkgerrors.WithMessagef(err, "config review checker %s failed", checker.name)
+			}
+			configReport := dbmodel.ConfigReport{
+				CheckerName: checker.name,
+				DaemonID:    daemon.ID,
+			}
+			if report != nil {
+				configReport.Content = report.content
+			}
+			reports = append(reports, configReport)
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,59 @@
+package configreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the offline review runs the checkers that don't require the
+// database and returns the reports of all of them.
+func TestReviewOffline(t *testing.T) {
+	ctx := createReviewContext(t, nil, `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				},
+				{
+					"id": 2,
+					"subnet": "192.0.2.0/25"
+				}
+			]
+		}
+	}`, "2.6.0")
+
+	reports, err := ReviewOffline(ctx.subjectDaemon)
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+
+	issues := make(map[string]string)
+	for _, report := range reports {
+		require.True(t, isOfflineChecker(report.CheckerName))
+		require.EqualValues(t, 1, report.DaemonID)
+		if report.IsIssueFound() {
+			issues[report.CheckerName] = *report.Content
+		}
+	}
+	require.Contains(t, issues, "lease_cmds_presence")
+	require.Contains(t, issues, "overlapping_subnet")
+	require.Contains(t, issues["overlapping_subnet"], "{daemon}")
+	require.NotContains(t, issues, "canonical_prefix")
+
+	// The checkers requiring the database are not run.
+	for _, report := range reports {
+		require.NotEqual(t, "dispensable_subnet", report.CheckerName)
+		require.NotEqual(t, "ha_peer_consistency", report.CheckerName)
+	}
+}
+
+// Test that the checkers requiring the database or the daemon state
+// gathered by the server are not run offline.
+func TestIsOfflineChecker(t *testing.T) {
+	require.True(t, isOfflineChecker("overlapping_subnet"))
+	require.True(t, isOfflineChecker("bind9_open_recursion"))
+	require.False(t, isOfflineChecker("out_of_pool_reservation"))
+	require.False(t, isOfflineChecker("config_drift"))
+	require.True(t, isOfflineChecker("pdns_axfr_open_to_remote"))
+}
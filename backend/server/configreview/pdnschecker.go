@@ -0,0 +1,179 @@
+package configreview
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
+)
+
+// PowerDNS logs the incoming and outgoing zone transfers with the notice
+// level (5). The default log level is warning (4).
+const (
+	pdnsDefaultLogLevel = 4
+	pdnsMinXFRLogLevel  = 5
+)
+
+// Returns the PowerDNS configuration of the reviewed daemon or nil if the
+// configuration has not been fetched from the agent.
+func getPDNSConfig(ctx *ReviewContext) *pdnsconfig.Config {
+	if ctx.subjectDaemon.PDNSDaemon == nil {
+		return nil
+	}
+	return ctx.subjectDaemon.PDNSDaemon.Config
+}
+
+// Returns the string values specified for the parameter.
+func getPDNSStringValues(config *pdnsconfig.Config, key string) (values []string) {
+	for _, value := range config.GetValues(key) {
+		values = append(values, value.String())
+	}
+	return
+}
+
+// Checks if the address or prefix specified in the PowerDNS configuration
+// covers the loopback addresses only.
+func isPDNSLoopbackAddress(address string) bool {
+	if _, network, err := net.ParseCIDR(address); err == nil {
+		return network.IP.IsLoopback()
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Checks if the prefix specified in the PowerDNS configuration covers all
+// IPv4 or IPv6 addresses.
+func isPDNSAnyPrefix(address string) bool {
+	_, network, err := net.ParseCIDR(address)
+	if err != nil {
+		return false
+	}
+	ones, _ := network.Mask.Size()
+	return ones == 0
+}
+
+// The checker verifying if the API key is specified when the PowerDNS
+// webserver and REST API are enabled.
+func pdnsAPIKeyMissing(ctx *ReviewContext) (*Report, error) {
+	config := getPDNSConfig(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	if _, _, enabled := config.GetWebserverConfig(); !enabled {
+		return nil, nil
+	}
+	if apiKey := config.GetAPIKey(); apiKey != "" {
+		return nil, nil
+	}
+	return NewReport(ctx, "The PowerDNS {daemon} has the webserver and the REST API "+
+		"enabled but no api-key is specified. The REST API rejects the requests "+
+		"without the key, so Stork cannot fetch the server information and the "+
+		"zones. Set the api-key parameter in the configuration file.").
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the PowerDNS webserver listening on a non-loopback
+// address restricts the clients allowed to connect to it.
+func pdnsWebserverPublicWithoutAllowFrom(ctx *ReviewContext) (*Report, error) {
+	config := getPDNSConfig(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	if webserver := config.GetBool("webserver"); webserver == nil || !*webserver {
+		return nil, nil
+	}
+	// The webserver listens on the loopback address by default.
+	address := "127.0.0.1"
+	if webserverAddress := config.GetString("webserver-address"); webserverAddress != nil {
+		address = *webserverAddress
+	}
+	if isPDNSLoopbackAddress(address) {
+		return nil, nil
+	}
+	allowFrom := getPDNSStringValues(config, "webserver-allow-from")
+	if len(allowFrom) == 0 {
+		return NewReport(ctx, fmt.Sprintf("The PowerDNS {daemon} webserver listens "+
+			"on the %s address but the webserver-allow-from parameter is not "+
+			"specified. The access to the webserver depends on the default value "+
+			"of this parameter, which may differ between the PowerDNS versions. "+
+			"Explicitly specify the webserver-allow-from parameter with the "+
+			"addresses of the clients allowed to access the statistics and the "+
+			"REST API.", address)).
+			referencingDaemon(ctx.subjectDaemon).
+			create()
+	}
+	for _, allowed := range allowFrom {
+		if isPDNSAnyPrefix(allowed) {
+			return NewReport(ctx, fmt.Sprintf("The PowerDNS {daemon} webserver listens "+
+				"on the %s address and the webserver-allow-from parameter includes "+
+				"%s, which allows any client to access the statistics and the REST "+
+				"API. Restrict the webserver-allow-from parameter to the addresses "+
+				"of the trusted clients.", address, allowed)).
+				referencingDaemon(ctx.subjectDaemon).
+				create()
+		}
+	}
+	return nil, nil
+}
+
+// The checker verifying if the zones served by PowerDNS can be transferred
+// by the remote clients. PowerDNS allows the zone transfers to the clients
+// listed in the allow-axfr-ips parameter. The transfers are authenticated
+// only when the zones are associated with the TSIG keys using the
+// TSIG-ALLOW-AXFR metadata. The metadata is kept in the backends rather
+// than in the configuration file, so the checker cannot tell which zones
+// require TSIG. It reports the remote clients allowed to transfer the
+// zones instead.
+func pdnsAXFROpenToRemote(ctx *ReviewContext) (*Report, error) {
+	config := getPDNSConfig(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	if disableAXFR := config.GetBool("disable-axfr"); disableAXFR != nil && *disableAXFR {
+		return nil, nil
+	}
+	// By default, PowerDNS allows the zone transfers from the loopback
+	// addresses only.
+	var remoteClients []string
+	for _, allowed := range getPDNSStringValues(config, "allow-axfr-ips") {
+		if !isPDNSLoopbackAddress(allowed) {
+			remoteClients = append(remoteClients, allowed)
+		}
+	}
+	if len(remoteClients) == 0 {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("The PowerDNS {daemon} allows zone transfers "+
+		"to the clients matching the allow-axfr-ips parameter (%s). The source "+
+		"addresses can be spoofed, and the transfers of the zones not associated "+
+		"with the TSIG keys are not authenticated. Ensure that the zones have the "+
+		"TSIG-ALLOW-AXFR metadata to authenticate the secondary servers.",
+		strings.Join(remoteClients, ", "))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The checker verifying if the PowerDNS log level is high enough to log
+// the zone transfers.
+func pdnsLogLevelTooLow(ctx *ReviewContext) (*Report, error) {
+	config := getPDNSConfig(ctx)
+	if config == nil {
+		return nil, nil
+	}
+	logLevel := int64(pdnsDefaultLogLevel)
+	if configuredLogLevel := config.GetInt64("loglevel"); configuredLogLevel != nil {
+		logLevel = *configuredLogLevel
+	}
+	if logLevel >= pdnsMinXFRLogLevel {
+		return nil, nil
+	}
+	return NewReport(ctx, fmt.Sprintf("The PowerDNS {daemon} log level is %d. "+
+		"PowerDNS logs the incoming and outgoing zone transfers with the notice "+
+		"level (%d), so they are not recorded in the logs. It makes tracking "+
+		"and troubleshooting the zone transfers difficult. Consider setting "+
+		"the loglevel parameter to %d or higher.", logLevel, pdnsMinXFRLogLevel, pdnsMinXFRLogLevel)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
@@ -0,0 +1,211 @@
+package configreview
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
+	"isc.org/stork/datamodel/daemonname"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Creates the PowerDNS daemon with the specified pdns.conf contents.
+func createPDNSDaemon(t *testing.T, configStr string) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	daemon := dbmodel.NewDaemon(machine, daemonname.PDNS, true, []*dbmodel.AccessPoint{
+		{
+			Type:    dbmodel.AccessPointControl,
+			Address: "127.0.0.1",
+			Port:    8081,
+		},
+	})
+	config, err := pdnsconfig.NewParser().Parse("pdns.conf", strings.NewReader(configStr))
+	require.NoError(t, err)
+	daemon.PDNSDaemon.Config = config
+	return daemon
+}
+
+// Creates review context for the PowerDNS daemon with the specified
+// pdns.conf contents.
+func createPDNSReviewContext(t *testing.T, db *dbops.PgDB, configStr string) *ReviewContext {
+	daemon := createPDNSDaemon(t, configStr)
+	daemon.ID = 1
+
+	ctx := newReviewContext(db, daemon, []Trigger{ManualRun}, nil)
+	require.NotNil(t, ctx)
+
+	return ctx
+}
+
+// Tests that the PowerDNS checkers return no reports when the configuration
+// has not been fetched from the agent.
+func TestPDNSCheckersNoConfig(t *testing.T) {
+	ctx := createPDNSReviewContext(t, nil, "")
+	ctx.subjectDaemon.PDNSDaemon.Config = nil
+
+	for _, checker := range []func(*ReviewContext) (*Report, error){
+		pdnsAPIKeyMissing,
+		pdnsWebserverPublicWithoutAllowFrom,
+		pdnsAXFROpenToRemote,
+		pdnsLogLevelTooLow,
+	} {
+		report, err := checker(ctx)
+		require.NoError(t, err)
+		require.Nil(t, report)
+	}
+}
+
+// Tests that the missing API key is reported when the webserver and the
+// REST API are enabled.
+func TestPDNSAPIKeyMissing(t *testing.T) {
+	report, err := pdnsAPIKeyMissing(createPDNSReviewContext(t, nil, "api=yes\nwebserver=yes\n"))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "no api-key is specified")
+	require.Equal(t, []int64{1}, report.refDaemonIDs)
+}
+
+// Tests that the API key is not reported when it is specified. The agent
+// redacts the key, so the asterisks are specified instead.
+func TestPDNSAPIKeyMissingKeySpecified(t *testing.T) {
+	report, err := pdnsAPIKeyMissing(createPDNSReviewContext(t, nil, "api=yes\napi-key=********\nwebserver=yes\n"))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the missing API key is not reported when the REST API is
+// disabled.
+func TestPDNSAPIKeyMissingAPIDisabled(t *testing.T) {
+	report, err := pdnsAPIKeyMissing(createPDNSReviewContext(t, nil, "api=no\nwebserver=yes\n"))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the webserver listening on a non-loopback address without the
+// webserver-allow-from parameter is reported.
+func TestPDNSWebserverPublicWithoutAllowFrom(t *testing.T) {
+	configStr := "webserver=yes\nwebserver-address=0.0.0.0\n"
+	report, err := pdnsWebserverPublicWithoutAllowFrom(createPDNSReviewContext(t, nil, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "listens on the 0.0.0.0 address but the webserver-allow-from parameter is not specified")
+}
+
+// Tests that the webserver listening on a non-loopback address and
+// allowing access from any address is reported.
+func TestPDNSWebserverPublicAllowFromAny(t *testing.T) {
+	configStr := "webserver=yes\nwebserver-address=192.0.2.1\nwebserver-allow-from=10.0.0.0/8,::/0\n"
+	report, err := pdnsWebserverPublicWithoutAllowFrom(createPDNSReviewContext(t, nil, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "listens on the 192.0.2.1 address and the webserver-allow-from parameter includes ::/0")
+}
+
+// Tests that the webserver restricting the access or listening on the
+// loopback address is not reported.
+func TestPDNSWebserverPublicWithoutAllowFromNoIssues(t *testing.T) {
+	for _, configStr := range []string{
+		"webserver=no\nwebserver-address=0.0.0.0\n",
+		"webserver=yes\n",
+		"webserver=yes\nwebserver-address=::1\n",
+		"webserver=yes\nwebserver-address=0.0.0.0\nwebserver-allow-from=10.0.0.0/8,0.0.0.0\n",
+	} {
+		t.Run(configStr, func(t *testing.T) {
+			report, err := pdnsWebserverPublicWithoutAllowFrom(createPDNSReviewContext(t, nil, configStr))
+			require.NoError(t, err)
+			require.Nil(t, report)
+		})
+	}
+}
+
+// Tests that the zones are not reported when the zone transfers are
+// disabled or allowed from the loopback addresses only. The database is
+// not queried in these cases.
+func TestPDNSAXFROpenToRemoteNoRemoteClients(t *testing.T) {
+	for _, configStr := range []string{
+		"",
+		"allow-axfr-ips=127.0.0.0/8,::1\n",
+		"disable-axfr=yes\nallow-axfr-ips=192.0.2.0/24\n",
+	} {
+		t.Run(configStr, func(t *testing.T) {
+			report, err := pdnsAXFROpenToRemote(createPDNSReviewContext(t, nil, configStr))
+			require.NoError(t, err)
+			require.Nil(t, report)
+		})
+	}
+}
+
+// Tests that the remote clients allowed to transfer the zones are reported.
+func TestPDNSAXFROpenToRemote(t *testing.T) {
+	report, err := pdnsAXFROpenToRemote(createPDNSReviewContext(t, nil, "allow-axfr-ips=127.0.0.1,192.0.2.0/24,2001:db8::/64\n"))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "allows zone transfers to the clients matching the allow-axfr-ips parameter (192.0.2.0/24, 2001:db8::/64)")
+	require.Contains(t, *report.content, "TSIG-ALLOW-AXFR metadata")
+}
+
+// Tests that the zones served by PowerDNS are not reported as transferable
+// without TSIG. The zones may be associated with the TSIG keys in the
+// backend, which is not visible in the configuration file.
+func TestPDNSAXFROpenToRemoteZones(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := createPDNSDaemon(t, "allow-axfr-ips=127.0.0.1,192.0.2.0/24\n")
+	machine := daemon.Machine
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+	daemon.MachineID = machine.ID
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	err = dbmodel.AddZones(db, &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{{
+			DaemonID: daemon.ID,
+			View:     "localhost",
+			Class:    "IN",
+			Type:     "primary",
+			LoadedAt: time.Now().UTC(),
+		}},
+	})
+	require.NoError(t, err)
+
+	report, err := pdnsAXFROpenToRemote(newReviewContext(db, daemon, []Trigger{ManualRun}, nil))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.NotContains(t, *report.content, "example.com")
+	require.NotContains(t, *report.content, "without TSIG")
+}
+
+// Tests that too low log level is reported.
+func TestPDNSLogLevelTooLow(t *testing.T) {
+	report, err := pdnsLogLevelTooLow(createPDNSReviewContext(t, nil, "loglevel=3\n"))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "log level is 3")
+	require.Contains(t, *report.content, "setting the loglevel parameter to 5 or higher")
+}
+
+// Tests that the default log level is reported as too low.
+func TestPDNSLogLevelTooLowDefault(t *testing.T) {
+	report, err := pdnsLogLevelTooLow(createPDNSReviewContext(t, nil, ""))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "log level is 4")
+}
+
+// Tests that the log level sufficient for logging the zone transfers is
+// not reported.
+func TestPDNSLogLevelTooLowNoIssues(t *testing.T) {
+	report, err := pdnsLogLevelTooLow(createPDNSReviewContext(t, nil, "loglevel=6\n"))
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
@@ -0,0 +1,309 @@
+package configreview
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/configreview/expression"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Scopes of the user-defined config review rules. The expression of the
+// rule with the config scope is evaluated once for the daemon
+// configuration. The expression of the rule with the subnet scope is
+// evaluated for each subnet, including the subnets in the shared networks.
+const (
+	RuleScopeConfig = "config"
+	RuleScopeSubnet = "subnet"
+)
+
+// Names of the variables available in the rule expressions and messages.
+const (
+	// Name of the reviewed daemon, e.g., dhcp4.
+	ruleVariableDaemon = "daemon"
+	// Configuration of the reviewed daemon without the root node, e.g.,
+	// the contents of the Dhcp4 map.
+	ruleVariableConfig = "config"
+	// Configuration of the subnet (subnet scope only).
+	ruleVariableSubnet = "subnet"
+	// Configuration of the shared network including the subnet or null
+	// if the subnet doesn't belong to any shared network (subnet scope
+	// only).
+	ruleVariableSharedNetwork = "sharedNetwork"
+)
+
+// Pattern of the rule names. The rule name is used as a checker name.
+//
+//nolint:gochecknoglobals
+var ruleNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// User-defined rule prepared for registration in the dispatcher.
+type compiledRule struct {
+	name       string
+	selector   DispatchGroupSelector
+	scope      string
+	triggers   Triggers
+	expression *expression.Expression
+	message    *expression.Template
+}
+
+// Returns the dispatch group selectors the rules can be registered for.
+// The rule expressions are evaluated over the Kea configurations, so only
+// the Kea selectors are allowed. The subnet scope requires the DHCP
+// selectors.
+func getRuleSelectors(scope string) DispatchGroupSelectors {
+	if scope == RuleScopeSubnet {
+		return DispatchGroupSelectors{KeaDHCPDaemon, KeaDHCPv4Daemon, KeaDHCPv6Daemon}
+	}
+	return DispatchGroupSelectors{KeaDaemon, KeaCADaemon, KeaDHCPDaemon, KeaDHCPv4Daemon, KeaDHCPv6Daemon, KeaD2Daemon}
+}
+
+// Returns the triggers the rules can be run for.
+func getRuleTriggers() Triggers {
+	return Triggers{ManualRun, ConfigModified, DBHostsModified, StorkAgentConfigModified, ConfigDriftModified}
+}
+
+// Validates the rule and compiles its expression and message.
+func compileRule(rule *dbmodel.ConfigReviewRule) (*compiledRule, error) {
+	if !ruleNamePattern.MatchString(rule.Name) {
+		return nil, pkgerrors.Errorf("rule name %s must consist of lowercase letters, digits and underscores", rule.Name)
+	}
+	variables := []string{ruleVariableDaemon, ruleVariableConfig}
+	switch rule.Scope {
+	case RuleScopeConfig:
+	case RuleScopeSubnet:
+		variables = append(variables, ruleVariableSubnet, ruleVariableSharedNetwork)
+	default:
+		return nil, pkgerrors.Errorf("rule scope must be %s or %s", RuleScopeConfig, RuleScopeSubnet)
+	}
+	selector, err := ParseDispatchGroupSelector(rule.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(getRuleSelectors(rule.Scope), selector) {
+		return nil, pkgerrors.Errorf("rule with the %s scope cannot be used with the %s selector", rule.Scope, rule.Selector)
+	}
+	triggers := GetDefaultTriggers()
+	if len(rule.Triggers) > 0 {
+		triggers = Triggers{}
+		for _, name := range rule.Triggers {
+			trigger := Trigger(name)
+			if !slices.Contains(getRuleTriggers(), trigger) {
+				return nil, pkgerrors.Errorf("unknown trigger %s", name)
+			}
+			if !slices.Contains(triggers, trigger) {
+				triggers = append(triggers, trigger)
+			}
+		}
+	}
+	compiledExpression, err := expression.Compile(rule.Expression, variables...)
+	if err != nil {
+		return nil, err
+	}
+	if len(rule.Message) == 0 {
+		return nil, pkgerrors.New("rule message must not be empty")
+	}
+	message, err := expression.CompileTemplate(rule.Message, variables...)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledRule{
+		name:       rule.Name,
+		selector:   selector,
+		scope:      rule.Scope,
+		triggers:   triggers,
+		expression: compiledExpression,
+		message:    message,
+	}, nil
+}
+
+// Returns the daemon configuration without the root node. The rules are
+// evaluated on a copy of the configuration with the sensitive data (e.g.,
+// passwords) hidden, so they cannot be revealed in the rendered messages.
+func getRuleConfig(config *dbmodel.KeaConfig) (map[string]any, error) {
+	raw, _ := config.GetRawConfig()
+	copied, err := keaconfig.NewConfigFromMap(raw)
+	if err != nil {
+		return nil, pkgerrors.WithMessage(err, "problem copying the daemon configuration")
+	}
+	copied.HideSensitiveData()
+	raw, _ = copied.GetRawConfig()
+	for _, root := range raw {
+		if rootMap, ok := root.(map[string]any); ok {
+			return rootMap, nil
+		}
+	}
+	return map[string]any{}, nil
+}
+
+// Evaluates the rule expression. It returns the rendered message when the
+// expression evaluates to false and nil otherwise.
+func (r *compiledRule) evaluate(variables map[string]any) (*string, error) {
+	ok, err := r.expression.EvaluateBool(variables)
+	if err != nil || ok {
+		return nil, err
+	}
+	message, err := r.message.Render(variables)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// Evaluates the rule expression for each subnet, including the subnets in
+// the shared networks. It returns the rendered messages for the subnets
+// for which the expression evaluates to false.
+func (r *compiledRule) evaluateSubnets(daemonName daemonname.Name, config map[string]any) ([]string, error) {
+	subnetKey := "subnet4"
+	if daemonName == daemonname.DHCPv6 {
+		subnetKey = "subnet6"
+	}
+	var issues []string
+	evaluateSubnetList := func(subnets any, sharedNetwork any) error {
+		list, _ := subnets.([]any)
+		for _, subnet := range list {
+			message, err := r.evaluate(map[string]any{
+				ruleVariableDaemon:        string(daemonName),
+				ruleVariableConfig:        config,
+				ruleVariableSubnet:        subnet,
+				ruleVariableSharedNetwork: sharedNetwork,
+			})
+			if err != nil {
+				return err
+			}
+			if message != nil {
+				// The messages are joined with semicolons in the report.
+				issues = append(issues, strings.TrimRight(*message, "."))
+			}
+		}
+		return nil
+	}
+	if err := evaluateSubnetList(config[subnetKey], nil); err != nil {
+		return nil, err
+	}
+	sharedNetworks, _ := config["shared-networks"].([]any)
+	for _, sharedNetwork := range sharedNetworks {
+		if sharedNetworkMap, ok := sharedNetwork.(map[string]any); ok {
+			if err := evaluateSubnetList(sharedNetworkMap[subnetKey], sharedNetworkMap); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return issues, nil
+}
+
+// The checker implementing the user-defined rule. It evaluates the rule
+// expression and generates a report with the rendered message when the
+// expression evaluates to false. The report describing the problem is
+// generated when the expression cannot be evaluated (e.g., it compares
+// values of different types).
+func (r *compiledRule) check(ctx *ReviewContext) (*Report, error) {
+	daemon := ctx.subjectDaemon
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil, nil
+	}
+	config, err := getRuleConfig(daemon.KeaDaemon.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var content string
+	if r.scope == RuleScopeSubnet {
+		issues, err := r.evaluateSubnets(daemon.Name, config)
+		if err != nil {
+			return r.createEvaluationErrorReport(ctx, err)
+		}
+		if len(issues) == 0 {
+			return nil, nil
+		}
+		count, details := formatIssues(issues, "subnet", "s")
+		content = fmt.Sprintf("The {daemon} configuration violates the %s rule in %s.\n%s", r.name, count, details)
+	} else {
+		message, err := r.evaluate(map[string]any{
+			ruleVariableDaemon: string(daemon.Name),
+			ruleVariableConfig: config,
+		})
+		if err != nil {
+			return r.createEvaluationErrorReport(ctx, err)
+		}
+		if message == nil {
+			return nil, nil
+		}
+		content = *message
+	}
+	return NewReport(ctx, content).
+		referencingDaemon(daemon).
+		create()
+}
+
+// Creates the report describing the rule evaluation error.
+func (r *compiledRule) createEvaluationErrorReport(ctx *ReviewContext, err error) (*Report, error) {
+	return NewReport(ctx, fmt.Sprintf("The %s rule cannot be evaluated for the {daemon} "+
+		"configuration: %s. Correct the rule expression and message.", r.name, err)).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// Validates the user-defined rule. It checks the rule name, scope, selector
+// and triggers, and compiles the expression and the message template.
+func ValidateRule(rule *dbmodel.ConfigReviewRule) error {
+	_, err := compileRule(rule)
+	return err
+}
+
+// Registers the user-defined rule in the dispatcher as a checker with the
+// rule name. It returns an error if the rule is invalid or the checker with
+// the same name is already registered.
+func RegisterRule(dispatcher Dispatcher, rule *dbmodel.ConfigReviewRule) error {
+	compiled, err := compileRule(rule)
+	if err != nil {
+		return err
+	}
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	if err != nil {
+		return err
+	}
+	for _, checker := range metadata {
+		if checker.Name == compiled.name {
+			return pkgerrors.Errorf("config checker %s is already registered", compiled.name)
+		}
+	}
+	dispatcher.RegisterChecker(compiled.selector, compiled.name, compiled.triggers, compiled.check)
+	return nil
+}
+
+// Unregisters the checker implementing the user-defined rule from the
+// dispatcher. It returns a boolean value indicating if the checker was
+// found and removed.
+func UnregisterRule(dispatcher Dispatcher, rule *dbmodel.ConfigReviewRule) bool {
+	selector, err := ParseDispatchGroupSelector(rule.Selector)
+	if err != nil {
+		return false
+	}
+	return dispatcher.UnregisterChecker(selector, rule.Name)
+}
+
+// Fetches the user-defined rules from the database and registers them in
+// the dispatcher. The invalid rules are logged and skipped. Returns an
+// error if any database connection problem occurs. It must be called
+// before loading the checker preferences because the preferences may
+// refer to the rules.
+func LoadRules(db dbops.DBI, dispatcher Dispatcher) error {
+	rules, err := dbmodel.GetConfigReviewRules(db)
+	if err != nil {
+		return err
+	}
+	for i := range rules {
+		if err := RegisterRule(dispatcher, &rules[i]); err != nil {
+			log.WithError(err).Errorf("Cannot register the config review rule %s", rules[i].Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package configreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// DHCPv4 configuration used in the rule tests.
+const ruleTestDHCPv4Config = `{
+	"Dhcp4": {
+		"valid-lifetime": 4000,
+		"authoritative": false,
+		"subnet4": [
+			{
+				"id": 1,
+				"subnet": "192.0.2.0/24",
+				"option-data": [
+					{ "code": 6, "data": "192.0.2.1" }
+				]
+			},
+			{
+				"id": 2,
+				"subnet": "192.0.3.0/24"
+			}
+		],
+		"shared-networks": [
+			{
+				"name": "guest",
+				"subnet4": [
+					{
+						"id": 3,
+						"subnet": "10.0.0.0/8",
+						"valid-lifetime": 172800
+					},
+					{
+						"id": 4,
+						"subnet": "10.1.0.0/16"
+					}
+				]
+			}
+		]
+	}
+}`
+
+// Returns a valid rule with the subnet scope.
+func getTestSubnetRule() *dbmodel.ConfigReviewRule {
+	return &dbmodel.ConfigReviewRule{
+		Name:       "subnet_dns_servers",
+		Selector:   "kea-dhcp-v4-daemon",
+		Scope:      RuleScopeSubnet,
+		Expression: `any(subnet["option-data"], it.code == 6 || it.name == "domain-name-servers")`,
+		Message:    "Subnet {{ subnet.subnet }} has no DNS servers.",
+	}
+}
+
+// Tests that the valid rules are accepted.
+func TestValidateRule(t *testing.T) {
+	require.NoError(t, ValidateRule(getTestSubnetRule()))
+
+	rule := &dbmodel.ConfigReviewRule{
+		Name:       "authoritative",
+		Selector:   "kea-dhcp-daemon",
+		Scope:      RuleScopeConfig,
+		Triggers:   []string{"manual", "config change", "manual"},
+		Expression: `daemon != "dhcp4" || config.authoritative == true`,
+		Message:    "The {daemon} is not authoritative.",
+	}
+	compiled, err := compileRule(rule)
+	require.NoError(t, err)
+	require.Equal(t, KeaDHCPDaemon, compiled.selector)
+	require.Equal(t, Triggers{ManualRun, ConfigModified}, compiled.triggers)
+
+	// The default triggers are used when the triggers are not specified.
+	rule.Triggers = nil
+	compiled, err = compileRule(rule)
+	require.NoError(t, err)
+	require.Equal(t, GetDefaultTriggers(), compiled.triggers)
+}
+
+// Tests that the invalid rules are rejected.
+func TestValidateRuleErrors(t *testing.T) {
+	testCases := map[string]struct {
+		modify   func(rule *dbmodel.ConfigReviewRule)
+		expected string
+	}{
+		"invalid name": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Name = "DNS servers" },
+			"rule name DNS servers must consist of lowercase letters, digits and underscores",
+		},
+		"invalid scope": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Scope = "pool" },
+			"rule scope must be config or subnet",
+		},
+		"unknown selector": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Selector = "foo" },
+			"unknown dispatch group selector foo",
+		},
+		"non-Kea selector": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Selector = "bind9-daemon" },
+			"rule with the subnet scope cannot be used with the bind9-daemon selector",
+		},
+		"subnet scope without subnets": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Selector = "kea-ca-daemon" },
+			"rule with the subnet scope cannot be used with the kea-ca-daemon selector",
+		},
+		"unknown trigger": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Triggers = []string{"manual", "internal"} },
+			"unknown trigger internal",
+		},
+		"invalid expression": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Expression = "subnet.id ==" },
+			"failed to parse expression",
+		},
+		"variable out of scope": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Scope = RuleScopeConfig; rule.Selector = "kea-daemon" },
+			"unknown variable subnet",
+		},
+		"empty message": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Message = "" },
+			"rule message must not be empty",
+		},
+		"invalid message": {
+			func(rule *dbmodel.ConfigReviewRule) { rule.Message = "Subnet {{ pool }}" },
+			"unknown variable pool",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rule := getTestSubnetRule()
+			testCase.modify(rule)
+			require.ErrorContains(t, ValidateRule(rule), testCase.expected)
+		})
+	}
+}
+
+// Tests that the rule with the subnet scope reports the subnets for which
+// the expression evaluates to false.
+func TestRuleCheckerSubnetScope(t *testing.T) {
+	ctx := createReviewContext(t, nil, ruleTestDHCPv4Config, "2.6.0")
+	compiled, err := compileRule(getTestSubnetRule())
+	require.NoError(t, err)
+
+	report, err := compiled.check(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, "The {daemon} configuration violates the subnet_dns_servers rule in 3 subnets.\n"+
+		"1. Subnet 192.0.3.0/24 has no DNS servers; 2. Subnet 10.0.0.0/8 has no DNS servers; "+
+		"3. Subnet 10.1.0.0/16 has no DNS servers", *report.content)
+	require.Equal(t, []int64{1}, report.refDaemonIDs)
+}
+
+// Tests that the rule with the subnet scope can refer to the shared network
+// and the global configuration.
+func TestRuleCheckerSubnetScopeSharedNetwork(t *testing.T) {
+	ctx := createReviewContext(t, nil, ruleTestDHCPv4Config, "2.6.0")
+	compiled, err := compileRule(&dbmodel.ConfigReviewRule{
+		Name:       "guest_valid_lifetime",
+		Selector:   "kea-dhcp-daemon",
+		Scope:      RuleScopeSubnet,
+		Expression: `sharedNetwork == null || sharedNetwork.name != "guest" || default(subnet["valid-lifetime"], config["valid-lifetime"]) < 86400`,
+		Message:    `Valid lifetime in subnet {{ subnet.id }} is {{ subnet["valid-lifetime"] }}.`,
+	})
+	require.NoError(t, err)
+
+	report, err := compiled.check(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "in 1 subnet.\n1. Valid lifetime in subnet 3 is 172800")
+}
+
+// Tests that the rule with the config scope reports the rendered message
+// when the expression evaluates to false.
+func TestRuleCheckerConfigScope(t *testing.T) {
+	ctx := createReviewContext(t, nil, ruleTestDHCPv4Config, "2.6.0")
+	rule := &dbmodel.ConfigReviewRule{
+		Name:       "authoritative",
+		Selector:   "kea-dhcp-v4-daemon",
+		Scope:      RuleScopeConfig,
+		Expression: `config.authoritative == true`,
+		Message:    "The {daemon} ({{ daemon }}) is not authoritative.",
+	}
+	compiled, err := compileRule(rule)
+	require.NoError(t, err)
+
+	report, err := compiled.check(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, "The {daemon} (dhcp4) is not authoritative.", *report.content)
+
+	// No issues.
+	rule.Expression = `len(config.subnet4) == 2`
+	compiled, err = compileRule(rule)
+	require.NoError(t, err)
+
+	report, err = compiled.check(ctx)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Tests that the rules cannot reveal the sensitive data from the daemon
+// configuration.
+func TestRuleCheckerConfigScopeSensitiveData(t *testing.T) {
+	ctx := createReviewContext(t, nil, `{
+		"Dhcp4": {
+			"lease-database": {
+				"type": "postgresql",
+				"user": "kea",
+				"password": "secret"
+			}
+		}
+	}`, "2.6.0")
+	compiled, err := compileRule(&dbmodel.ConfigReviewRule{
+		Name:       "lease_database_password",
+		Selector:   "kea-dhcp-v4-daemon",
+		Scope:      RuleScopeConfig,
+		Expression: `false`,
+		Message:    "The password is {{ config['lease-database'].password }}.",
+	})
+	require.NoError(t, err)
+
+	report, err := compiled.check(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, "The password is null.", *report.content)
+
+	// The daemon configuration is not modified.
+	raw, _ := ctx.subjectDaemon.KeaDaemon.Config.GetRawConfig()
+	require.Equal(t, "secret", raw["Dhcp4"].(map[string]any)["lease-database"].(map[string]any)["password"])
+}
+
+// Tests that the report describing the problem is generated when the rule
+// expression cannot be evaluated.
+func TestRuleCheckerEvaluationError(t *testing.T) {
+	ctx := createReviewContext(t, nil, ruleTestDHCPv4Config, "2.6.0")
+	compiled, err := compileRule(&dbmodel.ConfigReviewRule{
+		Name:       "valid_lifetime",
+		Selector:   "kea-dhcp-v4-daemon",
+		Scope:      RuleScopeSubnet,
+		Expression: `subnet["valid-lifetime"] < 86400`,
+		Message:    "Too long valid lifetime.",
+	})
+	require.NoError(t, err)
+
+	report, err := compiled.check(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "The valid_lifetime rule cannot be evaluated for the {daemon} configuration: cannot compare null with 86400")
+}
+
+// Tests that the rule is registered in the dispatcher and unregistered.
+func TestRegisterUnregisterRule(t *testing.T) {
+	dispatcher := NewDispatcher(nil)
+	RegisterDefaultCheckers(dispatcher)
+
+	rule := getTestSubnetRule()
+	err := RegisterRule(dispatcher, rule)
+	require.NoError(t, err)
+
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	require.NoError(t, err)
+	var found *CheckerMetadata
+	for _, m := range metadata {
+		if m.Name == rule.Name {
+			found = m
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, DispatchGroupSelectors{KeaDHCPv4Daemon}, found.Selectors)
+	require.Equal(t, GetDefaultTriggers(), found.Triggers)
+
+	// The rule with the same name cannot be registered twice.
+	err = RegisterRule(dispatcher, rule)
+	require.ErrorContains(t, err, "config checker subnet_dns_servers is already registered")
+
+	// The rule cannot use the name of the built-in checker.
+	rule.Name = "dispensable_subnet"
+	err = RegisterRule(dispatcher, rule)
+	require.ErrorContains(t, err, "config checker dispensable_subnet is already registered")
+
+	rule.Name = "subnet_dns_servers"
+	require.True(t, UnregisterRule(dispatcher, rule))
+	require.False(t, UnregisterRule(dispatcher, rule))
+}
+
+// Tests that the rules are loaded from the database and registered in the
+// dispatcher.
+func TestLoadRules(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.AddConfigReviewRule(db, getTestSubnetRule())
+	require.NoError(t, err)
+
+	// The invalid rule is skipped.
+	err = dbmodel.AddConfigReviewRule(db, &dbmodel.ConfigReviewRule{
+		Name:       "invalid",
+		Selector:   "kea-dhcp-daemon",
+		Scope:      RuleScopeConfig,
+		Expression: "subnet.id == 1",
+		Message:    "message",
+	})
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(db)
+	err = LoadRules(db, dispatcher)
+	require.NoError(t, err)
+
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	require.NoError(t, err)
+	require.Len(t, metadata, 1)
+	require.Equal(t, "subnet_dns_servers", metadata[0].Name)
+}
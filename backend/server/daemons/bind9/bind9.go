@@ -4,16 +4,19 @@ import (
 	"context"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	agentapi "isc.org/stork/api"
+	bind9config "isc.org/stork/daemoncfg/bind9"
 	"isc.org/stork/daemondata/bind9stats"
 	"isc.org/stork/server/agentcomm"
 	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 // Provide example date format how named returns dates.
@@ -172,6 +175,47 @@ func GetDaemonState(ctx context.Context, agents agentcomm.ConnectedAgents, daemo
 	}
 }
 
+// Fetches the BIND 9 configuration from the agent, parses it and stores it
+// in the daemon along with its hash. The configuration is not stored in the
+// database. It is used for the configuration review. The configuration is
+// reset when the agent returns no configuration file.
+func GetDaemonConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon) error {
+	if daemon.Bind9Daemon == nil {
+		return errors.Errorf("daemon %d is not a BIND 9 daemon", daemon.ID)
+	}
+	daemon.Bind9Daemon.Config = nil
+	daemon.Bind9Daemon.ConfigHash = ""
+
+	var (
+		sourcePath string
+		lines      []string
+	)
+	fileSelector := bind9config.NewFileTypeSelector(bind9config.FileTypeConfig)
+	for rsp, err := range agents.ReceiveBind9FormattedConfig(ctx, daemon, fileSelector, nil) {
+		if err != nil {
+			return errors.WithMessage(err, "problem receiving BIND 9 configuration from the agent")
+		}
+		switch r := rsp.GetResponse().(type) {
+		case *agentapi.ReceiveBind9ConfigRsp_File:
+			sourcePath = r.File.GetSourcePath()
+		case *agentapi.ReceiveBind9ConfigRsp_Line:
+			lines = append(lines, r.Line)
+		}
+	}
+	if sourcePath == "" {
+		// No configuration file returned.
+		return nil
+	}
+	text := strings.Join(lines, "\n")
+	config, err := bind9config.NewParser().Parse(sourcePath, "", strings.NewReader(text))
+	if err != nil {
+		return errors.WithMessagef(err, "problem parsing BIND 9 configuration file %s", sourcePath)
+	}
+	daemon.Bind9Daemon.Config = config
+	daemon.Bind9Daemon.ConfigHash = storkutil.Fnv128(text)
+	return nil
+}
+
 // Inserts or updates information about BIND 9 daemon in the database.
 func CommitDaemonIntoDB(db *dbops.PgDB, daemon *dbmodel.Daemon, eventCenter eventcenter.EventCenter) (err error) {
 	if daemon.ID == 0 {
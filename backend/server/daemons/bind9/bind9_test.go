@@ -3,12 +3,15 @@ package bind9
 import (
 	"context"
 	_ "embed"
+	"iter"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 	agentapi "isc.org/stork/api"
+	bind9config "isc.org/stork/daemoncfg/bind9"
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/datamodel/protocoltype"
 	"isc.org/stork/server/agentcomm"
@@ -144,6 +147,87 @@ func TestGetDaemonState(t *testing.T) {
 	require.NotNil(t, daemon.Bind9Daemon)
 }
 
+// Returns the sequence of the responses returned by the agent when the
+// BIND 9 configuration is requested.
+func getBind9ConfigResponses(sourcePath string, lines ...string) iter.Seq2[*agentapi.ReceiveBind9ConfigRsp, error] {
+	return func(yield func(*agentapi.ReceiveBind9ConfigRsp, error) bool) {
+		if !yield(&agentapi.ReceiveBind9ConfigRsp{
+			Response: &agentapi.ReceiveBind9ConfigRsp_File{
+				File: &agentapi.ReceiveBind9ConfigFile{
+					FileType:   agentapi.Bind9ConfigFileType_CONFIG,
+					SourcePath: sourcePath,
+				},
+			},
+		}, nil) {
+			return
+		}
+		for _, line := range lines {
+			if !yield(&agentapi.ReceiveBind9ConfigRsp{
+				Response: &agentapi.ReceiveBind9ConfigRsp_Line{
+					Line: line,
+				},
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Test fetching and parsing the BIND 9 configuration.
+func TestGetDaemonConfig(t *testing.T) {
+	daemon := dbmodel.NewDaemon(&dbmodel.Machine{}, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConnectedAgents := NewMockConnectedAgents(ctrl)
+	mockConnectedAgents.EXPECT().
+		ReceiveBind9FormattedConfig(gomock.Any(), daemon, gomock.Any(), gomock.Nil()).
+		Return(getBind9ConfigResponses("/etc/bind/named.conf",
+			"options {",
+			`	directory "/var/cache/bind";`,
+			"};",
+		))
+
+	err := GetDaemonConfig(context.Background(), mockConnectedAgents, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, daemon.Bind9Daemon.Config)
+	require.Equal(t, "/etc/bind/named.conf", daemon.Bind9Daemon.Config.GetSourcePath())
+	require.NotNil(t, daemon.Bind9Daemon.Config.GetOptions())
+	require.NotEmpty(t, daemon.Bind9Daemon.ConfigHash)
+}
+
+// Test that the configuration is reset when it cannot be fetched or parsed.
+func TestGetDaemonConfigError(t *testing.T) {
+	daemon := dbmodel.NewDaemon(&dbmodel.Machine{}, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+	daemon.Bind9Daemon.Config = &bind9config.Config{}
+	daemon.Bind9Daemon.ConfigHash = "hash"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConnectedAgents := NewMockConnectedAgents(ctrl)
+	mockConnectedAgents.EXPECT().
+		ReceiveBind9FormattedConfig(gomock.Any(), daemon, gomock.Any(), gomock.Nil()).
+		Return(getBind9ConfigResponses("/etc/bind/named.conf", "options {"))
+	mockConnectedAgents.EXPECT().
+		ReceiveBind9FormattedConfig(gomock.Any(), daemon, gomock.Any(), gomock.Nil()).
+		Return(func(yield func(*agentapi.ReceiveBind9ConfigRsp, error) bool) {
+			_ = yield(nil, errors.New("connection refused"))
+		})
+
+	// Invalid configuration.
+	err := GetDaemonConfig(context.Background(), mockConnectedAgents, daemon)
+	require.ErrorContains(t, err, "problem parsing BIND 9 configuration file /etc/bind/named.conf")
+	require.Nil(t, daemon.Bind9Daemon.Config)
+	require.Empty(t, daemon.Bind9Daemon.ConfigHash)
+
+	// Communication error.
+	err = GetDaemonConfig(context.Background(), mockConnectedAgents, daemon)
+	require.ErrorContains(t, err, "connection refused")
+	require.Nil(t, daemon.Bind9Daemon.Config)
+}
+
 // Tests that BIND 9 can be added and then updated in the database.
 func TestCommitDaemonIntoDB(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
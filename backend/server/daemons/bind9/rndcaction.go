@@ -0,0 +1,207 @@
+package bind9
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// An rndc action performed on a zone or on the whole daemon.
+type RndcAction string
+
+// Supported rndc actions.
+const (
+	RndcActionReload     RndcAction = "reload"
+	RndcActionRetransfer RndcAction = "retransfer"
+	RndcActionNotify     RndcAction = "notify"
+	RndcActionFreeze     RndcAction = "freeze"
+	RndcActionThaw       RndcAction = "thaw"
+	RndcActionSign       RndcAction = "sign"
+	RndcActionLoadKeys   RndcAction = "loadkeys"
+	RndcActionFlush      RndcAction = "flush"
+	RndcActionFlushName  RndcAction = "flushname"
+	RndcActionReconfig   RndcAction = "reconfig"
+)
+
+// Optional parameters of the rndc actions.
+type RndcActionParams struct {
+	// Name of the zone the action is performed on.
+	ZoneName string
+	// Class of the zone. It defaults to IN when the view is specified.
+	Class string
+	// Name of the view in which the action is performed.
+	ViewName string
+	// Name flushed from the cache by the flushname action.
+	Name string
+}
+
+// An error returned when the rndc action parameters are invalid.
+type RndcActionParamsError struct {
+	action RndcAction
+	reason string
+}
+
+// Creates new instance of the RndcActionParamsError.
+func NewRndcActionParamsError(action RndcAction, reason string) error {
+	return &RndcActionParamsError{
+		action: action,
+		reason: reason,
+	}
+}
+
+// Returns error string.
+func (e RndcActionParamsError) Error() string {
+	return fmt.Sprintf("invalid parameters of the rndc %s action: %s", e.action, e.reason)
+}
+
+// Checks if the action is performed on a zone.
+func (action RndcAction) isZoneAction() bool {
+	switch action {
+	case RndcActionReload, RndcActionRetransfer, RndcActionNotify,
+		RndcActionFreeze, RndcActionThaw, RndcActionSign, RndcActionLoadKeys:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checks if the zone name is required by the zone action. The reload,
+// freeze and thaw actions are performed on all zones when the zone is
+// not specified.
+func (action RndcAction) isZoneRequired() bool {
+	switch action {
+	case RndcActionRetransfer, RndcActionNotify, RndcActionSign, RndcActionLoadKeys:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns the description of the action and its parameters used in the
+// logs and events, e.g., "reload of zone example.com in view trusted".
+func (action RndcAction) Describe(params RndcActionParams) string {
+	description := string(action)
+	switch {
+	case action.isZoneAction() && params.ZoneName != "":
+		description += fmt.Sprintf(" of zone %s", params.ZoneName)
+	case action.isZoneAction():
+		description += " of all zones"
+	case action == RndcActionFlushName:
+		description += fmt.Sprintf(" of name %s", params.Name)
+	}
+	if params.ViewName != "" {
+		description += fmt.Sprintf(" in view %s", params.ViewName)
+	}
+	return description
+}
+
+// Checks that the view, zone or domain name can be safely passed to rndc.
+// The command is split into arguments on whitespace by the agent, so the
+// names containing whitespace or control characters could inject extra
+// arguments.
+func IsValidRndcArgument(arg string) bool {
+	return arg != "" && !strings.ContainsFunc(arg, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == ';' || r == '{' || r == '}'
+	})
+}
+
+// Validates the parameters and creates the rndc command performing the
+// action. It returns RndcActionParamsError if the parameters are invalid.
+func createRndcActionCommand(action RndcAction, params RndcActionParams) (string, error) {
+	if params.ViewName != "" && !IsValidRndcArgument(params.ViewName) {
+		return "", NewRndcActionParamsError(action, fmt.Sprintf("invalid view name %q", params.ViewName))
+	}
+	args := []string{string(action)}
+	switch {
+	case action.isZoneAction():
+		if params.ZoneName == "" {
+			if action.isZoneRequired() {
+				return "", NewRndcActionParamsError(action, "zone name must be specified")
+			}
+			// Without the zone name the action applies to all zones
+			// in all views.
+			if params.ViewName != "" || params.Class != "" {
+				return "", NewRndcActionParamsError(action, "view and class can only be specified with the zone name")
+			}
+			break
+		}
+		if _, ok := dns.IsDomainName(params.ZoneName); !ok || !IsValidRndcArgument(params.ZoneName) {
+			return "", NewRndcActionParamsError(action, fmt.Sprintf("invalid zone name %q", params.ZoneName))
+		}
+		args = append(args, params.ZoneName)
+		class := params.Class
+		if class == "" && params.ViewName != "" {
+			// The class must precede the view.
+			class = "IN"
+		}
+		if class != "" {
+			if _, ok := dns.StringToClass[strings.ToUpper(class)]; !ok {
+				return "", NewRndcActionParamsError(action, fmt.Sprintf("invalid class %s", class))
+			}
+			args = append(args, strings.ToUpper(class))
+		}
+		if params.ViewName != "" {
+			args = append(args, params.ViewName)
+		}
+	case action == RndcActionFlush:
+		if params.ViewName != "" {
+			args = append(args, params.ViewName)
+		}
+	case action == RndcActionFlushName:
+		if params.Name == "" {
+			return "", NewRndcActionParamsError(action, "name must be specified")
+		}
+		if _, ok := dns.IsDomainName(params.Name); !ok || !IsValidRndcArgument(params.Name) {
+			return "", NewRndcActionParamsError(action, fmt.Sprintf("invalid name %q", params.Name))
+		}
+		args = append(args, params.Name)
+		if params.ViewName != "" {
+			args = append(args, params.ViewName)
+		}
+	case action == RndcActionReconfig:
+		if params.ViewName != "" {
+			return "", NewRndcActionParamsError(action, "view cannot be specified")
+		}
+	default:
+		return "", errors.Errorf("unsupported rndc action %s", action)
+	}
+	return strings.Join(args, " "), nil
+}
+
+// Performs the rndc action by sending a suitable command to the specified
+// BIND 9 daemon. It returns RndcActionParamsError if the parameters are
+// invalid. Otherwise, it returns the text returned by rndc or an error if
+// the command failed.
+func ExecuteRndcAction(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, action RndcAction, params RndcActionParams) (string, error) {
+	if daemon.Name != daemonname.Bind9 {
+		return "", errors.Errorf("rndc actions are not supported for daemon %s", daemon.Name)
+	}
+	command, err := createRndcActionCommand(action, params)
+	if err != nil {
+		return "", err
+	}
+
+	// Reloading the configuration or all zones may take a long time.
+	timeout := 10 * time.Second
+	if action == RndcActionReconfig || (action == RndcActionReload && params.ZoneName == "") {
+		timeout = time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output, err := agents.ForwardRndcCommand(ctx, daemon, command)
+	if err != nil {
+		return "", errors.WithMessagef(err, "rndc %s command failed", action)
+	}
+	if output == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(output.Output), nil
+}
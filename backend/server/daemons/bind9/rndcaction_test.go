@@ -0,0 +1,138 @@
+package bind9
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Returns a BIND 9 daemon used in the rndc action tests.
+func getTestRndcActionDaemon() *dbmodel.Daemon {
+	return &dbmodel.Daemon{
+		ID:          1,
+		Name:        daemonname.Bind9,
+		Bind9Daemon: &dbmodel.Bind9Daemon{},
+	}
+}
+
+// Test that the rndc commands are created for the supported actions.
+func TestCreateRndcActionCommand(t *testing.T) {
+	testCases := []struct {
+		action   RndcAction
+		params   RndcActionParams
+		expected string
+	}{
+		{RndcActionReload, RndcActionParams{}, "reload"},
+		{RndcActionReload, RndcActionParams{ZoneName: "example.com"}, "reload example.com"},
+		{RndcActionReload, RndcActionParams{ZoneName: "example.com", ViewName: "trusted"}, "reload example.com IN trusted"},
+		{RndcActionRetransfer, RndcActionParams{ZoneName: "example.com", Class: "ch", ViewName: "trusted"}, "retransfer example.com CH trusted"},
+		{RndcActionNotify, RndcActionParams{ZoneName: "example.com", Class: "IN"}, "notify example.com IN"},
+		{RndcActionFreeze, RndcActionParams{}, "freeze"},
+		{RndcActionThaw, RndcActionParams{ZoneName: "example.com", ViewName: "_default"}, "thaw example.com IN _default"},
+		{RndcActionSign, RndcActionParams{ZoneName: "example.com"}, "sign example.com"},
+		{RndcActionLoadKeys, RndcActionParams{ZoneName: "example.com", ViewName: "guest"}, "loadkeys example.com IN guest"},
+		{RndcActionFlush, RndcActionParams{}, "flush"},
+		{RndcActionFlush, RndcActionParams{ViewName: "guest"}, "flush guest"},
+		{RndcActionFlushName, RndcActionParams{Name: "www.example.org", ViewName: "guest"}, "flushname www.example.org guest"},
+		{RndcActionReconfig, RndcActionParams{}, "reconfig"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.expected, func(t *testing.T) {
+			command, err := createRndcActionCommand(testCase.action, testCase.params)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, command)
+		})
+	}
+}
+
+// Test that the invalid rndc action parameters are rejected.
+func TestCreateRndcActionCommandInvalidParams(t *testing.T) {
+	testCases := []struct {
+		action RndcAction
+		params RndcActionParams
+		reason string
+	}{
+		{RndcActionRetransfer, RndcActionParams{}, "zone name must be specified"},
+		{RndcActionReload, RndcActionParams{ViewName: "trusted"}, "view and class can only be specified with the zone name"},
+		{RndcActionNotify, RndcActionParams{ZoneName: "example..com"}, `invalid zone name "example..com"`},
+		{RndcActionRetransfer, RndcActionParams{ZoneName: "example.com; reconfig"}, `invalid zone name "example.com; reconfig"`},
+		{RndcActionNotify, RndcActionParams{ZoneName: "example.com IN trusted"}, `invalid zone name "example.com IN trusted"`},
+		{RndcActionReload, RndcActionParams{ZoneName: "example.com\tIN"}, `invalid zone name "example.com\tIN"`},
+		{RndcActionNotify, RndcActionParams{ZoneName: "example.com", Class: "XY"}, "invalid class XY"},
+		{RndcActionFlush, RndcActionParams{ViewName: "guest; reconfig"}, `invalid view name "guest; reconfig"`},
+		{RndcActionFlush, RndcActionParams{ViewName: "guest trusted"}, `invalid view name "guest trusted"`},
+		{RndcActionFlushName, RndcActionParams{}, "name must be specified"},
+		{RndcActionFlushName, RndcActionParams{Name: "example..com"}, `invalid name "example..com"`},
+		{RndcActionFlushName, RndcActionParams{Name: "example.com; reconfig"}, `invalid name "example.com; reconfig"`},
+		{RndcActionFlushName, RndcActionParams{Name: "example.com guest"}, `invalid name "example.com guest"`},
+		{RndcActionFlushName, RndcActionParams{Name: "example.com\nguest"}, `invalid name "example.com\nguest"`},
+		{RndcActionReconfig, RndcActionParams{ViewName: "guest"}, "view cannot be specified"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.reason, func(t *testing.T) {
+			_, err := createRndcActionCommand(testCase.action, testCase.params)
+			var paramsErr *RndcActionParamsError
+			require.ErrorAs(t, err, &paramsErr)
+			require.ErrorContains(t, err, testCase.reason)
+		})
+	}
+
+	_, err := createRndcActionCommand(RndcAction("stop"), RndcActionParams{})
+	require.ErrorContains(t, err, "unsupported rndc action stop")
+}
+
+// Test the description of the rndc actions.
+func TestRndcActionDescribe(t *testing.T) {
+	require.Equal(t, "reload of all zones", RndcActionReload.Describe(RndcActionParams{}))
+	require.Equal(t, "retransfer of zone example.com in view trusted", RndcActionRetransfer.Describe(RndcActionParams{ZoneName: "example.com", ViewName: "trusted"}))
+	require.Equal(t, "flushname of name www.example.org in view guest", RndcActionFlushName.Describe(RndcActionParams{Name: "www.example.org", ViewName: "guest"}))
+	require.Equal(t, "flush in view guest", RndcActionFlush.Describe(RndcActionParams{ViewName: "guest"}))
+	require.Equal(t, "reconfig", RndcActionReconfig.Describe(RndcActionParams{}))
+}
+
+// Test that the rndc action is executed and the rndc output is returned.
+func TestExecuteRndcAction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := NewMockConnectedAgents(ctrl)
+	daemon := getTestRndcActionDaemon()
+	mock.EXPECT().ForwardRndcCommand(gomock.Any(), daemon, "retransfer example.com IN trusted").
+		Return(&agentcomm.RndcOutput{Output: "zone transfer requested\n"}, nil)
+
+	text, err := ExecuteRndcAction(context.Background(), mock, daemon, RndcActionRetransfer, RndcActionParams{
+		ZoneName: "example.com",
+		ViewName: "trusted",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "zone transfer requested", text)
+}
+
+// Test that an error is returned when the rndc command fails or the
+// action is not supported by the daemon.
+func TestExecuteRndcActionError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := NewMockConnectedAgents(ctrl)
+	daemon := getTestRndcActionDaemon()
+	mock.EXPECT().ForwardRndcCommand(gomock.Any(), daemon, "sign example.com").
+		Return(nil, errors.New("not a dynamic zone"))
+
+	_, err := ExecuteRndcAction(context.Background(), mock, daemon, RndcActionSign, RndcActionParams{
+		ZoneName: "example.com",
+	})
+	require.ErrorContains(t, err, "rndc sign command failed: not a dynamic zone")
+
+	// The parameters are validated before contacting the agent.
+	_, err = ExecuteRndcAction(context.Background(), mock, daemon, RndcActionSign, RndcActionParams{})
+	var paramsErr *RndcActionParamsError
+	require.ErrorAs(t, err, &paramsErr)
+
+	// Other daemons are not supported.
+	daemon.Name = daemonname.PDNS
+	_, err = ExecuteRndcAction(context.Background(), mock, daemon, RndcActionFlush, RndcActionParams{})
+	require.ErrorContains(t, err, "rndc actions are not supported for daemon pdns")
+}
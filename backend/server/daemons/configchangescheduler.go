@@ -0,0 +1,34 @@
+package daemons
+
+import (
+	"time"
+
+	"isc.org/stork/server/config"
+	storkutil "isc.org/stork/util"
+)
+
+// Interval at which the scheduler checks if there are scheduled config
+// changes which deadlines have expired.
+const configChangeSchedulerInterval = 10 * time.Second
+
+// Instance of the scheduler which periodically commits the scheduled
+// config changes which deadlines have expired.
+type ConfigChangeScheduler struct {
+	*storkutil.PeriodicExecutor
+}
+
+// Creates an instance of the scheduler committing the due config changes
+// with the specified config manager.
+func NewConfigChangeScheduler(manager config.Manager) (*ConfigChangeScheduler, error) {
+	executor, err := storkutil.NewPeriodicExecutor("Config Change Scheduler",
+		manager.CommitDue,
+		func() (time.Duration, error) {
+			return configChangeSchedulerInterval, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigChangeScheduler{
+		PeriodicExecutor: executor,
+	}, nil
+}
@@ -0,0 +1,22 @@
+package daemons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appstest "isc.org/stork/server/daemons/test"
+)
+
+// Test creating and shutting down the config change scheduler.
+func TestNewConfigChangeScheduler(t *testing.T) {
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{})
+	require.NotNil(t, manager)
+
+	scheduler, err := NewConfigChangeScheduler(manager)
+	require.NoError(t, err)
+	require.NotNil(t, scheduler)
+	defer scheduler.Shutdown()
+
+	require.Equal(t, configChangeSchedulerInterval, scheduler.GetInterval())
+	require.Equal(t, "Config Change Scheduler", scheduler.GetName())
+}
@@ -0,0 +1,16 @@
+package kea
+
+import (
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Stores the client classes from the Kea daemon configuration in the
+// database. The classes that are no longer configured are removed.
+// It does nothing for the daemons other than the DHCP servers.
+func commitDaemonClientClasses(dbi dbops.DBI, daemon *dbmodel.Daemon) error {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil || !daemon.Name.IsDHCP() {
+		return nil
+	}
+	return dbmodel.CommitClientClassesIntoDB(dbi, daemon.ID, daemon.KeaDaemon.Config.GetClientClasses())
+}
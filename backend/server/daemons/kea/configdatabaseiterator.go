@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/daemoncfg/kea"
+	keactrl "isc.org/stork/daemonctrl/kea"
 	dbmodel "isc.org/stork/server/database/model"
 )
 
@@ -27,6 +28,22 @@ type configTargetKey struct {
 	DaemonID int64
 }
 
+// Checks if the daemon's configuration should be altered in the config
+// backend database using the cb_cmds hook library.
+func isConfigBackendDaemon(daemon *dbmodel.Daemon) bool {
+	return daemon != nil && daemon.KeaDaemon != nil && daemon.KeaDaemon.Config != nil &&
+		daemon.KeaDaemon.Config.IsConfigBackendAltering()
+}
+
+// Returns the server tag of the daemon using the config backend. If the
+// server tag is not configured, the "all" tag is returned.
+func getConfigBackendServerTag(daemon *dbmodel.Daemon) string {
+	if daemon.KeaDaemon != nil && daemon.KeaDaemon.ServerTag != nil {
+		return *daemon.KeaDaemon.ServerTag
+	}
+	return keactrl.ServerTagAll
+}
+
 // Constructs a configTargetKey for a local subnet's cb_cmds daemon.
 // The daemon's config databases list must not be empty.
 func buildConfigTargetKey(daemon *dbmodel.Daemon) (configTargetKey, error) {
@@ -70,7 +87,7 @@ func forEachUniqueConfigSource(
 	localSubnetsByBackend := map[configTargetKey][]*dbmodel.LocalSubnet{}
 
 	for _, ls := range localSubnets {
-		hook := ls.Daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+		hook := ls.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary()
 		switch hook {
 		case keaconfig.SubnetAndSharedNetworkAlteringHookLibrarySubnetCmds:
 			// For non-cb_cmds daemons, the config target is the daemon config,
@@ -164,11 +181,7 @@ func forEachUniqueConsistentConfigSource(
 		// Collect unique server tags.
 		serverTagsSet := make(map[string]struct{})
 		for _, ls := range localSubnets {
-			serverTag := "all"
-			if ls.Daemon.KeaDaemon.ServerTag != nil {
-				serverTag = *ls.Daemon.KeaDaemon.ServerTag
-			}
-			serverTagsSet[serverTag] = struct{}{}
+			serverTagsSet[getConfigBackendServerTag(ls.Daemon)] = struct{}{}
 		}
 		serverTags := slices.Collect(maps.Keys(serverTagsSet))
 
@@ -180,3 +193,49 @@ func forEachUniqueConsistentConfigSource(
 		return nil
 	})
 }
+
+// Calls fn once per unique config backend among the given daemons using the
+// cb_cmds hook library. The fn receives the first daemon sharing the config
+// backend and the unique server tags of all daemons sharing it. The daemons
+// with an invalid config database configuration are skipped. The daemons
+// which don't use the config backend are ignored. The order of the calls
+// follows the order of the daemons.
+func forEachUniqueConfigBackend(
+	daemons []*dbmodel.Daemon,
+	fn func(daemon *dbmodel.Daemon, serverTags []string) error,
+) error {
+	var keys []configTargetKey
+	daemonsByBackend := map[configTargetKey][]*dbmodel.Daemon{}
+	for _, daemon := range daemons {
+		if !isConfigBackendDaemon(daemon) {
+			continue
+		}
+		key, err := buildConfigTargetKey(daemon)
+		if err != nil {
+			log.WithError(err).Warnf(
+				"Skipping daemon [%d] while iterating over Config Backends "+
+					"because it has an invalid config database configuration",
+				daemon.ID,
+			)
+			continue
+		}
+		if _, ok := daemonsByBackend[key]; !ok {
+			keys = append(keys, key)
+		}
+		daemonsByBackend[key] = append(daemonsByBackend[key], daemon)
+	}
+
+	for _, key := range keys {
+		var serverTags []string
+		for _, daemon := range daemonsByBackend[key] {
+			serverTag := getConfigBackendServerTag(daemon)
+			if !slices.Contains(serverTags, serverTag) {
+				serverTags = append(serverTags, serverTag)
+			}
+		}
+		if err := fn(daemonsByBackend[key][0], serverTags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -376,3 +376,51 @@ func TestForEachUniqueConsistentConfigSourceAcceptsConsistentLocalSubnetData(t *
 	// Assert
 	require.NoError(t, err)
 }
+
+// Tests that the config backend iterator calls a function once per config
+// database with the distinct server tags and skips the daemons not using the
+// config backend.
+func TestForEachUniqueConfigBackend(t *testing.T) {
+	// Arrange
+	daemon1 := newTestDaemonWithConfig(t, daemonname.DHCPv4, storkutil.Ptr("server1"), keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+	daemon1.ID = 1
+	daemon2 := newTestDaemonWithConfig(t, daemonname.DHCPv4, nil, keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+	daemon2.ID = 2
+	daemon3 := newTestDaemonWithConfig(t, daemonname.DHCPv4, storkutil.Ptr("server1"), keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+	daemon3.ID = 3
+	daemon4 := newTestDaemonWithConfig(t, daemonname.DHCPv4, nil, keaconfig.SubnetAndSharedNetworkAlteringHookLibrarySubnetCmds)
+	daemon4.ID = 4
+
+	var (
+		daemons    []*dbmodel.Daemon
+		serverTags [][]string
+	)
+
+	// Act
+	err := forEachUniqueConfigBackend([]*dbmodel.Daemon{daemon1, daemon2, daemon3, daemon4}, func(daemon *dbmodel.Daemon, tags []string) error {
+		daemons = append(daemons, daemon)
+		serverTags = append(serverTags, tags)
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	require.Equal(t, daemon1, daemons[0])
+	require.Equal(t, []string{"server1", "all"}, serverTags[0])
+}
+
+// Tests that the config backend iterator returns an error returned by the
+// function.
+func TestForEachUniqueConfigBackendError(t *testing.T) {
+	// Arrange
+	daemon := newTestDaemonWithConfig(t, daemonname.DHCPv4, nil, keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+
+	// Act
+	err := forEachUniqueConfigBackend([]*dbmodel.Daemon{daemon}, func(daemon *dbmodel.Daemon, tags []string) error {
+		return fmt.Errorf("test error")
+	})
+
+	// Assert
+	require.ErrorContains(t, err, "test error")
+}
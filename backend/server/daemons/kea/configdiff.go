@@ -0,0 +1,267 @@
+package kea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/server/config"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Collects the configuration differences computed for the particular daemons.
+type configChangeDiffCollector struct {
+	entries map[int64][]keaconfig.ConfigDiffEntry
+}
+
+// Computes the differences between two values in the Kea format and
+// appends them to the daemon's differences. The paths of the differences
+// are prefixed with the specified path to indicate which configuration
+// element they pertain to.
+func (collector *configChangeDiffCollector) add(daemonID int64, path string, oldValue, newValue any) error {
+	oldDecoded, err := decodeConfigDiffValue(oldValue)
+	if err != nil {
+		return err
+	}
+	newDecoded, err := decodeConfigDiffValue(newValue)
+	if err != nil {
+		return err
+	}
+	for _, entry := range keaconfig.DiffValues(oldDecoded, newDecoded) {
+		entry.Path = path + entry.Path
+		collector.entries[daemonID] = append(collector.entries[daemonID], entry)
+	}
+	return nil
+}
+
+// Returns the collected differences ordered by daemon IDs.
+func (collector *configChangeDiffCollector) getDiff() []dbmodel.ConfigChangeDaemonDiff {
+	diff := []dbmodel.ConfigChangeDaemonDiff{}
+	for daemonID, entries := range collector.entries {
+		diff = append(diff, dbmodel.ConfigChangeDaemonDiff{
+			DaemonID: daemonID,
+			Entries:  entries,
+		})
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].DaemonID < diff[j].DaemonID
+	})
+	return diff
+}
+
+// Converts a configuration element in the Kea format to the generic
+// representation (maps and lists) that can be compared with
+// keaconfig.DiffValues.
+func decodeConfigDiffValue(value any) (any, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem with encoding configuration element")
+	}
+	var decoded any
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, errors.Wrap(err, "problem with decoding configuration element")
+	}
+	return decoded, nil
+}
+
+// Computes the differences in the daemons' configurations resulting from
+// the config updates queued in the context. The hosts, subnets, shared
+// networks and client classes are converted to the Kea format for each
+// daemon before and after the update, and then compared. The global
+// parameters are compared with the configurations stored in the database.
+// The differences are presented to the users approving the config changes.
+func (module *ConfigModule) ComputeConfigChangeDiff(ctx context.Context) ([]dbmodel.ConfigChangeDaemonDiff, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return nil, errors.New("context lacks state")
+	}
+	collector := &configChangeDiffCollector{
+		entries: make(map[int64][]keaconfig.ConfigDiffEntry),
+	}
+	for _, update := range state.Updates {
+		var err error
+		recipe := update.Recipe
+		switch {
+		case recipe.KeaDaemonsAfterConfigUpdate != nil:
+			err = module.addGlobalParametersDiff(collector, recipe.KeaDaemonsAfterConfigUpdate)
+		case len(recipe.ImportedHosts) > 0:
+			for _, host := range recipe.ImportedHosts {
+				if err = module.addHostDiff(collector, nil, host); err != nil {
+					break
+				}
+			}
+		case recipe.HostBeforeUpdate != nil || recipe.HostAfterUpdate != nil:
+			err = module.addHostDiff(collector, recipe.HostBeforeUpdate, recipe.HostAfterUpdate)
+		case recipe.SubnetBeforeUpdate != nil || recipe.SubnetAfterUpdate != nil:
+			err = module.addSubnetDiff(collector, recipe.SubnetBeforeUpdate, recipe.SubnetAfterUpdate)
+		case recipe.SharedNetworkBeforeUpdate != nil || recipe.SharedNetworkAfterUpdate != nil:
+			err = module.addSharedNetworkDiff(collector, recipe.SharedNetworkBeforeUpdate, recipe.SharedNetworkAfterUpdate)
+		case recipe.ClientClassBeforeUpdate != nil || recipe.ClientClassAfterUpdate != nil:
+			err = addClientClassDiff(collector, recipe.ClientClassBeforeUpdate, recipe.ClientClassAfterUpdate)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return collector.getDiff(), nil
+}
+
+// Compares the updated configurations of the daemons with their
+// configurations stored in the database.
+func (module *ConfigModule) addGlobalParametersDiff(collector *configChangeDiffCollector, daemons []dbmodel.Daemon) error {
+	for _, daemon := range daemons {
+		if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+			continue
+		}
+		existingDaemon, err := dbmodel.GetKeaDaemonByID(module.manager.GetDB(), daemon.ID)
+		if err != nil {
+			return err
+		}
+		if existingDaemon == nil || existingDaemon.KeaDaemon == nil || existingDaemon.KeaDaemon.Config == nil {
+			continue
+		}
+		entries, err := keaconfig.DiffConfigs(existingDaemon.KeaDaemon.Config, daemon.KeaDaemon.Config)
+		if err != nil {
+			return err
+		}
+		collector.entries[daemon.ID] = append(collector.entries[daemon.ID], entries...)
+	}
+	return nil
+}
+
+// Compares the host reservations in the Kea format.
+func (module *ConfigModule) addHostDiff(collector *configChangeDiffCollector, hostBefore, hostAfter *dbmodel.Host) error {
+	lookup := module.manager.GetDHCPOptionDefinitionLookup()
+	reservations := make(map[int64][2]*keaconfig.HostCmdsReservation)
+	for i, host := range []*dbmodel.Host{hostBefore, hostAfter} {
+		if host == nil {
+			continue
+		}
+		for _, lh := range host.LocalHosts {
+			reservation, err := keaconfig.CreateHostCmdsReservation(lh.DaemonID, lookup, host)
+			if err != nil {
+				return err
+			}
+			pair := reservations[lh.DaemonID]
+			pair[i] = reservation
+			reservations[lh.DaemonID] = pair
+		}
+	}
+	for daemonID, pair := range reservations {
+		if err := collector.add(daemonID, "/reservation", pair[0], pair[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compares the subnets in the Kea format.
+func (module *ConfigModule) addSubnetDiff(collector *configChangeDiffCollector, subnetBefore, subnetAfter *dbmodel.Subnet) error {
+	lookup := module.manager.GetDHCPOptionDefinitionLookup()
+	type subnetPair struct {
+		path   string
+		values [2]any
+	}
+	subnets := make(map[int64]*subnetPair)
+	for i, subnet := range []*dbmodel.Subnet{subnetBefore, subnetAfter} {
+		if subnet == nil {
+			continue
+		}
+		for _, ls := range subnet.LocalSubnets {
+			var (
+				keaSubnet any
+				err       error
+				name      string
+			)
+			if subnet.GetFamily() == 4 {
+				keaSubnet, err = keaconfig.CreateSubnet4(ls.DaemonID, lookup, subnet)
+				name = "subnet4"
+			} else {
+				keaSubnet, err = keaconfig.CreateSubnet6(ls.DaemonID, lookup, subnet)
+				name = "subnet6"
+			}
+			if err != nil {
+				return err
+			}
+			pair, ok := subnets[ls.DaemonID]
+			if !ok {
+				pair = &subnetPair{}
+				subnets[ls.DaemonID] = pair
+			}
+			pair.path = fmt.Sprintf("/%s[id=%d]", name, ls.LocalSubnetID)
+			pair.values[i] = keaSubnet
+		}
+	}
+	for daemonID, pair := range subnets {
+		if err := collector.add(daemonID, pair.path, pair.values[0], pair.values[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compares the shared networks in the Kea format.
+func (module *ConfigModule) addSharedNetworkDiff(collector *configChangeDiffCollector, sharedNetworkBefore, sharedNetworkAfter *dbmodel.SharedNetwork) error {
+	lookup := module.manager.GetDHCPOptionDefinitionLookup()
+	type sharedNetworkPair struct {
+		path   string
+		values [2]any
+	}
+	sharedNetworks := make(map[int64]*sharedNetworkPair)
+	for i, sharedNetwork := range []*dbmodel.SharedNetwork{sharedNetworkBefore, sharedNetworkAfter} {
+		if sharedNetwork == nil {
+			continue
+		}
+		for _, lsn := range sharedNetwork.LocalSharedNetworks {
+			var (
+				keaSharedNetwork any
+				err              error
+			)
+			if sharedNetwork.Family == 4 {
+				keaSharedNetwork, err = keaconfig.CreateSharedNetwork4(lsn.DaemonID, lookup, sharedNetwork)
+			} else {
+				keaSharedNetwork, err = keaconfig.CreateSharedNetwork6(lsn.DaemonID, lookup, sharedNetwork)
+			}
+			if err != nil {
+				return err
+			}
+			pair, ok := sharedNetworks[lsn.DaemonID]
+			if !ok {
+				pair = &sharedNetworkPair{}
+				sharedNetworks[lsn.DaemonID] = pair
+			}
+			pair.path = fmt.Sprintf("/shared-networks[name=%s]", sharedNetwork.Name)
+			pair.values[i] = keaSharedNetwork
+		}
+	}
+	for daemonID, pair := range sharedNetworks {
+		if err := collector.add(daemonID, pair.path, pair.values[0], pair.values[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compares the client class definitions.
+func addClientClassDiff(collector *configChangeDiffCollector, classBefore, classAfter *dbmodel.ClientClass) error {
+	var (
+		daemonID int64
+		name     string
+		values   [2]any
+	)
+	for i, class := range []*dbmodel.ClientClass{classBefore, classAfter} {
+		if class == nil || class.KeaParameters == nil {
+			continue
+		}
+		daemonID = class.DaemonID
+		name = class.Name
+		values[i] = class.KeaParameters
+	}
+	if daemonID == 0 {
+		return nil
+	}
+	return collector.add(daemonID, fmt.Sprintf("/client-classes[name=%s]", name), values[0], values[1])
+}
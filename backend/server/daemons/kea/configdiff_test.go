@@ -0,0 +1,115 @@
+package kea
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/config"
+	appstest "isc.org/stork/server/daemons/test"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Test computing the differences resulting from the client class update.
+func TestComputeConfigChangeDiffClientClass(t *testing.T) {
+	module := NewConfigModule(nil)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv4)
+	classBefore := getTestClientClass(daemon, "foo")
+	classAfter := getTestClientClass(daemon, "foo")
+	classAfter.KeaParameters.NextServer = storkutil.Ptr("192.0.2.3")
+	classAfter.KeaParameters.ValidLifetime = storkutil.Ptr(int64(1800))
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaClientClassUpdate, daemon.ID)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassBeforeUpdate: classBefore,
+			ClientClassAfterUpdate:  classAfter,
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	diff, err := module.ComputeConfigChangeDiff(ctx)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, daemon.ID, diff[0].DaemonID)
+	require.Len(t, diff[0].Entries, 2)
+
+	require.Equal(t, "/client-classes[name=foo]/next-server", diff[0].Entries[0].Path)
+	require.Equal(t, keaconfig.ConfigDiffOperationModified, diff[0].Entries[0].Operation)
+	require.Equal(t, "192.0.2.2", diff[0].Entries[0].OldValue)
+	require.Equal(t, "192.0.2.3", diff[0].Entries[0].NewValue)
+
+	require.Equal(t, "/client-classes[name=foo]/valid-lifetime", diff[0].Entries[1].Path)
+	require.Equal(t, keaconfig.ConfigDiffOperationAdded, diff[0].Entries[1].Operation)
+	require.EqualValues(t, 1800, diff[0].Entries[1].NewValue)
+}
+
+// Test computing the differences resulting from adding a host reservation
+// to two daemons.
+func TestComputeConfigChangeDiffHostAdd(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+
+	host := &dbmodel.Host{
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID: 2,
+				Hostname: "cool.example.org",
+			},
+			{
+				DaemonID: 1,
+				Hostname: "cool.example.org",
+			},
+		},
+	}
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaHostAdd, 1, 2)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{
+		HostConfigRecipeParams: HostConfigRecipeParams{
+			HostAfterUpdate: host,
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	diff, err := module.ComputeConfigChangeDiff(ctx)
+	require.NoError(t, err)
+	require.Len(t, diff, 2)
+	for i, daemonDiff := range diff {
+		require.EqualValues(t, i+1, daemonDiff.DaemonID)
+		require.Len(t, daemonDiff.Entries, 1)
+		require.Equal(t, "/reservation", daemonDiff.Entries[0].Path)
+		require.Equal(t, keaconfig.ConfigDiffOperationAdded, daemonDiff.Entries[0].Operation)
+		require.Nil(t, daemonDiff.Entries[0].OldValue)
+		require.Equal(t, "010203040506", daemonDiff.Entries[0].NewValue.(map[string]any)["hw-address"])
+		require.Equal(t, "cool.example.org", daemonDiff.Entries[0].NewValue.(map[string]any)["hostname"])
+	}
+}
+
+// Test that no differences are computed for the updates lacking the
+// information about the changed entities.
+func TestComputeConfigChangeDiffEmpty(t *testing.T) {
+	module := NewConfigModule(nil)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaLeaseWipe, 1)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	diff, err := module.ComputeConfigChangeDiff(ctx)
+	require.NoError(t, err)
+	require.Empty(t, diff)
+}
@@ -0,0 +1,43 @@
+package kea
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Sends the command without arguments to the Kea daemon and checks if it
+// succeeded.
+func sendConfigCommand(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, commandName keactrl.CommandName) error {
+	command := keactrl.NewCommandBase(commandName, daemon.Name)
+	var response keactrl.Response
+	result, err := agents.ForwardToKeaOverHTTP(ctx, daemon, []keactrl.SerializableCommand{command}, &response)
+	if err != nil {
+		return errors.WithMessage(err, "problem communicating with Stork agent")
+	}
+	if err = result.GetFirstError(); err != nil {
+		return errors.WithMessagef(err, "problem with %s response", commandName)
+	}
+	if err = response.GetError(); err != nil {
+		return errors.WithMessagef(err, "%s command failed", commandName)
+	}
+	return nil
+}
+
+// Writes the running configuration of the Kea daemon to its configuration
+// file with the config-write command. It resolves the drift between the
+// running configuration and the file in favor of the running configuration.
+func WriteConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon) error {
+	return sendConfigCommand(ctx, agents, daemon, keactrl.ConfigWrite)
+}
+
+// Reloads the configuration of the Kea daemon from its configuration file
+// with the config-reload command. It resolves the drift between the running
+// configuration and the file in favor of the file.
+func ReloadConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon) error {
+	return sendConfigCommand(ctx, agents, daemon, keactrl.ConfigReload)
+}
@@ -3,11 +3,16 @@ package kea
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/datamodel/daemonname"
+	dhcpmodel "isc.org/stork/datamodel/dhcp"
 	"isc.org/stork/server/config"
 	dbmodel "isc.org/stork/server/database/model"
 	storkutil "isc.org/stork/util"
@@ -45,6 +50,13 @@ type HostConfigRecipeParams struct {
 	HostAfterUpdate *dbmodel.Host
 	// Edited or deleted host ID.
 	HostID *int64
+	// Host reservations added in a single transaction by the bulk import.
+	ImportedHosts []*dbmodel.Host
+	// Errors which occurred while committing the imported hosts. The
+	// error is empty if the respective host has been imported successfully.
+	// The indexes of the errors correspond to the indexes of the imported
+	// hosts.
+	ImportedHostErrors []string
 }
 
 // A structure embedded in the ConfigRecipe grouping parameters used
@@ -79,6 +91,50 @@ type SubnetConfigRecipeParams struct {
 	SubnetID *int64
 }
 
+// A structure embedded in the ConfigRecipe grouping parameters used
+// in transactions adding, updating, deleting and wiping leases.
+type LeaseConfigRecipeParams struct {
+	// An instance of the lease after it has been added or updated. It is
+	// used to update the lease in the Stork database upon commit.
+	LeaseAfterUpdate *dbmodel.Lease
+	// An instance of the deleted lease.
+	DeletedLease *dbmodel.Lease
+	// ID of the daemon from which the leases are wiped.
+	WipedLeasesDaemonID *int64
+	// Local subnet ID from which the leases are wiped. It is 0 when
+	// the leases are wiped from all subnets.
+	WipedLeasesLocalSubnetID *int64
+}
+
+// A structure embedded in the ConfigRecipe grouping parameters used
+// in transactions adding, updating and deleting client classes.
+type ClientClassConfigRecipeParams struct {
+	// An instance of the client class before an update. It is typically
+	// fetched at the beginning of the client class update (e.g., when a
+	// user clicks the client class edit button).
+	ClientClassBeforeUpdate *dbmodel.ClientClass
+	// An instance of the client class after it has been added or updated.
+	// This instance is held in the context until it is committed or
+	// scheduled for committing later.
+	ClientClassAfterUpdate *dbmodel.ClientClass
+	// Edited or deleted client class ID.
+	ClientClassID *int64
+	// Name of the deleted client class.
+	DeletedClientClassName *string
+}
+
+// A structure embedded in the ConfigRecipe grouping parameters used
+// in transactions rolling back the daemon's configuration to one of its
+// previous versions.
+type ConfigRollbackRecipeParams struct {
+	// An instance of the daemon which configuration is rolled back. It is
+	// fetched at the beginning of the rollback.
+	RollbackDaemon *dbmodel.Daemon
+	// Number of the configuration version to which the configuration is
+	// rolled back.
+	RollbackConfigVersion *int64
+}
+
 // Represents a Kea config change recipe. A recipe is associated with
 // each config update and may comprise several commands sent to different
 // Kea servers. Other data stored in the recipe structure are used in the
@@ -101,6 +157,15 @@ type ConfigRecipe struct {
 	// Embedded structure holding the parameters appropriate for the
 	// subnet management.
 	SubnetConfigRecipeParams
+	// Embedded structure holding the parameters appropriate for the
+	// lease management.
+	LeaseConfigRecipeParams
+	// Embedded structure holding the parameters appropriate for the
+	// client class management.
+	ClientClassConfigRecipeParams
+	// Embedded structure holding the parameters appropriate for the
+	// configuration rollback.
+	ConfigRollbackRecipeParams
 }
 
 // A configuration manager module responsible for the Kea configuration.
@@ -148,6 +213,8 @@ func (module *ConfigModule) Commit(ctx context.Context) (context.Context, error)
 			ctx, err = module.commitHostUpdate(ctx)
 		case dbmodel.ConfigOperationKeaHostDelete:
 			ctx, err = module.commitHostDelete(ctx)
+		case dbmodel.ConfigOperationKeaHostImport:
+			ctx, err = module.commitHostImport(ctx)
 		case dbmodel.ConfigOperationKeaSharedNetworkAdd:
 			ctx, err = module.commitSharedNetworkAdd(ctx)
 		case dbmodel.ConfigOperationKeaSharedNetworkUpdate:
@@ -160,6 +227,22 @@ func (module *ConfigModule) Commit(ctx context.Context) (context.Context, error)
 			ctx, err = module.commitSubnetUpdate(ctx)
 		case dbmodel.ConfigOperationKeaSubnetDelete:
 			ctx, err = module.commitSubnetDelete(ctx)
+		case dbmodel.ConfigOperationKeaLeaseAdd:
+			ctx, err = module.commitLeaseAdd(ctx)
+		case dbmodel.ConfigOperationKeaLeaseUpdate:
+			ctx, err = module.commitLeaseUpdate(ctx)
+		case dbmodel.ConfigOperationKeaLeaseDelete:
+			ctx, err = module.commitLeaseDelete(ctx)
+		case dbmodel.ConfigOperationKeaLeaseWipe:
+			ctx, err = module.commitLeaseWipe(ctx)
+		case dbmodel.ConfigOperationKeaClientClassAdd:
+			ctx, err = module.commitClientClassAdd(ctx)
+		case dbmodel.ConfigOperationKeaClientClassUpdate:
+			ctx, err = module.commitClientClassUpdate(ctx)
+		case dbmodel.ConfigOperationKeaClientClassDelete:
+			ctx, err = module.commitClientClassDelete(ctx)
+		case dbmodel.ConfigOperationKeaConfigRollback:
+			ctx, err = module.commitConfigRollback(ctx)
 		default:
 			err = errors.Errorf("unknown operation %s when called Commit()", pu.Operation)
 		}
@@ -227,11 +310,25 @@ func (module *ConfigModule) ApplyGlobalParametersUpdate(ctx context.Context, dae
 	for _, daemonSettableConfig := range daemonSettableConfigs {
 		for _, existingDaemon := range existingDaemons {
 			if daemonSettableConfig.GetID() == existingDaemon.ID {
+				// Remember the global options before the merge. They are
+				// needed to find the options deleted from the config backend.
+				existingOptions := existingDaemon.KeaDaemon.Config.GetDHCPOptions()
 				// Merge the partial configuration into the existing configuration.
 				err = existingDaemon.KeaDaemon.Config.Merge(daemonSettableConfig.GetEntity())
 				if err != nil {
 					return ctx, err
 				}
+				if isConfigBackendDaemon(&existingDaemon) {
+					// The global parameters of the daemons using the config
+					// backend are updated in the database.
+					cbCommands, err := createConfigBackendGlobalParametersCommands(&existingDaemon, daemonSettableConfig.GetEntity(), existingOptions)
+					if err != nil {
+						return ctx, err
+					}
+					commands = append(commands, cbCommands...)
+					updatedDaemonIDs = append(updatedDaemonIDs, daemonSettableConfig.GetID())
+					continue
+				}
 				command := ConfigCommand{
 					Command: keactrl.NewCommandConfigSet(existingDaemon.KeaDaemon.Config.Config, existingDaemon.Name),
 					Daemon:  &existingDaemon,
@@ -246,6 +343,9 @@ func (module *ConfigModule) ApplyGlobalParametersUpdate(ctx context.Context, dae
 	}
 	// Each config-set must come with config-write to persist the configuration.
 	for _, existingDaemon := range existingDaemons {
+		if isConfigBackendDaemon(&existingDaemon) {
+			continue
+		}
 		command := ConfigCommand{
 			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, existingDaemon.Name),
 			Daemon:  &existingDaemon,
@@ -258,6 +358,121 @@ func (module *ConfigModule) ApplyGlobalParametersUpdate(ctx context.Context, dae
 	return config.SetRecipeForUpdate(ctx, 0, recipe)
 }
 
+// Creates the commands updating the global parameters and options of
+// a daemon using the config backend. The daemon's configuration must
+// already include the merged settable configuration. The scalar parameters
+// are set with the remote-global-parameter4-set or remote-global-parameter6-set
+// command. The map parameters are flattened to the names containing dots
+// (e.g., expired-leases-processing.max-reclaim-time). The parameters
+// having null values are deleted. The global options are replaced with
+// the remote-option4-global-set and remote-option4-global-del commands
+// (or their DHCPv6 counterparts).
+func createConfigBackendGlobalParametersCommands(daemon *dbmodel.Daemon, settableConfig *keaconfig.SettableConfig, existingOptions []keaconfig.SingleOptionData) ([]ConfigCommand, error) {
+	rawConfig, err := settableConfig.GetRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	serverTag := getConfigBackendServerTag(daemon)
+	setParameters := make(map[string]any)
+	var (
+		delParameters []string
+		updateOptions bool
+	)
+	for _, section := range rawConfig {
+		parameters, ok := section.(map[string]any)
+		if !ok {
+			continue
+		}
+		for name, value := range parameters {
+			if name == "option-data" {
+				updateOptions = true
+				continue
+			}
+			if err := flattenConfigBackendGlobalParameter(name, value, setParameters, &delParameters); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var commands []ConfigCommand
+	if len(setParameters) > 0 {
+		commands = append(commands, ConfigCommand{
+			Command: keactrl.NewCommandRemoteGlobalParameterSet(setParameters, []string{serverTag}, daemon.Name),
+			Daemon:  daemon,
+		})
+	}
+	if len(delParameters) > 0 {
+		sort.Strings(delParameters)
+		commands = append(commands, ConfigCommand{
+			Command: keactrl.NewCommandRemoteGlobalParameterDel(delParameters, serverTag, daemon.Name),
+			Daemon:  daemon,
+		})
+	}
+	if !updateOptions {
+		return commands, nil
+	}
+	defaultSpace := string(dhcpmodel.DHCPv4OptionSpace)
+	if daemon.Name == daemonname.DHCPv6 {
+		defaultSpace = string(dhcpmodel.DHCPv6OptionSpace)
+	}
+	getOptionSpace := func(option keaconfig.SingleOptionData) string {
+		if option.Space == "" {
+			return defaultSpace
+		}
+		return option.Space
+	}
+	options := daemon.KeaDaemon.Config.GetDHCPOptions()
+	// Delete the options that no longer exist. The options lacking the
+	// code cannot be identified in the config backend.
+	for _, existingOption := range existingOptions {
+		if existingOption.Code == 0 {
+			continue
+		}
+		found := false
+		for _, option := range options {
+			if option.Code == existingOption.Code && getOptionSpace(option) == getOptionSpace(existingOption) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			commands = append(commands, ConfigCommand{
+				Command: keactrl.NewCommandRemoteOptionGlobalDel(existingOption.Code, getOptionSpace(existingOption), serverTag, daemon.Name),
+				Daemon:  daemon,
+			})
+		}
+	}
+	for i := range options {
+		commands = append(commands, ConfigCommand{
+			Command: keactrl.NewCommandRemoteOptionGlobalSet(&options[i], serverTag, daemon.Name),
+			Daemon:  daemon,
+		})
+	}
+	return commands, nil
+}
+
+// Converts the global parameter to the format accepted by the config backend.
+// The scalar parameters are added to the parameters to be set. The parameters
+// with nil values are added to the parameters to be deleted. The maps are
+// flattened recursively. The lists are not supported by the config backend
+// and an error is returned for them.
+func flattenConfigBackendGlobalParameter(name string, value any, setParameters map[string]any, delParameters *[]string) error {
+	switch value := value.(type) {
+	case nil:
+		*delParameters = append(*delParameters, name)
+	case map[string]any:
+		for childName, childValue := range value {
+			if err := flattenConfigBackendGlobalParameter(fmt.Sprintf("%s.%s", name, childName), childValue, setParameters, delParameters); err != nil {
+				return err
+			}
+		}
+	case []any:
+		return errors.Errorf("parameter %s cannot be set in the configuration backend", name)
+	default:
+		setParameters[name] = value
+	}
+	return nil
+}
+
 // Sends commands to Kea to update the global configuration parameters.
 // It also updates the respective configurations in the Stork database.
 func (module *ConfigModule) commitGlobalParametersUpdate(ctx context.Context) (context.Context, error) {
@@ -306,16 +521,58 @@ func (module *ConfigModule) ApplyHostAdd(ctx context.Context, host *dbmodel.Host
 	if len(host.LocalHosts) == 0 {
 		return ctx, errors.New("applied host is not associated with any daemon")
 	}
+	commands, err := module.createHostAddCommands(host)
+	if err != nil {
+		return ctx, err
+	}
+	recipe := &ConfigRecipe{
+		HostConfigRecipeParams: HostConfigRecipeParams{
+			HostAfterUpdate: host,
+		},
+		Commands: commands,
+	}
+	if ctx, err = config.SetRecipeForUpdate(ctx, 0, recipe); err != nil {
+		return ctx, err
+	}
+	return ctx, nil
+}
+
+// Create the host reservation in the Kea servers.
+func (module *ConfigModule) commitHostAdd(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.HostAfterUpdate == nil {
+			return ctx, errors.New("server logic error: the update.Recipe.HostAfterUpdate cannot be nil when committing host creation")
+		}
+		err = dbmodel.AddHost(module.manager.GetDB(), update.Recipe.HostAfterUpdate)
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "host has been successfully added to Kea but adding to the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Creates the reservation-add commands for the host. There is one command
+// for each daemon associated with the host.
+func (module *ConfigModule) createHostAddCommands(host *dbmodel.Host) ([]ConfigCommand, error) {
 	var commands []ConfigCommand
 	for _, lh := range host.LocalHosts {
 		if lh.Daemon == nil {
-			return ctx, errors.New("applied host is associated with nil daemon")
+			return nil, errors.New("applied host is associated with nil daemon")
 		}
 		// Convert the host information to Kea reservation.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
 		reservation, err := keaconfig.CreateHostCmdsReservation(lh.DaemonID, lookup, host)
 		if err != nil {
-			return ctx, err
+			return nil, err
 		}
 		// Associate the command with a daemon receiving this command.
 		command := ConfigCommand{
@@ -324,39 +581,90 @@ func (module *ConfigModule) ApplyHostAdd(ctx context.Context, host *dbmodel.Host
 		}
 		commands = append(commands, command)
 	}
-	var err error
+	return commands, nil
+}
+
+// Begins importing multiple host reservations. It initializes transaction
+// state.
+func (module *ConfigModule) BeginHostImport(ctx context.Context) (context.Context, error) {
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaHostImport)
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies the imported host reservations. It prepares the reservation-add
+// commands for all hosts to be sent to Kea upon commit.
+func (module *ConfigModule) ApplyHostImport(ctx context.Context, hosts []*dbmodel.Host) (context.Context, error) {
+	if len(hosts) == 0 {
+		return ctx, errors.New("no hosts to import")
+	}
+	var commands []ConfigCommand
+	for _, host := range hosts {
+		if len(host.LocalHosts) == 0 {
+			return ctx, errors.New("imported host is not associated with any daemon")
+		}
+		hostCommands, err := module.createHostAddCommands(host)
+		if err != nil {
+			return ctx, err
+		}
+		commands = append(commands, hostCommands...)
+	}
 	recipe := &ConfigRecipe{
 		HostConfigRecipeParams: HostConfigRecipeParams{
-			HostAfterUpdate: host,
+			ImportedHosts: hosts,
 		},
 		Commands: commands,
 	}
+	var err error
 	if ctx, err = config.SetRecipeForUpdate(ctx, 0, recipe); err != nil {
 		return ctx, err
 	}
 	return ctx, nil
 }
 
-// Create the host reservation in the Kea servers.
-func (module *ConfigModule) commitHostAdd(ctx context.Context) (context.Context, error) {
+// Creates the imported host reservations in the Kea servers. Unlike other
+// operations, a failure to add one host doesn't stop adding the remaining
+// hosts. The errors are recorded in the recipe for each host. The hosts
+// successfully added to Kea are also added to the Stork database. It
+// returns an error if any of the hosts couldn't be added.
+func (module *ConfigModule) commitHostImport(ctx context.Context) (context.Context, error) {
 	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
 	if !ok {
 		return ctx, errors.New("context lacks state")
 	}
-	var err error
-	ctx, err = module.commitChanges(ctx)
-	if err != nil {
-		return ctx, err
-	}
-	for _, update := range state.Updates {
-		if update.Recipe.HostAfterUpdate == nil {
-			return ctx, errors.New("server logic error: the update.Recipe.HostAfterUpdate cannot be nil when committing host creation")
+	var failed int
+	for i, update := range state.Updates {
+		recipe := update.Recipe
+		recipe.ImportedHostErrors = make([]string, len(recipe.ImportedHosts))
+		for j, host := range recipe.ImportedHosts {
+			commands, err := module.createHostAddCommands(host)
+			if err == nil {
+				for _, command := range commands {
+					if err = module.sendCommand(command); err != nil {
+						break
+					}
+				}
+			}
+			if err == nil {
+				if err = dbmodel.AddHost(module.manager.GetDB(), host); err != nil {
+					err = errors.WithMessage(err, "host has been successfully added to Kea but adding to the Stork database failed")
+				}
+			}
+			if err != nil {
+				recipe.ImportedHostErrors[j] = err.Error()
+				failed++
+			}
 		}
-		err = dbmodel.AddHost(module.manager.GetDB(), update.Recipe.HostAfterUpdate)
-		if err != nil {
-			return ctx, errors.WithMessagef(err, "host has been successfully added to Kea but adding to the Stork database failed")
+		var err error
+		if ctx, err = config.SetRecipeForUpdate(ctx, i, &recipe); err != nil {
+			return ctx, err
 		}
 	}
+	module.addConfigPendingChanges(ctx)
+	if failed > 0 {
+		return ctx, errors.Errorf("failed to import %d host reservations", failed)
+	}
 	return ctx, nil
 }
 
@@ -618,32 +926,72 @@ func (module *ConfigModule) commitChanges(ctx context.Context) (context.Context,
 		// Retrieve associations between the commands and daemons.
 		// Iterate over the associations.
 		for _, cmd := range update.Recipe.Commands {
-			// Send the command to Kea.
-			var response keactrl.Response
-			result, err := module.manager.GetConnectedAgents().ForwardToKeaOverHTTP(context.Background(), cmd.Daemon, []keactrl.SerializableCommand{cmd.Command}, &response)
-			// There was no error in communication between the server and the agent but
-			// the agent could have issues with the Kea response.
-			if err == nil {
-				// Let's check if the agent found errors in communication with Kea.
-				// If not, the Kea daemon could return an error as a result of
-				// processing the commands.
-				if err = result.GetFirstError(); err == nil {
-					// Let's check if the Kea server returned an error
-					// for the processed command.
-					if err = keactrl.GetResponseError(response); err != nil {
-						break
-					}
-				}
-			}
-			if err != nil {
-				err = errors.WithMessagef(err, "%s command to %s failed", cmd.Command.GetCommand(), cmd.Daemon.GetName())
+			if err := module.sendCommand(cmd); err != nil {
 				return ctx, err
 			}
 		}
 	}
+	module.addConfigPendingChanges(ctx)
 	return ctx, nil
 }
 
+// Sends a single command to Kea and checks the response.
+func (module *ConfigModule) sendCommand(cmd ConfigCommand) error {
+	var response keactrl.Response
+	result, err := module.manager.GetConnectedAgents().ForwardToKeaOverHTTP(context.Background(), cmd.Daemon, []keactrl.SerializableCommand{cmd.Command}, &response)
+	// There was no error in communication between the server and the agent but
+	// the agent could have issues with the Kea response.
+	if err == nil {
+		// Let's check if the agent found errors in communication with Kea.
+		// If not, the Kea daemon could return an error as a result of
+		// processing the commands.
+		if err = result.GetFirstError(); err == nil {
+			// Let's check if the Kea server returned an error
+			// for the processed command.
+			err = keactrl.GetResponseError(response)
+		}
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "%s command to %s failed", cmd.Command.GetCommand(), cmd.Daemon.GetName())
+	}
+	return nil
+}
+
+// Remembers the configuration changes successfully sent to the daemons.
+// The config puller uses them to attribute the next fetched configuration
+// versions to the user who applied the changes. The failure to remember
+// the changes is not fatal because the changes have been already applied.
+func (module *ConfigModule) addConfigPendingChanges(ctx context.Context) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok || module.manager == nil || module.manager.GetDB() == nil {
+		return
+	}
+	var userID *int64
+	if id, ok := config.GetValueAsInt64(ctx, config.UserContextKey); ok {
+		userID = &id
+	}
+	for _, update := range state.Updates {
+		if !update.Operation.IsConfigAltering() {
+			continue
+		}
+		daemonIDs := make(map[int64]bool)
+		for _, cmd := range update.Recipe.Commands {
+			if cmd.Daemon == nil || cmd.Daemon.ID == 0 || daemonIDs[cmd.Daemon.ID] {
+				continue
+			}
+			daemonIDs[cmd.Daemon.ID] = true
+			err := dbmodel.AddKeaConfigPendingChange(module.manager.GetDB(), &dbmodel.KeaConfigPendingChange{
+				DaemonID:  cmd.Daemon.ID,
+				Operation: update.Operation,
+				UserID:    userID,
+			})
+			if err != nil {
+				log.WithError(err).Warnf("Failed to remember the configuration change for daemon %d", cmd.Daemon.ID)
+			}
+		}
+	}
+}
+
 // Begins adding a new shared network. It initializes transaction state.
 func (module *ConfigModule) BeginSharedNetworkAdd(ctx context.Context) (context.Context, error) {
 	// Create transaction state.
@@ -664,12 +1012,21 @@ func (module *ConfigModule) ApplySharedNetworkAdd(ctx context.Context, sharedNet
 		return ctx, err
 	}
 
-	var commands []ConfigCommand
+	var (
+		commands  []ConfigCommand
+		cbDaemons []*dbmodel.Daemon
+	)
 	// Add the shared network instances.
 	for _, lsn := range sharedNetwork.LocalSharedNetworks {
 		if lsn.Daemon == nil {
 			return ctx, errors.Errorf("applied shared network %s is associated with nil daemon", sharedNetwork.Name)
 		}
+		// The shared network is added to the config backend once for all
+		// daemons sharing the database.
+		if isConfigBackendDaemon(lsn.Daemon) {
+			cbDaemons = append(cbDaemons, lsn.Daemon)
+			continue
+		}
 		// Convert the shared network information to Kea shared network.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
 		command := ConfigCommand{
@@ -693,9 +1050,19 @@ func (module *ConfigModule) ApplySharedNetworkAdd(ctx context.Context, sharedNet
 			commands = append(commands, command)
 		}
 	}
+	cbCommands, err := module.createConfigBackendSharedNetworkSetCommands(sharedNetwork, cbDaemons)
+	if err != nil {
+		return ctx, err
+	}
+	commands = append(commands, cbCommands...)
+
 	// Create the commands to write the updated configuration to files. The shared network
 	// changes won't persist across the servers' restarts otherwise.
 	for _, lsn := range sharedNetwork.LocalSharedNetworks {
+		if isConfigBackendDaemon(lsn.Daemon) {
+			// The changes are already stored in the database.
+			continue
+		}
 		commands = append(commands, ConfigCommand{
 			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, lsn.Daemon.Name),
 			Daemon:  lsn.Daemon,
@@ -719,6 +1086,39 @@ func (module *ConfigModule) ApplySharedNetworkAdd(ctx context.Context, sharedNet
 	return config.SetRecipeForUpdate(ctx, 0, recipe)
 }
 
+// Creates the remote-network4-set or remote-network6-set commands for the
+// daemons using the config backend. A single command is created for all
+// daemons sharing the same config backend. The command carries the server
+// tags of these daemons. The subnets are not included in the commands
+// because they are associated with the shared network by name.
+func (module *ConfigModule) createConfigBackendSharedNetworkSetCommands(sharedNetwork *dbmodel.SharedNetwork, daemons []*dbmodel.Daemon) (commands []ConfigCommand, err error) {
+	lookup := module.manager.GetDHCPOptionDefinitionLookup()
+	err = forEachUniqueConfigBackend(daemons, func(daemon *dbmodel.Daemon, serverTags []string) error {
+		command := ConfigCommand{
+			Daemon: daemon,
+		}
+		switch sharedNetwork.Family {
+		case 4:
+			sharedNetwork4, err := keaconfig.CreateSharedNetwork4(daemon.ID, lookup, sharedNetwork)
+			if err != nil {
+				return err
+			}
+			sharedNetwork4.Subnet4 = nil
+			command.Command = keactrl.NewCommandRemoteNetwork4Set(sharedNetwork4, serverTags, daemon.Name)
+		default:
+			sharedNetwork6, err := keaconfig.CreateSharedNetwork6(daemon.ID, lookup, sharedNetwork)
+			if err != nil {
+				return err
+			}
+			sharedNetwork6.Subnet6 = nil
+			command.Command = keactrl.NewCommandRemoteNetwork6Set(sharedNetwork6, serverTags, daemon.Name)
+		}
+		commands = append(commands, command)
+		return nil
+	})
+	return
+}
+
 // Create the shared network in the Kea servers.
 func (module *ConfigModule) commitSharedNetworkAdd(ctx context.Context) (context.Context, error) {
 	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
@@ -777,7 +1177,7 @@ func (module *ConfigModule) BeginSharedNetworkUpdate(ctx context.Context, shared
 		if ls.Daemon.KeaDaemon.Config == nil {
 			return ctx, errors.Errorf("configuration not found for daemon %d", ls.DaemonID)
 		}
-		if _, _, exists := ls.Daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_subnet_cmds"); !exists {
+		if ls.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary() == keaconfig.SubnetAndSharedNetworkAlteringHookLibraryNone {
 			return ctx, errors.WithStack(config.NewNoSubnetCmdsHookError())
 		}
 		daemonIDs = append(daemonIDs, ls.DaemonID)
@@ -818,7 +1218,10 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 		return ctx, errors.New("internal server error - shared network instance cannot be nil when committing shared network update")
 	}
 
-	var commands []ConfigCommand
+	var (
+		commands  []ConfigCommand
+		cbDaemons []*dbmodel.Daemon
+	)
 
 	// Temporarily remove the subnets from the shared network to avoid including
 	// them in the networkX-add commands. The subnets will be added back by
@@ -831,6 +1234,12 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 		if lsn.Daemon == nil {
 			return ctx, errors.Errorf("applied shared network %s is associated with nil daemon", sharedNetwork.Name)
 		}
+		// The shared network in the config backend is replaced with a single
+		// command. The subnets remain associated with it by name.
+		if isConfigBackendDaemon(lsn.Daemon) {
+			cbDaemons = append(cbDaemons, lsn.Daemon)
+			continue
+		}
 		// Convert the updated shared network information to Kea shared network.
 		lookup := module.manager.GetDHCPOptionDefinitionLookup()
 		command := ConfigCommand{}
@@ -895,12 +1304,21 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 		}
 	}
 
+	cbCommands, err := module.createConfigBackendSharedNetworkSetCommands(sharedNetwork, cbDaemons)
+	if err != nil {
+		return ctx, err
+	}
+	commands = append(commands, cbCommands...)
+
 	// Restore the subnets in the shared network.
 	sharedNetwork.Subnets = subnets
 
 	// Identify the daemons which no longer exist in the updated shared network.
 	// Remove the shared network from these daemons.
-	var deletedLocalSharedNetworks []*dbmodel.LocalSharedNetwork
+	var (
+		deletedLocalSharedNetworks []*dbmodel.LocalSharedNetwork
+		deletedCBDaemons           []*dbmodel.Daemon
+	)
 	for i, exln := range existingSharedNetwork.LocalSharedNetworks {
 		deletedLocalSharedNetwork := existingSharedNetwork.LocalSharedNetworks[i]
 		for _, ln := range sharedNetwork.LocalSharedNetworks {
@@ -910,6 +1328,10 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 				break
 			}
 		}
+		if deletedLocalSharedNetwork != nil && isConfigBackendDaemon(deletedLocalSharedNetwork.Daemon) {
+			deletedCBDaemons = append(deletedCBDaemons, deletedLocalSharedNetwork.Daemon)
+			continue
+		}
 		if deletedLocalSharedNetwork != nil {
 			command := ConfigCommand{}
 			deletedKeaSharedNetwork := keaconfig.CreateSubnetCmdsDeletedSharedNetwork(deletedLocalSharedNetwork.DaemonID, existingSharedNetwork, keaconfig.SharedNetworkSubnetsActionDelete)
@@ -923,9 +1345,36 @@ func (module *ConfigModule) ApplySharedNetworkUpdate(ctx context.Context, shared
 			deletedLocalSharedNetworks = append(deletedLocalSharedNetworks, deletedLocalSharedNetwork)
 		}
 	}
+	// Delete the shared network from the config backends no longer used by
+	// any of the daemons associated with the shared network. In the config
+	// backends still in use, the remote-network*-set command has replaced
+	// the server tags.
+	if err = forEachUniqueConfigBackend(deletedCBDaemons, func(daemon *dbmodel.Daemon, _ []string) error {
+		deletedKey, err := buildConfigTargetKey(daemon)
+		if err != nil {
+			return err
+		}
+		for _, cbDaemon := range cbDaemons {
+			if key, err := buildConfigTargetKey(cbDaemon); err == nil && key == deletedKey {
+				return nil
+			}
+		}
+		commands = append(commands, ConfigCommand{
+			Command: keactrl.NewCommandRemoteNetworkDel(existingSharedNetwork.Name, keaconfig.SharedNetworkSubnetsActionDelete, daemon.Name),
+			Daemon:  daemon,
+		})
+		return nil
+	}); err != nil {
+		return ctx, err
+	}
+
 	// Create the commands to write the updated configuration to files. The shared network
 	// changes won't persist across the servers' restarts otherwise.
 	for _, lsn := range append(sharedNetwork.LocalSharedNetworks, deletedLocalSharedNetworks...) {
+		if isConfigBackendDaemon(lsn.Daemon) {
+			// The changes are already stored in the database.
+			continue
+		}
 		commands = append(commands, ConfigCommand{
 			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, lsn.Daemon.Name),
 			Daemon:  lsn.Daemon,
@@ -993,11 +1442,20 @@ func (module *ConfigModule) ApplySharedNetworkDelete(ctx context.Context, shared
 	if len(sharedNetwork.LocalSharedNetworks) == 0 {
 		return ctx, errors.Errorf("deleted shared network %d is not associated with any daemon", sharedNetwork.ID)
 	}
-	var commands []ConfigCommand
+	var (
+		commands  []ConfigCommand
+		cbDaemons []*dbmodel.Daemon
+	)
 	for _, lsn := range sharedNetwork.LocalSharedNetworks {
 		if lsn.Daemon == nil {
 			return ctx, errors.Errorf("deleted shared network %d is associated with nil daemon", sharedNetwork.ID)
 		}
+		// The shared network is deleted from the config backend once for
+		// all daemons sharing the database.
+		if isConfigBackendDaemon(lsn.Daemon) {
+			cbDaemons = append(cbDaemons, lsn.Daemon)
+			continue
+		}
 		// Convert the shared network information to Kea shared network.
 		deletedSharedNetwork := keaconfig.CreateSubnetCmdsDeletedSharedNetwork(lsn.DaemonID, sharedNetwork, keaconfig.SharedNetworkSubnetsActionDelete)
 
@@ -1014,8 +1472,19 @@ func (module *ConfigModule) ApplySharedNetworkDelete(ctx context.Context, shared
 		command.Daemon = lsn.Daemon
 		commands = append(commands, command)
 	}
+	_ = forEachUniqueConfigBackend(cbDaemons, func(daemon *dbmodel.Daemon, _ []string) error {
+		commands = append(commands, ConfigCommand{
+			Command: keactrl.NewCommandRemoteNetworkDel(sharedNetwork.Name, keaconfig.SharedNetworkSubnetsActionDelete, daemon.Name),
+			Daemon:  daemon,
+		})
+		return nil
+	})
 	// Persist the configuration changes.
 	for _, ls := range sharedNetwork.LocalSharedNetworks {
+		if isConfigBackendDaemon(ls.Daemon) {
+			// The changes are already stored in the database.
+			continue
+		}
 		commands = append(commands, ConfigCommand{
 			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, ls.Daemon.Name),
 			Daemon:  ls.Daemon,
@@ -1092,7 +1561,7 @@ func (module *ConfigModule) ApplySubnetAdd(ctx context.Context, subnet *dbmodel.
 	lookup := module.manager.GetDHCPOptionDefinitionLookup()
 	// Validate that every daemon has at least one supported hook library.
 	for _, ls := range subnet.LocalSubnets {
-		if hook := ls.Daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary(); hook == keaconfig.SubnetAndSharedNetworkAlteringHookLibraryNone {
+		if hook := ls.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary(); hook == keaconfig.SubnetAndSharedNetworkAlteringHookLibraryNone {
 			return ctx, errors.New("daemon lacks a supported hook library")
 		}
 	}
@@ -1194,7 +1663,7 @@ func (module *ConfigModule) BeginSubnetUpdate(ctx context.Context, subnetID int6
 		if ls.Daemon.KeaDaemon.Config == nil {
 			return ctx, errors.Errorf("configuration not found for daemon %d", ls.DaemonID)
 		}
-		if hook := ls.Daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary(); hook == keaconfig.SubnetAndSharedNetworkAlteringHookLibraryNone {
+		if hook := ls.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary(); hook == keaconfig.SubnetAndSharedNetworkAlteringHookLibraryNone {
 			return ctx, errors.WithStack(config.NewNoSubnetCmdsHookError())
 		}
 		daemonIDs = append(daemonIDs, ls.DaemonID)
@@ -1477,3 +1946,652 @@ func (module *ConfigModule) commitSubnetDelete(ctx context.Context) (context.Con
 	}
 	return ctx, nil
 }
+
+// Checks if the daemon can receive the lease_cmds hook library commands.
+// It returns an error if the daemon configuration is not available or
+// when the libdhcp_lease_cmds hook library is not configured.
+func checkLeaseCmdsDaemon(daemon *dbmodel.Daemon) error {
+	if daemon == nil {
+		return errors.New("lease is associated with nil daemon")
+	}
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return errors.Errorf("configuration not found for daemon %d", daemon.ID)
+	}
+	if _, _, exists := daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_lease_cmds"); !exists {
+		return errors.WithStack(config.NewNoLeaseCmdsHookError())
+	}
+	return nil
+}
+
+// Converts the lease from the Stork database to the lease sent to Kea in
+// the lease4-add, lease6-add, lease4-update and lease6-update commands.
+func createLeaseCmdsLease(lease *dbmodel.Lease) *keaconfig.LeaseCmdsLease {
+	leaseCmdsLease := &keaconfig.LeaseCmdsLease{
+		IPAddress:     lease.IPAddress,
+		SubnetID:      int64(lease.LocalSubnetID),
+		HWAddress:     lease.HWAddress,
+		ValidLifetime: storkutil.Ptr(int64(lease.ValidLifetime)),
+		Hostname:      lease.Hostname,
+		FqdnFwd:       lease.FqdnFwd,
+		FqdnRev:       lease.FqdnRev,
+		State:         storkutil.Ptr(int64(lease.State)),
+		UserContext:   lease.UserContext,
+	}
+	if lease.ClientID != nil {
+		leaseCmdsLease.ClientID = lease.ClientID.String()
+	}
+	if lease.Daemon.Name == daemonname.DHCPv6 {
+		if lease.DUID != nil {
+			leaseCmdsLease.DUID = lease.DUID.String()
+		}
+		leaseCmdsLease.IAID = storkutil.Ptr(int64(lease.IAID))
+		leaseCmdsLease.Type = lease.Type
+		leaseCmdsLease.PrefixLength = int64(lease.PrefixLength)
+		leaseCmdsLease.PreferredLifetime = storkutil.Ptr(int64(lease.PreferredLifetime))
+	}
+	return leaseCmdsLease
+}
+
+// Applies new lease. It prepares the lease4-add or lease6-add command to
+// be sent to Kea upon commit.
+func (module *ConfigModule) ApplyLeaseAdd(ctx context.Context, lease *dbmodel.Lease) (context.Context, error) {
+	if err := checkLeaseCmdsDaemon(lease.Daemon); err != nil {
+		return ctx, err
+	}
+	var command *keactrl.Command
+	switch lease.Daemon.Name {
+	case daemonname.DHCPv4:
+		command = keactrl.NewCommandLease4Add(createLeaseCmdsLease(lease))
+	case daemonname.DHCPv6:
+		command = keactrl.NewCommandLease6Add(createLeaseCmdsLease(lease))
+	default:
+		return ctx, errors.Errorf("unable to add a lease to the non-DHCP daemon %d", lease.DaemonID)
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaLeaseAdd, lease.DaemonID)
+	recipe := ConfigRecipe{
+		Commands: []ConfigCommand{
+			{
+				Command: command,
+				Daemon:  lease.Daemon,
+			},
+		},
+		LeaseConfigRecipeParams: LeaseConfigRecipeParams{
+			LeaseAfterUpdate: lease,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Creates the lease in the Kea server and in the Stork database.
+func (module *ConfigModule) commitLeaseAdd(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.LeaseAfterUpdate == nil {
+			return ctx, errors.New("server logic error: the update.Recipe.LeaseAfterUpdate cannot be nil when committing lease creation")
+		}
+		err = dbmodel.AddLease(module.manager.GetDB(), update.Recipe.LeaseAfterUpdate)
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "lease has been successfully added to Kea but adding to the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Applies updated lease. It prepares the lease4-update or lease6-update
+// command to be sent to Kea upon commit. If forceCreate is true, Kea creates
+// the lease when it does not exist.
+func (module *ConfigModule) ApplyLeaseUpdate(ctx context.Context, lease *dbmodel.Lease, forceCreate bool) (context.Context, error) {
+	if err := checkLeaseCmdsDaemon(lease.Daemon); err != nil {
+		return ctx, err
+	}
+	leaseCmdsLease := createLeaseCmdsLease(lease)
+	leaseCmdsLease.ForceCreate = forceCreate
+	var command *keactrl.Command
+	switch lease.Daemon.Name {
+	case daemonname.DHCPv4:
+		command = keactrl.NewCommandLease4Update(leaseCmdsLease)
+	case daemonname.DHCPv6:
+		command = keactrl.NewCommandLease6Update(leaseCmdsLease)
+	default:
+		return ctx, errors.Errorf("unable to update a lease in the non-DHCP daemon %d", lease.DaemonID)
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaLeaseUpdate, lease.DaemonID)
+	recipe := ConfigRecipe{
+		Commands: []ConfigCommand{
+			{
+				Command: command,
+				Daemon:  lease.Daemon,
+			},
+		},
+		LeaseConfigRecipeParams: LeaseConfigRecipeParams{
+			LeaseAfterUpdate: lease,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Updates the lease in the Kea server and in the Stork database.
+func (module *ConfigModule) commitLeaseUpdate(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.LeaseAfterUpdate == nil {
+			return ctx, errors.New("server logic error: the update.Recipe.LeaseAfterUpdate cannot be nil when committing the lease update")
+		}
+		// The lease is inserted or updated if it already exists.
+		err = dbmodel.AddLease(module.manager.GetDB(), update.Recipe.LeaseAfterUpdate)
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "lease has been successfully updated in Kea but updating it in the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Creates a request to delete a lease. It prepares the lease4-del or
+// lease6-del command to be sent to Kea upon commit.
+func (module *ConfigModule) ApplyLeaseDelete(ctx context.Context, lease *dbmodel.Lease) (context.Context, error) {
+	if err := checkLeaseCmdsDaemon(lease.Daemon); err != nil {
+		return ctx, err
+	}
+	var command *keactrl.Command
+	switch lease.Daemon.Name {
+	case daemonname.DHCPv4:
+		command = keactrl.NewCommandLease4Del(lease.IPAddress)
+	case daemonname.DHCPv6:
+		leaseType := keactrl.LeaseTypeNA
+		if lease.Type == string(keactrl.LeaseTypePD) {
+			leaseType = keactrl.LeaseTypePD
+		}
+		command = keactrl.NewCommandLease6Del(leaseType, lease.IPAddress)
+	default:
+		return ctx, errors.Errorf("unable to delete a lease from the non-DHCP daemon %d", lease.DaemonID)
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaLeaseDelete, lease.DaemonID)
+	recipe := ConfigRecipe{
+		Commands: []ConfigCommand{
+			{
+				Command: command,
+				Daemon:  lease.Daemon,
+			},
+		},
+		LeaseConfigRecipeParams: LeaseConfigRecipeParams{
+			DeletedLease: lease,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Deletes the lease from the Kea server and from the Stork database.
+func (module *ConfigModule) commitLeaseDelete(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		lease := update.Recipe.DeletedLease
+		if lease == nil {
+			return ctx, errors.New("server logic error: the update.Recipe.DeletedLease cannot be nil when committing lease deletion")
+		}
+		// The lease may not be stored in the Stork database. It is not
+		// an error.
+		err = dbmodel.DeleteLease(module.manager.GetDB(), lease.DaemonID, lease.IPAddress)
+		if err != nil && !errors.Is(err, dbmodel.ErrNotExists) {
+			return ctx, errors.WithMessagef(err, "lease has been successfully deleted in Kea but deleting in the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Creates a request to wipe the leases from the specified subnet or from
+// all subnets when the local subnet ID is 0. It prepares the lease4-wipe
+// or lease6-wipe command to be sent to Kea upon commit.
+func (module *ConfigModule) ApplyLeaseWipe(ctx context.Context, daemon *dbmodel.Daemon, localSubnetID int64) (context.Context, error) {
+	if err := checkLeaseCmdsDaemon(daemon); err != nil {
+		return ctx, err
+	}
+	var command *keactrl.Command
+	switch daemon.Name {
+	case daemonname.DHCPv4:
+		command = keactrl.NewCommandLease4Wipe(localSubnetID)
+	case daemonname.DHCPv6:
+		command = keactrl.NewCommandLease6Wipe(localSubnetID)
+	default:
+		return ctx, errors.Errorf("unable to wipe leases from the non-DHCP daemon %d", daemon.ID)
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaLeaseWipe, daemon.ID)
+	recipe := ConfigRecipe{
+		Commands: []ConfigCommand{
+			{
+				Command: command,
+				Daemon:  daemon,
+			},
+		},
+		LeaseConfigRecipeParams: LeaseConfigRecipeParams{
+			WipedLeasesDaemonID:      &daemon.ID,
+			WipedLeasesLocalSubnetID: &localSubnetID,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Wipes the leases from the Kea server and from the Stork database.
+func (module *ConfigModule) commitLeaseWipe(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.WipedLeasesDaemonID == nil || update.Recipe.WipedLeasesLocalSubnetID == nil {
+			return ctx, errors.New("server logic error: the daemon ID and local subnet ID cannot be nil when committing leases wipe")
+		}
+		_, err = dbmodel.DeleteLeasesByLocalSubnetID(module.manager.GetDB(), *update.Recipe.WipedLeasesDaemonID, *update.Recipe.WipedLeasesLocalSubnetID)
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "leases have been successfully wiped in Kea but deleting them in the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Checks if the daemon can receive the class_cmds hook library commands.
+// It returns an error if the daemon configuration is not available or
+// when the libdhcp_class_cmds hook library is not configured.
+func checkClassCmdsDaemon(daemon *dbmodel.Daemon) error {
+	if daemon == nil {
+		return errors.New("client class is associated with nil daemon")
+	}
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return errors.Errorf("configuration not found for daemon %d", daemon.ID)
+	}
+	// The client classes of a daemon using the config backend are managed
+	// with the remote-class* commands.
+	if isConfigBackendDaemon(daemon) {
+		return nil
+	}
+	if _, _, exists := daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_class_cmds"); !exists {
+		return errors.WithStack(config.NewNoClassCmdsHookError())
+	}
+	return nil
+}
+
+// Creates the commands applying the client class change in the daemon. The
+// specified command is followed by the config-write command. The client
+// class changes won't persist across the server's restarts otherwise. The
+// config-write command is not sent to the daemons using the config backend
+// because the changes are stored in the database.
+func createClientClassCommands(command *keactrl.Command, daemon *dbmodel.Daemon) []ConfigCommand {
+	commands := []ConfigCommand{
+		{
+			Command: command,
+			Daemon:  daemon,
+		},
+	}
+	if !isConfigBackendDaemon(daemon) {
+		commands = append(commands, ConfigCommand{
+			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, daemon.Name),
+			Daemon:  daemon,
+		})
+	}
+	return commands
+}
+
+// Creates the command adding or updating the client class in the daemon.
+// It is the remote-class4-set or remote-class6-set command for the daemons
+// using the config backend. Otherwise, it is the class-add or class-update
+// command.
+func createClientClassSetCommand(class *dbmodel.ClientClass, update bool) *keactrl.Command {
+	switch {
+	case isConfigBackendDaemon(class.Daemon):
+		return keactrl.NewCommandRemoteClassSet(class.KeaParameters, []string{getConfigBackendServerTag(class.Daemon)}, class.Daemon.Name)
+	case update:
+		return keactrl.NewCommandClassUpdate(class.KeaParameters, class.Daemon.Name)
+	default:
+		return keactrl.NewCommandClassAdd(class.KeaParameters, class.Daemon.Name)
+	}
+}
+
+// Creates the command deleting the client class from the daemon.
+func createClientClassDelCommand(class *dbmodel.ClientClass) *keactrl.Command {
+	if isConfigBackendDaemon(class.Daemon) {
+		return keactrl.NewCommandRemoteClassDel(class.Name, class.Daemon.Name)
+	}
+	return keactrl.NewCommandClassDel(class.Name, class.Daemon.Name)
+}
+
+// Begins adding a new client class. It initializes transaction state.
+func (module *ConfigModule) BeginClientClassAdd(ctx context.Context) (context.Context, error) {
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaClientClassAdd)
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies new client class. It prepares the class-add command to be sent
+// to Kea upon commit.
+func (module *ConfigModule) ApplyClientClassAdd(ctx context.Context, class *dbmodel.ClientClass) (context.Context, error) {
+	if err := checkClassCmdsDaemon(class.Daemon); err != nil {
+		return ctx, err
+	}
+	if class.KeaParameters == nil {
+		return ctx, errors.Errorf("applied client class %s has no definition", class.Name)
+	}
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	class.DaemonID = class.Daemon.ID
+	class.KeaParameters.Name = class.Name
+
+	// Store the data in the existing recipe.
+	recipe.ClientClassAfterUpdate = class
+	recipe.Commands = createClientClassCommands(createClientClassSetCommand(class, false), class.Daemon)
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Creates the client class in the Kea server and in the Stork database.
+func (module *ConfigModule) commitClientClassAdd(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for i, update := range state.Updates {
+		class := update.Recipe.ClientClassAfterUpdate
+		if class == nil {
+			return ctx, errors.New("server logic error: the update.Recipe.ClientClassAfterUpdate cannot be nil when committing the client class")
+		}
+		err = dbmodel.AddClientClass(module.manager.GetDB(), class)
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "client class has been successfully created in Kea but creating it in the Stork database failed")
+		}
+		recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, i)
+		if err != nil {
+			return ctx, err
+		}
+		recipe.ClientClassID = storkutil.Ptr(class.ID)
+		if ctx, err = config.SetRecipeForUpdate(ctx, i, recipe); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// Begins a client class update. It fetches the specified client class from
+// the database and stores it in the context state. Then, it locks the daemon
+// owning the class for updates.
+func (module *ConfigModule) BeginClientClassUpdate(ctx context.Context, classID int64) (context.Context, error) {
+	// Try to get the client class to be updated from the database.
+	class, err := dbmodel.GetClientClassByID(module.manager.GetDB(), classID)
+	if err != nil {
+		// Internal database error.
+		return ctx, err
+	}
+	// Client class does not exist.
+	if class == nil {
+		return ctx, errors.WithStack(config.NewClientClassNotFoundError(classID))
+	}
+	if err = checkClassCmdsDaemon(class.Daemon); err != nil {
+		return ctx, err
+	}
+	// Try to lock the daemon configuration.
+	ctx, err = module.manager.Lock(ctx, class.DaemonID)
+	if err != nil {
+		return ctx, errors.WithStack(config.NewLockError())
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaClientClassUpdate, class.DaemonID)
+	recipe := &ConfigRecipe{
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassBeforeUpdate: class,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Applies updated client class. It prepares the class-update command to be
+// sent to Kea upon commit. The class-update command identifies the class by
+// name. Therefore, renaming the class is not supported.
+func (module *ConfigModule) ApplyClientClassUpdate(ctx context.Context, class *dbmodel.ClientClass) (context.Context, error) {
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	existingClass := recipe.ClientClassBeforeUpdate
+	if existingClass == nil {
+		return ctx, errors.New("internal server error - client class instance cannot be nil when committing client class update")
+	}
+	if class.Name != existingClass.Name {
+		return ctx, errors.Errorf("renaming client class %s to %s is not supported", existingClass.Name, class.Name)
+	}
+	if class.KeaParameters == nil {
+		return ctx, errors.Errorf("applied client class %s has no definition", class.Name)
+	}
+	// The class always belongs to the same daemon.
+	class.ID = existingClass.ID
+	class.DaemonID = existingClass.DaemonID
+	class.Daemon = existingClass.Daemon
+	class.KeaParameters.Name = class.Name
+
+	// Preserve the parameters not supported by Stork (e.g., option-def).
+	// Otherwise, the class-update command would remove them from the server.
+	if class.KeaParameters.UnknownParameters == nil && existingClass.KeaParameters != nil {
+		class.KeaParameters.UnknownParameters = existingClass.KeaParameters.UnknownParameters
+	}
+
+	// Store the data in the existing recipe.
+	recipe.ClientClassAfterUpdate = class
+	recipe.Commands = createClientClassCommands(createClientClassSetCommand(class, true), class.Daemon)
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Updates the client class in the Kea server and in the Stork database.
+func (module *ConfigModule) commitClientClassUpdate(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.ClientClassAfterUpdate == nil {
+			return ctx, errors.New("server logic error: the update.Recipe.ClientClassAfterUpdate cannot be nil when committing the client class update")
+		}
+		err = dbmodel.AddClientClass(module.manager.GetDB(), update.Recipe.ClientClassAfterUpdate)
+		if err != nil {
+			return ctx, errors.WithMessagef(err, "client class has been successfully updated in Kea but updating it in the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Creates a request to delete a client class. It prepares the class-del
+// command to be sent to Kea upon commit.
+func (module *ConfigModule) ApplyClientClassDelete(ctx context.Context, class *dbmodel.ClientClass) (context.Context, error) {
+	if err := checkClassCmdsDaemon(class.Daemon); err != nil {
+		return ctx, err
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaClientClassDelete, class.DaemonID)
+	recipe := ConfigRecipe{
+		Commands: createClientClassCommands(createClientClassDelCommand(class), class.Daemon),
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassID:          &class.ID,
+			DeletedClientClassName: &class.Name,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, &recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Deletes the client class from the Kea server and from the Stork database.
+func (module *ConfigModule) commitClientClassDelete(ctx context.Context) (context.Context, error) {
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	if !ok {
+		return ctx, errors.New("context lacks state")
+	}
+	var err error
+	ctx, err = module.commitChanges(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for _, update := range state.Updates {
+		if update.Recipe.ClientClassID == nil {
+			return ctx, errors.New("server logic error: the client class ID cannot be nil when committing client class deletion")
+		}
+		// The class may have been already removed from the Stork database
+		// by the config puller. It is not an error.
+		err = dbmodel.DeleteClientClass(module.manager.GetDB(), *update.Recipe.ClientClassID)
+		if err != nil && !errors.Is(err, dbmodel.ErrNotExists) {
+			return ctx, errors.WithMessagef(err, "client class has been successfully deleted in Kea but deleting in the Stork database failed")
+		}
+	}
+	return ctx, nil
+}
+
+// Begins rolling back the daemon's configuration to one of its previous
+// versions. It locks the daemon's configuration and initializes the
+// transaction state.
+func (module *ConfigModule) BeginConfigRollback(ctx context.Context, daemonID int64) (context.Context, error) {
+	daemon, err := dbmodel.GetKeaDaemonByID(module.manager.GetDB(), daemonID)
+	if err != nil {
+		// Internal database error.
+		return ctx, err
+	}
+	if daemon == nil {
+		return ctx, errors.WithStack(config.NewSomeDaemonsNotFoundError(daemonID))
+	}
+	// The stored configuration of a daemon using the config backend includes
+	// the subnets, shared networks and options fetched from the database.
+	// Setting and writing it would copy them to the configuration file.
+	if isConfigBackendDaemon(daemon) {
+		return ctx, errors.WithStack(config.NewConfigBackendRollbackError(daemonID))
+	}
+	// Try to lock the configuration.
+	ctx, err = module.manager.Lock(ctx, daemonID)
+	if err != nil {
+		return ctx, errors.WithStack(config.NewLockError())
+	}
+	// Create transaction state.
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaConfigRollback, daemonID)
+	recipe := &ConfigRecipe{
+		ConfigRollbackRecipeParams: ConfigRollbackRecipeParams{
+			RollbackDaemon: daemon,
+		},
+	}
+	if err := state.SetRecipeForUpdate(0, recipe); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, *state)
+	return ctx, nil
+}
+
+// Prepares the config-set and config-write commands pushing the specified
+// configuration version to the daemon.
+func (module *ConfigModule) ApplyConfigRollback(ctx context.Context, version *dbmodel.KeaConfigVersion) (context.Context, error) {
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	if err != nil {
+		return ctx, err
+	}
+	daemon := recipe.RollbackDaemon
+	if daemon == nil {
+		return ctx, errors.New("internal server error - the daemon cannot be nil when applying the configuration rollback")
+	}
+	if version.DaemonID != daemon.ID {
+		return ctx, errors.Errorf("configuration version %d does not belong to daemon %d", version.Version, daemon.ID)
+	}
+	if version.Config == nil {
+		return ctx, errors.Errorf("configuration version %d of daemon %d lacks the configuration", version.Version, daemon.ID)
+	}
+	rawConfig, err := version.Config.GetRawConfig()
+	if err != nil {
+		return ctx, err
+	}
+	// The hash is returned by Kea in the config-get response but it is
+	// not a part of the configuration.
+	setConfig := make(keaconfig.RawConfig)
+	for key, value := range rawConfig {
+		if key != "hash" {
+			setConfig[key] = value
+		}
+	}
+	configToSet, err := keaconfig.NewConfigFromMap(setConfig)
+	if err != nil {
+		return ctx, err
+	}
+	recipe.RollbackConfigVersion = &version.Version
+	recipe.Commands = []ConfigCommand{
+		{
+			Command: keactrl.NewCommandConfigSet(configToSet, daemon.Name),
+			Daemon:  daemon,
+		},
+		{
+			Command: keactrl.NewCommandBase(keactrl.ConfigWrite, daemon.Name),
+			Daemon:  daemon,
+		},
+	}
+	return config.SetRecipeForUpdate(ctx, 0, recipe)
+}
+
+// Pushes the previous configuration version to the Kea server. The new
+// configuration is stored in the Stork database by the config puller.
+func (module *ConfigModule) commitConfigRollback(ctx context.Context) (context.Context, error) {
+	return module.commitChanges(ctx)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
+	keadata "isc.org/stork/daemondata/kea"
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/datamodel/protocoltype"
 	"isc.org/stork/server/agentcomm"
@@ -22,6 +23,7 @@ import (
 	dbmodel "isc.org/stork/server/database/model"
 	dbmodeltest "isc.org/stork/server/database/model/test"
 	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/eventcenter"
 	storktest "isc.org/stork/server/test/dbmodel"
 	storkutil "isc.org/stork/util"
 )
@@ -33,6 +35,7 @@ type testManager struct {
 	agents       agentcomm.ConnectedAgents
 	lookup       keaconfig.DHCPOptionDefinitionLookup
 	daemonLocker config.DaemonLocker
+	eventCenter  eventcenter.EventCenter
 
 	locks map[int64]bool
 }
@@ -45,6 +48,7 @@ func newTestManager(server config.ManagerAccessors) *testManager {
 		lookup:       server.GetDHCPOptionDefinitionLookup(),
 		locks:        make(map[int64]bool),
 		daemonLocker: server.GetDaemonLocker(),
+		eventCenter:  server.GetEventCenter(),
 	}
 }
 
@@ -70,6 +74,11 @@ func (tm *testManager) GetDaemonLocker() config.DaemonLocker {
 	return tm.daemonLocker
 }
 
+// Returns an interface to the event center.
+func (tm *testManager) GetEventCenter() eventcenter.EventCenter {
+	return tm.eventCenter
+}
+
 // Applies locks on specified daemons.
 func (tm *testManager) Lock(ctx context.Context, daemonIDs ...int64) (context.Context, error) {
 	for _, id := range daemonIDs {
@@ -346,6 +355,131 @@ func TestApplyGlobalParametersUpdate(t *testing.T) {
 }
 
 // Test committing global configuration parameters, i.e. actually sending control
+// Test that the global parameters and options of a daemon using the config
+// backend are updated with the remote-* commands instead of config-set.
+func TestApplyGlobalParametersUpdateToConfigBackend(t *testing.T) {
+	daemonConfig, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 3000,
+			"allocator": "random",
+			"server-tag": "server1",
+			"option-data": [
+				{ "code": 6, "data": "192.0.2.1" },
+				{ "code": 15, "data": "example.org" }
+			],
+			"config-control": {
+				"config-databases": [
+					{ "type": "mysql", "name": "keatest", "host": "localhost" }
+				]
+			},
+			"hooks-libraries": [
+				{ "library": "libdhcp_cb_cmds.so" }
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	daemons := []dbmodel.Daemon{
+		{
+			ID:   1,
+			Name: daemonname.DHCPv4,
+			KeaDaemon: &dbmodel.KeaDaemon{
+				ServerTag: storkutil.Ptr("server1"),
+				Config:    &dbmodel.KeaConfig{Config: daemonConfig},
+			},
+		},
+	}
+
+	module := NewConfigModule(newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	}))
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaGlobalParametersUpdate, 1)
+	err = state.SetRecipeForUpdate(0, &ConfigRecipe{
+		GlobalConfigRecipeParams: GlobalConfigRecipeParams{
+			KeaDaemonsBeforeConfigUpdate: daemons,
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	settableConfig := keaconfig.NewSettableDHCPv4Config()
+	require.NoError(t, settableConfig.SetValidLifetime(storkutil.Ptr(int64(1111))))
+	require.NoError(t, settableConfig.SetAllocator(nil))
+	require.NoError(t, settableConfig.SetDHCPOptions([]keaconfig.SingleOptionData{
+		{
+			SingleOptionDataKnownParameters: keaconfig.SingleOptionDataKnownParameters{
+				Code: 6,
+				Data: "192.0.2.2",
+			},
+		},
+	}))
+
+	ctx, err = module.ApplyGlobalParametersUpdate(ctx, []config.AnnotatedEntity[*keaconfig.SettableConfig]{
+		*config.NewAnnotatedEntity(1, settableConfig),
+	})
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	commands := returnedState.Updates[0].Recipe.Commands
+	require.Len(t, commands, 4)
+
+	marshalled, err := commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"parameters": { "valid-lifetime": 1111 },
+			"server-tags": [ "server1" ]
+		}
+	}`, string(marshalled))
+
+	marshalled, err = commands[1].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-global-parameter4-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"parameters": [ "allocator" ],
+			"server-tags": [ "server1" ]
+		}
+	}`, string(marshalled))
+
+	// The option 15 no longer exists in the configuration.
+	marshalled, err = commands[2].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-option4-global-del",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"options": [ { "code": 15, "space": "dhcp4" } ],
+			"server-tags": [ "server1" ]
+		}
+	}`, string(marshalled))
+
+	require.Equal(t, keactrl.RemoteOption4GlobalSet, commands[3].Command.Command)
+}
+
+// Test that an error is returned when the list parameter other than the
+// option-data is set in the config backend.
+func TestFlattenConfigBackendGlobalParameter(t *testing.T) {
+	setParameters := make(map[string]any)
+	var delParameters []string
+
+	err := flattenConfigBackendGlobalParameter("expired-leases-processing", map[string]any{
+		"max-reclaim-time":    float64(100),
+		"hold-reclaimed-time": nil,
+	}, setParameters, &delParameters)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"expired-leases-processing.max-reclaim-time": float64(100)}, setParameters)
+	require.Equal(t, []string{"expired-leases-processing.hold-reclaimed-time"}, delParameters)
+
+	err = flattenConfigBackendGlobalParameter("interfaces", []any{"eth0"}, setParameters, &delParameters)
+	require.ErrorContains(t, err, "parameter interfaces cannot be set in the configuration backend")
+}
+
 // commands to Kea.
 func TestCommitGlobalParametersUpdate(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -910,6 +1044,173 @@ func TestCommitScheduledHostAdd(t *testing.T) {
 	require.NotNil(t, newHost)
 }
 
+// Returns a host reservation to be imported. The host is associated with
+// the specified daemon.
+func newTestImportedHost(daemonID int64, address string, hwAddress []byte) *dbmodel.Host {
+	return &dbmodel.Host{
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: hwAddress,
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID: daemonID,
+				Daemon: &dbmodel.Daemon{
+					Name: daemonname.DHCPv4,
+					AccessPoints: []*dbmodel.AccessPoint{
+						{
+							Type:     dbmodel.AccessPointControl,
+							Address:  "192.0.2.1",
+							Port:     1234,
+							Protocol: protocoltype.HTTP,
+						},
+					},
+				},
+				DataSource: dbmodel.HostDataSourceAPI,
+				IPReservations: []dbmodel.IPReservation{
+					{
+						Address: address,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Test first stage of importing hosts.
+func TestBeginHostImport(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginHostImport(context.Background())
+	require.NoError(t, err)
+
+	// There should be no locks on any daemons.
+	require.Empty(t, manager.locks)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, dbmodel.ConfigOperationKeaHostImport, state.Updates[0].Operation)
+}
+
+// Test second stage of importing hosts.
+func TestApplyHostImport(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginHostImport(context.Background())
+	require.NoError(t, err)
+
+	hosts := []*dbmodel.Host{
+		newTestImportedHost(1, "192.0.2.10", []byte{1, 2, 3, 4, 5, 6}),
+		newTestImportedHost(1, "192.0.2.11", []byte{1, 2, 3, 4, 5, 7}),
+	}
+	ctx, err = module.ApplyHostImport(ctx, hosts)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	recipe := state.Updates[0].Recipe
+	require.Len(t, recipe.ImportedHosts, 2)
+	require.Len(t, recipe.Commands, 2)
+
+	marshalled, err := recipe.Commands[1].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+             "command": "reservation-add",
+             "service": [ "dhcp4" ],
+             "arguments": {
+                 "reservation": {
+                     "subnet-id": 0,
+                     "hw-address": "010203040507",
+                     "ip-address": "192.0.2.11"
+                 }
+             }
+         }`,
+		string(marshalled))
+
+	// No hosts to import.
+	_, err = module.ApplyHostImport(ctx, []*dbmodel.Host{})
+	require.ErrorContains(t, err, "no hosts to import")
+}
+
+// Test that the imported hosts are sent to Kea and stored in the database,
+// and that the failure to add one host doesn't prevent adding other hosts.
+func TestCommitHostImport(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, daemons := storktest.AddTestHosts(t, db)
+
+	// The second command fails.
+	agents := agentcommtest.NewKeaFakeAgents(func(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []interface{}) {
+		result := 0
+		if callNo == 1 {
+			result = 1
+		}
+		bytes := []byte(fmt.Sprintf(`{"result": %d, "text": "error is error"}`, result))
+		response := &keactrl.ResponseHeader{}
+		_ = json.Unmarshal(bytes, response)
+		cmdResponses[0] = response
+	})
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginHostImport(context.Background())
+	require.NoError(t, err)
+
+	hosts := []*dbmodel.Host{
+		newTestImportedHost(daemons[0].ID, "192.0.2.10", []byte{1, 2, 3, 4, 5, 6}),
+		newTestImportedHost(daemons[0].ID, "192.0.2.11", []byte{1, 2, 3, 4, 5, 7}),
+		newTestImportedHost(daemons[0].ID, "192.0.2.12", []byte{1, 2, 3, 4, 5, 8}),
+	}
+	ctx, err = module.ApplyHostImport(ctx, hosts)
+	require.NoError(t, err)
+
+	ctx, err = module.Commit(ctx)
+	require.ErrorContains(t, err, "failed to import 1 host reservations")
+
+	// All commands should be sent despite the error.
+	require.Len(t, agents.RecordedCommands, 3)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	errs := state.Updates[0].Recipe.ImportedHostErrors
+	require.Len(t, errs, 3)
+	require.Empty(t, errs[0])
+	require.Contains(t, errs[1], "reservation-add command to dhcp4 failed")
+	require.Empty(t, errs[2])
+
+	// Only the successfully imported hosts should be in the database.
+	for i, expected := range []bool{true, false, true} {
+		dbHosts, _, err := dbmodel.GetHostsByPage(db, 0, 10, dbmodel.HostsByPageFilters{
+			FilterText: storkutil.Ptr(hosts[i].LocalHosts[0].IPReservations[0].Address),
+		}, "", dbmodel.SortDirAny)
+		require.NoError(t, err)
+		if expected {
+			require.Len(t, dbHosts, 1)
+		} else {
+			require.Empty(t, dbHosts)
+		}
+	}
+}
+
 // Test the first stage of updating a host. It checks that the host information
 // is fetched from the database and stored in the context. It also checks that
 // appropriate locks are applied.
@@ -2039,6 +2340,66 @@ func TestApplySharedNetworkAdd(t *testing.T) {
 	}
 }
 
+// Test that the shared network is added to the config backend with a single
+// remote-network4-set command for the daemons sharing the database. The
+// daemons using the subnet_cmds hook receive the network4-add command.
+func TestApplySharedNetworkAddToConfigBackend(t *testing.T) {
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaSharedNetworkAdd)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	daemon1 := newTestDaemonWithConfig(t, daemonname.DHCPv4, storkutil.Ptr("server1"), keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+	daemon1.ID = 1
+	daemon2 := newTestDaemonWithConfig(t, daemonname.DHCPv4, storkutil.Ptr("server2"), keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+	daemon2.ID = 2
+	daemon3 := newTestDaemonWithConfig(t, daemonname.DHCPv4, nil, keaconfig.SubnetAndSharedNetworkAlteringHookLibrarySubnetCmds)
+	daemon3.ID = 3
+
+	sharedNetwork := &dbmodel.SharedNetwork{
+		Name:   "foo",
+		Family: 4,
+	}
+	for _, daemon := range []*dbmodel.Daemon{daemon1, daemon2, daemon3} {
+		sharedNetwork.LocalSharedNetworks = append(sharedNetwork.LocalSharedNetworks, &dbmodel.LocalSharedNetwork{
+			DaemonID: daemon.ID,
+			Daemon:   daemon,
+		})
+	}
+
+	ctx, err := module.ApplySharedNetworkAdd(ctx, sharedNetwork)
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, returnedState.Updates, 1)
+	commands := returnedState.Updates[0].Recipe.Commands
+	require.Len(t, commands, 3)
+
+	require.Equal(t, daemon3, commands[0].Daemon)
+	require.Equal(t, keactrl.Network4Add, commands[0].Command.Command)
+
+	require.Equal(t, daemon1, commands[1].Daemon)
+	marshalled, err := commands[1].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "remote-network4-set",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"shared-networks": [ { "name": "foo" } ],
+			"server-tags": [ "server1", "server2" ]
+		}
+	}`, string(marshalled))
+
+	// The config-write is only sent to the daemon not using the config backend.
+	require.Equal(t, daemon3, commands[2].Daemon)
+	require.Equal(t, keactrl.ConfigWrite, commands[2].Command.Command)
+}
+
 // Test committing created shared network, i.e. actually sending control commands to Kea.
 func TestCommitSharedNetworkAdd(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -6420,3 +6781,1002 @@ func TestCommitSubnetDeleteSubnetCmds(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, returnedSubnet)
 }
+
+// Returns a Kea daemon instance with the lease_cmds hook library configured.
+func getTestLeaseCmdsDaemon(t *testing.T, name daemonname.Name) *dbmodel.Daemon {
+	rootName := "Dhcp4"
+	if name == daemonname.DHCPv6 {
+		rootName = "Dhcp6"
+	}
+	leaseCmdsConfig, err := keaconfig.NewConfig([]byte(fmt.Sprintf(`{
+		"%s": {
+			"hooks-libraries": [{"library": "libdhcp_lease_cmds.so"}]
+		}
+	}`, rootName)))
+	require.NoError(t, err)
+	return &dbmodel.Daemon{
+		ID:   1,
+		Name: name,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: &dbmodel.KeaConfig{Config: leaseCmdsConfig},
+		},
+		AccessPoints: []*dbmodel.AccessPoint{
+			{
+				Type:    dbmodel.AccessPointControl,
+				Address: "192.0.2.1",
+				Port:    1234,
+			},
+		},
+	}
+}
+
+// Test preparing the lease4-add command.
+func TestApplyLease4Add(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestLeaseCmdsDaemon(t, daemonname.DHCPv4)
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Lease: keadata.Lease{
+			IPAddress:     "192.0.2.10",
+			HWAddress:     "01:02:03:04:05:06",
+			ValidLifetime: 3600,
+			LocalSubnetID: 123,
+			Hostname:      "client.example.org",
+		},
+	}
+	ctx, err := module.ApplyLeaseAdd(context.Background(), lease)
+	require.NoError(t, err)
+
+	// Make sure that the transaction state exists and comprises expected data.
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	update := state.Updates[0]
+	require.Equal(t, dbmodel.ConfigOperationKeaLeaseAdd, update.Operation)
+	require.Equal(t, []int64{daemon.ID}, update.DaemonIDs)
+	require.Equal(t, lease, update.Recipe.LeaseAfterUpdate)
+
+	require.Len(t, update.Recipe.Commands, 1)
+	require.Equal(t, daemon, update.Recipe.Commands[0].Daemon)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "lease4-add",
+			"service": [ "dhcp4" ],
+			"arguments": {
+				"ip-address": "192.0.2.10",
+				"subnet-id": 123,
+				"hw-address": "01:02:03:04:05:06",
+				"valid-lft": 3600,
+				"hostname": "client.example.org",
+				"state": 0
+			}
+		}`,
+		string(marshalled))
+}
+
+// Test that adding a lease fails when the lease_cmds hook library is not
+// configured.
+func TestApplyLeaseAddNoLeaseCmdsHook(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestLeaseCmdsDaemon(t, daemonname.DHCPv4)
+	noHooksConfig, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {}
+	}`))
+	require.NoError(t, err)
+	daemon.KeaDaemon.Config = &dbmodel.KeaConfig{Config: noHooksConfig}
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Lease: keadata.Lease{
+			IPAddress: "192.0.2.10",
+		},
+	}
+	_, err = module.ApplyLeaseAdd(context.Background(), lease)
+	var hookErr *config.NoLeaseCmdsHookError
+	require.ErrorAs(t, err, &hookErr)
+}
+
+// Test preparing the lease6-update command.
+func TestApplyLease6Update(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestLeaseCmdsDaemon(t, daemonname.DHCPv6)
+	duid := "01:02:03:04"
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Lease: keadata.Lease{
+			IPAddress:         "2001:db8:1::",
+			DUID:              keadata.NewColonSepHexStr(&duid),
+			IAID:              5,
+			Type:              string(keactrl.LeaseTypePD),
+			PrefixLength:      64,
+			ValidLifetime:     3600,
+			PreferredLifetime: 1800,
+			LocalSubnetID:     1,
+		},
+	}
+	ctx, err := module.ApplyLeaseUpdate(context.Background(), lease, true)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	update := state.Updates[0]
+	require.Equal(t, dbmodel.ConfigOperationKeaLeaseUpdate, update.Operation)
+	require.Equal(t, lease, update.Recipe.LeaseAfterUpdate)
+
+	require.Len(t, update.Recipe.Commands, 1)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "lease6-update",
+			"service": [ "dhcp6" ],
+			"arguments": {
+				"ip-address": "2001:db8:1::",
+				"subnet-id": 1,
+				"duid": "01:02:03:04",
+				"iaid": 5,
+				"type": "IA_PD",
+				"prefix-len": 64,
+				"valid-lft": 3600,
+				"preferred-lft": 1800,
+				"state": 0,
+				"force-create": true
+			}
+		}`,
+		string(marshalled))
+}
+
+// Test preparing the lease4-del and lease6-del commands.
+func TestApplyLeaseDelete(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	t.Run("DHCPv4", func(t *testing.T) {
+		daemon := getTestLeaseCmdsDaemon(t, daemonname.DHCPv4)
+		lease := &dbmodel.Lease{
+			DaemonID: daemon.ID,
+			Daemon:   daemon,
+			Lease: keadata.Lease{
+				IPAddress: "192.0.2.10",
+			},
+		}
+		ctx, err := module.ApplyLeaseDelete(context.Background(), lease)
+		require.NoError(t, err)
+
+		state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+		require.True(t, ok)
+		require.Len(t, state.Updates, 1)
+		update := state.Updates[0]
+		require.Equal(t, dbmodel.ConfigOperationKeaLeaseDelete, update.Operation)
+		require.Equal(t, lease, update.Recipe.DeletedLease)
+
+		require.Len(t, update.Recipe.Commands, 1)
+		marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+		require.NoError(t, err)
+		require.JSONEq(t,
+			`{
+				"command": "lease4-del",
+				"service": [ "dhcp4" ],
+				"arguments": {
+					"ip-address": "192.0.2.10"
+				}
+			}`,
+			string(marshalled))
+	})
+
+	t.Run("DHCPv6", func(t *testing.T) {
+		daemon := getTestLeaseCmdsDaemon(t, daemonname.DHCPv6)
+		lease := &dbmodel.Lease{
+			DaemonID: daemon.ID,
+			Daemon:   daemon,
+			Lease: keadata.Lease{
+				IPAddress: "2001:db8:1::",
+				Type:      string(keactrl.LeaseTypePD),
+			},
+		}
+		ctx, err := module.ApplyLeaseDelete(context.Background(), lease)
+		require.NoError(t, err)
+
+		state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+		require.True(t, ok)
+		require.Len(t, state.Updates, 1)
+
+		require.Len(t, state.Updates[0].Recipe.Commands, 1)
+		marshalled, err := state.Updates[0].Recipe.Commands[0].Command.Marshal()
+		require.NoError(t, err)
+		require.JSONEq(t,
+			`{
+				"command": "lease6-del",
+				"service": [ "dhcp6" ],
+				"arguments": {
+					"ip-address": "2001:db8:1::",
+					"type": "IA_PD"
+				}
+			}`,
+			string(marshalled))
+	})
+}
+
+// Test preparing the lease4-wipe command.
+func TestApplyLeaseWipe(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestLeaseCmdsDaemon(t, daemonname.DHCPv4)
+	ctx, err := module.ApplyLeaseWipe(context.Background(), daemon, 123)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	update := state.Updates[0]
+	require.Equal(t, dbmodel.ConfigOperationKeaLeaseWipe, update.Operation)
+	require.NotNil(t, update.Recipe.WipedLeasesDaemonID)
+	require.Equal(t, daemon.ID, *update.Recipe.WipedLeasesDaemonID)
+	require.NotNil(t, update.Recipe.WipedLeasesLocalSubnetID)
+	require.EqualValues(t, 123, *update.Recipe.WipedLeasesLocalSubnetID)
+
+	require.Len(t, update.Recipe.Commands, 1)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "lease4-wipe",
+			"service": [ "dhcp4" ],
+			"arguments": {
+				"subnet-id": 123
+			}
+		}`,
+		string(marshalled))
+}
+
+// Test committing lease deletion, i.e. actually sending control commands
+// to Kea and removing the lease from the database.
+func TestCommitLeaseDelete(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"hooks-libraries": [{"library": "libdhcp_lease_cmds.so"}]
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Lease: keadata.Lease{
+			Family:        storkutil.IPv4,
+			IPAddress:     "192.0.2.10",
+			HWAddress:     "01:02:03:04:05:06",
+			ValidLifetime: 3600,
+		},
+	}
+	err = dbmodel.AddLease(db, lease)
+	require.NoError(t, err)
+	lease.Daemon = daemon
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.ApplyLeaseDelete(context.Background(), lease)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.EqualValues(t, keactrl.Lease4Del, agents.RecordedCommands[0].GetCommand())
+
+	returnedLease, err := dbmodel.GetLeaseByID(db, lease.ID)
+	require.NoError(t, err)
+	require.Nil(t, returnedLease)
+}
+
+// Test committing the leases wipe.
+func TestCommitLeaseWipe(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"hooks-libraries": [{"library": "libdhcp_lease_cmds.so"}]
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	// Add two leases in different subnets.
+	for i, localSubnetID := range []uint32{1, 2} {
+		lease := &dbmodel.Lease{
+			DaemonID: daemon.ID,
+			Lease: keadata.Lease{
+				Family:        storkutil.IPv4,
+				IPAddress:     fmt.Sprintf("192.0.2.%d", 10+i),
+				ValidLifetime: 3600,
+				LocalSubnetID: localSubnetID,
+			},
+		}
+		err = dbmodel.AddLease(db, lease)
+		require.NoError(t, err)
+	}
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.ApplyLeaseWipe(context.Background(), daemon, 1)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	require.EqualValues(t, keactrl.Lease4Wipe, agents.RecordedCommands[0].GetCommand())
+
+	// Only the lease from the wiped subnet should be removed.
+	leases, total, err := dbmodel.GetLeasesByPage(db, 0, 10, dbmodel.LeasesByPageFilters{}, "", dbmodel.SortDirAny)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.EqualValues(t, 2, leases[0].LocalSubnetID)
+}
+
+// Returns a test daemon with the libdhcp_class_cmds hook library configured.
+func getTestClassCmdsDaemon(t *testing.T, name daemonname.Name) *dbmodel.Daemon {
+	rootName := "Dhcp4"
+	if name == daemonname.DHCPv6 {
+		rootName = "Dhcp6"
+	}
+	classCmdsConfig, err := keaconfig.NewConfig([]byte(fmt.Sprintf(`{
+		"%s": {
+			"hooks-libraries": [{"library": "libdhcp_class_cmds.so"}]
+		}
+	}`, rootName)))
+	require.NoError(t, err)
+	return &dbmodel.Daemon{
+		ID:   1,
+		Name: name,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: &dbmodel.KeaConfig{Config: classCmdsConfig},
+		},
+		AccessPoints: []*dbmodel.AccessPoint{
+			{
+				Type:    dbmodel.AccessPointControl,
+				Address: "192.0.2.1",
+				Port:    1234,
+			},
+		},
+	}
+}
+
+// Returns a test client class with the specified name.
+func getTestClientClass(daemon *dbmodel.Daemon, name string) *dbmodel.ClientClass {
+	return &dbmodel.ClientClass{
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Name:     name,
+		KeaParameters: &keaconfig.ClientClass{
+			ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+				Test:       storkutil.Ptr("member('ALL')"),
+				NextServer: storkutil.Ptr("192.0.2.2"),
+			},
+		},
+	}
+}
+
+// Test preparing the class-add command.
+func TestApplyClientClassAdd(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv4)
+	class := getTestClientClass(daemon, "foo")
+	ctx, err = module.ApplyClientClassAdd(ctx, class)
+	require.NoError(t, err)
+
+	// Make sure that the transaction state exists and comprises expected data.
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	update := state.Updates[0]
+	require.Equal(t, dbmodel.ConfigOperationKeaClientClassAdd, update.Operation)
+	require.Equal(t, class, update.Recipe.ClientClassAfterUpdate)
+
+	require.Len(t, update.Recipe.Commands, 2)
+	require.Equal(t, daemon, update.Recipe.Commands[0].Daemon)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "class-add",
+			"service": [ "dhcp4" ],
+			"arguments": {
+				"client-classes": [
+					{
+						"name": "foo",
+						"test": "member('ALL')",
+						"next-server": "192.0.2.2"
+					}
+				]
+			}
+		}`,
+		string(marshalled))
+
+	marshalled, err = update.Recipe.Commands[1].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "config-write",
+			"service": [ "dhcp4" ]
+		}`,
+		string(marshalled))
+}
+
+// Test that an error is returned when adding a client class to a daemon
+// lacking the class_cmds hook library.
+func TestApplyClientClassAddNoClassCmdsHook(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv4)
+	noHooksConfig, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {}
+	}`))
+	require.NoError(t, err)
+	daemon.KeaDaemon.Config = &dbmodel.KeaConfig{Config: noHooksConfig}
+
+	_, err = module.ApplyClientClassAdd(ctx, getTestClientClass(daemon, "foo"))
+	var hookErr *config.NoClassCmdsHookError
+	require.ErrorAs(t, err, &hookErr)
+}
+
+// Test that the remote-class4-set command is prepared for a daemon using
+// the config backend and that config-write is not sent to such a daemon.
+func TestApplyClientClassAddToConfigBackend(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	daemon := newTestDaemonWithConfig(t, daemonname.DHCPv4, storkutil.Ptr("server1"), keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds)
+	class := getTestClientClass(daemon, "foo")
+	ctx, err = module.ApplyClientClassAdd(ctx, class)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Len(t, state.Updates[0].Recipe.Commands, 1)
+	marshalled, err := state.Updates[0].Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "remote-class4-set",
+			"service": [ "dhcp4" ],
+			"arguments": {
+				"client-classes": [
+					{
+						"name": "foo",
+						"test": "member('ALL')",
+						"next-server": "192.0.2.2"
+					}
+				],
+				"server-tags": [ "server1" ]
+			}
+		}`,
+		string(marshalled))
+
+	// Delete the class.
+	ctx, err = module.ApplyClientClassDelete(context.Background(), class)
+	require.NoError(t, err)
+	state, ok = config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates[0].Recipe.Commands, 1)
+	require.Equal(t, keactrl.RemoteClass4Del, state.Updates[0].Recipe.Commands[0].Command.Command)
+}
+
+// Test preparing the class-update command.
+func TestApplyClientClassUpdate(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv6)
+	existingClass := getTestClientClass(daemon, "foo")
+	existingClass.ID = 5
+	existingClass.KeaParameters.UnknownParameters = map[string]any{
+		"comment": "bar",
+	}
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaClientClassUpdate, daemon.ID)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassBeforeUpdate: existingClass,
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	class := &dbmodel.ClientClass{
+		Name: "foo",
+		KeaParameters: &keaconfig.ClientClass{
+			ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+				PreferredLifetimeParameters: keaconfig.PreferredLifetimeParameters{
+					PreferredLifetime: storkutil.Ptr(int64(1800)),
+				},
+			},
+		},
+	}
+	ctx, err = module.ApplyClientClassUpdate(ctx, class)
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, returnedState.Updates, 1)
+	update := returnedState.Updates[0]
+	require.NotNil(t, update.Recipe.ClientClassAfterUpdate)
+	require.EqualValues(t, 5, update.Recipe.ClientClassAfterUpdate.ID)
+	require.Equal(t, daemon.ID, update.Recipe.ClientClassAfterUpdate.DaemonID)
+
+	require.Len(t, update.Recipe.Commands, 2)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "class-update",
+			"service": [ "dhcp6" ],
+			"arguments": {
+				"client-classes": [
+					{
+						"name": "foo",
+						"preferred-lifetime": 1800,
+						"comment": "bar"
+					}
+				]
+			}
+		}`,
+		string(marshalled))
+	require.EqualValues(t, keactrl.ConfigWrite, update.Recipe.Commands[1].Command.GetCommand())
+}
+
+// Test that renaming a client class is rejected.
+func TestApplyClientClassUpdateRename(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv4)
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaClientClassUpdate, daemon.ID)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{
+		ClientClassConfigRecipeParams: ClientClassConfigRecipeParams{
+			ClientClassBeforeUpdate: getTestClientClass(daemon, "foo"),
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	_, err = module.ApplyClientClassUpdate(ctx, getTestClientClass(daemon, "bar"))
+	require.ErrorContains(t, err, "renaming client class foo to bar is not supported")
+}
+
+// Test preparing the class-del command.
+func TestApplyClientClassDelete(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv4)
+	class := getTestClientClass(daemon, "foo")
+	class.ID = 7
+
+	ctx, err := module.ApplyClientClassDelete(context.Background(), class)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	update := state.Updates[0]
+	require.Equal(t, dbmodel.ConfigOperationKeaClientClassDelete, update.Operation)
+	require.Equal(t, []int64{daemon.ID}, update.DaemonIDs)
+	require.NotNil(t, update.Recipe.ClientClassID)
+	require.EqualValues(t, 7, *update.Recipe.ClientClassID)
+	require.NotNil(t, update.Recipe.DeletedClientClassName)
+	require.Equal(t, "foo", *update.Recipe.DeletedClientClassName)
+
+	require.Len(t, update.Recipe.Commands, 2)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "class-del",
+			"service": [ "dhcp4" ],
+			"arguments": {
+				"name": "foo"
+			}
+		}`,
+		string(marshalled))
+	require.EqualValues(t, keactrl.ConfigWrite, update.Recipe.Commands[1].Command.GetCommand())
+}
+
+// Test the client class update transaction from the beginning to the commit.
+func TestBeginCommitClientClassUpdate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('ALL')"
+				}
+			],
+			"hooks-libraries": [{"library": "libdhcp_class_cmds.so"}]
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	err = dbmodel.CommitClientClassesIntoDB(db, daemon.ID, daemon.KeaDaemon.Config.GetClientClasses())
+	require.NoError(t, err)
+	existingClass, err := dbmodel.GetClientClassByDaemonIDAndName(db, daemon.ID, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, existingClass)
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx := context.WithValue(context.Background(), config.ContextIDKey, int64(1))
+	ctx = context.WithValue(ctx, config.UserContextKey, int64(1))
+
+	ctx, err = module.BeginClientClassUpdate(ctx, existingClass.ID)
+	require.NoError(t, err)
+
+	state, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, state.Updates, 1)
+	require.Equal(t, []int64{daemon.ID}, state.Updates[0].DaemonIDs)
+	require.NotNil(t, state.Updates[0].Recipe.ClientClassBeforeUpdate)
+
+	// Make sure that the daemon configuration has been locked.
+	require.Contains(t, manager.locks, daemon.ID)
+
+	class := &dbmodel.ClientClass{
+		Name: "foo",
+		KeaParameters: &keaconfig.ClientClass{
+			ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+				Test: storkutil.Ptr("member('KNOWN')"),
+			},
+		},
+	}
+	ctx, err = module.ApplyClientClassUpdate(ctx, class)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.EqualValues(t, keactrl.ClassUpdate, agents.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	returnedClass, err := dbmodel.GetClientClassByID(db, existingClass.ID)
+	require.NoError(t, err)
+	require.NotNil(t, returnedClass)
+	require.Equal(t, "member('KNOWN')", returnedClass.KeaParameters.GetTest())
+}
+
+// Test that an error is returned when beginning the update of a non-existing
+// client class.
+func TestBeginClientClassUpdateNonExisting(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agentcommtest.NewKeaFakeAgents(),
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	_, err := module.BeginClientClassUpdate(context.Background(), 1234)
+	var notFoundErr *config.ClientClassNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+}
+
+// Test committing a new client class and then deleting it.
+func TestCommitClientClassAddDelete(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"hooks-libraries": [{"library": "libdhcp_class_cmds.so"}]
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx, err := module.BeginClientClassAdd(context.Background())
+	require.NoError(t, err)
+
+	class := getTestClientClass(daemon, "foo")
+	ctx, err = module.ApplyClientClassAdd(ctx, class)
+	require.NoError(t, err)
+
+	ctx, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	// The ID of the new class should be stored in the recipe.
+	recipe, err := config.GetRecipeForUpdate[ConfigRecipe](ctx, 0)
+	require.NoError(t, err)
+	require.NotNil(t, recipe.ClientClassID)
+
+	returnedClass, err := dbmodel.GetClientClassByID(db, *recipe.ClientClassID)
+	require.NoError(t, err)
+	require.NotNil(t, returnedClass)
+	require.Equal(t, "foo", returnedClass.Name)
+	require.Equal(t, "192.0.2.2", *returnedClass.KeaParameters.NextServer)
+
+	ctx, err = module.ApplyClientClassDelete(context.Background(), returnedClass)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 4)
+	require.EqualValues(t, keactrl.ClassAdd, agents.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, keactrl.ClassDel, agents.RecordedCommands[2].GetCommand())
+
+	returnedClass, err = dbmodel.GetClientClassByID(db, returnedClass.ID)
+	require.NoError(t, err)
+	require.Nil(t, returnedClass)
+}
+
+// Test that the configuration rollback prepares the config-set and
+// config-write commands and strips the configuration hash.
+func TestApplyConfigRollback(t *testing.T) {
+	module := NewConfigModule(nil)
+	require.NotNil(t, module)
+
+	daemon := getTestClassCmdsDaemon(t, daemonname.DHCPv4)
+
+	state := config.NewTransactionStateWithUpdate[ConfigRecipe](dbmodel.ConfigOperationKeaConfigRollback, daemon.ID)
+	err := state.SetRecipeForUpdate(0, &ConfigRecipe{
+		ConfigRollbackRecipeParams: ConfigRollbackRecipeParams{
+			RollbackDaemon: daemon,
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.StateContextKey, *state)
+
+	versionConfig, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		},
+		"hash": "abc"
+	}`))
+	require.NoError(t, err)
+	version := &dbmodel.KeaConfigVersion{
+		DaemonID: daemon.ID,
+		Version:  3,
+		Config:   &dbmodel.KeaConfig{Config: versionConfig},
+	}
+
+	ctx, err = module.ApplyConfigRollback(ctx, version)
+	require.NoError(t, err)
+
+	returnedState, ok := config.GetTransactionState[ConfigRecipe](ctx)
+	require.True(t, ok)
+	require.Len(t, returnedState.Updates, 1)
+	update := returnedState.Updates[0]
+	require.Equal(t, dbmodel.ConfigOperationKeaConfigRollback, update.Operation)
+	require.NotNil(t, update.Recipe.RollbackConfigVersion)
+	require.EqualValues(t, 3, *update.Recipe.RollbackConfigVersion)
+
+	require.Len(t, update.Recipe.Commands, 2)
+	marshalled, err := update.Recipe.Commands[0].Command.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{
+			"command": "config-set",
+			"service": [ "dhcp4" ],
+			"arguments": {
+				"Dhcp4": {
+					"valid-lifetime": 1000
+				}
+			}
+		}`,
+		string(marshalled))
+	require.EqualValues(t, keactrl.ConfigWrite, update.Recipe.Commands[1].Command.GetCommand())
+
+	// The version of another daemon cannot be applied.
+	version.DaemonID = 2
+	_, err = module.ApplyConfigRollback(ctx, version)
+	require.Error(t, err)
+}
+
+// Test the configuration rollback transaction from the beginning to the
+// commit and that the applied change is remembered for the config puller.
+func TestBeginCommitConfigRollback(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"valid-lifetime": 2000
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	user := &dbmodel.SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err = dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	versionConfig, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {
+			"valid-lifetime": 1000
+		}
+	}`))
+	require.NoError(t, err)
+	version := &dbmodel.KeaConfigVersion{
+		DaemonID:   daemon.ID,
+		Config:     &dbmodel.KeaConfig{Config: versionConfig},
+		ConfigHash: "abc",
+		Source:     dbmodel.KeaConfigVersionSourceExternal,
+	}
+	err = dbmodel.AddKeaConfigVersion(db, version)
+	require.NoError(t, err)
+
+	agents := agentcommtest.NewKeaFakeAgents()
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agents,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	ctx := context.WithValue(context.Background(), config.ContextIDKey, int64(1))
+	ctx = context.WithValue(ctx, config.UserContextKey, user.ID)
+
+	ctx, err = module.BeginConfigRollback(ctx, daemon.ID)
+	require.NoError(t, err)
+
+	// Make sure that the daemon configuration has been locked.
+	require.Contains(t, manager.locks, daemon.ID)
+
+	ctx, err = module.ApplyConfigRollback(ctx, version)
+	require.NoError(t, err)
+
+	_, err = module.Commit(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, agents.RecordedCommands, 2)
+	require.EqualValues(t, keactrl.ConfigSet, agents.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, keactrl.ConfigWrite, agents.RecordedCommands[1].GetCommand())
+
+	changes, err := dbmodel.DeleteKeaConfigPendingChanges(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, dbmodel.ConfigOperationKeaConfigRollback, changes[0].Operation)
+	require.NotNil(t, changes[0].UserID)
+	require.Equal(t, user.ID, *changes[0].UserID)
+}
+
+// Test that an error is returned when beginning the rollback of a
+// non-existing daemon's configuration.
+func TestBeginConfigRollbackNonExisting(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agentcommtest.NewKeaFakeAgents(),
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	_, err := module.BeginConfigRollback(context.Background(), 1234)
+	var notFoundErr *config.SomeDaemonsNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+}
+
+// Test that the configuration of a daemon using the config backend cannot
+// be rolled back.
+func TestBeginConfigRollbackConfigBackend(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(`{
+		"Dhcp4": {
+			"hooks-libraries": [{"library": "libdhcp_cb_cmds.so"}],
+			"config-control": {
+				"config-databases": [{"name": "keatest", "host": "localhost", "type": "mysql"}]
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	manager := newTestManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    agentcommtest.NewKeaFakeAgents(),
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	module := NewConfigModule(manager)
+	require.NotNil(t, module)
+
+	_, err = module.BeginConfigRollback(context.Background(), daemon.ID)
+	var configBackendErr *config.ConfigBackendRollbackError
+	require.ErrorAs(t, err, &configBackendErr)
+
+	// The configuration must not be locked.
+	require.NotContains(t, manager.locks, daemon.ID)
+}
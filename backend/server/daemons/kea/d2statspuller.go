@@ -0,0 +1,101 @@
+package kea
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// The puller responsible for fetching the statistics from the Kea
+// DHCP-DDNS (D2) servers. The D2 servers return the global statistics
+// (e.g., ncr-received, update-error) and the statistics per TSIG key.
+// Note that Kea doesn't export the depth of the NCR queue as a statistic.
+type D2StatsPuller struct {
+	*agentcomm.PeriodicPuller
+}
+
+// Creates a D2StatsPuller object that in background pulls the statistics
+// from the Kea D2 servers.
+func NewD2StatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents) (*D2StatsPuller, error) {
+	statsPuller := &D2StatsPuller{}
+	periodicPuller, err := agentcomm.NewPeriodicPuller(db, agents, "Kea D2 stats puller", "kea_d2_stats_puller_interval",
+		statsPuller.pullStats)
+	if err != nil {
+		return nil, err
+	}
+	statsPuller.PeriodicPuller = periodicPuller
+	return statsPuller, nil
+}
+
+// Shutdown D2StatsPuller. It stops goroutine that pulls stats.
+func (statsPuller *D2StatsPuller) Shutdown() {
+	statsPuller.PeriodicPuller.Shutdown()
+}
+
+// Pull stats periodically for all Kea D2 daemons which Stork is monitoring.
+// The function returns last encountered error.
+func (statsPuller *D2StatsPuller) pullStats() error {
+	daemons, err := dbmodel.GetDaemonsByName(statsPuller.DB, daemonname.D2)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	okCnt := 0
+	for _, daemon := range daemons {
+		err := statsPuller.getStatsFromDaemon(&daemon)
+		if err != nil {
+			lastErr = err
+			log.WithError(err).Errorf("Error occurred while getting stats from D2 daemon %d", daemon.ID)
+		} else {
+			okCnt++
+		}
+	}
+	log.Infof("Completed pulling stats from Kea D2 daemons: %d/%d succeeded", okCnt, len(daemons))
+	return lastErr
+}
+
+// Get stats from the given D2 daemon and store them in the database.
+func (statsPuller *D2StatsPuller) getStatsFromDaemon(daemon *dbmodel.Daemon) error {
+	if !daemon.Active {
+		return nil
+	}
+
+	cmd := keactrl.NewCommandBase(keactrl.StatisticGetAll, daemon.Name)
+	response := &keactrl.StatisticGetAllResponse{}
+
+	cmdsResult, err := statsPuller.Agents.ForwardToKeaOverHTTP(context.Background(), daemon, []keactrl.SerializableCommand{cmd}, response)
+	if err != nil {
+		return err
+	}
+	if err := cmdsResult.GetFirstError(); err != nil {
+		return err
+	}
+	if err := response.GetError(); err != nil {
+		return errors.WithMessage(err, "the statistic-get-all command returned an error")
+	}
+	if response.Arguments == nil {
+		return errors.Errorf("arguments missing in the statistic-get-all response")
+	}
+
+	return dbmodel.AddKeaD2Statistics(statsPuller.DB, dbmodel.NewKeaD2Statistics(daemon.ID, convertD2Statistics(response.Arguments)))
+}
+
+// Converts the samples returned by the D2 server to the map of statistics.
+// The values which don't fit in int64 are skipped.
+func convertD2Statistics(samples keactrl.StatisticGetAllResponseArguments) map[string]int64 {
+	statistics := make(map[string]int64)
+	for _, sample := range samples {
+		if sample == nil || sample.Value == nil || !sample.Value.IsInt64() {
+			continue
+		}
+		statistics[sample.Name] = sample.Value.Int64()
+	}
+	return statistics
+}
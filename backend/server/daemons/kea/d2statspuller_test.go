@@ -0,0 +1,71 @@
+package kea
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the statistics returned by D2 are converted to a map.
+func TestConvertD2Statistics(t *testing.T) {
+	var arguments keactrl.StatisticGetAllResponseArguments
+	err := json.Unmarshal([]byte(`{
+		"ncr-received": [ [ 12, "2025-04-22 17:59:15.338212" ] ],
+		"update-error": [ [ 1, "2025-04-22 17:59:15.338212" ] ],
+		"key[key1].update-sent": [ [ 7, "2025-04-22 17:59:15.338212" ] ],
+		"update-success": [ [ 18446744073709551615, "2025-04-22 17:59:15.338212" ] ]
+	}`), &arguments)
+	require.NoError(t, err)
+
+	statistics := convertD2Statistics(arguments)
+	require.Len(t, statistics, 3)
+	require.EqualValues(t, 12, statistics["ncr-received"])
+	require.EqualValues(t, 1, statistics["update-error"])
+	require.EqualValues(t, 7, statistics["key[key1].update-sent"])
+}
+
+// Test that the D2 statistics are pulled and stored in the database.
+func TestD2StatsPullerPullStats(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+
+	server, err := dbmodeltest.NewKeaD2Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(func(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+		err := json.Unmarshal([]byte(`{
+			"result": 0,
+			"arguments": {
+				"ncr-received": [ [ 12, "2025-04-22 17:59:15.338212" ] ],
+				"update-sent": [ [ 10, "2025-04-22 17:59:15.338212" ] ]
+			}
+		}`), cmdResponses[0])
+		require.NoError(t, err)
+	}, nil)
+
+	sp, err := NewD2StatsPuller(db, fa)
+	require.NoError(t, err)
+	defer sp.Shutdown()
+
+	err = sp.pullStats()
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	require.EqualValues(t, keactrl.StatisticGetAll, fa.RecordedCommands[0].GetCommand())
+
+	statistics, err := dbmodel.GetKeaD2Statistics(db, daemon.ID)
+	require.NoError(t, err)
+	require.NotNil(t, statistics)
+	require.EqualValues(t, 12, statistics.Statistics["ncr-received"])
+	require.EqualValues(t, 10, statistics.Statistics["update-sent"])
+}
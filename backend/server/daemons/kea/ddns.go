@@ -0,0 +1,172 @@
+package kea
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Indicates whether a DDNS domain is a forward or reverse domain.
+type DDNSDirection string
+
+const (
+	DDNSDirectionForward DDNSDirection = "forward"
+	DDNSDirectionReverse DDNSDirection = "reverse"
+)
+
+// Describes how a DDNS domain relates to the DNS zones known to Stork.
+type DDNSDomainZoneStatus string
+
+const (
+	// A zone with the same name as the domain is served by at least one
+	// managed DNS daemon.
+	DDNSDomainZoneStatusServed DDNSDomainZoneStatus = "served"
+	// The domain belongs to a zone served by at least one managed DNS
+	// daemon but the zone has a different name than the domain (e.g.,
+	// the domain is dyn.example.org and the zone is example.org).
+	DDNSDomainZoneStatusServedByParent DDNSDomainZoneStatus = "served-by-parent"
+	// None of the managed DNS daemons serves a zone for the domain.
+	DDNSDomainZoneStatusNotServed DDNSDomainZoneStatus = "not-served"
+)
+
+// Represents a DDNS domain configured in the D2 server correlated with
+// a zone served by the managed DNS daemons.
+type DDNSDomainCorrelation struct {
+	Direction DDNSDirection
+	Domain    keaconfig.DDNSDomain
+	// Names of the TSIG keys used for the domain.
+	KeyNames []string
+	// Names of the TSIG keys referenced by the domain but not configured
+	// in the D2 server.
+	MissingKeyNames []string
+	Status          DDNSDomainZoneStatus
+	// Zone matching the domain or nil if no zone has been found. The
+	// zone includes the local zones of the DNS daemons serving it.
+	Zone *dbmodel.Zone
+	// Indicates if any of the DNS daemons is primary for the zone, i.e.
+	// can directly accept the DNS updates.
+	Primary bool
+}
+
+// Normalizes the domain name for comparison with the zone names stored in
+// the database. It converts the name to lower case and removes the trailing
+// dot.
+func normalizeDDNSDomainName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// Returns the domain name and the names of its parent domains. The root
+// zone is not included.
+func getDDNSDomainCandidateZoneNames(name string) (names []string) {
+	labels := strings.Split(normalizeDDNSDomainName(name), ".")
+	for i := range labels {
+		candidate := strings.Join(labels[i:], ".")
+		if len(candidate) > 0 {
+			names = append(names, candidate)
+		}
+	}
+	return
+}
+
+// Checks if the local zone may hold the records updated by D2.
+func isDDNSServingLocalZone(localZone *dbmodel.LocalZone) bool {
+	switch dbmodel.ZoneType(localZone.Type) {
+	case dbmodel.ZoneTypePrimary, dbmodel.ZoneTypeMaster, dbmodel.ZoneTypeNative,
+		dbmodel.ZoneTypeSecondary, dbmodel.ZoneTypeSlave:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checks if the local zone can directly accept the DNS updates.
+func isDDNSPrimaryLocalZone(localZone *dbmodel.LocalZone) bool {
+	switch dbmodel.ZoneType(localZone.Type) {
+	case dbmodel.ZoneTypePrimary, dbmodel.ZoneTypeMaster, dbmodel.ZoneTypeNative:
+		return true
+	default:
+		return false
+	}
+}
+
+// Matches the forward and reverse DDNS domains configured in the D2 server
+// with the zones served by the managed DNS daemons. For each domain it finds
+// the zone with the longest name the domain belongs to. The zones whose
+// local zones don't hold the authoritative data (e.g., builtin or forward
+// zones) are ignored. It returns an error if the daemon is not a D2 server.
+func CorrelateDDNSDomains(db pg.DBI, daemon *dbmodel.Daemon) ([]DDNSDomainCorrelation, error) {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil || !daemon.KeaDaemon.Config.IsD2() {
+		return nil, errors.Errorf("D2 configuration not found for daemon %d", daemon.ID)
+	}
+	config := daemon.KeaDaemon.Config
+
+	var correlations []DDNSDomainCorrelation
+	for _, domain := range config.GetForwardDDNSDomains() {
+		correlations = append(correlations, DDNSDomainCorrelation{
+			Direction: DDNSDirectionForward,
+			Domain:    domain,
+		})
+	}
+	for _, domain := range config.GetReverseDDNSDomains() {
+		correlations = append(correlations, DDNSDomainCorrelation{
+			Direction: DDNSDirectionReverse,
+			Domain:    domain,
+		})
+	}
+
+	// Fetch all zones which may contain the domains in a single query.
+	var names []string
+	for _, correlation := range correlations {
+		for _, name := range getDDNSDomainCandidateZoneNames(correlation.Domain.Name) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	zones, err := dbmodel.GetZonesByNames(db, names, dbmodel.ZoneRelationLocalZonesDaemon)
+	if err != nil {
+		return nil, err
+	}
+	zonesByName := make(map[string]*dbmodel.Zone)
+	for _, zone := range zones {
+		zonesByName[zone.Name] = zone
+	}
+
+	for i := range correlations {
+		correlation := &correlations[i]
+		correlation.Status = DDNSDomainZoneStatusNotServed
+		correlation.KeyNames = correlation.Domain.GetKeyNames()
+		for _, keyName := range correlation.KeyNames {
+			if config.GetTSIGKey(keyName) == nil {
+				correlation.MissingKeyNames = append(correlation.MissingKeyNames, keyName)
+			}
+		}
+		// The candidate names are ordered from the longest to the shortest,
+		// so the first matching zone is the closest one.
+	ZONE_MATCH_LOOP:
+		for j, name := range getDDNSDomainCandidateZoneNames(correlation.Domain.Name) {
+			zone, ok := zonesByName[name]
+			if !ok {
+				continue
+			}
+			for _, localZone := range zone.LocalZones {
+				if !isDDNSServingLocalZone(localZone) {
+					continue
+				}
+				correlation.Zone = zone
+				if j == 0 {
+					correlation.Status = DDNSDomainZoneStatusServed
+				} else {
+					correlation.Status = DDNSDomainZoneStatusServedByParent
+				}
+				correlation.Primary = slices.ContainsFunc(zone.LocalZones, isDDNSPrimaryLocalZone)
+				break ZONE_MATCH_LOOP
+			}
+		}
+	}
+	return correlations, nil
+}
@@ -0,0 +1,142 @@
+package kea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the candidate zone names are generated for a domain name.
+func TestGetDDNSDomainCandidateZoneNames(t *testing.T) {
+	require.Equal(t, []string{"dyn.example.org", "example.org", "org"}, getDDNSDomainCandidateZoneNames("Dyn.Example.ORG."))
+	require.Equal(t, []string{"2.0.192.in-addr.arpa", "0.192.in-addr.arpa", "192.in-addr.arpa", "in-addr.arpa", "arpa"}, getDDNSDomainCandidateZoneNames("2.0.192.in-addr.arpa"))
+	require.Empty(t, getDDNSDomainCandidateZoneNames("."))
+	require.Empty(t, getDDNSDomainCandidateZoneNames(""))
+}
+
+// Test that an error is returned when correlating the domains for a daemon
+// which is not a D2 server.
+func TestCorrelateDDNSDomainsNotD2(t *testing.T) {
+	config, err := keaconfig.NewConfig([]byte(`{ "Dhcp4": { } }`))
+	require.NoError(t, err)
+	daemon := &dbmodel.Daemon{
+		ID: 1,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: &dbmodel.KeaConfig{Config: config},
+		},
+	}
+	correlations, err := CorrelateDDNSDomains(nil, daemon)
+	require.Error(t, err)
+	require.Nil(t, correlations)
+}
+
+// Test that the DDNS domains are correlated with the zones served by the
+// managed DNS servers.
+func TestCorrelateDDNSDomains(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine, err := dbmodeltest.NewMachine(db)
+	require.NoError(t, err)
+
+	d2, err := machine.NewKeaD2Server()
+	require.NoError(t, err)
+	err = d2.Configure(`{
+		"DhcpDdns": {
+			"forward-ddns": {
+				"ddns-domains": [
+					{
+						"name": "example.org.",
+						"key-name": "key1",
+						"dns-servers": [ { "ip-address": "192.0.2.1" } ]
+					},
+					{
+						"name": "dyn.example.com.",
+						"dns-servers": [ { "ip-address": "192.0.2.1", "key-name": "key2" } ]
+					},
+					{
+						"name": "example.net.",
+						"dns-servers": [ { "ip-address": "192.0.2.1" } ]
+					}
+				]
+			},
+			"reverse-ddns": {
+				"ddns-domains": [
+					{
+						"name": "2.0.192.in-addr.arpa.",
+						"dns-servers": [ { "ip-address": "192.0.2.1" } ]
+					}
+				]
+			},
+			"tsig-keys": [
+				{
+					"name": "key1",
+					"algorithm": "HMAC-SHA256",
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	d2Daemon, err := d2.GetDaemon()
+	require.NoError(t, err)
+
+	bind9, err := machine.NewBind9Daemon()
+	require.NoError(t, err)
+
+	// The example.org zone is served as primary, the example.com zone as
+	// secondary and the reverse zone exists only as a builtin zone.
+	for name, zoneType := range map[string]dbmodel.ZoneType{
+		"example.org":          dbmodel.ZoneTypePrimary,
+		"example.com":          dbmodel.ZoneTypeSecondary,
+		"2.0.192.in-addr.arpa": dbmodel.ZoneTypeBuiltin,
+	} {
+		err = dbmodel.AddZones(db, &dbmodel.Zone{
+			Name: name,
+			LocalZones: []*dbmodel.LocalZone{
+				{
+					DaemonID: bind9.DaemonID,
+					View:     "_default",
+					Class:    "IN",
+					Serial:   1,
+					Type:     string(zoneType),
+					LoadedAt: time.Now().UTC(),
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	correlations, err := CorrelateDDNSDomains(db, d2Daemon)
+	require.NoError(t, err)
+	require.Len(t, correlations, 4)
+
+	require.Equal(t, DDNSDirectionForward, correlations[0].Direction)
+	require.Equal(t, "example.org.", correlations[0].Domain.Name)
+	require.Equal(t, DDNSDomainZoneStatusServed, correlations[0].Status)
+	require.NotNil(t, correlations[0].Zone)
+	require.Equal(t, "example.org", correlations[0].Zone.Name)
+	require.True(t, correlations[0].Primary)
+	require.Equal(t, []string{"key1"}, correlations[0].KeyNames)
+	require.Empty(t, correlations[0].MissingKeyNames)
+
+	require.Equal(t, DDNSDirectionForward, correlations[1].Direction)
+	require.Equal(t, DDNSDomainZoneStatusServedByParent, correlations[1].Status)
+	require.NotNil(t, correlations[1].Zone)
+	require.Equal(t, "example.com", correlations[1].Zone.Name)
+	require.False(t, correlations[1].Primary)
+	require.Equal(t, []string{"key2"}, correlations[1].MissingKeyNames)
+
+	require.Equal(t, DDNSDirectionForward, correlations[2].Direction)
+	require.Equal(t, DDNSDomainZoneStatusNotServed, correlations[2].Status)
+	require.Nil(t, correlations[2].Zone)
+
+	require.Equal(t, DDNSDirectionReverse, correlations[3].Direction)
+	require.Equal(t, DDNSDomainZoneStatusNotServed, correlations[3].Status)
+	require.Nil(t, correlations[3].Zone)
+}
@@ -0,0 +1,244 @@
+package kea
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// An action changing the state of the HA service.
+type HAAction string
+
+// Supported HA actions.
+const (
+	HAActionMaintenanceStart  HAAction = "maintenance-start"
+	HAActionMaintenanceCancel HAAction = "maintenance-cancel"
+	HAActionContinue          HAAction = "continue"
+	HAActionSync              HAAction = "sync"
+	HAActionScopes            HAAction = "scopes"
+	HAActionReset             HAAction = "reset"
+)
+
+// Optional parameters of the HA actions.
+type HAActionParams struct {
+	// Scopes to be enabled by the scopes action.
+	Scopes []string
+	// Maximum duration in seconds for which the partner's DHCP service
+	// is disabled during the lease database synchronization.
+	MaxPeriod *int64
+}
+
+// An error returned when the HA action cannot be performed in the current
+// state of the HA service.
+type HAActionNotAllowedError struct {
+	action HAAction
+	reason string
+}
+
+// Creates new instance of the HAActionNotAllowedError.
+func NewHAActionNotAllowedError(action HAAction, reason string) error {
+	return &HAActionNotAllowedError{
+		action: action,
+		reason: reason,
+	}
+}
+
+// Returns error string.
+func (e HAActionNotAllowedError) Error() string {
+	return fmt.Sprintf("HA %s action is not allowed: %s", e.action, e.reason)
+}
+
+// Holds the information about an HA relationship from the point of view
+// of a server receiving an HA command.
+type haRelationshipView struct {
+	state            dbmodel.HAState
+	reachable        bool
+	partnerState     dbmodel.HAState
+	partnerReachable bool
+	partnerName      string
+	peerNames        []string
+	hubAndSpoke      bool
+}
+
+// Finds the configuration of the HA relationship the service pertains to
+// in the daemon's configuration and returns the relationship information
+// combined with the last known states of the servers.
+func getHARelationshipView(service *dbmodel.Service, daemon *dbmodel.Daemon) (*haRelationshipView, error) {
+	ha := service.HAService
+	view := &haRelationshipView{}
+	switch daemon.ID {
+	case ha.PrimaryID:
+		view.state = ha.PrimaryLastState
+		view.reachable = ha.PrimaryReachable
+		view.partnerState = ha.SecondaryLastState
+		view.partnerReachable = ha.SecondaryReachable
+	case ha.SecondaryID:
+		view.state = ha.SecondaryLastState
+		view.reachable = ha.SecondaryReachable
+		view.partnerState = ha.PrimaryLastState
+		view.partnerReachable = ha.PrimaryReachable
+	default:
+		return nil, errors.Errorf("daemon %d is neither a primary nor a secondary server in the HA relationship %s", daemon.ID, ha.Relationship)
+	}
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil, errors.Errorf("configuration not found for daemon %d", daemon.ID)
+	}
+	_, params, ok := daemon.KeaDaemon.Config.GetHookLibraries().GetHAHookLibrary()
+	if !ok {
+		return nil, errors.Errorf("HA hook library not configured for daemon %d", daemon.ID)
+	}
+	relationships := params.GetAllRelationships()
+	view.hubAndSpoke = len(relationships) > 1
+
+	// The relationship is identified by one of the peer names. In the
+	// hub-and-spoke configuration, the hub's name appears in all its
+	// relationships, so the relationships in which the name belongs to
+	// the other peer take precedence.
+	var relationship *keaconfig.HA
+RELATIONSHIP_MATCH_LOOP:
+	for i := range relationships {
+		for _, peer := range relationships[i].Peers {
+			if peer.Name == nil || *peer.Name != ha.Relationship {
+				continue
+			}
+			if relationship == nil {
+				relationship = &relationships[i]
+			}
+			if relationships[i].ThisServerName != nil && *relationships[i].ThisServerName != ha.Relationship {
+				relationship = &relationships[i]
+				break RELATIONSHIP_MATCH_LOOP
+			}
+		}
+	}
+	if relationship == nil || !relationship.IsValid() {
+		return nil, errors.Errorf("configuration of the HA relationship %s not found for daemon %d", ha.Relationship, daemon.ID)
+	}
+	for _, peer := range relationship.Peers {
+		view.peerNames = append(view.peerNames, *peer.Name)
+		if *peer.Name == *relationship.ThisServerName {
+			continue
+		}
+		switch *peer.Role {
+		case "primary", "secondary", "standby":
+			view.partnerName = *peer.Name
+		}
+	}
+	return view, nil
+}
+
+// Checks if the HA action can be performed in the current state of the
+// HA service. The action is performed by the specified daemon belonging to
+// the service. It returns HAActionNotAllowedError when the action is not
+// allowed.
+func checkHAActionPreconditions(service *dbmodel.Service, view *haRelationshipView, action HAAction, params HAActionParams) error {
+	if !view.reachable || view.state == dbmodel.HAStateUnavailable {
+		return NewHAActionNotAllowedError(action, "the server is unreachable")
+	}
+	switch action {
+	case HAActionMaintenanceStart:
+		if service.HAService.HAMode == dbmodel.HAModePassiveBackup {
+			return NewHAActionNotAllowedError(action, "maintenance is not supported in the passive-backup mode")
+		}
+		switch {
+		case view.state == dbmodel.HAStatePartnerDown || !view.partnerReachable ||
+			view.partnerState == dbmodel.HAStateUnavailable || view.partnerState == dbmodel.HAStateTerminated:
+			return NewHAActionNotAllowedError(action, "the partner is already down")
+		case view.state == dbmodel.HAStateInMaintenance || view.state == dbmodel.HAStatePartnerInMaintenance ||
+			view.partnerState == dbmodel.HAStateInMaintenance || view.partnerState == dbmodel.HAStatePartnerInMaintenance:
+			return NewHAActionNotAllowedError(action, "the maintenance is already in progress")
+		}
+	case HAActionMaintenanceCancel:
+		if view.state != dbmodel.HAStatePartnerInMaintenance {
+			return NewHAActionNotAllowedError(action, fmt.Sprintf("the server is in the %s state rather than in the partner-in-maintenance state", view.state))
+		}
+	case HAActionSync:
+		if len(view.partnerName) == 0 {
+			return NewHAActionNotAllowedError(action, "the partner is not configured")
+		}
+		if !view.partnerReachable || view.partnerState == dbmodel.HAStateUnavailable {
+			return NewHAActionNotAllowedError(action, "the partner is unreachable")
+		}
+	case HAActionScopes:
+		for _, scope := range params.Scopes {
+			if !slices.Contains(view.peerNames, scope) {
+				return NewHAActionNotAllowedError(action, fmt.Sprintf("the scope %s does not match any server in the relationship", scope))
+			}
+		}
+	case HAActionContinue, HAActionReset:
+	default:
+		return errors.Errorf("unsupported HA action %s", action)
+	}
+	return nil
+}
+
+// Creates the command performing the HA action.
+func createHAActionCommand(daemon *dbmodel.Daemon, view *haRelationshipView, action HAAction, params HAActionParams) *keactrl.Command {
+	// The server name is only required to select the relationship in
+	// the hub-and-spoke configuration. The older Kea versions don't
+	// accept it.
+	serverName := ""
+	if view.hubAndSpoke {
+		serverName = view.partnerName
+	}
+	switch action {
+	case HAActionMaintenanceStart:
+		return keactrl.NewCommandHAMaintenanceStart(daemon.Name, serverName)
+	case HAActionMaintenanceCancel:
+		return keactrl.NewCommandHAMaintenanceCancel(daemon.Name, serverName)
+	case HAActionContinue:
+		return keactrl.NewCommandHAContinue(daemon.Name, serverName)
+	case HAActionSync:
+		return keactrl.NewCommandHASync(daemon.Name, view.partnerName, params.MaxPeriod)
+	case HAActionScopes:
+		return keactrl.NewCommandHAScopes(daemon.Name, serverName, params.Scopes)
+	default:
+		return keactrl.NewCommandHAReset(daemon.Name, serverName)
+	}
+}
+
+// Performs the HA action by sending a suitable command to the specified
+// daemon belonging to the HA service. It first checks whether the action
+// is allowed in the current state of the service and returns the
+// HAActionNotAllowedError if it is not. Otherwise, it returns the text
+// of the Kea response or an error if the command failed.
+func ExecuteHAAction(ctx context.Context, agents agentcomm.ConnectedAgents, service *dbmodel.Service, daemon *dbmodel.Daemon, action HAAction, params HAActionParams) (string, error) {
+	if service.HAService == nil {
+		return "", errors.Errorf("service %d is not an HA service", service.ID)
+	}
+	view, err := getHARelationshipView(service, daemon)
+	if err != nil {
+		return "", err
+	}
+	if err = checkHAActionPreconditions(service, view, action, params); err != nil {
+		return "", err
+	}
+	command := createHAActionCommand(daemon, view, action, params)
+
+	// The synchronization may take a long time.
+	timeout := 10 * time.Second
+	if action == HAActionSync {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var response keactrl.Response
+	result, err := agents.ForwardToKeaOverHTTP(ctx, daemon, []keactrl.SerializableCommand{command}, &response)
+	if err != nil {
+		return "", err
+	}
+	if err = result.GetFirstError(); err != nil {
+		return "", err
+	}
+	if err = response.GetError(); err != nil {
+		return "", errors.WithMessagef(err, "%s command failed", command.GetCommand())
+	}
+	return response.Text, nil
+}
@@ -0,0 +1,316 @@
+package kea
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/agentcomm"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns a DHCPv4 daemon with the specified ID and configuration.
+func getTestHAControlDaemon(id int64, config *dbmodel.KeaConfig) *dbmodel.Daemon {
+	return &dbmodel.Daemon{
+		ID:   id,
+		Name: daemonname.DHCPv4,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: config,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{
+			{
+				Type:    dbmodel.AccessPointControl,
+				Address: "192.0.2.1",
+				Port:    8000,
+			},
+		},
+	}
+}
+
+// Returns an HA service in the load-balancing mode with both servers
+// reachable and in the load-balancing state.
+func getTestHAControlService() *dbmodel.Service {
+	return &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			ID: 1,
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:             daemonname.DHCPv4,
+			HAMode:             dbmodel.HAModeLoadBalancing,
+			Relationship:       "server1",
+			PrimaryID:          1,
+			SecondaryID:        2,
+			BackupID:           []int64{3},
+			PrimaryLastState:   dbmodel.HAStateLoadBalancing,
+			SecondaryLastState: dbmodel.HAStateLoadBalancing,
+			PrimaryReachable:   true,
+			SecondaryReachable: true,
+		},
+	}
+}
+
+// Generates a failed response to an HA command.
+func mockHACommandError(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	_ = json.Unmarshal([]byte(`{
+		"result": 1,
+		"text": "unable to transition the server to the in-maintenance state"
+	}`), cmdResponses[0])
+}
+
+// Test that the maintenance is started when both servers are operational.
+func TestExecuteHAActionMaintenanceStart(t *testing.T) {
+	daemon := getTestHAControlDaemon(1, getHATestConfig("Dhcp4", "server1", "load-balancing", "server1", "server2", "server4"))
+	service := getTestHAControlService()
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	marshalled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-maintenance-start",
+		"service": [ "dhcp4" ]
+	}`, string(marshalled))
+}
+
+// Test that the maintenance is not started when the partner is down.
+func TestExecuteHAActionMaintenanceStartPartnerDown(t *testing.T) {
+	daemon := getTestHAControlDaemon(1, getHATestConfig("Dhcp4", "server1", "load-balancing", "server1", "server2"))
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	t.Run("partner-down state", func(t *testing.T) {
+		service := getTestHAControlService()
+		service.HAService.PrimaryLastState = dbmodel.HAStatePartnerDown
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+		var notAllowed *HAActionNotAllowedError
+		require.ErrorAs(t, err, &notAllowed)
+		require.Contains(t, err.Error(), "the partner is already down")
+	})
+
+	t.Run("partner unreachable", func(t *testing.T) {
+		service := getTestHAControlService()
+		service.HAService.SecondaryReachable = false
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+		var notAllowed *HAActionNotAllowedError
+		require.ErrorAs(t, err, &notAllowed)
+	})
+
+	t.Run("maintenance in progress", func(t *testing.T) {
+		service := getTestHAControlService()
+		service.HAService.PrimaryLastState = dbmodel.HAStatePartnerInMaintenance
+		service.HAService.SecondaryLastState = dbmodel.HAStateInMaintenance
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+		var notAllowed *HAActionNotAllowedError
+		require.ErrorAs(t, err, &notAllowed)
+		require.Contains(t, err.Error(), "already in progress")
+	})
+
+	t.Run("passive-backup mode", func(t *testing.T) {
+		service := getTestHAControlService()
+		service.HAService.HAMode = dbmodel.HAModePassiveBackup
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+		var notAllowed *HAActionNotAllowedError
+		require.ErrorAs(t, err, &notAllowed)
+	})
+
+	require.Empty(t, fa.RecordedCommands)
+}
+
+// Test that the maintenance can only be cancelled by the server in the
+// partner-in-maintenance state.
+func TestExecuteHAActionMaintenanceCancel(t *testing.T) {
+	daemon := getTestHAControlDaemon(2, getHATestConfig("Dhcp4", "server2", "load-balancing", "server1", "server2"))
+	service := getTestHAControlService()
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceCancel, HAActionParams{})
+	var notAllowed *HAActionNotAllowedError
+	require.ErrorAs(t, err, &notAllowed)
+	require.Empty(t, fa.RecordedCommands)
+
+	service.HAService.SecondaryLastState = dbmodel.HAStatePartnerInMaintenance
+	service.HAService.PrimaryLastState = dbmodel.HAStateInMaintenance
+	_, err = ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceCancel, HAActionParams{})
+	require.NoError(t, err)
+	require.Len(t, fa.RecordedCommands, 1)
+	require.EqualValues(t, "ha-maintenance-cancel", fa.RecordedCommands[0].GetCommand())
+}
+
+// Test that the lease database synchronization is requested from the
+// partner.
+func TestExecuteHAActionSync(t *testing.T) {
+	daemon := getTestHAControlDaemon(2, getHATestConfig("Dhcp4", "server2", "load-balancing", "server1", "server2", "server4"))
+	service := getTestHAControlService()
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionSync, HAActionParams{
+		MaxPeriod: storkutil.Ptr(int64(30)),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	marshalled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-sync",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"server-name": "server1",
+			"max-period": 30
+		}
+	}`, string(marshalled))
+
+	// The synchronization is not possible when the partner is unreachable.
+	service.HAService.PrimaryReachable = false
+	_, err = ExecuteHAAction(context.Background(), fa, service, daemon, HAActionSync, HAActionParams{})
+	var notAllowed *HAActionNotAllowedError
+	require.ErrorAs(t, err, &notAllowed)
+	require.Len(t, fa.RecordedCommands, 1)
+}
+
+// Test that the scopes must match the names of the servers in the
+// relationship.
+func TestExecuteHAActionScopes(t *testing.T) {
+	daemon := getTestHAControlDaemon(1, getHATestConfig("Dhcp4", "server1", "load-balancing", "server1", "server2"))
+	service := getTestHAControlService()
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionScopes, HAActionParams{
+		Scopes: []string{"server1", "server3"},
+	})
+	var notAllowed *HAActionNotAllowedError
+	require.ErrorAs(t, err, &notAllowed)
+	require.Empty(t, fa.RecordedCommands)
+
+	_, err = ExecuteHAAction(context.Background(), fa, service, daemon, HAActionScopes, HAActionParams{
+		Scopes: []string{"server1", "server2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, fa.RecordedCommands, 1)
+	marshalled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-scopes",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"scopes": [ "server1", "server2" ]
+		}
+	}`, string(marshalled))
+}
+
+// Test that the continue and reset actions are sent to a reachable server.
+func TestExecuteHAActionContinueReset(t *testing.T) {
+	daemon := getTestHAControlDaemon(1, getHATestConfig("Dhcp4", "server1", "load-balancing", "server1", "server2"))
+	service := getTestHAControlService()
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionContinue, HAActionParams{})
+	require.NoError(t, err)
+	_, err = ExecuteHAAction(context.Background(), fa, service, daemon, HAActionReset, HAActionParams{})
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	require.EqualValues(t, "ha-continue", fa.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, "ha-reset", fa.RecordedCommands[1].GetCommand())
+
+	// No command should be sent to the unreachable server.
+	service.HAService.PrimaryReachable = false
+	_, err = ExecuteHAAction(context.Background(), fa, service, daemon, HAActionReset, HAActionParams{})
+	var notAllowed *HAActionNotAllowedError
+	require.ErrorAs(t, err, &notAllowed)
+	require.Len(t, fa.RecordedCommands, 2)
+}
+
+// Test that the server name selecting the relationship is included in the
+// commands sent to the hub in the hub-and-spoke configuration.
+func TestExecuteHAActionHubAndSpoke(t *testing.T) {
+	config, err := keaconfig.NewConfig([]byte(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_ha.so",
+					"parameters": {
+						"high-availability": [
+							{
+								"this-server-name": "hub",
+								"mode": "hot-standby",
+								"peers": [
+									{ "name": "hub", "url": "http://192.0.2.1:8000", "role": "primary" },
+									{ "name": "spoke1", "url": "http://192.0.2.2:8000", "role": "standby" }
+								]
+							},
+							{
+								"this-server-name": "hub",
+								"mode": "hot-standby",
+								"peers": [
+									{ "name": "hub", "url": "http://192.0.2.1:8001", "role": "primary" },
+									{ "name": "spoke2", "url": "http://192.0.2.3:8000", "role": "standby" }
+								]
+							}
+						]
+					}
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	daemon := getTestHAControlDaemon(1, &dbmodel.KeaConfig{Config: config})
+	service := getTestHAControlService()
+	service.HAService.HAMode = dbmodel.HAModeHotStandby
+	service.HAService.Relationship = "spoke2"
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+
+	_, err = ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	marshalled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "ha-maintenance-start",
+		"service": [ "dhcp4" ],
+		"arguments": {
+			"server-name": "spoke2"
+		}
+	}`, string(marshalled))
+}
+
+// Test that an error is returned when the daemon is a backup server or
+// when Kea returns an error.
+func TestExecuteHAActionError(t *testing.T) {
+	service := getTestHAControlService()
+
+	t.Run("backup server", func(t *testing.T) {
+		daemon := getTestHAControlDaemon(3, getHATestConfig("Dhcp4", "server4", "load-balancing", "server1", "server2", "server4"))
+		fa := agentcommtest.NewFakeAgents(nil, nil)
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionContinue, HAActionParams{})
+		require.Error(t, err)
+		require.Empty(t, fa.RecordedCommands)
+	})
+
+	t.Run("no HA configuration", func(t *testing.T) {
+		daemon := getTestHAControlDaemon(1, nil)
+		fa := agentcommtest.NewFakeAgents(nil, nil)
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionContinue, HAActionParams{})
+		require.Error(t, err)
+		require.Empty(t, fa.RecordedCommands)
+	})
+
+	t.Run("Kea error", func(t *testing.T) {
+		daemon := getTestHAControlDaemon(1, getHATestConfig("Dhcp4", "server1", "load-balancing", "server1", "server2"))
+		fa := agentcommtest.NewFakeAgents(mockHACommandError, nil)
+		_, err := ExecuteHAAction(context.Background(), fa, service, daemon, HAActionMaintenanceStart, HAActionParams{})
+		require.Error(t, err)
+		var notAllowed *HAActionNotAllowedError
+		require.False(t, errors.As(err, &notAllowed))
+		require.Len(t, fa.RecordedCommands, 1)
+	})
+}
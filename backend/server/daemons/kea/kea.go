@@ -3,6 +3,7 @@ package kea
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -45,8 +46,25 @@ type VersionGetResponse struct {
 type DaemonStateMeta struct {
 	Events          []*dbmodel.Event
 	IsConfigChanged bool
+	// Indicates that the differences between the running configuration
+	// and the configuration file have changed since the previous fetch.
+	IsConfigDriftChanged bool
+	// Indicates that the daemon has been restarted or has reloaded its
+	// configuration since the previous fetch.
+	IsReloaded bool
 }
 
+// Maximum age of the configuration change applied by Stork to be used to
+// attribute the fetched configuration version to the Stork transaction.
+// Older changes are assumed to be already superseded.
+const configPendingChangeLifetime = 10 * time.Minute
+
+// Tolerance of the reload time comparison. The reload time is computed
+// from the number of seconds since the last reload returned by Kea, so it
+// may slightly differ between the fetches even if the daemon was not
+// reloaded.
+const daemonReloadTimeTolerance = 2 * time.Second
+
 // Get configuration from Kea daemon using ForwardToKeaOverHTTP function.
 // Return a config, its hash and an error if any.
 func GetConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon agentcomm.ControlledDaemon) (*keaconfig.Config, error) {
@@ -152,6 +170,20 @@ func getDaemonWithRefreshedState(ctx context.Context, agents agentcomm.Connected
 		}
 	}
 
+	// Fetch the differences between the running configuration and the
+	// configuration file found by the agent. The agents prior to this
+	// feature don't support it, so the failure doesn't make the daemon
+	// inactive. The previously fetched differences are kept in this case.
+	if daemon.KeaDaemon != nil {
+		drift, driftErr := agents.GetKeaConfigDrift(ctx, daemon)
+		if driftErr != nil {
+			log.WithError(driftErr).WithField("daemon", daemon.GetLabel()).
+				Debug("Cannot get differences between the running Kea configuration and the configuration file")
+		} else {
+			daemon.KeaDaemon.ConfigDrift = drift
+		}
+	}
+
 	return daemon, err
 }
 
@@ -181,13 +213,44 @@ func isDaemonConfigChanged(daemonOld, daemonNew *dbmodel.Daemon) bool {
 	return daemonOld.KeaDaemon.ConfigHash != daemonNew.KeaDaemon.ConfigHash
 }
 
+// Checks if the differences between the running configuration and the
+// configuration file have changed compared to the previous state. The time
+// of the comparison is ignored.
+func isDaemonConfigDriftChanged(daemonOld, daemonNew *dbmodel.Daemon) bool {
+	var oldDrift, newDrift *keaconfig.ConfigDrift
+	if daemonOld.KeaDaemon != nil {
+		oldDrift = daemonOld.KeaDaemon.ConfigDrift
+	}
+	if daemonNew.KeaDaemon != nil {
+		newDrift = daemonNew.KeaDaemon.ConfigDrift
+	}
+	if !oldDrift.IsDrifted() || !newDrift.IsDrifted() {
+		return oldDrift.IsDrifted() != newDrift.IsDrifted()
+	}
+	return oldDrift.ConfigPath != newDrift.ConfigPath ||
+		!reflect.DeepEqual(oldDrift.Entries, newDrift.Entries)
+}
+
+// Checks if the daemon has been restarted or has reloaded its configuration
+// between the fetches.
+func isDaemonReloaded(daemonOld, daemonNew *dbmodel.Daemon) bool {
+	if daemonOld.Uptime > daemonNew.Uptime {
+		return true
+	}
+	if daemonOld.ReloadedAt.IsZero() || daemonNew.ReloadedAt.IsZero() {
+		return false
+	}
+	return daemonNew.ReloadedAt.Sub(daemonOld.ReloadedAt) > daemonReloadTimeTolerance
+}
+
 // Detects changes in the daemon before and after the fetching state from Kea.
 // It raises events when a daemon changes its state between active and
 // inactive state. It also raises events about detected daemon restarts and when
 // configuration change was detected.
 func findChangesAndRaiseEvents(daemonOld, daemonNew *dbmodel.Daemon, err error) DaemonStateMeta {
 	meta := DaemonStateMeta{
-		IsConfigChanged: isDaemonConfigChanged(daemonOld, daemonNew),
+		IsConfigChanged:      isDaemonConfigChanged(daemonOld, daemonNew),
+		IsConfigDriftChanged: isDaemonConfigDriftChanged(daemonOld, daemonNew),
 	}
 
 	if daemonNew.ID == 0 {
@@ -197,6 +260,8 @@ func findChangesAndRaiseEvents(daemonOld, daemonNew *dbmodel.Daemon, err error)
 		return meta
 	}
 
+	meta.IsReloaded = isDaemonReloaded(daemonOld, daemonNew)
+
 	if daemonOld.Active && !daemonNew.Active {
 		// Kea daemon was not found in the response or it is inactive.
 		ev := eventcenter.CreateEvent(dbmodel.EvError, "{daemon} is unreachable", err, daemonOld)
@@ -323,6 +388,45 @@ func addOnCommitSubnetEvents(daemon *dbmodel.Daemon, addedSubnets []*dbmodel.Sub
 	}
 }
 
+// Stores the daemon's configuration as a new configuration version if it
+// differs from the most recent version. The version is attributed to the
+// Stork transaction if Stork has recently applied a change to the daemon's
+// configuration. Otherwise, it is attributed to the daemon's reload or to
+// an external change.
+func commitDaemonConfigVersion(tx *pg.Tx, daemon *dbmodel.Daemon, state DaemonStateMeta) error {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil
+	}
+	latest, err := dbmodel.GetLatestKeaConfigVersion(tx, daemon.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.ConfigHash == daemon.KeaDaemon.ConfigHash {
+		return nil
+	}
+	// The pending changes are consumed by the first configuration version
+	// fetched after applying them.
+	changes, err := dbmodel.DeleteKeaConfigPendingChanges(tx, daemon.ID)
+	if err != nil {
+		return err
+	}
+	version := &dbmodel.KeaConfigVersion{
+		DaemonID:   daemon.ID,
+		Config:     daemon.KeaDaemon.Config,
+		ConfigHash: daemon.KeaDaemon.ConfigHash,
+		Source:     dbmodel.KeaConfigVersionSourceExternal,
+	}
+	if len(changes) > 0 && time.Since(changes[len(changes)-1].CreatedAt) < configPendingChangeLifetime {
+		change := changes[len(changes)-1]
+		version.Source = dbmodel.KeaConfigVersionSourceTransaction
+		version.Operation = change.Operation
+		version.UserID = change.UserID
+	} else if state.IsReloaded {
+		version.Source = dbmodel.KeaConfigVersionSourceReload
+	}
+	return dbmodel.AddKeaConfigVersion(tx, version)
+}
+
 // Inserts or updates information about Kea daemons in the database. Next, it extracts
 // Kea's configurations and uses to either update or create new shared networks,
 // subnets and pools. Finally, the relations between the subnets and the Kea daemon
@@ -399,12 +503,23 @@ func CommitDaemonsIntoDB(db *dbops.PgDB, daemons []*dbmodel.Daemon, eventCenter
 				return err
 			}
 
+			// Store the client classes configured in the daemon.
+			if err = commitDaemonClientClasses(tx, daemon); err != nil {
+				return err
+			}
+
 			// Add subnet related events to the database.
 			addOnCommitSubnetEvents(daemon, addedSubnets, eventCenter)
 
 			if err = detectAndCommitServices(tx, daemon); err != nil {
 				return err
 			}
+
+			// Store the configuration in the history of the daemon's
+			// configurations.
+			if err = commitDaemonConfigVersion(tx, daemon, states[i]); err != nil {
+				return err
+			}
 		}
 
 		// Remove empty shared networks and orphaned subnets and hosts.
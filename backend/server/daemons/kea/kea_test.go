@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/datamodel/protocoltype"
@@ -419,6 +421,81 @@ func TestCommitDaemonIntoDB(t *testing.T) {
 	require.Equal(t, protocoltype.HTTPS, returned.AccessPoints[0].Protocol)
 }
 
+// Test that the client classes from the Kea configuration are stored in the
+// database when the daemon is committed.
+func TestCommitDaemonIntoDBClientClasses(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fec := &storktest.FakeEventCenter{}
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.DHCPv4, true, []*dbmodel.AccessPoint{
+		{
+			Type:     dbmodel.AccessPointControl,
+			Address:  "localhost",
+			Port:     1234,
+			Protocol: protocoltype.HTTP,
+		},
+	})
+	err = daemon.SetKeaConfigFromJSON([]byte(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('ALL')"
+				},
+				{
+					"name": "bar",
+					"next-server": "192.0.2.1"
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	daemons := []*dbmodel.Daemon{daemon}
+	states := []DaemonStateMeta{{IsConfigChanged: true}}
+	err = CommitDaemonsIntoDB(db, daemons, fec, states, lookup)
+	require.NoError(t, err)
+
+	classes, err := dbmodel.GetClientClassesByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+	require.Equal(t, "bar", classes[0].Name)
+	require.Equal(t, "192.0.2.1", *classes[0].KeaParameters.NextServer)
+	require.Equal(t, "foo", classes[1].Name)
+	require.Equal(t, "member('ALL')", classes[1].KeaParameters.GetTest())
+
+	// Remove one of the classes from the configuration.
+	err = daemon.SetKeaConfigFromJSON([]byte(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('ALL')"
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	err = CommitDaemonsIntoDB(db, daemons, fec, states, lookup)
+	require.NoError(t, err)
+
+	classes, err = dbmodel.GetClientClassesByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, classes, 1)
+	require.Equal(t, "foo", classes[0].Name)
+}
+
 // Test that the changed configuration is detected correctly.
 func TestIsDaemonConfigChanged(t *testing.T) {
 	t.Run("Missing both KeaDaemon", func(t *testing.T) {
@@ -475,3 +552,161 @@ func TestIsDaemonConfigChanged(t *testing.T) {
 		require.True(t, isDaemonConfigChanged(daemonOld, daemonNew))
 	})
 }
+
+// Test that the daemon's restart and reload are detected correctly.
+func TestIsDaemonReloaded(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Not reloaded", func(t *testing.T) {
+		daemonOld := &dbmodel.Daemon{Uptime: 100, ReloadedAt: now}
+		daemonNew := &dbmodel.Daemon{Uptime: 130, ReloadedAt: now.Add(time.Second)}
+		require.False(t, isDaemonReloaded(daemonOld, daemonNew))
+	})
+
+	t.Run("Restarted", func(t *testing.T) {
+		daemonOld := &dbmodel.Daemon{Uptime: 100, ReloadedAt: now}
+		daemonNew := &dbmodel.Daemon{Uptime: 10, ReloadedAt: now}
+		require.True(t, isDaemonReloaded(daemonOld, daemonNew))
+	})
+
+	t.Run("Reloaded", func(t *testing.T) {
+		daemonOld := &dbmodel.Daemon{Uptime: 100, ReloadedAt: now}
+		daemonNew := &dbmodel.Daemon{Uptime: 130, ReloadedAt: now.Add(20 * time.Second)}
+		require.True(t, isDaemonReloaded(daemonOld, daemonNew))
+	})
+
+	t.Run("Unknown reload time", func(t *testing.T) {
+		daemonOld := &dbmodel.Daemon{Uptime: 100}
+		daemonNew := &dbmodel.Daemon{Uptime: 130, ReloadedAt: now}
+		require.False(t, isDaemonReloaded(daemonOld, daemonNew))
+	})
+}
+
+// Test that the distinct daemon configurations are stored as versions
+// and attributed to their sources.
+func TestCommitDaemonIntoDBConfigVersions(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fec := &storktest.FakeEventCenter{}
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	user := &dbmodel.SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err = dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.DHCPv4, true, []*dbmodel.AccessPoint{
+		{
+			Type:     dbmodel.AccessPointControl,
+			Address:  "localhost",
+			Port:     1234,
+			Protocol: protocoltype.HTTP,
+		},
+	})
+	err = daemon.SetKeaConfigFromJSON([]byte(`{ "Dhcp4": { "valid-lifetime": 1000 } }`))
+	require.NoError(t, err)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	daemons := []*dbmodel.Daemon{daemon}
+	states := []DaemonStateMeta{{IsConfigChanged: true}}
+	err = CommitDaemonsIntoDB(db, daemons, fec, states, lookup)
+	require.NoError(t, err)
+
+	// The configuration applied by Stork.
+	err = dbmodel.AddKeaConfigPendingChange(db, &dbmodel.KeaConfigPendingChange{
+		DaemonID:  daemon.ID,
+		Operation: dbmodel.ConfigOperationKeaGlobalParametersUpdate,
+		UserID:    &user.ID,
+	})
+	require.NoError(t, err)
+	err = daemon.SetKeaConfigFromJSON([]byte(`{ "Dhcp4": { "valid-lifetime": 2000 } }`))
+	require.NoError(t, err)
+	err = CommitDaemonsIntoDB(db, daemons, fec, states, lookup)
+	require.NoError(t, err)
+
+	// The configuration reloaded by the daemon.
+	err = daemon.SetKeaConfigFromJSON([]byte(`{ "Dhcp4": { "valid-lifetime": 3000 } }`))
+	require.NoError(t, err)
+	states = []DaemonStateMeta{{IsConfigChanged: true, IsReloaded: true}}
+	err = CommitDaemonsIntoDB(db, daemons, fec, states, lookup)
+	require.NoError(t, err)
+
+	// The same configuration doesn't produce a new version.
+	err = CommitDaemonsIntoDB(db, daemons, fec, states, lookup)
+	require.NoError(t, err)
+
+	versions, total, err := dbmodel.GetKeaConfigVersionsByPage(db, daemon.ID, 0, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, versions, 3)
+
+	require.EqualValues(t, 3, versions[0].Version)
+	require.Equal(t, dbmodel.KeaConfigVersionSourceReload, versions[0].Source)
+
+	require.EqualValues(t, 2, versions[1].Version)
+	require.Equal(t, dbmodel.KeaConfigVersionSourceTransaction, versions[1].Source)
+	require.Equal(t, dbmodel.ConfigOperationKeaGlobalParametersUpdate, versions[1].Operation)
+	require.NotNil(t, versions[1].UserID)
+	require.Equal(t, user.ID, *versions[1].UserID)
+
+	require.EqualValues(t, 1, versions[2].Version)
+	require.Equal(t, dbmodel.KeaConfigVersionSourceExternal, versions[2].Source)
+	require.Nil(t, versions[2].UserID)
+}
+
+// Test that the change of the drift between the running configuration
+// and the configuration file is detected.
+func TestIsDaemonConfigDriftChanged(t *testing.T) {
+	newDaemon := func(drift *keaconfig.ConfigDrift) *dbmodel.Daemon {
+		return &dbmodel.Daemon{
+			KeaDaemon: &dbmodel.KeaDaemon{
+				ConfigDrift: drift,
+			},
+		}
+	}
+	entries := []keaconfig.ConfigDiffEntry{{
+		Path:      "/Dhcp4/valid-lifetime",
+		Operation: keaconfig.ConfigDiffOperationModified,
+		OldValue:  3600,
+		NewValue:  7200,
+	}}
+
+	// No drift.
+	require.False(t, isDaemonConfigDriftChanged(newDaemon(nil), newDaemon(nil)))
+	require.False(t, isDaemonConfigDriftChanged(newDaemon(nil), newDaemon(&keaconfig.ConfigDrift{})))
+
+	// Drift appeared or disappeared.
+	require.True(t, isDaemonConfigDriftChanged(newDaemon(nil), newDaemon(&keaconfig.ConfigDrift{Entries: entries})))
+	require.True(t, isDaemonConfigDriftChanged(newDaemon(&keaconfig.ConfigDrift{Entries: entries}), newDaemon(&keaconfig.ConfigDrift{})))
+
+	// The same drift checked at different times.
+	require.False(t, isDaemonConfigDriftChanged(
+		newDaemon(&keaconfig.ConfigDrift{CheckedAt: time.Unix(1000, 0), Entries: entries}),
+		newDaemon(&keaconfig.ConfigDrift{CheckedAt: time.Unix(2000, 0), Entries: entries}),
+	))
+
+	// Different drift.
+	require.True(t, isDaemonConfigDriftChanged(
+		newDaemon(&keaconfig.ConfigDrift{Entries: entries}),
+		newDaemon(&keaconfig.ConfigDrift{Entries: entries[:0:0]}),
+	))
+	require.True(t, isDaemonConfigDriftChanged(
+		newDaemon(&keaconfig.ConfigDrift{Entries: entries}),
+		newDaemon(&keaconfig.ConfigDrift{Entries: []keaconfig.ConfigDiffEntry{{
+			Path:      "/Dhcp4/valid-lifetime",
+			Operation: keaconfig.ConfigDiffOperationModified,
+			OldValue:  3600,
+			NewValue:  1800,
+		}}}),
+	))
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/go-pg/pg/v10"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"isc.org/stork/server/agentcomm"
 	storkutil "isc.org/stork/util"
@@ -21,6 +23,10 @@ import (
 	dbmodel "isc.org/stork/server/database/model"
 )
 
+// Name of the setting holding the number of days for which the lease
+// lifecycle events are kept. Zero disables removing the old events.
+const LeaseHistoryRetentionSetting = "lease_history_retention"
+
 // Leases puller is responsible for fetching lease data from Kea via the agents.
 type LeasesPuller struct {
 	*agentcomm.PeriodicPuller
@@ -191,6 +197,9 @@ func (puller *LeasesPuller) pullLeases() error {
 	for _, daemon := range selectedDaemons {
 		wg.Go(func() {
 			err := puller.getLeasesFromDaemon(daemon)
+			if err == nil {
+				err = puller.getLeaseEventsFromDaemon(daemon)
+			}
 			if err != nil {
 				errorPipe <- errors.WithMessagef(err, "could not retrieve leases from daemon %d", daemon.ID)
 			} else {
@@ -212,6 +221,9 @@ func (puller *LeasesPuller) pullLeases() error {
 		}
 	}
 	log.Debug("lease: results processed")
+	if err := puller.deleteOldLeaseEvents(); err != nil {
+		errors = append(errors, err)
+	}
 	elapsed := time.Since(beginTimestamp)
 	log.WithField("successful_daemons", selectedDaemonsCount-len(errors)).
 		WithField("total_daemons", selectedDaemonsCount).
@@ -281,3 +293,68 @@ func (puller *LeasesPuller) getLeasesFromDaemon(daemon *dbmodel.Daemon) error {
 		return nil
 	})
 }
+
+// Fetches the lease lifecycle events observed by the agent since the last
+// pull and stores them in the database. The events of the subnets unknown
+// to Stork are stored without the subnet.
+func (puller *LeasesPuller) getLeaseEventsFromDaemon(daemon *dbmodel.Daemon) error {
+	if daemon.KeaDaemon == nil || !daemon.Active || !daemon.Name.IsDHCP() {
+		return nil
+	}
+	minObservedAt, err := dbmodel.GetLastLeaseEventObservedAt(puller.db, daemon.ID)
+	if err != nil {
+		return err
+	}
+	// Cache the subnet IDs to avoid querying the database for each event.
+	subnetIDs := make(map[uint32]int64)
+	var events []*dbmodel.LeaseEvent
+	for response, err := range puller.Agents.ReceiveKeaLeaseEvents(context.Background(), daemon, minObservedAt) {
+		switch {
+		case status.Code(err) == codes.Unimplemented:
+			// The agent is too old to support the lease history.
+			log.WithField("daemon_id", daemon.ID).Debug("Agent does not support lease events")
+			return nil
+		case err != nil:
+			return err
+		case response == nil || response.Event == nil || response.Event.Lease == nil:
+			return errors.New("unexpected nil in response stream of Kea lease events")
+		}
+		localSubnetID := response.Event.Lease.SubnetID
+		subnetID, ok := subnetIDs[localSubnetID]
+		if !ok {
+			id, err := dbmodel.GetSubnetIDByDaemonIDAndLocalID(puller.db, daemon.ID, localSubnetID)
+			if err != nil {
+				return err
+			}
+			if id != nil {
+				subnetID = *id
+			}
+			subnetIDs[localSubnetID] = subnetID
+		}
+		event := dbmodel.NewLeaseEventFromGRPC(response.Event, daemon.ID, subnetID)
+		if event == nil {
+			return errors.New("unable to convert lease event from gRPC format to model format; data is missing or invalid")
+		}
+		events = append(events, event)
+	}
+	return dbmodel.AddLeaseEvents(puller.db, events)
+}
+
+// Deletes the lease events older than the configured retention time.
+func (puller *LeasesPuller) deleteOldLeaseEvents() error {
+	retention, err := dbmodel.GetSettingInt(puller.db, LeaseHistoryRetentionSetting)
+	if err != nil {
+		return err
+	}
+	if retention <= 0 {
+		return nil
+	}
+	count, err := dbmodel.DeleteLeaseEventsBefore(puller.db, storkutil.UTCNow().AddDate(0, 0, -int(retention)))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.WithField("count", count).Debug("Deleted old lease events")
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package kea
 import (
 	"encoding/json"
 	"fmt"
+	"iter"
 	"slices"
 	"testing"
 
@@ -11,6 +12,7 @@ import (
 	"go.uber.org/mock/gomock"
 	agentapi "isc.org/stork/api"
 	keaconfig "isc.org/stork/daemoncfg/kea"
+	keadata "isc.org/stork/daemondata/kea"
 	"isc.org/stork/datamodel/daemonname"
 	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
@@ -21,6 +23,11 @@ import (
 
 //go:generate mockgen -package=kea -destination=connectedagentsmock_test.go -source=../../agentcomm/agentcomm.go ConnectedAgents
 
+// Returns an empty stream of the lease events.
+func noMockLeaseEvents() iter.Seq2[*agentapi.ReceiveKeaLeaseEventsRsp, error] {
+	return storkutil.ZipPairs([]*agentapi.ReceiveKeaLeaseEventsRsp{}, []error{})
+}
+
 // Return a fake daemon that can be filtered by filterDaemons in the tests below.
 func mockFilterableDaemon(name daemonname.Name, id, machineID int64) *dbmodel.Daemon {
 	return &dbmodel.Daemon{
@@ -627,6 +634,7 @@ func TestLeasePullingBasic(t *testing.T) {
 	)
 	fa := NewMockConnectedAgents(ctrl)
 	fa.EXPECT().ReceiveKeaLeases(gomock.Any(), gomock.Any(), gomock.Eq(uint64(0))).Return(mockLeases)
+	fa.EXPECT().ReceiveKeaLeaseEvents(gomock.Any(), gomock.Any(), gomock.Eq(int64(0))).Return(noMockLeaseEvents())
 
 	puller, err := NewLeasesPuller(db, fa)
 	require.NoError(t, err)
@@ -716,6 +724,7 @@ func TestPullLeasesDoesntDuplicateLeasesWhenFetchingFromDHCPv4Andv6OnOneMachine(
 			return daemon.ID == daemon6.ID
 		}),
 		gomock.Eq(uint64(0))).Return(emptyMockLeases).AnyTimes()
+	fa.EXPECT().ReceiveKeaLeaseEvents(gomock.Any(), gomock.Any(), gomock.Any()).Return(noMockLeaseEvents()).AnyTimes()
 
 	puller, err := NewLeasesPuller(db, fa)
 	require.NoError(t, err)
@@ -1285,3 +1294,95 @@ func TestGetLeasesFromDaemonReadsMaxCLTTFromDatabase(t *testing.T) {
 	require.NoError(t, errFirst)
 	require.NoError(t, errSecond)
 }
+
+// Test that the lease events are fetched from the agent after the most recent
+// stored event and stored in the database without duplicates.
+func TestGetLeaseEventsFromDaemon(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_ = dbmodel.InitializeSettings(db, 0)
+
+	daemonServer, _ := dbmodeltest.NewKeaDHCPv4Server(db)
+	err := daemonServer.Configure(`{ "Dhcp4": {
+		"lease-database": {
+			"type": "memfile",
+			"name": "/var/lib/kea/kea-leases4.csv"
+		},
+		"subnet4": [
+			{ "id": 67, "subnet": "192.168.1.0/24" }
+		]
+	}}`)
+	require.NoError(t, err)
+	daemon, err := daemonServer.GetDaemon()
+	require.NoError(t, err)
+
+	testHelperAddDemoSubnet(t, db, daemon, "192.168.1.0/24")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assigned := &agentapi.ReceiveKeaLeaseEventsRsp{
+		Event: &agentapi.LeaseEvent{
+			Type: agentapi.LeaseEvent_ASSIGNED,
+			Lease: &agentapi.Lease{
+				IpAddress:     "192.168.1.179",
+				HwAddress:     "00:01:02:03:04:04",
+				Cltt:          1000,
+				ValidLifetime: 3600,
+				Family:        4,
+				SubnetID:      67,
+			},
+			OccurredAt: 1000,
+			ObservedAt: 5000,
+		},
+	}
+	expired := &agentapi.ReceiveKeaLeaseEventsRsp{
+		Event: &agentapi.LeaseEvent{
+			Type: agentapi.LeaseEvent_EXPIRED,
+			Lease: &agentapi.Lease{
+				IpAddress:     "192.168.1.179",
+				HwAddress:     "00:01:02:03:04:04",
+				Cltt:          1000,
+				ValidLifetime: 3600,
+				Family:        4,
+				// Subnet unknown to Stork.
+				SubnetID: 68,
+				State:    2,
+			},
+			OccurredAt: 4600,
+			ObservedAt: 6000,
+		},
+	}
+	fa := NewMockConnectedAgents(ctrl)
+	fa.EXPECT().ReceiveKeaLeaseEvents(gomock.Any(), gomock.Any(), gomock.Eq(int64(0))).
+		Return(storkutil.ZipPairs([]*agentapi.ReceiveKeaLeaseEventsRsp{assigned}, []error{nil}))
+	// The agent was restarted and sends the same event again.
+	fa.EXPECT().ReceiveKeaLeaseEvents(gomock.Any(), gomock.Any(), gomock.Eq(int64(5000))).
+		Return(storkutil.ZipPairs([]*agentapi.ReceiveKeaLeaseEventsRsp{assigned, expired}, []error{nil, nil}))
+
+	puller, err := NewLeasesPuller(db, fa)
+	require.NoError(t, err)
+	defer puller.Shutdown()
+
+	// Act
+	errFirst := puller.getLeaseEventsFromDaemon(daemon)
+	errSecond := puller.getLeaseEventsFromDaemon(daemon)
+
+	// Assert
+	require.NoError(t, errFirst)
+	require.NoError(t, errSecond)
+
+	events, total, err := dbmodel.GetLeaseEventsByPage(db, 0, 10, dbmodel.LeaseEventsByPageFilters{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Equal(t, keadata.LeaseEventExpired, events[0].EventType)
+	require.Zero(t, events[0].SubnetID)
+	require.Equal(t, keadata.LeaseEventAssigned, events[1].EventType)
+	require.NotZero(t, events[1].SubnetID)
+
+	observedAt, err := dbmodel.GetLastLeaseEventObservedAt(db, daemon.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 6000, observedAt)
+}
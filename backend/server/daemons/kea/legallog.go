@@ -0,0 +1,78 @@
+package kea
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	keadata "isc.org/stork/daemondata/kea"
+	"isc.org/stork/server/agentcomm"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// A legal log entry found in the legal log files of the daemon.
+type LegalLogEntry struct {
+	keadata.LegalLogEntry
+	Daemon *dbmodel.Daemon
+}
+
+// Returns the specified daemon and the daemons belonging to the same High
+// Availability services. The daemon must have the machine and access points
+// relations.
+func getDaemonWithHAPeers(dbi dbops.DBI, daemon *dbmodel.Daemon) ([]*dbmodel.Daemon, error) {
+	daemons := []*dbmodel.Daemon{daemon}
+	services, err := dbmodel.GetDetailedServicesByDaemonID(dbi, daemon.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		if service.HAService == nil {
+			continue
+		}
+		for _, peer := range service.Daemons {
+			if !slices.ContainsFunc(daemons, func(d *dbmodel.Daemon) bool { return d.ID == peer.ID }) {
+				daemons = append(daemons, peer)
+			}
+		}
+	}
+	return daemons, nil
+}
+
+// Searches the forensic (legal) log files written by the specified daemon
+// and its HA peers. The entries from all daemons are merged in the
+// chronological order and their number is limited by the filter. It returns
+// the daemons for which the search failed along with the found entries.
+func SearchLegalLog(ctx context.Context, dbi dbops.DBI, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, filter *agentcomm.LegalLogFilter) (entries []LegalLogEntry, erredDaemons []*dbmodel.Daemon, err error) {
+	daemons, err := getDaemonWithHAPeers(dbi, daemon)
+	if err != nil {
+		err = errors.WithMessagef(err, "failed to fetch HA peers of the daemon %d while searching legal log", daemon.ID)
+		return nil, nil, err
+	}
+
+	for _, d := range daemons {
+		for entry, err := range agents.SearchKeaLegalLog(ctx, d, filter) {
+			if err != nil {
+				log.WithError(err).WithField("daemon", d.GetLabel()).Error("Failed to search legal log of the Kea daemon")
+				erredDaemons = append(erredDaemons, d)
+				break
+			}
+			entries = append(entries, LegalLogEntry{
+				LegalLogEntry: *entry,
+				Daemon:        d,
+			})
+		}
+	}
+
+	// The entries of each daemon are already sorted. Merge them preserving
+	// the daemons order for the entries logged at the same time.
+	slices.SortStableFunc(entries, func(a, b LegalLogEntry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	if filter.Limit > 0 && int64(len(entries)) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, erredDaemons, nil
+}
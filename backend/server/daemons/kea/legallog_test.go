@@ -0,0 +1,136 @@
+package kea
+
+import (
+	"context"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	keadata "isc.org/stork/daemondata/kea"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Returns an iterator over the legal log entries with the specified
+// timestamps (Unix seconds).
+func legalLogEntriesIterator(timestamps ...int64) iter.Seq2[*keadata.LegalLogEntry, error] {
+	return func(yield func(*keadata.LegalLogEntry, error) bool) {
+		for _, timestamp := range timestamps {
+			entry := &keadata.LegalLogEntry{
+				Timestamp: time.Unix(timestamp, 0).UTC(),
+				Action:    "assigned",
+				Address:   "192.0.2.1",
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Creates two DHCPv4 daemons belonging to the same HA service.
+func addLegalLogHAPair(t *testing.T, db *pg.DB) (*dbmodel.Daemon, *dbmodel.Daemon) {
+	var daemons []*dbmodel.Daemon
+	for range 2 {
+		server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+		require.NoError(t, err)
+		daemon, err := server.GetDaemon()
+		require.NoError(t, err)
+		daemons = append(daemons, daemon)
+	}
+	service := &dbmodel.Service{
+		HAService: &dbmodel.BaseHAService{
+			HAType:       "dhcp4",
+			HAMode:       "hot-standby",
+			Relationship: "server1",
+			PrimaryID:    daemons[0].ID,
+			SecondaryID:  daemons[1].ID,
+		},
+	}
+	err := dbmodel.AddService(db, service)
+	require.NoError(t, err)
+	for _, daemon := range daemons {
+		err = dbmodel.AddDaemonToService(db, service.ID, daemon)
+		require.NoError(t, err)
+	}
+	return daemons[0], daemons[1]
+}
+
+// Test that the legal log entries of the HA peers are merged in the
+// chronological order.
+func TestSearchLegalLog(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	primary, secondary := addLegalLogHAPair(t, db)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	agents := NewMockConnectedAgents(ctrl)
+	filter := &agentcomm.LegalLogFilter{Address: "192.0.2.1", Limit: 4}
+	agents.EXPECT().SearchKeaLegalLog(gomock.Any(), gomock.Any(), filter).
+		DoAndReturn(func(ctx context.Context, daemon agentcomm.ControlledDaemon, filter *agentcomm.LegalLogFilter) iter.Seq2[*keadata.LegalLogEntry, error] {
+			if daemon.GetID() == primary.ID {
+				return legalLogEntriesIterator(10, 30, 50)
+			}
+			return legalLogEntriesIterator(20, 30, 40)
+		}).Times(2)
+
+	// Act
+	entries, erredDaemons, err := SearchLegalLog(context.Background(), db, agents, primary, filter)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, erredDaemons)
+	require.Len(t, entries, 4)
+	require.EqualValues(t, 10, entries[0].Timestamp.Unix())
+	require.Equal(t, primary.ID, entries[0].Daemon.ID)
+	require.EqualValues(t, 20, entries[1].Timestamp.Unix())
+	require.Equal(t, secondary.ID, entries[1].Daemon.ID)
+	// The entries logged at the same time are ordered by daemons.
+	require.EqualValues(t, 30, entries[2].Timestamp.Unix())
+	require.Equal(t, primary.ID, entries[2].Daemon.ID)
+	require.EqualValues(t, 30, entries[3].Timestamp.Unix())
+	require.Equal(t, secondary.ID, entries[3].Daemon.ID)
+}
+
+// Test that the daemons for which the search failed are returned along
+// with the entries found for the other daemons.
+func TestSearchLegalLogErredDaemon(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	primary, secondary := addLegalLogHAPair(t, db)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	agents := NewMockConnectedAgents(ctrl)
+	agents.EXPECT().SearchKeaLegalLog(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, daemon agentcomm.ControlledDaemon, filter *agentcomm.LegalLogFilter) iter.Seq2[*keadata.LegalLogEntry, error] {
+			if daemon.GetID() == primary.ID {
+				return legalLogEntriesIterator(10)
+			}
+			return func(yield func(*keadata.LegalLogEntry, error) bool) {
+				yield(nil, errors.New("legal log not configured"))
+			}
+		}).Times(2)
+
+	// Act
+	entries, erredDaemons, err := SearchLegalLog(context.Background(), db, agents, primary, &agentcomm.LegalLogFilter{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, primary.ID, entries[0].Daemon.ID)
+	require.Len(t, erredDaemons, 1)
+	require.Equal(t, secondary.ID, erredDaemons[0].ID)
+}
@@ -0,0 +1,270 @@
+package kea
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
+)
+
+// Maximum duration of the temporary log level change.
+const MaxLogLevelChangeDuration = 24 * time.Hour
+
+// Maximum debug level supported by Kea.
+const maxLogLevelDebugLevel = 99
+
+// Interval at which the reverter checks if there are log level changes
+// which expired.
+const logLevelReverterInterval = 10 * time.Second
+
+// Severities supported by the Kea loggers.
+var logLevelSeverities = []string{"FATAL", "ERROR", "WARN", "INFO", "DEBUG", "NONE"}
+
+// Serializes the log level changes and reverts. Each of them fetches the
+// daemon's configuration and sends it back with a modified logger, so
+// they must not interleave.
+var logLevelMutex sync.Mutex
+
+// Checks if the severity and the debug level are valid. The debug level
+// is only allowed for the DEBUG severity.
+func ValidateLogLevel(severity string, debugLevel int) error {
+	if !slices.Contains(logLevelSeverities, severity) {
+		return errors.Errorf("invalid severity %s; it must be one of: %v", severity, logLevelSeverities)
+	}
+	if debugLevel < 0 || debugLevel > maxLogLevelDebugLevel {
+		return errors.Errorf("invalid debug level %d; it must be between 0 and %d", debugLevel, maxLogLevelDebugLevel)
+	}
+	if debugLevel != 0 && severity != "DEBUG" {
+		return errors.Errorf("debug level can only be specified for the DEBUG severity")
+	}
+	return nil
+}
+
+// Sends the configuration to the daemon with the config-set command. The
+// configuration is not written to disk, so the daemon uses the original
+// configuration after restart.
+func setConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, config *keaconfig.Config) error {
+	command := keactrl.NewCommandConfigSet(config, daemon.Name)
+	var response keactrl.Response
+	result, err := agents.ForwardToKeaOverHTTP(ctx, daemon, []keactrl.SerializableCommand{command}, &response)
+	if err != nil {
+		return errors.WithMessage(err, "problem communicating with Stork agent")
+	}
+	if err = result.GetFirstError(); err != nil {
+		return errors.WithMessage(err, "problem with config-set response")
+	}
+	if err = response.GetError(); err != nil {
+		return errors.WithMessagef(err, "%s command failed", command.GetCommand())
+	}
+	return nil
+}
+
+// Fetches the current configuration of the daemon, modifies it and sends
+// it back to the daemon.
+func modifyConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, modify func(config *keaconfig.Config) error) error {
+	config, err := GetConfig(ctx, agents, daemon)
+	if err != nil {
+		return err
+	}
+	if err = modify(config); err != nil {
+		return err
+	}
+	return setConfig(ctx, agents, daemon, config)
+}
+
+// Temporarily changes the severity and debug level of the logger in the
+// specified Kea daemon. The logger is added to the configuration if it is
+// not configured. The change is applied with config-set and is reverted
+// when it expires after the specified duration. If the logger has been
+// already changed, the change is updated and its expiration time is
+// extended. The original severity is preserved, so it is restored when
+// the change expires.
+func SetTemporaryLogLevel(ctx context.Context, db *pg.DB, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon, loggerName, severity string, debugLevel int, duration time.Duration, userID *int64) (*dbmodel.KeaLogLevelChange, error) {
+	if err := ValidateLogLevel(severity, debugLevel); err != nil {
+		return nil, err
+	}
+	if duration <= 0 || duration > MaxLogLevelChangeDuration {
+		return nil, errors.Errorf("invalid duration %s; it must be positive and not longer than %s", duration, MaxLogLevelChangeDuration)
+	}
+
+	logLevelMutex.Lock()
+	defer logLevelMutex.Unlock()
+
+	change, err := dbmodel.GetKeaLogLevelChangeByLogger(db, daemon.ID, loggerName)
+	if err != nil {
+		return nil, err
+	}
+	now := storkutil.UTCNow()
+	if change != nil {
+		change.Severity = severity
+		change.DebugLevel = debugLevel
+		change.ExpiresAt = now.Add(duration)
+		change.UserID = userID
+		err = modifyConfig(ctx, agents, daemon, func(config *keaconfig.Config) error {
+			_, err := config.SetLoggerSeverity(loggerName, severity, debugLevel)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = dbmodel.UpdateKeaLogLevelChange(db, change)
+		return change, err
+	}
+
+	change = &dbmodel.KeaLogLevelChange{
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(duration),
+		DaemonID:   daemon.ID,
+		Daemon:     daemon,
+		UserID:     userID,
+		LoggerName: loggerName,
+		Severity:   severity,
+		DebugLevel: debugLevel,
+	}
+	config, err := GetConfig(ctx, agents, daemon)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := config.SetLoggerSeverity(loggerName, severity, debugLevel)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		change.OriginalSeverity = &previous.Severity
+		change.OriginalDebugLevel = previous.DebugLevel
+	}
+	// Remember the change before applying it, so it is never left
+	// unreverted when the server stops right after applying it.
+	if err = dbmodel.AddKeaLogLevelChange(db, change); err != nil {
+		return nil, err
+	}
+	if err = setConfig(ctx, agents, daemon, config); err != nil {
+		if deleteErr := dbmodel.DeleteKeaLogLevelChange(db, change.ID); deleteErr != nil {
+			log.WithError(deleteErr).Error("Failed to delete the log level change which could not be applied")
+		}
+		return nil, err
+	}
+	return change, nil
+}
+
+// Reverts the temporary log level change. It restores the original severity
+// and debug level of the logger or removes the logger if it was not
+// configured before the change. The change is removed from the database
+// when it is successfully reverted.
+func RevertTemporaryLogLevel(ctx context.Context, dbi dbops.DBI, agents agentcomm.ConnectedAgents, change *dbmodel.KeaLogLevelChange) error {
+	if change.Daemon == nil {
+		return errors.Errorf("daemon not fetched for the log level change %d", change.ID)
+	}
+
+	logLevelMutex.Lock()
+	defer logLevelMutex.Unlock()
+
+	err := modifyConfig(ctx, agents, change.Daemon, func(config *keaconfig.Config) error {
+		if change.OriginalSeverity == nil {
+			return config.DeleteLogger(change.LoggerName)
+		}
+		_, err := config.SetLoggerSeverity(change.LoggerName, *change.OriginalSeverity, change.OriginalDebugLevel)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return dbmodel.DeleteKeaLogLevelChange(dbi, change.ID)
+}
+
+// Returns the description of the severity and the debug level for the events.
+func getLogLevelDescription(severity string, debugLevel int) string {
+	if severity == "DEBUG" {
+		return fmt.Sprintf("%s (debug level %d)", severity, debugLevel)
+	}
+	return severity
+}
+
+// Returns the description of the log level after the change for the events.
+func GetLogLevelChangeDescription(change *dbmodel.KeaLogLevelChange) string {
+	return getLogLevelDescription(change.Severity, change.DebugLevel)
+}
+
+// Returns the description of the log level restored by reverting the
+// change for the events.
+func GetLogLevelRevertDescription(change *dbmodel.KeaLogLevelChange) string {
+	if change.OriginalSeverity == nil {
+		return "the default severity"
+	}
+	return getLogLevelDescription(*change.OriginalSeverity, change.OriginalDebugLevel)
+}
+
+// Periodically reverts the temporary log level changes which expired. It
+// also reverts the changes made before the server started because their
+// expiration could not be tracked while the server was not running.
+type LogLevelReverter struct {
+	*storkutil.PeriodicExecutor
+	db          *pg.DB
+	agents      agentcomm.ConnectedAgents
+	eventCenter eventcenter.EventCenter
+	startedAt   time.Time
+}
+
+// Creates an instance of the reverter of the temporary log level changes.
+func NewLogLevelReverter(db *pg.DB, agents agentcomm.ConnectedAgents, eventCenter eventcenter.EventCenter) (*LogLevelReverter, error) {
+	reverter := &LogLevelReverter{
+		db:          db,
+		agents:      agents,
+		eventCenter: eventCenter,
+		startedAt:   storkutil.UTCNow(),
+	}
+	executor, err := storkutil.NewPeriodicExecutor("Kea Log Level Reverter",
+		reverter.revertExpired,
+		func() (time.Duration, error) {
+			return logLevelReverterInterval, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	reverter.PeriodicExecutor = executor
+	return reverter, nil
+}
+
+// Reverts the expired log level changes. The changes which could not be
+// reverted are retried in the next iteration.
+func (reverter *LogLevelReverter) revertExpired() error {
+	changes, err := dbmodel.GetExpiredKeaLogLevelChanges(reverter.db, storkutil.UTCNow(), reverter.startedAt)
+	if err != nil {
+		return err
+	}
+	for i := range changes {
+		change := &changes[i]
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := RevertTemporaryLogLevel(ctx, reverter.db, reverter.agents, change)
+		cancel()
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"daemon": change.Daemon.GetLabel(),
+				"logger": change.LoggerName,
+			}).Warn("Failed to revert the temporary log level change; it will be retried")
+			continue
+		}
+		reason := "expired"
+		if change.CreatedAt.Before(reverter.startedAt) {
+			reason = "was made before Stork Server restart"
+		}
+		reverter.eventCenter.AddInfoEvent(
+			fmt.Sprintf("reverted logger %s in {daemon} to %s because the temporary change to %s %s",
+				change.LoggerName, GetLogLevelRevertDescription(change), GetLogLevelChangeDescription(change), reason),
+			change.Daemon)
+	}
+	return nil
+}
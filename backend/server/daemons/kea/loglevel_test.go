@@ -0,0 +1,299 @@
+package kea
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns a mock function responding to the config-get command with the
+// configuration including the kea-dhcp4 logger.
+func mockLogLevelConfigGet(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	response := cmdResponses[0].(*keactrl.Response)
+	*response = keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseSuccess,
+		},
+		Arguments: json.RawMessage(`{
+			"Dhcp4": {
+				"loggers": [
+					{
+						"name": "kea-dhcp4",
+						"output-options": [ { "output": "stdout" } ],
+						"severity": "INFO"
+					}
+				]
+			},
+			"hash": "1234"
+		}`),
+	}
+}
+
+// Mock function responding to the config-set command with success.
+func mockLogLevelConfigSet(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	response := cmdResponses[0].(*keactrl.Response)
+	*response = keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseSuccess,
+		},
+	}
+}
+
+// Mock function responding to the config-set command with an error.
+func mockLogLevelConfigSetError(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	response := cmdResponses[0].(*keactrl.Response)
+	*response = keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseError,
+			Text:   "configuration rejected",
+		},
+	}
+}
+
+// Returns the loggers sent to the daemon in the config-set command.
+func getConfigSetLoggers(t *testing.T, command keactrl.SerializableCommand) []map[string]any {
+	require.EqualValues(t, keactrl.ConfigSet, command.GetCommand())
+	marshalled, err := command.Marshal()
+	require.NoError(t, err)
+	var parsed struct {
+		Arguments struct {
+			Dhcp4 struct {
+				Loggers []map[string]any
+			}
+			Hash *string
+		}
+	}
+	err = json.Unmarshal(marshalled, &parsed)
+	require.NoError(t, err)
+	// The hash must not be sent back.
+	require.Nil(t, parsed.Arguments.Hash)
+	return parsed.Arguments.Dhcp4.Loggers
+}
+
+// Test validating the severity and the debug level.
+func TestValidateLogLevel(t *testing.T) {
+	require.NoError(t, ValidateLogLevel("DEBUG", 99))
+	require.NoError(t, ValidateLogLevel("WARN", 0))
+	require.Error(t, ValidateLogLevel("debug", 0))
+	require.Error(t, ValidateLogLevel("DEBUG", 100))
+	require.Error(t, ValidateLogLevel("DEBUG", -1))
+	require.Error(t, ValidateLogLevel("INFO", 10))
+}
+
+// Test the descriptions of the log level changes used in the events.
+func TestGetLogLevelChangeDescriptions(t *testing.T) {
+	severity := "INFO"
+	change := &dbmodel.KeaLogLevelChange{
+		Severity:         "DEBUG",
+		DebugLevel:       99,
+		OriginalSeverity: &severity,
+	}
+	require.Equal(t, "DEBUG (debug level 99)", GetLogLevelChangeDescription(change))
+	require.Equal(t, "INFO", GetLogLevelRevertDescription(change))
+
+	change.OriginalSeverity = nil
+	require.Equal(t, "the default severity", GetLogLevelRevertDescription(change))
+}
+
+// Test that the logger severity is temporarily changed and reverted.
+func TestSetAndRevertTemporaryLogLevel(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigGet, mockLogLevelConfigSet, mockLogLevelConfigGet, mockLogLevelConfigSet)
+
+	change, err := SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4", "DEBUG", 99, time.Hour, nil)
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	require.Equal(t, "INFO", *change.OriginalSeverity)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	require.EqualValues(t, keactrl.ConfigGet, fa.RecordedCommands[0].GetCommand())
+	loggers := getConfigSetLoggers(t, fa.RecordedCommands[1])
+	require.Len(t, loggers, 1)
+	require.Equal(t, "DEBUG", loggers[0]["severity"])
+	require.EqualValues(t, 99, loggers[0]["debuglevel"])
+	require.NotNil(t, loggers[0]["output-options"])
+
+	changes, err := dbmodel.GetKeaLogLevelChanges(db, &daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	err = RevertTemporaryLogLevel(context.Background(), db, fa, &changes[0])
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 4)
+	loggers = getConfigSetLoggers(t, fa.RecordedCommands[3])
+	require.Len(t, loggers, 1)
+	require.Equal(t, "INFO", loggers[0]["severity"])
+	require.EqualValues(t, 0, loggers[0]["debuglevel"])
+
+	changes, err = dbmodel.GetKeaLogLevelChanges(db, &daemon.ID)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+// Test that the logger which is not configured is added and then removed.
+func TestSetAndRevertTemporaryLogLevelNewLogger(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigGet, mockLogLevelConfigSet, mockLogLevelConfigGet, mockLogLevelConfigSet)
+
+	change, err := SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4.packets", "DEBUG", 50, time.Hour, nil)
+	require.NoError(t, err)
+	require.Nil(t, change.OriginalSeverity)
+
+	loggers := getConfigSetLoggers(t, fa.RecordedCommands[1])
+	require.Len(t, loggers, 2)
+	require.Equal(t, "kea-dhcp4.packets", loggers[1]["name"])
+	require.NotNil(t, loggers[1]["output-options"])
+
+	change, err = dbmodel.GetKeaLogLevelChangeByID(db, change.ID)
+	require.NoError(t, err)
+	err = RevertTemporaryLogLevel(context.Background(), db, fa, change)
+	require.NoError(t, err)
+
+	loggers = getConfigSetLoggers(t, fa.RecordedCommands[3])
+	require.Len(t, loggers, 1)
+	require.Equal(t, "kea-dhcp4", loggers[0]["name"])
+}
+
+// Test that changing the already changed logger preserves the original
+// severity and extends the expiration time.
+func TestSetTemporaryLogLevelTwice(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigGet, mockLogLevelConfigSet, mockLogLevelConfigGet, mockLogLevelConfigSet)
+
+	first, err := SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4", "DEBUG", 10, time.Minute, nil)
+	require.NoError(t, err)
+	second, err := SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4", "DEBUG", 99, time.Hour, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, first.ID, second.ID)
+	require.True(t, second.ExpiresAt.After(first.ExpiresAt))
+
+	change, err := dbmodel.GetKeaLogLevelChangeByID(db, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "INFO", *change.OriginalSeverity)
+	require.Equal(t, 99, change.DebugLevel)
+}
+
+// Test that the change is not recorded when the daemon rejects it.
+func TestSetTemporaryLogLevelError(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigGet, mockLogLevelConfigSetError)
+
+	_, err = SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4", "DEBUG", 99, time.Hour, nil)
+	require.ErrorContains(t, err, "configuration rejected")
+
+	changes, err := dbmodel.GetKeaLogLevelChanges(db, nil)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Invalid parameters are rejected without contacting the daemon.
+	_, err = SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4", "DEBUG", 99, 25*time.Hour, nil)
+	require.Error(t, err)
+	_, err = SetTemporaryLogLevel(context.Background(), db, fa, daemon, "kea-dhcp4", "VERBOSE", 0, time.Hour, nil)
+	require.Error(t, err)
+	require.Len(t, fa.RecordedCommands, 2)
+}
+
+// Test that the reverter reverts the expired changes and the changes
+// made before it started, and records the events.
+func TestLogLevelReverterRevertExpired(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	now := storkutil.UTCNow()
+	for i, change := range []*dbmodel.KeaLogLevelChange{
+		// Expired.
+		{CreatedAt: now, ExpiresAt: now.Add(-time.Second)},
+		// Made before the reverter started.
+		{CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		// Active.
+		{CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	} {
+		change.DaemonID = daemon.ID
+		change.LoggerName = []string{"kea-dhcp4.a", "kea-dhcp4.b", "kea-dhcp4.c"}[i]
+		change.Severity = "DEBUG"
+		err = dbmodel.AddKeaLogLevelChange(db, change)
+		require.NoError(t, err)
+	}
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigGet, mockLogLevelConfigSet, mockLogLevelConfigGet, mockLogLevelConfigSet)
+	fec := &storktest.FakeEventCenter{}
+	reverter := &LogLevelReverter{
+		db:          db,
+		agents:      fa,
+		eventCenter: fec,
+		startedAt:   now.Add(-time.Minute),
+	}
+
+	err = reverter.revertExpired()
+	require.NoError(t, err)
+
+	require.Len(t, fa.RecordedCommands, 4)
+	changes, err := dbmodel.GetKeaLogLevelChanges(db, nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "kea-dhcp4.c", changes[0].LoggerName)
+
+	require.Len(t, fec.Events, 2)
+	require.Contains(t, fec.Events[0].Text, "kea-dhcp4.a")
+	require.Contains(t, fec.Events[0].Text, "expired")
+	require.Contains(t, fec.Events[1].Text, "kea-dhcp4.b")
+	require.Contains(t, fec.Events[1].Text, "restart")
+}
+
+// Test that the reverter is created and shut down.
+func TestNewLogLevelReverter(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	reverter, err := NewLogLevelReverter(db, agentcommtest.NewFakeAgents(nil, nil), &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+	require.NotNil(t, reverter)
+	reverter.Shutdown()
+}
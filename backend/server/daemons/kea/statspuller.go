@@ -12,6 +12,7 @@ import (
 	"isc.org/stork/server/agentcomm"
 	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
 	storkutil "isc.org/stork/util"
 )
 
@@ -20,12 +21,13 @@ import (
 type StatsPuller struct {
 	*agentcomm.PeriodicPuller
 	*RpsWorker
+	*UtilizationHistoryWorker
 }
 
 // Create a StatsPuller object that in background pulls Kea stats about leases.
 // Beneath it spawns a goroutine that pulls stats periodically from Kea daemons
 // (that are stored in database).
-func NewStatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents) (*StatsPuller, error) {
+func NewStatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents, eventCenter eventcenter.EventCenter) (*StatsPuller, error) {
 	statsPuller := &StatsPuller{}
 	periodicPuller, err := agentcomm.NewPeriodicPuller(db, agents, "Kea Stats puller", "kea_stats_puller_interval",
 		statsPuller.pullStats)
@@ -40,6 +42,7 @@ func NewStatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents) (*StatsPuller,
 		return nil, err
 	}
 	statsPuller.RpsWorker = rpsWorker
+	statsPuller.UtilizationHistoryWorker = NewUtilizationHistoryWorker(db, eventCenter)
 
 	return statsPuller, nil
 }
@@ -162,6 +165,13 @@ func (statsPuller *StatsPuller) pullStats() error {
 		lastErr = err
 	}
 
+	// record the utilization history and forecast the subnets exhaustion
+	err = statsPuller.UtilizationHistoryWorker.Update()
+	if err != nil {
+		lastErr = err
+		log.WithError(err).Error("Cannot update the utilization history")
+	}
+
 	return lastErr
 }
 
@@ -18,6 +18,7 @@ import (
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
 )
 
 // Prepares the Kea mock. It accepts list of serialized JSON responses in order:
@@ -481,7 +482,7 @@ func TestStatsPullerBasic(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(nil, nil)
 
 	// Act
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	defer sp.Shutdown()
 
 	// Assert
@@ -525,7 +526,7 @@ func TestStatsPullerEmptyResponse(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, _ := NewStatsPuller(db, fa)
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	defer sp.Shutdown()
 
 	// Act
@@ -568,7 +569,7 @@ func checkStatsPullerPullStats(t *testing.T, statsFormat string) {
 	}
 
 	// prepare stats puller
-	sp, _ := NewStatsPuller(db, fa)
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	defer sp.Shutdown()
 
 	// Act
@@ -1008,7 +1009,7 @@ func TestPrepareHAEnvironment(t *testing.T) {
 	keaMock := createKeaMock(t, func(callNo int) (jsons []string) { return []string{} })
 
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 
 	// Assert
 	require.NoError(t, err)
@@ -1031,7 +1032,7 @@ func TestStatsPullerPullStatsHAPairNotInitializedYet(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1081,7 +1082,7 @@ func TestStatsPullerPullStatsHAPairHealthy(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1120,7 +1121,7 @@ func TestStatsPullerPullStatsHAPairPrimaryIsDownSecondaryIsReady(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1159,7 +1160,7 @@ func TestStatsPullerPullStatsHAPairPrimaryIsDownSecondaryIsDown(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1186,7 +1187,7 @@ func TestProcessDaemonResponsesForResponseWithBigNumbers(t *testing.T) {
 
 	_ = dbmodel.InitializeSettings(db, 0)
 	fa := agentcommtest.NewFakeAgents(nil, nil)
-	puller, _ := NewStatsPuller(db, fa)
+	puller, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{})
 
 	var response keactrl.StatisticGetAllResponse
 	err := json.Unmarshal(statisticGetAllBigNumbersJSON, &response)
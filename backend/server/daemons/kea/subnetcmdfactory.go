@@ -246,7 +246,7 @@ func createSubnetUpdateCommands(
 	serverTags []string,
 	lookup keaconfig.DHCPOptionDefinitionLookup,
 ) ([]ConfigCommand, error) {
-	hook := localSubnet.Daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+	hook := localSubnet.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary()
 
 	switch hook {
 	case keaconfig.SubnetAndSharedNetworkAlteringHookLibrarySubnetCmds:
@@ -278,7 +278,7 @@ func createSubnetDeleteCommands(
 	family int,
 	sharedNetworkNameBeforeUpdate string,
 ) ([]ConfigCommand, error) {
-	hook := localSubnet.Daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+	hook := localSubnet.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary()
 
 	var commands []ConfigCommand
 	switch hook {
@@ -334,7 +334,7 @@ func createSubnetAddCommands(
 	serverTags []string,
 	lookup keaconfig.DHCPOptionDefinitionLookup,
 ) ([]ConfigCommand, error) {
-	hook := localSubnet.Daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+	hook := localSubnet.Daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary()
 
 	switch hook {
 	case keaconfig.SubnetAndSharedNetworkAlteringHookLibrarySubnetCmds:
@@ -350,7 +350,7 @@ func createSubnetAddCommands(
 // daemons with subnet_cmds hooks. For daemons running cb_cmds no additional
 // commands are created.
 func createSubnetSaveCommands(daemon *dbmodel.Daemon) ([]ConfigCommand, error) {
-	hook := daemon.KeaDaemon.Config.GetHookLibraries().GetSubnetAndSharedNetworkAlteringHookLibrary()
+	hook := daemon.KeaDaemon.Config.GetSubnetAndSharedNetworkAlteringHookLibrary()
 
 	if hook == keaconfig.SubnetAndSharedNetworkAlteringHookLibraryCBCmds {
 		// No additional command is needed to save the subnet in the config
@@ -0,0 +1,254 @@
+package kea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
+)
+
+// Name of the setting holding the number of days ahead for which the
+// exhaustion warnings are raised. Zero disables the warnings.
+const UtilizationForecastHorizonSetting = "utilization_forecast_horizon"
+
+// Default time span of the samples used for forecasting.
+const DefaultUtilizationForecastWindow = 14 * 24 * time.Hour
+
+// Minimum time span of the samples required to forecast the utilization.
+// The trend calculated from shorter spans is unreliable.
+const minUtilizationForecastSpan = time.Hour
+
+// Projected utilization of a subnet. The projection is based on the
+// linear trend of the utilization samples.
+type UtilizationForecast struct {
+	// Most recent address utilization.
+	AddrUtilization float64
+	// Most recent delegated prefix utilization.
+	PdUtilization float64
+	// Address utilization change per day.
+	AddrGrowthPerDay float64
+	// Delegated prefix utilization change per day.
+	PdGrowthPerDay float64
+	// Projected time when all addresses are used or nil if the utilization
+	// is not growing.
+	AddrExhaustionAt *time.Time
+	// Projected time when all delegated prefixes are used or nil if the
+	// utilization is not growing.
+	PdExhaustionAt *time.Time
+}
+
+// Returns the earlier of the projected address and delegated prefix
+// exhaustion times, or nil if neither is projected.
+func (forecast *UtilizationForecast) GetExhaustionAt() *time.Time {
+	switch {
+	case forecast.AddrExhaustionAt == nil:
+		return forecast.PdExhaustionAt
+	case forecast.PdExhaustionAt == nil:
+		return forecast.AddrExhaustionAt
+	case forecast.PdExhaustionAt.Before(*forecast.AddrExhaustionAt):
+		return forecast.PdExhaustionAt
+	default:
+		return forecast.AddrExhaustionAt
+	}
+}
+
+// Calculates the slope of the least-squares line fitted to the utilization
+// values in time. The slope is expressed as the utilization change per day.
+func utilizationTrend(samples []dbmodel.UtilizationSample, value func(dbmodel.UtilizationSample) float64) float64 {
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		x := sample.SampledAt.Sub(samples[0].SampledAt).Hours() / 24
+		y := value(sample)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// Projects the time when the utilization reaches 100% continuing from the
+// current utilization at the specified daily growth. It returns nil if the
+// utilization is not growing.
+func projectExhaustion(current, growthPerDay float64, now time.Time) *time.Time {
+	if current >= 1 {
+		return &now
+	}
+	if growthPerDay <= 0 {
+		return nil
+	}
+	days := (1 - current) / growthPerDay
+	// Avoid overflowing the time for the negligible growth.
+	if days > 100*365 {
+		return nil
+	}
+	exhaustionAt := now.Add(time.Duration(days * float64(24*time.Hour)))
+	return &exhaustionAt
+}
+
+// Forecasts the utilization of an object from its utilization samples
+// ordered by the sampling time. It returns nil if there are not enough
+// samples to calculate the trend.
+func ForecastUtilization(samples []dbmodel.UtilizationSample, now time.Time) *UtilizationForecast {
+	if len(samples) < 2 || samples[len(samples)-1].SampledAt.Sub(samples[0].SampledAt) < minUtilizationForecastSpan {
+		return nil
+	}
+	last := samples[len(samples)-1]
+	forecast := &UtilizationForecast{
+		AddrUtilization: float64(last.AddrUtilization),
+		PdUtilization:   float64(last.PdUtilization),
+		AddrGrowthPerDay: utilizationTrend(samples, func(sample dbmodel.UtilizationSample) float64 {
+			return float64(sample.AddrUtilization)
+		}),
+		PdGrowthPerDay: utilizationTrend(samples, func(sample dbmodel.UtilizationSample) float64 {
+			return float64(sample.PdUtilization)
+		}),
+	}
+	forecast.AddrExhaustionAt = projectExhaustion(forecast.AddrUtilization, forecast.AddrGrowthPerDay, now)
+	forecast.PdExhaustionAt = projectExhaustion(forecast.PdUtilization, forecast.PdGrowthPerDay, now)
+	return forecast
+}
+
+// Returns the utilization forecasts for the subnets with the specified IDs
+// or for all subnets if the IDs are not specified. The forecasts are
+// calculated from the samples taken within the specified window.
+func GetSubnetUtilizationForecasts(db pg.DBI, subnetIDs []int64, window time.Duration, now time.Time) (map[int64]*UtilizationForecast, error) {
+	samples, err := dbmodel.GetUtilizationSamples(db, dbmodel.UtilizationObjectSubnet, subnetIDs, now.Add(-window), time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	// The samples are ordered by the subnet ID. Forecast the utilization
+	// for each group of samples belonging to the same subnet.
+	forecasts := make(map[int64]*UtilizationForecast)
+	start := 0
+	for i := range samples {
+		if i < len(samples)-1 && samples[i+1].ObjectID == samples[i].ObjectID {
+			continue
+		}
+		if forecast := ForecastUtilization(samples[start:i+1], now); forecast != nil {
+			forecasts[samples[i].ObjectID] = forecast
+		}
+		start = i + 1
+	}
+	return forecasts, nil
+}
+
+// Maintains the history of the subnets, shared networks and pools
+// utilization. It stores the samples after each statistics pull,
+// downsamples and ages off the old samples, and raises the events for
+// the subnets projected to run out of addresses or prefixes.
+type UtilizationHistoryWorker struct {
+	db          *pg.DB
+	eventCenter eventcenter.EventCenter
+	// The raw samples older than this are downsampled to hourly averages.
+	RawRetention time.Duration
+	// The hourly samples older than this are downsampled to daily averages.
+	HourlyRetention time.Duration
+	// The samples older than this are deleted.
+	Retention time.Duration
+	// The time span of the samples used for forecasting.
+	ForecastWindow time.Duration
+	// Subnets for which the exhaustion warning has been raised.
+	warnedSubnets map[int64]bool
+}
+
+// Creates the utilization history worker.
+func NewUtilizationHistoryWorker(db *pg.DB, eventCenter eventcenter.EventCenter) *UtilizationHistoryWorker {
+	// The retention values may some day be configurable.
+	return &UtilizationHistoryWorker{
+		db:              db,
+		eventCenter:     eventCenter,
+		RawRetention:    24 * time.Hour,
+		HourlyRetention: 30 * 24 * time.Hour,
+		Retention:       365 * 24 * time.Hour,
+		ForecastWindow:  DefaultUtilizationForecastWindow,
+		warnedSubnets:   make(map[int64]bool),
+	}
+}
+
+// Stores the current utilizations in the history, downsamples the old
+// samples and checks for the subnets projected to run out of addresses
+// or prefixes.
+func (worker *UtilizationHistoryWorker) Update() error {
+	now := storkutil.UTCNow()
+	if err := dbmodel.AddUtilizationSamples(worker.db, now); err != nil {
+		return err
+	}
+	err := dbmodel.DownsampleUtilizationSamples(worker.db, dbmodel.UtilizationResolutionRaw,
+		dbmodel.UtilizationResolutionHour, now.Add(-worker.RawRetention))
+	if err != nil {
+		return err
+	}
+	err = dbmodel.DownsampleUtilizationSamples(worker.db, dbmodel.UtilizationResolutionHour,
+		dbmodel.UtilizationResolutionDay, now.Add(-worker.HourlyRetention))
+	if err != nil {
+		return err
+	}
+	if err = dbmodel.AgeOffUtilizationSamples(worker.db, now.Add(-worker.Retention)); err != nil {
+		return err
+	}
+	return worker.checkExhaustion(now)
+}
+
+// Raises a warning event for each subnet projected to run out of addresses
+// or prefixes within the configured horizon. The warning is raised once
+// until the subnet is no longer projected to run out.
+func (worker *UtilizationHistoryWorker) checkExhaustion(now time.Time) error {
+	horizon, err := dbmodel.GetSettingInt(worker.db, UtilizationForecastHorizonSetting)
+	if err != nil {
+		return err
+	}
+	if horizon <= 0 {
+		return nil
+	}
+	forecasts, err := GetSubnetUtilizationForecasts(worker.db, nil, worker.ForecastWindow, now)
+	if err != nil {
+		return err
+	}
+	deadline := now.Add(time.Duration(horizon) * 24 * time.Hour)
+	exhausting := make(map[int64]bool)
+	for subnetID, forecast := range forecasts {
+		exhaustionAt := forecast.GetExhaustionAt()
+		if exhaustionAt == nil || exhaustionAt.After(deadline) {
+			continue
+		}
+		exhausting[subnetID] = true
+		if worker.warnedSubnets[subnetID] {
+			continue
+		}
+		subnet, err := dbmodel.GetSubnet(worker.db, subnetID)
+		if err != nil {
+			return errors.WithMessagef(err, "cannot get subnet %d for the exhaustion warning", subnetID)
+		}
+		if subnet == nil {
+			continue
+		}
+		resource := "addresses"
+		if exhaustionAt == forecast.PdExhaustionAt {
+			resource = "delegated prefixes"
+		}
+		worker.eventCenter.AddWarningEvent(
+			fmt.Sprintf("{subnet} is projected to run out of %s by %s", resource, exhaustionAt.Format(time.DateOnly)),
+			subnet,
+			fmt.Sprintf("Address utilization: %.1f%% (%+.2f%% per day)\nDelegated prefix utilization: %.1f%% (%+.2f%% per day)",
+				forecast.AddrUtilization*100, forecast.AddrGrowthPerDay*100,
+				forecast.PdUtilization*100, forecast.PdGrowthPerDay*100),
+		)
+		log.WithFields(log.Fields{
+			"subnet":       subnet.Prefix,
+			"exhaustionAt": exhaustionAt,
+		}).Warn("Subnet is projected to run out of leases")
+	}
+	worker.warnedSubnets = exhausting
+	return nil
+}
@@ -0,0 +1,181 @@
+package kea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/datamodel/daemonname"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Generates the utilization samples taken every hour with the utilization
+// growing linearly by the specified value per day.
+func generateUtilizationSamples(start time.Time, count int, initial, growthPerDay float64) []dbmodel.UtilizationSample {
+	samples := []dbmodel.UtilizationSample{}
+	for i := 0; i < count; i++ {
+		samples = append(samples, dbmodel.UtilizationSample{
+			ObjectType:      dbmodel.UtilizationObjectSubnet,
+			ObjectID:        1,
+			Resolution:      dbmodel.UtilizationResolutionHour,
+			SampledAt:       start.Add(time.Duration(i) * time.Hour),
+			AddrUtilization: dbmodel.Utilization(initial + growthPerDay*float64(i)/24),
+		})
+	}
+	return samples
+}
+
+// Test that the exhaustion time is projected from the growing utilization.
+func TestForecastUtilizationGrowing(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// The utilization grows from 10% by 10% per day for two days.
+	samples := generateUtilizationSamples(start, 49, 0.1, 0.1)
+	now := samples[len(samples)-1].SampledAt
+
+	forecast := ForecastUtilization(samples, now)
+	require.NotNil(t, forecast)
+	require.InDelta(t, 0.3, forecast.AddrUtilization, 0.001)
+	require.InDelta(t, 0.1, forecast.AddrGrowthPerDay, 0.001)
+	require.Zero(t, forecast.PdGrowthPerDay)
+	require.Nil(t, forecast.PdExhaustionAt)
+
+	// The remaining 70% should be used in 7 days.
+	require.NotNil(t, forecast.AddrExhaustionAt)
+	require.WithinDuration(t, now.Add(7*24*time.Hour), *forecast.AddrExhaustionAt, time.Hour)
+	require.Equal(t, forecast.AddrExhaustionAt, forecast.GetExhaustionAt())
+}
+
+// Test that the exhaustion time is not projected when the utilization
+// is decreasing or constant.
+func TestForecastUtilizationNotGrowing(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	samples := generateUtilizationSamples(start, 24, 0.5, -0.1)
+	forecast := ForecastUtilization(samples, samples[len(samples)-1].SampledAt)
+	require.NotNil(t, forecast)
+	require.Negative(t, forecast.AddrGrowthPerDay)
+	require.Nil(t, forecast.GetExhaustionAt())
+
+	samples = generateUtilizationSamples(start, 24, 0.5, 0)
+	forecast = ForecastUtilization(samples, samples[len(samples)-1].SampledAt)
+	require.NotNil(t, forecast)
+	require.Zero(t, forecast.AddrGrowthPerDay)
+	require.Nil(t, forecast.GetExhaustionAt())
+}
+
+// Test that the subnet which is already full is projected to run out now.
+func TestForecastUtilizationExhausted(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 3, 1, 0)
+	now := start.Add(3 * time.Hour)
+
+	forecast := ForecastUtilization(samples, now)
+	require.NotNil(t, forecast)
+	require.NotNil(t, forecast.GetExhaustionAt())
+	require.Equal(t, now, *forecast.GetExhaustionAt())
+}
+
+// Test that the forecast requires enough samples.
+func TestForecastUtilizationNotEnoughSamples(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, ForecastUtilization(nil, start))
+	require.Nil(t, ForecastUtilization(generateUtilizationSamples(start, 1, 0.5, 0.1), start))
+
+	// Two samples taken within a short period of time.
+	samples := []dbmodel.UtilizationSample{
+		{SampledAt: start, AddrUtilization: 0.1},
+		{SampledAt: start.Add(time.Minute), AddrUtilization: 0.2},
+	}
+	require.Nil(t, ForecastUtilization(samples, start))
+}
+
+// Test selecting the earlier of the address and prefix exhaustion times.
+func TestUtilizationForecastGetExhaustionAt(t *testing.T) {
+	early := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	forecast := &UtilizationForecast{}
+	require.Nil(t, forecast.GetExhaustionAt())
+
+	forecast.PdExhaustionAt = &late
+	require.Equal(t, &late, forecast.GetExhaustionAt())
+
+	forecast.AddrExhaustionAt = &early
+	require.Equal(t, &early, forecast.GetExhaustionAt())
+
+	forecast.AddrExhaustionAt, forecast.PdExhaustionAt = &late, &early
+	require.Equal(t, &early, forecast.GetExhaustionAt())
+}
+
+// Test that the worker stores the utilization samples and raises the
+// warning for the subnet projected to run out of addresses.
+func TestUtilizationHistoryWorkerUpdate(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+	daemon := dbmodel.NewDaemon(machine, daemonname.DHCPv4, true, nil)
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	subnet := &dbmodel.Subnet{
+		Prefix: "192.0.2.0/24",
+	}
+	err = dbmodel.AddSubnet(db, subnet)
+	require.NoError(t, err)
+	err = dbmodel.AddDaemonToSubnet(db, subnet, daemon)
+	require.NoError(t, err)
+
+	// Simulate the growing utilization in the past.
+	now := storkutil.UTCNow()
+	samples := generateUtilizationSamples(now.Add(-24*time.Hour), 24, 0.5, 0.2)
+	for i := range samples {
+		samples[i].ObjectID = subnet.ID
+		_, err = db.Model(&samples[i]).Insert()
+		require.NoError(t, err)
+	}
+	_, err = db.Model(subnet).
+		Set("addr_utilization = ?", dbmodel.Utilization(0.7)).
+		Set("stats_collected_at = ?", now).
+		WherePK().
+		Update()
+	require.NoError(t, err)
+
+	fec := &storktest.FakeEventCenter{}
+	worker := NewUtilizationHistoryWorker(db, fec)
+	err = worker.Update()
+	require.NoError(t, err)
+
+	// The current utilization should be recorded.
+	stored, err := dbmodel.GetUtilizationSamples(db, dbmodel.UtilizationObjectSubnet, []int64{subnet.ID}, now, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, dbmodel.UtilizationResolutionRaw, stored[0].Resolution)
+	require.InDelta(t, 0.7, float64(stored[0].AddrUtilization), 0.001)
+
+	// The subnet should run out of addresses within the default horizon.
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "is projected to run out of addresses")
+	require.EqualValues(t, subnet.ID, fec.Events[0].Relations.SubnetID)
+
+	// The warning should not be repeated.
+	err = worker.Update()
+	require.NoError(t, err)
+	require.Len(t, fec.Events, 1)
+
+	// Disabling the warnings.
+	err = dbmodel.SetSettingInt(db, UtilizationForecastHorizonSetting, 0)
+	require.NoError(t, err)
+	worker = NewUtilizationHistoryWorker(db, fec)
+	err = worker.Update()
+	require.NoError(t, err)
+	require.Len(t, fec.Events, 1)
+}
@@ -4,18 +4,23 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-pg/pg/v10"
 	pkgerrors "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/daemoncfg/kea"
 	"isc.org/stork/server/agentcomm"
 	"isc.org/stork/server/config"
 	"isc.org/stork/server/daemons/kea"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 // Holds a pair of a context and its cancel function.
@@ -51,6 +56,9 @@ type configManagerImpl struct {
 	kea config.KeaModule
 	// The locker that manages the daemon configuration locks.
 	locker config.DaemonLocker
+	// Event center used to notify about the results of the scheduled
+	// configuration changes.
+	eventCenter eventcenter.EventCenter
 }
 
 // Generates new context ID. This ID is returned to the client when the
@@ -80,12 +88,13 @@ func (manager *configManagerImpl) generateContextID() (int64, error) {
 // instance of the Stork Server holding the state.).
 func NewManager(server config.ManagerAccessors) config.Manager {
 	manager := &configManagerImpl{
-		db:       server.GetDB(),
-		agents:   server.GetConnectedAgents(),
-		lookup:   server.GetDHCPOptionDefinitionLookup(),
-		locker:   server.GetDaemonLocker(),
-		contexts: make(map[int64]contextPair),
-		mutex:    &sync.RWMutex{},
+		db:          server.GetDB(),
+		agents:      server.GetConnectedAgents(),
+		lookup:      server.GetDHCPOptionDefinitionLookup(),
+		locker:      server.GetDaemonLocker(),
+		eventCenter: server.GetEventCenter(),
+		contexts:    make(map[int64]contextPair),
+		mutex:       &sync.RWMutex{},
 	}
 	keaConfigModule := kea.NewConfigModule(manager)
 	manager.kea = keaConfigModule
@@ -120,6 +129,11 @@ func (manager *configManagerImpl) GetDaemonLocker() config.DaemonLocker {
 	return manager.locker
 }
 
+// Returns the event center instance used by the configuration manager.
+func (manager *configManagerImpl) GetEventCenter() eventcenter.EventCenter {
+	return manager.eventCenter
+}
+
 // Creates the context for use with the configuration manager. It sets the
 // unique context ID and a user identifier used to associate the context
 // and the configuration change transaction with a user applying the
@@ -294,39 +308,13 @@ func (manager *configManagerImpl) CommitDue() error {
 	}
 	// Iterate over the changes.
 	for _, change := range changes {
-		var state any
-		// Re-create the transaction state from the serialized data stored in
-		// the database.
-		switch {
-		case change.HasKeaUpdates():
-			keaState := config.TransactionState[kea.ConfigRecipe]{
-				Scheduled: true,
-			}
-			for _, u := range change.Updates {
-				update := kea.NewConfigUpdateFromDBModel(u)
-				if update == nil {
-					continue
-				}
-				keaState.Updates = append(keaState.Updates, update)
-			}
-			state = keaState
-		default:
-		}
-		var (
-			ctx context.Context
-			err error
-		)
-		// Re-create the context.
-		ctx, err = manager.CreateContext(change.UserID)
-		if err == nil {
-			ctx = context.WithValue(ctx, config.StateContextKey, state)
-			// Commit the changes in the monitored daemons.
-			_, err = manager.Commit(ctx)
-		}
+		// Commit the changes in the monitored daemons.
+		err := manager.commitConfigUpdates(change.UserID, change.Updates, true)
 		var errText string
 		if err != nil {
 			errText = err.Error()
 		}
+		manager.addScheduledConfigChangeEvent(&change, err)
 		// Mark the current config change as executed.
 		if err = dbmodel.SetScheduledConfigChangeExecuted(manager.GetDB(), change.ID, errText); err != nil {
 			return err
@@ -335,6 +323,147 @@ func (manager *configManagerImpl) CommitDue() error {
 	return nil
 }
 
+// Re-creates the transaction state from the config updates serialized in
+// the database and commits it on behalf of the specified user.
+func (manager *configManagerImpl) commitConfigUpdates(userID int64, updates []*dbmodel.ConfigUpdate, scheduled bool) error {
+	var (
+		state      any
+		keaUpdates []*config.Update[kea.ConfigRecipe]
+	)
+	switch {
+	case dbmodel.HasKeaConfigUpdates(updates):
+		keaState := config.TransactionState[kea.ConfigRecipe]{
+			Scheduled: scheduled,
+		}
+		for _, u := range updates {
+			update := kea.NewConfigUpdateFromDBModel(u)
+			if update == nil {
+				continue
+			}
+			keaState.Updates = append(keaState.Updates, update)
+		}
+		state = keaState
+		keaUpdates = keaState.Updates
+	default:
+	}
+	// Re-create the context.
+	ctx, err := manager.CreateContext(userID)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, config.StateContextKey, state)
+	if _, err = manager.Commit(ctx); err != nil {
+		return err
+	}
+	manager.addCommittedConfigUpdateEvents(userID, keaUpdates)
+	return nil
+}
+
+// Records the events describing the changes of the particular objects
+// (e.g., leases, client classes) committed on behalf of the user after an approval or at
+// the scheduled time. The REST API records such events only for the
+// changes committed right away.
+func (manager *configManagerImpl) addCommittedConfigUpdateEvents(userID int64, updates []*config.Update[kea.ConfigRecipe]) {
+	if manager.eventCenter == nil {
+		return
+	}
+	user, err := dbmodel.GetUserByID(manager.db, userID)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to get user %d who submitted the committed config change", userID)
+	}
+	for _, update := range updates {
+		text, daemon, warning := getCommittedConfigUpdateEvent(update)
+		if text == "" {
+			continue
+		}
+		objects := []any{}
+		if user != nil {
+			objects = append(objects, user)
+		} else {
+			text = strings.ReplaceAll(text, "{user}", "unknown user")
+		}
+		if daemon != nil {
+			objects = append(objects, daemon)
+		}
+		if warning {
+			manager.eventCenter.AddWarningEvent(text, objects...)
+		} else {
+			manager.eventCenter.AddInfoEvent(text, objects...)
+		}
+	}
+}
+
+// Returns the text of the event describing the change of the object in the
+// committed config update and the daemon in which the object was changed.
+// The text is empty when the update does not change a particular object.
+// The returned flag indicates whether the event should be a warning.
+func getCommittedConfigUpdateEvent(update *config.Update[kea.ConfigRecipe]) (text string, daemon *dbmodel.Daemon, warning bool) {
+	recipe := update.Recipe
+	if len(recipe.Commands) > 0 {
+		daemon = recipe.Commands[0].Daemon
+	}
+	switch update.Operation {
+	case dbmodel.ConfigOperationKeaLeaseAdd:
+		if recipe.LeaseAfterUpdate != nil {
+			text = fmt.Sprintf("{user} added lease %s to {daemon}", recipe.LeaseAfterUpdate.IPAddress)
+		}
+	case dbmodel.ConfigOperationKeaLeaseUpdate:
+		if recipe.LeaseAfterUpdate != nil {
+			text = fmt.Sprintf("{user} updated lease %s in {daemon}", recipe.LeaseAfterUpdate.IPAddress)
+		}
+	case dbmodel.ConfigOperationKeaLeaseDelete:
+		if recipe.DeletedLease != nil {
+			text = fmt.Sprintf("{user} deleted lease %s from {daemon}", recipe.DeletedLease.IPAddress)
+		}
+	case dbmodel.ConfigOperationKeaLeaseWipe:
+		warning = true
+		if recipe.WipedLeasesLocalSubnetID != nil && *recipe.WipedLeasesLocalSubnetID > 0 {
+			text = fmt.Sprintf("{user} wiped leases from subnet %d of {daemon}", *recipe.WipedLeasesLocalSubnetID)
+		} else {
+			text = "{user} wiped leases from all subnets of {daemon}"
+		}
+	case dbmodel.ConfigOperationKeaClientClassAdd:
+		if recipe.ClientClassAfterUpdate != nil {
+			text = fmt.Sprintf("{user} added client class %s to {daemon}", recipe.ClientClassAfterUpdate.Name)
+		}
+	case dbmodel.ConfigOperationKeaClientClassUpdate:
+		if recipe.ClientClassAfterUpdate != nil {
+			text = fmt.Sprintf("{user} updated client class %s in {daemon}", recipe.ClientClassAfterUpdate.Name)
+		}
+	case dbmodel.ConfigOperationKeaClientClassDelete:
+		if recipe.DeletedClientClassName != nil {
+			text = fmt.Sprintf("{user} deleted client class %s from {daemon}", *recipe.DeletedClientClassName)
+		}
+	case dbmodel.ConfigOperationKeaConfigRollback:
+		if recipe.RollbackDaemon != nil && recipe.RollbackConfigVersion != nil {
+			daemon = recipe.RollbackDaemon
+			text = fmt.Sprintf("{user} rolled back configuration of {daemon} to version %d", *recipe.RollbackConfigVersion)
+		}
+	}
+	return text, daemon, warning
+}
+
+// Notifies about the result of committing the scheduled config change.
+func (manager *configManagerImpl) addScheduledConfigChangeEvent(change *dbmodel.ScheduledConfigChange, err error) {
+	if manager.eventCenter == nil {
+		return
+	}
+	submitter := "unknown user"
+	objects := []any{}
+	if change.User != nil {
+		submitter = "{user}"
+		objects = append(objects, change.User)
+	}
+	if err != nil {
+		objects = append(objects, err)
+		text := fmt.Sprintf("failed to commit scheduled config change %d submitted by %s", change.ID, submitter)
+		manager.eventCenter.AddErrorEvent(text, objects...)
+		return
+	}
+	text := fmt.Sprintf("committed scheduled config change %d submitted by %s", change.ID, submitter)
+	manager.eventCenter.AddInfoEvent(text, objects...)
+}
+
 // Schedules sending the changes queued in the context to one or multiple daemons.
 // The deadline parameter specifies the time when the changes should be committed.
 func (manager *configManagerImpl) Schedule(ctx context.Context, deadline time.Time) (context.Context, error) {
@@ -351,6 +480,22 @@ func (manager *configManagerImpl) Schedule(ctx context.Context, deadline time.Ti
 		DeadlineAt: deadline,
 		UserID:     userID,
 	}
+	updates, err := newConfigUpdatesFromState(state)
+	if err != nil {
+		return ctx, err
+	}
+	scc.Updates = updates
+	if err := dbmodel.AddScheduledConfigChange(manager.db, scc); err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, config.ScheduledConfigChangeIDKey, scc.ID)
+	return ctx, nil
+}
+
+// Converts the config updates held in the transaction state to the format
+// in which they are stored in the database.
+func newConfigUpdatesFromState(state config.TransactionStateAccessor) ([]*dbmodel.ConfigUpdate, error) {
+	var updates []*dbmodel.ConfigUpdate
 	for _, u := range state.GetUpdates() {
 		update := &dbmodel.ConfigUpdate{
 			Operation: u.Operation,
@@ -358,13 +503,73 @@ func (manager *configManagerImpl) Schedule(ctx context.Context, deadline time.Ti
 		}
 		recipe, err := json.Marshal(u.Recipe)
 		if err != nil {
-			return ctx, pkgerrors.Wrapf(err, "problem converting config update recipe to the raw format")
+			return nil, pkgerrors.Wrapf(err, "problem converting config update recipe to the raw format")
 		}
 		update.Recipe = (*json.RawMessage)(&recipe)
-		scc.Updates = append(scc.Updates, update)
+		updates = append(updates, update)
 	}
-	if err := dbmodel.AddScheduledConfigChange(manager.db, scc); err != nil {
+	return updates, nil
+}
+
+// Submits the changes queued in the context for approval by another user.
+// It computes the differences in the daemons' configurations resulting from
+// the changes and stores them along with the changes in the database. The
+// changes are not sent to the daemons until the request is approved. The
+// optional deadline specifies when the changes should be committed after
+// the approval.
+func (manager *configManagerImpl) RequestApproval(ctx context.Context, deadline *time.Time) (context.Context, error) {
+	state, ok := config.GetAnyTransactionState(ctx)
+	if !ok {
+		return ctx, pkgerrors.Errorf("context lacks state")
+	}
+	userID, ok := config.GetValueAsInt64(ctx, config.UserContextKey)
+	if !ok {
+		return ctx, pkgerrors.Errorf("context lacks user key")
+	}
+	request := &dbmodel.ConfigChangeRequest{
+		DeadlineAt: deadline,
+		UserID:     userID,
+		Status:     dbmodel.ConfigChangeRequestStatusPending,
+	}
+	updates, err := newConfigUpdatesFromState(state)
+	if err != nil {
+		return ctx, err
+	}
+	request.Updates = updates
+	if request.HasKeaUpdates() {
+		diff, err := manager.kea.ComputeConfigChangeDiff(ctx)
+		if err != nil {
+			return ctx, err
+		}
+		request.Diff = diff
+	}
+	if err := dbmodel.AddConfigChangeRequest(manager.db, request); err != nil {
 		return ctx, err
 	}
+	ctx = context.WithValue(ctx, config.ConfigChangeRequestIDKey, request.ID)
 	return ctx, nil
 }
+
+// Commits the changes from the approved config change request on behalf
+// of the user who submitted the request. If the request specifies a deadline
+// in the future, the changes are scheduled instead. If committing or
+// scheduling the changes fails, the request is marked as failed and the
+// error is recorded in it, so the request can be approved again.
+func (manager *configManagerImpl) CommitApproved(request *dbmodel.ConfigChangeRequest) error {
+	var err error
+	if request.DeadlineAt != nil && request.DeadlineAt.After(storkutil.UTCNow()) {
+		err = dbmodel.AddScheduledConfigChange(manager.db, &dbmodel.ScheduledConfigChange{
+			DeadlineAt: *request.DeadlineAt,
+			UserID:     request.UserID,
+			Updates:    request.Updates,
+		})
+	} else {
+		err = manager.commitConfigUpdates(request.UserID, request.Updates, false)
+	}
+	if err != nil {
+		if setErr := dbmodel.SetConfigChangeRequestFailed(manager.db, request.ID, err.Error()); setErr != nil {
+			log.WithError(setErr).Errorf("Failed to record the error of the config change request %d", request.ID)
+		}
+	}
+	return err
+}
@@ -7,6 +7,8 @@ import (
 
 	pkgerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	keadata "isc.org/stork/daemondata/kea"
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/datamodel/protocoltype"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
@@ -15,6 +17,7 @@ import (
 	appstest "isc.org/stork/server/daemons/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
 	storkutil "isc.org/stork/util"
 )
 
@@ -62,12 +65,14 @@ func TestNewManager(t *testing.T) {
 	agents := &agentcommtest.FakeAgents{}
 	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
 	daemonLocker := config.NewDaemonLocker()
+	eventCenter := &storktest.FakeEventCenter{}
 
 	manager := NewManager(&appstest.ManagerAccessorsWrapper{
 		DB:           db,
 		Agents:       agents,
 		DefLookup:    lookup,
 		DaemonLocker: daemonLocker,
+		EventCenter:  eventCenter,
 	})
 	require.NotNil(t, manager)
 	require.NotNil(t, manager.GetKeaModule())
@@ -79,6 +84,7 @@ func TestNewManager(t *testing.T) {
 	require.Equal(t, agents, impl.GetConnectedAgents())
 	require.Equal(t, lookup, impl.GetDHCPOptionDefinitionLookup())
 	require.Equal(t, daemonLocker, impl.GetDaemonLocker())
+	require.Equal(t, eventCenter, impl.GetEventCenter())
 }
 
 // Test creating new context with context ID and user ID.
@@ -474,8 +480,10 @@ func TestCommitDue(t *testing.T) {
 	require.NoError(t, err)
 	require.NotZero(t, user.ID)
 
+	eventCenter := &storktest.FakeEventCenter{}
 	manager := NewManager(&appstest.ManagerAccessorsWrapper{
-		DB: db,
+		DB:          db,
+		EventCenter: eventCenter,
 	})
 	require.NotNil(t, manager)
 
@@ -542,6 +550,15 @@ func TestCommitDue(t *testing.T) {
 	changes, err = dbmodel.GetDueConfigChanges(db)
 	require.NoError(t, err)
 	require.Empty(t, changes)
+
+	// An event should be raised for each committed change.
+	require.Len(t, eventCenter.Events, 2)
+	for _, event := range eventCenter.Events {
+		require.Equal(t, dbmodel.EvInfo, event.Level)
+		require.Contains(t, event.Text, "committed scheduled config change")
+		require.Contains(t, event.Text, "test")
+		require.EqualValues(t, user.ID, event.Relations.UserID)
+	}
 }
 
 // Test that errors are recorded in the database when committing due
@@ -560,8 +577,10 @@ func TestCommitDueErrors(t *testing.T) {
 	require.NoError(t, err)
 	require.NotZero(t, user.ID)
 
+	eventCenter := &storktest.FakeEventCenter{}
 	manager := NewManager(&appstest.ManagerAccessorsWrapper{
-		DB: db,
+		DB:          db,
+		EventCenter: eventCenter,
 	})
 	require.NotNil(t, manager)
 
@@ -611,6 +630,14 @@ func TestCommitDueErrors(t *testing.T) {
 		require.True(t, change.Executed)
 		require.Equal(t, "custom test error", change.Error)
 	}
+
+	// An error event should be raised for each failed change.
+	require.Len(t, eventCenter.Events, 2)
+	for _, event := range eventCenter.Events {
+		require.Equal(t, dbmodel.EvError, event.Level)
+		require.Contains(t, event.Text, "failed to commit scheduled config change")
+		require.Equal(t, "custom test error", event.Details)
+	}
 }
 
 // Test that due changes are dropped if the user is deleted.
@@ -750,13 +777,18 @@ func TestSchedule(t *testing.T) {
 	ctx = context.WithValue(ctx, config.StateContextKey, state)
 
 	// Schedule the change.
-	_, err = manager.Schedule(ctx, storkutil.UTCNow().Add(time.Second*100))
+	ctx, err = manager.Schedule(ctx, storkutil.UTCNow().Add(time.Second*100))
 	require.NoError(t, err)
 
 	// Ensure that the change has been added to the database.
 	changes, err := dbmodel.GetScheduledConfigChanges(db)
 	require.NoError(t, err)
 	require.Len(t, changes, 1)
+
+	// The ID of the scheduled change should be stored in the context.
+	changeID, ok := config.GetValueAsInt64(ctx, config.ScheduledConfigChangeIDKey)
+	require.True(t, ok)
+	require.Equal(t, changes[0].ID, changeID)
 	require.Len(t, changes[0].Updates, 1)
 	require.Equal(t, dbmodel.ConfigOperationKeaHostAdd, changes[0].Updates[0].Operation)
 	require.NotNil(t, changes[0].Updates[0].Recipe)
@@ -784,3 +816,287 @@ func TestSchedule(t *testing.T) {
 	require.Equal(t, daemonname.CA, daemonReturned.GetName())
 	require.Equal(t, parsedMachine.GetID(), daemonReturned.MachineID)
 }
+
+// Test that the config change is submitted for approval with the computed
+// differences and committed when it is approved.
+func TestRequestApprovalCommitApproved(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:        db,
+		DefLookup: dbmodel.NewDHCPOptionDefinitionLookup(),
+	})
+	require.NotNil(t, manager)
+
+	impl := manager.(*configManagerImpl)
+	fkm := newFakeKeaModuleCommit()
+	impl.keaCommit = fkm
+
+	// Create a context with a client class update.
+	ctx, err := manager.CreateContext(1)
+	require.NoError(t, err)
+
+	classBefore := &dbmodel.ClientClass{
+		DaemonID: 1,
+		Name:     "foo",
+		KeaParameters: &keaconfig.ClientClass{
+			ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+				NextServer: storkutil.Ptr("192.0.2.1"),
+			},
+		},
+	}
+	classAfter := &dbmodel.ClientClass{
+		DaemonID: 1,
+		Name:     "foo",
+		KeaParameters: &keaconfig.ClientClass{
+			ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+				NextServer: storkutil.Ptr("192.0.2.2"),
+			},
+		},
+	}
+	state := config.TransactionState[kea.ConfigRecipe]{
+		Updates: []*config.Update[kea.ConfigRecipe]{
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaClientClassUpdate, 1),
+		},
+	}
+	state.Updates[0].Recipe.ClientClassBeforeUpdate = classBefore
+	state.Updates[0].Recipe.ClientClassAfterUpdate = classAfter
+	ctx = context.WithValue(ctx, config.StateContextKey, state)
+
+	// Submit the change for approval.
+	ctx, err = manager.RequestApproval(ctx, nil)
+	require.NoError(t, err)
+
+	// Nothing should be committed yet.
+	require.Empty(t, fkm.ops)
+
+	requestID, ok := config.GetValueAsInt64(ctx, config.ConfigChangeRequestIDKey)
+	require.True(t, ok)
+
+	request, err := dbmodel.GetConfigChangeRequestByID(db, requestID)
+	require.NoError(t, err)
+	require.NotNil(t, request)
+	require.EqualValues(t, 1, request.UserID)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusPending, request.Status)
+	require.Nil(t, request.DeadlineAt)
+	require.Len(t, request.Updates, 1)
+	require.Equal(t, dbmodel.ConfigOperationKeaClientClassUpdate, request.Updates[0].Operation)
+	require.Len(t, request.Diff, 1)
+	require.EqualValues(t, 1, request.Diff[0].DaemonID)
+	require.Len(t, request.Diff[0].Entries, 1)
+	require.Equal(t, "/client-classes[name=foo]/next-server", request.Diff[0].Entries[0].Path)
+
+	// Commit the approved change.
+	err = manager.CommitApproved(request)
+	require.NoError(t, err)
+	require.Len(t, fkm.ops, 1)
+	require.Equal(t, dbmodel.ConfigOperationKeaClientClassUpdate, fkm.ops[0])
+	require.Len(t, fkm.contexts, 1)
+	userID, ok := config.GetValueAsInt64(fkm.contexts[0], config.UserContextKey)
+	require.True(t, ok)
+	require.EqualValues(t, 1, userID)
+	committedState, ok := config.GetTransactionState[kea.ConfigRecipe](fkm.contexts[0])
+	require.True(t, ok)
+	require.False(t, committedState.Scheduled)
+	require.NotNil(t, committedState.Updates[0].Recipe.ClientClassAfterUpdate)
+
+	// Simulate an error during the commit. It should be recorded and
+	// the request should be marked as failed.
+	fkm.err = pkgerrors.New("commit error")
+	err = manager.CommitApproved(request)
+	require.Error(t, err)
+
+	request, err = dbmodel.GetConfigChangeRequestByID(db, requestID)
+	require.NoError(t, err)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusFailed, request.Status)
+	require.Equal(t, "commit error", request.Error)
+}
+
+// Test that the approved config change with a deadline in the future is
+// scheduled rather than committed.
+func TestCommitApprovedScheduled(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB: db,
+	})
+	require.NotNil(t, manager)
+
+	impl := manager.(*configManagerImpl)
+	fkm := newFakeKeaModuleCommit()
+	impl.keaCommit = fkm
+
+	deadline := storkutil.UTCNow().Add(time.Hour)
+	request := &dbmodel.ConfigChangeRequest{
+		DeadlineAt: &deadline,
+		UserID:     1,
+		Status:     dbmodel.ConfigChangeRequestStatusPending,
+		Updates: []*dbmodel.ConfigUpdate{
+			dbmodel.NewConfigUpdate(dbmodel.ConfigOperationKeaHostAdd, 1),
+		},
+	}
+	err := dbmodel.AddConfigChangeRequest(db, request)
+	require.NoError(t, err)
+
+	err = manager.CommitApproved(request)
+	require.NoError(t, err)
+	require.Empty(t, fkm.ops)
+
+	changes, err := dbmodel.GetScheduledConfigChanges(db)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.EqualValues(t, 1, changes[0].UserID)
+	require.WithinDuration(t, deadline, changes[0].DeadlineAt, time.Second)
+	require.Len(t, changes[0].Updates, 1)
+	require.Equal(t, dbmodel.ConfigOperationKeaHostAdd, changes[0].Updates[0].Operation)
+}
+
+// Test that the events describing the changed leases are recorded when the
+// lease changes are committed at the scheduled time or after an approval.
+func TestCommitDueAndApprovedLeaseEvents(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	user := &dbmodel.SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		EventCenter: eventCenter,
+	})
+	impl := manager.(*configManagerImpl)
+	fkm := newFakeKeaModuleCommit()
+	impl.keaCommit = fkm
+
+	daemon := &dbmodel.Daemon{ID: 1, Name: daemonname.DHCPv4}
+	state := config.TransactionState[kea.ConfigRecipe]{
+		Updates: []*config.Update[kea.ConfigRecipe]{
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaLeaseAdd, daemon.ID),
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaLeaseWipe, daemon.ID),
+		},
+	}
+	state.Updates[0].Recipe.Commands = []kea.ConfigCommand{{Daemon: daemon}}
+	state.Updates[0].Recipe.LeaseAfterUpdate = &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Lease:    keadata.Lease{IPAddress: "192.0.2.10"},
+	}
+	state.Updates[1].Recipe.Commands = []kea.ConfigCommand{{Daemon: daemon}}
+	state.Updates[1].Recipe.WipedLeasesDaemonID = &daemon.ID
+	state.Updates[1].Recipe.WipedLeasesLocalSubnetID = storkutil.Ptr(int64(5))
+	updates, err := newConfigUpdatesFromState(state)
+	require.NoError(t, err)
+
+	// Commit the scheduled change.
+	err = dbmodel.AddScheduledConfigChange(db, &dbmodel.ScheduledConfigChange{
+		DeadlineAt: storkutil.UTCNow().Add(-time.Second),
+		UserID:     user.ID,
+		Updates:    updates,
+	})
+	require.NoError(t, err)
+	err = manager.CommitDue()
+	require.NoError(t, err)
+
+	require.Len(t, eventCenter.Events, 3)
+	require.Equal(t, dbmodel.EvInfo, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "added lease 192.0.2.10 to")
+	require.EqualValues(t, user.ID, eventCenter.Events[0].Relations.UserID)
+	require.EqualValues(t, daemon.ID, eventCenter.Events[0].Relations.DaemonID)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[1].Level)
+	require.Contains(t, eventCenter.Events[1].Text, "wiped leases from subnet 5 of")
+	require.Contains(t, eventCenter.Events[2].Text, "committed scheduled config change")
+
+	// Commit the approved change.
+	state.Updates = state.Updates[:1]
+	state.Updates[0].Operation = dbmodel.ConfigOperationKeaLeaseDelete
+	state.Updates[0].Recipe.DeletedLease = state.Updates[0].Recipe.LeaseAfterUpdate
+	state.Updates[0].Recipe.LeaseAfterUpdate = nil
+	updates, err = newConfigUpdatesFromState(state)
+	require.NoError(t, err)
+	request := &dbmodel.ConfigChangeRequest{
+		UserID:  user.ID,
+		Status:  dbmodel.ConfigChangeRequestStatusPending,
+		Updates: updates,
+	}
+	err = dbmodel.AddConfigChangeRequest(db, request)
+	require.NoError(t, err)
+	err = manager.CommitApproved(request)
+	require.NoError(t, err)
+
+	require.Len(t, eventCenter.Events, 4)
+	require.Equal(t, dbmodel.EvInfo, eventCenter.Events[3].Level)
+	require.Contains(t, eventCenter.Events[3].Text, "deleted lease 192.0.2.10 from")
+	require.EqualValues(t, user.ID, eventCenter.Events[3].Relations.UserID)
+
+	// No events are recorded when the commit fails.
+	fkm.err = pkgerrors.New("commit error")
+	err = manager.CommitApproved(request)
+	require.Error(t, err)
+	require.Len(t, eventCenter.Events, 4)
+}
+
+// Test that the events describing the changed client classes and the
+// configuration rollback are recorded when these changes are committed
+// after an approval.
+func TestCommitApprovedClientClassAndRollbackEvents(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		EventCenter: eventCenter,
+	})
+	impl := manager.(*configManagerImpl)
+	impl.keaCommit = newFakeKeaModuleCommit()
+
+	daemon := &dbmodel.Daemon{ID: 1, Name: daemonname.DHCPv4}
+	state := config.TransactionState[kea.ConfigRecipe]{
+		Updates: []*config.Update[kea.ConfigRecipe]{
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaClientClassAdd, daemon.ID),
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaClientClassUpdate, daemon.ID),
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaClientClassDelete, daemon.ID),
+			config.NewUpdate[kea.ConfigRecipe](dbmodel.ConfigOperationKeaConfigRollback, daemon.ID),
+		},
+	}
+	for _, update := range state.Updates {
+		update.Recipe.Commands = []kea.ConfigCommand{{Daemon: daemon}}
+	}
+	state.Updates[0].Recipe.ClientClassAfterUpdate = &dbmodel.ClientClass{DaemonID: daemon.ID, Name: "foo"}
+	state.Updates[1].Recipe.ClientClassAfterUpdate = &dbmodel.ClientClass{DaemonID: daemon.ID, Name: "bar"}
+	state.Updates[2].Recipe.DeletedClientClassName = storkutil.Ptr("baz")
+	state.Updates[3].Recipe.RollbackDaemon = daemon
+	state.Updates[3].Recipe.RollbackConfigVersion = storkutil.Ptr(int64(3))
+	updates, err := newConfigUpdatesFromState(state)
+	require.NoError(t, err)
+
+	// The request is submitted by the default admin.
+	request := &dbmodel.ConfigChangeRequest{
+		UserID:  1,
+		Status:  dbmodel.ConfigChangeRequestStatusPending,
+		Updates: updates,
+	}
+	err = dbmodel.AddConfigChangeRequest(db, request)
+	require.NoError(t, err)
+	err = manager.CommitApproved(request)
+	require.NoError(t, err)
+
+	require.Len(t, eventCenter.Events, 4)
+	require.Contains(t, eventCenter.Events[0].Text, "added client class foo to")
+	require.Contains(t, eventCenter.Events[1].Text, "updated client class bar in")
+	require.Contains(t, eventCenter.Events[2].Text, "deleted client class baz from")
+	require.Contains(t, eventCenter.Events[3].Text, "rolled back configuration of")
+	require.Contains(t, eventCenter.Events[3].Text, "to version 3")
+	for _, event := range eventCenter.Events {
+		require.Equal(t, dbmodel.EvInfo, event.Level)
+		require.EqualValues(t, 1, event.Relations.UserID)
+		require.EqualValues(t, daemon.ID, event.Relations.DaemonID)
+	}
+}
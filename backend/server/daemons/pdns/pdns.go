@@ -2,13 +2,17 @@ package pdns
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
 	"isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 // Fetches the general information about the PowerDNS server and updates the
@@ -32,6 +36,31 @@ func GetDaemonState(ctx context.Context, agents agentcomm.ConnectedAgents, daemo
 	daemon.PDNSDaemon.Details.AutoprimariesURL = serverInfo.AutoprimariesURL
 }
 
+// Fetches the PowerDNS configuration from the agent, parses it and stores it
+// in the daemon along with its hash. The configuration is not stored in the
+// database. It is used for the configuration review. The agent redacts the
+// secrets, so the configuration contains asterisks instead of the API key
+// and the passwords.
+func GetDaemonConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *dbmodel.Daemon) error {
+	if daemon.PDNSDaemon == nil {
+		return errors.Errorf("daemon %d is not a PowerDNS daemon", daemon.ID)
+	}
+	daemon.PDNSDaemon.Config = nil
+	daemon.PDNSDaemon.ConfigHash = ""
+
+	rsp, err := agents.GetPowerDNSConfig(ctx, daemon)
+	if err != nil {
+		return errors.WithMessage(err, "problem getting PowerDNS configuration from the agent")
+	}
+	config, err := pdnsconfig.NewParser().Parse(rsp.ConfigPath, strings.NewReader(rsp.Config))
+	if err != nil {
+		return errors.WithMessagef(err, "problem parsing PowerDNS configuration file %s", rsp.ConfigPath)
+	}
+	daemon.PDNSDaemon.Config = config
+	daemon.PDNSDaemon.ConfigHash = storkutil.Fnv128(rsp.Config)
+	return nil
+}
+
 // Inserts or updates information about PowerDNS daemon in the database.
 func CommitDaemonIntoDB(db *pg.DB, daemon *dbmodel.Daemon, eventCenter eventcenter.EventCenter) (err error) {
 	if daemon.ID == 0 {
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	agentapi "isc.org/stork/api"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
 	pdnsdata "isc.org/stork/daemondata/pdns"
 	"isc.org/stork/datamodel/daemonname"
 	dbmodel "isc.org/stork/server/database/model"
@@ -141,6 +143,69 @@ func TestGetDaemonStateError(t *testing.T) {
 	require.Equal(t, "4.5.2", daemon.Version)
 }
 
+// Test getting and parsing the PowerDNS configuration.
+func TestGetDaemonConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	agents := NewMockConnectedAgents(ctrl)
+
+	agents.EXPECT().GetPowerDNSConfig(gomock.Any(), gomock.Any()).Return(&agentapi.GetPowerDNSConfigRsp{
+		ConfigPath: "/etc/powerdns/pdns.conf",
+		Config:     "api=yes\napi-key=********\nloglevel=6\nwebserver=yes\n",
+	}, nil)
+
+	machine := &dbmodel.Machine{
+		Address:   "127.0.0.1",
+		AgentPort: 1111,
+	}
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.PDNS, true, []*dbmodel.AccessPoint{
+		{
+			Type:    dbmodel.AccessPointControl,
+			Address: "127.0.0.1",
+			Port:    8081,
+		},
+	})
+
+	err := GetDaemonConfig(context.Background(), agents, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, daemon.PDNSDaemon.Config)
+	require.NotEmpty(t, daemon.PDNSDaemon.ConfigHash)
+	require.Equal(t, "********", daemon.PDNSDaemon.Config.GetAPIKey())
+	require.EqualValues(t, 6, *daemon.PDNSDaemon.Config.GetInt64("loglevel"))
+}
+
+// Test that the configuration is reset when getting it from the agent fails.
+func TestGetDaemonConfigError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	agents := NewMockConnectedAgents(ctrl)
+
+	agents.EXPECT().GetPowerDNSConfig(gomock.Any(), gomock.Any()).Return(nil, &testError{})
+
+	machine := &dbmodel.Machine{
+		Address:   "127.0.0.1",
+		AgentPort: 1111,
+	}
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.PDNS, true, []*dbmodel.AccessPoint{
+		{
+			Type:    dbmodel.AccessPointControl,
+			Address: "127.0.0.1",
+			Port:    8081,
+		},
+	})
+	daemon.PDNSDaemon.Config = &pdnsconfig.Config{}
+	daemon.PDNSDaemon.ConfigHash = "hash"
+
+	err := GetDaemonConfig(context.Background(), agents, daemon)
+	require.ErrorContains(t, err, "test error")
+	require.Nil(t, daemon.PDNSDaemon.Config)
+	require.Empty(t, daemon.PDNSDaemon.ConfigHash)
+}
+
 // Test inserting PowerDNS daemon into the database.
 func TestCommitDaemonIntoDB(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
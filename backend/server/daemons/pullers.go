@@ -3,6 +3,7 @@ package daemons
 import (
 	"isc.org/stork/server/daemons/bind9"
 	"isc.org/stork/server/daemons/kea"
+	"isc.org/stork/server/dnsop"
 )
 
 // Collection of pullers used by the server.
@@ -10,7 +11,9 @@ type Pullers struct {
 	StatePuller      *StatePuller
 	Bind9StatsPuller *bind9.StatsPuller
 	KeaStatsPuller   *kea.StatsPuller
+	KeaD2StatsPuller *kea.D2StatsPuller
 	KeaHostsPuller   *kea.HostsPuller
 	HAStatusPuller   *kea.HAStatusPuller
 	LeasesPuller     *kea.LeasesPuller
+	ZoneSyncPuller   *dnsop.ZoneSyncPuller
 }
@@ -463,6 +463,11 @@ func (puller *StatePuller) updateMachineAndDaemonsState(ctx context.Context, mac
 		case "bind9":
 			for _, daemon := range mergedDaemons {
 				bind9.GetDaemonState(ctx2, puller.state.Agents, daemon, puller.state.EventCenter)
+				if daemon.Active {
+					if err := bind9.GetDaemonConfig(ctx2, puller.state.Agents, daemon); err != nil {
+						log.WithError(err).Warnf("Cannot get configuration of BIND 9 daemon %s", daemon.GetLabel())
+					}
+				}
 				err = bind9.CommitDaemonIntoDB(puller.state.DB, daemon, puller.state.EventCenter)
 				if err != nil {
 					break
@@ -471,15 +476,24 @@ func (puller *StatePuller) updateMachineAndDaemonsState(ctx context.Context, mac
 				if err := puller.state.DNSManager.StartXFRTrackingForDaemon(daemon); err != nil {
 					log.WithError(err).Warnf("Cannot start zone transfer tracking for BIND 9 daemon with ID %d", daemon.ID)
 				}
+				// Schedule the configuration review if the configuration
+				// has changed since the last review.
+				conditionallyBeginDNSConfigReviews(puller.state.DB, daemon, puller.state.ReviewDispatcher)
 			}
 		case "pdns":
 			for _, daemon := range mergedDaemons {
 				pdns.GetDaemonState(ctx2, puller.state.Agents, daemon, puller.state.EventCenter)
+				if daemon.Active {
+					if err := pdns.GetDaemonConfig(ctx2, puller.state.Agents, daemon); err != nil {
+						log.WithError(err).Warnf("Cannot get configuration of PowerDNS daemon %s", daemon.GetLabel())
+					}
+				}
 				err = pdns.CommitDaemonIntoDB(puller.state.DB, daemon, puller.state.EventCenter)
 				if err != nil {
 					break
 				}
 				allDaemons = append(allDaemons, daemon)
+				conditionallyBeginDNSConfigReviews(puller.state.DB, daemon, puller.state.ReviewDispatcher)
 			}
 		default:
 			err = nil
@@ -523,12 +537,46 @@ func conditionallyBeginKeaConfigReviews(daemon *dbmodel.Daemon, state kea.Daemon
 	if isConfigModified {
 		triggers = append(triggers, configreview.ConfigModified)
 	}
+	if state.IsConfigDriftChanged {
+		triggers = append(triggers, configreview.ConfigDriftModified)
+	}
 
 	if len(triggers) != 0 {
 		_ = reviewDispatcher.BeginReview(daemon, triggers, nil)
 	}
 }
 
+// This function checks if a new BIND 9 or PowerDNS config review should be
+// performed. The DNS server configurations are not stored in the database,
+// so their hashes are compared with the hash stored with the last review. The review is also
+// performed when the dispatcher's signature has changed.
+func conditionallyBeginDNSConfigReviews(dbi dbops.DBI, daemon *dbmodel.Daemon, reviewDispatcher configreview.Dispatcher) {
+	// The configuration is not set when the daemon is inactive or it
+	// could not be fetched from the agent.
+	var configHash string
+	switch {
+	case daemon.Bind9Daemon != nil && daemon.Bind9Daemon.Config != nil:
+		configHash = daemon.Bind9Daemon.ConfigHash
+	case daemon.PDNSDaemon != nil && daemon.PDNSDaemon.Config != nil:
+		configHash = daemon.PDNSDaemon.ConfigHash
+	default:
+		return
+	}
+	configReview, err := dbmodel.GetConfigReviewByDaemonID(dbi, daemon.ID)
+	if err != nil {
+		log.WithError(err).Warnf("Cannot get the last config review of DNS daemon %s", daemon.GetLabel())
+		return
+	}
+	if configReview != nil &&
+		configReview.ConfigHash == configHash &&
+		configReview.Signature == reviewDispatcher.GetSignature() {
+		// Configuration of this daemon hasn't changed and the dispatcher has
+		// no checkers modified since the last review.
+		return
+	}
+	_ = reviewDispatcher.BeginReview(daemon, configreview.Triggers{configreview.ConfigModified}, nil)
+}
+
 // Reads the daemons from the Kea CA configuration file.
 // It is expected that the provided daemon is the Kea CA daemon.
 func getDaemonsFromKeaCAConfig(ctx context.Context, agents agentcomm.ConnectedAgents, daemon *agentcomm.Daemon) ([]*agentcomm.Daemon, error) {
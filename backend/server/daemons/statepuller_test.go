@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
 	keactrl "isc.org/stork/daemonctrl/kea"
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/datamodel/protocoltype"
@@ -1159,6 +1161,113 @@ func TestConditionallyBeginKeaConfigReviews(t *testing.T) {
 	require.Equal(t, configreview.ConfigModified, dispatcher.CallLog[6].Triggers[1])
 }
 
+// Test that new BIND 9 configuration review is scheduled when the daemon's
+// configuration hash differs from the hash of the reviewed configuration
+// or when review dispatcher's checkers have changed.
+func TestConditionallyBeginBind9ConfigReviews(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	dispatcher := &storktest.FakeDispatcher{Signature: "abc"}
+
+	// The configuration has not been fetched. The review should not
+	// be initiated.
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Empty(t, dispatcher.CallLog)
+
+	// The configuration has never been reviewed.
+	daemon.Bind9Daemon.Config = &bind9config.Config{}
+	daemon.Bind9Daemon.ConfigHash = "hash"
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 1)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[0].CallName)
+	require.Equal(t, configreview.Triggers{configreview.ConfigModified}, dispatcher.CallLog[0].Triggers)
+
+	// The configuration has been reviewed.
+	err = dbmodel.AddConfigReview(db, &dbmodel.ConfigReview{
+		DaemonID:   daemon.ID,
+		ConfigHash: "hash",
+		Signature:  "abc",
+	})
+	require.NoError(t, err)
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 2)
+	require.Equal(t, "GetSignature", dispatcher.CallLog[1].CallName)
+
+	// The checkers have changed.
+	dispatcher.Signature = "def"
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 4)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[3].CallName)
+
+	// The configuration has changed.
+	dispatcher.Signature = "abc"
+	daemon.Bind9Daemon.ConfigHash = "new-hash"
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 5)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[4].CallName)
+}
+
+// Test that the PowerDNS config review is initiated when the configuration
+// fetched from the agent has changed.
+func TestConditionallyBeginPDNSConfigReviews(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.PDNS, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	dispatcher := &storktest.FakeDispatcher{Signature: "abc"}
+
+	// The configuration has not been fetched. The review should not
+	// be initiated.
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Empty(t, dispatcher.CallLog)
+
+	// The configuration has never been reviewed.
+	daemon.PDNSDaemon.Config = &pdnsconfig.Config{}
+	daemon.PDNSDaemon.ConfigHash = "hash"
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 1)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[0].CallName)
+
+	// The configuration has been reviewed.
+	err = dbmodel.AddConfigReview(db, &dbmodel.ConfigReview{
+		DaemonID:   daemon.ID,
+		ConfigHash: "hash",
+		Signature:  "abc",
+	})
+	require.NoError(t, err)
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 2)
+	require.Equal(t, "GetSignature", dispatcher.CallLog[1].CallName)
+
+	// The configuration has changed.
+	daemon.PDNSDaemon.ConfigHash = "new-hash"
+	conditionallyBeginDNSConfigReviews(db, daemon, dispatcher)
+	require.Len(t, dispatcher.CallLog, 3)
+	require.Equal(t, "BeginReview", dispatcher.CallLog[2].CallName)
+}
+
 // Test that concurrent pulls should not cause data duplication.
 func TestStatePullerConcurrentPulls(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
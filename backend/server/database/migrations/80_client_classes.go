@@ -0,0 +1,37 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Client classes configured in the Kea DHCP servers. The classes
+			-- are stored per daemon because the class with the same name may
+			-- have different definitions on different servers.
+			CREATE TABLE IF NOT EXISTS public.client_class (
+				id BIGSERIAL NOT NULL,
+				created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				daemon_id BIGINT NOT NULL,
+				name TEXT NOT NULL,
+				kea_parameters JSONB,
+				CONSTRAINT client_class_pkey PRIMARY KEY (id),
+				CONSTRAINT client_class_daemon_id_name_unique UNIQUE (daemon_id, name),
+				CONSTRAINT client_class_daemon_id_fkey FOREIGN KEY (daemon_id)
+					REFERENCES public.daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+
+			-- Create an index on the name column to speed up filtering by name.
+			CREATE INDEX IF NOT EXISTS client_class_name_idx
+				ON public.client_class USING btree (name);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP INDEX IF EXISTS client_class_name_idx;
+			DROP TABLE IF EXISTS public.client_class;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,28 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Statistics returned by the Kea DHCP-DDNS (D2) servers. Only
+			-- the most recent values are stored for each daemon.
+			CREATE TABLE IF NOT EXISTS public.kea_d2_statistics (
+				daemon_id BIGINT NOT NULL,
+				collected_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				statistics JSONB,
+				CONSTRAINT kea_d2_statistics_pkey PRIMARY KEY (daemon_id),
+				CONSTRAINT kea_d2_statistics_daemon_id_fkey FOREIGN KEY (daemon_id)
+					REFERENCES public.daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.kea_d2_statistics;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,62 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Versioned snapshots of the Kea daemons' configurations. A new
+			-- version is stored whenever a distinct configuration is fetched
+			-- from the daemon.
+			CREATE TABLE IF NOT EXISTS public.kea_config_version (
+				id BIGSERIAL NOT NULL,
+				daemon_id BIGINT NOT NULL,
+				version BIGINT NOT NULL,
+				created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				config JSONB NOT NULL,
+				config_hash TEXT,
+				source TEXT NOT NULL,
+				operation TEXT,
+				user_id BIGINT,
+				CONSTRAINT kea_config_version_pkey PRIMARY KEY (id),
+				CONSTRAINT kea_config_version_daemon_id_version_key UNIQUE (daemon_id, version),
+				CONSTRAINT kea_config_version_daemon_id_fkey FOREIGN KEY (daemon_id)
+					REFERENCES public.daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT kea_config_version_user_id_fkey FOREIGN KEY (user_id)
+					REFERENCES public.system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+
+			-- Configuration changes applied by Stork which haven't been
+			-- fetched from the daemons yet. They are used to attribute the
+			-- new configuration versions to the users.
+			CREATE TABLE IF NOT EXISTS public.kea_config_pending_change (
+				id BIGSERIAL NOT NULL,
+				daemon_id BIGINT NOT NULL,
+				created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				operation TEXT NOT NULL,
+				user_id BIGINT,
+				CONSTRAINT kea_config_pending_change_pkey PRIMARY KEY (id),
+				CONSTRAINT kea_config_pending_change_daemon_id_fkey FOREIGN KEY (daemon_id)
+					REFERENCES public.daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT kea_config_pending_change_user_id_fkey FOREIGN KEY (user_id)
+					REFERENCES public.system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+			CREATE INDEX kea_config_pending_change_daemon_id_idx ON public.kea_config_pending_change (daemon_id);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.kea_config_pending_change;
+			DROP TABLE IF EXISTS public.kea_config_version;
+		`)
+		return err
+	})
+}
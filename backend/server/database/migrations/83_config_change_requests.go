@@ -0,0 +1,51 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Stork uses hard-coded IDs for the built-in groups. The members
+			-- of the approver group can approve or reject the configuration
+			-- change requests submitted by other users.
+			INSERT INTO system_group (id, name, description) VALUES (4, 'approver', 'Users that belong to this group can approve or reject configuration changes submitted by other users. This group grants no other permissions, so it should be combined with the admin or super-admin group.');
+			-- Reset the primary key sequence to be in sync with max ID in the table.
+			SELECT setval('system_group_id_seq', MAX(id)) FROM system_group;
+
+			-- Configuration changes awaiting an approval of a second user
+			-- before they are sent to the daemons.
+			CREATE TABLE IF NOT EXISTS public.config_change_request (
+				id BIGSERIAL NOT NULL,
+				created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				deadline_at TIMESTAMP WITHOUT TIME ZONE,
+				user_id BIGINT NOT NULL,
+				status TEXT NOT NULL,
+				reviewer_id BIGINT,
+				reviewed_at TIMESTAMP WITHOUT TIME ZONE,
+				comment TEXT,
+				error TEXT,
+				updates JSONB NOT NULL,
+				diff JSONB,
+				CONSTRAINT config_change_request_pkey PRIMARY KEY (id),
+				CONSTRAINT config_change_request_user_id_fkey FOREIGN KEY (user_id)
+					REFERENCES public.system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT config_change_request_reviewer_id_fkey FOREIGN KEY (reviewer_id)
+					REFERENCES public.system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+			CREATE INDEX config_change_request_status_idx ON public.config_change_request (status);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.config_change_request;
+			DELETE FROM system_group WHERE id = 4;
+			-- Reset the primary key sequence to be in sync with max ID in the table.
+			SELECT setval('system_group_id_seq', MAX(id)) FROM system_group;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,39 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Historical utilization of the subnets, shared networks and pools.
+			-- The recent samples are stored as collected by the stats puller.
+			-- The older samples are downsampled to the hourly and daily averages.
+			-- The object_id refers to a subnet, shared network, address pool
+			-- or prefix pool, depending on the object_type. There is no foreign
+			-- key because the samples of the deleted objects are aged off
+			-- separately.
+			CREATE TABLE IF NOT EXISTS public.utilization_sample (
+				object_type TEXT NOT NULL,
+				object_id BIGINT NOT NULL,
+				resolution TEXT NOT NULL,
+				sampled_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+				addr_utilization SMALLINT,
+				pd_utilization SMALLINT,
+				CONSTRAINT utilization_sample_pkey PRIMARY KEY (object_type, object_id, resolution, sampled_at),
+				CONSTRAINT utilization_sample_object_type_check CHECK (
+					object_type IN ('subnet', 'shared-network', 'address-pool', 'prefix-pool')
+				),
+				CONSTRAINT utilization_sample_resolution_check CHECK (
+					resolution IN ('raw', 'hour', 'day')
+				)
+			);
+			CREATE INDEX utilization_sample_sampled_at_idx ON public.utilization_sample (resolution, sampled_at);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.utilization_sample;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,67 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Lease lifecycle events observed by the agents in the lease files.
+			-- The lease columns hold the lease state after the transition. The
+			-- events are removed when the daemon is deleted, but they are kept
+			-- when the subnet is deleted.
+			CREATE TABLE IF NOT EXISTS public.lease_event (
+				id                 BIGSERIAL NOT NULL,
+				event_type         TEXT NOT NULL,
+				occurred_at        TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+				-- Unix time in nanoseconds when the agent observed the event.
+				observed_at        BIGINT NOT NULL,
+				daemon_id          BIGINT NOT NULL,
+				subnet_id          BIGINT,
+				local_subnet_id    BIGINT,
+				family             SMALLINT NOT NULL,
+				client_id          BYTEA,
+				hostname           TEXT,
+				hw_address         MACADDR,
+				hw_address_source  VARCHAR(255),
+				hw_type            INTEGER,
+				duid               BYTEA,
+				ip_address         INET NOT NULL,
+				type               VARCHAR(255),
+				cltt               BIGINT NOT NULL,
+				state              SMALLINT NOT NULL,
+				user_context       JSONB,
+				valid_lifetime     BIGINT,
+				iaid               BIGINT,
+				preferred_lifetime BIGINT,
+				fqdn_fwd           BOOLEAN,
+				fqdn_rev           BOOLEAN,
+				prefix_length      SMALLINT,
+				CONSTRAINT lease_event_pkey PRIMARY KEY (id),
+				CONSTRAINT lease_event_event_type_check CHECK (
+					event_type IN ('assigned', 'renewed', 'released', 'expired', 'declined')
+				),
+				-- The agent sends the same events again when it is restarted.
+				CONSTRAINT lease_event_unique UNIQUE (daemon_id, ip_address, event_type, cltt),
+				CONSTRAINT lease_event_daemon_fkey FOREIGN KEY (daemon_id)
+					REFERENCES daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT lease_event_subnet_fkey FOREIGN KEY (subnet_id)
+					REFERENCES subnet (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+			-- Index to efficiently find the most recent event observed by the agent.
+			CREATE INDEX lease_event_daemon_observed_at_idx ON public.lease_event (daemon_id, observed_at);
+			-- Indexes to efficiently search the events by address and time.
+			CREATE INDEX lease_event_ip_address_idx ON public.lease_event (ip_address, occurred_at);
+			CREATE INDEX lease_event_occurred_at_idx ON public.lease_event (occurred_at);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.lease_event;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,42 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Temporary changes of the Kea loggers' severities. The changes
+			-- are reverted when they expire. The original severity is null
+			-- when the logger was not configured before the change.
+			CREATE TABLE IF NOT EXISTS public.kea_log_level_change (
+				id                   BIGSERIAL NOT NULL,
+				created_at           TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				expires_at           TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+				daemon_id            BIGINT NOT NULL,
+				user_id              BIGINT,
+				logger_name          TEXT NOT NULL,
+				severity             TEXT NOT NULL,
+				debug_level          INTEGER NOT NULL DEFAULT 0,
+				original_severity    TEXT,
+				original_debug_level INTEGER NOT NULL DEFAULT 0,
+				CONSTRAINT kea_log_level_change_pkey PRIMARY KEY (id),
+				CONSTRAINT kea_log_level_change_daemon_id_logger_name_key UNIQUE (daemon_id, logger_name),
+				CONSTRAINT kea_log_level_change_daemon_id_fkey FOREIGN KEY (daemon_id)
+					REFERENCES public.daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT kea_log_level_change_user_id_fkey FOREIGN KEY (user_id)
+					REFERENCES public.system_user (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+			);
+			CREATE INDEX kea_log_level_change_expires_at_idx ON public.kea_log_level_change (expires_at);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.kea_log_level_change;
+		`)
+		return err
+	})
+}
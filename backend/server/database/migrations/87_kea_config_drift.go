@@ -0,0 +1,19 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Differences between the running Kea configuration and the
+			-- configuration file found by the Stork agent.
+			ALTER TABLE public.kea_daemon ADD COLUMN config_drift JSONB;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE public.kea_daemon DROP COLUMN config_drift;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,32 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- User-defined configuration review rules. Each rule is
+			-- registered in the config review dispatcher as a checker with
+			-- the rule name.
+			CREATE TABLE IF NOT EXISTS public.config_review_rule (
+				id          BIGSERIAL NOT NULL,
+				created_at  TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT timezone('utc'::text, now()),
+				name        TEXT NOT NULL,
+				description TEXT,
+				selector    TEXT NOT NULL,
+				scope       TEXT NOT NULL,
+				triggers    TEXT[],
+				expression  TEXT NOT NULL,
+				message     TEXT NOT NULL,
+				CONSTRAINT config_review_rule_pkey PRIMARY KEY (id),
+				CONSTRAINT config_review_rule_name_key UNIQUE (name)
+			);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS public.config_review_rule;
+		`)
+		return err
+	})
+}
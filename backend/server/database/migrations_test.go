@@ -23,7 +23,7 @@ import (
 
 // Current schema version. This value must be bumped up every
 // time the schema is updated.
-const expectedSchemaVersion int64 = 79
+const expectedSchemaVersion int64 = 88
 
 // Common function which tests a selected migration action.
 func testMigrateAction(t *testing.T, db *dbops.PgDB, expectedOldVersion, expectedNewVersion int64, action ...string) {
@@ -889,7 +889,7 @@ func TestMigrateFromDemoV2_3_0ToLatest(t *testing.T) {
 	require.NoError(t, err)
 	settings, err := dbmodel.GetAllSettings(db)
 	require.NoError(t, err)
-	require.Len(t, settings, 11)
+	require.Len(t, settings, 18)
 
 	expectSettings := map[string]any{
 		"kea_status_puller_interval":      int64(30),
@@ -900,9 +900,16 @@ func TestMigrateFromDemoV2_3_0ToLatest(t *testing.T) {
 		"state_puller_interval":           int64(30),
 		"bind9_stats_puller_interval":     int64(60),
 		"kea_stats_puller_interval":       int64(60),
+		"kea_d2_stats_puller_interval":    int64(60),
 		"kea_hosts_puller_interval":       int64(60),
 		"kea_leases_puller_interval":      int64(60),
 		"enable_online_software_versions": true,
+		"enable_config_change_approval":   false,
+		"utilization_forecast_horizon":    int64(30),
+		"lease_history_retention":         int64(90),
+		"zone_sync_puller_interval":       int64(600),
+		"zone_serial_lag_threshold":       int64(3600),
+		"enable_zone_sync_refresh":        false,
 	}
 
 	for expectedKey, expectedValue := range expectSettings {
@@ -0,0 +1,247 @@
+package dbmodel
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	pkgerrors "github.com/pkg/errors"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	dbops "isc.org/stork/server/database"
+)
+
+// Represents a client class configured in a Kea DHCP server. The classes
+// are stored per daemon because the classes with the same name may have
+// different definitions in different servers. The complete class
+// definition fetched from the Kea configuration is held in the
+// KeaParameters field.
+type ClientClass struct {
+	ID        int64
+	CreatedAt time.Time
+	DaemonID  int64
+	Name      string
+
+	KeaParameters *keaconfig.ClientClass
+
+	Daemon *Daemon `pg:"rel:has-one"`
+}
+
+// Holds the subnets, pools and host reservations referencing a client
+// class in a particular daemon.
+type ClientClassUsage struct {
+	Subnets      []Subnet
+	AddressPools []AddressPool
+	PrefixPools  []PrefixPool
+	Hosts        []Host
+}
+
+// Returns a where clause expression checking whether the Kea parameters
+// stored in the specified column reference a client class. The class name
+// is expected to be passed as the first query parameter.
+func clientClassReferenceExpr(column string) string {
+	return fmt.Sprintf(`(%[1]s->>'client-class' = ?0
+		OR %[1]s->'client-classes' @> to_jsonb(?0::text)
+		OR %[1]s->'require-client-classes' @> to_jsonb(?0::text)
+		OR %[1]s->'evaluate-additional-classes' @> to_jsonb(?0::text))`, column)
+}
+
+// Inserts a client class into the database or updates the existing one
+// if the class with the same name already exists for the daemon.
+func AddClientClass(dbi dbops.DBI, class *ClientClass) error {
+	_, err := dbi.Model(class).
+		OnConflict("(daemon_id, name) DO UPDATE").
+		Set("kea_parameters = EXCLUDED.kea_parameters").
+		Returning("id").
+		Insert()
+	if err != nil {
+		err = pkgerrors.Wrapf(err, "problem adding client class %s for daemon %d", class.Name, class.DaemonID)
+	}
+	return err
+}
+
+// Replaces the client classes of the specified daemon with the classes
+// from its configuration. It inserts new classes, updates existing ones
+// and removes the classes that are no longer configured. The IDs of the
+// existing classes are preserved.
+func CommitClientClassesIntoDB(dbi dbops.DBI, daemonID int64, classes []keaconfig.ClientClass) error {
+	names := []string{}
+	for i := range classes {
+		class := &ClientClass{
+			DaemonID:      daemonID,
+			Name:          classes[i].Name,
+			KeaParameters: &classes[i],
+		}
+		if err := AddClientClass(dbi, class); err != nil {
+			return err
+		}
+		names = append(names, classes[i].Name)
+	}
+	q := dbi.Model((*ClientClass)(nil)).Where("daemon_id = ?", daemonID)
+	if len(names) > 0 {
+		q = q.Where("name NOT IN (?)", pg.In(names))
+	}
+	if _, err := q.Delete(); err != nil {
+		return pkgerrors.Wrapf(err, "problem deleting stale client classes for daemon %d", daemonID)
+	}
+	return nil
+}
+
+// Returns a client class by ID. The returned class includes the daemon and
+// the machine it belongs to. If the class does not exist, nil is returned.
+func GetClientClassByID(dbi dbops.DBI, id int64) (*ClientClass, error) {
+	class := &ClientClass{}
+	err := dbi.Model(class).
+		Relation("Daemon.Machine").
+		Relation("Daemon.KeaDaemon").
+		Relation("Daemon.AccessPoints").
+		Where("client_class.id = ?", id).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting client class with ID %d", id)
+	}
+	return class, nil
+}
+
+// Returns a client class with the specified name configured in the
+// specified daemon. If the class does not exist, nil is returned.
+func GetClientClassByDaemonIDAndName(dbi dbops.DBI, daemonID int64, name string) (*ClientClass, error) {
+	class := &ClientClass{}
+	err := dbi.Model(class).
+		Where("client_class.daemon_id = ?", daemonID).
+		Where("client_class.name = ?", name).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting client class %s for daemon %d", name, daemonID)
+	}
+	return class, nil
+}
+
+// Returns the client classes configured in the specified daemon, ordered
+// by name.
+func GetClientClassesByDaemonID(dbi dbops.DBI, daemonID int64) ([]ClientClass, error) {
+	classes := []ClientClass{}
+	err := dbi.Model(&classes).
+		Where("client_class.daemon_id = ?", daemonID).
+		OrderExpr("client_class.name ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting client classes for daemon %d", daemonID)
+	}
+	return classes, nil
+}
+
+// Fetches a collection of client classes from the database. The offset and
+// limit specify the beginning of the page and the maximum size of the page.
+// The daemonID is used to filter the classes to those configured in the
+// given daemon. It is ignored when it is 0. The filterText can be used to
+// match the class name or its test expression. The nil value disables such
+// filtering. The classes are ordered by name and daemon ID. This function
+// returns a collection of classes, the total number of classes and error.
+func GetClientClassesByPage(dbi dbops.DBI, offset, limit, daemonID int64, filterText *string) ([]ClientClass, int64, error) {
+	classes := []ClientClass{}
+	q := dbi.Model(&classes).
+		Relation("Daemon.Machine")
+
+	if daemonID != 0 {
+		q = q.Where("client_class.daemon_id = ?", daemonID)
+	}
+
+	if filterText != nil {
+		text := "%" + *filterText + "%"
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("client_class.name ILIKE ?", text).
+				WhereOr("client_class.kea_parameters->>'test' ILIKE ?", text)
+			return q, nil
+		})
+	}
+
+	q = q.OrderExpr("client_class.name ASC, client_class.daemon_id ASC").
+		Offset(int(offset)).
+		Limit(int(limit))
+
+	total, err := q.SelectAndCount()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, 0, nil
+		}
+		err = pkgerrors.Wrapf(err, "problem getting client classes by page")
+	}
+	return classes, int64(total), err
+}
+
+// Returns the subnets, pools and host reservations referencing the
+// specified client class in the specified daemon.
+func GetClientClassUsage(dbi dbops.DBI, daemonID int64, name string) (*ClientClassUsage, error) {
+	usage := &ClientClassUsage{
+		Subnets:      []Subnet{},
+		AddressPools: []AddressPool{},
+		PrefixPools:  []PrefixPool{},
+		Hosts:        []Host{},
+	}
+
+	err := dbi.Model(&usage.Subnets).
+		Join("JOIN local_subnet AS ls").JoinOn("ls.subnet_id = subnet.id").
+		Where("ls.daemon_id = ?", daemonID).
+		Where(clientClassReferenceExpr("ls.kea_parameters"), name).
+		OrderExpr("subnet.id ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting subnets using client class %s", name)
+	}
+
+	err = dbi.Model(&usage.AddressPools).
+		Relation("LocalSubnet.Subnet").
+		Where("local_subnet.daemon_id = ?", daemonID).
+		Where(clientClassReferenceExpr("address_pool.kea_parameters"), name).
+		OrderExpr("address_pool.id ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting address pools using client class %s", name)
+	}
+
+	err = dbi.Model(&usage.PrefixPools).
+		Relation("LocalSubnet.Subnet").
+		Where("local_subnet.daemon_id = ?", daemonID).
+		Where(clientClassReferenceExpr("prefix_pool.kea_parameters"), name).
+		OrderExpr("prefix_pool.id ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting prefix pools using client class %s", name)
+	}
+
+	err = dbi.Model(&usage.Hosts).
+		DistinctOn("host.id").
+		Relation("HostIdentifiers").
+		Join("JOIN local_host AS lh").JoinOn("lh.host_id = host.id").
+		Where("lh.daemon_id = ?", daemonID).
+		Where("? = ANY(lh.client_classes)", name).
+		OrderExpr("host.id ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting hosts using client class %s", name)
+	}
+	return usage, nil
+}
+
+// Deletes a client class with the specified ID. It returns ErrNotExists
+// when the class does not exist.
+func DeleteClientClass(dbi dbops.DBI, id int64) error {
+	class := &ClientClass{
+		ID: id,
+	}
+	result, err := dbi.Model(class).WherePK().Delete()
+	if err != nil {
+		err = pkgerrors.Wrapf(err, "problem deleting client class with ID %d", id)
+	} else if result.RowsAffected() <= 0 {
+		err = pkgerrors.Wrapf(ErrNotExists, "client class with ID %d does not exist", id)
+	}
+	return err
+}
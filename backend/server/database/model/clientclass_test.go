@@ -0,0 +1,314 @@
+package dbmodel
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	dbops "isc.org/stork/server/database"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds a machine with a DHCPv4 daemon to be used in the client class tests.
+func addTestClientClassDaemon(t *testing.T, db *dbops.PgDB) *Daemon {
+	machine := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := NewDaemon(machine, daemonname.DHCPv4, true, []*AccessPoint{{
+		Type:    AccessPointControl,
+		Address: "localhost",
+		Port:    8000,
+	}})
+	err = AddDaemon(db, daemon)
+	require.NoError(t, err)
+	return daemon
+}
+
+// Creates a client class with the specified name and test expression.
+func newTestClientClass(name, test string) keaconfig.ClientClass {
+	return keaconfig.ClientClass{
+		ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+			Name: name,
+			Test: storkutil.Ptr(test),
+		},
+	}
+}
+
+// Test that the client class is added and then fetched by ID and by name.
+func TestAddClientClass(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClientClassDaemon(t, db)
+
+	params := newTestClientClass("foo", "member('ALL')")
+	params.NextServer = storkutil.Ptr("192.0.2.1")
+	class := &ClientClass{
+		DaemonID:      daemon.ID,
+		Name:          "foo",
+		KeaParameters: &params,
+	}
+	err := AddClientClass(db, class)
+	require.NoError(t, err)
+	require.NotZero(t, class.ID)
+
+	returned, err := GetClientClassByID(db, class.ID)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+	require.Equal(t, "foo", returned.Name)
+	require.NotNil(t, returned.Daemon)
+	require.NotNil(t, returned.Daemon.Machine)
+	require.NotNil(t, returned.KeaParameters)
+	require.Equal(t, "member('ALL')", returned.KeaParameters.GetTest())
+	require.Equal(t, "192.0.2.1", *returned.KeaParameters.NextServer)
+
+	returned, err = GetClientClassByDaemonIDAndName(db, daemon.ID, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+	require.Equal(t, class.ID, returned.ID)
+
+	// Adding the class with the same name should update the existing one.
+	params = newTestClientClass("foo", "member('KNOWN')")
+	class = &ClientClass{
+		DaemonID:      daemon.ID,
+		Name:          "foo",
+		KeaParameters: &params,
+	}
+	err = AddClientClass(db, class)
+	require.NoError(t, err)
+
+	classes, err := GetClientClassesByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, classes, 1)
+	require.Equal(t, "member('KNOWN')", classes[0].KeaParameters.GetTest())
+}
+
+// Test that nil is returned when the class does not exist.
+func TestGetClientClassNonExisting(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	class, err := GetClientClassByID(db, 123)
+	require.NoError(t, err)
+	require.Nil(t, class)
+
+	class, err = GetClientClassByDaemonIDAndName(db, 123, "foo")
+	require.NoError(t, err)
+	require.Nil(t, class)
+}
+
+// Test that the client classes from the configuration replace the classes
+// stored in the database.
+func TestCommitClientClassesIntoDB(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClientClassDaemon(t, db)
+
+	err := CommitClientClassesIntoDB(db, daemon.ID, []keaconfig.ClientClass{
+		newTestClientClass("foo", "member('ALL')"),
+		newTestClientClass("bar", "member('KNOWN')"),
+	})
+	require.NoError(t, err)
+
+	classes, err := GetClientClassesByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+	require.Equal(t, "bar", classes[0].Name)
+	require.Equal(t, "foo", classes[1].Name)
+	fooID := classes[1].ID
+
+	// Update the foo class, remove the bar class and add the baz class.
+	err = CommitClientClassesIntoDB(db, daemon.ID, []keaconfig.ClientClass{
+		newTestClientClass("foo", "member('UNKNOWN')"),
+		newTestClientClass("baz", "member('DROP')"),
+	})
+	require.NoError(t, err)
+
+	classes, err = GetClientClassesByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+	require.Equal(t, "baz", classes[0].Name)
+	require.Equal(t, "foo", classes[1].Name)
+	require.Equal(t, fooID, classes[1].ID)
+	require.Equal(t, "member('UNKNOWN')", classes[1].KeaParameters.GetTest())
+
+	// Removing all classes from the configuration should remove them
+	// from the database.
+	err = CommitClientClassesIntoDB(db, daemon.ID, []keaconfig.ClientClass{})
+	require.NoError(t, err)
+
+	classes, err = GetClientClassesByDaemonID(db, daemon.ID)
+	require.NoError(t, err)
+	require.Empty(t, classes)
+}
+
+// Test getting the client classes by page with filtering.
+func TestGetClientClassesByPage(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClientClassDaemon(t, db)
+
+	err := CommitClientClassesIntoDB(db, daemon.ID, []keaconfig.ClientClass{
+		newTestClientClass("foo", "member('ALL')"),
+		newTestClientClass("bar", "member('KNOWN')"),
+		newTestClientClass("baz", "option[61].hex == 0x01"),
+	})
+	require.NoError(t, err)
+
+	classes, total, err := GetClientClassesByPage(db, 0, 2, 0, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, classes, 2)
+	require.Equal(t, "bar", classes[0].Name)
+	require.Equal(t, "baz", classes[1].Name)
+	require.NotNil(t, classes[0].Daemon)
+	require.NotNil(t, classes[0].Daemon.Machine)
+
+	classes, total, err = GetClientClassesByPage(db, 2, 2, daemon.ID, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, classes, 1)
+	require.Equal(t, "foo", classes[0].Name)
+
+	// Filter by the test expression.
+	classes, total, err = GetClientClassesByPage(db, 0, 10, 0, storkutil.Ptr("option[61]"))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Len(t, classes, 1)
+	require.Equal(t, "baz", classes[0].Name)
+
+	// Filter by non-matching daemon.
+	classes, total, err = GetClientClassesByPage(db, 0, 10, daemon.ID+1, nil)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, classes)
+}
+
+// Test that the subnets, pools and hosts using the client class are
+// returned.
+func TestGetClientClassUsage(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClientClassDaemon(t, db)
+
+	subnet := &Subnet{
+		Prefix: "192.0.2.0/24",
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID:      daemon.ID,
+				LocalSubnetID: 1,
+				KeaParameters: keaconfig.SubnetParameters{
+					ClientClassParameters: keaconfig.ClientClassParameters{
+						ClientClass: storkutil.Ptr("foo"),
+					},
+				},
+				AddressPools: []AddressPool{
+					{
+						LowerBound: "192.0.2.10",
+						UpperBound: "192.0.2.20",
+						KeaParameters: keaconfig.PoolParameters{
+							ClientClassParameters: keaconfig.ClientClassParameters{
+								RequireClientClasses: []string{"bar", "foo"},
+							},
+						},
+					},
+					{
+						LowerBound: "192.0.2.30",
+						UpperBound: "192.0.2.40",
+						KeaParameters: keaconfig.PoolParameters{
+							ClientClassParameters: keaconfig.ClientClassParameters{
+								ClientClass: storkutil.Ptr("bar"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := AddSubnet(db, subnet)
+	require.NoError(t, err)
+	err = SetLocalSubnets(db, subnet)
+	require.NoError(t, err)
+
+	host := &Host{
+		HostIdentifiers: []HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		LocalHosts: []LocalHost{
+			{
+				DaemonID:      daemon.ID,
+				DataSource:    HostDataSourceConfig,
+				ClientClasses: []string{"baz", "foo"},
+			},
+		},
+	}
+	err = AddHost(db, host)
+	require.NoError(t, err)
+
+	usage, err := GetClientClassUsage(db, daemon.ID, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, usage)
+	require.Len(t, usage.Subnets, 1)
+	require.Equal(t, subnet.ID, usage.Subnets[0].ID)
+	require.Len(t, usage.AddressPools, 1)
+	require.Equal(t, "192.0.2.10", usage.AddressPools[0].LowerBound)
+	require.NotNil(t, usage.AddressPools[0].LocalSubnet)
+	require.NotNil(t, usage.AddressPools[0].LocalSubnet.Subnet)
+	require.Equal(t, "192.0.2.0/24", usage.AddressPools[0].LocalSubnet.Subnet.Prefix)
+	require.Empty(t, usage.PrefixPools)
+	require.Len(t, usage.Hosts, 1)
+	require.Equal(t, host.ID, usage.Hosts[0].ID)
+	require.Len(t, usage.Hosts[0].HostIdentifiers, 1)
+
+	usage, err = GetClientClassUsage(db, daemon.ID, "bar")
+	require.NoError(t, err)
+	require.Empty(t, usage.Subnets)
+	require.Len(t, usage.AddressPools, 2)
+	require.Empty(t, usage.Hosts)
+
+	usage, err = GetClientClassUsage(db, daemon.ID, "qux")
+	require.NoError(t, err)
+	require.Empty(t, usage.Subnets)
+	require.Empty(t, usage.AddressPools)
+	require.Empty(t, usage.PrefixPools)
+	require.Empty(t, usage.Hosts)
+}
+
+// Test deleting the client class.
+func TestDeleteClientClass(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClientClassDaemon(t, db)
+
+	params := newTestClientClass("foo", "member('ALL')")
+	class := &ClientClass{
+		DaemonID:      daemon.ID,
+		Name:          "foo",
+		KeaParameters: &params,
+	}
+	err := AddClientClass(db, class)
+	require.NoError(t, err)
+
+	err = DeleteClientClass(db, class.ID)
+	require.NoError(t, err)
+
+	returned, err := GetClientClassByID(db, class.ID)
+	require.NoError(t, err)
+	require.Nil(t, returned)
+
+	err = DeleteClientClass(db, class.ID)
+	require.ErrorIs(t, err, ErrNotExists)
+}
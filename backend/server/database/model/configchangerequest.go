@@ -0,0 +1,178 @@
+package dbmodel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	dbops "isc.org/stork/server/database"
+	storkutil "isc.org/stork/util"
+)
+
+// Status of the config change request.
+type ConfigChangeRequestStatus string
+
+const (
+	// The request awaits the decision of the approver.
+	ConfigChangeRequestStatusPending ConfigChangeRequestStatus = "pending"
+	// The request has been approved and the config change has been
+	// committed or scheduled.
+	ConfigChangeRequestStatusApproved ConfigChangeRequestStatus = "approved"
+	// The request has been rejected and the config change has been
+	// discarded.
+	ConfigChangeRequestStatusRejected ConfigChangeRequestStatus = "rejected"
+	// The request has been approved but committing the config change
+	// failed. The request can be approved again to retry the commit or
+	// rejected.
+	ConfigChangeRequestStatusFailed ConfigChangeRequestStatus = "failed"
+)
+
+// Describes the differences in the configuration of a single daemon
+// caused by a config change.
+type ConfigChangeDaemonDiff struct {
+	DaemonID int64
+	Entries  []keaconfig.ConfigDiffEntry
+}
+
+// Represents a config change submitted by a user that must be approved
+// by another user (i.e., a member of the approver group) before it is
+// committed. The config change is stored in the same format as the
+// scheduled config changes. The request also holds the differences in
+// the daemons' configurations computed at the time of the submission
+// to help the approver evaluate the change.
+type ConfigChangeRequest struct {
+	ID        int64
+	CreatedAt time.Time
+	// Optional time when the config change should be committed after
+	// approval. The config change is committed right away when it is
+	// approved if this value is nil or is in the past.
+	DeadlineAt *time.Time
+
+	UserID int64
+	User   *SystemUser `pg:"rel:has-one"`
+
+	Status ConfigChangeRequestStatus
+
+	ReviewerID *int64
+	Reviewer   *SystemUser `pg:"rel:has-one"`
+	ReviewedAt *time.Time
+	Comment    string
+
+	Updates []*ConfigUpdate `pg:",json_use_number"`
+	Diff    []ConfigChangeDaemonDiff
+
+	// An error returned while committing the approved config change.
+	Error string
+}
+
+// Checks if any of the updates pertain to Kea.
+func (r ConfigChangeRequest) HasKeaUpdates() bool {
+	return HasKeaConfigUpdates(r.Updates)
+}
+
+// Inserts the config change request into the database.
+func AddConfigChangeRequest(dbi dbops.DBI, request *ConfigChangeRequest) error {
+	_, err := dbi.Model(request).Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with adding config change request")
+	}
+	return nil
+}
+
+// Returns a page of the config change requests from the most recent one.
+// The status is optional. If it is specified, only the requests having this
+// status are returned. It also returns the total number of the requests
+// matching the filter.
+func GetConfigChangeRequestsByPage(dbi dbops.DBI, offset, limit int64, status *ConfigChangeRequestStatus) ([]ConfigChangeRequest, int64, error) {
+	requests := []ConfigChangeRequest{}
+	q := dbi.Model(&requests).
+		Relation("User").
+		Relation("Reviewer").
+		OrderExpr("config_change_request.id DESC").
+		Offset(int(offset))
+	if status != nil {
+		q = q.Where("config_change_request.status = ?", *status)
+	}
+	if limit > 0 {
+		q = q.Limit(int(limit))
+	}
+	total, err := q.SelectAndCount()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return requests, 0, nil
+		}
+		return nil, 0, pkgerrors.Wrapf(err, "problem with getting config change requests")
+	}
+	return requests, int64(total), nil
+}
+
+// Returns the config change request by ID or nil if it doesn't exist.
+func GetConfigChangeRequestByID(dbi dbops.DBI, requestID int64) (*ConfigChangeRequest, error) {
+	request := &ConfigChangeRequest{}
+	err := dbi.Model(request).
+		Relation("User").
+		Relation("Reviewer").
+		Where("config_change_request.id = ?", requestID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem with getting config change request with id %d", requestID)
+	}
+	return request, nil
+}
+
+// Records the decision of the reviewer for the pending or failed config
+// change request. The status must be approved or rejected. The error of
+// the previous commit attempt is cleared. It returns ErrNotExists if the
+// request doesn't exist or it has been already reviewed. It guarantees that
+// only one reviewer can make a decision about the request.
+func SetConfigChangeRequestReviewed(dbi dbops.DBI, requestID int64, status ConfigChangeRequestStatus, reviewerID int64, comment string) error {
+	if status != ConfigChangeRequestStatusApproved && status != ConfigChangeRequestStatusRejected {
+		return pkgerrors.Errorf("invalid status %s of the reviewed config change request %d", status, requestID)
+	}
+	reviewedAt := storkutil.UTCNow()
+	request := &ConfigChangeRequest{
+		ID:         requestID,
+		Status:     status,
+		ReviewerID: &reviewerID,
+		ReviewedAt: &reviewedAt,
+		Comment:    comment,
+	}
+	result, err := dbi.Model(request).
+		Column("status", "reviewer_id", "reviewed_at", "comment", "error").
+		WherePK().
+		WhereIn("status IN (?)", []ConfigChangeRequestStatus{ConfigChangeRequestStatusPending, ConfigChangeRequestStatusFailed}).
+		Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with reviewing config change request %d", requestID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "pending config change request with id %d does not exist", requestID)
+	}
+	return nil
+}
+
+// Marks the approved config change request as failed and sets the error
+// which occurred while committing it.
+func SetConfigChangeRequestFailed(dbi dbops.DBI, requestID int64, errtext string) error {
+	request := &ConfigChangeRequest{
+		ID:     requestID,
+		Status: ConfigChangeRequestStatusFailed,
+		Error:  errtext,
+	}
+	result, err := dbi.Model(request).
+		Column("status", "error").
+		WherePK().
+		Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with updating config change request %d", requestID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "config change request with id %d does not exist", requestID)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Test adding, getting and reviewing the config change requests.
+func TestAddReviewConfigChangeRequest(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Config change requests must be associated with users.
+	var users []*SystemUser
+	for _, login := range []string{"requester", "reviewer"} {
+		user := &SystemUser{
+			Login:    login,
+			Lastname: login,
+			Name:     login,
+		}
+		_, err := CreateUser(db, user)
+		require.NoError(t, err)
+		users = append(users, user)
+	}
+
+	for i := 0; i < 2; i++ {
+		request := &ConfigChangeRequest{
+			UserID: users[0].ID,
+			Status: ConfigChangeRequestStatusPending,
+			Updates: []*ConfigUpdate{
+				NewConfigUpdate(ConfigOperationKeaHostAdd, 1, 2),
+			},
+			Diff: []ConfigChangeDaemonDiff{
+				{
+					DaemonID: 1,
+					Entries: []keaconfig.ConfigDiffEntry{
+						{
+							Operation: keaconfig.ConfigDiffOperationAdded,
+							NewValue:  map[string]any{"hw-address": "01:02:03:04:05:06"},
+						},
+					},
+				},
+			},
+		}
+		if i == 1 {
+			request.DeadlineAt = storkutil.Ptr(storkutil.UTCNow().Add(time.Hour))
+		}
+		err := AddConfigChangeRequest(db, request)
+		require.NoError(t, err)
+		require.NotZero(t, request.ID)
+	}
+
+	// Get all requests from the most recent one.
+	requests, total, err := GetConfigChangeRequestsByPage(db, 0, 10, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, requests, 2)
+	require.NotNil(t, requests[0].DeadlineAt)
+	require.Nil(t, requests[1].DeadlineAt)
+	require.NotNil(t, requests[1].User)
+	require.Equal(t, "requester", requests[1].User.Login)
+	require.True(t, requests[1].HasKeaUpdates())
+	require.Len(t, requests[1].Diff, 1)
+	require.EqualValues(t, 1, requests[1].Diff[0].DaemonID)
+	require.Len(t, requests[1].Diff[0].Entries, 1)
+	require.Equal(t, keaconfig.ConfigDiffOperationAdded, requests[1].Diff[0].Entries[0].Operation)
+
+	// Approve the first request.
+	err = SetConfigChangeRequestReviewed(db, requests[1].ID, ConfigChangeRequestStatusApproved, users[1].ID, "looks good")
+	require.NoError(t, err)
+
+	// It can't be reviewed again.
+	err = SetConfigChangeRequestReviewed(db, requests[1].ID, ConfigChangeRequestStatusRejected, users[1].ID, "")
+	require.ErrorIs(t, err, ErrNotExists)
+
+	err = SetConfigChangeRequestFailed(db, requests[1].ID, "commit failed")
+	require.NoError(t, err)
+
+	request, err := GetConfigChangeRequestByID(db, requests[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, request)
+	require.Equal(t, ConfigChangeRequestStatusFailed, request.Status)
+	require.NotNil(t, request.Reviewer)
+	require.Equal(t, "reviewer", request.Reviewer.Login)
+	require.NotNil(t, request.ReviewedAt)
+	require.Equal(t, "looks good", request.Comment)
+	require.Equal(t, "commit failed", request.Error)
+
+	// The failed request can be approved again to retry the commit.
+	err = SetConfigChangeRequestReviewed(db, requests[1].ID, ConfigChangeRequestStatusApproved, users[1].ID, "retry")
+	require.NoError(t, err)
+
+	request, err = GetConfigChangeRequestByID(db, requests[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, request)
+	require.Equal(t, ConfigChangeRequestStatusApproved, request.Status)
+	require.Equal(t, "retry", request.Comment)
+	require.Empty(t, request.Error)
+
+	// Filter by status.
+	status := ConfigChangeRequestStatusPending
+	requests, total, err = GetConfigChangeRequestsByPage(db, 0, 10, &status)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Len(t, requests, 1)
+	require.Equal(t, ConfigChangeRequestStatusPending, requests[0].Status)
+
+	// Reject the other request.
+	err = SetConfigChangeRequestReviewed(db, requests[0].ID, ConfigChangeRequestStatusRejected, users[1].ID, "")
+	require.NoError(t, err)
+
+	requests, total, err = GetConfigChangeRequestsByPage(db, 0, 10, &status)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, requests)
+}
+
+// Test that the config change request can't be set to pending or reviewed
+// when it doesn't exist.
+func TestReviewConfigChangeRequestErrors(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := SetConfigChangeRequestReviewed(db, 1, ConfigChangeRequestStatusPending, 1, "")
+	require.Error(t, err)
+	require.False(t, pkgerrors.Is(err, ErrNotExists))
+
+	err = SetConfigChangeRequestReviewed(db, 1, ConfigChangeRequestStatusApproved, 1, "")
+	require.ErrorIs(t, err, ErrNotExists)
+
+	err = SetConfigChangeRequestFailed(db, 1, "error")
+	require.ErrorIs(t, err, ErrNotExists)
+
+	request, err := GetConfigChangeRequestByID(db, 1)
+	require.NoError(t, err)
+	require.Nil(t, request)
+}
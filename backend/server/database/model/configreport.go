@@ -142,6 +142,23 @@ func GetConfigReportsByDaemonID(db *pg.DB, offset, limit int64, daemonID int64,
 	return configReports, int64(total), nil
 }
 
+// Returns the config reports of all daemons ordered by the daemon ID.
+// It is used to export the config review results of all monitored
+// daemons.
+func GetAllConfigReports(dbi dbops.DBI) ([]ConfigReport, error) {
+	var configReports []ConfigReport
+	err := dbi.Model(&configReports).
+		Order("config_report.daemon_id ASC", "config_report.id ASC").
+		Relation("RefDaemons", func(q *orm.Query) (*orm.Query, error) {
+			return q.Order("daemon_to_config_report.order_index ASC"), nil
+		}).
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrap(err, "problem selecting config reports")
+	}
+	return configReports, nil
+}
+
 // Counts the total number of config reports. Accepts the same filters as
 // GetConfigReportsByDaemonID.
 func CountConfigReportsByDaemonID(db *pg.DB, daemonID int64, issuesOnly bool) (int64, error) {
@@ -133,6 +133,67 @@ func TestGetConfigReportsExceptEmpty(t *testing.T) {
 	require.EqualValues(t, "test", reports[0].CheckerName)
 }
 
+// Test that the config reports of all daemons are returned ordered by the
+// daemon ID.
+func TestGetAllConfigReports(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon1 := NewDaemon(machine, daemonname.DHCPv4, true, []*AccessPoint{})
+	err = AddDaemon(db, daemon1)
+	require.NoError(t, err)
+
+	daemon2 := NewDaemon(machine, daemonname.DHCPv6, true, []*AccessPoint{})
+	err = AddDaemon(db, daemon2)
+	require.NoError(t, err)
+
+	// No reports.
+	reports, err := GetAllConfigReports(db)
+	require.NoError(t, err)
+	require.Empty(t, reports)
+
+	// Add the reports in the reverse order of the daemons.
+	for _, configReport := range []*ConfigReport{
+		{
+			CheckerName: "test",
+			Content:     newPtr("Here is the test report for {daemon}"),
+			DaemonID:    daemon2.ID,
+			RefDaemons:  []*Daemon{daemon2},
+		},
+		{
+			CheckerName: "empty",
+			DaemonID:    daemon1.ID,
+		},
+		{
+			CheckerName: "test",
+			Content:     newPtr("Here is the test report for {daemon}"),
+			DaemonID:    daemon1.ID,
+			RefDaemons:  []*Daemon{daemon1},
+		},
+	} {
+		err = AddConfigReport(db, configReport)
+		require.NoError(t, err)
+	}
+
+	reports, err = GetAllConfigReports(db)
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+	require.Equal(t, daemon1.ID, reports[0].DaemonID)
+	require.Equal(t, "empty", reports[0].CheckerName)
+	require.Nil(t, reports[0].Content)
+	require.Equal(t, daemon1.ID, reports[1].DaemonID)
+	require.Equal(t, "Here is the test report for <daemon id=\"1\" name=\"dhcp4\" machineId=\"1\">", *reports[1].Content)
+	require.Equal(t, daemon2.ID, reports[2].DaemonID)
+	require.Len(t, reports[2].RefDaemons, 1)
+}
+
 // Test inserting, selecting and deleting configuration reports associated
 // with distinct daemons.
 func TestConfigReportDistinctDaemons(t *testing.T) {
@@ -0,0 +1,121 @@
+package dbmodel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+)
+
+// Represents a user-defined configuration review rule. The rule is
+// registered in the config review dispatcher as a checker with the rule
+// name. The selector is the name of the dispatch group the checker is
+// registered in (e.g., kea-dhcp-daemon). The scope specifies whether the
+// expression is evaluated once for the daemon configuration or for each
+// subnet. A report with the rendered message is generated when the
+// expression evaluates to false. The triggers are the names of the config
+// review triggers; the default triggers are used when they are empty.
+type ConfigReviewRule struct {
+	ID          int64
+	CreatedAt   time.Time
+	Name        string
+	Description string
+	Selector    string
+	Scope       string
+	Triggers    []string `pg:",array"`
+	Expression  string
+	Message     string
+}
+
+// Inserts the config review rule into the database.
+func AddConfigReviewRule(dbi dbops.DBI, rule *ConfigReviewRule) error {
+	_, err := dbi.Model(rule).Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding config review rule %s", rule.Name)
+	}
+	return nil
+}
+
+// Updates the config review rule. The rule name is not updated because
+// the checker preferences refer to the rule by name.
+func UpdateConfigReviewRule(dbi dbops.DBI, rule *ConfigReviewRule) error {
+	result, err := dbi.Model(rule).
+		Column("description", "selector", "scope", "triggers", "expression", "message").
+		WherePK().
+		Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem updating config review rule %d", rule.ID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "config review rule with ID %d does not exist", rule.ID)
+	}
+	return nil
+}
+
+// Returns all config review rules ordered by name.
+func GetConfigReviewRules(dbi dbops.DBI) ([]ConfigReviewRule, error) {
+	rules := []ConfigReviewRule{}
+	err := dbi.Model(&rules).
+		OrderExpr("name ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrap(err, "problem getting config review rules")
+	}
+	return rules, nil
+}
+
+// Returns the config review rule by ID or nil if it doesn't exist.
+func GetConfigReviewRuleByID(dbi dbops.DBI, id int64) (*ConfigReviewRule, error) {
+	rule := &ConfigReviewRule{}
+	err := dbi.Model(rule).
+		Where("id = ?", id).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting config review rule %d", id)
+	}
+	return rule, nil
+}
+
+// Deletes the config review rule by ID along with the checker preferences
+// and the config reports referring to the rule.
+func deleteConfigReviewRule(dbi dbops.DBI, id int64) error {
+	rule := &ConfigReviewRule{}
+	_, err := dbi.Model(rule).
+		Where("id = ?", id).
+		Returning("name").
+		Delete()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem deleting config review rule %d", id)
+	}
+	if rule.Name == "" {
+		return nil
+	}
+	_, err = dbi.Model(&ConfigCheckerPreference{}).
+		Where("checker_name = ?", rule.Name).
+		Delete()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem deleting checker preferences of config review rule %s", rule.Name)
+	}
+	_, err = dbi.Model(&ConfigReport{}).
+		Where("checker_name = ?", rule.Name).
+		Delete()
+	return pkgerrors.Wrapf(err, "problem deleting config reports of config review rule %s", rule.Name)
+}
+
+// Deletes the config review rule by ID along with the checker preferences
+// and the config reports referring to the rule. It is not an error if the
+// rule doesn't exist. The transaction is created if needed.
+func DeleteConfigReviewRule(dbi dbops.DBI, id int64) error {
+	if db, ok := dbi.(*pg.DB); ok {
+		return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			return deleteConfigReviewRule(tx, id)
+		})
+	}
+	return deleteConfigReviewRule(dbi, id)
+}
@@ -0,0 +1,150 @@
+package dbmodel
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test adding, updating, getting and deleting the config review rules.
+func TestAddGetUpdateDeleteConfigReviewRule(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rules := []*ConfigReviewRule{
+		{
+			Name:       "subnet_dns_servers",
+			Selector:   "kea-dhcp-v4-daemon",
+			Scope:      "subnet",
+			Expression: `any(subnet["option-data"], it.code == 6)`,
+			Message:    "Subnet {{ subnet.subnet }} has no DNS servers.",
+		},
+		{
+			Name:        "authoritative",
+			Description: "The DHCPv4 servers must be authoritative.",
+			Selector:    "kea-dhcp-v4-daemon",
+			Scope:       "config",
+			Triggers:    []string{"manual", "config change"},
+			Expression:  `config.authoritative == true`,
+			Message:     "The {daemon} is not authoritative.",
+		},
+	}
+	for _, rule := range rules {
+		err := AddConfigReviewRule(db, rule)
+		require.NoError(t, err)
+		require.NotZero(t, rule.ID)
+	}
+
+	// The rule names must be unique.
+	err := AddConfigReviewRule(db, &ConfigReviewRule{
+		Name:       "authoritative",
+		Selector:   "kea-dhcp-daemon",
+		Scope:      "config",
+		Expression: "true",
+		Message:    "message",
+	})
+	require.Error(t, err)
+
+	// The rules are ordered by name.
+	returned, err := GetConfigReviewRules(db)
+	require.NoError(t, err)
+	require.Len(t, returned, 2)
+	require.Equal(t, "authoritative", returned[0].Name)
+	require.Equal(t, "The DHCPv4 servers must be authoritative.", returned[0].Description)
+	require.Equal(t, []string{"manual", "config change"}, returned[0].Triggers)
+	require.Equal(t, "subnet_dns_servers", returned[1].Name)
+	require.Empty(t, returned[1].Triggers)
+
+	rule, err := GetConfigReviewRuleByID(db, rules[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, rule)
+	require.Equal(t, "subnet", rule.Scope)
+	require.Equal(t, `any(subnet["option-data"], it.code == 6)`, rule.Expression)
+
+	// Update the rule. The name is not updated.
+	rule.Name = "other"
+	rule.Selector = "kea-dhcp-daemon"
+	rule.Expression = `subnet["valid-lifetime"] < 86400`
+	rule.Triggers = []string{"manual"}
+	err = UpdateConfigReviewRule(db, rule)
+	require.NoError(t, err)
+
+	rule, err = GetConfigReviewRuleByID(db, rules[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, rule)
+	require.Equal(t, "subnet_dns_servers", rule.Name)
+	require.Equal(t, "kea-dhcp-daemon", rule.Selector)
+	require.Equal(t, `subnet["valid-lifetime"] < 86400`, rule.Expression)
+	require.Equal(t, []string{"manual"}, rule.Triggers)
+
+	// Updating a non-existing rule fails.
+	err = UpdateConfigReviewRule(db, &ConfigReviewRule{ID: rules[1].ID + 100})
+	require.ErrorIs(t, err, ErrNotExists)
+
+	// Delete the rule.
+	err = DeleteConfigReviewRule(db, rules[0].ID)
+	require.NoError(t, err)
+
+	rule, err = GetConfigReviewRuleByID(db, rules[0].ID)
+	require.NoError(t, err)
+	require.Nil(t, rule)
+
+	// Deleting a non-existing rule is not an error.
+	err = DeleteConfigReviewRule(db, rules[0].ID)
+	require.NoError(t, err)
+
+	returned, err = GetConfigReviewRules(db)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+}
+
+// Test that the checker preferences and config reports of the deleted rule
+// are deleted.
+func TestDeleteConfigReviewRuleWithPreferencesAndReports(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, _, err := addTestDaemons(db)
+	require.NoError(t, err)
+
+	rule := &ConfigReviewRule{
+		Name:       "authoritative",
+		Selector:   "kea-dhcp-v4-daemon",
+		Scope:      "config",
+		Expression: `config.authoritative == true`,
+		Message:    "The {daemon} is not authoritative.",
+	}
+	err = AddConfigReviewRule(db, rule)
+	require.NoError(t, err)
+
+	err = CommitCheckerPreferences(db, []*ConfigCheckerPreference{
+		NewGlobalConfigCheckerPreference("authoritative"),
+		NewDaemonConfigCheckerPreference(daemon.ID, "authoritative", true),
+		NewDaemonConfigCheckerPreference(daemon.ID, "other", false),
+	}, nil)
+	require.NoError(t, err)
+
+	content := "The daemon is not authoritative."
+	for _, checkerName := range []string{"authoritative", "other"} {
+		err = AddConfigReport(db, &ConfigReport{
+			CheckerName: checkerName,
+			Content:     &content,
+			DaemonID:    daemon.ID,
+		})
+		require.NoError(t, err)
+	}
+
+	err = DeleteConfigReviewRule(db, rule.ID)
+	require.NoError(t, err)
+
+	preferences, err := GetAllCheckerPreferences(db)
+	require.NoError(t, err)
+	require.Len(t, preferences, 1)
+	require.Equal(t, "other", preferences[0].CheckerName)
+
+	reports, _, err := GetConfigReportsByDaemonID(db, 0, 0, daemon.ID, false)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, "other", reports[0].CheckerName)
+}
@@ -0,0 +1,57 @@
+package dbmodel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Represents the most recent statistics returned by the Kea DHCP-DDNS (D2)
+// server. The global statistics (e.g., ncr-received, update-error) are
+// stored under their names. The statistics pertaining to the TSIG keys are
+// stored under the names returned by Kea (e.g., key[key1].update-sent).
+type KeaD2Statistics struct {
+	tableName   struct{} `pg:"kea_d2_statistics"` //nolint:unused
+	DaemonID    int64    `pg:",pk"`
+	CollectedAt time.Time
+	Statistics  map[string]int64
+}
+
+// Instantiates the D2 statistics for a given daemon.
+func NewKeaD2Statistics(daemonID int64, statistics map[string]int64) *KeaD2Statistics {
+	return &KeaD2Statistics{
+		DaemonID:    daemonID,
+		CollectedAt: time.Now().UTC(),
+		Statistics:  statistics,
+	}
+}
+
+// Upserts the D2 statistics in the database.
+func AddKeaD2Statistics(db pg.DBI, statistics *KeaD2Statistics) error {
+	_, err := db.Model(statistics).OnConflict("(daemon_id) DO UPDATE").
+		Set("collected_at = EXCLUDED.collected_at").
+		Set("statistics = EXCLUDED.statistics").
+		Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "failed to upsert D2 statistics for daemon %d", statistics.DaemonID)
+	}
+	return nil
+}
+
+// Returns the D2 statistics for a daemon or nil if they don't exist.
+func GetKeaD2Statistics(db pg.DBI, daemonID int64) (*KeaD2Statistics, error) {
+	statistics := &KeaD2Statistics{}
+	err := db.Model(statistics).
+		Where("daemon_id = ?", daemonID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		err = pkgerrors.Wrapf(err, "failed to get D2 statistics for daemon %d", daemonID)
+		return nil, err
+	}
+	return statistics, nil
+}
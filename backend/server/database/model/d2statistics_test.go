@@ -0,0 +1,63 @@
+package dbmodel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/datamodel/daemonname"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the D2 statistics are inserted, updated and fetched.
+func TestAddGetKeaD2Statistics(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &Machine{
+		ID:        0,
+		Address:   "localhost",
+		AgentPort: int64(8080),
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := NewDaemon(machine, daemonname.D2, true, []*AccessPoint{
+		{
+			Type:    AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+		},
+	})
+	err = AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	// No statistics yet.
+	statistics, err := GetKeaD2Statistics(db, daemon.ID)
+	require.NoError(t, err)
+	require.Nil(t, statistics)
+
+	err = AddKeaD2Statistics(db, NewKeaD2Statistics(daemon.ID, map[string]int64{
+		"ncr-received":          10,
+		"key[key1].update-sent": 5,
+	}))
+	require.NoError(t, err)
+
+	statistics, err = GetKeaD2Statistics(db, daemon.ID)
+	require.NoError(t, err)
+	require.NotNil(t, statistics)
+	require.EqualValues(t, 10, statistics.Statistics["ncr-received"])
+	require.EqualValues(t, 5, statistics.Statistics["key[key1].update-sent"])
+	require.False(t, statistics.CollectedAt.IsZero())
+
+	// Update the statistics.
+	err = AddKeaD2Statistics(db, NewKeaD2Statistics(daemon.ID, map[string]int64{
+		"ncr-received": 12,
+	}))
+	require.NoError(t, err)
+
+	statistics, err = GetKeaD2Statistics(db, daemon.ID)
+	require.NoError(t, err)
+	require.NotNil(t, statistics)
+	require.Len(t, statistics.Statistics, 1)
+	require.EqualValues(t, 12, statistics.Statistics["ncr-received"])
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
 	errors "github.com/pkg/errors"
+	bind9config "isc.org/stork/daemoncfg/bind9"
 	keaconfig "isc.org/stork/daemoncfg/kea"
+	pdnsconfig "isc.org/stork/daemoncfg/pdns"
 	"isc.org/stork/daemondata/bind9stats"
 	"isc.org/stork/datamodel/daemonname"
 	dbops "isc.org/stork/server/database"
@@ -60,6 +62,10 @@ type KeaDaemon struct {
 	ConfigHash string
 	DaemonID   int64
 	ServerTag  *string
+	// Differences between the running configuration and the configuration
+	// file found by the Stork agent. It is nil if the agent has not
+	// compared the configurations.
+	ConfigDrift *keaconfig.ConfigDrift
 
 	KeaDHCPDaemon *KeaDHCPDaemon `pg:"rel:belongs-to"`
 }
@@ -79,6 +85,13 @@ type Bind9Daemon struct {
 	ID       int64
 	DaemonID int64
 	Stats    Bind9DaemonStats
+	// Parsed configuration fetched from the agent. It is not stored in
+	// the database but it is used to review the configuration.
+	Config *bind9config.Config `pg:"-"`
+	// Hash of the configuration fetched from the agent. It is not stored
+	// in the database but it is compared with the hash stored with the
+	// config review to determine if the configuration has changed.
+	ConfigHash string `pg:"-"`
 }
 
 // A structure holding PowerDNS daemon specific information.
@@ -95,6 +108,14 @@ type PDNSDaemon struct {
 	ID        int64
 	DaemonID  int64
 	Details   PDNSDaemonDetails
+	// Parsed configuration fetched from the agent. The secrets are
+	// redacted. It is not stored in the database but it is used to
+	// review the configuration.
+	Config *pdnsconfig.Config `pg:"-"`
+	// Hash of the configuration fetched from the agent. It is not stored
+	// in the database but it is compared with the hash stored with the
+	// config review to determine if the configuration has changed.
+	ConfigHash string `pg:"-"`
 }
 
 // A structure reflecting all SQL tables holding information about the
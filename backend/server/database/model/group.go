@@ -14,6 +14,7 @@ const (
 	SuperAdminGroupID int64 = 1
 	AdminGroupID      int64 = 2
 	ReadOnlyGroupID   int64 = 3
+	ApproverGroupID   int64 = 4
 )
 
 // Represents a group of users having some specific permissions.
@@ -14,9 +14,9 @@ func TestGetGroups(t *testing.T) {
 
 	groups, total, err := GetGroupsByPage(db, 0, 10, nil, "", SortDirAny)
 	require.NoError(t, err)
-	require.EqualValues(t, 3, total)
-	// There are two predefined groups.
-	require.Len(t, groups, 3)
+	require.EqualValues(t, 4, total)
+	// There are four predefined groups.
+	require.Len(t, groups, 4)
 
 	// Groups are supposed to be ordered by id.
 	require.Equal(t, SuperAdminGroupID, groups[0].ID)
@@ -25,24 +25,28 @@ func TestGetGroups(t *testing.T) {
 	require.Equal(t, "admin", groups[1].Name)
 	require.Equal(t, ReadOnlyGroupID, groups[2].ID)
 	require.Equal(t, "read-only", groups[2].Name)
+	require.Equal(t, ApproverGroupID, groups[3].ID)
+	require.Equal(t, "approver", groups[3].Name)
 
 	// check sorting field and order ascending
 	groups, total, err = GetGroupsByPage(db, 0, 10, nil, "name", SortDirAsc)
 	require.NoError(t, err)
-	require.EqualValues(t, 3, total)
-	require.Len(t, groups, 3)
+	require.EqualValues(t, 4, total)
+	require.Len(t, groups, 4)
 	require.Equal(t, "admin", groups[0].Name)
-	require.Equal(t, "read-only", groups[1].Name)
-	require.Equal(t, "super-admin", groups[2].Name)
+	require.Equal(t, "approver", groups[1].Name)
+	require.Equal(t, "read-only", groups[2].Name)
+	require.Equal(t, "super-admin", groups[3].Name)
 
 	// check sorting field and order descending
 	groups, total, err = GetGroupsByPage(db, 0, 10, nil, "name", SortDirDesc)
 	require.NoError(t, err)
-	require.EqualValues(t, 3, total)
-	require.Len(t, groups, 3)
+	require.EqualValues(t, 4, total)
+	require.Len(t, groups, 4)
 	require.Equal(t, "super-admin", groups[0].Name)
 	require.Equal(t, "read-only", groups[1].Name)
-	require.Equal(t, "admin", groups[2].Name)
+	require.Equal(t, "approver", groups[2].Name)
+	require.Equal(t, "admin", groups[3].Name)
 
 	// check filtering by text
 	text := "super"
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"net"
 	"strings"
 	"time"
 
@@ -942,6 +943,80 @@ func (host Host) GetLocalHost(daemonID int64) *LocalHost {
 	return nil
 }
 
+// Checks if the host reservation can be sent to the Kea servers. The host
+// must have exactly one identifier of the type supported by Kea and must be
+// associated with at least one daemon. The reserved addresses and prefixes
+// must be valid and belong to the same family. If the host belongs to a
+// subnet, the reserved addresses must be in this subnet and the subnet must
+// be configured in all daemons associated with the host. The Subnet must be
+// populated (see PopulateSubnet) for the subnet specific checks.
+func (host Host) Validate() error {
+	if len(host.HostIdentifiers) != 1 {
+		return pkgerrors.Errorf("host must have exactly one identifier but it has %d", len(host.HostIdentifiers))
+	}
+	identifier := host.HostIdentifiers[0]
+	switch identifier.Type {
+	case "hw-address", "duid", "circuit-id", "client-id", "flex-id":
+	default:
+		return pkgerrors.Errorf("unsupported host identifier type %s", identifier.Type)
+	}
+	if len(identifier.Value) == 0 {
+		return pkgerrors.Errorf("empty %s host identifier", identifier.Type)
+	}
+	if len(host.LocalHosts) == 0 {
+		return pkgerrors.New("host is not associated with any daemon")
+	}
+
+	var subnetNet *net.IPNet
+	if host.Subnet != nil {
+		var err error
+		if _, subnetNet, err = net.ParseCIDR(host.Subnet.Prefix); err != nil {
+			return pkgerrors.Wrapf(err, "invalid prefix %s of the host subnet", host.Subnet.Prefix)
+		}
+		for _, lh := range host.LocalHosts {
+			found := false
+			for _, ls := range host.Subnet.LocalSubnets {
+				if ls.DaemonID == lh.DaemonID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return pkgerrors.Errorf("subnet %s is not configured in daemon %d", host.Subnet.Prefix, lh.DaemonID)
+			}
+		}
+	}
+
+	var (
+		protocol  storkutil.IPType
+		addresses int
+	)
+	for _, reservation := range host.GetIPReservations() {
+		parsed := storkutil.ParseIP(reservation)
+		if parsed == nil {
+			return pkgerrors.Errorf("invalid IP reservation %s", reservation)
+		}
+		if protocol != 0 && protocol != parsed.Protocol {
+			return pkgerrors.New("host must not have both IPv4 and IPv6 reservations")
+		}
+		protocol = parsed.Protocol
+		if parsed.Prefix {
+			if parsed.Protocol == storkutil.IPv4 {
+				return pkgerrors.Errorf("invalid IPv4 prefix reservation %s", reservation)
+			}
+			continue
+		}
+		addresses++
+		if parsed.Protocol == storkutil.IPv4 && addresses > 1 {
+			return pkgerrors.New("host must not have more than one IPv4 address reservation")
+		}
+		if subnetNet != nil && !subnetNet.Contains(parsed.IP) {
+			return pkgerrors.Errorf("reserved address %s does not belong to subnet %s", reservation, host.Subnet.Prefix)
+		}
+	}
+	return nil
+}
+
 // Converts host identifier value to a string of hexadecimal digits.
 func (id HostIdentifier) ToHex(separator string) string {
 	// Convert binary value to hexadecimal value.
@@ -2370,6 +2370,116 @@ func TestHostIdentifierToHex(t *testing.T) {
 	require.Equal(t, "01020304050a0b", id.ToHex(""))
 }
 
+// Test validating the host reservations before sending them to Kea.
+func TestHostValidate(t *testing.T) {
+	newHost := func() Host {
+		return Host{
+			Subnet: &Subnet{
+				Prefix: "192.0.2.0/24",
+				LocalSubnets: []*LocalSubnet{
+					{
+						DaemonID: 1,
+					},
+				},
+			},
+			HostIdentifiers: []HostIdentifier{
+				{
+					Type:  "hw-address",
+					Value: []byte{1, 2, 3, 4, 5, 6},
+				},
+			},
+			LocalHosts: []LocalHost{
+				{
+					DaemonID: 1,
+					IPReservations: []IPReservation{
+						{
+							Address: "192.0.2.10",
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("valid host", func(t *testing.T) {
+		host := newHost()
+		require.NoError(t, host.Validate())
+	})
+
+	t.Run("valid global host", func(t *testing.T) {
+		host := newHost()
+		host.Subnet = nil
+		host.LocalHosts[0].IPReservations[0].Address = "10.0.0.1"
+		require.NoError(t, host.Validate())
+	})
+
+	t.Run("no identifiers", func(t *testing.T) {
+		host := newHost()
+		host.HostIdentifiers = nil
+		require.ErrorContains(t, host.Validate(), "exactly one identifier")
+	})
+
+	t.Run("unsupported identifier", func(t *testing.T) {
+		host := newHost()
+		host.HostIdentifiers[0].Type = "foo"
+		require.ErrorContains(t, host.Validate(), "unsupported host identifier type foo")
+	})
+
+	t.Run("empty identifier", func(t *testing.T) {
+		host := newHost()
+		host.HostIdentifiers[0].Value = []byte{}
+		require.ErrorContains(t, host.Validate(), "empty hw-address host identifier")
+	})
+
+	t.Run("no daemons", func(t *testing.T) {
+		host := newHost()
+		host.LocalHosts = nil
+		require.ErrorContains(t, host.Validate(), "not associated with any daemon")
+	})
+
+	t.Run("subnet not in daemon", func(t *testing.T) {
+		host := newHost()
+		host.LocalHosts[0].DaemonID = 2
+		require.ErrorContains(t, host.Validate(), "subnet 192.0.2.0/24 is not configured in daemon 2")
+	})
+
+	t.Run("address out of subnet", func(t *testing.T) {
+		host := newHost()
+		host.LocalHosts[0].IPReservations[0].Address = "192.0.3.10"
+		require.ErrorContains(t, host.Validate(), "does not belong to subnet")
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		host := newHost()
+		host.LocalHosts[0].IPReservations[0].Address = "192.0.2"
+		require.ErrorContains(t, host.Validate(), "invalid IP reservation")
+	})
+
+	t.Run("mixed families", func(t *testing.T) {
+		host := newHost()
+		host.Subnet = nil
+		host.LocalHosts[0].IPReservations = append(host.LocalHosts[0].IPReservations, IPReservation{
+			Address: "2001:db8:1::1",
+		})
+		require.ErrorContains(t, host.Validate(), "both IPv4 and IPv6")
+	})
+
+	t.Run("multiple IPv4 addresses", func(t *testing.T) {
+		host := newHost()
+		host.LocalHosts[0].IPReservations = append(host.LocalHosts[0].IPReservations, IPReservation{
+			Address: "192.0.2.11",
+		})
+		require.ErrorContains(t, host.Validate(), "more than one IPv4 address")
+	})
+
+	t.Run("IPv4 prefix", func(t *testing.T) {
+		host := newHost()
+		host.Subnet = nil
+		host.LocalHosts[0].IPReservations[0].Address = "10.0.0.0/24"
+		require.ErrorContains(t, host.Validate(), "invalid IPv4 prefix")
+	})
+}
+
 // Tests that global host reservations and their associations with the daemons
 // are properly stored in the database.
 func TestCommitGlobalHostsIntoDB(t *testing.T) {
@@ -0,0 +1,162 @@
+package dbmodel
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+)
+
+// Indicates what has caused the Kea configuration change.
+type KeaConfigVersionSource string
+
+const (
+	// The configuration has been changed by the Stork transaction.
+	KeaConfigVersionSourceTransaction KeaConfigVersionSource = "transaction"
+	// The configuration has been changed outside of Stork, e.g., by
+	// editing the configuration file or sending the commands directly
+	// to the daemon.
+	KeaConfigVersionSourceExternal KeaConfigVersionSource = "external"
+	// The configuration has been changed as a result of the daemon's
+	// reload or restart.
+	KeaConfigVersionSourceReload KeaConfigVersionSource = "reload"
+)
+
+// Represents a versioned snapshot of the Kea daemon's configuration.
+// A new version is stored whenever Stork fetches a configuration
+// different from the most recent version. The version numbers are
+// assigned sequentially for each daemon.
+type KeaConfigVersion struct {
+	ID         int64
+	DaemonID   int64
+	Version    int64
+	CreatedAt  time.Time
+	Config     *KeaConfig
+	ConfigHash string
+	Source     KeaConfigVersionSource
+	// Config operation which caused the change. It is only set when the
+	// source is the Stork transaction.
+	Operation ConfigOperation
+	// User who applied the change. It is only set when the source is
+	// the Stork transaction.
+	UserID *int64
+	User   *SystemUser `pg:"rel:has-one"`
+}
+
+// Represents a configuration change sent by Stork to the Kea daemon
+// but not yet fetched from this daemon. It is used to attribute the
+// next configuration version to the Stork transaction and the user.
+type KeaConfigPendingChange struct {
+	ID        int64
+	DaemonID  int64
+	CreatedAt time.Time
+	Operation ConfigOperation
+	UserID    *int64
+}
+
+// Inserts a new configuration version for a daemon. The version
+// number is one higher than the number of the most recent version
+// of the daemon's configuration.
+func AddKeaConfigVersion(dbi dbops.DBI, version *KeaConfigVersion) error {
+	_, err := dbi.Model(version).
+		Value("version", "(SELECT COALESCE(MAX(version), 0) + 1 FROM kea_config_version WHERE daemon_id = ?)", version.DaemonID).
+		Returning("id, version, created_at").
+		Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding configuration version for daemon %d", version.DaemonID)
+	}
+	return nil
+}
+
+// Returns the configuration version by daemon ID and version number. It
+// returns nil if the version doesn't exist.
+func GetKeaConfigVersion(dbi dbops.DBI, daemonID, versionNumber int64) (*KeaConfigVersion, error) {
+	version := &KeaConfigVersion{}
+	err := dbi.Model(version).
+		Relation("User").
+		Where("kea_config_version.daemon_id = ?", daemonID).
+		Where("kea_config_version.version = ?", versionNumber).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting configuration version %d for daemon %d", versionNumber, daemonID)
+	}
+	return version, nil
+}
+
+// Returns the most recent configuration version for a daemon. It returns
+// nil if no version exists for the daemon.
+func GetLatestKeaConfigVersion(dbi dbops.DBI, daemonID int64) (*KeaConfigVersion, error) {
+	version := &KeaConfigVersion{}
+	err := dbi.Model(version).
+		Relation("User").
+		Where("kea_config_version.daemon_id = ?", daemonID).
+		OrderExpr("kea_config_version.version DESC").
+		Limit(1).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting latest configuration version for daemon %d", daemonID)
+	}
+	return version, nil
+}
+
+// Returns the configuration versions of a daemon from the most recent one.
+// The configurations are not fetched to limit the amount of returned data.
+// The offset and limit specify the page of the versions to return. It
+// also returns the total number of the versions of the daemon.
+func GetKeaConfigVersionsByPage(dbi dbops.DBI, daemonID, offset, limit int64) ([]KeaConfigVersion, int64, error) {
+	versions := []KeaConfigVersion{}
+	q := dbi.Model(&versions).
+		ExcludeColumn("config").
+		Relation("User").
+		Where("kea_config_version.daemon_id = ?", daemonID).
+		OrderExpr("kea_config_version.version DESC").
+		Offset(int(offset))
+	if limit > 0 {
+		q = q.Limit(int(limit))
+	}
+	total, err := q.SelectAndCount()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return versions, 0, nil
+		}
+		return nil, 0, pkgerrors.Wrapf(err, "problem getting configuration versions for daemon %d", daemonID)
+	}
+	return versions, int64(total), nil
+}
+
+// Inserts the configuration change applied by Stork and not yet fetched
+// from the daemon.
+func AddKeaConfigPendingChange(dbi dbops.DBI, change *KeaConfigPendingChange) error {
+	_, err := dbi.Model(change).Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding pending configuration change for daemon %d", change.DaemonID)
+	}
+	return nil
+}
+
+// Deletes and returns all pending configuration changes of a daemon. The
+// returned changes are ordered from the oldest to the most recent one.
+func DeleteKeaConfigPendingChanges(dbi dbops.DBI, daemonID int64) ([]KeaConfigPendingChange, error) {
+	changes := []KeaConfigPendingChange{}
+	_, err := dbi.Model(&changes).
+		Where("daemon_id = ?", daemonID).
+		Returning("*").
+		Delete()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem deleting pending configuration changes for daemon %d", daemonID)
+	}
+	// The DELETE statement doesn't support ordering.
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+	return changes, nil
+}
@@ -0,0 +1,153 @@
+package dbmodel
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Adds a Kea DHCPv4 daemon to the database for the configuration version
+// tests.
+func addTestKeaConfigVersionDaemon(t *testing.T, db *pg.DB) *Daemon {
+	machine := &Machine{
+		Address:   "localhost",
+		AgentPort: int64(8080),
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := NewDaemon(machine, daemonname.DHCPv4, true, []*AccessPoint{
+		{
+			Type:    AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+		},
+	})
+	err = AddDaemon(db, daemon)
+	require.NoError(t, err)
+	return daemon
+}
+
+// Test that the configuration versions are numbered sequentially and
+// can be fetched.
+func TestAddGetKeaConfigVersion(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestKeaConfigVersionDaemon(t, db)
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+
+	// No versions yet.
+	latest, err := GetLatestKeaConfigVersion(db, daemon.ID)
+	require.NoError(t, err)
+	require.Nil(t, latest)
+
+	for i, lifetime := range []string{"1000", "2000"} {
+		config, err := keaconfig.NewConfig([]byte(`{ "Dhcp4": { "valid-lifetime": ` + lifetime + ` } }`))
+		require.NoError(t, err)
+		version := &KeaConfigVersion{
+			DaemonID:   daemon.ID,
+			Config:     newKeaConfig(config),
+			ConfigHash: lifetime,
+			Source:     KeaConfigVersionSourceExternal,
+		}
+		if i == 1 {
+			version.Source = KeaConfigVersionSourceTransaction
+			version.Operation = ConfigOperationKeaGlobalParametersUpdate
+			version.UserID = &user.ID
+		}
+		err = AddKeaConfigVersion(db, version)
+		require.NoError(t, err)
+		require.NotZero(t, version.ID)
+		require.EqualValues(t, i+1, version.Version)
+	}
+
+	latest, err = GetLatestKeaConfigVersion(db, daemon.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	require.EqualValues(t, 2, latest.Version)
+	require.Equal(t, "2000", latest.ConfigHash)
+	require.Equal(t, KeaConfigVersionSourceTransaction, latest.Source)
+	require.Equal(t, ConfigOperationKeaGlobalParametersUpdate, latest.Operation)
+	require.NotNil(t, latest.User)
+	require.Equal(t, "test", latest.User.Login)
+	require.NotNil(t, latest.Config)
+	require.True(t, latest.Config.IsDHCPv4())
+
+	version, err := GetKeaConfigVersion(db, daemon.ID, 1)
+	require.NoError(t, err)
+	require.NotNil(t, version)
+	require.Equal(t, KeaConfigVersionSourceExternal, version.Source)
+	require.Empty(t, version.Operation)
+	require.Nil(t, version.UserID)
+
+	// Non-existing version.
+	version, err = GetKeaConfigVersion(db, daemon.ID, 3)
+	require.NoError(t, err)
+	require.Nil(t, version)
+
+	// Get the versions by page.
+	versions, total, err := GetKeaConfigVersionsByPage(db, daemon.ID, 0, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, versions, 1)
+	require.EqualValues(t, 2, versions[0].Version)
+	require.Nil(t, versions[0].Config)
+
+	versions, total, err = GetKeaConfigVersionsByPage(db, daemon.ID, 1, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, versions, 1)
+	require.EqualValues(t, 1, versions[0].Version)
+
+	// Deleting the daemon deletes its configuration versions.
+	err = DeleteDaemon(db, daemon)
+	require.NoError(t, err)
+	versions, total, err = GetKeaConfigVersionsByPage(db, daemon.ID, 0, 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, versions)
+}
+
+// Test that the pending configuration changes are added and deleted.
+func TestAddDeleteKeaConfigPendingChanges(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestKeaConfigVersionDaemon(t, db)
+
+	changes, err := DeleteKeaConfigPendingChanges(db, daemon.ID)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	for _, operation := range []ConfigOperation{ConfigOperationKeaSubnetAdd, ConfigOperationKeaSubnetUpdate} {
+		err = AddKeaConfigPendingChange(db, &KeaConfigPendingChange{
+			DaemonID:  daemon.ID,
+			Operation: operation,
+		})
+		require.NoError(t, err)
+	}
+
+	changes, err = DeleteKeaConfigPendingChanges(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, ConfigOperationKeaSubnetAdd, changes[0].Operation)
+	require.Equal(t, ConfigOperationKeaSubnetUpdate, changes[1].Operation)
+	require.False(t, changes[1].CreatedAt.IsZero())
+
+	// The changes have been deleted.
+	changes, err = DeleteKeaConfigPendingChanges(db, daemon.ID)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
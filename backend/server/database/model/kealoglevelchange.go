@@ -0,0 +1,135 @@
+package dbmodel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+)
+
+// Represents a temporary change of the Kea logger's severity applied by
+// Stork. The change is reverted when it expires. The original severity
+// and debug level are restored. The original severity is nil if the
+// logger was not configured before the change; the logger is removed
+// from the configuration in this case.
+type KeaLogLevelChange struct {
+	ID        int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	DaemonID int64
+	Daemon   *Daemon `pg:"rel:has-one"`
+
+	UserID *int64
+	User   *SystemUser `pg:"rel:has-one"`
+
+	LoggerName         string
+	Severity           string
+	DebugLevel         int `pg:",use_zero"`
+	OriginalSeverity   *string
+	OriginalDebugLevel int `pg:",use_zero"`
+}
+
+// Inserts the log level change into the database.
+func AddKeaLogLevelChange(dbi dbops.DBI, change *KeaLogLevelChange) error {
+	_, err := dbi.Model(change).Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding log level change of logger %s in daemon %d", change.LoggerName, change.DaemonID)
+	}
+	return nil
+}
+
+// Updates the severity, debug level, expiration time and the user of the
+// log level change. The original severity and debug level are not
+// updated because they must be restored when the change expires.
+func UpdateKeaLogLevelChange(dbi dbops.DBI, change *KeaLogLevelChange) error {
+	result, err := dbi.Model(change).
+		Column("severity", "debug_level", "expires_at", "user_id").
+		WherePK().
+		Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem updating log level change %d", change.ID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "log level change with ID %d does not exist", change.ID)
+	}
+	return nil
+}
+
+// Selects the log level changes along with the daemons, their machines
+// and access points, and the users who made the changes. It orders the
+// changes by the expiration time.
+func selectKeaLogLevelChanges(dbi dbops.DBI, where func(q *pg.Query) *pg.Query) ([]KeaLogLevelChange, error) {
+	changes := []KeaLogLevelChange{}
+	q := dbi.Model(&changes).
+		Relation("Daemon").
+		Relation("Daemon.Machine").
+		Relation("Daemon.AccessPoints").
+		Relation("User").
+		OrderExpr("kea_log_level_change.expires_at ASC").
+		OrderExpr("kea_log_level_change.id ASC")
+	err := where(q).Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrap(err, "problem getting log level changes")
+	}
+	return changes, nil
+}
+
+// Returns all log level changes. If the daemon ID is specified, only the
+// changes of this daemon are returned.
+func GetKeaLogLevelChanges(dbi dbops.DBI, daemonID *int64) ([]KeaLogLevelChange, error) {
+	return selectKeaLogLevelChanges(dbi, func(q *pg.Query) *pg.Query {
+		if daemonID != nil {
+			q = q.Where("kea_log_level_change.daemon_id = ?", *daemonID)
+		}
+		return q
+	})
+}
+
+// Returns the log level changes which expired at or before the specified
+// time or were created before the specified time. The latter are the
+// changes made before the server started which must be reverted because
+// the server could not track them while it was not running.
+func GetExpiredKeaLogLevelChanges(dbi dbops.DBI, now, createdBefore time.Time) ([]KeaLogLevelChange, error) {
+	return selectKeaLogLevelChanges(dbi, func(q *pg.Query) *pg.Query {
+		return q.WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.WhereOr("kea_log_level_change.expires_at <= ?", now).
+				WhereOr("kea_log_level_change.created_at < ?", createdBefore), nil
+		})
+	})
+}
+
+// Returns the log level change by ID or nil if it doesn't exist.
+func GetKeaLogLevelChangeByID(dbi dbops.DBI, id int64) (*KeaLogLevelChange, error) {
+	changes, err := selectKeaLogLevelChanges(dbi, func(q *pg.Query) *pg.Query {
+		return q.Where("kea_log_level_change.id = ?", id)
+	})
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+// Returns the log level change of the specified logger in the specified
+// daemon or nil if it doesn't exist.
+func GetKeaLogLevelChangeByLogger(dbi dbops.DBI, daemonID int64, loggerName string) (*KeaLogLevelChange, error) {
+	changes, err := selectKeaLogLevelChanges(dbi, func(q *pg.Query) *pg.Query {
+		return q.Where("kea_log_level_change.daemon_id = ?", daemonID).
+			Where("kea_log_level_change.logger_name = ?", loggerName)
+	})
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+// Deletes the log level change by ID. It is not an error if the change
+// doesn't exist.
+func DeleteKeaLogLevelChange(dbi dbops.DBI, id int64) error {
+	_, err := dbi.Model(&KeaLogLevelChange{}).
+		Where("id = ?", id).
+		Delete()
+	return pkgerrors.Wrapf(err, "problem deleting log level change %d", id)
+}
@@ -0,0 +1,138 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	require "github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Test adding, updating, getting and deleting the log level changes.
+func TestAddGetDeleteKeaLogLevelChange(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon1, daemon2, err := addTestDaemons(db)
+	require.NoError(t, err)
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err = CreateUser(db, user)
+	require.NoError(t, err)
+
+	now := storkutil.UTCNow().Truncate(time.Second)
+	severity := "INFO"
+	changes := []*KeaLogLevelChange{
+		{
+			CreatedAt:        now,
+			ExpiresAt:        now.Add(time.Hour),
+			DaemonID:         daemon1.ID,
+			UserID:           &user.ID,
+			LoggerName:       "kea-dhcp4",
+			Severity:         "DEBUG",
+			DebugLevel:       99,
+			OriginalSeverity: &severity,
+		},
+		{
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(-time.Minute),
+			DaemonID:   daemon2.ID,
+			LoggerName: "kea-dhcp4.packets",
+			Severity:   "DEBUG",
+		},
+	}
+	for _, change := range changes {
+		err = AddKeaLogLevelChange(db, change)
+		require.NoError(t, err)
+		require.NotZero(t, change.ID)
+	}
+
+	// The same logger cannot be changed twice.
+	err = AddKeaLogLevelChange(db, &KeaLogLevelChange{
+		ExpiresAt:  now,
+		DaemonID:   daemon1.ID,
+		LoggerName: "kea-dhcp4",
+		Severity:   "WARN",
+	})
+	require.Error(t, err)
+
+	// Get all changes ordered by the expiration time.
+	all, err := GetKeaLogLevelChanges(db, nil)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	require.Equal(t, changes[1].ID, all[0].ID)
+	require.Nil(t, all[0].OriginalSeverity)
+	require.Nil(t, all[0].User)
+	require.Equal(t, changes[0].ID, all[1].ID)
+	require.NotNil(t, all[1].Daemon)
+	require.NotNil(t, all[1].Daemon.Machine)
+	require.NotNil(t, all[1].User)
+	require.Equal(t, "INFO", *all[1].OriginalSeverity)
+	require.Equal(t, 99, all[1].DebugLevel)
+
+	// Get the changes of a daemon.
+	byDaemon, err := GetKeaLogLevelChanges(db, &daemon1.ID)
+	require.NoError(t, err)
+	require.Len(t, byDaemon, 1)
+	require.Equal(t, changes[0].ID, byDaemon[0].ID)
+
+	// Get the expired changes.
+	expired, err := GetExpiredKeaLogLevelChanges(db, now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, changes[1].ID, expired[0].ID)
+
+	// Get the changes created before the specified time.
+	expired, err = GetExpiredKeaLogLevelChanges(db, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+
+	// Get the change by the logger.
+	change, err := GetKeaLogLevelChangeByLogger(db, daemon2.ID, "kea-dhcp4.packets")
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	require.Equal(t, changes[1].ID, change.ID)
+
+	change, err = GetKeaLogLevelChangeByLogger(db, daemon2.ID, "kea-dhcp4")
+	require.NoError(t, err)
+	require.Nil(t, change)
+
+	// Update the change. The original severity is not modified.
+	changes[0].Severity = "WARN"
+	changes[0].DebugLevel = 0
+	changes[0].OriginalSeverity = nil
+	changes[0].ExpiresAt = now.Add(2 * time.Hour)
+	err = UpdateKeaLogLevelChange(db, changes[0])
+	require.NoError(t, err)
+
+	change, err = GetKeaLogLevelChangeByID(db, changes[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	require.Equal(t, "WARN", change.Severity)
+	require.Zero(t, change.DebugLevel)
+	require.Equal(t, now.Add(2*time.Hour), change.ExpiresAt)
+	require.Equal(t, "INFO", *change.OriginalSeverity)
+
+	// Delete the change.
+	err = DeleteKeaLogLevelChange(db, changes[0].ID)
+	require.NoError(t, err)
+
+	change, err = GetKeaLogLevelChangeByID(db, changes[0].ID)
+	require.NoError(t, err)
+	require.Nil(t, change)
+
+	err = UpdateKeaLogLevelChange(db, changes[0])
+	require.ErrorIs(t, err, ErrNotExists)
+
+	// Deleting the daemon deletes its changes.
+	err = DeleteDaemon(db, daemon2)
+	require.NoError(t, err)
+	all, err = GetKeaLogLevelChanges(db, nil)
+	require.NoError(t, err)
+	require.Empty(t, all)
+}
@@ -90,6 +90,41 @@ func GetLeaseByID(dbi dbops.DBI, leaseID int64) (*Lease, error) {
 	return lease, err
 }
 
+// Deletes a lease with the specified IP address (or delegated prefix)
+// belonging to the specified daemon. It returns ErrNotExists when the
+// lease does not exist in the database.
+func DeleteLease(dbi dbops.DBI, daemonID int64, ipAddress string) error {
+	result, err := dbi.Model((*Lease)(nil)).
+		Where("daemon_id = ?", daemonID).
+		Where("ip_address = ?", ipAddress).
+		Delete()
+	if err != nil {
+		err = pkgerrors.Wrapf(err, "problem deleting the lease %s of the daemon %d", ipAddress, daemonID)
+	} else if result.RowsAffected() <= 0 {
+		err = pkgerrors.Wrapf(ErrNotExists, "lease %s of the daemon %d does not exist", ipAddress, daemonID)
+	}
+	return err
+}
+
+// Deletes all leases belonging to the specified daemon and local subnet ID.
+// If the local subnet ID is 0, all leases of the daemon are deleted. It
+// returns the number of deleted leases.
+func DeleteLeasesByLocalSubnetID(dbi dbops.DBI, daemonID, localSubnetID int64) (int64, error) {
+	q := dbi.Model((*Lease)(nil)).
+		Where("daemon_id = ?", daemonID)
+
+	if localSubnetID != 0 {
+		q = q.Where("local_subnet_id = ?", localSubnetID)
+	}
+
+	result, err := q.Delete()
+	if err != nil && !pkgerrors.Is(err, pg.ErrNoRows) {
+		err = pkgerrors.Wrapf(err, "problem deleting the leases of the daemon %d", daemonID)
+		return 0, err
+	}
+	return int64(result.RowsAffected()), nil
+}
+
 // Container for values filtering leases fetched by page.
 //
 // FilterText searches by IP, DUID, Client ID, hardware address, hostname, etc.
@@ -427,6 +427,66 @@ func TestGetLeaseByIDDatabaseError(t *testing.T) {
 }
 
 // Verify that [GetLeasesByPage] operates correctly and returns no errors when
+// Test that a lease can be deleted by daemon ID and IP address.
+func TestDeleteLease(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	daemons, subnets := addTestLeaseDaemons(t, db)
+	leases := testHelperAddMockLeases(t, db, daemons, subnets)
+
+	err := DeleteLease(db, daemons[0].ID, "192.0.2.9")
+	require.NoError(t, err)
+
+	returned, err := GetLeaseByID(db, leases[0].ID)
+	require.NoError(t, err)
+	require.Nil(t, returned)
+
+	// Other leases should be intact.
+	returned, err = GetLeaseByID(db, leases[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+
+	// Deleting the same lease again should return an error.
+	err = DeleteLease(db, daemons[0].ID, "192.0.2.9")
+	require.ErrorIs(t, err, ErrNotExists)
+
+	// The lease exists but belongs to a different daemon.
+	err = DeleteLease(db, daemons[0].ID, "2001:db8:1::4")
+	require.ErrorIs(t, err, ErrNotExists)
+}
+
+// Test that the leases can be deleted for a daemon and a local subnet ID
+// or for all subnets of the daemon.
+func TestDeleteLeasesByLocalSubnetID(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	daemons, subnets := addTestLeaseDaemons(t, db)
+	leases := testHelperAddMockLeases(t, db, daemons, subnets)
+
+	// There are no leases in this subnet.
+	count, err := DeleteLeasesByLocalSubnetID(db, daemons[1].ID, 7)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	count, err = DeleteLeasesByLocalSubnetID(db, daemons[1].ID, 6)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, count)
+
+	// The DHCPv4 leases should be intact.
+	returned, err := GetLeaseByID(db, leases[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+
+	// Delete all leases of the DHCPv4 daemon.
+	count, err = DeleteLeasesByLocalSubnetID(db, daemons[0].ID, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+
+	_, total, err := GetLeasesByPage(db, 0, 10, LeasesByPageFilters{}, "", SortDirAny)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
 // no filters are specified.
 func TestGetLeasesByPageNoFilter(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -0,0 +1,205 @@
+package dbmodel
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	pkgerrors "github.com/pkg/errors"
+
+	agentapi "isc.org/stork/api"
+	keadata "isc.org/stork/daemondata/kea"
+	dbops "isc.org/stork/server/database"
+	storkutil "isc.org/stork/util"
+)
+
+// Represents a transition in the lease lifecycle observed by the agent,
+// e.g., assigning a lease to a client or its expiration. The embedded
+// lease holds the lease state after the transition.
+type LeaseEvent struct {
+	ID int64
+
+	EventType keadata.LeaseEventType
+	// The time when the transition occurred.
+	OccurredAt time.Time
+	// Unix time in nanoseconds when the agent observed the transition.
+	ObservedAt int64
+
+	keadata.Lease
+
+	DaemonID int64
+	Daemon   *Daemon `pg:"rel:has-one"`
+	// Stork's subnet ID. It is zero if the subnet is not known to Stork.
+	SubnetID int64
+	Subnet   *Subnet `pg:"rel:has-one"`
+}
+
+// Container for values filtering the lease events fetched by page.
+//
+// Identifier matches the hardware address, DUID or client identifier
+// regardless of the separators used. The Start and End limit the time
+// when the events occurred.
+type LeaseEventsByPageFilters struct {
+	DaemonID   *int64
+	SubnetID   *int64
+	IPAddress  *string
+	Identifier *string
+	Start      *time.Time
+	End        *time.Time
+}
+
+// Create a LeaseEvent from the gRPC LeaseEvent structure. It returns nil
+// if the event is missing or invalid.
+func NewLeaseEventFromGRPC(grpc *agentapi.LeaseEvent, daemonID, subnetID int64) *LeaseEvent {
+	if grpc == nil {
+		return nil
+	}
+	eventType, ok := keadata.NewLeaseEventTypeFromGRPC(grpc.Type)
+	if !ok {
+		return nil
+	}
+	lease := NewLeaseFromGRPC(grpc.Lease, daemonID, subnetID)
+	if lease == nil {
+		return nil
+	}
+	lease.Type = grpc.Lease.Type
+	lease.Hostname = grpc.Lease.Hostname
+	lease.HWAddressSource = grpc.Lease.HwAddressSource
+	lease.HWType = grpc.Lease.HwType
+	lease.IAID = grpc.Lease.Iaid
+	lease.PreferredLifetime = grpc.Lease.PreferredLifetime
+	lease.FqdnFwd = grpc.Lease.FqdnFwd
+	lease.FqdnRev = grpc.Lease.FqdnRev
+	return &LeaseEvent{
+		EventType:  eventType,
+		OccurredAt: time.Unix(int64(grpc.OccurredAt), 0).UTC(), // #nosec G115
+		ObservedAt: grpc.ObservedAt,
+		Lease:      lease.Lease,
+		DaemonID:   daemonID,
+		SubnetID:   subnetID,
+	}
+}
+
+// Adds the lease events into the database. The events which have already
+// been stored are not duplicated, but their observation time is updated,
+// so the agent does not send them again.
+func AddLeaseEvents(dbi dbops.DBI, events []*LeaseEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := dbi.Model(&events).
+		OnConflict("(daemon_id, ip_address, event_type, cltt) DO UPDATE").
+		Set("observed_at = EXCLUDED.observed_at").
+		Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem inserting %d lease events", len(events))
+	}
+	return nil
+}
+
+// Returns the observation time of the most recent lease event of the
+// specified daemon or zero if there are no events.
+func GetLastLeaseEventObservedAt(dbi dbops.DBI, daemonID int64) (int64, error) {
+	var observedAt int64
+	err := dbi.Model((*LeaseEvent)(nil)).
+		ColumnExpr("COALESCE(MAX(observed_at), 0)").
+		Where("daemon_id = ?", daemonID).
+		Select(pg.Scan(&observedAt))
+	if err != nil {
+		return 0, pkgerrors.Wrapf(err, "problem getting the last lease event of the daemon %d", daemonID)
+	}
+	return observedAt, nil
+}
+
+// Fetches a collection of lease events from the database, the most recent
+// first. It returns the events and their total number.
+func GetLeaseEventsByPage(dbi dbops.DBI, offset, limit int64, filters LeaseEventsByPageFilters) ([]LeaseEvent, int64, error) {
+	events := []LeaseEvent{}
+	q := dbi.Model(&events).
+		Relation("Daemon").
+		Relation("Daemon.Machine").
+		Relation("Subnet")
+
+	if filters.DaemonID != nil {
+		q = q.Where("lease_event.daemon_id = ?", *filters.DaemonID)
+	}
+	if filters.SubnetID != nil {
+		q = q.Where("lease_event.subnet_id = ?", *filters.SubnetID)
+	}
+	if filters.IPAddress != nil && len(*filters.IPAddress) > 0 {
+		q = q.Where("lease_event.ip_address = ?", *filters.IPAddress)
+	}
+	if filters.Identifier != nil && len(*filters.Identifier) > 0 {
+		identifier := strings.ToLower(storkutil.BytesToHex(storkutil.HexToBytes(*filters.Identifier)))
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("REPLACE(CAST(lease_event.hw_address AS TEXT), ':', '') = ?", identifier).
+				WhereOr("encode(lease_event.duid, 'hex') = ?", identifier).
+				WhereOr("encode(lease_event.client_id, 'hex') = ?", identifier)
+			return q, nil
+		})
+	}
+	if filters.Start != nil {
+		q = q.Where("lease_event.occurred_at >= ?", *filters.Start)
+	}
+	if filters.End != nil {
+		q = q.Where("lease_event.occurred_at <= ?", *filters.End)
+	}
+
+	total, err := q.
+		OrderExpr("lease_event.occurred_at DESC").
+		OrderExpr("lease_event.id DESC").
+		Offset(int(offset)).
+		Limit(int(limit)).
+		SelectAndCount()
+	if err != nil {
+		return nil, 0, pkgerrors.Wrap(err, "problem getting lease events by page")
+	}
+	return events, int64(total), nil
+}
+
+// Returns the events which assigned or renewed the lease of the specified IP
+// address (or delegated prefix) held by a client at the specified time. There
+// is at most one event per daemon. The lease is not held when it has expired
+// or was released or declined before the specified time.
+func GetLeaseHolders(dbi dbops.DBI, ipAddress string, at time.Time) ([]LeaseEvent, error) {
+	// Find the most recent event preceding the specified time for each daemon.
+	events := []LeaseEvent{}
+	err := dbi.Model(&events).
+		DistinctOn("lease_event.daemon_id").
+		Relation("Daemon").
+		Relation("Daemon.Machine").
+		Relation("Subnet").
+		Where("lease_event.ip_address = ?", ipAddress).
+		Where("lease_event.occurred_at <= ?", at).
+		OrderExpr("lease_event.daemon_id").
+		OrderExpr("lease_event.occurred_at DESC").
+		OrderExpr("lease_event.id DESC").
+		Select()
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting the holders of the lease %s", ipAddress)
+	}
+	holders := []LeaseEvent{}
+	for _, event := range events {
+		if event.EventType != keadata.LeaseEventAssigned && event.EventType != keadata.LeaseEventRenewed {
+			continue
+		}
+		expiresAt := event.OccurredAt.Add(time.Duration(event.ValidLifetime) * time.Second)
+		if expiresAt.After(at) {
+			holders = append(holders, event)
+		}
+	}
+	return holders, nil
+}
+
+// Deletes the lease events which occurred before the specified time. It
+// returns the number of deleted events.
+func DeleteLeaseEventsBefore(dbi dbops.DBI, before time.Time) (int64, error) {
+	result, err := dbi.Model((*LeaseEvent)(nil)).
+		Where("occurred_at < ?", before).
+		Delete()
+	if err != nil {
+		return 0, pkgerrors.Wrap(err, "problem deleting old lease events")
+	}
+	return int64(result.RowsAffected()), nil
+}
@@ -0,0 +1,236 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	require "github.com/stretchr/testify/require"
+	agentapi "isc.org/stork/api"
+	keadata "isc.org/stork/daemondata/kea"
+	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
+)
+
+// Creates a lease event of the specified type for the DHCPv4 lease.
+func newTestLeaseEvent(daemon *Daemon, eventType keadata.LeaseEventType, ipAddress, hwAddress string, occurredAt time.Time, validLifetime uint32) *LeaseEvent {
+	return &LeaseEvent{
+		EventType:  eventType,
+		OccurredAt: occurredAt,
+		ObservedAt: occurredAt.UnixNano(),
+		Lease: keadata.Lease{
+			Family:        storkutil.IPv4,
+			IPAddress:     ipAddress,
+			HWAddress:     hwAddress,
+			CLTT:          uint64(occurredAt.Unix()), // #nosec G115
+			ValidLifetime: validLifetime,
+			LocalSubnetID: 7,
+		},
+		DaemonID: daemon.ID,
+	}
+}
+
+// Test converting the lease event from the gRPC structure.
+func TestNewLeaseEventFromGRPC(t *testing.T) {
+	event := NewLeaseEventFromGRPC(&agentapi.LeaseEvent{
+		Type: agentapi.LeaseEvent_RENEWED,
+		Lease: &agentapi.Lease{
+			Family:        4,
+			IpAddress:     "192.0.2.1",
+			HwAddress:     "01:02:03:04:05:06",
+			Hostname:      "foo.example.org",
+			Cltt:          1000,
+			ValidLifetime: 3600,
+			SubnetID:      7,
+		},
+		OccurredAt: 1000,
+		ObservedAt: 2000,
+	}, 1, 2)
+	require.NotNil(t, event)
+	require.Equal(t, keadata.LeaseEventRenewed, event.EventType)
+	require.Equal(t, time.Unix(1000, 0).UTC(), event.OccurredAt)
+	require.EqualValues(t, 2000, event.ObservedAt)
+	require.Equal(t, "192.0.2.1", event.IPAddress)
+	require.Equal(t, "foo.example.org", event.Hostname)
+	require.EqualValues(t, 1, event.DaemonID)
+	require.EqualValues(t, 2, event.SubnetID)
+
+	require.Nil(t, NewLeaseEventFromGRPC(nil, 1, 2))
+	require.Nil(t, NewLeaseEventFromGRPC(&agentapi.LeaseEvent{Type: agentapi.LeaseEvent_ASSIGNED}, 1, 2))
+	require.Nil(t, NewLeaseEventFromGRPC(&agentapi.LeaseEvent{
+		Type:  agentapi.LeaseEvent_UNKNOWN,
+		Lease: &agentapi.Lease{Family: 4, IpAddress: "192.0.2.1"},
+	}, 1, 2))
+}
+
+// Test that the lease events are added without duplicates and the most
+// recent observation time is returned.
+func TestAddLeaseEvents(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, subnets := addTestLeaseDaemons(t, db)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	observedAt, err := GetLastLeaseEventObservedAt(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, observedAt)
+
+	event := newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.1", "01:02:03:04:05:06", now, 3600)
+	event.SubnetID = subnets[0].ID
+	err = AddLeaseEvents(db, []*LeaseEvent{event})
+	require.NoError(t, err)
+
+	// Add the same event observed again and the deletion of the lease.
+	duplicate := newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.1", "01:02:03:04:05:06", now, 3600)
+	duplicate.ObservedAt += 10
+	released := newTestLeaseEvent(daemons[0], keadata.LeaseEventReleased, "192.0.2.1", "01:02:03:04:05:06", now, 0)
+	released.OccurredAt = now.Add(time.Minute)
+	released.ObservedAt += 5
+	err = AddLeaseEvents(db, []*LeaseEvent{duplicate, released})
+	require.NoError(t, err)
+
+	events, total, err := GetLeaseEventsByPage(db, 0, 10, LeaseEventsByPageFilters{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, events, 2)
+	require.Equal(t, keadata.LeaseEventReleased, events[0].EventType)
+	require.Zero(t, events[0].ValidLifetime)
+	require.Equal(t, keadata.LeaseEventAssigned, events[1].EventType)
+	require.Equal(t, subnets[0].ID, events[1].SubnetID)
+	require.NotNil(t, events[1].Daemon)
+	require.NotNil(t, events[1].Daemon.Machine)
+	require.NotNil(t, events[1].Subnet)
+
+	observedAt, err = GetLastLeaseEventObservedAt(db, daemons[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, now.UnixNano()+10, observedAt)
+
+	observedAt, err = GetLastLeaseEventObservedAt(db, daemons[1].ID)
+	require.NoError(t, err)
+	require.Zero(t, observedAt)
+}
+
+// Test filtering the lease events.
+func TestGetLeaseEventsByPageFilters(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, _ := addTestLeaseDaemons(t, db)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*LeaseEvent{
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.1", "01:02:03:04:05:06", now.AddDate(0, -1, 0), 3600),
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.2", "01:02:03:04:05:06", now, 3600),
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.3", "0a:0b:0c:0d:0e:0f", now, 3600),
+		newTestLeaseEvent(daemons[2], keadata.LeaseEventAssigned, "192.0.2.2", "01:02:03:04:05:06", now, 3600),
+	}
+	err := AddLeaseEvents(db, events)
+	require.NoError(t, err)
+
+	t.Run("identifier", func(t *testing.T) {
+		identifier := "01-02-03-04-05-06"
+		result, total, err := GetLeaseEventsByPage(db, 0, 10, LeaseEventsByPageFilters{Identifier: &identifier})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, total)
+		require.Len(t, result, 3)
+	})
+
+	t.Run("identifier and time", func(t *testing.T) {
+		identifier := "010203040506"
+		start := now.AddDate(0, -1, -1)
+		end := now.AddDate(0, 0, -1)
+		result, total, err := GetLeaseEventsByPage(db, 0, 10, LeaseEventsByPageFilters{Identifier: &identifier, Start: &start, End: &end})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, total)
+		require.Equal(t, "192.0.2.1", result[0].IPAddress)
+	})
+
+	t.Run("IP address and daemon", func(t *testing.T) {
+		ipAddress := "192.0.2.2"
+		result, total, err := GetLeaseEventsByPage(db, 0, 10, LeaseEventsByPageFilters{IPAddress: &ipAddress, DaemonID: &daemons[2].ID})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, total)
+		require.Equal(t, daemons[2].ID, result[0].DaemonID)
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		result, total, err := GetLeaseEventsByPage(db, 1, 2, LeaseEventsByPageFilters{})
+		require.NoError(t, err)
+		require.EqualValues(t, 4, total)
+		require.Len(t, result, 2)
+	})
+}
+
+// Test finding the client holding the lease at the specified time.
+func TestGetLeaseHolders(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, _ := addTestLeaseDaemons(t, db)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*LeaseEvent{
+		// The first client holds the lease for two hours and releases it.
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.1", "01:01:01:01:01:01", start, 3600),
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventRenewed, "192.0.2.1", "01:01:01:01:01:01", start.Add(time.Hour), 3600),
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventReleased, "192.0.2.1", "01:01:01:01:01:01", start.Add(2*time.Hour), 0),
+		// The second client gets the lease later and it expires.
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.1", "02:02:02:02:02:02", start.Add(3*time.Hour), 3600),
+		// The HA partner records the same assignment.
+		newTestLeaseEvent(daemons[2], keadata.LeaseEventAssigned, "192.0.2.1", "02:02:02:02:02:02", start.Add(3*time.Hour), 3600),
+	}
+	err := AddLeaseEvents(db, events)
+	require.NoError(t, err)
+
+	holders, err := GetLeaseHolders(db, "192.0.2.1", start.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, holders, 1)
+	require.Equal(t, "01:01:01:01:01:01", holders[0].HWAddress)
+	require.Equal(t, keadata.LeaseEventRenewed, holders[0].EventType)
+
+	// Released.
+	holders, err = GetLeaseHolders(db, "192.0.2.1", start.Add(150*time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, holders)
+
+	holders, err = GetLeaseHolders(db, "192.0.2.1", start.Add(200*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, holders, 2)
+	require.Equal(t, "02:02:02:02:02:02", holders[0].HWAddress)
+	require.Equal(t, "02:02:02:02:02:02", holders[1].HWAddress)
+
+	// Expired.
+	holders, err = GetLeaseHolders(db, "192.0.2.1", start.Add(5*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, holders)
+
+	// Before the first assignment.
+	holders, err = GetLeaseHolders(db, "192.0.2.1", start.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, holders)
+}
+
+// Test deleting the old lease events.
+func TestDeleteLeaseEventsBefore(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemons, _ := addTestLeaseDaemons(t, db)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*LeaseEvent{
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.1", "01:01:01:01:01:01", now.AddDate(0, 0, -100), 3600),
+		newTestLeaseEvent(daemons[0], keadata.LeaseEventAssigned, "192.0.2.2", "01:01:01:01:01:01", now, 3600),
+	}
+	err := AddLeaseEvents(db, events)
+	require.NoError(t, err)
+
+	count, err := DeleteLeaseEventsBefore(db, now.AddDate(0, 0, -90))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+
+	result, total, err := GetLeaseEventsByPage(db, 0, 10, LeaseEventsByPageFilters{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Equal(t, "192.0.2.2", result[0].IPAddress)
+}
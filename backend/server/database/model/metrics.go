@@ -21,6 +21,25 @@ type CalculatedNetworkMetrics struct {
 	SharedNetworkStats Stats
 }
 
+// Metric values calculated for a zone served by a specific server in
+// a view.
+type CalculatedZoneSyncMetrics struct {
+	// Zone name.
+	Zone string
+	// View name.
+	View string
+	// Zone class.
+	Class string
+	// Label of the daemon serving the zone.
+	Daemon string
+	// Sync state of the zone compared with the other servers.
+	State ZoneSyncState
+	// Difference between the reference serial and the zone serial.
+	SerialLag int64
+	// Time in seconds for which the zone has been out of sync.
+	LagDuration float64
+}
+
 // Metric values calculated from the database.
 type CalculatedMetrics struct {
 	AuthorizedMachines   int64
@@ -28,6 +47,7 @@ type CalculatedMetrics struct {
 	UnreachableMachines  int64
 	SubnetMetrics        []CalculatedNetworkMetrics
 	SharedNetworkMetrics []CalculatedNetworkMetrics
+	ZoneSyncMetrics      []CalculatedZoneSyncMetrics
 }
 
 // Calculates various metrics using several SELECT queries.
@@ -66,5 +86,27 @@ func GetCalculatedMetrics(db *pg.DB) (*CalculatedMetrics, error) {
 		return nil, errors.Wrap(err, "cannot calculate shared network metrics")
 	}
 
+	zones, err := GetZonesForSyncCheck(db, ZoneRelationLocalZonesDaemon, ZoneRelationLocalZonesMachine)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot calculate zone sync metrics")
+	}
+	fetchTimes, err := GetZoneInventoryFetchTimes(db)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot calculate zone sync metrics")
+	}
+	for _, zone := range zones {
+		for _, sync := range zone.GetSyncStates(fetchTimes) {
+			metrics.ZoneSyncMetrics = append(metrics.ZoneSyncMetrics, CalculatedZoneSyncMetrics{
+				Zone:        zone.Name,
+				View:        sync.LocalZone.View,
+				Class:       sync.LocalZone.Class,
+				Daemon:      sync.LocalZone.Daemon.GetLabel(),
+				State:       sync.State,
+				SerialLag:   sync.SerialLag,
+				LagDuration: sync.LagDuration.Seconds(),
+			})
+		}
+	}
+
 	return &metrics, nil
 }
@@ -210,3 +210,29 @@ func DeleteLocalZoneRRs(dbi pg.DBI, localZoneID int64) error {
 	}
 	return deleteLocalZoneRRs(dbi.(*pg.Tx), localZoneID)
 }
+
+// Deletes the cached RRs and resets the timestamp of the last RRs fetch
+// within transaction for a specified local zone.
+func invalidateLocalZoneRRs(tx *pg.Tx, localZoneID int64) error {
+	if err := deleteLocalZoneRRs(tx, localZoneID); err != nil {
+		return err
+	}
+	_, err := tx.Model((*LocalZone)(nil)).
+		Column("zone_transfer_at").
+		Set("zone_transfer_at = NULL").
+		Where("id = ?", localZoneID).
+		Update()
+	return errors.Wrapf(err, "failed to reset RRs transfer time for local zone id %d", localZoneID)
+}
+
+// Invalidates the cached RRs for a specified local zone. It deletes the
+// cached RRs and resets the timestamp of the last RRs fetch, so the RRs
+// are fetched using the zone transfer next time they are requested.
+func InvalidateLocalZoneRRs(dbi pg.DBI, localZoneID int64) error {
+	if db, ok := dbi.(*pg.DB); ok {
+		return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+			return invalidateLocalZoneRRs(tx, localZoneID)
+		})
+	}
+	return invalidateLocalZoneRRs(dbi.(*pg.Tx), localZoneID)
+}
@@ -198,6 +198,64 @@ func TestAddGetDeleteLocalZoneRRs(t *testing.T) {
 	require.Len(t, returnedRRs, 0)
 }
 
+// Test that invalidating the cached RRs deletes them and resets the
+// timestamp of the last RRs fetch.
+func TestInvalidateLocalZoneRRs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &Machine{
+		Address:   "localhost",
+		AgentPort: int64(8080),
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := NewDaemon(machine, daemonname.Bind9, true, []*AccessPoint{})
+	err = AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	zone := &Zone{
+		Name: "example.com.",
+		LocalZones: []*LocalZone{
+			{
+				DaemonID: daemon.ID,
+				View:     "_default",
+				Class:    "IN",
+				Serial:   123456,
+				Type:     string(ZoneTypePrimary),
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	}
+	err = AddZones(db, zone)
+	require.NoError(t, err)
+	localZoneID := zone.LocalZones[0].ID
+
+	rr, err := dnsmodel.NewRR("example.com. 3600 IN A 192.0.2.1")
+	require.NoError(t, err)
+	err = AddLocalZoneRRs(db, &LocalZoneRR{
+		RR:          *rr,
+		LocalZoneID: localZoneID,
+	})
+	require.NoError(t, err)
+	err = UpdateLocalZoneRRsTransferAt(db, localZoneID)
+	require.NoError(t, err)
+
+	err = InvalidateLocalZoneRRs(db, localZoneID)
+	require.NoError(t, err)
+
+	returnedRRs, total, err := GetDNSConfigRRs(db, localZoneID, nil)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, returnedRRs)
+
+	returnedZone, err := GetZoneByID(db, zone.ID, ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Len(t, returnedZone.LocalZones, 1)
+	require.Nil(t, returnedZone.LocalZones[0].ZoneTransferAt)
+}
+
 // Test filtering RRs from the database.
 func TestFilterLocalZoneRRs(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
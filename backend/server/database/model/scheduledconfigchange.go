@@ -21,6 +21,7 @@ const (
 	ConfigOperationKeaHostAdd                ConfigOperation = "kea.host_add"
 	ConfigOperationKeaHostUpdate             ConfigOperation = "kea.host_update"
 	ConfigOperationKeaHostDelete             ConfigOperation = "kea.host_delete"
+	ConfigOperationKeaHostImport             ConfigOperation = "kea.host_import"
 	ConfigOperationKeaSharedNetworkAdd       ConfigOperation = "kea.shared_network_add"
 	ConfigOperationKeaSharedNetworkUpdate    ConfigOperation = "kea.shared_network_update"
 	ConfigOperationKeaSharedNetworkDelete    ConfigOperation = "kea.shared_network_delete"
@@ -28,6 +29,14 @@ const (
 	ConfigOperationKeaSubnetUpdate           ConfigOperation = "kea.subnet_update"
 	ConfigOperationKeaSubnetDelete           ConfigOperation = "kea.subnet_delete"
 	ConfigOperationKeaGlobalParametersUpdate ConfigOperation = "kea.global_parameters_update"
+	ConfigOperationKeaLeaseAdd               ConfigOperation = "kea.lease_add"
+	ConfigOperationKeaLeaseUpdate            ConfigOperation = "kea.lease_update"
+	ConfigOperationKeaLeaseDelete            ConfigOperation = "kea.lease_delete"
+	ConfigOperationKeaLeaseWipe              ConfigOperation = "kea.lease_wipe"
+	ConfigOperationKeaClientClassAdd         ConfigOperation = "kea.client_class_add"
+	ConfigOperationKeaClientClassUpdate      ConfigOperation = "kea.client_class_update"
+	ConfigOperationKeaClientClassDelete      ConfigOperation = "kea.client_class_delete"
+	ConfigOperationKeaConfigRollback         ConfigOperation = "kea.config_rollback"
 )
 
 // Indicates whether the config operation pertains to Kea.
@@ -35,6 +44,19 @@ func (op ConfigOperation) IsKeaOperation() bool {
 	return strings.HasPrefix(string(op), "kea.")
 }
 
+// Indicates whether the config operation modifies the daemon's
+// configuration. The lease operations modify the lease databases
+// rather than the configuration.
+func (op ConfigOperation) IsConfigAltering() bool {
+	switch op {
+	case ConfigOperationKeaLeaseAdd, ConfigOperationKeaLeaseUpdate,
+		ConfigOperationKeaLeaseDelete, ConfigOperationKeaLeaseWipe:
+		return false
+	default:
+		return true
+	}
+}
+
 // Representation of the config changes scheduled by the config
 // manager (see server/daemons). Each scheduled config change includes
 // a deadline (timestamp) indicating when this config change should
@@ -72,7 +94,12 @@ type ConfigUpdate struct {
 
 // Checks if any of the updates pertain to Kea.
 func (c ScheduledConfigChange) HasKeaUpdates() bool {
-	for _, update := range c.Updates {
+	return HasKeaConfigUpdates(c.Updates)
+}
+
+// Checks if any of the specified updates pertain to Kea.
+func HasKeaConfigUpdates(updates []*ConfigUpdate) bool {
+	for _, update := range updates {
 		if update.Operation.IsKeaOperation() {
 			return true
 		}
@@ -121,13 +148,79 @@ func GetScheduledConfigChanges(dbi dbops.DBI) ([]ScheduledConfigChange, error) {
 	return changes, err
 }
 
+// Returns a page of the scheduled config changes ordered by deadline. The
+// executed parameter is optional. If it is specified, only executed or only
+// pending config changes are returned. It also returns the total number of
+// the config changes matching the filter.
+func GetScheduledConfigChangesByPage(dbi dbops.DBI, offset, limit int64, executed *bool) ([]ScheduledConfigChange, int64, error) {
+	changes := []ScheduledConfigChange{}
+	q := dbi.Model(&changes).
+		Relation("User").
+		OrderExpr("scheduled_config_change.deadline_at ASC").
+		OrderExpr("scheduled_config_change.id ASC").
+		Offset(int(offset))
+	if executed != nil {
+		q = q.Where("scheduled_config_change.executed = ?", *executed)
+	}
+	if limit > 0 {
+		q = q.Limit(int(limit))
+	}
+	total, err := q.SelectAndCount()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return changes, 0, nil
+		}
+		return nil, 0, pkgerrors.Wrapf(err, "problem with getting scheduled config changes")
+	}
+	return changes, int64(total), nil
+}
+
+// Returns the scheduled config change by ID or nil if it doesn't exist.
+func GetScheduledConfigChangeByID(dbi dbops.DBI, changeID int64) (*ScheduledConfigChange, error) {
+	change := &ScheduledConfigChange{}
+	err := dbi.Model(change).
+		Relation("User").
+		Where("scheduled_config_change.id = ?", changeID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem with getting scheduled config change with id %d", changeID)
+	}
+	return change, nil
+}
+
+// Sets new deadline for the config change which hasn't been executed yet.
+// It returns ErrNotExists if the config change doesn't exist or it has been
+// already executed.
+func RescheduleConfigChange(dbi dbops.DBI, changeID int64, deadline time.Time) error {
+	change := &ScheduledConfigChange{
+		ID:         changeID,
+		DeadlineAt: deadline,
+	}
+	result, err := dbi.Model(change).
+		Column("deadline_at").
+		WherePK().
+		Where("executed = ?", false).
+		Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with rescheduling config change %d", changeID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "pending config change with id %d does not exist", changeID)
+	}
+	return nil
+}
+
 // Returns scheduled and not executed config changes which deadline has expired.
 func GetDueConfigChanges(dbi dbops.DBI) ([]ScheduledConfigChange, error) {
 	var changes []ScheduledConfigChange
 	err := dbi.Model(&changes).
-		OrderExpr("deadline_at ASC").
-		Where("executed = ?", false).
-		Where("deadline_at < now() at time zone 'UTC'").
+		Relation("User").
+		OrderExpr("scheduled_config_change.deadline_at ASC").
+		Where("scheduled_config_change.executed = ?", false).
+		Where("scheduled_config_change.deadline_at < now() at time zone 'UTC'").
 		Select()
 	if err != nil {
 		if errors.Is(err, pg.ErrNoRows) {
@@ -180,6 +273,23 @@ func GetTimeToNextScheduledConfigChange(dbi dbops.DBI) (time.Duration, bool, err
 	return time.Duration(*tm.Duration) * time.Second, true, err
 }
 
+// Deletes selected config change which hasn't been executed yet. It returns
+// ErrNotExists if the config change doesn't exist or it has been already
+// executed.
+func CancelScheduledConfigChange(dbi dbops.DBI, changeID int64) error {
+	scc := &ScheduledConfigChange{
+		ID: changeID,
+	}
+	result, err := dbi.Model(scc).WherePK().Where("executed = ?", false).Delete()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem with canceling scheduled config change with id %d", changeID)
+	}
+	if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "pending config change with id %d does not exist", changeID)
+	}
+	return nil
+}
+
 // Deletes selected scheduled config change from the database.
 func DeleteScheduledConfigChange(dbi dbops.DBI, changeID int64) error {
 	scc := &ScheduledConfigChange{
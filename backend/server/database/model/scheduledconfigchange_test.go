@@ -185,6 +185,168 @@ func TestGetDueConfigChanges(t *testing.T) {
 	require.EqualValues(t, 2, returned[0].Updates[0].DaemonIDs[0])
 }
 
+// Test getting the scheduled config changes by page and by ID.
+func TestGetScheduledConfigChangesByPage(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := 1; i <= 3; i++ {
+		change := &ScheduledConfigChange{
+			CreatedAt:  storkutil.UTCNow(),
+			DeadlineAt: storkutil.UTCNow().Add(time.Duration(i) * time.Hour),
+			UserID:     user.ID,
+			Updates: []*ConfigUpdate{
+				NewConfigUpdate(ConfigOperationKeaHostDelete, int64(i)),
+			},
+		}
+		err = AddScheduledConfigChange(db, change)
+		require.NoError(t, err)
+		ids = append(ids, change.ID)
+	}
+	err = SetScheduledConfigChangeExecuted(db, ids[0], "failed")
+	require.NoError(t, err)
+
+	// Get all changes.
+	changes, total, err := GetScheduledConfigChangesByPage(db, 0, 10, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, changes, 3)
+	require.Equal(t, ids[0], changes[0].ID)
+	require.NotNil(t, changes[0].User)
+	require.Equal(t, "test", changes[0].User.Login)
+
+	// Get the second page.
+	changes, total, err = GetScheduledConfigChangesByPage(db, 2, 10, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Len(t, changes, 1)
+	require.Equal(t, ids[2], changes[0].ID)
+
+	// Get pending changes.
+	changes, total, err = GetScheduledConfigChangesByPage(db, 0, 10, storkutil.Ptr(false))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Len(t, changes, 2)
+	require.Equal(t, ids[1], changes[0].ID)
+
+	// Get executed changes.
+	changes, total, err = GetScheduledConfigChangesByPage(db, 0, 10, storkutil.Ptr(true))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Len(t, changes, 1)
+	require.Equal(t, "failed", changes[0].Error)
+
+	// Get a change by ID.
+	change, err := GetScheduledConfigChangeByID(db, ids[1])
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	require.False(t, change.Executed)
+	require.Len(t, change.Updates, 1)
+	require.NotNil(t, change.User)
+
+	// Non-existing change.
+	change, err = GetScheduledConfigChangeByID(db, ids[2]+1)
+	require.NoError(t, err)
+	require.Nil(t, change)
+}
+
+// Test rescheduling a pending config change.
+func TestRescheduleConfigChange(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+
+	change := &ScheduledConfigChange{
+		CreatedAt:  storkutil.UTCNow(),
+		DeadlineAt: storkutil.UTCNow().Add(time.Hour),
+		UserID:     user.ID,
+		Updates: []*ConfigUpdate{
+			NewConfigUpdate(ConfigOperationKeaHostDelete, 1),
+		},
+	}
+	err = AddScheduledConfigChange(db, change)
+	require.NoError(t, err)
+
+	deadline := storkutil.UTCNow().Add(-time.Minute).Truncate(time.Second)
+	err = RescheduleConfigChange(db, change.ID, deadline)
+	require.NoError(t, err)
+
+	returned, err := GetScheduledConfigChangeByID(db, change.ID)
+	require.NoError(t, err)
+	require.NotNil(t, returned)
+	require.Equal(t, deadline, returned.DeadlineAt)
+
+	// The change is now due.
+	due, err := GetDueConfigChanges(db)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+
+	// The executed change cannot be rescheduled.
+	err = SetScheduledConfigChangeExecuted(db, change.ID, "")
+	require.NoError(t, err)
+	err = RescheduleConfigChange(db, change.ID, storkutil.UTCNow().Add(time.Hour))
+	require.ErrorIs(t, err, ErrNotExists)
+}
+
+// Test canceling a pending config change.
+func TestCancelScheduledConfigChange(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	user := &SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := CreateUser(db, user)
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := 0; i < 2; i++ {
+		change := &ScheduledConfigChange{
+			CreatedAt:  storkutil.UTCNow(),
+			DeadlineAt: storkutil.UTCNow().Add(time.Hour),
+			UserID:     user.ID,
+			Updates: []*ConfigUpdate{
+				NewConfigUpdate(ConfigOperationKeaHostDelete, 1),
+			},
+		}
+		err = AddScheduledConfigChange(db, change)
+		require.NoError(t, err)
+		ids = append(ids, change.ID)
+	}
+	err = SetScheduledConfigChangeExecuted(db, ids[1], "")
+	require.NoError(t, err)
+
+	err = CancelScheduledConfigChange(db, ids[0])
+	require.NoError(t, err)
+
+	// The executed change cannot be canceled.
+	err = CancelScheduledConfigChange(db, ids[1])
+	require.ErrorIs(t, err, ErrNotExists)
+
+	changes, err := GetScheduledConfigChanges(db)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, ids[1], changes[0].ID)
+}
+
 // Test marking the specified config change as executed.
 func TestSetConfigChangeExecuted(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -395,3 +557,12 @@ func TestHasKeaUpdatesNoKeaUpdate(t *testing.T) {
 	}
 	require.False(t, change.HasKeaUpdates())
 }
+
+// Test that the lease operations are not considered config altering.
+func TestConfigOperationIsConfigAltering(t *testing.T) {
+	require.True(t, ConfigOperationKeaSubnetAdd.IsConfigAltering())
+	require.True(t, ConfigOperationKeaGlobalParametersUpdate.IsConfigAltering())
+	require.True(t, ConfigOperationKeaConfigRollback.IsConfigAltering())
+	require.False(t, ConfigOperationKeaLeaseAdd.IsConfigAltering())
+	require.False(t, ConfigOperationKeaLeaseWipe.IsConfigAltering())
+}
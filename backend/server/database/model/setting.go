@@ -42,11 +42,14 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 	// Init puller intervals.
 	longInterval := "60"
 	mediumInterval := "30"
+	// Refreshing the zone inventories, if enabled, is expensive.
+	zoneSyncInterval := "600"
 
 	if initialPullerInterval != 0 {
 		interval := fmt.Sprint(initialPullerInterval)
 		longInterval = interval
 		mediumInterval = interval
+		zoneSyncInterval = interval
 	}
 
 	// list of all stork settings with default values
@@ -61,6 +64,11 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeInt,
 			Value:   longInterval,
 		},
+		{
+			Name:    "kea_d2_stats_puller_interval", // in seconds
+			ValType: SettingValTypeInt,
+			Value:   longInterval,
+		},
 		{
 			Name:    "kea_hosts_puller_interval", // in seconds
 			ValType: SettingValTypeInt,
@@ -81,6 +89,11 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeInt,
 			Value:   longInterval,
 		},
+		{
+			Name:    "zone_sync_puller_interval", // in seconds
+			ValType: SettingValTypeInt,
+			Value:   zoneSyncInterval,
+		},
 		{
 			Name:    "grafana_url",
 			ValType: SettingValTypeStr,
@@ -112,6 +125,43 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeBool,
 			Value:   "true",
 		},
+		{
+			// Requires an approval of the config changes by a member
+			// of the approver group before they are committed.
+			Name:    "enable_config_change_approval",
+			ValType: SettingValTypeBool,
+			Value:   "false",
+		},
+		{
+			// Number of days ahead for which the warnings about the
+			// subnets projected to run out of leases are raised.
+			// Zero disables the warnings.
+			Name:    "utilization_forecast_horizon",
+			ValType: SettingValTypeInt,
+			Value:   "30",
+		},
+		{
+			// Number of days for which the lease lifecycle events are
+			// kept. Zero disables removing the old events.
+			Name:    "lease_history_retention",
+			ValType: SettingValTypeInt,
+			Value:   "90",
+		},
+		{
+			// Number of seconds after which the zone served with an
+			// older or newer serial than the primary is reported.
+			// Zero disables the reports.
+			Name:    "zone_serial_lag_threshold",
+			ValType: SettingValTypeInt,
+			Value:   "3600",
+		},
+		{
+			// Refreshes the zone inventories on the agents before
+			// comparing the zone serials.
+			Name:    "enable_zone_sync_refresh",
+			ValType: SettingValTypeBool,
+			Value:   "false",
+		},
 	}
 
 	// Check if there are new settings vs existing ones. Add new ones to DB.
@@ -53,6 +53,14 @@ func TestInitializeSettings(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, 60, val)
 
+	val, err = GetSettingInt(db, "zone_sync_puller_interval")
+	require.NoError(t, err)
+	require.EqualValues(t, 600, val)
+
+	val, err = GetSettingInt(db, "zone_serial_lag_threshold")
+	require.NoError(t, err)
+	require.EqualValues(t, 3600, val)
+
 	boolVal, err := GetSettingBool(db, "enable_machine_registration")
 	require.NoError(t, err)
 	require.True(t, boolVal)
@@ -61,6 +69,10 @@ func TestInitializeSettings(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, boolVal)
 
+	boolVal, err = GetSettingBool(db, "enable_zone_sync_refresh")
+	require.NoError(t, err)
+	require.False(t, boolVal)
+
 	valStr, err := GetSettingStr(db, "grafana_url")
 	require.NoError(t, err)
 	require.Empty(t, valStr)
@@ -145,6 +157,8 @@ func TestInitializeSettingsWithInterval(t *testing.T) {
 	stateInterval, err6 := GetSettingInt(db, "state_puller_interval")
 	haStatusInterval, err7 := GetSettingInt(db, "kea_status_puller_interval")
 	leasesPullerInterval, err8 := GetSettingInt(db, "kea_leases_puller_interval")
+	d2StatsInterval, err9 := GetSettingInt(db, "kea_d2_stats_puller_interval")
+	zoneSyncInterval, err10 := GetSettingInt(db, "zone_sync_puller_interval")
 
 	// Assert
 	require.NoError(t, err1)
@@ -155,6 +169,8 @@ func TestInitializeSettingsWithInterval(t *testing.T) {
 	require.NoError(t, err6)
 	require.NoError(t, err7)
 	require.NoError(t, err8)
+	require.NoError(t, err9)
+	require.NoError(t, err10)
 
 	require.EqualValues(t, 42, bind9Interval)
 	require.EqualValues(t, 42, keaStatsInterval)
@@ -162,6 +178,8 @@ func TestInitializeSettingsWithInterval(t *testing.T) {
 	require.EqualValues(t, 42, stateInterval)
 	require.EqualValues(t, 42, haStatusInterval)
 	require.EqualValues(t, 42, leasesPullerInterval)
+	require.EqualValues(t, 42, d2StatsInterval)
+	require.EqualValues(t, 42, zoneSyncInterval)
 }
 
 // Check getting and setting settings.
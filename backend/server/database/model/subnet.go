@@ -568,6 +568,22 @@ func GetSubnet(dbi dbops.DBI, subnetID int64) (*Subnet, error) {
 	return subnet, err
 }
 
+// Fetches the subnets with the specified IDs without their relations.
+func GetSubnetsByIDs(dbi dbops.DBI, subnetIDs []int64) ([]Subnet, error) {
+	subnets := []Subnet{}
+	if len(subnetIDs) == 0 {
+		return subnets, nil
+	}
+	err := dbi.Model(&subnets).
+		WhereIn("subnet.id IN (?)", subnetIDs).
+		OrderExpr("subnet.id ASC").
+		Select()
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting subnets by IDs")
+	}
+	return subnets, nil
+}
+
 // Fetches all subnets associated with the given daemon by ID.
 func GetSubnetsByDaemonID(dbi dbops.DBI, daemonID int64) ([]Subnet, error) {
 	subnets := []Subnet{}
@@ -0,0 +1,172 @@
+package dbmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Type of the object whose utilization is sampled.
+type UtilizationObjectType string
+
+const (
+	UtilizationObjectSubnet        UtilizationObjectType = "subnet"
+	UtilizationObjectSharedNetwork UtilizationObjectType = "shared-network"
+	UtilizationObjectAddressPool   UtilizationObjectType = "address-pool"
+	UtilizationObjectPrefixPool    UtilizationObjectType = "prefix-pool"
+)
+
+// Resolution of the utilization samples. The raw samples are stored as
+// collected by the stats puller. They are later replaced with the hourly
+// averages, and the hourly averages with the daily averages.
+type UtilizationResolution string
+
+const (
+	UtilizationResolutionRaw  UtilizationResolution = "raw"
+	UtilizationResolutionHour UtilizationResolution = "hour"
+	UtilizationResolutionDay  UtilizationResolution = "day"
+)
+
+// Returns the name of the PostgreSQL date_trunc() field corresponding to
+// the resolution.
+func (resolution UtilizationResolution) truncateField() string {
+	switch resolution {
+	case UtilizationResolutionHour:
+		return "hour"
+	case UtilizationResolutionDay:
+		return "day"
+	default:
+		return "second"
+	}
+}
+
+// A single sample of the address and delegated prefix utilization of
+// a subnet, shared network or pool. The pools hold only one of the
+// utilizations, depending on the pool type.
+type UtilizationSample struct {
+	ObjectType      UtilizationObjectType `pg:",pk"`
+	ObjectID        int64                 `pg:",pk"`
+	Resolution      UtilizationResolution `pg:",pk"`
+	SampledAt       time.Time             `pg:",pk"`
+	AddrUtilization Utilization
+	PdUtilization   Utilization
+}
+
+// Copies the current utilizations of all subnets, shared networks and pools
+// having the statistics into the utilization samples. The samples are taken
+// at the specified time.
+func AddUtilizationSamples(db *pg.DB, sampledAt time.Time) error {
+	queries := []struct {
+		objectType UtilizationObjectType
+		query      string
+	}{
+		{
+			UtilizationObjectSubnet,
+			"SELECT ?, id, ?, ?, addr_utilization, pd_utilization FROM subnet WHERE stats_collected_at IS NOT NULL",
+		},
+		{
+			UtilizationObjectSharedNetwork,
+			"SELECT ?, id, ?, ?, addr_utilization, pd_utilization FROM shared_network WHERE stats_collected_at IS NOT NULL",
+		},
+		{
+			UtilizationObjectAddressPool,
+			"SELECT ?, id, ?, ?, utilization, NULL FROM address_pool WHERE stats_collected_at IS NOT NULL",
+		},
+		{
+			UtilizationObjectPrefixPool,
+			"SELECT ?, id, ?, ?, NULL, utilization FROM prefix_pool WHERE stats_collected_at IS NOT NULL",
+		},
+	}
+	return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		for _, q := range queries {
+			_, err := tx.Exec(`
+				INSERT INTO utilization_sample (object_type, object_id, resolution, sampled_at, addr_utilization, pd_utilization) `+
+				q.query+` ON CONFLICT DO NOTHING`,
+				q.objectType, UtilizationResolutionRaw, sampledAt,
+			)
+			if err != nil {
+				return pkgerrors.Wrapf(err, "problem adding %s utilization samples", q.objectType)
+			}
+		}
+		return nil
+	})
+}
+
+// Returns the utilization samples of the objects of the specified type
+// taken in the specified time range, ordered by the object ID and the
+// sampling time. If the object IDs are not specified, the samples of all
+// objects of this type are returned. The zero start or end time means
+// no limit.
+func GetUtilizationSamples(db pg.DBI, objectType UtilizationObjectType, objectIDs []int64, start, end time.Time) ([]UtilizationSample, error) {
+	samples := []UtilizationSample{}
+	q := db.Model(&samples).
+		Where("object_type = ?", objectType).
+		OrderExpr("object_id ASC").
+		OrderExpr("sampled_at ASC")
+	if len(objectIDs) > 0 {
+		q = q.WhereIn("object_id IN (?)", objectIDs)
+	}
+	if !start.IsZero() {
+		q = q.Where("sampled_at >= ?", start)
+	}
+	if !end.IsZero() {
+		q = q.Where("sampled_at <= ?", end)
+	}
+	err := q.Select()
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting %s utilization samples", objectType)
+	}
+	return samples, nil
+}
+
+// Replaces the samples of the specified resolution taken before the
+// specified time with their averages in the coarser resolution. Only the
+// complete periods (e.g., hours) are downsampled.
+func DownsampleUtilizationSamples(db *pg.DB, from, to UtilizationResolution, before time.Time) error {
+	return db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO utilization_sample (object_type, object_id, resolution, sampled_at, addr_utilization, pd_utilization)
+			SELECT object_type, object_id, ?2, date_trunc(?3, sampled_at) AS period,
+				ROUND(AVG(addr_utilization)), ROUND(AVG(pd_utilization))
+			FROM utilization_sample
+			WHERE resolution = ?0 AND sampled_at < date_trunc(?3, ?1::timestamp)
+			GROUP BY object_type, object_id, period
+			ON CONFLICT DO NOTHING`,
+			from, before, to, to.truncateField(),
+		)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "problem downsampling utilization samples to %s resolution", to)
+		}
+		_, err = tx.Exec(`
+			DELETE FROM utilization_sample
+			WHERE resolution = ? AND sampled_at < date_trunc(?, ?::timestamp)`,
+			from, to.truncateField(), before,
+		)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "problem deleting downsampled %s utilization samples", from)
+		}
+		return nil
+	})
+}
+
+// Deletes the utilization samples taken before the specified time and
+// the samples of the objects that no longer exist.
+func AgeOffUtilizationSamples(db *pg.DB, before time.Time) error {
+	_, err := db.Exec(`
+		DELETE FROM utilization_sample AS s
+		WHERE s.sampled_at < ?
+			OR (s.object_type = ? AND NOT EXISTS (SELECT 1 FROM subnet WHERE id = s.object_id))
+			OR (s.object_type = ? AND NOT EXISTS (SELECT 1 FROM shared_network WHERE id = s.object_id))
+			OR (s.object_type = ? AND NOT EXISTS (SELECT 1 FROM address_pool WHERE id = s.object_id))
+			OR (s.object_type = ? AND NOT EXISTS (SELECT 1 FROM prefix_pool WHERE id = s.object_id))`,
+		before,
+		UtilizationObjectSubnet, UtilizationObjectSharedNetwork,
+		UtilizationObjectAddressPool, UtilizationObjectPrefixPool,
+	)
+	if err != nil {
+		return pkgerrors.Wrap(err, "problem aging off utilization samples")
+	}
+	return nil
+}
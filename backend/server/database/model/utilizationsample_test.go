@@ -0,0 +1,164 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the utilization samples are copied from the subnets and
+// shared networks having the statistics.
+func TestAddUtilizationSamples(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	sharedNetwork := &SharedNetwork{
+		Name:   "foo",
+		Family: 4,
+	}
+	err := AddSharedNetwork(db, sharedNetwork)
+	require.NoError(t, err)
+
+	subnets := []Subnet{
+		{
+			Prefix:          "192.0.2.0/24",
+			SharedNetworkID: sharedNetwork.ID,
+		},
+		{
+			Prefix: "192.0.3.0/24",
+		},
+	}
+	for i := range subnets {
+		err = AddSubnet(db, &subnets[i])
+		require.NoError(t, err)
+	}
+
+	// Only the first subnet and the shared network have the statistics.
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err = db.Model(&subnets[0]).
+		Set("addr_utilization = ?", Utilization(0.25)).
+		Set("stats_collected_at = ?", now).
+		WherePK().
+		Update()
+	require.NoError(t, err)
+	_, err = db.Model(sharedNetwork).
+		Set("addr_utilization = ?", Utilization(0.5)).
+		Set("stats_collected_at = ?", now).
+		WherePK().
+		Update()
+	require.NoError(t, err)
+
+	err = AddUtilizationSamples(db, now)
+	require.NoError(t, err)
+	// Adding the samples at the same time again should be no-op.
+	err = AddUtilizationSamples(db, now)
+	require.NoError(t, err)
+
+	samples, err := GetUtilizationSamples(db, UtilizationObjectSubnet, nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	require.Equal(t, subnets[0].ID, samples[0].ObjectID)
+	require.Equal(t, UtilizationResolutionRaw, samples[0].Resolution)
+	require.Equal(t, now, samples[0].SampledAt)
+	require.InDelta(t, 0.25, float64(samples[0].AddrUtilization), 0.001)
+
+	samples, err = GetUtilizationSamples(db, UtilizationObjectSharedNetwork, []int64{sharedNetwork.ID}, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	require.InDelta(t, 0.5, float64(samples[0].AddrUtilization), 0.001)
+}
+
+// Test filtering the utilization samples by time.
+func TestGetUtilizationSamplesByTime(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_, err := db.Model(&UtilizationSample{
+			ObjectType: UtilizationObjectSubnet,
+			ObjectID:   1,
+			Resolution: UtilizationResolutionRaw,
+			SampledAt:  start.Add(time.Duration(i) * time.Hour),
+		}).Insert()
+		require.NoError(t, err)
+	}
+
+	samples, err := GetUtilizationSamples(db, UtilizationObjectSubnet, []int64{1}, start.Add(time.Hour), start.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+	require.Equal(t, start.Add(time.Hour), samples[0].SampledAt)
+	require.Equal(t, start.Add(3*time.Hour), samples[2].SampledAt)
+
+	samples, err = GetUtilizationSamples(db, UtilizationObjectSubnet, []int64{2}, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, samples)
+}
+
+// Test that the old samples are replaced with their averages.
+func TestDownsampleUtilizationSamples(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	// Four samples in the 10:00 hour and two in the 11:00 hour.
+	for i, utilization := range []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6} {
+		_, err := db.Model(&UtilizationSample{
+			ObjectType:      UtilizationObjectSubnet,
+			ObjectID:        1,
+			Resolution:      UtilizationResolutionRaw,
+			SampledAt:       start.Add(time.Duration(i) * 15 * time.Minute),
+			AddrUtilization: Utilization(utilization),
+		}).Insert()
+		require.NoError(t, err)
+	}
+
+	// Only the complete 10:00 hour should be downsampled.
+	err := DownsampleUtilizationSamples(db, UtilizationResolutionRaw, UtilizationResolutionHour, start.Add(90*time.Minute))
+	require.NoError(t, err)
+
+	samples, err := GetUtilizationSamples(db, UtilizationObjectSubnet, nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+	require.Equal(t, UtilizationResolutionHour, samples[0].Resolution)
+	require.Equal(t, start, samples[0].SampledAt)
+	require.InDelta(t, 0.25, float64(samples[0].AddrUtilization), 0.001)
+	require.Equal(t, UtilizationResolutionRaw, samples[1].Resolution)
+	require.Equal(t, UtilizationResolutionRaw, samples[2].Resolution)
+}
+
+// Test that the old samples and the samples of the deleted objects are
+// aged off.
+func TestAgeOffUtilizationSamples(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	subnet := &Subnet{
+		Prefix: "192.0.2.0/24",
+	}
+	err := AddSubnet(db, subnet)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, sample := range []UtilizationSample{
+		{ObjectID: subnet.ID, SampledAt: now.Add(-48 * time.Hour)},
+		{ObjectID: subnet.ID, SampledAt: now},
+		{ObjectID: subnet.ID + 1, SampledAt: now},
+	} {
+		sample.ObjectType = UtilizationObjectSubnet
+		sample.Resolution = UtilizationResolutionDay
+		_, err = db.Model(&sample).Insert()
+		require.NoError(t, err)
+	}
+
+	err = AgeOffUtilizationSamples(db, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+
+	samples, err := GetUtilizationSamples(db, UtilizationObjectSubnet, nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	require.Equal(t, subnet.ID, samples[0].ObjectID)
+	require.Equal(t, now, samples[0].SampledAt)
+}
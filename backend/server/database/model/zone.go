@@ -406,6 +406,27 @@ func GetZoneByID(db pg.DBI, id int64, relations ...ZoneRelation) (*Zone, error)
 	return &zone, nil
 }
 
+// Retrieves the zones with the specified names from the database. The names
+// must be specified without the trailing dot. The zones which don't exist
+// are not returned.
+func GetZonesByNames(db pg.DBI, names []string, relations ...ZoneRelation) ([]*Zone, error) {
+	var zones []*Zone
+	if len(names) == 0 {
+		return zones, nil
+	}
+	q := db.Model(&zones)
+	// Add relations.
+	for _, relation := range relations {
+		q = q.Relation(string(relation))
+	}
+	q = q.Where("zone.name IN (?)", pg.In(names)).OrderExpr("zone.rname ASC")
+	err := q.Select()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select zones by names")
+	}
+	return zones, nil
+}
+
 // Deletes zones which are not associated with any daemons. Returns deleted zone
 // count and an error.
 func DeleteOrphanedZones(dbi dbops.DBI) (int64, error) {
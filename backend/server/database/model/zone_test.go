@@ -959,6 +959,61 @@ func TestGetZoneByID(t *testing.T) {
 	require.Nil(t, zone)
 }
 
+// Test getting the zones by names.
+func TestGetZonesByNames(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &Machine{
+		ID:        0,
+		Address:   "localhost",
+		AgentPort: int64(8080),
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := NewDaemon(machine, daemonname.Bind9, true, []*AccessPoint{
+		{
+			Type:    AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+		},
+	})
+	err = AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	for _, name := range []string{"example.org", "example.com", "2.0.192.in-addr.arpa"} {
+		err = AddZones(db, &Zone{
+			Name: name,
+			LocalZones: []*LocalZone{
+				{
+					DaemonID: daemon.ID,
+					View:     "_default",
+					Class:    "IN",
+					Serial:   123456,
+					Type:     "primary",
+					LoadedAt: time.Now().UTC(),
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	zones, err := GetZonesByNames(db, []string{"example.org", "2.0.192.in-addr.arpa", "example.net"}, ZoneRelationLocalZonesDaemon)
+	require.NoError(t, err)
+	require.Len(t, zones, 2)
+	require.Equal(t, "2.0.192.in-addr.arpa", zones[0].Name)
+	require.Equal(t, "example.org", zones[1].Name)
+	require.Len(t, zones[1].LocalZones, 1)
+	require.NotNil(t, zones[1].LocalZones[0].Daemon)
+	require.Equal(t, daemon.ID, zones[1].LocalZones[0].Daemon.ID)
+
+	// No names specified.
+	zones, err = GetZonesByNames(db, []string{})
+	require.NoError(t, err)
+	require.Empty(t, zones)
+}
+
 // Test deleting the zones that have no associations with the daemons.
 func TestDeleteOrphanedZones(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
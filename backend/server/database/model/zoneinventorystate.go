@@ -150,3 +150,21 @@ func GetZoneInventoryStates(db pg.DBI, relations ...ZoneInventoryStateRelation)
 	}
 	return states, count, nil
 }
+
+// Returns the times when the zones were last successfully fetched from
+// the zone inventories of the daemons, indexed by the daemon IDs. The
+// daemons for which the last fetch failed are not included because their
+// zones in the database may be outdated or incomplete.
+func GetZoneInventoryFetchTimes(db pg.DBI) (map[int64]time.Time, error) {
+	states, _, err := GetZoneInventoryStates(db)
+	if err != nil {
+		return nil, err
+	}
+	fetchTimes := make(map[int64]time.Time)
+	for _, state := range states {
+		if state.State != nil && state.State.Status == ZoneInventoryStatusOK {
+			fetchTimes[state.DaemonID] = state.CreatedAt
+		}
+	}
+	return fetchTimes, nil
+}
@@ -3,6 +3,7 @@ package dbmodel
 import (
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"isc.org/stork/datamodel/daemonname"
@@ -240,3 +241,37 @@ func TestGetZoneInventoryStates(t *testing.T) {
 		require.Positive(t, states[index].DaemonID)
 	}
 }
+
+// Test that the times of the last successful zone fetches are returned
+// for the daemons.
+func TestGetZoneInventoryFetchTimes(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fetchedAt := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	var daemonIDs []int64
+	for i, status := range []ZoneInventoryStatus{ZoneInventoryStatusOK, ZoneInventoryStatusErred} {
+		machine := &Machine{
+			Address:   "localhost",
+			AgentPort: int64(8080 + i),
+		}
+		err := AddMachine(db, machine)
+		require.NoError(t, err)
+
+		daemon := NewDaemon(machine, daemonname.Bind9, true, []*AccessPoint{})
+		err = AddDaemon(db, daemon)
+		require.NoError(t, err)
+		daemonIDs = append(daemonIDs, daemon.ID)
+
+		state := NewZoneInventoryState(daemon.ID, &ZoneInventoryStateDetails{Status: status})
+		state.CreatedAt = fetchedAt
+		err = AddZoneInventoryState(db, state)
+		require.NoError(t, err)
+	}
+
+	fetchTimes, err := GetZoneInventoryFetchTimes(db)
+	require.NoError(t, err)
+	require.Len(t, fetchTimes, 1)
+	require.Contains(t, fetchTimes, daemonIDs[0])
+	require.True(t, fetchedAt.Equal(fetchTimes[daemonIDs[0]]))
+}
@@ -0,0 +1,187 @@
+package dbmodel
+
+import (
+	"slices"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+)
+
+// Synchronization state of a zone served by several DNS servers in the
+// same view. It is determined by comparing the zone serials.
+type ZoneSyncState string
+
+const (
+	// All servers serve the same serial.
+	ZoneSyncStateInSync ZoneSyncState = "in-sync"
+	// The server serves an older serial than the reference server.
+	ZoneSyncStateLagging ZoneSyncState = "lagging"
+	// The server serves a newer serial than the primary server.
+	ZoneSyncStateInconsistent ZoneSyncState = "inconsistent"
+)
+
+// Returns the severity of the sync state used to select the worst state
+// among the servers.
+func (state ZoneSyncState) severity() int {
+	switch state {
+	case ZoneSyncStateInSync:
+		return 1
+	case ZoneSyncStateLagging:
+		return 2
+	case ZoneSyncStateInconsistent:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// The zone types which serials are compared. Other zone types (e.g.,
+// forward or builtin) don't have the meaningful serials.
+var syncZoneTypes = []ZoneType{
+	ZoneTypePrimary, ZoneTypeMaster,
+	ZoneTypeSecondary, ZoneTypeSlave, ZoneTypeMirror,
+}
+
+// Checks if the zone type denotes the primary zone.
+func isPrimaryZoneType(zoneType string) bool {
+	return zoneType == string(ZoneTypePrimary) || zoneType == string(ZoneTypeMaster)
+}
+
+// Checks if the zone serial is compared with the other servers.
+func isSyncZoneType(zoneType string) bool {
+	return slices.Contains(syncZoneTypes, ZoneType(zoneType))
+}
+
+// Compares the serials using the serial number arithmetic (RFC 1982). It
+// returns a positive value if the first serial is ahead of the second one,
+// a negative value if it is behind, and zero if the serials are equal.
+func compareSerials(serial1, serial2 int64) int64 {
+	return int64(int32(uint32(serial1) - uint32(serial2)))
+}
+
+// The sync state of the zone served by a single server.
+type LocalZoneSync struct {
+	LocalZone *LocalZone
+	// The local zone with which the serial is compared. It is the primary
+	// zone with the highest serial or, if the primary is not monitored, the
+	// secondary zone with the highest serial.
+	Reference *LocalZone
+	State     ZoneSyncState
+	// Difference between the reference serial and the local zone serial.
+	// It is negative when the local zone is ahead of the reference.
+	SerialLag int64
+	// For the lagging zone, it is the time elapsed between loading the
+	// newer serial by the reference server and the last fetch of the
+	// zones from the lagging server. For the inconsistent zone, it is the
+	// time elapsed between loading the serial by the server and the last
+	// fetch of the zones from the reference server. The lag is measured
+	// only against the zones fetched after the newer serial was loaded,
+	// so it doesn't grow when the fetched zones are stale.
+	LagDuration time.Duration
+}
+
+// Compares the serials of the local zones belonging to the same view
+// and class. The fetch times are the times when the zones were last
+// fetched from the daemons, indexed by the daemon IDs.
+func getLocalZonesSync(localZones []*LocalZone, fetchTimes map[int64]time.Time) []*LocalZoneSync {
+	if len(localZones) < 2 {
+		return nil
+	}
+	var reference *LocalZone
+	for _, localZone := range localZones {
+		switch {
+		case reference == nil:
+			reference = localZone
+		case isPrimaryZoneType(localZone.Type) != isPrimaryZoneType(reference.Type):
+			if isPrimaryZoneType(localZone.Type) {
+				reference = localZone
+			}
+		case compareSerials(localZone.Serial, reference.Serial) > 0:
+			reference = localZone
+		}
+	}
+	var syncs []*LocalZoneSync
+	for _, localZone := range localZones {
+		sync := &LocalZoneSync{
+			LocalZone: localZone,
+			Reference: reference,
+			State:     ZoneSyncStateInSync,
+			SerialLag: compareSerials(reference.Serial, localZone.Serial),
+		}
+		switch {
+		case sync.SerialLag > 0:
+			sync.State = ZoneSyncStateLagging
+			sync.LagDuration = max(fetchTimes[localZone.DaemonID].Sub(reference.LoadedAt), 0)
+		case sync.SerialLag < 0:
+			sync.State = ZoneSyncStateInconsistent
+			sync.LagDuration = max(fetchTimes[reference.DaemonID].Sub(localZone.LoadedAt), 0)
+		}
+		syncs = append(syncs, sync)
+	}
+	return syncs
+}
+
+// Compares the serials of the zone served by different servers in the
+// same view and class. It returns the sync states of the local zones
+// which can be compared with at least one other local zone. The local
+// zones of other types than primary and secondary are ignored. The fetch
+// times are typically returned by GetZoneInventoryFetchTimes.
+func (zone *Zone) GetSyncStates(fetchTimes map[int64]time.Time) []*LocalZoneSync {
+	type syncGroupKey struct {
+		view  string
+		class string
+	}
+	var keys []syncGroupKey
+	groups := make(map[syncGroupKey][]*LocalZone)
+	for _, localZone := range zone.LocalZones {
+		if !isSyncZoneType(localZone.Type) {
+			continue
+		}
+		key := syncGroupKey{view: localZone.View, class: localZone.Class}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], localZone)
+	}
+	var syncs []*LocalZoneSync
+	for _, key := range keys {
+		syncs = append(syncs, getLocalZonesSync(groups[key], fetchTimes)...)
+	}
+	return syncs
+}
+
+// Returns the worst sync state among the local zones. It returns an
+// empty state if there are no local zones to compare.
+func GetWorstZoneSyncState(syncs []*LocalZoneSync) ZoneSyncState {
+	var state ZoneSyncState
+	for _, sync := range syncs {
+		if sync.State.severity() > state.severity() {
+			state = sync.State
+		}
+	}
+	return state
+}
+
+// Returns the zones served by at least two servers in the same view and
+// class, for which the serials can be compared. The zones are returned
+// with their local zones and the specified relations.
+func GetZonesForSyncCheck(dbi pg.DBI, relations ...ZoneRelation) ([]*Zone, error) {
+	var zones []*Zone
+	q := dbi.Model(&zones)
+	for _, relation := range relations {
+		q = q.Relation(string(relation))
+	}
+	subquery := dbi.Model((*LocalZone)(nil)).
+		Column("zone_id").
+		WhereIn("type IN (?)", syncZoneTypes).
+		Group("zone_id", "view", "class").
+		Having("COUNT(*) > 1")
+	err := q.Where("zone.id IN (?)", subquery).
+		OrderExpr("zone.id ASC").
+		Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select the zones served by multiple servers")
+	}
+	return zones, nil
+}
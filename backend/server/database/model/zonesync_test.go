@@ -0,0 +1,197 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/datamodel/daemonname"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test comparing the serials using the serial number arithmetic.
+func TestCompareSerials(t *testing.T) {
+	require.Zero(t, compareSerials(2025071700, 2025071700))
+	require.EqualValues(t, 2, compareSerials(2025071702, 2025071700))
+	require.EqualValues(t, -2, compareSerials(2025071700, 2025071702))
+	// Wrapped serial is ahead of the serial before wrapping.
+	require.EqualValues(t, 10, compareSerials(5, 4294967291))
+	require.EqualValues(t, -10, compareSerials(4294967291, 5))
+}
+
+// Test that the sync states are determined for the local zones in the
+// same view and class.
+func TestZoneGetSyncStates(t *testing.T) {
+	now := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	zone := &Zone{
+		Name: "example.com",
+		LocalZones: []*LocalZone{
+			{ID: 1, DaemonID: 1, View: "_default", Class: "IN", Type: "secondary", Serial: 10, LoadedAt: now.Add(-3 * time.Hour)},
+			{ID: 2, DaemonID: 2, View: "_default", Class: "IN", Type: "primary", Serial: 12, LoadedAt: now.Add(-2 * time.Hour)},
+			{ID: 3, DaemonID: 3, View: "_default", Class: "IN", Type: "slave", Serial: 12, LoadedAt: now.Add(-time.Hour)},
+			{ID: 4, DaemonID: 4, View: "_default", Class: "IN", Type: "secondary", Serial: 13, LoadedAt: now.Add(-time.Minute)},
+			// Not compared with the zones in other views.
+			{ID: 5, DaemonID: 1, View: "guest", Class: "IN", Type: "secondary", Serial: 1, LoadedAt: now},
+			// Other zone types are ignored.
+			{ID: 6, DaemonID: 5, View: "_default", Class: "IN", Type: "forward", LoadedAt: now},
+		},
+	}
+	// The zones were fetched from all daemons at the same time.
+	fetchTimes := map[int64]time.Time{1: now, 2: now, 3: now, 4: now}
+	syncs := zone.GetSyncStates(fetchTimes)
+	require.Len(t, syncs, 4)
+
+	for _, sync := range syncs {
+		require.EqualValues(t, 2, sync.Reference.ID)
+	}
+	require.EqualValues(t, 1, syncs[0].LocalZone.ID)
+	require.Equal(t, ZoneSyncStateLagging, syncs[0].State)
+	require.EqualValues(t, 2, syncs[0].SerialLag)
+	require.Equal(t, 2*time.Hour, syncs[0].LagDuration)
+
+	require.Equal(t, ZoneSyncStateInSync, syncs[1].State)
+	require.Zero(t, syncs[1].SerialLag)
+	require.Zero(t, syncs[1].LagDuration)
+
+	require.Equal(t, ZoneSyncStateInSync, syncs[2].State)
+
+	require.Equal(t, ZoneSyncStateInconsistent, syncs[3].State)
+	require.EqualValues(t, -1, syncs[3].SerialLag)
+	require.Equal(t, time.Minute, syncs[3].LagDuration)
+
+	require.Equal(t, ZoneSyncStateInconsistent, GetWorstZoneSyncState(syncs))
+}
+
+// Test that the secondary with the highest serial is the reference when
+// the primary is not monitored.
+func TestZoneGetSyncStatesNoPrimary(t *testing.T) {
+	now := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	zone := &Zone{
+		Name: "example.com",
+		LocalZones: []*LocalZone{
+			{ID: 1, DaemonID: 1, View: "_default", Class: "IN", Type: "secondary", Serial: 10, LoadedAt: now},
+			{ID: 2, DaemonID: 2, View: "_default", Class: "IN", Type: "secondary", Serial: 11, LoadedAt: now.Add(-time.Hour)},
+		},
+	}
+	syncs := zone.GetSyncStates(map[int64]time.Time{1: now, 2: now})
+	require.Len(t, syncs, 2)
+	require.EqualValues(t, 2, syncs[0].Reference.ID)
+	require.Equal(t, ZoneSyncStateLagging, syncs[0].State)
+	require.Equal(t, time.Hour, syncs[0].LagDuration)
+	require.Equal(t, ZoneSyncStateInSync, syncs[1].State)
+	require.Equal(t, ZoneSyncStateLagging, GetWorstZoneSyncState(syncs))
+}
+
+// Test that the lag is measured only against the zones fetched after the
+// newer serial was loaded. The stale zones don't extend the lag.
+func TestZoneGetSyncStatesStaleFetch(t *testing.T) {
+	loadedAt := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	zone := &Zone{
+		Name: "example.com",
+		LocalZones: []*LocalZone{
+			{ID: 1, DaemonID: 1, View: "_default", Class: "IN", Type: "primary", Serial: 12, LoadedAt: loadedAt},
+			{ID: 2, DaemonID: 2, View: "_default", Class: "IN", Type: "secondary", Serial: 10, LoadedAt: loadedAt.Add(-time.Hour)},
+			{ID: 3, DaemonID: 3, View: "_default", Class: "IN", Type: "secondary", Serial: 13, LoadedAt: loadedAt},
+		},
+	}
+
+	t.Run("fetched before the serial change", func(t *testing.T) {
+		syncs := zone.GetSyncStates(map[int64]time.Time{
+			1: loadedAt.Add(-time.Minute),
+			2: loadedAt.Add(-time.Minute),
+			3: loadedAt.Add(time.Hour),
+		})
+		require.Len(t, syncs, 3)
+		require.Equal(t, ZoneSyncStateLagging, syncs[1].State)
+		require.Zero(t, syncs[1].LagDuration)
+		require.Equal(t, ZoneSyncStateInconsistent, syncs[2].State)
+		require.Zero(t, syncs[2].LagDuration)
+	})
+
+	t.Run("fetched after the serial change", func(t *testing.T) {
+		syncs := zone.GetSyncStates(map[int64]time.Time{
+			1: loadedAt.Add(30 * time.Minute),
+			2: loadedAt.Add(2 * time.Hour),
+		})
+		require.Len(t, syncs, 3)
+		require.Equal(t, 2*time.Hour, syncs[1].LagDuration)
+		require.Equal(t, 30*time.Minute, syncs[2].LagDuration)
+	})
+
+	t.Run("never fetched", func(t *testing.T) {
+		syncs := zone.GetSyncStates(map[int64]time.Time{})
+		require.Len(t, syncs, 3)
+		require.Zero(t, syncs[1].LagDuration)
+		require.Zero(t, syncs[2].LagDuration)
+	})
+}
+
+// Test that no sync states are returned for the zone served by a single
+// server.
+func TestZoneGetSyncStatesSingleServer(t *testing.T) {
+	zone := &Zone{
+		Name: "example.com",
+		LocalZones: []*LocalZone{
+			{ID: 1, View: "_default", Class: "IN", Type: "primary", Serial: 10},
+			{ID: 2, View: "_default", Class: "IN", Type: "builtin", Serial: 10},
+		},
+	}
+	syncs := zone.GetSyncStates(map[int64]time.Time{})
+	require.Empty(t, syncs)
+	require.Empty(t, GetWorstZoneSyncState(syncs))
+}
+
+// Test selecting the zones served by multiple servers in the same view.
+func TestGetZonesForSyncCheck(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	var daemons []*Daemon
+	for i := 0; i < 2; i++ {
+		machine := &Machine{
+			Address:   "localhost",
+			AgentPort: int64(8080 + i),
+		}
+		err := AddMachine(db, machine)
+		require.NoError(t, err)
+		daemon := NewDaemon(machine, daemonname.Bind9, true, []*AccessPoint{})
+		err = AddDaemon(db, daemon)
+		require.NoError(t, err)
+		daemons = append(daemons, daemon)
+	}
+	zones := []*Zone{
+		{
+			Name: "example.com",
+			LocalZones: []*LocalZone{
+				{DaemonID: daemons[0].ID, View: "_default", Class: "IN", Type: "primary", Serial: 2, LoadedAt: time.Now().UTC()},
+				{DaemonID: daemons[1].ID, View: "_default", Class: "IN", Type: "secondary", Serial: 1, LoadedAt: time.Now().UTC()},
+			},
+		},
+		{
+			// Different views.
+			Name: "example.org",
+			LocalZones: []*LocalZone{
+				{DaemonID: daemons[0].ID, View: "trusted", Class: "IN", Type: "primary", Serial: 2, LoadedAt: time.Now().UTC()},
+				{DaemonID: daemons[1].ID, View: "guest", Class: "IN", Type: "secondary", Serial: 1, LoadedAt: time.Now().UTC()},
+			},
+		},
+		{
+			// Builtin zones are not compared.
+			Name: "localhost",
+			LocalZones: []*LocalZone{
+				{DaemonID: daemons[0].ID, View: "_default", Class: "IN", Type: "builtin", LoadedAt: time.Now().UTC()},
+				{DaemonID: daemons[1].ID, View: "_default", Class: "IN", Type: "builtin", LoadedAt: time.Now().UTC()},
+			},
+		},
+	}
+	err := AddZones(db, zones...)
+	require.NoError(t, err)
+
+	returned, err := GetZonesForSyncCheck(db, ZoneRelationLocalZonesDaemon, ZoneRelationLocalZonesMachine)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, "example.com", returned[0].Name)
+	require.Len(t, returned[0].LocalZones, 2)
+	require.NotNil(t, returned[0].LocalZones[0].Daemon)
+	require.NotNil(t, returned[0].LocalZones[0].Daemon.Machine)
+}
@@ -19,6 +19,7 @@ import (
 	dnsmodel "isc.org/stork/datamodel/dns"
 	agentcomm "isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
 	storkutil "isc.org/stork/util"
 )
 
@@ -82,6 +83,9 @@ type ManagerAccessors interface {
 	GetDB() *pg.DB
 	// Returns an interface to the agents the manager communicates with.
 	GetConnectedAgents() agentcomm.ConnectedAgents
+	// Returns an interface to the event center recording the changes
+	// made by the manager.
+	GetEventCenter() eventcenter.EventCenter
 }
 
 // An interface to the DNS Manager used from external packages. Exposing
@@ -116,6 +120,19 @@ type Manager interface {
 	// files are returned. Otherwise, only the configuration files explicitly enabled
 	// in the file selector are returned.
 	GetBind9FormattedConfig(ctx context.Context, daemonID int64, fileSelector *bind9config.FileTypeSelector, filter *bind9config.Filter) iter.Seq[*Bind9FormattedConfigResponse]
+	// Sends the dynamic update (RFC 2136) with the RRs changes for the specified
+	// zone, daemon and view name. The update is signed with the TSIG key found in
+	// the BIND 9 configuration. After successful update, the cached RRs are
+	// invalidated and the event is recorded for the user.
+	UpdateZoneRRs(ctx context.Context, zoneID int64, daemonID int64, viewName string, update *ZoneRRsUpdate, user *dbmodel.SystemUser) error
+	// Adds the zone to the BIND 9 daemons using rndc addzone. The zone must be
+	// a primary or secondary zone, and the allow-new-zones option must be enabled.
+	// The zone inventory is refreshed after the zone is added.
+	AddZone(ctx context.Context, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error)
+	// Modifies the zone on the BIND 9 daemons using rndc modzone.
+	ModifyZone(ctx context.Context, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error)
+	// Deletes the zone from the BIND 9 daemons using rndc delzone.
+	DeleteZone(ctx context.Context, daemonIDs []int64, viewName string, zoneName string, class string, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error)
 	// Starts tracking zone transfers.
 	StartXFRTracking() error
 	// Starts tracking zone transfers for a selected BIND 9 daemon.
@@ -415,6 +432,8 @@ type managerImpl struct {
 	db *pg.DB
 	// Interface to the connected agents.
 	agents agentcomm.ConnectedAgents
+	// Interface to the event center.
+	eventCenter eventcenter.EventCenter
 	// A state of fetching zones from the DNS servers by the manager.
 	fetchingState *fetchingState
 	// A state of RRs requests.
@@ -448,6 +467,7 @@ func NewManager(owner ManagerAccessors) (Manager, error) {
 	impl := &managerImpl{
 		db:            owner.GetDB(),
 		agents:        owner.GetConnectedAgents(),
+		eventCenter:   owner.GetEventCenter(),
 		fetchingState: &fetchingState{},
 		rrsReqsState: &rrsRequestingState{
 			requestChan: make(chan *rrsRequest),
@@ -475,6 +495,11 @@ func (manager *managerImpl) GetConnectedAgents() agentcomm.ConnectedAgents {
 	return manager.agents
 }
 
+// Returns the event center (implements the ManagerAccessors interface).
+func (manager *managerImpl) GetEventCenter() eventcenter.EventCenter {
+	return manager.eventCenter
+}
+
 // Contacts all agents with DNS servers and fetches zones from these servers.
 // It implements the Manager interface.
 func (manager *managerImpl) FetchZones(poolSize, batchSize int, options ...FetchZonesOption) (chan ManagerDoneNotify, error) {
@@ -0,0 +1,180 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"isc.org/stork/datamodel/daemonname"
+	dnsmodel "isc.org/stork/datamodel/dns"
+	"isc.org/stork/server/daemons/bind9"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Timeout for sending the dynamic update and receiving the response.
+const rrsUpdateTimeout = 10 * time.Second
+
+// A set of changes to the zone RRs sent to the DNS server in a single
+// dynamic update (RFC 2136). The server applies the changes atomically.
+// The RRs are deleted before the new RRs are added, so modifying an RR
+// is realized by deleting the old RR and adding the new one.
+type ZoneRRsUpdate struct {
+	// The RRs to be deleted. They must match the existing RRs exactly,
+	// except for the TTL which is ignored by the server.
+	Delete []*dnsmodel.RR
+	// The RRs to be added.
+	Add []*dnsmodel.RR
+}
+
+// Returns the text describing the changes. It is used in the events.
+func (update *ZoneRRsUpdate) getDescription() string {
+	var lines []string
+	for _, rr := range update.Delete {
+		lines = append(lines, "deleted: "+rr.GetString())
+	}
+	for _, rr := range update.Add {
+		lines = append(lines, "added: "+rr.GetString())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Converts the RRs to the format used by the DNS library. It returns an
+// error if any of the RRs does not belong to the zone.
+func convertRRsToDNS(zoneName string, rrs []*dnsmodel.RR) ([]dns.RR, error) {
+	converted := make([]dns.RR, 0, len(rrs))
+	for _, rr := range rrs {
+		dnsRR, err := dns.NewRR(rr.GetString())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse RR: %s", rr.GetString())
+		}
+		if dnsRR == nil {
+			return nil, errors.Errorf("failed to parse empty RR: %s", rr.GetString())
+		}
+		if !dns.IsSubDomain(zoneName, dnsRR.Header().Name) {
+			return nil, errors.Errorf("RR %s does not belong to zone %s", rr.GetString(), zoneName)
+		}
+		converted = append(converted, dnsRR)
+	}
+	return converted, nil
+}
+
+// Converts the TSIG algorithm name used in the BIND 9 configuration to the
+// name used in the TSIG RR.
+func getTSIGAlgorithm(algorithm string) string {
+	if strings.EqualFold(algorithm, "hmac-md5") {
+		return dns.HmacMD5
+	}
+	return dns.Fqdn(strings.ToLower(algorithm))
+}
+
+// Creates the dynamic update message for the zone and signs it with the
+// TSIG key.
+func newRRsUpdateMsg(zoneName string, update *ZoneRRsUpdate, keyName, algorithm string) (*dns.Msg, error) {
+	deleted, err := convertRRsToDNS(zoneName, update.Delete)
+	if err != nil {
+		return nil, err
+	}
+	added, err := convertRRsToDNS(zoneName, update.Add)
+	if err != nil {
+		return nil, err
+	}
+	msg := new(dns.Msg)
+	msg.SetUpdate(zoneName)
+	if len(deleted) > 0 {
+		msg.Remove(deleted)
+	}
+	if len(added) > 0 {
+		msg.Insert(added)
+	}
+	msg.SetTsig(dns.Fqdn(keyName), getTSIGAlgorithm(algorithm), 300, time.Now().Unix())
+	return msg, nil
+}
+
+// Sends the dynamic update (RFC 2136) with the RRs changes for the zone to
+// the primary BIND 9 server. The update is signed with the TSIG key found
+// in the server configuration (see bind9config.Config.GetUpdateCredentials).
+// The server configuration is fetched from the agent. The update is sent
+// to the address of the machine where the server runs. After successful
+// update, the cached RRs are invalidated, so they are fetched with the next
+// zone transfer, and the event describing the change is recorded. The user
+// is the one who requested the update. The event is not recorded if the
+// user is nil.
+func (manager *managerImpl) UpdateZoneRRs(ctx context.Context, zoneID int64, daemonID int64, viewName string, update *ZoneRRsUpdate, user *dbmodel.SystemUser) error {
+	if update == nil || len(update.Delete) == 0 && len(update.Add) == 0 {
+		return errors.New("no RRs to update specified")
+	}
+	daemon, err := dbmodel.GetDNSDaemonByID(manager.db, daemonID)
+	if err != nil {
+		return err
+	}
+	if daemon == nil {
+		return errors.Errorf("daemon with the ID of %d not found", daemonID)
+	}
+	if daemon.Name != daemonname.Bind9 {
+		return errors.Errorf("dynamic updates are not supported for daemon %s", daemon.Name)
+	}
+	zone, err := dbmodel.GetZoneByID(manager.db, zoneID, dbmodel.ZoneRelationLocalZones)
+	if err != nil {
+		return err
+	}
+	if zone == nil {
+		return errors.Errorf("zone with the ID of %d not found", zoneID)
+	}
+	localZone := zone.GetLocalZone(daemonID, viewName)
+	if localZone == nil {
+		return errors.Errorf("local zone information for daemon ID %d and view %s not found in zone: %s", daemonID, viewName, zone.Name)
+	}
+	if localZone.Type != string(dbmodel.ZoneTypePrimary) && localZone.Type != string(dbmodel.ZoneTypeMaster) {
+		return errors.Errorf("zone %s in view %s is not a primary zone", zone.Name, viewName)
+	}
+
+	// The configuration is not stored in the database, so it must be
+	// fetched from the agent to discover the key.
+	if err = bind9.GetDaemonConfig(ctx, manager.agents, daemon); err != nil {
+		return err
+	}
+	if daemon.Bind9Daemon.Config == nil {
+		return errors.Errorf("no configuration returned for daemon %d", daemonID)
+	}
+	port, keyName, algorithm, secret, err := daemon.Bind9Daemon.Config.GetUpdateCredentials(viewName, zone.Name)
+	if err != nil {
+		return err
+	}
+
+	msg, err := newRRsUpdateMsg(dns.Fqdn(zone.Name), update, keyName, algorithm)
+	if err != nil {
+		return err
+	}
+	client := &dns.Client{
+		Net:     "tcp",
+		Timeout: rrsUpdateTimeout,
+		TsigSecret: map[string]string{
+			dns.Fqdn(keyName): secret,
+		},
+	}
+	address := net.JoinHostPort(daemon.Machine.Address, strconv.FormatInt(port, 10))
+	response, _, err := client.ExchangeContext(ctx, msg, address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send dynamic update for zone %s to %s", zone.Name, address)
+	}
+	if response.Rcode != dns.RcodeSuccess {
+		return errors.Errorf("dynamic update for zone %s rejected by %s: %s", zone.Name, address, dns.RcodeToString[response.Rcode])
+	}
+
+	if manager.eventCenter != nil && user != nil {
+		manager.eventCenter.AddInfoEvent(
+			fmt.Sprintf("{user} updated RRs in zone %s in view %s on {daemon}", zone.Name, viewName),
+			user, daemon, update.getDescription(),
+		)
+	}
+
+	if err = dbmodel.InvalidateLocalZoneRRs(manager.db, localZone.ID); err != nil {
+		return errors.WithMessagef(err, "failed to invalidate cached RRs for zone %s after the update", zone.Name)
+	}
+	return nil
+}
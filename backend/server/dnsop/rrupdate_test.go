@@ -0,0 +1,348 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	iter "iter"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	agentapi "isc.org/stork/api"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	"isc.org/stork/datamodel/daemonname"
+	dnsmodel "isc.org/stork/datamodel/dns"
+	appstest "isc.org/stork/server/daemons/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// TSIG key used in the dynamic update tests.
+const (
+	testUpdateKeyName   = "ddns-key"
+	testUpdateKeySecret = "VO6xA4Tc1PWYaqMuPaf6wfkITb+c9/mkzlEaWJavejU="
+)
+
+// Parses the RRs used in the tests.
+func newTestRRs(t *testing.T, rrs ...string) (parsed []*dnsmodel.RR) {
+	for _, rr := range rrs {
+		parsedRR, err := dnsmodel.NewRR(rr)
+		require.NoError(t, err)
+		parsed = append(parsed, parsedRR)
+	}
+	return
+}
+
+// Test that the description of the update lists the deleted and added RRs.
+func TestZoneRRsUpdateGetDescription(t *testing.T) {
+	update := &ZoneRRsUpdate{
+		Delete: newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.1"),
+		Add:    newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.2", "www.example.com. 3600 IN AAAA 2001:db8::2"),
+	}
+	require.Equal(t, "deleted: www.example.com. 3600 IN A 192.0.2.1\n"+
+		"added: www.example.com. 3600 IN A 192.0.2.2\n"+
+		"added: www.example.com. 3600 IN AAAA 2001:db8::2", update.getDescription())
+}
+
+// Test converting the TSIG algorithm names.
+func TestGetTSIGAlgorithm(t *testing.T) {
+	require.Equal(t, dns.HmacSHA256, getTSIGAlgorithm("hmac-sha256"))
+	require.Equal(t, dns.HmacSHA512, getTSIGAlgorithm("HMAC-SHA512"))
+	require.Equal(t, dns.HmacMD5, getTSIGAlgorithm("hmac-md5"))
+}
+
+// Test creating the dynamic update message.
+func TestNewRRsUpdateMsg(t *testing.T) {
+	update := &ZoneRRsUpdate{
+		Delete: newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.1"),
+		Add:    newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.2"),
+	}
+	msg, err := newRRsUpdateMsg("example.com.", update, testUpdateKeyName, "hmac-sha256")
+	require.NoError(t, err)
+	require.NotNil(t, msg)
+
+	require.Equal(t, dns.OpcodeUpdate, msg.Opcode)
+	require.Len(t, msg.Question, 1)
+	require.Equal(t, "example.com.", msg.Question[0].Name)
+	require.Equal(t, dns.TypeSOA, msg.Question[0].Qtype)
+
+	require.Len(t, msg.Ns, 2)
+	require.EqualValues(t, dns.ClassNONE, msg.Ns[0].Header().Class)
+	require.Equal(t, "192.0.2.1", msg.Ns[0].(*dns.A).A.String())
+	require.EqualValues(t, dns.ClassINET, msg.Ns[1].Header().Class)
+	require.Equal(t, "192.0.2.2", msg.Ns[1].(*dns.A).A.String())
+
+	tsig := msg.IsTsig()
+	require.NotNil(t, tsig)
+	require.Equal(t, "ddns-key.", tsig.Hdr.Name)
+	require.Equal(t, dns.HmacSHA256, tsig.Algorithm)
+}
+
+// Test that creating the dynamic update message fails when the RR does
+// not belong to the zone.
+func TestNewRRsUpdateMsgOutOfZone(t *testing.T) {
+	update := &ZoneRRsUpdate{
+		Add: newTestRRs(t, "www.example.org. 3600 IN A 192.0.2.2"),
+	}
+	msg, err := newRRsUpdateMsg("example.com.", update, testUpdateKeyName, "hmac-sha256")
+	require.ErrorContains(t, err, "does not belong to zone example.com.")
+	require.Nil(t, msg)
+}
+
+// Starts the DNS server accepting the dynamic updates signed with the test
+// key. It returns the port the server listens on and the channel receiving
+// the accepted updates.
+func startTestUpdateServer(t *testing.T, rcode int) (int, chan *dns.Msg) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	updates := make(chan *dns.Msg, 1)
+	server := &dns.Server{
+		Listener:   listener,
+		TsigSecret: map[string]string{testUpdateKeyName + ".": testUpdateKeySecret},
+		// The default function rejects the dynamic updates.
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			if int(dh.Bits>>11)&0xF == dns.OpcodeUpdate {
+				return dns.MsgAccept
+			}
+			return dns.DefaultMsgAcceptFunc(dh)
+		},
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			response := new(dns.Msg)
+			response.SetRcode(req, rcode)
+			if w.TsigStatus() != nil {
+				response.SetRcode(req, dns.RcodeNotAuth)
+			} else {
+				updates <- req
+			}
+			response.SetTsig(testUpdateKeyName+".", dns.HmacSHA256, 300, time.Now().Unix())
+			_ = w.WriteMsg(response)
+		}),
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	server.NotifyStartedFunc = wg.Done
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	wg.Wait()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+	return listener.Addr().(*net.TCPAddr).Port, updates
+}
+
+// Configures the mock to return the specified BIND 9 configuration.
+func expectTestBind9Config(mock *MockConnectedAgents, config string) *gomock.Call {
+	return mock.EXPECT().ReceiveBind9FormattedConfig(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, daemon *dbmodel.Daemon, fileSelector *bind9config.FileTypeSelector, filter *bind9config.Filter) iter.Seq2[*agentapi.ReceiveBind9ConfigRsp, error] {
+			return func(yield func(*agentapi.ReceiveBind9ConfigRsp, error) bool) {
+				responses := []*agentapi.ReceiveBind9ConfigRsp{
+					{
+						Response: &agentapi.ReceiveBind9ConfigRsp_File{
+							File: &agentapi.ReceiveBind9ConfigFile{
+								FileType:   agentapi.Bind9ConfigFileType_CONFIG,
+								SourcePath: "/etc/bind/named.conf",
+							},
+						},
+					},
+					{
+						Response: &agentapi.ReceiveBind9ConfigRsp_Line{
+							Line: config,
+						},
+					},
+				}
+				for _, response := range responses {
+					if !yield(response, nil) {
+						return
+					}
+				}
+			}
+		})
+}
+
+// Configures the mock to return the BIND 9 configuration allowing the
+// dynamic updates of the example.com zone with the test key.
+func expectTestUpdateConfig(mock *MockConnectedAgents, port int) {
+	expectTestBind9Config(mock, fmt.Sprintf(`
+		options {
+			listen-on port %d { 127.0.0.1; };
+		};
+		key "%s" {
+			algorithm hmac-sha256;
+			secret "%s";
+		};
+		zone "example.com" {
+			type primary;
+			allow-update { key %s; };
+		};
+	`, port, testUpdateKeyName, testUpdateKeySecret, testUpdateKeyName))
+}
+
+// Adds the BIND 9 daemon with the primary example.com zone with the cached
+// RRs to the database.
+func addTestUpdateZone(t *testing.T, db pg.DBI) (*dbmodel.Daemon, *dbmodel.Zone) {
+	machine := &dbmodel.Machine{
+		Address:   "127.0.0.1",
+		AgentPort: int64(8080),
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	zone := &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{
+			{
+				DaemonID: daemon.ID,
+				View:     "_default",
+				Class:    "IN",
+				Serial:   123456,
+				Type:     "primary",
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	}
+	err = dbmodel.AddZones(db, zone)
+	require.NoError(t, err)
+
+	err = dbmodel.AddLocalZoneRRs(db, &dbmodel.LocalZoneRR{
+		RR:          *newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.1")[0],
+		LocalZoneID: zone.LocalZones[0].ID,
+	})
+	require.NoError(t, err)
+	err = dbmodel.UpdateLocalZoneRRsTransferAt(db, zone.LocalZones[0].ID)
+	require.NoError(t, err)
+	return daemon, zone
+}
+
+// Test that the dynamic update is sent to the server, the cached RRs are
+// invalidated and the event is recorded.
+func TestUpdateZoneRRs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, zone := addTestUpdateZone(t, db)
+	port, updates := startTestUpdateServer(t, dns.RcodeSuccess)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+	expectTestUpdateConfig(mock, port)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	user := &dbmodel.SystemUser{ID: 1, Login: "admin"}
+	err = manager.UpdateZoneRRs(context.Background(), zone.ID, daemon.ID, "_default", &ZoneRRsUpdate{
+		Delete: newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.1"),
+		Add:    newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.2"),
+	}, user)
+	require.NoError(t, err)
+
+	// The server should have received the update.
+	require.Len(t, updates, 1)
+	update := <-updates
+	require.Equal(t, "example.com.", update.Question[0].Name)
+	require.Len(t, update.Ns, 2)
+
+	// The cached RRs should have been invalidated.
+	rrs, total, err := dbmodel.GetDNSConfigRRs(db, zone.LocalZones[0].ID, nil)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, rrs)
+	returnedZone, err := dbmodel.GetZoneByID(db, zone.ID, dbmodel.ZoneRelationLocalZones)
+	require.NoError(t, err)
+	require.Nil(t, returnedZone.LocalZones[0].ZoneTransferAt)
+
+	// The event should have been recorded.
+	require.Len(t, eventCenter.Events, 1)
+	event := eventCenter.Events[0]
+	require.Contains(t, event.Text, "updated RRs in zone example.com in view _default")
+	require.Contains(t, event.Details, "added: www.example.com. 3600 IN A 192.0.2.2")
+	require.EqualValues(t, user.ID, event.Relations.UserID)
+	require.EqualValues(t, daemon.ID, event.Relations.DaemonID)
+}
+
+// Test that the cached RRs are not invalidated and no event is recorded
+// when the server rejects the update.
+func TestUpdateZoneRRsRejected(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, zone := addTestUpdateZone(t, db)
+	port, _ := startTestUpdateServer(t, dns.RcodeRefused)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+	expectTestUpdateConfig(mock, port)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	err = manager.UpdateZoneRRs(context.Background(), zone.ID, daemon.ID, "_default", &ZoneRRsUpdate{
+		Add: newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.2"),
+	}, &dbmodel.SystemUser{ID: 1})
+	require.ErrorContains(t, err, "rejected")
+	require.ErrorContains(t, err, "REFUSED")
+
+	_, total, err := dbmodel.GetDNSConfigRRs(db, zone.LocalZones[0].ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test that the update is not sent when the zone or daemon is invalid.
+func TestUpdateZoneRRsInvalidZone(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon, zone := addTestUpdateZone(t, db)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: mock,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	update := &ZoneRRsUpdate{
+		Add: newTestRRs(t, "www.example.com. 3600 IN A 192.0.2.2"),
+	}
+	err = manager.UpdateZoneRRs(context.Background(), zone.ID, daemon.ID, "_default", &ZoneRRsUpdate{}, nil)
+	require.ErrorContains(t, err, "no RRs to update specified")
+
+	err = manager.UpdateZoneRRs(context.Background(), zone.ID, daemon.ID+1, "_default", update, nil)
+	require.ErrorContains(t, err, "not found")
+
+	err = manager.UpdateZoneRRs(context.Background(), zone.ID+1, daemon.ID, "_default", update, nil)
+	require.ErrorContains(t, err, "zone with the ID")
+
+	err = manager.UpdateZoneRRs(context.Background(), zone.ID, daemon.ID, "trusted", update, nil)
+	require.ErrorContains(t, err, "local zone information")
+}
@@ -0,0 +1,207 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/daemons/bind9"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Timeout for the rndc commands adding, modifying and deleting the zones.
+const zoneProvisioningTimeout = 10 * time.Second
+
+// The number of the goroutines and the batch size used when refreshing the
+// zone inventory after provisioning the zones.
+const (
+	zoneInventoryRefreshPoolSize  = 10
+	zoneInventoryRefreshBatchSize = 1000
+)
+
+// The rndc command used to provision the zone.
+type zoneProvisioningCommand string
+
+const (
+	zoneProvisioningCommandAdd    zoneProvisioningCommand = "addzone"
+	zoneProvisioningCommandModify zoneProvisioningCommand = "modzone"
+	zoneProvisioningCommandDelete zoneProvisioningCommand = "delzone"
+)
+
+// Returns the event text template for the command.
+func (command zoneProvisioningCommand) getEventText() string {
+	switch command {
+	case zoneProvisioningCommandAdd:
+		return "{user} added zone %s to view %s on {daemon}"
+	case zoneProvisioningCommandModify:
+		return "{user} modified zone %s in view %s on {daemon}"
+	default:
+		return "{user} deleted zone %s from view %s on {daemon}"
+	}
+}
+
+// The result of provisioning the zone on a single daemon. The error is nil
+// if the zone was successfully provisioned.
+type ZoneProvisioningResult struct {
+	DaemonID int64
+	Err      error
+}
+
+// Adds the zone to the specified BIND 9 daemons using the rndc addzone
+// command. The zone is validated before it is sent to the daemons. It must
+// be a primary or secondary zone. The allow-new-zones option must be enabled
+// in the view on each daemon. The function returns an error if the zone is
+// invalid. Otherwise, it returns the results for the respective daemons.
+// The zone inventory is refreshed in background if the zone was added to
+// any of the daemons.
+func (manager *managerImpl) AddZone(ctx context.Context, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error) {
+	return manager.addOrModifyZone(ctx, zoneProvisioningCommandAdd, daemonIDs, viewName, zone, user)
+}
+
+// Modifies the zone on the specified BIND 9 daemons using the rndc modzone
+// command. The new zone configuration replaces the existing one. See AddZone
+// for the details.
+func (manager *managerImpl) ModifyZone(ctx context.Context, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error) {
+	return manager.addOrModifyZone(ctx, zoneProvisioningCommandModify, daemonIDs, viewName, zone, user)
+}
+
+// Deletes the zone from the specified BIND 9 daemons using the rndc delzone
+// command. The zone files are left intact. See AddZone for the details.
+func (manager *managerImpl) DeleteZone(ctx context.Context, daemonIDs []int64, viewName string, zoneName string, class string, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error) {
+	return manager.provisionZone(ctx, zoneProvisioningCommandDelete, daemonIDs, viewName, zoneName, class, "", user)
+}
+
+// Validates the zone and runs the addzone or modzone command.
+func (manager *managerImpl) addOrModifyZone(ctx context.Context, command zoneProvisioningCommand, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error) {
+	if zone == nil {
+		return nil, errors.New("no zone specified")
+	}
+	if err := validateZoneProvisioningParams(viewName, zone.Name, zone.Class); err != nil {
+		return nil, err
+	}
+	if err := zone.ValidateForProvisioning(); err != nil {
+		return nil, err
+	}
+	clauses, err := zone.GetFormattedClauses()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to serialize configuration of zone %s", zone.Name)
+	}
+	return manager.provisionZone(ctx, command, daemonIDs, viewName, zone.Name, zone.Class, clauses, user)
+}
+
+// Validates the view name, zone name and class passed to the rndc command.
+// The command is split into arguments on whitespace by the agent, so the
+// names must not contain whitespace or control characters. The empty view
+// name and class denote the defaults.
+func validateZoneProvisioningParams(viewName, zoneName, class string) error {
+	if _, ok := dns.IsDomainName(zoneName); !ok || !bind9.IsValidRndcArgument(zoneName) {
+		return errors.Errorf("invalid zone name %q", zoneName)
+	}
+	switch strings.ToUpper(class) {
+	case "", "IN", "CH", "HS":
+	default:
+		return errors.Errorf("invalid zone class %q", class)
+	}
+	if viewName != "" && !bind9.IsValidRndcArgument(viewName) {
+		return errors.Errorf("invalid view name %q", viewName)
+	}
+	return nil
+}
+
+// Runs the rndc command provisioning the zone on the daemons and refreshes
+// the zone inventory if the command succeeded for any of them.
+func (manager *managerImpl) provisionZone(ctx context.Context, command zoneProvisioningCommand, daemonIDs []int64, viewName, zoneName, class, clauses string, user *dbmodel.SystemUser) ([]*ZoneProvisioningResult, error) {
+	if err := validateZoneProvisioningParams(viewName, zoneName, class); err != nil {
+		return nil, err
+	}
+	if len(daemonIDs) == 0 {
+		return nil, errors.New("no daemons specified")
+	}
+	if viewName == "" {
+		viewName = bind9config.DefaultViewName
+	}
+	class = strings.ToUpper(class)
+	if class == "" {
+		class = "IN"
+	}
+	var (
+		results   []*ZoneProvisioningResult
+		succeeded bool
+	)
+	for _, daemonID := range daemonIDs {
+		err := manager.provisionZoneOnDaemon(ctx, command, daemonID, viewName, zoneName, class, clauses, user)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"daemonID": daemonID,
+				"view":     viewName,
+				"zone":     zoneName,
+			}).WithError(err).Errorf("Failed to run rndc %s", command)
+		} else {
+			succeeded = true
+		}
+		results = append(results, &ZoneProvisioningResult{
+			DaemonID: daemonID,
+			Err:      err,
+		})
+	}
+	if succeeded {
+		// The zone inventories must be populated again to include the
+		// provisioned zones. It is a background task.
+		if _, err := manager.FetchZones(zoneInventoryRefreshPoolSize, zoneInventoryRefreshBatchSize, FetchZonesOptionForcePopulate); err != nil {
+			log.WithError(err).Warn("Failed to refresh the zones after provisioning")
+		}
+	}
+	return results, nil
+}
+
+// Runs the rndc command provisioning the zone on a single daemon. The
+// daemon's configuration is fetched from the agent to check if the
+// allow-new-zones option is enabled.
+func (manager *managerImpl) provisionZoneOnDaemon(ctx context.Context, command zoneProvisioningCommand, daemonID int64, viewName, zoneName, class, clauses string, user *dbmodel.SystemUser) error {
+	daemon, err := dbmodel.GetDNSDaemonByID(manager.db, daemonID)
+	if err != nil {
+		return err
+	}
+	if daemon == nil {
+		return errors.Errorf("daemon with the ID of %d not found", daemonID)
+	}
+	if daemon.Name != daemonname.Bind9 {
+		return errors.Errorf("provisioning zones is not supported for daemon %s", daemon.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, zoneProvisioningTimeout)
+	defer cancel()
+
+	if err = bind9.GetDaemonConfig(ctx, manager.agents, daemon); err != nil {
+		return err
+	}
+	if daemon.Bind9Daemon.Config == nil {
+		return errors.Errorf("no configuration returned for daemon %d", daemonID)
+	}
+	allowed, err := daemon.Bind9Daemon.Config.IsAllowNewZones(viewName)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.Errorf("allow-new-zones is not enabled for view %s", viewName)
+	}
+
+	rndcCommand := fmt.Sprintf("%s %s %s %s", command, zoneName, class, viewName)
+	if clauses != "" {
+		rndcCommand += " " + clauses
+	}
+	if _, err = manager.agents.ForwardRndcCommand(ctx, daemon, rndcCommand); err != nil {
+		return errors.WithMessagef(err, "rndc %s failed for zone %s", command, zoneName)
+	}
+
+	if manager.eventCenter != nil && user != nil {
+		manager.eventCenter.AddInfoEvent(fmt.Sprintf(command.getEventText(), zoneName, viewName), user, daemon, clauses)
+	}
+	return nil
+}
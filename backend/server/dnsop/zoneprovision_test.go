@@ -0,0 +1,268 @@
+package dnsop
+
+import (
+	"context"
+	"fmt"
+	iter "iter"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	"isc.org/stork/datamodel/daemonname"
+	dnsmodel "isc.org/stork/datamodel/dns"
+	"isc.org/stork/server/agentcomm"
+	appstest "isc.org/stork/server/daemons/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// BIND 9 configuration allowing new zones in the trusted view.
+const testProvisioningConfig = `
+	view "trusted" {
+		match-clients { 127.0.0.1; };
+		allow-new-zones yes;
+	};
+	view "guest" {
+		match-clients { any; };
+	};
+`
+
+// Parses the zone used in the tests.
+func newTestProvisioningZone(t *testing.T, zoneText string) *bind9config.Zone {
+	zone, err := bind9config.NewParser().ParseZone(zoneText)
+	require.NoError(t, err)
+	return zone
+}
+
+// Adds the BIND 9 daemon to the database.
+func addTestProvisioningDaemon(t *testing.T, db pg.DBI) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   "127.0.0.1",
+		AgentPort: int64(8080),
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+	return daemon
+}
+
+// Configures the mock to return no zones when the zone inventory is
+// refreshed after provisioning.
+func expectTestZoneInventoryRefresh(mock *MockConnectedAgents) {
+	mock.EXPECT().ReceiveZones(gomock.Any(), gomock.Any(), gomock.Any(), true).AnyTimes().
+		DoAndReturn(func(ctx context.Context, daemon agentcomm.ControlledDaemon, filter *dnsmodel.ZoneFilter, forcePopulate bool) iter.Seq2[*dnsmodel.ExtendedZone, error] {
+			return func(yield func(*dnsmodel.ExtendedZone, error) bool) {}
+		})
+}
+
+// Waits until the zone inventory refresh completes.
+func waitForZoneInventoryRefresh(t *testing.T, manager Manager) {
+	require.Eventually(t, func() bool {
+		isFetching, _, _ := manager.GetFetchZonesProgress()
+		return !isFetching
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// Test the event text templates for the provisioning commands.
+func TestZoneProvisioningCommandGetEventText(t *testing.T) {
+	require.Equal(t, "{user} added zone %s to view %s on {daemon}", zoneProvisioningCommandAdd.getEventText())
+	require.Equal(t, "{user} modified zone %s in view %s on {daemon}", zoneProvisioningCommandModify.getEventText())
+	require.Equal(t, "{user} deleted zone %s from view %s on {daemon}", zoneProvisioningCommandDelete.getEventText())
+}
+
+// Test that the invalid zones are rejected before contacting the daemons.
+func TestAddZoneInvalid(t *testing.T) {
+	manager := &managerImpl{}
+
+	_, err := manager.AddZone(context.Background(), []int64{1}, "trusted", nil, nil)
+	require.ErrorContains(t, err, "no zone specified")
+
+	_, err = manager.AddZone(context.Background(), []int64{1}, "trusted", newTestProvisioningZone(t, `zone "example..com" { type primary; file "db.example.com"; };`), nil)
+	require.ErrorContains(t, err, `invalid zone name "example..com"`)
+
+	_, err = manager.AddZone(context.Background(), []int64{1}, "trusted", newTestProvisioningZone(t, `zone "example.com" { type primary; };`), nil)
+	require.ErrorContains(t, err, "must specify the file")
+
+	_, err = manager.ModifyZone(context.Background(), nil, "trusted", newTestProvisioningZone(t, `zone "example.com" { type primary; file "db.example.com"; };`), nil)
+	require.ErrorContains(t, err, "no daemons specified")
+
+	_, err = manager.DeleteZone(context.Background(), []int64{1}, "trusted", "example..com", "", nil)
+	require.ErrorContains(t, err, `invalid zone name "example..com"`)
+}
+
+// Test that the zone names, classes and view names that could inject extra
+// arguments into the rndc command are rejected before contacting the daemons.
+func TestProvisionZoneInvalidParams(t *testing.T) {
+	manager := &managerImpl{}
+
+	for _, zoneName := range []string{"example.com IN trusted", "example.com;", "example.com\tIN"} {
+		t.Run(zoneName, func(t *testing.T) {
+			zone := &bind9config.Zone{Name: zoneName}
+			_, err := manager.AddZone(context.Background(), []int64{1}, "trusted", zone, nil)
+			require.ErrorContains(t, err, fmt.Sprintf("invalid zone name %q", zoneName))
+
+			_, err = manager.DeleteZone(context.Background(), []int64{1}, "trusted", zoneName, "", nil)
+			require.ErrorContains(t, err, fmt.Sprintf("invalid zone name %q", zoneName))
+		})
+	}
+
+	_, err := manager.DeleteZone(context.Background(), []int64{1}, "trusted", "example.com", "IN trusted", nil)
+	require.ErrorContains(t, err, `invalid zone class "IN trusted"`)
+
+	_, err = manager.DeleteZone(context.Background(), []int64{1}, "trusted", "example.com", "XY", nil)
+	require.ErrorContains(t, err, `invalid zone class "XY"`)
+
+	_, err = manager.DeleteZone(context.Background(), []int64{1}, "trusted guest", "example.com", "", nil)
+	require.ErrorContains(t, err, `invalid view name "trusted guest"`)
+
+	_, err = manager.DeleteZone(context.Background(), nil, "trusted", "example.com", "ch", nil)
+	require.ErrorContains(t, err, "no daemons specified")
+}
+
+// Test that the zone is added with rndc addzone, the event is recorded and
+// the zone inventory is refreshed.
+func TestAddZone(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestProvisioningDaemon(t, db)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+	expectTestBind9Config(mock, testProvisioningConfig)
+	mock.EXPECT().ForwardRndcCommand(gomock.Any(), gomock.Any(), `addzone example.com IN trusted { type secondary; primaries { 192.0.2.1; }; };`).
+		Return(&agentcomm.RndcOutput{}, nil)
+	expectTestZoneInventoryRefresh(mock)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	user := &dbmodel.SystemUser{ID: 1, Login: "admin"}
+	results, err := manager.AddZone(context.Background(), []int64{daemon.ID}, "trusted", newTestProvisioningZone(t, `
+		zone "example.com" {
+			type secondary;
+			primaries { 192.0.2.1; };
+		};
+	`), user)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, daemon.ID, results[0].DaemonID)
+	require.NoError(t, results[0].Err)
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "added zone example.com to view trusted")
+	require.Contains(t, eventCenter.Events[0].Details, "primaries { 192.0.2.1; };")
+
+	waitForZoneInventoryRefresh(t, manager)
+}
+
+// Test that the zone is not added when the new zones are not allowed in
+// the view, and the zone inventory is not refreshed.
+func TestAddZoneNotAllowed(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestProvisioningDaemon(t, db)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+	expectTestBind9Config(mock, testProvisioningConfig)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	results, err := manager.AddZone(context.Background(), []int64{daemon.ID}, "guest", newTestProvisioningZone(t, `zone "example.com" { type primary; file "db.example.com"; };`), &dbmodel.SystemUser{ID: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.ErrorContains(t, results[0].Err, "allow-new-zones is not enabled for view guest")
+	require.Empty(t, eventCenter.Events)
+
+	isFetching, _, _ := manager.GetFetchZonesProgress()
+	require.False(t, isFetching)
+}
+
+// Test that the errors returned by rndc and for the non-existing daemons
+// are returned in the results for the respective daemons.
+func TestModifyZoneErrors(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestProvisioningDaemon(t, db)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+	expectTestBind9Config(mock, testProvisioningConfig)
+	mock.EXPECT().ForwardRndcCommand(gomock.Any(), gomock.Any(), `modzone example.com IN trusted { type primary; file "db.example.com"; };`).
+		Return(nil, errors.New("not found"))
+
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:     db,
+		Agents: mock,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	results, err := manager.ModifyZone(context.Background(), []int64{daemon.ID, daemon.ID + 1}, "trusted", newTestProvisioningZone(t, `zone "example.com" { type primary; file "db.example.com"; };`), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.ErrorContains(t, results[0].Err, "rndc modzone failed for zone example.com: not found")
+	require.ErrorContains(t, results[1].Err, "not found")
+}
+
+// Test that the zone is deleted with rndc delzone.
+func TestDeleteZone(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestProvisioningDaemon(t, db)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mock := NewMockConnectedAgents(controller)
+	expectTestBind9Config(mock, testProvisioningConfig)
+	mock.EXPECT().ForwardRndcCommand(gomock.Any(), gomock.Any(), "delzone example.com IN trusted").
+		Return(&agentcomm.RndcOutput{}, nil)
+	expectTestZoneInventoryRefresh(mock)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	manager, err := NewManager(&appstest.ManagerAccessorsWrapper{
+		DB:          db,
+		Agents:      mock,
+		EventCenter: eventCenter,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	results, err := manager.DeleteZone(context.Background(), []int64{daemon.ID}, "trusted", "example.com", "", &dbmodel.SystemUser{ID: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Err)
+
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "deleted zone example.com from view trusted")
+
+	waitForZoneInventoryRefresh(t, manager)
+}
@@ -0,0 +1,179 @@
+package dnsop
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+)
+
+// Name of the setting holding the time in seconds after which the lagging
+// or inconsistent zone serial is reported. Zero disables the reports.
+const ZoneSerialLagThresholdSetting = "zone_serial_lag_threshold"
+
+// Name of the setting enabling refreshing the zone inventories before
+// comparing the zone serials.
+const ZoneSyncRefreshSetting = "enable_zone_sync_refresh"
+
+// The puller periodically comparing the serials of the zones served by
+// several DNS servers in the same view. By default, it compares the serials
+// of the zones fetched previously. If enabled in the settings, it refreshes
+// the zone inventories before the comparison. It raises a warning event
+// when a server serves an older serial than the primary server (or newer
+// than the primary) for longer than the configured threshold, and an info
+// event when the server is in sync again. The lag is measured against the
+// zones fetched after the newer serial was loaded, so the stale zones don't
+// cause the warnings.
+type ZoneSyncPuller struct {
+	*agentcomm.PeriodicPuller
+	manager     Manager
+	eventCenter eventcenter.EventCenter
+	// Local zones for which the lag has been reported.
+	reportedLocalZones map[int64]bool
+	// Closed when the puller is shut down to stop waiting for the zone
+	// refresh.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// Creates the puller comparing the zone serials in background.
+func NewZoneSyncPuller(db *pg.DB, agents agentcomm.ConnectedAgents, manager Manager, eventCenter eventcenter.EventCenter) (*ZoneSyncPuller, error) {
+	puller := &ZoneSyncPuller{
+		manager:            manager,
+		eventCenter:        eventCenter,
+		reportedLocalZones: make(map[int64]bool),
+		shutdown:           make(chan struct{}),
+	}
+	periodicPuller, err := agentcomm.NewPeriodicPuller(db, agents, "Zone sync puller", "zone_sync_puller_interval",
+		puller.pullZoneSync)
+	if err != nil {
+		return nil, err
+	}
+	puller.PeriodicPuller = periodicPuller
+	return puller, nil
+}
+
+// Shuts down the puller. It stops waiting for the zone refresh and stops
+// the goroutine comparing the serials. It is safe to call it many times.
+func (puller *ZoneSyncPuller) Shutdown() {
+	puller.shutdownOnce.Do(func() {
+		close(puller.shutdown)
+		puller.PeriodicPuller.Shutdown()
+	})
+}
+
+// Refreshes the zone inventories, if enabled, and compares the serials.
+func (puller *ZoneSyncPuller) pullZoneSync() error {
+	refresh, err := dbmodel.GetSettingBool(puller.DB, ZoneSyncRefreshSetting)
+	if err != nil {
+		return err
+	}
+	if refresh {
+		completed, err := puller.refreshZones()
+		switch {
+		case err != nil:
+			// Compare the serials fetched previously.
+			log.WithError(err).Warn("Failed to refresh the zones before comparing their serials")
+		case !completed:
+			// The puller is shutting down.
+			return nil
+		}
+	}
+	return puller.checkZoneSync()
+}
+
+// Fetches the zones from the agents and waits for the completion. The
+// zone inventories are populated again to get the current serials. It
+// returns false when the puller was shut down before the zones were
+// refreshed.
+func (puller *ZoneSyncPuller) refreshZones() (bool, error) {
+	notify, err := puller.manager.FetchZones(zoneInventoryRefreshPoolSize, zoneInventoryRefreshBatchSize, FetchZonesOptionForcePopulate)
+	if err != nil {
+		var alreadyFetching *ManagerAlreadyFetchingError
+		if errors.As(err, &alreadyFetching) {
+			// The zones are already being fetched, e.g., by a user.
+			return true, nil
+		}
+		return true, err
+	}
+	select {
+	case <-notify:
+		return true, nil
+	case <-puller.shutdown:
+		// The notification channel is buffered, so the manager doesn't
+		// block when the fetch completes.
+		return false, nil
+	}
+}
+
+// Compares the serials of the zones and raises the events for the servers
+// lagging behind longer than the configured threshold.
+func (puller *ZoneSyncPuller) checkZoneSync() error {
+	threshold, err := dbmodel.GetSettingInt(puller.DB, ZoneSerialLagThresholdSetting)
+	if err != nil {
+		return err
+	}
+	if threshold <= 0 {
+		puller.reportedLocalZones = make(map[int64]bool)
+		return nil
+	}
+	zones, err := dbmodel.GetZonesForSyncCheck(puller.DB, dbmodel.ZoneRelationLocalZonesDaemon, dbmodel.ZoneRelationLocalZonesMachine)
+	if err != nil {
+		return err
+	}
+	fetchTimes, err := dbmodel.GetZoneInventoryFetchTimes(puller.DB)
+	if err != nil {
+		return err
+	}
+	reported := make(map[int64]bool)
+	outOfSyncCount := 0
+	for _, zone := range zones {
+		for _, zoneSync := range zone.GetSyncStates(fetchTimes) {
+			localZone := zoneSync.LocalZone
+			if zoneSync.State == dbmodel.ZoneSyncStateInSync {
+				if puller.reportedLocalZones[localZone.ID] {
+					puller.eventCenter.AddInfoEvent(
+						fmt.Sprintf("{daemon} serves zone %s in view %s in sync with %s", zone.Name, localZone.View, zoneSync.Reference.Daemon.GetLabel()),
+						localZone.Daemon,
+					)
+				}
+				continue
+			}
+			outOfSyncCount++
+			if puller.reportedLocalZones[localZone.ID] {
+				reported[localZone.ID] = true
+				continue
+			}
+			if zoneSync.LagDuration < time.Duration(threshold)*time.Second {
+				continue
+			}
+			reported[localZone.ID] = true
+			relation := "behind"
+			if zoneSync.State == dbmodel.ZoneSyncStateInconsistent {
+				relation = "ahead of"
+			}
+			puller.eventCenter.AddWarningEvent(
+				fmt.Sprintf("{daemon} serves zone %s in view %s with serial %d %s serial %d on %s",
+					zone.Name, localZone.View, localZone.Serial, relation, zoneSync.Reference.Serial, zoneSync.Reference.Daemon.GetLabel()),
+				localZone.Daemon,
+				fmt.Sprintf("Serial lag: %d\nOut of sync for: %s", zoneSync.SerialLag, zoneSync.LagDuration.Truncate(time.Second)),
+			)
+			log.WithFields(log.Fields{
+				"zone":      zone.Name,
+				"view":      localZone.View,
+				"daemon":    localZone.Daemon.GetLabel(),
+				"serial":    localZone.Serial,
+				"reference": zoneSync.Reference.Serial,
+			}).Warn("Zone serial is out of sync")
+		}
+	}
+	puller.reportedLocalZones = reported
+	log.Infof("Completed comparing the serials of %d zones: %d local zones out of sync", len(zones), outOfSyncCount)
+	return nil
+}
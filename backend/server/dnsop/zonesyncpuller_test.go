@@ -0,0 +1,236 @@
+package dnsop
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"isc.org/stork/datamodel/daemonname"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Adds the primary and secondary BIND 9 daemons serving the example.com
+// zone to the database. The secondary serves an older serial.
+func addTestZoneSyncDaemons(t *testing.T, db pg.DBI, loadedAt time.Time) []*dbmodel.Daemon {
+	var daemons []*dbmodel.Daemon
+	for i := 0; i < 2; i++ {
+		machine := &dbmodel.Machine{
+			Address:   fmt.Sprintf("192.0.2.%d", i+1),
+			AgentPort: 8080,
+		}
+		err := dbmodel.AddMachine(db, machine)
+		require.NoError(t, err)
+
+		daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+		err = dbmodel.AddDaemon(db, daemon)
+		require.NoError(t, err)
+		daemons = append(daemons, daemon)
+	}
+	err := dbmodel.AddZones(db, &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{
+			{DaemonID: daemons[0].ID, View: "_default", Class: "IN", Type: "primary", Serial: 12, LoadedAt: loadedAt},
+			{DaemonID: daemons[1].ID, View: "_default", Class: "IN", Type: "secondary", Serial: 10, LoadedAt: loadedAt},
+		},
+	})
+	require.NoError(t, err)
+	return daemons
+}
+
+// Records the time when the zones were fetched from the daemons.
+func setTestZoneSyncFetchTime(t *testing.T, db pg.DBI, daemons []*dbmodel.Daemon, fetchedAt time.Time) {
+	for _, daemon := range daemons {
+		state := dbmodel.NewZoneInventoryState(daemon.ID, dbmodel.NewZoneInventoryStateDetails())
+		state.CreatedAt = fetchedAt
+		err := dbmodel.AddZoneInventoryState(db, state)
+		require.NoError(t, err)
+	}
+}
+
+// Fake manager counting the zone fetches. The fetches never complete.
+type zoneSyncFetchManager struct {
+	Manager
+	fetchCount atomic.Int32
+}
+
+// Counts the zone fetches and returns the channel which is never notified.
+func (manager *zoneSyncFetchManager) FetchZones(poolSize, batchSize int, options ...FetchZonesOption) (chan ManagerDoneNotify, error) {
+	manager.fetchCount.Add(1)
+	return make(chan ManagerDoneNotify, 1), nil
+}
+
+// Test creating and shutting down the puller.
+func TestNewZoneSyncPuller(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	puller, err := NewZoneSyncPuller(db, NewMockConnectedAgents(controller), nil, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+	require.NotNil(t, puller)
+	require.Equal(t, "zone_sync_puller_interval", puller.GetIntervalSettingName())
+	puller.Shutdown()
+	// Shutting down again is harmless.
+	puller.Shutdown()
+}
+
+// Test that the warning event is raised once when the secondary server
+// lags behind longer than the threshold, and the info event is raised
+// when the server is in sync again.
+func TestZoneSyncPullerCheckZoneSync(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	daemons := addTestZoneSyncDaemons(t, db, now.Add(-2*time.Hour))
+	setTestZoneSyncFetchTime(t, db, daemons, now)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	puller, err := NewZoneSyncPuller(db, nil, nil, eventCenter)
+	require.NoError(t, err)
+	defer puller.Shutdown()
+
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "serves zone example.com in view _default with serial 10 behind serial 12")
+	require.Contains(t, eventCenter.Events[0].Details, "Serial lag: 2")
+
+	// The lag is reported only once.
+	setTestZoneSyncFetchTime(t, db, daemons, now.Add(time.Hour))
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+
+	// The secondary transferred the new serial.
+	_, err = db.Model((*dbmodel.LocalZone)(nil)).
+		Set("serial = ?", 12).
+		Where("daemon_id = ?", daemons[1].ID).
+		Update()
+	require.NoError(t, err)
+
+	setTestZoneSyncFetchTime(t, db, daemons, now.Add(2*time.Hour))
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 2)
+	require.Equal(t, dbmodel.EvInfo, eventCenter.Events[1].Level)
+	require.Contains(t, eventCenter.Events[1].Text, "serves zone example.com in view _default in sync with")
+}
+
+// Test that no event is raised when the lag is below the threshold or the
+// threshold is zero.
+func TestZoneSyncPullerCheckZoneSyncBelowThreshold(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	daemons := addTestZoneSyncDaemons(t, db, now.Add(-30*time.Minute))
+	setTestZoneSyncFetchTime(t, db, daemons, now)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	puller, err := NewZoneSyncPuller(db, nil, nil, eventCenter)
+	require.NoError(t, err)
+	defer puller.Shutdown()
+
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Empty(t, eventCenter.Events)
+
+	// Disable the reports.
+	err = dbmodel.SetSettingInt(db, ZoneSerialLagThresholdSetting, 0)
+	require.NoError(t, err)
+
+	setTestZoneSyncFetchTime(t, db, daemons, now.Add(2*time.Hour))
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test that no event is raised when the zones were fetched from the lagging
+// server before the reference server loaded the newer serial, regardless
+// of how long ago they were fetched.
+func TestZoneSyncPullerCheckZoneSyncStaleZones(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	loadedAt := time.Now().UTC().Add(-24 * time.Hour)
+	daemons := addTestZoneSyncDaemons(t, db, loadedAt)
+	setTestZoneSyncFetchTime(t, db, daemons, loadedAt.Add(-time.Minute))
+
+	eventCenter := &storktest.FakeEventCenter{}
+	puller, err := NewZoneSyncPuller(db, nil, nil, eventCenter)
+	require.NoError(t, err)
+	defer puller.Shutdown()
+
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Empty(t, eventCenter.Events)
+
+	// The zones fetched again confirm the lag.
+	setTestZoneSyncFetchTime(t, db, daemons, loadedAt.Add(2*time.Hour))
+	err = puller.checkZoneSync()
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Details, "Out of sync for: 2h0m0s")
+}
+
+// Test that the zones are not refreshed by default, and that the puller
+// waiting for the enabled zone refresh can be shut down.
+func TestZoneSyncPullerRefreshZones(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	manager := &zoneSyncFetchManager{}
+	puller, err := NewZoneSyncPuller(db, nil, manager, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	// The serials of the zones fetched previously are compared.
+	err = puller.pullZoneSync()
+	require.NoError(t, err)
+	require.Zero(t, manager.fetchCount.Load())
+
+	// Enable the zone refresh. The puller waits for the fetch to complete.
+	err = dbmodel.SetSettingBool(db, ZoneSyncRefreshSetting, true)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- puller.pullZoneSync()
+	}()
+	require.Eventually(t, func() bool {
+		return manager.fetchCount.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Shutting down the puller stops waiting.
+	puller.Shutdown()
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the puller did not stop waiting for the zone refresh")
+	}
+}
@@ -0,0 +1,319 @@
+// Package hostsio implements reading and writing the host reservations in
+// the CSV and JSON formats used by the bulk host import and export. Both
+// formats carry the same set of the host reservation fields. The CSV file
+// must begin with a header naming the columns. The multi-valued columns
+// (e.g., IP addresses) hold the values separated by spaces. The JSON file
+// holds a list of objects with the keys named like the CSV columns.
+package hostsio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Format of the imported or exported host reservations.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// Names of the columns in the CSV file.
+const (
+	columnSubnet         = "subnet"
+	columnIdentifierType = "identifier-type"
+	columnIdentifier     = "identifier"
+	columnHostname       = "hostname"
+	columnIPAddresses    = "ip-addresses"
+	columnPrefixes       = "prefixes"
+	columnClientClasses  = "client-classes"
+	columnNextServer     = "next-server"
+	columnServerHostname = "server-hostname"
+	columnBootFileName   = "boot-file-name"
+)
+
+// The CSV columns in the order they are written.
+var columns = []string{
+	columnSubnet,
+	columnIdentifierType,
+	columnIdentifier,
+	columnHostname,
+	columnIPAddresses,
+	columnPrefixes,
+	columnClientClasses,
+	columnNextServer,
+	columnServerHostname,
+	columnBootFileName,
+}
+
+// Converts a string to the format. It returns an error if the format
+// is not supported.
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(format)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", errors.Errorf("unsupported host reservations format %s", format)
+	}
+}
+
+// A single host reservation in the imported or exported file. The host
+// reservation belongs to the subnet with the specified prefix. It is a
+// global reservation if the subnet is empty. The identifier is a string
+// of hexadecimal digits, optionally separated by colons.
+type Record struct {
+	Subnet         string   `json:"subnet,omitempty"`
+	IdentifierType string   `json:"identifier-type"`
+	Identifier     string   `json:"identifier"`
+	Hostname       string   `json:"hostname,omitempty"`
+	IPAddresses    []string `json:"ip-addresses,omitempty"`
+	Prefixes       []string `json:"prefixes,omitempty"`
+	ClientClasses  []string `json:"client-classes,omitempty"`
+	NextServer     string   `json:"next-server,omitempty"`
+	ServerHostname string   `json:"server-hostname,omitempty"`
+	BootFileName   string   `json:"boot-file-name,omitempty"`
+}
+
+// Creates a record from the host reservation. The DHCP parameters, e.g.
+// client classes, are taken from the first daemon owning the reservation.
+func NewRecord(host *dbmodel.Host) Record {
+	record := Record{
+		Hostname: host.GetHostname(),
+	}
+	if host.Subnet != nil {
+		record.Subnet = host.Subnet.Prefix
+	}
+	if len(host.HostIdentifiers) > 0 {
+		record.IdentifierType = host.HostIdentifiers[0].Type
+		record.Identifier = host.HostIdentifiers[0].ToHex(":")
+	}
+	for _, reservation := range host.GetIPReservations() {
+		parsed := storkutil.ParseIP(reservation)
+		switch {
+		case parsed == nil:
+			continue
+		case parsed.Prefix:
+			record.Prefixes = append(record.Prefixes, parsed.NetworkAddress)
+		default:
+			record.IPAddresses = append(record.IPAddresses, parsed.NetworkAddress)
+		}
+	}
+	if len(host.LocalHosts) > 0 {
+		daemonID := host.LocalHosts[0].DaemonID
+		record.ClientClasses = host.GetClientClasses(daemonID)
+		record.NextServer = host.GetNextServer(daemonID)
+		record.ServerHostname = host.GetServerHostname(daemonID)
+		record.BootFileName = host.GetBootFileName(daemonID)
+	}
+	return record
+}
+
+// Converts the record to the host reservation associated with the specified
+// daemons. The subnets are indexed by their prefixes. The record's subnet
+// must be one of them. It returns an error if the host reservation doesn't
+// pass the validation (see dbmodel.Host.Validate).
+func (record Record) ToHost(subnets map[string]*dbmodel.Subnet, daemons []*dbmodel.Daemon) (*dbmodel.Host, error) {
+	host := &dbmodel.Host{}
+	if record.Subnet != "" {
+		prefix := record.Subnet
+		if parsed := storkutil.ParseIP(prefix); parsed != nil {
+			prefix = parsed.NetworkAddress
+		}
+		subnet, ok := subnets[prefix]
+		if !ok {
+			return nil, errors.Errorf("subnet %s does not exist", record.Subnet)
+		}
+		host.SubnetID = subnet.ID
+		host.Subnet = subnet
+	}
+	value := storkutil.HexToBytes(record.Identifier)
+	if len(value) == 0 && record.Identifier != "" {
+		return nil, errors.Errorf("invalid %s host identifier %s", record.IdentifierType, record.Identifier)
+	}
+	host.HostIdentifiers = []dbmodel.HostIdentifier{
+		{
+			Type:  record.IdentifierType,
+			Value: value,
+		},
+	}
+	for _, daemon := range daemons {
+		localHost := dbmodel.LocalHost{
+			DaemonID:       daemon.ID,
+			Daemon:         daemon,
+			DataSource:     dbmodel.HostDataSourceAPI,
+			Hostname:       record.Hostname,
+			ClientClasses:  record.ClientClasses,
+			NextServer:     record.NextServer,
+			ServerHostname: record.ServerHostname,
+			BootFileName:   record.BootFileName,
+		}
+		for _, address := range append(slices.Clone(record.IPAddresses), record.Prefixes...) {
+			localHost.IPReservations = append(localHost.IPReservations, dbmodel.IPReservation{
+				Address: address,
+			})
+		}
+		host.AddOrUpdateLocalHost(localHost)
+	}
+	if err := host.Validate(); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// Reads the host reservations in the specified format.
+func Read(reader io.Reader, format Format) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(reader)
+	case FormatJSON:
+		records := []Record{}
+		if err := json.NewDecoder(reader).Decode(&records); err != nil {
+			return nil, errors.Wrap(err, "problem with parsing host reservations in JSON format")
+		}
+		return records, nil
+	default:
+		return nil, errors.Errorf("unsupported host reservations format %s", format)
+	}
+}
+
+// Reads the host reservations from the CSV file. The first line must
+// contain the column names. The columns can be specified in any order.
+// The identifier-type and identifier columns are mandatory.
+func readCSV(reader io.Reader) ([]Record, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "problem with reading the header of the host reservations in CSV format")
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		if !slices.Contains(columns, header[i]) {
+			return nil, errors.Errorf("unknown column %s in the host reservations in CSV format", header[i])
+		}
+	}
+	for _, column := range []string{columnIdentifierType, columnIdentifier} {
+		if !slices.Contains(header, column) {
+			return nil, errors.Errorf("missing column %s in the host reservations in CSV format", column)
+		}
+	}
+	records := []Record{}
+	for {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "problem with parsing host reservations in CSV format")
+		}
+		var record Record
+		for i, value := range row {
+			value = strings.TrimSpace(value)
+			switch header[i] {
+			case columnSubnet:
+				record.Subnet = value
+			case columnIdentifierType:
+				record.IdentifierType = value
+			case columnIdentifier:
+				record.Identifier = value
+			case columnHostname:
+				record.Hostname = value
+			case columnIPAddresses:
+				record.IPAddresses = splitValues(value)
+			case columnPrefixes:
+				record.Prefixes = splitValues(value)
+			case columnClientClasses:
+				record.ClientClasses = splitValues(value)
+			case columnNextServer:
+				record.NextServer = value
+			case columnServerHostname:
+				record.ServerHostname = value
+			case columnBootFileName:
+				record.BootFileName = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Splits the value of a multi-valued column. It returns nil for an empty
+// value.
+func splitValues(value string) []string {
+	values := strings.Fields(value)
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// Writes the host reservations in the specified format.
+func Write(writer io.Writer, format Format, records []Record) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(writer, records)
+	case FormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "    ")
+		if records == nil {
+			records = []Record{}
+		}
+		if err := encoder.Encode(records); err != nil {
+			return errors.Wrap(err, "problem with writing host reservations in JSON format")
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported host reservations format %s", format)
+	}
+}
+
+// Writes the host reservations to the CSV file with a header.
+func writeCSV(writer io.Writer, records []Record) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(columns); err != nil {
+		return errors.Wrap(err, "problem with writing host reservations in CSV format")
+	}
+	for _, record := range records {
+		row := []string{
+			record.Subnet,
+			record.IdentifierType,
+			record.Identifier,
+			record.Hostname,
+			strings.Join(record.IPAddresses, " "),
+			strings.Join(record.Prefixes, " "),
+			strings.Join(record.ClientClasses, " "),
+			record.NextServer,
+			record.ServerHostname,
+			record.BootFileName,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return errors.Wrap(err, "problem with writing host reservations in CSV format")
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return errors.Wrap(err, "problem with writing host reservations in CSV format")
+	}
+	return nil
+}
+
+// Returns a key identifying the host reservation in the import. The
+// host reservations having the same key are duplicates.
+func (record Record) Key() string {
+	prefix := record.Subnet
+	if parsed := storkutil.ParseIP(prefix); parsed != nil {
+		prefix = parsed.NetworkAddress
+	}
+	return fmt.Sprintf("%s/%s/%x", prefix, record.IdentifierType, storkutil.HexToBytes(record.Identifier))
+}
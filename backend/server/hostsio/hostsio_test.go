@@ -0,0 +1,243 @@
+package hostsio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Test parsing the host reservations format.
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat("JSON")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("xml")
+	require.ErrorContains(t, err, "unsupported host reservations format xml")
+}
+
+// Test reading the host reservations from the CSV file.
+func TestReadCSV(t *testing.T) {
+	input := `identifier-type,identifier,subnet,ip-addresses,hostname,client-classes
+hw-address,01:02:03:04:05:06,192.0.2.0/24,192.0.2.10,foo.example.org,foo bar
+duid,01:02:03,2001:db8:1::/64,2001:db8:1::1 2001:db8:1::2,,
+`
+	records, err := Read(strings.NewReader(input), FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	require.Equal(t, "192.0.2.0/24", records[0].Subnet)
+	require.Equal(t, "hw-address", records[0].IdentifierType)
+	require.Equal(t, "01:02:03:04:05:06", records[0].Identifier)
+	require.Equal(t, []string{"192.0.2.10"}, records[0].IPAddresses)
+	require.Equal(t, "foo.example.org", records[0].Hostname)
+	require.Equal(t, []string{"foo", "bar"}, records[0].ClientClasses)
+
+	require.Equal(t, "duid", records[1].IdentifierType)
+	require.Equal(t, []string{"2001:db8:1::1", "2001:db8:1::2"}, records[1].IPAddresses)
+	require.Empty(t, records[1].Hostname)
+	require.Empty(t, records[1].ClientClasses)
+}
+
+// Test that the CSV file with an invalid header is rejected.
+func TestReadCSVInvalidHeader(t *testing.T) {
+	_, err := Read(strings.NewReader("identifier-type,identifier,foo\n"), FormatCSV)
+	require.ErrorContains(t, err, "unknown column foo")
+
+	_, err = Read(strings.NewReader("identifier-type,hostname\n"), FormatCSV)
+	require.ErrorContains(t, err, "missing column identifier")
+
+	_, err = Read(strings.NewReader(""), FormatCSV)
+	require.ErrorContains(t, err, "problem with reading the header")
+}
+
+// Test reading the host reservations from the JSON file.
+func TestReadJSON(t *testing.T) {
+	input := `[
+		{
+			"subnet": "192.0.2.0/24",
+			"identifier-type": "hw-address",
+			"identifier": "01:02:03:04:05:06",
+			"ip-addresses": [ "192.0.2.10" ],
+			"next-server": "192.0.2.1"
+		}
+	]`
+	records, err := Read(strings.NewReader(input), FormatJSON)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "192.0.2.0/24", records[0].Subnet)
+	require.Equal(t, []string{"192.0.2.10"}, records[0].IPAddresses)
+	require.Equal(t, "192.0.2.1", records[0].NextServer)
+
+	_, err = Read(strings.NewReader("{"), FormatJSON)
+	require.ErrorContains(t, err, "problem with parsing host reservations in JSON format")
+}
+
+// Test that the host reservations written to a file can be read back.
+func TestWriteRead(t *testing.T) {
+	records := []Record{
+		{
+			Subnet:         "2001:db8:1::/64",
+			IdentifierType: "duid",
+			Identifier:     "01:02:03",
+			Hostname:       "foo.example.org",
+			IPAddresses:    []string{"2001:db8:1::1"},
+			Prefixes:       []string{"3000::/96"},
+			ClientClasses:  []string{"foo", "bar"},
+		},
+		{
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:06",
+			NextServer:     "192.0.2.1",
+			ServerHostname: "server.example.org",
+			BootFileName:   "/tmp/boot",
+		},
+	}
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := Write(&buffer, format, records)
+			require.NoError(t, err)
+
+			readRecords, err := Read(&buffer, format)
+			require.NoError(t, err)
+			require.Equal(t, records, readRecords)
+		})
+	}
+}
+
+// Test creating the record from the host reservation.
+func TestNewRecord(t *testing.T) {
+	host := &dbmodel.Host{
+		Subnet: &dbmodel.Subnet{
+			Prefix: "2001:db8:1::/64",
+		},
+		HostIdentifiers: []dbmodel.HostIdentifier{
+			{
+				Type:  "duid",
+				Value: []byte{1, 2, 3},
+			},
+		},
+		LocalHosts: []dbmodel.LocalHost{
+			{
+				DaemonID:      1,
+				Hostname:      "foo.example.org",
+				ClientClasses: []string{"foo"},
+				IPReservations: []dbmodel.IPReservation{
+					{
+						Address: "2001:db8:1::1",
+					},
+					{
+						Address: "3000::/96",
+					},
+				},
+			},
+		},
+	}
+	record := NewRecord(host)
+	require.Equal(t, "2001:db8:1::/64", record.Subnet)
+	require.Equal(t, "duid", record.IdentifierType)
+	require.Equal(t, "01:02:03", record.Identifier)
+	require.Equal(t, "foo.example.org", record.Hostname)
+	require.Equal(t, []string{"2001:db8:1::1"}, record.IPAddresses)
+	require.Equal(t, []string{"3000::/96"}, record.Prefixes)
+	require.Equal(t, []string{"foo"}, record.ClientClasses)
+}
+
+// Test converting the record to the host reservation.
+func TestRecordToHost(t *testing.T) {
+	subnets := map[string]*dbmodel.Subnet{
+		"192.0.2.0/24": {
+			ID:     5,
+			Prefix: "192.0.2.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					DaemonID: 1,
+				},
+			},
+		},
+	}
+	daemons := []*dbmodel.Daemon{
+		{
+			ID: 1,
+		},
+	}
+
+	t.Run("valid record", func(t *testing.T) {
+		record := Record{
+			Subnet:         "192.0.2.0/24",
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:06",
+			Hostname:       "foo.example.org",
+			IPAddresses:    []string{"192.0.2.10"},
+			ClientClasses:  []string{"foo"},
+		}
+		host, err := record.ToHost(subnets, daemons)
+		require.NoError(t, err)
+		require.NotNil(t, host)
+		require.EqualValues(t, 5, host.SubnetID)
+		require.Len(t, host.HostIdentifiers, 1)
+		require.Equal(t, []byte{1, 2, 3, 4, 5, 6}, host.HostIdentifiers[0].Value)
+		require.Len(t, host.LocalHosts, 1)
+		require.Equal(t, daemons[0], host.LocalHosts[0].Daemon)
+		require.Equal(t, dbmodel.HostDataSourceAPI, host.LocalHosts[0].DataSource)
+		require.Equal(t, "foo.example.org", host.LocalHosts[0].Hostname)
+		require.Equal(t, []string{"foo"}, host.LocalHosts[0].ClientClasses)
+		require.Equal(t, []string{"192.0.2.10"}, host.GetIPReservations())
+	})
+
+	t.Run("unknown subnet", func(t *testing.T) {
+		record := Record{
+			Subnet:         "192.0.3.0/24",
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:06",
+		}
+		_, err := record.ToHost(subnets, daemons)
+		require.ErrorContains(t, err, "subnet 192.0.3.0/24 does not exist")
+	})
+
+	t.Run("invalid identifier", func(t *testing.T) {
+		record := Record{
+			IdentifierType: "hw-address",
+			Identifier:     "zz",
+		}
+		_, err := record.ToHost(subnets, daemons)
+		require.ErrorContains(t, err, "invalid hw-address host identifier zz")
+	})
+
+	t.Run("address out of subnet", func(t *testing.T) {
+		record := Record{
+			Subnet:         "192.0.2.0/24",
+			IdentifierType: "hw-address",
+			Identifier:     "01:02:03:04:05:06",
+			IPAddresses:    []string{"192.0.3.10"},
+		}
+		_, err := record.ToHost(subnets, daemons)
+		require.ErrorContains(t, err, "does not belong to subnet")
+	})
+}
+
+// Test that the duplicated records have the same key.
+func TestRecordKey(t *testing.T) {
+	record1 := Record{
+		Subnet:         "192.0.2.0/24",
+		IdentifierType: "hw-address",
+		Identifier:     "01:02:03:04:05:06",
+	}
+	record2 := Record{
+		Subnet:         "192.0.2.0/24",
+		IdentifierType: "hw-address",
+		Identifier:     "010203040506",
+	}
+	require.Equal(t, record1.Key(), record2.Key())
+
+	record2.Subnet = ""
+	require.NotEqual(t, record1.Key(), record2.Key())
+}
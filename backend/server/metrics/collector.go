@@ -60,6 +60,9 @@ type prometheusCollector struct {
 	subnetPdUtilizationDescriptor             *prometheus.Desc
 	sharedNetworkAddressUtilizationDescriptor *prometheus.Desc
 	sharedNetworkPdUtilizationDescriptor      *prometheus.Desc
+	zoneOutOfSyncDescriptor                   *prometheus.Desc
+	zoneSerialLagDescriptor                   *prometheus.Desc
+	zoneLagDurationDescriptor                 *prometheus.Desc
 	// The statistics are stored as a map in the dbmodel.SharedNetwork
 	// structure. So, it is possible to handle all of them in the same way and
 	// convert them to the Prometheus metrics using for-loop. The collector
@@ -125,6 +128,21 @@ func NewCollector(source MetricsSource) (Collector, error) {
 			"Shared-network delegated-prefix utilization",
 			[]string{"name"}, nil,
 		),
+		zoneOutOfSyncDescriptor: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "zone", "out_of_sync"),
+			"Zone serial differs from the serial on the primary server",
+			[]string{"zone", "view", "class", "daemon"}, nil,
+		),
+		zoneSerialLagDescriptor: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "zone", "serial_lag"),
+			"Difference between the zone serial on the primary server and the zone serial",
+			[]string{"zone", "view", "class", "daemon"}, nil,
+		),
+		zoneLagDurationDescriptor: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "zone", "lag_duration_seconds"),
+			"Time for which the zone serial has been out of sync",
+			[]string{"zone", "view", "class", "daemon"}, nil,
+		),
 		sharedNetworkStatisticDescriptors: storkutil.NewOrderedMapFromEntries(
 			[]dbmodel.StatName{
 				dbmodel.StatNameTotalNAs,
@@ -189,6 +207,9 @@ func (c *prometheusCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.subnetPdUtilizationDescriptor
 	ch <- c.sharedNetworkAddressUtilizationDescriptor
 	ch <- c.sharedNetworkPdUtilizationDescriptor
+	ch <- c.zoneOutOfSyncDescriptor
+	ch <- c.zoneSerialLagDescriptor
+	ch <- c.zoneLagDurationDescriptor
 	for _, descriptor := range c.sharedNetworkStatisticDescriptors.GetValues() {
 		ch <- descriptor
 	}
@@ -259,4 +280,17 @@ func (c *prometheusCollector) Collect(ch chan<- prometheus.Metric) {
 			)
 		}
 	}
+	for _, zoneMetrics := range calculatedMetrics.ZoneSyncMetrics {
+		labels := []string{zoneMetrics.Zone, zoneMetrics.View, zoneMetrics.Class, zoneMetrics.Daemon}
+		outOfSync := 0.0
+		if zoneMetrics.State != dbmodel.ZoneSyncStateInSync {
+			outOfSync = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.zoneOutOfSyncDescriptor,
+			prometheus.GaugeValue, outOfSync, labels...)
+		ch <- prometheus.MustNewConstMetric(c.zoneSerialLagDescriptor,
+			prometheus.GaugeValue, float64(zoneMetrics.SerialLag), labels...)
+		ch <- prometheus.MustNewConstMetric(c.zoneLagDurationDescriptor,
+			prometheus.GaugeValue, zoneMetrics.LagDuration, labels...)
+	}
 }
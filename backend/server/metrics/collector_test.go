@@ -165,7 +165,7 @@ func TestCollectorDescribe(t *testing.T) {
 	source := newMockMetricsSource()
 	collector, _ := NewCollector(source)
 	promCollector := collector.(prometheus.Collector)
-	expectedDescriptionCount := 11
+	expectedDescriptionCount := 14
 
 	t.Run("initial metrics values", func(t *testing.T) {
 		source.Set(dbmodel.CalculatedMetrics{})
@@ -439,6 +439,51 @@ func TestCollectorCollect(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("zone sync metrics", func(t *testing.T) {
+		source.Set(dbmodel.CalculatedMetrics{
+			ZoneSyncMetrics: []dbmodel.CalculatedZoneSyncMetrics{
+				{
+					Zone:   "example.com",
+					View:   "_default",
+					Class:  "IN",
+					Daemon: "named@primary",
+					State:  dbmodel.ZoneSyncStateInSync,
+				},
+				{
+					Zone:        "example.com",
+					View:        "_default",
+					Class:       "IN",
+					Daemon:      "named@secondary",
+					State:       dbmodel.ZoneSyncStateLagging,
+					SerialLag:   2,
+					LagDuration: 120,
+				},
+			},
+		})
+
+		metricsChannel := make(chan prometheus.Metric, 100)
+
+		// Act
+		promCollector.Collect(metricsChannel)
+
+		// Assert
+		close(metricsChannel)
+		require.Len(t, metricsChannel, 9)
+		var values []float64
+		for metric := range metricsChannel {
+			metricDTO := &dto.Metric{}
+			err := metric.Write(metricDTO)
+			require.NoError(t, err)
+			values = append(values, *metricDTO.Gauge.Value)
+			if len(values) > 3 {
+				require.Len(t, metricDTO.Label, 4)
+			}
+		}
+		// Machine counters, then the out of sync flag, serial lag and
+		// lag duration for each server.
+		require.Equal(t, []float64{0, 0, 0, 0, 0, 0, 1, 2, 120}, values)
+	})
 }
 
 // All metrics should be unregistered.
@@ -0,0 +1,681 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/config"
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Returns the IP type of the DHCP options configured in the daemon.
+func getDaemonOptionsUniverse(daemon *dbmodel.Daemon) storkutil.IPType {
+	if daemon != nil && daemon.Name == daemonname.DHCPv6 {
+		return storkutil.IPv6
+	}
+	return storkutil.IPv4
+}
+
+// Converts the client class fetched from the database to the format used
+// in REST API.
+func (r *RestAPI) convertClientClassToRestAPI(dbClass *dbmodel.ClientClass) *models.ClientClass {
+	class := &models.ClientClass{
+		ID:       dbClass.ID,
+		DaemonID: dbClass.DaemonID,
+		Name:     storkutil.Ptr(dbClass.Name),
+	}
+	if dbClass.Daemon != nil {
+		class.DaemonLabel = dbClass.Daemon.GetLabel()
+	}
+	params := dbClass.KeaParameters
+	if params == nil {
+		return class
+	}
+	if params.Test != nil {
+		class.Test = *params.Test
+	}
+	if params.TemplateTest != nil {
+		class.TemplateTest = *params.TemplateTest
+	}
+	class.OnlyInAdditionalList = params.IsOnlyInAdditionalList()
+	if params.NextServer != nil {
+		class.NextServer = *params.NextServer
+	}
+	if params.ServerHostname != nil {
+		class.ServerHostname = *params.ServerHostname
+	}
+	if params.BootFileName != nil {
+		class.BootFileName = *params.BootFileName
+	}
+	class.ValidLifetime = params.ValidLifetime
+	class.MinValidLifetime = params.MinValidLifetime
+	class.MaxValidLifetime = params.MaxValidLifetime
+	class.PreferredLifetime = params.PreferredLifetime
+	class.MinPreferredLifetime = params.MinPreferredLifetime
+	class.MaxPreferredLifetime = params.MaxPreferredLifetime
+	class.OfferLifetime = params.OfferLifetime
+	if params.UserContext != nil {
+		class.UserContext = params.UserContext
+	}
+
+	var convertedOptions []dbmodel.DHCPOption
+	for _, option := range params.OptionData {
+		convertedOption, err := dbmodel.NewDHCPOptionFromKea(
+			option, getDaemonOptionsUniverse(dbClass.Daemon), r.DHCPOptionDefinitionLookup,
+		)
+		if err != nil {
+			continue
+		}
+		convertedOptions = append(convertedOptions, *convertedOption)
+	}
+	class.Options = &models.DHCPOptions{
+		OptionsHash: keaconfig.NewHasher().Hash(convertedOptions),
+		Options:     r.unflattenDHCPOptions(convertedOptions, "", 0),
+	}
+	return class
+}
+
+// Converts the client class usage fetched from the database to the format
+// used in REST API.
+func (r *RestAPI) convertClientClassUsageToRestAPI(dbUsage *dbmodel.ClientClassUsage) *models.ClientClassUsage {
+	usage := &models.ClientClassUsage{
+		Subnets:               []*models.Subnet{},
+		Pools:                 []*models.ClientClassPoolUsage{},
+		PrefixDelegationPools: []*models.ClientClassPoolUsage{},
+		Hosts:                 []*models.Host{},
+	}
+	for _, subnet := range dbUsage.Subnets {
+		usage.Subnets = append(usage.Subnets, &models.Subnet{
+			ID:     subnet.ID,
+			Subnet: subnet.Prefix,
+		})
+	}
+	for _, pool := range dbUsage.AddressPools {
+		poolUsage := &models.ClientClassPoolUsage{
+			Pool: pool.LowerBound + "-" + pool.UpperBound,
+		}
+		if pool.LocalSubnet != nil && pool.LocalSubnet.Subnet != nil {
+			poolUsage.SubnetID = pool.LocalSubnet.Subnet.ID
+			poolUsage.Subnet = pool.LocalSubnet.Subnet.Prefix
+		}
+		usage.Pools = append(usage.Pools, poolUsage)
+	}
+	for _, pool := range dbUsage.PrefixPools {
+		poolUsage := &models.ClientClassPoolUsage{
+			Pool: pool.Prefix,
+		}
+		if pool.LocalSubnet != nil && pool.LocalSubnet.Subnet != nil {
+			poolUsage.SubnetID = pool.LocalSubnet.Subnet.ID
+			poolUsage.Subnet = pool.LocalSubnet.Subnet.Prefix
+		}
+		usage.PrefixDelegationPools = append(usage.PrefixDelegationPools, poolUsage)
+	}
+	for i := range dbUsage.Hosts {
+		usage.Hosts = append(usage.Hosts, r.convertHostFromRestAPI(&dbUsage.Hosts[i]))
+	}
+	return usage
+}
+
+// Converts the client class from the format used in REST API to the
+// database model. The daemon must be specified to convert the DHCP
+// options of the class.
+func (r *RestAPI) convertClientClassFromRestAPI(restClass *models.ClientClass, daemon *dbmodel.Daemon) (*dbmodel.ClientClass, error) {
+	if restClass.Name == nil || len(*restClass.Name) == 0 {
+		return nil, errors.New("client class name must not be empty")
+	}
+	params := &keaconfig.ClientClass{
+		ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+			PreferredLifetimeParameters: keaconfig.PreferredLifetimeParameters{
+				PreferredLifetime:    restClass.PreferredLifetime,
+				MinPreferredLifetime: restClass.MinPreferredLifetime,
+				MaxPreferredLifetime: restClass.MaxPreferredLifetime,
+			},
+			ValidLifetimeParameters: keaconfig.ValidLifetimeParameters{
+				ValidLifetime:    restClass.ValidLifetime,
+				MinValidLifetime: restClass.MinValidLifetime,
+				MaxValidLifetime: restClass.MaxValidLifetime,
+			},
+			Name:          *restClass.Name,
+			OfferLifetime: restClass.OfferLifetime,
+		},
+	}
+	if len(restClass.Test) > 0 {
+		params.Test = storkutil.Ptr(restClass.Test)
+	}
+	if len(restClass.TemplateTest) > 0 {
+		params.TemplateTest = storkutil.Ptr(restClass.TemplateTest)
+	}
+	if restClass.OnlyInAdditionalList {
+		params.OnlyInAdditionalList = storkutil.Ptr(true)
+	}
+	if len(restClass.NextServer) > 0 {
+		params.NextServer = storkutil.Ptr(restClass.NextServer)
+	}
+	if len(restClass.ServerHostname) > 0 {
+		params.ServerHostname = storkutil.Ptr(restClass.ServerHostname)
+	}
+	if len(restClass.BootFileName) > 0 {
+		params.BootFileName = storkutil.Ptr(restClass.BootFileName)
+	}
+	if restClass.UserContext != nil {
+		userContext, ok := restClass.UserContext.(map[string]any)
+		if !ok {
+			return nil, errors.New("client class user context must be a map")
+		}
+		params.UserContext = userContext
+	}
+	if restClass.Options != nil {
+		options, err := r.flattenDHCPOptions("", restClass.Options.Options, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range options {
+			singleOption, err := keaconfig.CreateSingleOptionData(daemon.ID, r.DHCPOptionDefinitionLookup, option)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "problem with creating Kea representation of the DHCP option (code: %d, space: %s)", option.Code, option.Space)
+			}
+			params.OptionData = append(params.OptionData, *singleOption)
+		}
+	}
+	class := &dbmodel.ClientClass{
+		ID:            restClass.ID,
+		DaemonID:      daemon.ID,
+		Daemon:        daemon,
+		Name:          *restClass.Name,
+		KeaParameters: params,
+	}
+	return class, nil
+}
+
+// Get list of DHCP client classes. The list can be filtered by daemon ID and text.
+func (r *RestAPI) GetClientClasses(ctx context.Context, params dhcp.GetClientClassesParams) middleware.Responder {
+	var start int64
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	var limit int64 = 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	var daemonID int64
+	if params.DaemonID != nil {
+		daemonID = *params.DaemonID
+	}
+
+	dbClasses, total, err := dbmodel.GetClientClassesByPage(r.DB, start, limit, daemonID, params.Text)
+	if err != nil {
+		msg := "Cannot get client classes from db"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetClientClassesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	classes := &models.ClientClasses{
+		Items: []*models.ClientClass{},
+		Total: total,
+	}
+	for i := range dbClasses {
+		classes.Items = append(classes.Items, r.convertClientClassToRestAPI(&dbClasses[i]))
+	}
+	rsp := dhcp.NewGetClientClassesOK().WithPayload(classes)
+	return rsp
+}
+
+// Returns the client class with its definition and the subnets, pools and
+// host reservations using the class in the daemon.
+func (r *RestAPI) GetClientClass(ctx context.Context, params dhcp.GetClientClassParams) middleware.Responder {
+	dbClass, err := dbmodel.GetClientClassByID(r.DB, params.ID)
+	if err != nil {
+		// Error while communicating with the database.
+		msg := fmt.Sprintf("Problem fetching client class with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbClass == nil {
+		// Client class not found.
+		msg := fmt.Sprintf("Cannot find client class with ID %d", params.ID)
+		log.Error(msg)
+		rsp := dhcp.NewGetClientClassDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	dbUsage, err := dbmodel.GetClientClassUsage(r.DB, dbClass.DaemonID, dbClass.Name)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching usage of the client class with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	class := r.convertClientClassToRestAPI(dbClass)
+	class.Usage = r.convertClientClassUsageToRestAPI(dbUsage)
+	rsp := dhcp.NewGetClientClassOK().WithPayload(class)
+	return rsp
+}
+
+// Common function that implements the POST calls to apply and commit a new
+// or updated client class. The transactionID is the identifier of the
+// current configuration transaction. The applyFunc is one of the
+// ApplyClientClassAdd or ApplyClientClassUpdate functions of the Kea config
+// module. The daemonID is the ID of the daemon owning the class. This
+// function returns the HTTP error code if an error occurs or 0 when there is
+// no error. It also returns the committed class and an error string to be
+// included in the HTTP response.
+func (r *RestAPI) commonCreateOrUpdateClientClassSubmit(ctx context.Context, transactionID int64, deadline *strfmt.DateTime, restClass *models.ClientClass, daemonID int64, applyFunc func(context.Context, *dbmodel.ClientClass) (context.Context, error)) (int, *dbmodel.ClientClass, string) {
+	// Make sure that the client class information is present.
+	if restClass == nil {
+		msg := "Client class information not specified"
+		log.Errorf("Problem with submitting a client class because the client class information is missing")
+		return http.StatusBadRequest, nil, msg
+	}
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, user.ID)
+	if cctx == nil {
+		msg := "Transaction expired for the client class update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return http.StatusNotFound, nil, msg
+	}
+	daemon, err := dbmodel.GetKeaDaemonByID(r.DB, daemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with fetching daemon with ID %d from the database", daemonID)
+		log.WithError(err).Error(msg)
+		return http.StatusInternalServerError, nil, msg
+	}
+	if daemon == nil {
+		msg := fmt.Sprintf("Specified client class is associated with daemon %d that no longer exists", daemonID)
+		log.Error(msg)
+		return http.StatusNotFound, nil, msg
+	}
+	// Convert client class information from REST API to database format.
+	class, err := r.convertClientClassFromRestAPI(restClass, daemon)
+	if err != nil {
+		msg := fmt.Sprintf("Error parsing specified client class: %s", err)
+		log.WithError(err).Error(msg)
+		return http.StatusBadRequest, nil, msg
+	}
+	// Apply the client class information (create Kea commands).
+	cctx, err = applyFunc(cctx, class)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with applying client class information: %s", err)
+		log.WithError(err).Error(msg)
+		return http.StatusInternalServerError, nil, msg
+	}
+	// Send the commands to Kea servers or schedule them.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, deadline, "client class information")
+	if code != 0 {
+		return code, nil, msg
+	}
+	if isConfigChangeCommitted(cctx) {
+		if class.ID == 0 {
+			recipe, err := config.GetRecipeForUpdate[kea.ConfigRecipe](cctx, 0)
+			if err != nil {
+				msg := "Problem recovering client class ID from the context"
+				log.WithError(err).Error(msg)
+				return http.StatusInternalServerError, nil, msg
+			}
+			if recipe.ClientClassID != nil {
+				class.ID = *recipe.ClientClassID
+			}
+			r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} added client class %s to {daemon}", class.Name), user, class.Daemon)
+		} else {
+			r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} updated client class %s in {daemon}", class.Name), user, class.Daemon)
+		}
+	}
+	// Everything ok. Cleanup and send OK to the client.
+	r.ConfigManager.Done(cctx)
+	return 0, class, ""
+}
+
+// Common function that implements the DELETE calls to cancel adding new
+// or updating a client class. It removes the specified transaction from the
+// config manager, if the transaction exists. It returns the HTTP error code
+// if an error occurs or 0 when there is no error. In addition it returns an
+// error string to be included in the HTTP response or an empty string if there
+// is no error.
+func (r *RestAPI) commonCreateOrUpdateClientClassDelete(ctx context.Context, transactionID int64) (int, string) {
+	// Retrieve the context from the config manager.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, _ := r.ConfigManager.RecoverContext(transactionID, user.ID)
+	if cctx == nil {
+		msg := "Transaction expired for the client class update"
+		log.Errorf("Problem with recovering transaction context for transaction ID %d and user ID %d", transactionID, user.ID)
+		return http.StatusNotFound, msg
+	}
+	r.ConfigManager.Done(cctx)
+	return 0, ""
+}
+
+// Implements the POST call to create new transaction for adding a new
+// client class (client-classes/new/transaction).
+func (r *RestAPI) CreateClientClassBegin(ctx context.Context, params dhcp.CreateClientClassBeginParams) middleware.Responder {
+	// A list of Kea DHCP daemons will be needed in the user form,
+	// so the user can select which server the class is sent to.
+	daemons, err := dbmodel.GetKeaDHCPDaemons(r.DB)
+	if err != nil {
+		msg := "Problem with fetching Kea daemons from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Keep daemons that can alter client classes with class_cmds or
+	// with cb_cmds in the config backend.
+	respDaemons := []*models.KeaDaemon{}
+	for i := range daemons {
+		if daemons[i].KeaDaemon == nil || daemons[i].KeaDaemon.Config == nil {
+			continue
+		}
+		if _, _, exists := daemons[i].KeaDaemon.Config.GetHookLibrary("libdhcp_class_cmds"); exists || daemons[i].KeaDaemon.Config.IsConfigBackendAltering() {
+			respDaemons = append(respDaemons, r.keaDaemonToRestAPI(&daemons[i]))
+		}
+	}
+	// If there are no daemons capable of altering client classes there is
+	// no way to proceed, so we don't begin a transaction.
+	if len(respDaemons) == 0 {
+		msg := "Unable to begin transaction because there are no Kea servers with class_cmds hook library available"
+		log.Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin client class add transaction.
+	if cctx, err = r.ConfigManager.GetKeaModule().BeginClientClassAdd(cctx); err != nil {
+		msg := "Problem with initializing transaction for creating client class"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to create a client class"
+		log.Error(msg)
+		rsp := dhcp.NewCreateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return transaction ID and daemons to the user.
+	contents := &models.CreateClientClassBeginResponse{
+		ID:      cctxID,
+		Daemons: respDaemons,
+	}
+	rsp := dhcp.NewCreateClientClassBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits a new client class
+// (client-classes/new/transaction/{id}/submit).
+func (r *RestAPI) CreateClientClassSubmit(ctx context.Context, params dhcp.CreateClientClassSubmitParams) middleware.Responder {
+	var daemonID int64
+	if params.ClientClass != nil {
+		daemonID = params.ClientClass.DaemonID
+	}
+	code, class, msg := r.commonCreateOrUpdateClientClassSubmit(ctx, params.ID, params.Deadline, params.ClientClass, daemonID, r.ConfigManager.GetKeaModule().ApplyClientClassAdd)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateClientClassSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	contents := &models.CreateClientClassSubmitResponse{
+		ClientClassID: class.ID,
+	}
+	rsp := dhcp.NewCreateClientClassSubmitOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the DELETE call to cancel creating a client class
+// (client-classes/new/transaction/{id}). It removes the specified
+// transaction from the config manager, if the transaction exists.
+func (r *RestAPI) CreateClientClassDelete(ctx context.Context, params dhcp.CreateClientClassDeleteParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateClientClassDelete(ctx, params.ID); code != 0 {
+		// Error case.
+		rsp := dhcp.NewCreateClientClassDeleteDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewCreateClientClassDeleteOK()
+	return rsp
+}
+
+// Implements the POST call to create new transaction for updating an
+// existing client class (client-classes/{clientClassId}/transaction).
+func (r *RestAPI) UpdateClientClassBegin(ctx context.Context, params dhcp.UpdateClientClassBeginParams) middleware.Responder {
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Begin client class update transaction. It retrieves current client
+	// class information and locks the daemon for updates.
+	cctx, err = r.ConfigManager.GetKeaModule().BeginClientClassUpdate(cctx, params.ClientClassID)
+	if err != nil {
+		var (
+			classNotFound      *config.ClientClassNotFoundError
+			lock               *config.LockError
+			hooksNotConfigured *config.NoClassCmdsHookError
+		)
+		switch {
+		case errors.As(err, &classNotFound):
+			// Failed to find client class.
+			msg := fmt.Sprintf("Unable to edit the client class with ID %d because it cannot be found", params.ClientClassID)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &lock):
+			// Failed to lock daemons.
+			msg := fmt.Sprintf("Unable to edit the client class with ID %d because it may be currently edited by another user", params.ClientClassID)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusLocked).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &hooksNotConfigured):
+			// Lack of the class_cmds hook.
+			msg := "Unable to update client class configuration because the daemon lacks libdhcp_class_cmds hook library"
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		default:
+			// Other error.
+			msg := fmt.Sprintf("Problem with initializing transaction for an update of the client class with ID %d", params.ClientClassID)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+	}
+	state, _ := config.GetTransactionState[kea.ConfigRecipe](cctx)
+	class := state.Updates[0].Recipe.ClientClassBeforeUpdate
+
+	// Retrieve the generated context ID.
+	cctxID, ok := config.GetValueAsInt64(cctx, config.ContextIDKey)
+	if !ok {
+		msg := "problem with retrieving context ID for a transaction to update a client class"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateClientClassBeginDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Remember the context, i.e. new transaction has been successfully created.
+	_ = r.ConfigManager.RememberContext(cctx, time.Minute*10)
+
+	// Return transaction ID and the client class to the user.
+	contents := &models.UpdateClientClassBeginResponse{
+		ID:          cctxID,
+		ClientClass: r.convertClientClassToRestAPI(class),
+	}
+	rsp := dhcp.NewUpdateClientClassBeginOK().WithPayload(contents)
+	return rsp
+}
+
+// Implements the POST call and commits an updated client class
+// (client-classes/{clientClassId}/transaction/{id}/submit).
+func (r *RestAPI) UpdateClientClassSubmit(ctx context.Context, params dhcp.UpdateClientClassSubmitParams) middleware.Responder {
+	dbClass, err := dbmodel.GetClientClassByID(r.DB, params.ClientClassID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching client class with ID %d from db", params.ClientClassID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateClientClassSubmitDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbClass == nil {
+		msg := fmt.Sprintf("Cannot find a client class with ID %d", params.ClientClassID)
+		log.Error(msg)
+		rsp := dhcp.NewUpdateClientClassSubmitDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	code, _, msg := r.commonCreateOrUpdateClientClassSubmit(ctx, params.ID, params.Deadline, params.ClientClass, dbClass.DaemonID, r.ConfigManager.GetKeaModule().ApplyClientClassUpdate)
+	if code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateClientClassSubmitDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	rsp := dhcp.NewUpdateClientClassSubmitOK()
+	return rsp
+}
+
+// Implements the DELETE call to cancel updating a client class
+// (client-classes/{clientClassId}/transaction/{id}). It removes the
+// specified transaction from the config manager, if the transaction exists.
+func (r *RestAPI) UpdateClientClassDelete(ctx context.Context, params dhcp.UpdateClientClassDeleteParams) middleware.Responder {
+	if code, msg := r.commonCreateOrUpdateClientClassDelete(ctx, params.ID); code != 0 {
+		// Error case.
+		rsp := dhcp.NewUpdateClientClassDeleteDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewUpdateClientClassDeleteOK()
+	return rsp
+}
+
+// Implements the DELETE call for a client class (client-classes/{id}). It
+// sends the class-del command to the Kea server owning the class. Similarly
+// to deleting a subnet, it is not transactional.
+func (r *RestAPI) DeleteClientClass(ctx context.Context, params dhcp.DeleteClientClassParams) middleware.Responder {
+	dbClass, err := dbmodel.GetClientClassByID(r.DB, params.ID)
+	if err != nil {
+		// Error while communicating with the database.
+		msg := fmt.Sprintf("Problem fetching client class with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbClass == nil {
+		// Client class not found.
+		msg := fmt.Sprintf("Cannot find a client class with ID %d", params.ID)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for deleting the client class"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteClientClassDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea commands to delete the client class.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyClientClassDelete(cctx, dbClass)
+	if err != nil {
+		code := http.StatusInternalServerError
+		msg := "Problem with preparing commands for deleting the client class"
+		var hooksNotConfigured *config.NoClassCmdsHookError
+		if errors.As(err, &hooksNotConfigured) {
+			code = http.StatusBadRequest
+			msg = "Unable to delete the client class because the daemon lacks libdhcp_class_cmds hook library"
+		}
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteClientClassDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the commands to Kea servers or submit them for approval.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, nil, "client class deletion")
+	if code != 0 {
+		rsp := dhcp.NewDeleteClientClassDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if isConfigChangeCommitted(cctx) {
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} deleted client class %s from {daemon}", dbClass.Name), user, dbClass.Daemon)
+	}
+
+	// Send OK to the client.
+	rsp := dhcp.NewDeleteClientClassOK()
+	return rsp
+}
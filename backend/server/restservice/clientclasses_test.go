@@ -0,0 +1,443 @@
+package restservice
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/datamodel/protocoltype"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds a machine with a DHCP daemon having the libdhcp_class_cmds hook
+// library and two client classes.
+func addTestClassCmdsDaemon(t *testing.T, db *dbops.PgDB, hookLibrary string) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   "machine",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.DHCPv4, true, []*dbmodel.AccessPoint{
+		{
+			Type:     dbmodel.AccessPointControl,
+			Address:  "localhost",
+			Port:     8000,
+			Protocol: protocoltype.HTTPS,
+		},
+	})
+	err = daemon.SetKeaConfigFromJSON([]byte(`{
+		"Dhcp4": {
+			"client-classes": [
+				{
+					"name": "foo",
+					"test": "member('ALL')",
+					"next-server": "192.0.2.1"
+				},
+				{
+					"name": "bar",
+					"test": "member('KNOWN')",
+					"comment": "baz"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "` + hookLibrary + `"
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	err = dbmodel.CommitClientClassesIntoDB(db, daemon.ID, daemon.KeaDaemon.Config.GetClientClasses())
+	require.NoError(t, err)
+	return daemon
+}
+
+// Test conversion of the client class between the database model and
+// the REST API format.
+func TestConvertClientClass(t *testing.T) {
+	rapi := &RestAPI{}
+	daemon := &dbmodel.Daemon{
+		ID:   1,
+		Name: daemonname.DHCPv4,
+	}
+	dbClass := &dbmodel.ClientClass{
+		ID:       2,
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Name:     "foo",
+		KeaParameters: &keaconfig.ClientClass{
+			ClientClassKnownParameters: keaconfig.ClientClassKnownParameters{
+				ValidLifetimeParameters: keaconfig.ValidLifetimeParameters{
+					ValidLifetime: storkutil.Ptr(int64(3600)),
+				},
+				Name:           "foo",
+				Test:           storkutil.Ptr("member('ALL')"),
+				OnlyIfRequired: storkutil.Ptr(true),
+				NextServer:     storkutil.Ptr("192.0.2.1"),
+				BootFileName:   storkutil.Ptr("/dev/null"),
+				OfferLifetime:  storkutil.Ptr(int64(60)),
+				UserContext: map[string]any{
+					"comment": "bar",
+				},
+			},
+		},
+	}
+	restClass := rapi.convertClientClassToRestAPI(dbClass)
+	require.NotNil(t, restClass)
+	require.EqualValues(t, 2, restClass.ID)
+	require.EqualValues(t, 1, restClass.DaemonID)
+	require.Equal(t, "foo", *restClass.Name)
+	require.Equal(t, "member('ALL')", restClass.Test)
+	require.Empty(t, restClass.TemplateTest)
+	require.True(t, restClass.OnlyInAdditionalList)
+	require.Equal(t, "192.0.2.1", restClass.NextServer)
+	require.Empty(t, restClass.ServerHostname)
+	require.Equal(t, "/dev/null", restClass.BootFileName)
+	require.EqualValues(t, 3600, *restClass.ValidLifetime)
+	require.Nil(t, restClass.PreferredLifetime)
+	require.EqualValues(t, 60, *restClass.OfferLifetime)
+	require.NotNil(t, restClass.Options)
+	require.Empty(t, restClass.Options.Options)
+
+	convertedClass, err := rapi.convertClientClassFromRestAPI(restClass, daemon)
+	require.NoError(t, err)
+	require.NotNil(t, convertedClass)
+	require.EqualValues(t, 2, convertedClass.ID)
+	require.Equal(t, daemon, convertedClass.Daemon)
+	require.Equal(t, "foo", convertedClass.Name)
+	require.NotNil(t, convertedClass.KeaParameters)
+	require.Equal(t, "foo", convertedClass.KeaParameters.Name)
+	require.Equal(t, "member('ALL')", convertedClass.KeaParameters.GetTest())
+	require.Nil(t, convertedClass.KeaParameters.TemplateTest)
+	require.True(t, convertedClass.KeaParameters.IsOnlyInAdditionalList())
+	require.Equal(t, "192.0.2.1", *convertedClass.KeaParameters.NextServer)
+	require.Nil(t, convertedClass.KeaParameters.ServerHostname)
+	require.EqualValues(t, 3600, *convertedClass.KeaParameters.ValidLifetime)
+	require.EqualValues(t, 60, *convertedClass.KeaParameters.OfferLifetime)
+	require.Equal(t, "bar", convertedClass.KeaParameters.UserContext["comment"])
+}
+
+// Test that converting the client class without a name fails.
+func TestConvertClientClassFromRestAPINoName(t *testing.T) {
+	rapi := &RestAPI{}
+	daemon := &dbmodel.Daemon{
+		ID:   1,
+		Name: daemonname.DHCPv4,
+	}
+	_, err := rapi.convertClientClassFromRestAPI(&models.ClientClass{}, daemon)
+	require.Error(t, err)
+
+	_, err = rapi.convertClientClassFromRestAPI(&models.ClientClass{
+		Name:        storkutil.Ptr("foo"),
+		UserContext: "bar",
+	}, daemon)
+	require.Error(t, err)
+}
+
+// Test getting the list of client classes and a single client class.
+func TestGetClientClasses(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.GetClientClasses(ctx, dhcp.GetClientClassesParams{})
+	require.IsType(t, &dhcp.GetClientClassesOK{}, rsp)
+	okRsp := rsp.(*dhcp.GetClientClassesOK)
+	require.EqualValues(t, 2, okRsp.Payload.Total)
+	require.Len(t, okRsp.Payload.Items, 2)
+	require.Equal(t, "bar", *okRsp.Payload.Items[0].Name)
+	require.Equal(t, "foo", *okRsp.Payload.Items[1].Name)
+	require.Equal(t, daemon.ID, okRsp.Payload.Items[1].DaemonID)
+	require.Equal(t, "192.0.2.1", okRsp.Payload.Items[1].NextServer)
+
+	// Filter by text.
+	rsp = rapi.GetClientClasses(ctx, dhcp.GetClientClassesParams{
+		Text: storkutil.Ptr("KNOWN"),
+	})
+	require.IsType(t, &dhcp.GetClientClassesOK{}, rsp)
+	okRsp = rsp.(*dhcp.GetClientClassesOK)
+	require.EqualValues(t, 1, okRsp.Payload.Total)
+	require.Equal(t, "bar", *okRsp.Payload.Items[0].Name)
+
+	// Get a single class.
+	rsp = rapi.GetClientClass(ctx, dhcp.GetClientClassParams{
+		ID: okRsp.Payload.Items[0].ID,
+	})
+	require.IsType(t, &dhcp.GetClientClassOK{}, rsp)
+	classRsp := rsp.(*dhcp.GetClientClassOK)
+	require.Equal(t, "bar", *classRsp.Payload.Name)
+	require.NotEmpty(t, classRsp.Payload.DaemonLabel)
+	require.NotNil(t, classRsp.Payload.Usage)
+	require.Empty(t, classRsp.Payload.Usage.Subnets)
+	require.Empty(t, classRsp.Payload.Usage.Hosts)
+
+	// Get non-existing class.
+	rsp = rapi.GetClientClass(ctx, dhcp.GetClientClassParams{
+		ID: 12345,
+	})
+	require.IsType(t, &dhcp.GetClientClassDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.GetClientClassDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test the transaction adding a new client class.
+func TestCreateClientClassBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginOK{}, rsp)
+	beginRsp := rsp.(*dhcp.CreateClientClassBeginOK)
+	require.NotZero(t, beginRsp.Payload.ID)
+	require.Len(t, beginRsp.Payload.Daemons, 1)
+	require.Equal(t, daemon.ID, beginRsp.Payload.Daemons[0].ID)
+
+	rsp = rapi.CreateClientClassSubmit(ctx, dhcp.CreateClientClassSubmitParams{
+		ID: beginRsp.Payload.ID,
+		ClientClass: &models.ClientClass{
+			DaemonID:      daemon.ID,
+			Name:          storkutil.Ptr("baz"),
+			Test:          "option[61].hex == 0x01",
+			ValidLifetime: storkutil.Ptr(int64(1200)),
+		},
+	})
+	require.IsType(t, &dhcp.CreateClientClassSubmitOK{}, rsp)
+	submitRsp := rsp.(*dhcp.CreateClientClassSubmitOK)
+	require.NotZero(t, submitRsp.Payload.ClientClassID)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "class-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "baz",
+					"test": "option[61].hex == 0x01",
+					"valid-lifetime": 1200
+				}
+			]
+		}
+	}`, string(commandMarshaled))
+	require.EqualValues(t, "config-write", fa.RecordedCommands[1].GetCommand())
+
+	class, err := dbmodel.GetClientClassByID(db, submitRsp.Payload.ClientClassID)
+	require.NoError(t, err)
+	require.NotNil(t, class)
+	require.Equal(t, "baz", class.Name)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "added client class baz")
+
+	// The transaction has been completed, so it can't be cancelled.
+	rsp = rapi.CreateClientClassDelete(ctx, dhcp.CreateClientClassDeleteParams{
+		ID: beginRsp.Payload.ID,
+	})
+	require.IsType(t, &dhcp.CreateClientClassDeleteDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.CreateClientClassDeleteDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test that the transaction adding a client class is not started when
+// there are no servers with the class_cmds hook library.
+func TestCreateClientClassBeginNoServers(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_ = addTestClassCmdsDaemon(t, db, "libdhcp_lease_cmds.so")
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.CreateClientClassBeginDefault)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	require.Equal(t, "Unable to begin transaction because there are no Kea servers with class_cmds hook library available", *defaultRsp.Payload.Message)
+}
+
+// Test the transaction updating an existing client class.
+func TestUpdateClientClassBeginSubmit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+	existingClass, err := dbmodel.GetClientClassByDaemonIDAndName(db, daemon.ID, "bar")
+	require.NoError(t, err)
+	require.NotNil(t, existingClass)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		ClientClassID: existingClass.ID,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginOK{}, rsp)
+	beginRsp := rsp.(*dhcp.UpdateClientClassBeginOK)
+	require.NotZero(t, beginRsp.Payload.ID)
+	require.NotNil(t, beginRsp.Payload.ClientClass)
+	require.Equal(t, "member('KNOWN')", beginRsp.Payload.ClientClass.Test)
+
+	// Beginning another transaction for the same class should fail because
+	// the daemon is locked.
+	rsp = rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		ClientClassID: existingClass.ID,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.UpdateClientClassBeginDefault)
+	require.Equal(t, http.StatusLocked, getStatusCode(*defaultRsp))
+
+	restClass := beginRsp.Payload.ClientClass
+	restClass.Test = "member('UNKNOWN')"
+	rsp = rapi.UpdateClientClassSubmit(ctx, dhcp.UpdateClientClassSubmitParams{
+		ClientClassID: existingClass.ID,
+		ID:            beginRsp.Payload.ID,
+		ClientClass:   restClass,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassSubmitOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "class-update",
+		"service": ["dhcp4"],
+		"arguments": {
+			"client-classes": [
+				{
+					"name": "bar",
+					"test": "member('UNKNOWN')",
+					"comment": "baz"
+				}
+			]
+		}
+	}`, string(commandMarshaled))
+
+	class, err := dbmodel.GetClientClassByID(db, existingClass.ID)
+	require.NoError(t, err)
+	require.NotNil(t, class)
+	require.Equal(t, "member('UNKNOWN')", class.KeaParameters.GetTest())
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "updated client class bar")
+}
+
+// Test that updating a non-existing client class fails.
+func TestUpdateClientClassBeginNonExisting(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.UpdateClientClassBegin(ctx, dhcp.UpdateClientClassBeginParams{
+		ClientClassID: 12345,
+	})
+	require.IsType(t, &dhcp.UpdateClientClassBeginDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.UpdateClientClassBeginDefault)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+}
+
+// Test deleting a client class.
+func TestDeleteClientClass(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+	existingClass, err := dbmodel.GetClientClassByDaemonIDAndName(db, daemon.ID, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, existingClass)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.DeleteClientClass(ctx, dhcp.DeleteClientClassParams{
+		ID: existingClass.ID,
+	})
+	require.IsType(t, &dhcp.DeleteClientClassOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "class-del",
+		"service": ["dhcp4"],
+		"arguments": {
+			"name": "foo"
+		}
+	}`, string(commandMarshaled))
+
+	class, err := dbmodel.GetClientClassByID(db, existingClass.ID)
+	require.NoError(t, err)
+	require.Nil(t, class)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "deleted client class foo")
+
+	// Deleting the class again should fail.
+	rsp = rapi.DeleteClientClass(ctx, dhcp.DeleteClientClassParams{
+		ID: existingClass.ID,
+	})
+	require.IsType(t, &dhcp.DeleteClientClassDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.DeleteClientClassDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test that deleting a client class is submitted for approval instead of
+// being committed when the config change approval is enabled.
+func TestDeleteClientClassApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+	existingClass, err := dbmodel.GetClientClassByDaemonIDAndName(db, daemon.ID, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, existingClass)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.DeleteClientClass(ctx, dhcp.DeleteClientClassParams{
+		ID: existingClass.ID,
+	})
+	require.IsType(t, &dhcp.DeleteClientClassOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaClientClassDelete)
+
+	// The class should not be deleted until the request is approved.
+	class, err := dbmodel.GetClientClassByID(db, existingClass.ID)
+	require.NoError(t, err)
+	require.NotNil(t, class)
+
+	// Only the submission should be recorded.
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+}
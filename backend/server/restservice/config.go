@@ -15,7 +15,9 @@ import (
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/server/config"
 	"isc.org/stork/server/configreview"
+	"isc.org/stork/server/daemons/bind9"
 	"isc.org/stork/server/daemons/kea"
+	"isc.org/stork/server/daemons/pdns"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/gen/models"
 	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
@@ -211,7 +213,7 @@ func (r *RestAPI) GetDaemonConfigReports(ctx context.Context, params services.Ge
 // Begins daemon configuration review on demand.
 func (r *RestAPI) PutDaemonConfigReview(ctx context.Context, params services.PutDaemonConfigReviewParams) middleware.Responder {
 	// Try to get the daemon information from the database.
-	daemon, err := dbmodel.GetKeaDaemonByID(r.DB, params.ID)
+	daemon, err := dbmodel.GetDaemonByID(r.DB, params.ID)
 	if err != nil {
 		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.ID)
 		log.WithError(err).Error(msg)
@@ -229,16 +231,34 @@ func (r *RestAPI) PutDaemonConfigReview(ctx context.Context, params services.Put
 		})
 		return rsp
 	}
-	// Config review is currently only supported for Kea.
-	if daemon.KeaDaemon == nil {
-		msg := fmt.Sprintf("Daemon with ID %d is not a Kea daemon", params.ID)
+	// Config review is currently only supported for Kea, BIND 9 and PowerDNS.
+	if daemon.KeaDaemon == nil && daemon.Bind9Daemon == nil && daemon.PDNSDaemon == nil {
+		msg := fmt.Sprintf("Daemon with ID %d is neither a Kea, BIND 9 nor PowerDNS daemon", params.ID)
 		rsp := services.NewPutDaemonConfigReviewDefault(http.StatusBadRequest).WithPayload(&models.APIError{
 			Message: &msg,
 		})
 		return rsp
 	}
+	// The DNS server configurations are not stored in the database. They
+	// must be fetched from the agent.
+	switch {
+	case daemon.Bind9Daemon != nil:
+		err = bind9.GetDaemonConfig(ctx, r.Agents, daemon)
+	case daemon.PDNSDaemon != nil:
+		err = pdns.GetDaemonConfig(ctx, r.Agents, daemon)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get configuration of daemon with ID %d from the agent", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewPutDaemonConfigReviewDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
 	// Config must be present to perform the review.
-	if daemon.KeaDaemon.Config == nil {
+	if (daemon.KeaDaemon != nil && daemon.KeaDaemon.Config == nil) ||
+		(daemon.Bind9Daemon != nil && daemon.Bind9Daemon.Config == nil) ||
+		(daemon.PDNSDaemon != nil && daemon.PDNSDaemon.Config == nil) {
 		msg := fmt.Sprintf("Configuration not found for daemon with ID %d", params.ID)
 		rsp := services.NewPutDaemonConfigReviewDefault(http.StatusBadRequest).WithPayload(&models.APIError{
 			Message: &msg,
@@ -793,12 +813,10 @@ func (r *RestAPI) UpdateKeaGlobalParametersSubmit(ctx context.Context, params dh
 			return rsp
 		}
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with committing Kea config: %s", err)
-		log.WithError(err).Error(msg)
-		rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(http.StatusConflict).WithPayload(&models.APIError{
+	// Send the commands to Kea servers or schedule them.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, params.Deadline, "Kea config")
+	if code != 0 {
+		rsp := dhcp.NewUpdateKeaGlobalParametersSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
 		})
 		return rsp
@@ -989,9 +989,64 @@ func TestPutDaemonConfigReviewDatabaseError(t *testing.T) {
 	require.Equal(t, "Cannot get daemon with ID 1 from db", *defaultRsp.Payload.Message)
 }
 
-// Test that HTTP Bad Request status is returned as a result of requesting
-// a configuration review for a non-Kea daemon.
-func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
+// Test that the PowerDNS configuration is fetched from the agent and the
+// review is scheduled for the PowerDNS daemon.
+func TestPutDaemonConfigReviewPDNS(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	// Create PowerDNS daemon instance.
+	accessPoint := &dbmodel.AccessPoint{
+		Type:     dbmodel.AccessPointControl,
+		Address:  "1.2.3.4",
+		Port:     8081,
+		Key:      "abcd",
+		Protocol: protocoltype.HTTP,
+	}
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.PDNS, true, []*dbmodel.AccessPoint{accessPoint})
+
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	fd := &storktest.FakeDispatcher{}
+	rapi, err := NewRestAPI(dbSettings, db, fa, fd)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	params := services.PutDaemonConfigReviewParams{
+		ID: daemon.ID,
+	}
+
+	// The agent fails to return the configuration.
+	rsp := rapi.PutDaemonConfigReview(ctx, params)
+	require.IsType(t, &services.PutDaemonConfigReviewDefault{}, rsp)
+	defaultRsp := rsp.(*services.PutDaemonConfigReviewDefault)
+	require.NotNil(t, defaultRsp)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+	require.Equal(t, fmt.Sprintf("Cannot get configuration of daemon with ID %d from the agent", daemon.ID),
+		*defaultRsp.Payload.Message)
+	require.Empty(t, fd.CallLog)
+
+	// The agent returns the configuration.
+	fa.PDNSConfig = "api=yes\napi-key=********\nwebserver=yes\n"
+	rsp = rapi.PutDaemonConfigReview(ctx, params)
+	require.IsType(t, &services.PutDaemonConfigReviewAccepted{}, rsp)
+	require.Len(t, fd.CallLog, 1)
+	require.Equal(t, "BeginReview", fd.CallLog[0].CallName)
+}
+
+// Test that the BIND 9 configuration is fetched from the agent and the
+// review is scheduled for the BIND 9 daemon.
+func TestPutDaemonConfigReviewBind9(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
 	defer teardown()
 
@@ -1002,7 +1057,6 @@ func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
 	err := dbmodel.AddMachine(db, machine)
 	require.NoError(t, err)
 
-	// Create BIND9 daemon instance.
 	accessPoint := &dbmodel.AccessPoint{
 		Type:     dbmodel.AccessPointControl,
 		Address:  "1.2.3.4",
@@ -1025,13 +1079,20 @@ func TestPutDaemonConfigReviewNotKeaDaemon(t *testing.T) {
 	params := services.PutDaemonConfigReviewParams{
 		ID: daemon.ID,
 	}
+
+	// The agent returns no configuration.
 	rsp := rapi.PutDaemonConfigReview(ctx, params)
 	require.IsType(t, &services.PutDaemonConfigReviewDefault{}, rsp)
 	defaultRsp := rsp.(*services.PutDaemonConfigReviewDefault)
-	require.NotNil(t, defaultRsp)
 	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
-	require.Equal(t, fmt.Sprintf("Daemon with ID %d is not a Kea daemon", daemon.ID),
-		*defaultRsp.Payload.Message)
+	require.Empty(t, fd.CallLog)
+
+	// The agent returns the configuration.
+	fa.Bind9Config = `options { directory "/var/cache/bind"; };`
+	rsp = rapi.PutDaemonConfigReview(ctx, params)
+	require.IsType(t, &services.PutDaemonConfigReviewAccepted{}, rsp)
+	require.Len(t, fd.CallLog, 1)
+	require.Equal(t, "BeginReview", fd.CallLog[0].CallName)
 }
 
 // Test that HTTP Bad Request status is returned as a result of requesting
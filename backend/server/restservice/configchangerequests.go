@@ -0,0 +1,218 @@
+package restservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+)
+
+// Converts the config change request to the format used in REST API.
+// The recipes and the configuration differences are only included when
+// the includeDetails flag is set.
+func (r *RestAPI) convertConfigChangeRequestToRestAPI(request *dbmodel.ConfigChangeRequest, includeDetails bool) *models.ConfigChangeRequest {
+	restRequest := &models.ConfigChangeRequest{
+		ID:        request.ID,
+		CreatedAt: strfmt.DateTime(request.CreatedAt),
+		UserID:    request.UserID,
+		Status:    string(request.Status),
+		Comment:   request.Comment,
+		Error:     request.Error,
+		Updates:   convertConfigUpdatesToRestAPI(request.Updates, includeDetails),
+		Diff:      []*models.ConfigChangeDaemonDiff{},
+	}
+	if request.DeadlineAt != nil {
+		restRequest.DeadlineAt = (*strfmt.DateTime)(request.DeadlineAt)
+	}
+	if request.User != nil {
+		restRequest.UserLogin = request.User.Login
+	}
+	if request.ReviewerID != nil {
+		restRequest.ReviewerID = *request.ReviewerID
+	}
+	if request.Reviewer != nil {
+		restRequest.ReviewerLogin = request.Reviewer.Login
+	}
+	if request.ReviewedAt != nil {
+		restRequest.ReviewedAt = (*strfmt.DateTime)(request.ReviewedAt)
+	}
+	if !includeDetails {
+		return restRequest
+	}
+	for _, daemonDiff := range request.Diff {
+		restDaemonDiff := &models.ConfigChangeDaemonDiff{
+			DaemonID: daemonDiff.DaemonID,
+			Entries:  []*models.KeaConfigDiffEntry{},
+		}
+		for _, entry := range daemonDiff.Entries {
+			restDaemonDiff.Entries = append(restDaemonDiff.Entries, &models.KeaConfigDiffEntry{
+				Path:      entry.Path,
+				Operation: string(entry.Operation),
+				OldValue:  entry.OldValue,
+				NewValue:  entry.NewValue,
+			})
+		}
+		restRequest.Diff = append(restRequest.Diff, restDaemonDiff)
+	}
+	return restRequest
+}
+
+// Implements the GET call to list the config change requests
+// (config-change-requests).
+func (r *RestAPI) GetConfigChangeRequests(ctx context.Context, params dhcp.GetConfigChangeRequestsParams) middleware.Responder {
+	var start int64
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	var limit int64 = 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	var status *dbmodel.ConfigChangeRequestStatus
+	if params.Status != nil {
+		status = (*dbmodel.ConfigChangeRequestStatus)(params.Status)
+	}
+
+	dbRequests, total, err := dbmodel.GetConfigChangeRequestsByPage(r.DB, start, limit, status)
+	if err != nil {
+		msg := "Problem with fetching config change requests from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetConfigChangeRequestsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	requests := &models.ConfigChangeRequests{
+		Items: []*models.ConfigChangeRequest{},
+		Total: total,
+	}
+	for i := range dbRequests {
+		requests.Items = append(requests.Items, r.convertConfigChangeRequestToRestAPI(&dbRequests[i], false))
+	}
+	rsp := dhcp.NewGetConfigChangeRequestsOK().WithPayload(requests)
+	return rsp
+}
+
+// Implements the GET call to get the config change request by ID
+// (config-change-requests/{id}).
+func (r *RestAPI) GetConfigChangeRequest(ctx context.Context, params dhcp.GetConfigChangeRequestParams) middleware.Responder {
+	dbRequest, err := dbmodel.GetConfigChangeRequestByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with fetching config change request with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetConfigChangeRequestDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbRequest == nil {
+		msg := fmt.Sprintf("Cannot find config change request with ID %d", params.ID)
+		rsp := dhcp.NewGetConfigChangeRequestDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewGetConfigChangeRequestOK().WithPayload(r.convertConfigChangeRequestToRestAPI(dbRequest, true))
+	return rsp
+}
+
+// Common function that implements the PUT calls to approve or reject the
+// config change request. The request can only be reviewed by a member of
+// the approver group who hasn't submitted this request. The approved config
+// change is committed or scheduled. If the commit fails, the request is
+// marked as failed and it can be reviewed again. It returns the HTTP error
+// code if an error occurs or 0 when there is no error. It also returns an
+// error string to be included in the HTTP response.
+func (r *RestAPI) commonReviewConfigChangeRequest(ctx context.Context, requestID int64, status dbmodel.ConfigChangeRequestStatus, review *models.ConfigChangeRequestReview) (int, string) {
+	_, user := r.SessionManager.Logged(ctx)
+	if !user.InGroup(&dbmodel.SystemGroup{ID: dbmodel.ApproverGroupID}) {
+		msg := "Config change requests can only be reviewed by the members of the approver group"
+		log.Errorf("Problem with reviewing config change request %d by user %s: %s", requestID, user.Login, msg)
+		return http.StatusForbidden, msg
+	}
+	request, err := dbmodel.GetConfigChangeRequestByID(r.DB, requestID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with fetching config change request with ID %d from the database", requestID)
+		log.WithError(err).Error(msg)
+		return http.StatusInternalServerError, msg
+	}
+	if request == nil {
+		msg := fmt.Sprintf("Cannot find config change request with ID %d", requestID)
+		return http.StatusNotFound, msg
+	}
+	if request.UserID == user.ID {
+		msg := fmt.Sprintf("Config change request %d cannot be reviewed by the user who submitted it", requestID)
+		log.Error(msg)
+		return http.StatusForbidden, msg
+	}
+	var comment string
+	if review != nil {
+		comment = review.Comment
+	}
+	if err = dbmodel.SetConfigChangeRequestReviewed(r.DB, requestID, status, user.ID, comment); err != nil {
+		code := http.StatusInternalServerError
+		msg := fmt.Sprintf("Problem with reviewing config change request with ID %d", requestID)
+		if errors.Is(err, dbmodel.ErrNotExists) {
+			code = http.StatusConflict
+			msg = fmt.Sprintf("Config change request with ID %d has been already reviewed", requestID)
+		}
+		log.WithError(err).Error(msg)
+		return code, msg
+	}
+
+	submitter := "unknown user"
+	if request.User != nil {
+		submitter = request.User.Login
+	}
+	if status == dbmodel.ConfigChangeRequestStatusRejected {
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} rejected config change request %d submitted by %s", requestID, submitter), user)
+		return 0, ""
+	}
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} approved config change request %d submitted by %s", requestID, submitter), user)
+
+	// Only the approved config changes are sent to the daemons.
+	if err = r.ConfigManager.CommitApproved(request); err != nil {
+		msg := fmt.Sprintf("Problem with committing approved config change request %d: %s", requestID, err)
+		log.WithError(err).Error(msg)
+		r.EventCenter.AddErrorEvent(fmt.Sprintf("failed to commit config change request %d approved by {user}", requestID), user, err)
+		return http.StatusConflict, msg
+	}
+	return 0, ""
+}
+
+// Implements the PUT call to approve the config change request
+// (config-change-requests/{id}/approve).
+func (r *RestAPI) ApproveConfigChangeRequest(ctx context.Context, params dhcp.ApproveConfigChangeRequestParams) middleware.Responder {
+	if code, msg := r.commonReviewConfigChangeRequest(ctx, params.ID, dbmodel.ConfigChangeRequestStatusApproved, params.Review); code != 0 {
+		rsp := dhcp.NewApproveConfigChangeRequestDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewApproveConfigChangeRequestOK()
+	return rsp
+}
+
+// Implements the PUT call to reject the config change request
+// (config-change-requests/{id}/reject).
+func (r *RestAPI) RejectConfigChangeRequest(ctx context.Context, params dhcp.RejectConfigChangeRequestParams) middleware.Responder {
+	if code, msg := r.commonReviewConfigChangeRequest(ctx, params.ID, dbmodel.ConfigChangeRequestStatusRejected, params.Review); code != 0 {
+		rsp := dhcp.NewRejectConfigChangeRequestDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewRejectConfigChangeRequestOK()
+	return rsp
+}
@@ -0,0 +1,273 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"isc.org/stork/server/agentcomm"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Enables the config change approval and submits a transaction adding
+// a client class. It returns the ID of the submitted config change request.
+func submitClientClassForApproval(t *testing.T, db *dbops.PgDB, rapi *RestAPI, ctx context.Context) int64 {
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginOK{}, rsp)
+	beginRsp := rsp.(*dhcp.CreateClientClassBeginOK)
+
+	rsp = rapi.CreateClientClassSubmit(ctx, dhcp.CreateClientClassSubmitParams{
+		ID: beginRsp.Payload.ID,
+		ClientClass: &models.ClientClass{
+			DaemonID:      daemon.ID,
+			Name:          storkutil.Ptr("baz"),
+			ValidLifetime: storkutil.Ptr(int64(1200)),
+		},
+	})
+	require.IsType(t, &dhcp.CreateClientClassSubmitOK{}, rsp)
+
+	requests, total, err := dbmodel.GetConfigChangeRequestsByPage(db, 0, 0, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	return requests[0].ID
+}
+
+// Checks that the config change has been submitted for approval rather
+// than committed. There should be exactly one pending config change request
+// for the specified operation and no commands should be sent to the agents.
+func requireConfigChangeSubmitted(t *testing.T, db *dbops.PgDB, fa *agentcommtest.FakeAgents, operation dbmodel.ConfigOperation) {
+	require.Empty(t, fa.RecordedCommands)
+
+	requests, total, err := dbmodel.GetConfigChangeRequestsByPage(db, 0, 0, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusPending, requests[0].Status)
+	require.Len(t, requests[0].Updates, 1)
+	require.Equal(t, operation, requests[0].Updates[0].Operation)
+}
+
+// Logs in the default admin user. Unlike the users created ad hoc in the
+// tests, this user exists in the database, so the config change requests
+// can be associated with it.
+func loginDefaultAdmin(t *testing.T, db *dbops.PgDB, rapi *RestAPI, ctx context.Context) {
+	user, err := dbmodel.GetUserByID(db, 1)
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+}
+
+// Creates a user belonging to the admin and approver groups and logs
+// the user in.
+func loginApprover(t *testing.T, db *dbops.PgDB, rapi *RestAPI, ctx context.Context) *dbmodel.SystemUser {
+	approver := &dbmodel.SystemUser{
+		Login:    "approver",
+		Lastname: "approver",
+		Name:     "approver",
+		Groups: []*dbmodel.SystemGroup{
+			{ID: dbmodel.AdminGroupID},
+			{ID: dbmodel.ApproverGroupID},
+		},
+	}
+	_, err := dbmodel.CreateUser(db, approver)
+	require.NoError(t, err)
+
+	err = rapi.SessionManager.LoginHandler(ctx, approver)
+	require.NoError(t, err)
+	return approver
+}
+
+// Test that the submitted transaction is not committed when the approval
+// is required, and that it is committed when another user approves it.
+func TestApproveConfigChangeRequest(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	requestID := submitClientClassForApproval(t, db, rapi, ctx)
+
+	// No commands should be sent until the request is approved.
+	require.Empty(t, fa.RecordedCommands)
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+
+	// List the pending requests.
+	rsp := rapi.GetConfigChangeRequests(ctx, dhcp.GetConfigChangeRequestsParams{
+		Status: storkutil.Ptr(string(dbmodel.ConfigChangeRequestStatusPending)),
+	})
+	require.IsType(t, &dhcp.GetConfigChangeRequestsOK{}, rsp)
+	requests := rsp.(*dhcp.GetConfigChangeRequestsOK).Payload
+	require.EqualValues(t, 1, requests.Total)
+	require.Len(t, requests.Items, 1)
+	require.Equal(t, "test", requests.Items[0].UserLogin)
+	require.Len(t, requests.Items[0].Updates, 1)
+	require.Nil(t, requests.Items[0].Updates[0].Recipe)
+	require.Empty(t, requests.Items[0].Diff)
+
+	// Get the request with the differences.
+	rsp = rapi.GetConfigChangeRequest(ctx, dhcp.GetConfigChangeRequestParams{
+		ID: requestID,
+	})
+	require.IsType(t, &dhcp.GetConfigChangeRequestOK{}, rsp)
+	request := rsp.(*dhcp.GetConfigChangeRequestOK).Payload
+	require.Len(t, request.Diff, 1)
+	require.NotEmpty(t, request.Diff[0].Entries)
+	require.Equal(t, "/client-classes[name=baz]", request.Diff[0].Entries[0].Path)
+	require.Equal(t, "added", request.Diff[0].Entries[0].Operation)
+
+	// The user submitting the request is not an approver.
+	rsp = rapi.ApproveConfigChangeRequest(ctx, dhcp.ApproveConfigChangeRequestParams{
+		ID: requestID,
+	})
+	require.IsType(t, &dhcp.ApproveConfigChangeRequestDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.ApproveConfigChangeRequestDefault)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*defaultRsp))
+
+	// Approve the request as another user.
+	approver := loginApprover(t, db, rapi, ctx)
+	rsp = rapi.ApproveConfigChangeRequest(ctx, dhcp.ApproveConfigChangeRequestParams{
+		ID: requestID,
+		Review: &models.ConfigChangeRequestReview{
+			Comment: "looks good",
+		},
+	})
+	require.IsType(t, &dhcp.ApproveConfigChangeRequestOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	require.EqualValues(t, "class-add", fa.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, "config-write", fa.RecordedCommands[1].GetCommand())
+
+	require.Len(t, fec.Events, 2)
+	require.Contains(t, fec.Events[1].Text, "approved config change request")
+
+	dbRequest, err := dbmodel.GetConfigChangeRequestByID(db, requestID)
+	require.NoError(t, err)
+	require.NotNil(t, dbRequest)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusApproved, dbRequest.Status)
+	require.NotNil(t, dbRequest.ReviewerID)
+	require.Equal(t, approver.ID, *dbRequest.ReviewerID)
+	require.Equal(t, "looks good", dbRequest.Comment)
+	require.Empty(t, dbRequest.Error)
+
+	// The request can't be reviewed again.
+	rsp = rapi.RejectConfigChangeRequest(ctx, dhcp.RejectConfigChangeRequestParams{
+		ID: requestID,
+	})
+	require.IsType(t, &dhcp.RejectConfigChangeRequestDefault{}, rsp)
+	defaultRejectRsp := rsp.(*dhcp.RejectConfigChangeRequestDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRejectRsp))
+}
+
+// Test that the approved config change request is marked as failed when
+// committing the config change fails, and that it can be approved again.
+func TestApproveConfigChangeRequestCommitFailure(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Return an error in response to the first command.
+	fa := agentcommtest.NewFakeAgents(func(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []interface{}) {
+		if callNo == 0 {
+			mockStatusError(cmdResponses)
+		}
+	}, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	requestID := submitClientClassForApproval(t, db, rapi, ctx)
+
+	_ = loginApprover(t, db, rapi, ctx)
+	rsp := rapi.ApproveConfigChangeRequest(ctx, dhcp.ApproveConfigChangeRequestParams{
+		ID: requestID,
+	})
+	require.IsType(t, &dhcp.ApproveConfigChangeRequestDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.ApproveConfigChangeRequestDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+
+	require.Len(t, fec.Events, 3)
+	require.Contains(t, fec.Events[2].Text, "failed to commit config change request")
+
+	dbRequest, err := dbmodel.GetConfigChangeRequestByID(db, requestID)
+	require.NoError(t, err)
+	require.NotNil(t, dbRequest)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusFailed, dbRequest.Status)
+	require.NotEmpty(t, dbRequest.Error)
+
+	// Retry the commit by approving the request again.
+	rsp = rapi.ApproveConfigChangeRequest(ctx, dhcp.ApproveConfigChangeRequestParams{
+		ID: requestID,
+	})
+	require.IsType(t, &dhcp.ApproveConfigChangeRequestOK{}, rsp)
+
+	dbRequest, err = dbmodel.GetConfigChangeRequestByID(db, requestID)
+	require.NoError(t, err)
+	require.NotNil(t, dbRequest)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusApproved, dbRequest.Status)
+	require.Empty(t, dbRequest.Error)
+}
+
+// Test that the rejected config change request is not committed.
+func TestRejectConfigChangeRequest(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	requestID := submitClientClassForApproval(t, db, rapi, ctx)
+
+	_ = loginApprover(t, db, rapi, ctx)
+	rsp := rapi.RejectConfigChangeRequest(ctx, dhcp.RejectConfigChangeRequestParams{
+		ID: requestID,
+		Review: &models.ConfigChangeRequestReview{
+			Comment: "wrong lifetime",
+		},
+	})
+	require.IsType(t, &dhcp.RejectConfigChangeRequestOK{}, rsp)
+
+	require.Empty(t, fa.RecordedCommands)
+	require.Len(t, fec.Events, 2)
+	require.Contains(t, fec.Events[1].Text, "rejected config change request")
+
+	dbRequest, err := dbmodel.GetConfigChangeRequestByID(db, requestID)
+	require.NoError(t, err)
+	require.NotNil(t, dbRequest)
+	require.Equal(t, dbmodel.ConfigChangeRequestStatusRejected, dbRequest.Status)
+	require.Equal(t, "wrong lifetime", dbRequest.Comment)
+}
+
+// Test that reviewing a non-existing config change request returns an error.
+func TestReviewNonExistingConfigChangeRequest(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	_ = loginApprover(t, db, rapi, ctx)
+	rsp := rapi.ApproveConfigChangeRequest(ctx, dhcp.ApproveConfigChangeRequestParams{
+		ID: 123,
+	})
+	require.IsType(t, &dhcp.ApproveConfigChangeRequestDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.ApproveConfigChangeRequestDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+
+	rsp = rapi.GetConfigChangeRequest(ctx, dhcp.GetConfigChangeRequestParams{
+		ID: 123,
+	})
+	require.IsType(t, &dhcp.GetConfigChangeRequestDefault{}, rsp)
+	defaultGetRsp := rsp.(*dhcp.GetConfigChangeRequestDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultGetRsp))
+}
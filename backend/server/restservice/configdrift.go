@@ -0,0 +1,87 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+)
+
+// Write the running configuration of the Kea daemon to its configuration
+// file with the config-write command. It is refused while the daemon has
+// temporary log level changes because they would be written to the file
+// and persist after they are reverted.
+func (r *RestAPI) WriteDaemonConfig(ctx context.Context, params services.WriteDaemonConfigParams) middleware.Responder {
+	daemon, code, msg := r.getKeaDaemon(params.ID)
+	if daemon == nil {
+		rsp := services.NewWriteDaemonConfigDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	changes, err := dbmodel.GetKeaLogLevelChanges(r.DB, &daemon.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with fetching temporary log level changes for daemon with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if len(changes) > 0 {
+		msg := fmt.Sprintf("Unable to write the configuration of daemon with ID %d because it has temporary log level changes; revert them first", params.ID)
+		log.Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	if err := kea.WriteConfig(ctx, r.Agents, daemon); err != nil {
+		msg := fmt.Sprintf("Problem with writing the configuration of daemon with ID %d: %s", params.ID, err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewWriteDaemonConfigDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent("{user} wrote the running configuration of {daemon} to disk", user, daemon)
+
+	rsp := services.NewWriteDaemonConfigOK()
+	return rsp
+}
+
+// Reload the configuration of the Kea daemon from its configuration file
+// with the config-reload command.
+func (r *RestAPI) ReloadDaemonConfig(ctx context.Context, params services.ReloadDaemonConfigParams) middleware.Responder {
+	daemon, code, msg := r.getKeaDaemon(params.ID)
+	if daemon == nil {
+		rsp := services.NewReloadDaemonConfigDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	if err := kea.ReloadConfig(ctx, r.Agents, daemon); err != nil {
+		msg := fmt.Sprintf("Problem with reloading the configuration of daemon with ID %d: %s", params.ID, err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewReloadDaemonConfigDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent("{user} reloaded the configuration of {daemon} from disk", user, daemon)
+
+	rsp := services.NewReloadDaemonConfigOK()
+	return rsp
+}
@@ -0,0 +1,129 @@
+package restservice
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Mock function responding to a command with an error.
+func mockConfigCommandError(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	response := cmdResponses[0].(*keactrl.Response)
+	*response = keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseError,
+			Text:   "unable to open file",
+		},
+	}
+}
+
+// Test that the running Kea configuration is written to disk over the
+// REST API.
+func TestWriteDaemonConfig(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigSet, mockConfigCommandError)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.WriteDaemonConfig(ctx, services.WriteDaemonConfigParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.WriteDaemonConfigOK{}, rsp)
+	require.Len(t, fa.RecordedCommands, 1)
+	require.EqualValues(t, keactrl.ConfigWrite, fa.RecordedCommands[0].GetCommand())
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "wrote the running configuration")
+
+	// Kea returns an error.
+	rsp = rapi.WriteDaemonConfig(ctx, services.WriteDaemonConfigParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.WriteDaemonConfigDefault{}, rsp)
+	defaultRsp := rsp.(*services.WriteDaemonConfigDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+	require.Len(t, fec.Events, 1)
+
+	// Non-existing daemon.
+	rsp = rapi.WriteDaemonConfig(ctx, services.WriteDaemonConfigParams{
+		ID: daemon.ID + 1,
+	})
+	require.IsType(t, &services.WriteDaemonConfigDefault{}, rsp)
+	defaultRsp = rsp.(*services.WriteDaemonConfigDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	require.Len(t, fa.RecordedCommands, 2)
+}
+
+// Test that writing the running Kea configuration to disk is refused while
+// the daemon has temporary log level changes.
+func TestWriteDaemonConfigTemporaryLogLevel(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	now := storkutil.UTCNow()
+	err := dbmodel.AddKeaLogLevelChange(db, &dbmodel.KeaLogLevelChange{
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(time.Hour),
+		DaemonID:   daemon.ID,
+		LoggerName: "kea-dhcp4",
+		Severity:   "DEBUG",
+		DebugLevel: 99,
+	})
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewKeaFakeAgents()
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.WriteDaemonConfig(ctx, services.WriteDaemonConfigParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.WriteDaemonConfigDefault{}, rsp)
+	defaultRsp := rsp.(*services.WriteDaemonConfigDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+	require.Contains(t, *defaultRsp.Payload.Message, "temporary log level changes")
+	require.Empty(t, fa.RecordedCommands)
+	require.Empty(t, fec.Events)
+}
+
+// Test that the Kea configuration is reloaded from disk over the REST API.
+func TestReloadDaemonConfig(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigSet, mockConfigCommandError)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.ReloadDaemonConfig(ctx, services.ReloadDaemonConfigParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.ReloadDaemonConfigOK{}, rsp)
+	require.Len(t, fa.RecordedCommands, 1)
+	require.EqualValues(t, keactrl.ConfigReload, fa.RecordedCommands[0].GetCommand())
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "reloaded the configuration")
+
+	// Kea returns an error.
+	rsp = rapi.ReloadDaemonConfig(ctx, services.ReloadDaemonConfigParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.ReloadDaemonConfigDefault{}, rsp)
+	defaultRsp := rsp.(*services.ReloadDaemonConfigDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+	require.Len(t, fec.Events, 1)
+}
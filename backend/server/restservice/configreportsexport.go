@@ -0,0 +1,165 @@
+package restservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/configreportio"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+)
+
+// Returns the format of the exported config review reports. The SARIF
+// format is used by default.
+func getConfigReportsExportFormat(format *string) (configreportio.Format, error) {
+	if format == nil {
+		return configreportio.FormatSARIF, nil
+	}
+	return configreportio.ParseFormat(*format)
+}
+
+// Returns the value of the Content-Disposition header for the exported
+// config review reports.
+func getConfigReportsExportDisposition(name string, format configreportio.Format) string {
+	return fmt.Sprintf(
+		"attachment; filename=\"%s_%s.%s\"",
+		name,
+		strings.ReplaceAll(time.Now().UTC().Format(time.RFC3339), ":", "-"),
+		format.Extension(),
+	)
+}
+
+// Exports the last configuration review reports of the daemon in the
+// SARIF or JUnit XML format.
+func (r *RestAPI) ExportDaemonConfigReports(ctx context.Context, params services.ExportDaemonConfigReportsParams) middleware.Responder {
+	format, err := getConfigReportsExportFormat(params.Format)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid format of the config review reports: %s", err)
+		rsp := services.NewExportDaemonConfigReportsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	daemon, err := dbmodel.GetDaemonByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewExportDaemonConfigReportsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if daemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", params.ID)
+		rsp := services.NewExportDaemonConfigReportsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	dbReports, _, err := dbmodel.GetConfigReportsByDaemonID(r.DB, 0, 0, params.ID, false)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get configuration review reports for daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewExportDaemonConfigReportsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	var buffer bytes.Buffer
+	err = configreportio.Write(&buffer, format, []configreportio.DaemonReports{
+		configreportio.NewDaemonReports(daemon, dbReports),
+	})
+	if err != nil {
+		msg := "Problem with exporting configuration review reports"
+		log.WithError(err).Error(msg)
+		rsp := services.NewExportDaemonConfigReportsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	rsp := services.NewExportDaemonConfigReportsOK().
+		WithContentType(format.ContentType()).
+		WithContentDisposition(getConfigReportsExportDisposition(fmt.Sprintf("stork-config-reports-%d", daemon.ID), format)).
+		WithPayload(io.NopCloser(&buffer))
+	return rsp
+}
+
+// Exports the last configuration review reports of all reviewed daemons
+// in the SARIF or JUnit XML format.
+func (r *RestAPI) ExportConfigReports(ctx context.Context, params services.ExportConfigReportsParams) middleware.Responder {
+	format, err := getConfigReportsExportFormat(params.Format)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid format of the config review reports: %s", err)
+		rsp := services.NewExportConfigReportsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	daemons, err := dbmodel.GetAllDaemonsWithRelations(r.DB, dbmodel.DaemonRelationMachine)
+	if err != nil {
+		msg := "Cannot get daemons from db"
+		log.WithError(err).Error(msg)
+		rsp := services.NewExportConfigReportsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	daemonsByID := make(map[int64]*dbmodel.Daemon)
+	for i := range daemons {
+		daemonsByID[daemons[i].ID] = &daemons[i]
+	}
+
+	dbReports, err := dbmodel.GetAllConfigReports(r.DB)
+	if err != nil {
+		msg := "Cannot get configuration review reports from db"
+		log.WithError(err).Error(msg)
+		rsp := services.NewExportConfigReportsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	// The reports are ordered by the daemon ID, so the reports of each
+	// daemon are adjacent.
+	var daemonReports []configreportio.DaemonReports
+	for start := 0; start < len(dbReports); {
+		end := start + 1
+		for end < len(dbReports) && dbReports[end].DaemonID == dbReports[start].DaemonID {
+			end++
+		}
+		if daemon, ok := daemonsByID[dbReports[start].DaemonID]; ok {
+			daemonReports = append(daemonReports, configreportio.NewDaemonReports(daemon, dbReports[start:end]))
+		}
+		start = end
+	}
+
+	var buffer bytes.Buffer
+	if err = configreportio.Write(&buffer, format, daemonReports); err != nil {
+		msg := "Problem with exporting configuration review reports"
+		log.WithError(err).Error(msg)
+		rsp := services.NewExportConfigReportsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	rsp := services.NewExportConfigReportsOK().
+		WithContentType(format.ContentType()).
+		WithContentDisposition(getConfigReportsExportDisposition("stork-config-reports", format)).
+		WithPayload(io.NopCloser(&buffer))
+	return rsp
+}
@@ -0,0 +1,141 @@
+package restservice
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"isc.org/stork/datamodel/daemonname"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds two Kea daemons with the config reports to the database. The first
+// daemon has one issue and the second daemon has no issues.
+func addTestConfigReports(t *testing.T, db *dbops.PgDB) (*dbmodel.Daemon, *dbmodel.Daemon) {
+	machine := &dbmodel.Machine{
+		Address:   "192.0.2.1",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon4 := dbmodel.NewDaemon(machine, daemonname.DHCPv4, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon4)
+	require.NoError(t, err)
+
+	daemon6 := dbmodel.NewDaemon(machine, daemonname.DHCPv6, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon6)
+	require.NoError(t, err)
+
+	for _, configReport := range []*dbmodel.ConfigReport{
+		{
+			CheckerName: "overlapping_subnet",
+			Content:     storkutil.Ptr("Kea {daemon} configuration includes 2 overlapping subnets."),
+			DaemonID:    daemon4.ID,
+			RefDaemons:  []*dbmodel.Daemon{daemon4},
+		},
+		{
+			CheckerName: "canonical_prefix",
+			DaemonID:    daemon4.ID,
+		},
+		{
+			CheckerName: "canonical_prefix",
+			DaemonID:    daemon6.ID,
+		},
+	} {
+		err = dbmodel.AddConfigReport(db, configReport)
+		require.NoError(t, err)
+	}
+	return daemon4, daemon6
+}
+
+// Test that the config reports of the daemon are exported in the SARIF
+// format.
+func TestExportDaemonConfigReportsSARIF(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon4, _ := addTestConfigReports(t, db)
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeDispatcher{})
+	require.NoError(t, err)
+
+	rsp := rapi.ExportDaemonConfigReports(context.Background(), services.ExportDaemonConfigReportsParams{
+		ID: daemon4.ID,
+	})
+	require.IsType(t, &services.ExportDaemonConfigReportsOK{}, rsp)
+	okRsp := rsp.(*services.ExportDaemonConfigReportsOK)
+	require.Equal(t, "application/sarif+json", okRsp.ContentType)
+	require.Regexp(t, `^attachment; filename="stork-config-reports-1_.*\.sarif"$`, okRsp.ContentDisposition)
+
+	content, err := io.ReadAll(okRsp.Payload)
+	require.NoError(t, err)
+	var sarif map[string]any
+	err = json.Unmarshal(content, &sarif)
+	require.NoError(t, err)
+	results := sarif["runs"].([]any)[0].(map[string]any)["results"].([]any)
+	require.Len(t, results, 1)
+	require.Equal(t, map[string]any{
+		"text": "Kea dhcp4 (ID 1) configuration includes 2 overlapping subnets.",
+	}, results[0].(map[string]any)["message"])
+}
+
+// Test that exporting the config reports of a non-existing daemon or in
+// an unsupported format fails.
+func TestExportDaemonConfigReportsErrors(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon4, _ := addTestConfigReports(t, db)
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeDispatcher{})
+	require.NoError(t, err)
+
+	rsp := rapi.ExportDaemonConfigReports(context.Background(), services.ExportDaemonConfigReportsParams{
+		ID: daemon4.ID + 100,
+	})
+	require.IsType(t, &services.ExportDaemonConfigReportsDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.ExportDaemonConfigReportsDefault)))
+
+	rsp = rapi.ExportDaemonConfigReports(context.Background(), services.ExportDaemonConfigReportsParams{
+		ID:     daemon4.ID,
+		Format: storkutil.Ptr("csv"),
+	})
+	require.IsType(t, &services.ExportDaemonConfigReportsDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*services.ExportDaemonConfigReportsDefault)))
+}
+
+// Test that the config reports of all daemons are exported in the JUnit
+// format.
+func TestExportConfigReportsJUnit(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	addTestConfigReports(t, db)
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeDispatcher{})
+	require.NoError(t, err)
+
+	rsp := rapi.ExportConfigReports(context.Background(), services.ExportConfigReportsParams{
+		Format: storkutil.Ptr("junit"),
+	})
+	require.IsType(t, &services.ExportConfigReportsOK{}, rsp)
+	okRsp := rsp.(*services.ExportConfigReportsOK)
+	require.Equal(t, "application/xml", okRsp.ContentType)
+	require.Regexp(t, `^attachment; filename="stork-config-reports_.*\.xml"$`, okRsp.ContentDisposition)
+
+	content, err := io.ReadAll(okRsp.Payload)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `<testsuites name="Stork configuration review" tests="3" failures="1">`)
+	require.Contains(t, string(content), `<testsuite name="dhcp4@192.0.2.1 (ID 1)" tests="2" failures="1">`)
+	require.Contains(t, string(content), `<testsuite name="dhcp6@192.0.2.1 (ID 2)" tests="1" failures="0">`)
+}
@@ -0,0 +1,261 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/configreview"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the user-defined config review rule to the format used in REST API.
+func convertConfigReviewRuleToRestAPI(rule *dbmodel.ConfigReviewRule) *models.ConfigReviewRule {
+	restRule := &models.ConfigReviewRule{
+		ID:          rule.ID,
+		CreatedAt:   strfmt.DateTime(rule.CreatedAt),
+		Name:        storkutil.Ptr(rule.Name),
+		Description: rule.Description,
+		Selector:    storkutil.Ptr(rule.Selector),
+		Scope:       storkutil.Ptr(rule.Scope),
+		Triggers:    rule.Triggers,
+		Expression:  storkutil.Ptr(rule.Expression),
+		Message:     storkutil.Ptr(rule.Message),
+	}
+	if restRule.Triggers == nil {
+		restRule.Triggers = []string{}
+	}
+	return restRule
+}
+
+// Converts the user-defined config review rule received over the REST API
+// to the database model. It returns nil if any of the required fields is
+// missing.
+func convertConfigReviewRuleFromRestAPI(restRule *models.ConfigReviewRule) *dbmodel.ConfigReviewRule {
+	if restRule == nil || restRule.Name == nil || restRule.Selector == nil || restRule.Scope == nil ||
+		restRule.Expression == nil || restRule.Message == nil {
+		return nil
+	}
+	return &dbmodel.ConfigReviewRule{
+		ID:          restRule.ID,
+		Name:        *restRule.Name,
+		Description: restRule.Description,
+		Selector:    *restRule.Selector,
+		Scope:       *restRule.Scope,
+		Triggers:    restRule.Triggers,
+		Expression:  *restRule.Expression,
+		Message:     *restRule.Message,
+	}
+}
+
+// Get the user-defined config review rules.
+func (r *RestAPI) GetConfigReviewRules(ctx context.Context, params services.GetConfigReviewRulesParams) middleware.Responder {
+	dbRules, err := dbmodel.GetConfigReviewRules(r.DB)
+	if err != nil {
+		msg := "Cannot get config review rules from db"
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetConfigReviewRulesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	rules := &models.ConfigReviewRules{
+		Items: []*models.ConfigReviewRule{},
+		Total: int64(len(dbRules)),
+	}
+	for i := range dbRules {
+		rules.Items = append(rules.Items, convertConfigReviewRuleToRestAPI(&dbRules[i]))
+	}
+	rsp := services.NewGetConfigReviewRulesOK().WithPayload(rules)
+	return rsp
+}
+
+// Get the user-defined config review rule by ID.
+func (r *RestAPI) GetConfigReviewRule(ctx context.Context, params services.GetConfigReviewRuleParams) middleware.Responder {
+	rule, err := dbmodel.GetConfigReviewRuleByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get config review rule with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetConfigReviewRuleDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if rule == nil {
+		msg := fmt.Sprintf("Cannot find config review rule with ID %d", params.ID)
+		rsp := services.NewGetConfigReviewRuleDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := services.NewGetConfigReviewRuleOK().WithPayload(convertConfigReviewRuleToRestAPI(rule))
+	return rsp
+}
+
+// Create the user-defined config review rule. The rule is validated and
+// registered in the config review dispatcher as a checker with the rule
+// name, so it runs on the next review of the matching daemons.
+func (r *RestAPI) CreateConfigReviewRule(ctx context.Context, params services.CreateConfigReviewRuleParams) middleware.Responder {
+	rule := convertConfigReviewRuleFromRestAPI(params.Rule)
+	if rule == nil {
+		msg := "Rule name, selector, scope, expression and message must be specified"
+		rsp := services.NewCreateConfigReviewRuleDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := configreview.ValidateRule(rule); err != nil {
+		msg := fmt.Sprintf("Invalid config review rule: %s", err)
+		rsp := services.NewCreateConfigReviewRuleDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := configreview.RegisterRule(r.ReviewDispatcher, rule); err != nil {
+		msg := fmt.Sprintf("Cannot register config review rule %s: %s", rule.Name, err)
+		rsp := services.NewCreateConfigReviewRuleDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := dbmodel.AddConfigReviewRule(r.DB, rule); err != nil {
+		configreview.UnregisterRule(r.ReviewDispatcher, rule)
+		msg := fmt.Sprintf("Problem with inserting config review rule %s into db", rule.Name)
+		log.WithError(err).Error(msg)
+		rsp := services.NewCreateConfigReviewRuleDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} created config review rule %s", rule.Name), user)
+
+	rsp := services.NewCreateConfigReviewRuleOK().WithPayload(convertConfigReviewRuleToRestAPI(rule))
+	return rsp
+}
+
+// Update the user-defined config review rule. The rule name cannot be
+// changed because the checker preferences and the config reports refer
+// to the rule by name. The checker implementing the rule is replaced in
+// the config review dispatcher.
+func (r *RestAPI) UpdateConfigReviewRule(ctx context.Context, params services.UpdateConfigReviewRuleParams) middleware.Responder {
+	rule := convertConfigReviewRuleFromRestAPI(params.Rule)
+	if rule == nil {
+		msg := "Rule name, selector, scope, expression and message must be specified"
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rule.ID = params.ID
+
+	existingRule, err := dbmodel.GetConfigReviewRuleByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get config review rule with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if existingRule == nil {
+		msg := fmt.Sprintf("Cannot find config review rule with ID %d", params.ID)
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if rule.Name != existingRule.Name {
+		msg := fmt.Sprintf("Config review rule %s cannot be renamed", existingRule.Name)
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := configreview.ValidateRule(rule); err != nil {
+		msg := fmt.Sprintf("Invalid config review rule: %s", err)
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	// Replace the checker. The existing rule may not be registered if
+	// it was invalid when the server started.
+	registered := configreview.UnregisterRule(r.ReviewDispatcher, existingRule)
+	if err := configreview.RegisterRule(r.ReviewDispatcher, rule); err != nil {
+		if registered {
+			_ = configreview.RegisterRule(r.ReviewDispatcher, existingRule)
+		}
+		msg := fmt.Sprintf("Cannot register config review rule %s: %s", rule.Name, err)
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := dbmodel.UpdateConfigReviewRule(r.DB, rule); err != nil {
+		configreview.UnregisterRule(r.ReviewDispatcher, rule)
+		if registered {
+			_ = configreview.RegisterRule(r.ReviewDispatcher, existingRule)
+		}
+		msg := fmt.Sprintf("Problem with updating config review rule %s in db", rule.Name)
+		log.WithError(err).Error(msg)
+		rsp := services.NewUpdateConfigReviewRuleDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rule.CreatedAt = existingRule.CreatedAt
+
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} updated config review rule %s", rule.Name), user)
+
+	rsp := services.NewUpdateConfigReviewRuleOK().WithPayload(convertConfigReviewRuleToRestAPI(rule))
+	return rsp
+}
+
+// Delete the user-defined config review rule. The checker implementing the
+// rule is unregistered from the config review dispatcher, and its checker
+// preferences and config reports are deleted.
+func (r *RestAPI) DeleteConfigReviewRule(ctx context.Context, params services.DeleteConfigReviewRuleParams) middleware.Responder {
+	rule, err := dbmodel.GetConfigReviewRuleByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get config review rule with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewDeleteConfigReviewRuleDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if rule == nil {
+		msg := fmt.Sprintf("Cannot find config review rule with ID %d", params.ID)
+		rsp := services.NewDeleteConfigReviewRuleDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := dbmodel.DeleteConfigReviewRule(r.DB, rule.ID); err != nil {
+		msg := fmt.Sprintf("Problem with deleting config review rule %s from db", rule.Name)
+		log.WithError(err).Error(msg)
+		rsp := services.NewDeleteConfigReviewRuleDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	configreview.UnregisterRule(r.ReviewDispatcher, rule)
+
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} deleted config review rule %s", rule.Name), user)
+
+	rsp := services.NewDeleteConfigReviewRuleOK()
+	return rsp
+}
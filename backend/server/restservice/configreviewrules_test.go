@@ -0,0 +1,217 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"isc.org/stork/server/configreview"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storktestdbmodel "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Creates the REST API with the config review dispatcher and the logged user.
+func setupConfigReviewRulesRestAPI(t *testing.T, db *dbops.PgDB, dbSettings *dbops.DatabaseSettings) (*RestAPI, configreview.Dispatcher, *storktestdbmodel.FakeEventCenter, context.Context) {
+	dispatcher := configreview.NewDispatcher(db)
+	configreview.RegisterDefaultCheckers(dispatcher)
+
+	fec := &storktestdbmodel.FakeEventCenter{}
+	rapi, err := NewRestAPI(dbSettings, db, dispatcher, fec)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := &dbmodel.SystemUser{
+		ID: 1234,
+	}
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	return rapi, dispatcher, fec, ctx
+}
+
+// Returns a valid rule in the REST API format.
+func getTestRestConfigReviewRule() *models.ConfigReviewRule {
+	return &models.ConfigReviewRule{
+		Name:        storkutil.Ptr("subnet_dns_servers"),
+		Description: "Every subnet must have the DNS servers option.",
+		Selector:    storkutil.Ptr("kea-dhcp-v4-daemon"),
+		Scope:       storkutil.Ptr("subnet"),
+		Expression:  storkutil.Ptr(`any(subnet["option-data"], it.code == 6)`),
+		Message:     storkutil.Ptr("Subnet {{ subnet.subnet }} has no DNS servers."),
+	}
+}
+
+// Returns a boolean value indicating if the checker with a given name is
+// registered in the dispatcher.
+func isCheckerRegistered(t *testing.T, dispatcher configreview.Dispatcher, name string) bool {
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	require.NoError(t, err)
+	for _, checker := range metadata {
+		if checker.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Test that the config review rule is created, listed, fetched, updated and
+// deleted over the REST API, and that the corresponding checker is
+// registered in the dispatcher.
+func TestCreateUpdateDeleteConfigReviewRule(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, dispatcher, fec, ctx := setupConfigReviewRulesRestAPI(t, db, dbSettings)
+
+	// Create the rule.
+	rsp := rapi.CreateConfigReviewRule(ctx, services.CreateConfigReviewRuleParams{
+		Rule: getTestRestConfigReviewRule(),
+	})
+	require.IsType(t, &services.CreateConfigReviewRuleOK{}, rsp)
+	created := rsp.(*services.CreateConfigReviewRuleOK).Payload
+	require.NotZero(t, created.ID)
+	require.Equal(t, "subnet_dns_servers", *created.Name)
+	require.Empty(t, created.Triggers)
+	require.True(t, isCheckerRegistered(t, dispatcher, "subnet_dns_servers"))
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "created config review rule subnet_dns_servers")
+
+	// List the rules.
+	rsp = rapi.GetConfigReviewRules(ctx, services.GetConfigReviewRulesParams{})
+	require.IsType(t, &services.GetConfigReviewRulesOK{}, rsp)
+	rules := rsp.(*services.GetConfigReviewRulesOK).Payload
+	require.EqualValues(t, 1, rules.Total)
+	require.Len(t, rules.Items, 1)
+	require.Equal(t, "Every subnet must have the DNS servers option.", rules.Items[0].Description)
+
+	// Get the rule.
+	rsp = rapi.GetConfigReviewRule(ctx, services.GetConfigReviewRuleParams{ID: created.ID})
+	require.IsType(t, &services.GetConfigReviewRuleOK{}, rsp)
+	require.Equal(t, `any(subnet["option-data"], it.code == 6)`, *rsp.(*services.GetConfigReviewRuleOK).Payload.Expression)
+
+	// Update the rule.
+	rule := getTestRestConfigReviewRule()
+	rule.Selector = storkutil.Ptr("kea-dhcp-daemon")
+	rule.Triggers = []string{"manual"}
+	rsp = rapi.UpdateConfigReviewRule(ctx, services.UpdateConfigReviewRuleParams{
+		ID:   created.ID,
+		Rule: rule,
+	})
+	require.IsType(t, &services.UpdateConfigReviewRuleOK{}, rsp)
+	updated := rsp.(*services.UpdateConfigReviewRuleOK).Payload
+	require.Equal(t, created.ID, updated.ID)
+	require.Equal(t, "kea-dhcp-daemon", *updated.Selector)
+
+	metadata, err := dispatcher.GetCheckersMetadata(nil)
+	require.NoError(t, err)
+	for _, checker := range metadata {
+		if checker.Name == "subnet_dns_servers" {
+			require.Equal(t, configreview.DispatchGroupSelectors{configreview.KeaDHCPDaemon}, checker.Selectors)
+			require.Equal(t, configreview.Triggers{configreview.ManualRun}, checker.Triggers)
+		}
+	}
+
+	// Delete the rule.
+	rsp = rapi.DeleteConfigReviewRule(ctx, services.DeleteConfigReviewRuleParams{ID: created.ID})
+	require.IsType(t, &services.DeleteConfigReviewRuleOK{}, rsp)
+	require.False(t, isCheckerRegistered(t, dispatcher, "subnet_dns_servers"))
+
+	rsp = rapi.GetConfigReviewRule(ctx, services.GetConfigReviewRuleParams{ID: created.ID})
+	require.IsType(t, &services.GetConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.GetConfigReviewRuleDefault)))
+
+	require.Len(t, fec.Events, 3)
+	require.Contains(t, fec.Events[1].Text, "updated config review rule subnet_dns_servers")
+	require.Contains(t, fec.Events[2].Text, "deleted config review rule subnet_dns_servers")
+}
+
+// Test that the invalid config review rules are rejected.
+func TestCreateConfigReviewRuleErrors(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, _, fec, ctx := setupConfigReviewRulesRestAPI(t, db, dbSettings)
+
+	// Missing expression.
+	rule := getTestRestConfigReviewRule()
+	rule.Expression = nil
+	rsp := rapi.CreateConfigReviewRule(ctx, services.CreateConfigReviewRuleParams{Rule: rule})
+	require.IsType(t, &services.CreateConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*services.CreateConfigReviewRuleDefault)))
+
+	// Invalid expression.
+	rule = getTestRestConfigReviewRule()
+	rule.Expression = storkutil.Ptr("subnet.id ==")
+	rsp = rapi.CreateConfigReviewRule(ctx, services.CreateConfigReviewRuleParams{Rule: rule})
+	require.IsType(t, &services.CreateConfigReviewRuleDefault{}, rsp)
+	defaultRsp := rsp.(*services.CreateConfigReviewRuleDefault)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	require.Contains(t, *defaultRsp.Payload.Message, "Invalid config review rule")
+
+	// The name of the built-in checker.
+	rule = getTestRestConfigReviewRule()
+	rule.Name = storkutil.Ptr("dispensable_subnet")
+	rsp = rapi.CreateConfigReviewRule(ctx, services.CreateConfigReviewRuleParams{Rule: rule})
+	require.IsType(t, &services.CreateConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusConflict, getStatusCode(*rsp.(*services.CreateConfigReviewRuleDefault)))
+
+	rules, err := dbmodel.GetConfigReviewRules(db)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+	require.Empty(t, fec.Events)
+}
+
+// Test that the config review rule cannot be renamed or updated with an
+// invalid expression.
+func TestUpdateConfigReviewRuleErrors(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, dispatcher, _, ctx := setupConfigReviewRulesRestAPI(t, db, dbSettings)
+
+	rsp := rapi.CreateConfigReviewRule(ctx, services.CreateConfigReviewRuleParams{
+		Rule: getTestRestConfigReviewRule(),
+	})
+	require.IsType(t, &services.CreateConfigReviewRuleOK{}, rsp)
+	id := rsp.(*services.CreateConfigReviewRuleOK).Payload.ID
+
+	// Non-existing rule.
+	rsp = rapi.UpdateConfigReviewRule(ctx, services.UpdateConfigReviewRuleParams{
+		ID:   id + 1,
+		Rule: getTestRestConfigReviewRule(),
+	})
+	require.IsType(t, &services.UpdateConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.UpdateConfigReviewRuleDefault)))
+
+	// Renaming the rule.
+	rule := getTestRestConfigReviewRule()
+	rule.Name = storkutil.Ptr("other")
+	rsp = rapi.UpdateConfigReviewRule(ctx, services.UpdateConfigReviewRuleParams{ID: id, Rule: rule})
+	require.IsType(t, &services.UpdateConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*services.UpdateConfigReviewRuleDefault)))
+
+	// Invalid message.
+	rule = getTestRestConfigReviewRule()
+	rule.Message = storkutil.Ptr("Subnet {{ pool }}")
+	rsp = rapi.UpdateConfigReviewRule(ctx, services.UpdateConfigReviewRuleParams{ID: id, Rule: rule})
+	require.IsType(t, &services.UpdateConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*services.UpdateConfigReviewRuleDefault)))
+
+	// The original checker is still registered.
+	require.True(t, isCheckerRegistered(t, dispatcher, "subnet_dns_servers"))
+
+	// Deleting a non-existing rule.
+	rsp = rapi.DeleteConfigReviewRule(ctx, services.DeleteConfigReviewRuleParams{ID: id + 1})
+	require.IsType(t, &services.DeleteConfigReviewRuleDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*services.DeleteConfigReviewRuleDefault)))
+}
@@ -0,0 +1,234 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	"isc.org/stork/server/config"
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+)
+
+// Converts the configuration version to the format used in REST API.
+func (r *RestAPI) convertKeaConfigVersionToRestAPI(version *dbmodel.KeaConfigVersion) *models.KeaConfigVersion {
+	restVersion := &models.KeaConfigVersion{
+		Version:    version.Version,
+		CreatedAt:  strfmt.DateTime(version.CreatedAt),
+		ConfigHash: version.ConfigHash,
+		Source:     string(version.Source),
+		Operation:  string(version.Operation),
+	}
+	if version.UserID != nil {
+		restVersion.UserID = *version.UserID
+	}
+	if version.User != nil {
+		restVersion.UserLogin = version.User.Login
+	}
+	return restVersion
+}
+
+// Get the configuration versions of the Kea daemon.
+func (r *RestAPI) GetDaemonConfigVersions(ctx context.Context, params services.GetDaemonConfigVersionsParams) middleware.Responder {
+	var start int64
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	var limit int64 = 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	dbVersions, total, err := dbmodel.GetKeaConfigVersionsByPage(r.DB, params.ID, start, limit)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get configuration versions for daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetDaemonConfigVersionsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	versions := &models.KeaConfigVersions{
+		Items: []*models.KeaConfigVersion{},
+		Total: total,
+	}
+	for i := range dbVersions {
+		versions.Items = append(versions.Items, r.convertKeaConfigVersionToRestAPI(&dbVersions[i]))
+	}
+	rsp := services.NewGetDaemonConfigVersionsOK().WithPayload(versions)
+	return rsp
+}
+
+// Get the semantic differences between two configuration versions of the
+// Kea daemon.
+func (r *RestAPI) GetDaemonConfigVersionsDiff(ctx context.Context, params services.GetDaemonConfigVersionsDiffParams) middleware.Responder {
+	dbVersions := []*dbmodel.KeaConfigVersion{}
+	for _, versionNumber := range []int64{params.From, params.To} {
+		dbVersion, err := dbmodel.GetKeaConfigVersion(r.DB, params.ID, versionNumber)
+		if err != nil {
+			msg := fmt.Sprintf("Cannot get configuration version %d for daemon with ID %d from db", versionNumber, params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewGetDaemonConfigVersionsDiffDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		if dbVersion == nil || dbVersion.Config == nil {
+			msg := fmt.Sprintf("Cannot find configuration version %d for daemon with ID %d", versionNumber, params.ID)
+			rsp := services.NewGetDaemonConfigVersionsDiffDefault(http.StatusNotFound).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		dbVersions = append(dbVersions, dbVersion)
+	}
+
+	_, dbUser := r.SessionManager.Logged(ctx)
+	if !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+		for _, dbVersion := range dbVersions {
+			dbVersion.Config.HideSensitiveData()
+		}
+	}
+
+	entries, err := keaconfig.DiffConfigs(dbVersions[0].Config, dbVersions[1].Config)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with comparing configuration versions %d and %d for daemon with ID %d", params.From, params.To, params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetDaemonConfigVersionsDiffDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	diff := &models.KeaConfigVersionsDiff{
+		From:  params.From,
+		To:    params.To,
+		Items: []*models.KeaConfigDiffEntry{},
+	}
+	for _, entry := range entries {
+		diff.Items = append(diff.Items, &models.KeaConfigDiffEntry{
+			Path:      entry.Path,
+			Operation: string(entry.Operation),
+			OldValue:  entry.OldValue,
+			NewValue:  entry.NewValue,
+		})
+	}
+	rsp := services.NewGetDaemonConfigVersionsDiffOK().WithPayload(diff)
+	return rsp
+}
+
+// Roll back the Kea daemon's configuration to one of its previous versions.
+// It sends the configuration to the daemon with the config-set command and
+// persists it with the config-write command. The daemon's configuration is
+// locked for the duration of the rollback.
+func (r *RestAPI) RollbackDaemonConfig(ctx context.Context, params services.RollbackDaemonConfigParams) middleware.Responder {
+	dbVersion, err := dbmodel.GetKeaConfigVersion(r.DB, params.ID, params.Version)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get configuration version %d for daemon with ID %d from db", params.Version, params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbVersion == nil {
+		msg := fmt.Sprintf("Cannot find configuration version %d for daemon with ID %d", params.Version, params.ID)
+		rsp := services.NewRollbackDaemonConfigDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for rolling back the configuration"
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	// Lock the daemon's configuration.
+	cctx, err = r.ConfigManager.GetKeaModule().BeginConfigRollback(cctx, params.ID)
+	if err != nil {
+		var (
+			daemonsNotFound *config.SomeDaemonsNotFoundError
+			configBackend   *config.ConfigBackendRollbackError
+			lock            *config.LockError
+		)
+		switch {
+		case errors.As(err, &daemonsNotFound):
+			msg := fmt.Sprintf("Cannot find daemon with ID %d", params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewRollbackDaemonConfigDefault(http.StatusNotFound).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &configBackend):
+			msg := fmt.Sprintf("Unable to roll back the configuration of daemon with ID %d because it uses the config backend", params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewRollbackDaemonConfigDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		case errors.As(err, &lock):
+			msg := fmt.Sprintf("Unable to roll back the configuration of daemon with ID %d because it may be currently edited by another user", params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewRollbackDaemonConfigDefault(http.StatusLocked).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		default:
+			msg := fmt.Sprintf("Problem with initializing transaction for rolling back the configuration of daemon with ID %d", params.ID)
+			log.WithError(err).Error(msg)
+			rsp := services.NewRollbackDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+	}
+	// Release the lock regardless of the result.
+	defer r.ConfigManager.Done(cctx)
+
+	state, _ := config.GetTransactionState[kea.ConfigRecipe](cctx)
+	daemon := state.Updates[0].Recipe.RollbackDaemon
+
+	// Create Kea commands to set the configuration.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyConfigRollback(cctx, dbVersion)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with preparing commands for rolling back the configuration: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRollbackDaemonConfigDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	// Send the commands to Kea server or submit them for approval.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, nil, "configuration rollback")
+	if code != 0 {
+		rsp := services.NewRollbackDaemonConfigDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if isConfigChangeCommitted(cctx) {
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} rolled back configuration of {daemon} to version %d", params.Version), user, daemon)
+	}
+
+	rsp := services.NewRollbackDaemonConfigOK()
+	return rsp
+}
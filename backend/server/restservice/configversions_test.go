@@ -0,0 +1,181 @@
+package restservice
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keaconfig "isc.org/stork/daemoncfg/kea"
+	keactrl "isc.org/stork/daemonctrl/kea"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/restapi/operations/services"
+)
+
+// Adds a Kea daemon with two configuration versions to the database.
+func addTestKeaConfigVersions(t *testing.T, db *dbops.PgDB) *dbmodel.Daemon {
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	for _, rawConfig := range []string{
+		`{ "Dhcp4": { "valid-lifetime": 1000, "subnet4": [ { "id": 1, "subnet": "192.0.2.0/24" } ] } }`,
+		`{ "Dhcp4": { "valid-lifetime": 2000, "subnet4": [ { "id": 1, "subnet": "192.0.2.0/24" }, { "id": 2, "subnet": "192.0.3.0/24" } ] } }`,
+	} {
+		config, err := keaconfig.NewConfig([]byte(rawConfig))
+		require.NoError(t, err)
+		err = dbmodel.AddKeaConfigVersion(db, &dbmodel.KeaConfigVersion{
+			DaemonID:   daemon.ID,
+			Config:     &dbmodel.KeaConfig{Config: config},
+			ConfigHash: rawConfig,
+			Source:     dbmodel.KeaConfigVersionSourceExternal,
+		})
+		require.NoError(t, err)
+	}
+	return daemon
+}
+
+// Test that the daemon configuration versions are returned over the REST API.
+func TestGetDaemonConfigVersions(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestKeaConfigVersions(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.GetDaemonConfigVersions(ctx, services.GetDaemonConfigVersionsParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.GetDaemonConfigVersionsOK{}, rsp)
+	versions := rsp.(*services.GetDaemonConfigVersionsOK).Payload
+	require.EqualValues(t, 2, versions.Total)
+	require.Len(t, versions.Items, 2)
+	require.EqualValues(t, 2, versions.Items[0].Version)
+	require.EqualValues(t, 1, versions.Items[1].Version)
+	require.Equal(t, "external", versions.Items[0].Source)
+}
+
+// Test that the differences between the daemon configuration versions are
+// returned over the REST API.
+func TestGetDaemonConfigVersionsDiff(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestKeaConfigVersions(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.GetDaemonConfigVersionsDiff(ctx, services.GetDaemonConfigVersionsDiffParams{
+		ID:   daemon.ID,
+		From: 1,
+		To:   2,
+	})
+	require.IsType(t, &services.GetDaemonConfigVersionsDiffOK{}, rsp)
+	diff := rsp.(*services.GetDaemonConfigVersionsDiffOK).Payload
+	require.EqualValues(t, 1, diff.From)
+	require.EqualValues(t, 2, diff.To)
+	require.Len(t, diff.Items, 2)
+
+	require.Equal(t, "/Dhcp4/subnet4[id=2]", diff.Items[0].Path)
+	require.Equal(t, "added", diff.Items[0].Operation)
+	require.Nil(t, diff.Items[0].OldValue)
+	require.NotNil(t, diff.Items[0].NewValue)
+
+	require.Equal(t, "/Dhcp4/valid-lifetime", diff.Items[1].Path)
+	require.Equal(t, "modified", diff.Items[1].Operation)
+	require.EqualValues(t, 1000, diff.Items[1].OldValue)
+	require.EqualValues(t, 2000, diff.Items[1].NewValue)
+
+	// Non-existing version.
+	rsp = rapi.GetDaemonConfigVersionsDiff(ctx, services.GetDaemonConfigVersionsDiffParams{
+		ID:   daemon.ID,
+		From: 1,
+		To:   3,
+	})
+	require.IsType(t, &services.GetDaemonConfigVersionsDiffDefault{}, rsp)
+	defaultRsp := rsp.(*services.GetDaemonConfigVersionsDiffDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test that the daemon configuration is rolled back to the previous version
+// over the REST API.
+func TestRollbackDaemonConfig(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestKeaConfigVersions(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.RollbackDaemonConfig(ctx, services.RollbackDaemonConfigParams{
+		ID:      daemon.ID,
+		Version: 1,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "config-set",
+		"service": ["dhcp4"],
+		"arguments": {
+			"Dhcp4": {
+				"valid-lifetime": 1000,
+				"subnet4": [ { "id": 1, "subnet": "192.0.2.0/24" } ]
+			}
+		}
+	}`, string(commandMarshaled))
+	require.EqualValues(t, keactrl.ConfigWrite, fa.RecordedCommands[1].GetCommand())
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "rolled back configuration")
+	require.Contains(t, fec.Events[0].Text, "to version 1")
+
+	// The lock has been released, so the rollback can be repeated.
+	rsp = rapi.RollbackDaemonConfig(ctx, services.RollbackDaemonConfigParams{
+		ID:      daemon.ID,
+		Version: 1,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigOK{}, rsp)
+
+	// Non-existing version.
+	rsp = rapi.RollbackDaemonConfig(ctx, services.RollbackDaemonConfigParams{
+		ID:      daemon.ID,
+		Version: 3,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigDefault{}, rsp)
+	defaultRsp := rsp.(*services.RollbackDaemonConfigDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test that rolling back the configuration is submitted for approval instead
+// of being committed when the config change approval is enabled.
+func TestRollbackDaemonConfigApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestKeaConfigVersions(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.RollbackDaemonConfig(ctx, services.RollbackDaemonConfigParams{
+		ID:      daemon.ID,
+		Version: 1,
+	})
+	require.IsType(t, &services.RollbackDaemonConfigOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaConfigRollback)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+}
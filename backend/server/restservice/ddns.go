@@ -0,0 +1,187 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+)
+
+// Matches the TSIG key name in the D2 statistic name.
+var d2KeyStatNameRegex = regexp.MustCompile(`^key\[(.+)\]\.(.+)$`)
+
+// Converts the DDNS domain correlated with a zone to the format used in
+// REST API.
+func convertDDNSDomainCorrelationToRestAPI(correlation kea.DDNSDomainCorrelation) *models.DDNSDomain {
+	domain := &models.DDNSDomain{
+		Direction:       string(correlation.Direction),
+		Name:            correlation.Domain.Name,
+		KeyNames:        correlation.KeyNames,
+		MissingKeyNames: correlation.MissingKeyNames,
+		ZoneStatus:      string(correlation.Status),
+		Primary:         correlation.Primary,
+	}
+	for _, server := range correlation.Domain.DNSServers {
+		restServer := &models.DDNSDNSServer{}
+		if server.Hostname != nil {
+			restServer.Hostname = *server.Hostname
+		}
+		if server.IPAddress != nil {
+			restServer.IPAddress = *server.IPAddress
+		}
+		if server.Port != nil {
+			restServer.Port = *server.Port
+		}
+		if server.KeyName != nil {
+			restServer.KeyName = *server.KeyName
+		}
+		domain.DNSServers = append(domain.DNSServers, restServer)
+	}
+	if correlation.Zone != nil {
+		var localZones []*models.LocalZone
+		for _, localZone := range correlation.Zone.LocalZones {
+			localZones = append(localZones, &models.LocalZone{
+				ZoneClass:   localZone.Class,
+				DaemonID:    localZone.DaemonID,
+				DaemonLabel: localZone.Daemon.GetLabel(),
+				LoadedAt:    strfmt.DateTime(localZone.LoadedAt),
+				Serial:      localZone.Serial,
+				Rpz:         localZone.RPZ,
+				View:        localZone.View,
+				ZoneType:    localZone.Type,
+			})
+		}
+		domain.Zone = &models.Zone{
+			ID:         correlation.Zone.ID,
+			Name:       correlation.Zone.Name,
+			Rname:      correlation.Zone.Rname,
+			LocalZones: localZones,
+		}
+	}
+	return domain
+}
+
+// Returns the D2 daemon with the specified ID. It returns an HTTP error code
+// and message if the daemon doesn't exist or is not a D2 daemon.
+func (r *RestAPI) getD2Daemon(daemonID int64) (*dbmodel.Daemon, int, string) {
+	daemon, err := dbmodel.GetKeaDaemonByID(r.DB, daemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", daemonID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if daemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", daemonID)
+		return nil, http.StatusNotFound, msg
+	}
+	if daemon.Name != daemonname.D2 {
+		msg := fmt.Sprintf("Daemon with ID %d is not a Kea DHCP-DDNS daemon", daemonID)
+		return nil, http.StatusBadRequest, msg
+	}
+	return daemon, 0, ""
+}
+
+// Returns the DDNS domains configured in the D2 daemon correlated with the
+// zones served by the monitored DNS servers.
+func (r *RestAPI) GetDaemonDDNSDomains(ctx context.Context, params dhcp.GetDaemonDDNSDomainsParams) middleware.Responder {
+	daemon, code, msg := r.getD2Daemon(params.ID)
+	if code != 0 {
+		rsp := dhcp.NewGetDaemonDDNSDomainsDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	domains := &models.DDNSDomains{
+		Items:    []*models.DDNSDomain{},
+		TsigKeys: []*models.TSIGKey{},
+	}
+	// The configuration may not be fetched yet.
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		rsp := dhcp.NewGetDaemonDDNSDomainsOK().WithPayload(domains)
+		return rsp
+	}
+
+	correlations, err := kea.CorrelateDDNSDomains(r.DB, daemon)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot correlate DDNS domains with zones for daemon with ID %d", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetDaemonDDNSDomainsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	for _, correlation := range correlations {
+		domains.Items = append(domains.Items, convertDDNSDomainCorrelationToRestAPI(correlation))
+	}
+	for _, key := range daemon.KeaDaemon.Config.GetTSIGKeys() {
+		domains.TsigKeys = append(domains.TsigKeys, &models.TSIGKey{
+			Name:       key.Name,
+			Algorithm:  key.Algorithm,
+			DigestBits: key.DigestBits,
+		})
+	}
+	domains.Total = int64(len(domains.Items))
+
+	rsp := dhcp.NewGetDaemonDDNSDomainsOK().WithPayload(domains)
+	return rsp
+}
+
+// Returns the most recent statistics pulled from the D2 daemon.
+func (r *RestAPI) GetDaemonD2Statistics(ctx context.Context, params dhcp.GetDaemonD2StatisticsParams) middleware.Responder {
+	_, code, msg := r.getD2Daemon(params.ID)
+	if code != 0 {
+		rsp := dhcp.NewGetDaemonD2StatisticsDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	statistics, err := dbmodel.GetKeaD2Statistics(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get statistics for daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetDaemonD2StatisticsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	restStatistics := &models.D2Statistics{
+		Items: []*models.D2Statistic{},
+	}
+	if statistics != nil {
+		restStatistics.CollectedAt = strfmt.DateTime(statistics.CollectedAt)
+		for name, value := range statistics.Statistics {
+			statistic := &models.D2Statistic{
+				Name:  name,
+				Value: value,
+			}
+			if matches := d2KeyStatNameRegex.FindStringSubmatch(name); len(matches) == 3 {
+				statistic.KeyName = matches[1]
+				statistic.Name = matches[2]
+			}
+			restStatistics.Items = append(restStatistics.Items, statistic)
+		}
+		// Global statistics first, then the statistics grouped by keys.
+		sort.Slice(restStatistics.Items, func(i, j int) bool {
+			if restStatistics.Items[i].KeyName != restStatistics.Items[j].KeyName {
+				return restStatistics.Items[i].KeyName < restStatistics.Items[j].KeyName
+			}
+			return restStatistics.Items[i].Name < restStatistics.Items[j].Name
+		})
+	}
+
+	rsp := dhcp.NewGetDaemonD2StatisticsOK().WithPayload(restStatistics)
+	return rsp
+}
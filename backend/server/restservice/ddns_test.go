@@ -0,0 +1,182 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+)
+
+// Test getting the DDNS domains of the D2 daemon correlated with the zones.
+func TestGetDaemonDDNSDomains(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine, err := dbmodeltest.NewMachine(db)
+	require.NoError(t, err)
+
+	d2, err := machine.NewKeaD2Server()
+	require.NoError(t, err)
+	err = d2.Configure(`{
+		"DhcpDdns": {
+			"forward-ddns": {
+				"ddns-domains": [
+					{
+						"name": "example.org.",
+						"key-name": "key1",
+						"dns-servers": [ { "ip-address": "192.0.2.1", "port": 5353 } ]
+					}
+				]
+			},
+			"reverse-ddns": {
+				"ddns-domains": [
+					{
+						"name": "2.0.192.in-addr.arpa.",
+						"dns-servers": [ { "hostname": "ns.example.org" } ]
+					}
+				]
+			},
+			"tsig-keys": [
+				{
+					"name": "key1",
+					"algorithm": "HMAC-SHA256",
+					"secret": "LSWXnfkKZjdPJI5QxlpnfQ=="
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	d2Daemon, err := d2.GetDaemon()
+	require.NoError(t, err)
+
+	bind9, err := machine.NewBind9Daemon()
+	require.NoError(t, err)
+	err = dbmodel.AddZones(db, &dbmodel.Zone{
+		Name: "example.org",
+		LocalZones: []*dbmodel.LocalZone{
+			{
+				DaemonID: bind9.DaemonID,
+				View:     "_default",
+				Class:    "IN",
+				Serial:   1,
+				Type:     string(dbmodel.ZoneTypePrimary),
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	rapi, err := NewRestAPI(dbSettings, db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	rsp := rapi.GetDaemonDDNSDomains(ctx, dhcp.GetDaemonDDNSDomainsParams{
+		ID: d2Daemon.ID,
+	})
+	require.IsType(t, &dhcp.GetDaemonDDNSDomainsOK{}, rsp)
+	domains := rsp.(*dhcp.GetDaemonDDNSDomainsOK).Payload
+	require.EqualValues(t, 2, domains.Total)
+	require.Len(t, domains.Items, 2)
+
+	require.Equal(t, "forward", domains.Items[0].Direction)
+	require.Equal(t, "example.org.", domains.Items[0].Name)
+	require.Equal(t, "served", domains.Items[0].ZoneStatus)
+	require.True(t, domains.Items[0].Primary)
+	require.Equal(t, []string{"key1"}, domains.Items[0].KeyNames)
+	require.Len(t, domains.Items[0].DNSServers, 1)
+	require.Equal(t, "192.0.2.1", domains.Items[0].DNSServers[0].IPAddress)
+	require.EqualValues(t, 5353, domains.Items[0].DNSServers[0].Port)
+	require.NotNil(t, domains.Items[0].Zone)
+	require.Equal(t, "example.org", domains.Items[0].Zone.Name)
+	require.Len(t, domains.Items[0].Zone.LocalZones, 1)
+	require.Equal(t, bind9.DaemonID, domains.Items[0].Zone.LocalZones[0].DaemonID)
+
+	require.Equal(t, "reverse", domains.Items[1].Direction)
+	require.Equal(t, "not-served", domains.Items[1].ZoneStatus)
+	require.Nil(t, domains.Items[1].Zone)
+	require.Equal(t, "ns.example.org", domains.Items[1].DNSServers[0].Hostname)
+
+	require.Len(t, domains.TsigKeys, 1)
+	require.Equal(t, "key1", domains.TsigKeys[0].Name)
+	require.Equal(t, "HMAC-SHA256", domains.TsigKeys[0].Algorithm)
+
+	// The DNS daemon is not a D2 daemon.
+	rsp = rapi.GetDaemonDDNSDomains(ctx, dhcp.GetDaemonDDNSDomainsParams{
+		ID: bind9.DaemonID,
+	})
+	require.IsType(t, &dhcp.GetDaemonDDNSDomainsDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.GetDaemonDDNSDomainsDefault)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+
+	// Non-existing daemon.
+	rsp = rapi.GetDaemonDDNSDomains(ctx, dhcp.GetDaemonDDNSDomainsParams{
+		ID: 12345,
+	})
+	require.IsType(t, &dhcp.GetDaemonDDNSDomainsDefault{}, rsp)
+	defaultRsp = rsp.(*dhcp.GetDaemonDDNSDomainsDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test getting the statistics of the D2 daemon.
+func TestGetDaemonD2Statistics(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine, err := dbmodeltest.NewMachine(db)
+	require.NoError(t, err)
+	d2, err := machine.NewKeaD2Server()
+	require.NoError(t, err)
+	d2Daemon, err := d2.GetDaemon()
+	require.NoError(t, err)
+
+	rapi, err := NewRestAPI(dbSettings, db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// No statistics pulled yet.
+	rsp := rapi.GetDaemonD2Statistics(ctx, dhcp.GetDaemonD2StatisticsParams{
+		ID: d2Daemon.ID,
+	})
+	require.IsType(t, &dhcp.GetDaemonD2StatisticsOK{}, rsp)
+	require.Empty(t, rsp.(*dhcp.GetDaemonD2StatisticsOK).Payload.Items)
+
+	err = dbmodel.AddKeaD2Statistics(db, dbmodel.NewKeaD2Statistics(d2Daemon.ID, map[string]int64{
+		"update-error":          1,
+		"ncr-received":          10,
+		"key[key1].update-sent": 5,
+	}))
+	require.NoError(t, err)
+
+	rsp = rapi.GetDaemonD2Statistics(ctx, dhcp.GetDaemonD2StatisticsParams{
+		ID: d2Daemon.ID,
+	})
+	require.IsType(t, &dhcp.GetDaemonD2StatisticsOK{}, rsp)
+	statistics := rsp.(*dhcp.GetDaemonD2StatisticsOK).Payload
+	require.Len(t, statistics.Items, 3)
+
+	require.Empty(t, statistics.Items[0].KeyName)
+	require.Equal(t, "ncr-received", statistics.Items[0].Name)
+	require.EqualValues(t, 10, statistics.Items[0].Value)
+
+	require.Empty(t, statistics.Items[1].KeyName)
+	require.Equal(t, "update-error", statistics.Items[1].Name)
+	require.EqualValues(t, 1, statistics.Items[1].Value)
+
+	require.Equal(t, "key1", statistics.Items[2].KeyName)
+	require.Equal(t, "update-sent", statistics.Items[2].Name)
+	require.EqualValues(t, 5, statistics.Items[2].Value)
+
+	// Non-existing daemon.
+	rsp = rapi.GetDaemonD2Statistics(ctx, dhcp.GetDaemonD2StatisticsParams{
+		ID: 12345,
+	})
+	require.IsType(t, &dhcp.GetDaemonD2StatisticsDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.GetDaemonD2StatisticsDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
@@ -375,7 +376,7 @@ func (r *RestAPI) CreateHostBegin(ctx context.Context, params dhcp.CreateHostBeg
 // returns the HTTP error code if an error occurs or 0 when there is no error.
 // In addition it returns an error string to be included in the HTTP response
 // or an empty string if there is no error.
-func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactionID int64, restHost *models.Host, applyFunc func(context.Context, *dbmodel.Host) (context.Context, error)) (int, string) {
+func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactionID int64, deadline *strfmt.DateTime, restHost *models.Host, applyFunc func(context.Context, *dbmodel.Host) (context.Context, error)) (int, string) {
 	// Make sure that the host information is present.
 	if restHost == nil {
 		msg := "Host information not specified"
@@ -417,12 +418,10 @@ func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactio
 		log.WithError(err).Error(msg)
 		return http.StatusInternalServerError, msg
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with committing host information: %s", err)
-		log.WithError(err).Error(msg)
-		return http.StatusConflict, msg
+	// Send the commands to Kea servers or schedule them.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, deadline, "host information")
+	if code != 0 {
+		return code, msg
 	}
 	// Everything ok. Cleanup and send OK to the client.
 	r.ConfigManager.Done(cctx)
@@ -431,7 +430,7 @@ func (r *RestAPI) commonCreateOrUpdateHostSubmit(ctx context.Context, transactio
 
 // Implements the POST call to apply and commit host reservation (hosts/new/transaction/{id}/submit).
 func (r *RestAPI) CreateHostSubmit(ctx context.Context, params dhcp.CreateHostSubmitParams) middleware.Responder {
-	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostAdd); code != 0 {
+	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Deadline, params.Host, r.ConfigManager.GetKeaModule().ApplyHostAdd); code != 0 {
 		// Error case.
 		rsp := dhcp.NewCreateHostSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -554,7 +553,7 @@ func (r *RestAPI) UpdateHostBegin(ctx context.Context, params dhcp.UpdateHostBeg
 
 // Implements the POST call and commit an updated host reservation (hosts/{hostId}/transaction/{id}/submit).
 func (r *RestAPI) UpdateHostSubmit(ctx context.Context, params dhcp.UpdateHostSubmitParams) middleware.Responder {
-	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Host, r.ConfigManager.GetKeaModule().ApplyHostUpdate); code != 0 {
+	if code, msg := r.commonCreateOrUpdateHostSubmit(ctx, params.ID, params.Deadline, params.Host, r.ConfigManager.GetKeaModule().ApplyHostUpdate); code != 0 {
 		// Error case.
 		rsp := dhcp.NewUpdateHostSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -625,12 +624,10 @@ func (r *RestAPI) DeleteHost(ctx context.Context, params dhcp.DeleteHostParams)
 		})
 		return rsp
 	}
-	// Send the commands to Kea servers.
-	_, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with deleting host reservation: %s", err)
-		log.WithError(err).Error(msg)
-		rsp := dhcp.NewDeleteHostDefault(http.StatusConflict).WithPayload(&models.APIError{
+	// Send the commands to Kea servers or submit them for approval.
+	_, code, msg := r.commitOrScheduleConfigChange(cctx, user, nil, "host reservation deletion")
+	if code != 0 {
+		rsp := dhcp.NewDeleteHostDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
 		})
 		return rsp
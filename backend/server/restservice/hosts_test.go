@@ -1868,6 +1868,46 @@ func TestDeleteHost(t *testing.T) {
 	require.Nil(t, returnedHost)
 }
 
+// Test that deleting a host reservation is submitted for approval instead
+// of being committed when the config change approval is enabled.
+func TestDeleteHostApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := daemonsconfig.NewManager(&daemonstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	hosts, _ := storktestdbmodel.AddTestHosts(t, db)
+
+	rsp := rapi.DeleteHost(ctx, dhcp.DeleteHostParams{
+		ID: hosts[0].ID,
+	})
+	require.IsType(t, &dhcp.DeleteHostOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaHostDelete)
+
+	// The host should not be deleted until the request is approved.
+	returnedHost, err := dbmodel.GetHost(db, hosts[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returnedHost)
+}
+
 // Test error cases for deleting a host reservation.
 func TestDeleteHostError(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -1932,7 +1972,7 @@ func TestDeleteHostError(t *testing.T) {
 		require.IsType(t, &dhcp.DeleteHostDefault{}, rsp)
 		defaultRsp := rsp.(*dhcp.DeleteHostDefault)
 		require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
-		require.Equal(t, "Problem with deleting host reservation: reservation-del command to dhcp4 failed: non-success response result from Kea: 1, text: unable to communicate with the daemon", *defaultRsp.Payload.Message)
+		require.Equal(t, "Problem with committing host reservation deletion: reservation-del command to dhcp4 failed: non-success response result from Kea: 1, text: unable to communicate with the daemon", *defaultRsp.Payload.Message)
 	})
 }
 
@@ -0,0 +1,332 @@
+package restservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/config"
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	"isc.org/stork/server/hostsio"
+	storkutil "isc.org/stork/util"
+)
+
+// Default number of the host reservations added in a single transaction
+// during the import.
+const defaultHostsImportBatchSize = 100
+
+// A valid host reservation to be imported along with the number of the
+// row it has been read from.
+type importedHost struct {
+	row  int64
+	host *dbmodel.Host
+}
+
+// Checks if the reserved addresses and prefixes match the family of the
+// DHCP servers receiving the reservation.
+func checkImportedHostFamily(host *dbmodel.Host, daemonName daemonname.Name) error {
+	for _, reservation := range host.GetIPReservations() {
+		parsed := storkutil.ParseIP(reservation)
+		if parsed == nil {
+			continue
+		}
+		if (parsed.Protocol == storkutil.IPv4) != (daemonName == daemonname.DHCPv4) {
+			return fmt.Errorf("IP reservation %s cannot be added to %s server", reservation, daemonName)
+		}
+	}
+	return nil
+}
+
+// Validates the imported host reservations and converts them to the
+// database model. The host reservations are associated with the specified
+// daemons. It returns the valid host reservations and the errors for the
+// invalid ones.
+func (r *RestAPI) validateImportedHosts(records []hostsio.Record, daemons []*dbmodel.Daemon) ([]importedHost, []*models.HostsImportError, error) {
+	family := 4
+	if daemons[0].Name == daemonname.DHCPv6 {
+		family = 6
+	}
+	dbSubnets, err := dbmodel.GetAllSubnets(r.DB, family)
+	if err != nil {
+		return nil, nil, err
+	}
+	subnets := make(map[string]*dbmodel.Subnet)
+	for i := range dbSubnets {
+		subnets[dbSubnets[i].Prefix] = &dbSubnets[i]
+	}
+
+	var (
+		hosts      []importedHost
+		importErrs []*models.HostsImportError
+	)
+	rows := make(map[string]int64)
+	for i, record := range records {
+		row := int64(i + 1)
+		if duplicate, ok := rows[record.Key()]; ok {
+			importErrs = append(importErrs, &models.HostsImportError{
+				Row:     row,
+				Message: fmt.Sprintf("duplicate of the host reservation in row %d", duplicate),
+			})
+			continue
+		}
+		rows[record.Key()] = row
+		host, err := record.ToHost(subnets, daemons)
+		if err == nil {
+			err = checkImportedHostFamily(host, daemons[0].Name)
+		}
+		if err != nil {
+			importErrs = append(importErrs, &models.HostsImportError{
+				Row:     row,
+				Message: err.Error(),
+			})
+			continue
+		}
+		hosts = append(hosts, importedHost{
+			row:  row,
+			host: host,
+		})
+	}
+	return hosts, importErrs, nil
+}
+
+// Adds a batch of the imported host reservations to the Kea servers in
+// a single transaction. The transaction is submitted for approval if the
+// config change approval is enabled. It returns the number of the imported
+// host reservations, the ID of the config change request if the batch has
+// been submitted for approval, and the errors for the host reservations
+// which couldn't be imported.
+func (r *RestAPI) importHostsBatch(user *dbmodel.SystemUser, batch []importedHost) (int64, int64, []*models.HostsImportError) {
+	batchError := func(msg string) (int64, int64, []*models.HostsImportError) {
+		importErrs := []*models.HostsImportError{}
+		for _, h := range batch {
+			importErrs = append(importErrs, &models.HostsImportError{
+				Row:     h.row,
+				Message: msg,
+			})
+		}
+		return 0, 0, importErrs
+	}
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for importing host reservations"
+		log.WithError(err).Error(msg)
+		return batchError(msg)
+	}
+	defer r.ConfigManager.Done(cctx)
+
+	if cctx, err = r.ConfigManager.GetKeaModule().BeginHostImport(cctx); err != nil {
+		msg := "Problem with initializing transaction for importing host reservations"
+		log.WithError(err).Error(msg)
+		return batchError(msg)
+	}
+	hosts := []*dbmodel.Host{}
+	for _, h := range batch {
+		hosts = append(hosts, h.host)
+	}
+	if cctx, err = r.ConfigManager.GetKeaModule().ApplyHostImport(cctx, hosts); err != nil {
+		msg := fmt.Sprintf("Problem with applying host reservations: %s", err)
+		log.WithError(err).Error(msg)
+		return batchError(msg)
+	}
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, nil, "imported host reservations")
+	if code == 0 {
+		if requestID, ok := config.GetValueAsInt64(cctx, config.ConfigChangeRequestIDKey); ok {
+			return 0, requestID, nil
+		}
+		return int64(len(batch)), 0, nil
+	}
+	// Some host reservations could have been added despite the error.
+	// Report the errors for the remaining ones.
+	recipe, err := config.GetRecipeForUpdate[kea.ConfigRecipe](cctx, 0)
+	if err != nil || len(recipe.ImportedHostErrors) != len(batch) {
+		return batchError(msg)
+	}
+	var (
+		imported   int64
+		importErrs []*models.HostsImportError
+	)
+	for i, hostErr := range recipe.ImportedHostErrors {
+		if hostErr == "" {
+			imported++
+			continue
+		}
+		importErrs = append(importErrs, &models.HostsImportError{
+			Row:     batch[i].row,
+			Message: hostErr,
+		})
+	}
+	return imported, 0, importErrs
+}
+
+// Implements the POST call to import host reservations from a CSV or JSON
+// file (hosts/import). The host reservations are validated and the valid
+// ones are added to the selected Kea servers in batches. The errors are
+// reported for each invalid or rejected row.
+func (r *RestAPI) ImportHosts(ctx context.Context, params dhcp.ImportHostsParams) middleware.Responder {
+	badRequest := func(msg string) middleware.Responder {
+		log.Errorf("Problem with importing host reservations: %s", msg)
+		return dhcp.NewImportHostsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+	hostsImport := params.HostsImport
+	if hostsImport == nil || hostsImport.Format == nil || hostsImport.Content == nil {
+		return badRequest("Host reservations to import not specified")
+	}
+	if len(hostsImport.DaemonIds) == 0 {
+		return badRequest("Servers to which the host reservations should be imported not specified")
+	}
+	format, err := hostsio.ParseFormat(*hostsImport.Format)
+	if err != nil {
+		return badRequest(fmt.Sprintf("Invalid format of the host reservations: %s", err))
+	}
+	records, err := hostsio.Read(strings.NewReader(*hostsImport.Content), format)
+	if err != nil {
+		return badRequest(fmt.Sprintf("Problem with parsing the host reservations: %s", err))
+	}
+
+	dbDaemons, err := dbmodel.GetKeaDaemonsByIDs(r.DB, hostsImport.DaemonIds)
+	if err != nil {
+		msg := "Problem with fetching Kea daemons from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewImportHostsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if len(dbDaemons) != len(hostsImport.DaemonIds) {
+		return badRequest("Some of the specified servers do not exist")
+	}
+	daemons := []*dbmodel.Daemon{}
+	for i := range dbDaemons {
+		if !dbDaemons[i].Name.IsDHCP() || dbDaemons[i].Name != dbDaemons[0].Name {
+			return badRequest("Host reservations can only be imported to DHCPv4 servers or to DHCPv6 servers")
+		}
+		daemons = append(daemons, &dbDaemons[i])
+	}
+
+	hosts, importErrs, err := r.validateImportedHosts(records, daemons)
+	if err != nil {
+		msg := "Problem with validating the host reservations"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewImportHostsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	result := &models.HostsImportResult{
+		Total:  int64(len(records)),
+		Valid:  int64(len(hosts)),
+		Errors: importErrs,
+	}
+	if hostsImport.DryRun || len(hosts) == 0 {
+		rsp := dhcp.NewImportHostsOK().WithPayload(result)
+		return rsp
+	}
+
+	batchSize := hostsImport.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultHostsImportBatchSize
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	for start := 0; start < len(hosts); start += int(batchSize) {
+		end := min(start+int(batchSize), len(hosts))
+		imported, requestID, batchErrs := r.importHostsBatch(user, hosts[start:end])
+		result.Imported += imported
+		if requestID != 0 {
+			result.Submitted += int64(end - start)
+			result.ConfigChangeRequestIds = append(result.ConfigChangeRequestIds, requestID)
+		}
+		result.Errors = append(result.Errors, batchErrs...)
+	}
+	text := fmt.Sprintf("{user} imported %d of %d host reservations", result.Imported, result.Total)
+	if result.Submitted > 0 {
+		requestIDs := []string{}
+		for _, requestID := range result.ConfigChangeRequestIds {
+			requestIDs = append(requestIDs, fmt.Sprint(requestID))
+		}
+		text = fmt.Sprintf("{user} submitted %d of %d host reservations for approval in config change requests %s",
+			result.Submitted, result.Total, strings.Join(requestIDs, ", "))
+		if result.Imported > 0 {
+			text += fmt.Sprintf(" and imported %d host reservations", result.Imported)
+		}
+	}
+	r.EventCenter.AddInfoEvent(text, user)
+
+	rsp := dhcp.NewImportHostsOK().WithPayload(result)
+	return rsp
+}
+
+// Implements the GET call to export host reservations to a CSV or JSON
+// file (hosts/export). The exported file can be imported with the
+// ImportHosts call.
+func (r *RestAPI) ExportHosts(ctx context.Context, params dhcp.ExportHostsParams) middleware.Responder {
+	format := hostsio.FormatCSV
+	if params.Format != nil {
+		var err error
+		if format, err = hostsio.ParseFormat(*params.Format); err != nil {
+			msg := fmt.Sprintf("Invalid format of the host reservations: %s", err)
+			log.WithError(err).Error(msg)
+			rsp := dhcp.NewExportHostsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+	}
+	filters := dbmodel.HostsByPageFilters{
+		MachineID:     params.MachineID,
+		DaemonID:      params.DaemonID,
+		SubnetID:      params.SubnetID,
+		LocalSubnetID: params.LocalSubnetID,
+		FilterText:    params.Text,
+		Global:        params.Global,
+	}
+	dbHosts, _, err := dbmodel.GetHostsByPage(r.DB, 0, 0, filters, "", dbmodel.SortDirAny)
+	if err != nil {
+		msg := "Problem fetching hosts from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewExportHostsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	records := []hostsio.Record{}
+	for i := range dbHosts {
+		records = append(records, hostsio.NewRecord(&dbHosts[i]))
+	}
+	var buffer bytes.Buffer
+	if err = hostsio.Write(&buffer, format, records); err != nil {
+		msg := "Problem with exporting host reservations"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewExportHostsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	contentType := "text/csv"
+	if format == hostsio.FormatJSON {
+		contentType = "application/json"
+	}
+	dispositionHeaderValue := fmt.Sprintf(
+		"attachment; filename=\"stork-hosts_%s.%s\"",
+		strings.ReplaceAll(time.Now().UTC().Format(time.RFC3339), ":", "-"),
+		format,
+	)
+	rsp := dhcp.NewExportHostsOK().
+		WithContentType(contentType).
+		WithContentDisposition(dispositionHeaderValue).
+		WithPayload(io.NopCloser(&buffer))
+	return rsp
+}
@@ -0,0 +1,214 @@
+package restservice
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	"isc.org/stork/server/hostsio"
+	storktestdbmodel "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Test importing host reservations from a CSV file. The valid host
+// reservations should be added to the Kea servers and the errors should
+// be reported for the invalid ones.
+func TestImportHosts(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, daemons := storktestdbmodel.AddTestHosts(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	content := `subnet,identifier-type,identifier,hostname,ip-addresses
+192.0.2.0/24,hw-address,0a:0b:0c:0d:0e:0f,foo.example.org,192.0.2.10
+192.0.2.0/24,hw-address,0a0b0c0d0e0f,,
+192.0.3.0/24,hw-address,01:01:01:01:01:01,,
+192.0.2.0/24,hw-address,02:02:02:02:02:02,,2001:db8:1::1
+`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		HostsImport: &models.HostsImport{
+			Format:    storkutil.Ptr("csv"),
+			Content:   &content,
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 4, result.Total)
+	require.EqualValues(t, 1, result.Valid)
+	require.EqualValues(t, 1, result.Imported)
+	require.Len(t, result.Errors, 3)
+	require.EqualValues(t, 2, result.Errors[0].Row)
+	require.Contains(t, result.Errors[0].Message, "duplicate of the host reservation in row 1")
+	require.EqualValues(t, 3, result.Errors[1].Row)
+	require.Contains(t, result.Errors[1].Message, "subnet 192.0.3.0/24 does not exist")
+	require.EqualValues(t, 4, result.Errors[2].Row)
+
+	// The valid host reservation should be sent to the server.
+	require.Len(t, fa.RecordedCommands, 1)
+	require.EqualValues(t, "reservation-add", fa.RecordedCommands[0].GetCommand())
+
+	// The host reservation should be added to the database.
+	hosts, _, err := dbmodel.GetHostsByDaemonID(db, daemons[0].ID, dbmodel.HostDataSourceAPI)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, "foo.example.org", hosts[0].GetHostname())
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "imported 1 of 4 host reservations")
+}
+
+// Test that the host reservations are validated but not imported in the
+// dry run mode.
+func TestImportHostsDryRun(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, daemons := storktestdbmodel.AddTestHosts(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	content := `[
+		{
+			"subnet": "192.0.2.0/24",
+			"identifier-type": "hw-address",
+			"identifier": "0a:0b:0c:0d:0e:0f",
+			"ip-addresses": [ "192.0.2.10" ]
+		}
+	]`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		HostsImport: &models.HostsImport{
+			Format:    storkutil.Ptr("json"),
+			Content:   &content,
+			DaemonIds: []int64{daemons[0].ID},
+			DryRun:    true,
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 1, result.Total)
+	require.EqualValues(t, 1, result.Valid)
+	require.Zero(t, result.Imported)
+	require.Empty(t, result.Errors)
+
+	require.Empty(t, fa.RecordedCommands)
+	require.Empty(t, fec.Events)
+}
+
+// Test that the imported host reservations submitted for approval are
+// reported separately from the imported ones.
+func TestImportHostsApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	_, daemons := storktestdbmodel.AddTestHosts(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	content := `subnet,identifier-type,identifier,hostname,ip-addresses
+192.0.2.0/24,hw-address,0a:0b:0c:0d:0e:0f,foo.example.org,192.0.2.10
+192.0.2.0/24,hw-address,0a:0b:0c:0d:0e:01,bar.example.org,192.0.2.11
+`
+	rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+		HostsImport: &models.HostsImport{
+			Format:    storkutil.Ptr("csv"),
+			Content:   &content,
+			DaemonIds: []int64{daemons[0].ID},
+		},
+	})
+	require.IsType(t, &dhcp.ImportHostsOK{}, rsp)
+	result := rsp.(*dhcp.ImportHostsOK).Payload
+	require.EqualValues(t, 2, result.Total)
+	require.EqualValues(t, 2, result.Valid)
+	require.Zero(t, result.Imported)
+	require.EqualValues(t, 2, result.Submitted)
+	require.Empty(t, result.Errors)
+
+	requests, total, err := dbmodel.GetConfigChangeRequestsByPage(db, 0, 0, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Equal(t, []int64{requests[0].ID}, result.ConfigChangeRequestIds)
+
+	// Nothing should be sent until the request is approved.
+	require.Empty(t, fa.RecordedCommands)
+
+	require.Len(t, fec.Events, 2)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+	require.Contains(t, fec.Events[1].Text, "submitted 2 of 2 host reservations for approval")
+	require.NotContains(t, fec.Events[1].Text, "imported")
+}
+
+// Test that the import is rejected when the daemons are invalid.
+func TestImportHostsInvalidDaemons(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, daemons := storktestdbmodel.AddTestHosts(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	content := "identifier-type,identifier\nhw-address,01:02:03:04:05:06\n"
+	for _, daemonIDs := range [][]int64{{}, {daemons[0].ID, daemons[1].ID}, {12345}} {
+		rsp := rapi.ImportHosts(ctx, dhcp.ImportHostsParams{
+			HostsImport: &models.HostsImport{
+				Format:    storkutil.Ptr("csv"),
+				Content:   &content,
+				DaemonIds: daemonIDs,
+			},
+		})
+		require.IsType(t, &dhcp.ImportHostsDefault{}, rsp)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.ImportHostsDefault)))
+	}
+	require.Empty(t, fa.RecordedCommands)
+}
+
+// Test exporting the host reservations to a file.
+func TestExportHosts(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	hosts, _ := storktestdbmodel.AddTestHosts(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	for _, format := range []string{"csv", "json"} {
+		t.Run(format, func(t *testing.T) {
+			rsp := rapi.ExportHosts(ctx, dhcp.ExportHostsParams{
+				Format: &format,
+			})
+			require.IsType(t, &dhcp.ExportHostsOK{}, rsp)
+			okRsp := rsp.(*dhcp.ExportHostsOK)
+			require.Contains(t, okRsp.ContentDisposition, "."+format)
+
+			content, err := io.ReadAll(okRsp.Payload)
+			require.NoError(t, err)
+			records, err := hostsio.Read(strings.NewReader(string(content)), hostsio.Format(format))
+			require.NoError(t, err)
+			require.Len(t, records, len(hosts))
+		})
+	}
+
+	// Invalid format.
+	rsp := rapi.ExportHosts(ctx, dhcp.ExportHostsParams{
+		Format: storkutil.Ptr("xml"),
+	})
+	require.IsType(t, &dhcp.ExportHostsDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.ExportHostsDefault)))
+}
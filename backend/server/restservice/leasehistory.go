@@ -0,0 +1,184 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the lease event from the database to the REST API format. It
+// returns an error when the daemon of the event was not fetched.
+func convertLeaseEventToRestAPI(dbEvent *dbmodel.LeaseEvent) (*models.LeaseEvent, error) {
+	if dbEvent.Daemon == nil {
+		return nil, errors.New("database did not return a Daemon for this lease event")
+	}
+	eventType := string(dbEvent.EventType)
+	occurredAt := strfmt.DateTime(dbEvent.OccurredAt)
+	daemonLabel := dbEvent.Daemon.GetLabel()
+	event := &models.LeaseEvent{
+		ID:            &dbEvent.ID,
+		Type:          &eventType,
+		OccurredAt:    &occurredAt,
+		DaemonID:      &dbEvent.DaemonID,
+		DaemonLabel:   &daemonLabel,
+		SubnetID:      dbEvent.SubnetID,
+		LocalSubnetID: int64(dbEvent.LocalSubnetID),
+		IPAddress:     &dbEvent.IPAddress,
+		PrefixLength:  int64(dbEvent.PrefixLength),
+		HwAddress:     dbEvent.HWAddress,
+		Duid:          dbEvent.DUID.String(),
+		ClientID:      dbEvent.ClientID.String(),
+		Hostname:      dbEvent.Hostname,
+		Cltt:          dbEvent.CLTT,
+		ValidLifetime: int64(dbEvent.ValidLifetime),
+		State:         dbEvent.State,
+	}
+	if dbEvent.Subnet != nil {
+		event.SubnetPrefix = dbEvent.Subnet.Prefix
+	}
+	return event, nil
+}
+
+// Converts the lease events from the database to the REST API format.
+// The events that cannot be converted are skipped.
+func convertLeaseEventsToRestAPI(dbEvents []dbmodel.LeaseEvent, total int64) *models.LeaseEvents {
+	events := &models.LeaseEvents{
+		Items: make([]*models.LeaseEvent, 0, len(dbEvents)),
+		Total: total,
+	}
+	for i := range dbEvents {
+		event, err := convertLeaseEventToRestAPI(&dbEvents[i])
+		if err != nil {
+			log.WithError(err).Warn("Skipping the lease event that cannot be converted")
+			continue
+		}
+		events.Items = append(events.Items, event)
+	}
+	return events
+}
+
+// Parses the IP address or delegated prefix specified in the lease history
+// query. The lease events store the delegated prefixes without the prefix
+// length, so the prefix length is dropped.
+func parseLeaseEventAddress(address string) (string, bool) {
+	parsed := storkutil.ParseIP(address)
+	if parsed == nil {
+		return "", false
+	}
+	return parsed.IP.String(), true
+}
+
+// Checks if the user is allowed to access the lease history. It returns
+// a status code and an error message if the access is denied.
+func (r *RestAPI) checkLeaseHistoryAccess(ctx context.Context) (int, string) {
+	_, user := r.SessionManager.Logged(ctx)
+	if user == nil {
+		return http.StatusBadRequest, "Unable to identify the user requesting lease history (for access control purposes)"
+	}
+	if !user.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) &&
+		!user.InGroup(&dbmodel.SystemGroup{ID: dbmodel.AdminGroupID}) {
+		return http.StatusForbidden, "User is forbidden to access lease history"
+	}
+	return http.StatusOK, ""
+}
+
+// Returns the lease lifecycle events recorded by the monitored Kea servers.
+// It implements the /api/leases/history endpoint.
+func (r *RestAPI) GetLeaseHistory(ctx context.Context, params dhcp.GetLeaseHistoryParams) middleware.Responder {
+	if code, msg := r.checkLeaseHistoryAccess(ctx); code != http.StatusOK {
+		return dhcp.NewGetLeaseHistoryDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+
+	var start int64
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	var limit int64 = 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	filters := dbmodel.LeaseEventsByPageFilters{
+		DaemonID: params.DaemonID,
+		SubnetID: params.SubnetID,
+	}
+	if params.IPAddress != nil {
+		ipAddress, ok := parseLeaseEventAddress(*params.IPAddress)
+		if !ok {
+			msg := "Invalid IP address or prefix specified in the lease history query"
+			return dhcp.NewGetLeaseHistoryDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+		}
+		filters.IPAddress = &ipAddress
+	}
+	if params.Identifier != nil {
+		if !storkutil.IsHexIdentifier(*params.Identifier) {
+			msg := "Invalid client identifier specified in the lease history query"
+			return dhcp.NewGetLeaseHistoryDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+		}
+		filters.Identifier = params.Identifier
+	}
+	if params.From != nil {
+		from := time.Time(*params.From)
+		filters.Start = &from
+	}
+	if params.To != nil {
+		to := time.Time(*params.To)
+		filters.End = &to
+	}
+
+	dbEvents, total, err := dbmodel.GetLeaseEventsByPage(r.DB, start, limit, filters)
+	if err != nil {
+		msg := "Problem fetching lease history from the database"
+		log.WithError(err).Error(msg)
+		return dhcp.NewGetLeaseHistoryDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+	return dhcp.NewGetLeaseHistoryOK().WithPayload(convertLeaseEventsToRestAPI(dbEvents, total))
+}
+
+// Returns the events which assigned or renewed the lease of the specified
+// IP address that was held by a client at the specified time. It implements
+// the /api/leases/holders endpoint.
+func (r *RestAPI) GetLeaseHolders(ctx context.Context, params dhcp.GetLeaseHoldersParams) middleware.Responder {
+	if code, msg := r.checkLeaseHistoryAccess(ctx); code != http.StatusOK {
+		return dhcp.NewGetLeaseHoldersDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+
+	ipAddress, ok := parseLeaseEventAddress(params.IPAddress)
+	if !ok {
+		msg := "Invalid IP address or prefix specified in the lease holders query"
+		return dhcp.NewGetLeaseHoldersDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+
+	dbEvents, err := dbmodel.GetLeaseHolders(r.DB, ipAddress, time.Time(params.At))
+	if err != nil {
+		msg := "Problem fetching lease holders from the database"
+		log.WithError(err).Error(msg)
+		return dhcp.NewGetLeaseHoldersDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+	return dhcp.NewGetLeaseHoldersOK().WithPayload(convertLeaseEventsToRestAPI(dbEvents, int64(len(dbEvents))))
+}
@@ -0,0 +1,232 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	keadata "isc.org/stork/daemondata/kea"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Adds lease events of two clients sharing the same address to the database.
+// The first client holds the lease for an hour and releases it. The second
+// client gets the lease two hours later.
+func helperSetUpLeaseEvents(t *testing.T, db *dbops.PgDB) time.Time {
+	leases, _ := helperSetUpLeases(t, db)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	duid1 := "01:01:01:01"
+	duid2 := "02:02:02:02"
+	var events []*dbmodel.LeaseEvent
+	for i, item := range []struct {
+		eventType     keadata.LeaseEventType
+		duid          *string
+		offset        time.Duration
+		validLifetime uint32
+	}{
+		{keadata.LeaseEventAssigned, &duid1, 0, 3600},
+		{keadata.LeaseEventReleased, &duid1, time.Hour, 0},
+		{keadata.LeaseEventAssigned, &duid2, 2 * time.Hour, 3600},
+	} {
+		occurredAt := start.Add(item.offset)
+		events = append(events, &dbmodel.LeaseEvent{
+			EventType:  item.eventType,
+			OccurredAt: occurredAt,
+			ObservedAt: int64(i + 1),
+			Lease: keadata.Lease{
+				Family:        6,
+				IPAddress:     "2001:db8:1::1",
+				DUID:          keadata.NewColonSepHexStr(item.duid),
+				CLTT:          uint64(occurredAt.Unix()), // #nosec G115
+				ValidLifetime: item.validLifetime,
+				LocalSubnetID: 123,
+			},
+			DaemonID: leases[0].DaemonID,
+			SubnetID: leases[0].SubnetID,
+		})
+	}
+	err := dbmodel.AddLeaseEvents(db, events)
+	require.NoError(t, err)
+	return start
+}
+
+// Test that the lease history is not returned to the users who are not
+// allowed to access the leases.
+func TestGetLeaseHistoryAccessControl(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	// The user is not logged in.
+	rsp := rapi.GetLeaseHistory(ctx, dhcp.GetLeaseHistoryParams{})
+	require.IsType(t, &dhcp.GetLeaseHistoryDefault{}, rsp)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.GetLeaseHistoryDefault)))
+
+	user := &dbmodel.SystemUser{
+		Email:    "read.only@example.org",
+		Lastname: "Only",
+		Name:     "Read",
+		Groups: []*dbmodel.SystemGroup{
+			{ID: dbmodel.ReadOnlyGroupID},
+		},
+	}
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	rsp = rapi.GetLeaseHistory(ctx, dhcp.GetLeaseHistoryParams{})
+	require.IsType(t, &dhcp.GetLeaseHistoryDefault{}, rsp)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*rsp.(*dhcp.GetLeaseHistoryDefault)))
+
+	holdersRsp := rapi.GetLeaseHolders(ctx, dhcp.GetLeaseHoldersParams{IPAddress: "192.0.2.1"})
+	require.IsType(t, &dhcp.GetLeaseHoldersDefault{}, holdersRsp)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*holdersRsp.(*dhcp.GetLeaseHoldersDefault)))
+
+	legalLogRsp := rapi.SearchLegalLog(ctx, dhcp.SearchLegalLogParams{DaemonID: 1})
+	require.IsType(t, &dhcp.SearchLegalLogDefault{}, legalLogRsp)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*legalLogRsp.(*dhcp.SearchLegalLogDefault)))
+}
+
+// Test getting the history of the client's leases.
+func TestGetLeaseHistory(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	start := helperSetUpLeaseEvents(t, db)
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := exampleSuperAdmin()
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	t.Run("all events", func(t *testing.T) {
+		rsp := rapi.GetLeaseHistory(ctx, dhcp.GetLeaseHistoryParams{})
+		require.IsType(t, &dhcp.GetLeaseHistoryOK{}, rsp)
+		events := rsp.(*dhcp.GetLeaseHistoryOK).Payload
+		require.EqualValues(t, 3, events.Total)
+		require.Len(t, events.Items, 3)
+		require.Equal(t, string(keadata.LeaseEventAssigned), *events.Items[0].Type)
+		require.Equal(t, "02:02:02:02", events.Items[0].Duid)
+		require.Equal(t, "2001:db8:1::/64", events.Items[0].SubnetPrefix)
+		require.NotEmpty(t, *events.Items[0].DaemonLabel)
+	})
+
+	t.Run("client in time range", func(t *testing.T) {
+		identifier := "01010101"
+		from := strfmt.DateTime(start)
+		to := strfmt.DateTime(start.Add(30 * time.Minute))
+		rsp := rapi.GetLeaseHistory(ctx, dhcp.GetLeaseHistoryParams{
+			Identifier: &identifier,
+			From:       &from,
+			To:         &to,
+		})
+		require.IsType(t, &dhcp.GetLeaseHistoryOK{}, rsp)
+		events := rsp.(*dhcp.GetLeaseHistoryOK).Payload
+		require.EqualValues(t, 1, events.Total)
+		require.Equal(t, "2001:db8:1::1", *events.Items[0].IPAddress)
+		require.Equal(t, strfmt.DateTime(start), *events.Items[0].OccurredAt)
+	})
+
+	t.Run("invalid identifier", func(t *testing.T) {
+		identifier := "foo"
+		rsp := rapi.GetLeaseHistory(ctx, dhcp.GetLeaseHistoryParams{Identifier: &identifier})
+		require.IsType(t, &dhcp.GetLeaseHistoryDefault{}, rsp)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.GetLeaseHistoryDefault)))
+	})
+
+	t.Run("invalid IP address", func(t *testing.T) {
+		ipAddress := "2001:db8:1::z"
+		rsp := rapi.GetLeaseHistory(ctx, dhcp.GetLeaseHistoryParams{IPAddress: &ipAddress})
+		require.IsType(t, &dhcp.GetLeaseHistoryDefault{}, rsp)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.GetLeaseHistoryDefault)))
+	})
+}
+
+// Test getting the client holding the lease at the specified time.
+func TestGetLeaseHolders(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	start := helperSetUpLeaseEvents(t, db)
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := exampleSuperAdmin()
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	rsp := rapi.GetLeaseHolders(ctx, dhcp.GetLeaseHoldersParams{
+		IPAddress: "2001:db8:1::1",
+		At:        strfmt.DateTime(start.Add(30 * time.Minute)),
+	})
+	require.IsType(t, &dhcp.GetLeaseHoldersOK{}, rsp)
+	holders := rsp.(*dhcp.GetLeaseHoldersOK).Payload
+	require.Len(t, holders.Items, 1)
+	require.Equal(t, "01:01:01:01", holders.Items[0].Duid)
+
+	// The lease was released in the meantime.
+	rsp = rapi.GetLeaseHolders(ctx, dhcp.GetLeaseHoldersParams{
+		IPAddress: "2001:db8:1::1",
+		At:        strfmt.DateTime(start.Add(90 * time.Minute)),
+	})
+	require.IsType(t, &dhcp.GetLeaseHoldersOK{}, rsp)
+	require.Empty(t, rsp.(*dhcp.GetLeaseHoldersOK).Payload.Items)
+
+	rsp = rapi.GetLeaseHolders(ctx, dhcp.GetLeaseHoldersParams{
+		IPAddress: "2001:db8:1::1",
+		At:        strfmt.DateTime(start.Add(150 * time.Minute)),
+	})
+	require.IsType(t, &dhcp.GetLeaseHoldersOK{}, rsp)
+	holders = rsp.(*dhcp.GetLeaseHoldersOK).Payload
+	require.Len(t, holders.Items, 1)
+	require.Equal(t, "02:02:02:02", holders.Items[0].Duid)
+}
+
+// Test converting the lease event without the daemon.
+func TestConvertLeaseEventToRestAPIWithNilDaemon(t *testing.T) {
+	event, err := convertLeaseEventToRestAPI(&dbmodel.LeaseEvent{})
+	require.Nil(t, event)
+	require.ErrorContains(t, err, "Daemon")
+
+	events := convertLeaseEventsToRestAPI([]dbmodel.LeaseEvent{{}}, 1)
+	require.Empty(t, events.Items)
+	require.EqualValues(t, 1, events.Total)
+}
+
+// Test that the prefix length is dropped from the queried address.
+func TestParseLeaseEventAddress(t *testing.T) {
+	address, ok := parseLeaseEventAddress("2001:db8:1::/48")
+	require.True(t, ok)
+	require.Equal(t, "2001:db8:1::", address)
+
+	address, ok = parseLeaseEventAddress("192.0.2.1")
+	require.True(t, ok)
+	require.Equal(t, "192.0.2.1", address)
+
+	_, ok = parseLeaseEventAddress("foo")
+	require.False(t, ok)
+}
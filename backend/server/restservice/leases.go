@@ -2,17 +2,24 @@ package restservice
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"regexp"
 	"strings"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	keadata "isc.org/stork/daemondata/kea"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/config"
 	"isc.org/stork/server/daemons/kea"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/gen/models"
 	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
 )
 
 // This call searches for leases allocated by monitored DHCP servers.
@@ -135,3 +142,364 @@ func (r *RestAPI) GetLeases(ctx context.Context, params dhcp.GetLeasesParams) mi
 	rsp := dhcp.NewGetLeasesOK().WithPayload(leases)
 	return rsp
 }
+
+// Fetches the DHCP daemon for which the lease is added, updated, deleted or
+// wiped. It returns an HTTP status code and an error message when the daemon
+// cannot be fetched, does not exist or is not a DHCP daemon.
+func (r *RestAPI) getLeaseDaemon(daemonID int64) (*dbmodel.Daemon, int, string) {
+	daemon, err := dbmodel.GetKeaDaemonByID(r.DB, daemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching daemon with ID %d from db", daemonID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if daemon == nil {
+		return nil, http.StatusNotFound, fmt.Sprintf("Cannot find daemon with ID %d", daemonID)
+	}
+	if !daemon.Name.IsDHCP() {
+		return nil, http.StatusBadRequest, fmt.Sprintf("Daemon with ID %d is not a DHCP server", daemonID)
+	}
+	return daemon, 0, ""
+}
+
+// Checks that the lease IP address is a valid IP address of the family
+// served by the DHCP daemon. It returns the address in the canonical form.
+func validateLeaseIPAddress(address string, daemon *dbmodel.Daemon) (string, error) {
+	ip := net.ParseIP(strings.TrimSpace(address))
+	if ip == nil {
+		return "", errors.Errorf("%s is not a valid IP address", address)
+	}
+	isIPv4 := ip.To4() != nil
+	switch {
+	case daemon.Name == daemonname.DHCPv6 && isIPv4:
+		return "", errors.Errorf("%s is not an IPv6 address", address)
+	case daemon.Name != daemonname.DHCPv6 && !isIPv4:
+		return "", errors.Errorf("%s is not an IPv4 address", address)
+	}
+	return ip.String(), nil
+}
+
+// Converts the lease received over the REST API to the lease in the Stork
+// database format. The returned lease is associated with the specified daemon
+// and with the Stork subnet matching the local subnet ID, if such a subnet
+// exists.
+func (r *RestAPI) convertLeaseFromRestAPI(restLease *models.EditedLease, daemon *dbmodel.Daemon) (*dbmodel.Lease, error) {
+	if restLease.IPAddress == nil || len(strings.TrimSpace(*restLease.IPAddress)) == 0 {
+		return nil, errors.New("lease IP address must not be empty")
+	}
+	ipAddress, err := validateLeaseIPAddress(*restLease.IPAddress, daemon)
+	if err != nil {
+		return nil, err
+	}
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Lease: keadata.Lease{
+			Family:        storkutil.IPv4,
+			IPAddress:     ipAddress,
+			HWAddress:     restLease.HwAddress,
+			Hostname:      restLease.Hostname,
+			FqdnFwd:       restLease.FqdnFwd,
+			FqdnRev:       restLease.FqdnRev,
+			State:         restLease.State,
+			LocalSubnetID: uint32(restLease.LocalSubnetID), // #nosec G115
+			ValidLifetime: uint32(restLease.ValidLifetime), // #nosec G115
+		},
+	}
+	if len(restLease.ClientID) > 0 {
+		lease.ClientID = keadata.NewColonSepHexStr(&restLease.ClientID)
+	}
+	if restLease.UserContext != nil {
+		userContext, ok := restLease.UserContext.(map[string]any)
+		if !ok {
+			return nil, errors.New("lease user context must be a map")
+		}
+		lease.UserContext = userContext
+	}
+	if daemon.Name == daemonname.DHCPv6 {
+		if len(restLease.Duid) == 0 {
+			return nil, errors.New("DUID is required for a DHCPv6 lease")
+		}
+		lease.Family = storkutil.IPv6
+		lease.DUID = keadata.NewColonSepHexStr(&restLease.Duid)
+		lease.IAID = uint32(restLease.Iaid)                           // #nosec G115
+		lease.PreferredLifetime = uint32(restLease.PreferredLifetime) // #nosec G115
+		lease.PrefixLength = uint8(restLease.PrefixLength)            // #nosec G115
+		lease.Type = restLease.LeaseType
+		if len(lease.Type) == 0 {
+			lease.Type = "IA_NA"
+		}
+	} else if len(lease.HWAddress) == 0 {
+		return nil, errors.New("hardware address is required for a DHCPv4 lease")
+	}
+	// Associate the lease with the Stork subnet.
+	subnetID, err := dbmodel.GetSubnetIDByDaemonIDAndLocalID(r.DB, daemon.ID, lease.LocalSubnetID)
+	if err != nil {
+		return nil, err
+	}
+	if subnetID != nil {
+		lease.SubnetID = *subnetID
+	}
+	return lease, nil
+}
+
+// Returns an HTTP status code and an error message appropriate for the
+// error returned while applying the lease change.
+func getLeaseApplyErrorResponse(err error, defaultMsg string) (int, string) {
+	var hooksNotConfigured *config.NoLeaseCmdsHookError
+	if errors.As(err, &hooksNotConfigured) {
+		return http.StatusBadRequest, "Unable to modify the leases because the daemon lacks the libdhcp_lease_cmds hook library"
+	}
+	return http.StatusInternalServerError, defaultMsg
+}
+
+// Implements the POST call to add a new lease to a DHCP server. It sends
+// the lease4-add or lease6-add command to the server via the config manager.
+func (r *RestAPI) AddLease(ctx context.Context, params dhcp.AddLeaseParams) middleware.Responder {
+	if params.Lease == nil || params.Lease.DaemonID == nil {
+		msg := "Lease and daemon ID must be specified to add a lease"
+		log.Error(msg)
+		rsp := dhcp.NewAddLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	daemon, code, msg := r.getLeaseDaemon(*params.Lease.DaemonID)
+	if daemon == nil {
+		rsp := dhcp.NewAddLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	lease, err := r.convertLeaseFromRestAPI(params.Lease, daemon)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid lease specified: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewAddLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for adding the lease"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewAddLeaseDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea command to add the lease.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyLeaseAdd(cctx, lease)
+	if err != nil {
+		code, msg := getLeaseApplyErrorResponse(err, "Problem with preparing commands for adding the lease")
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewAddLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the command to Kea server or submit it for approval.
+	cctx, code, msg = r.commitOrScheduleConfigChange(cctx, user, nil, "lease addition")
+	if code != 0 {
+		rsp := dhcp.NewAddLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if isConfigChangeCommitted(cctx) {
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} added lease %s to {daemon}", lease.IPAddress), user, daemon)
+	}
+
+	rsp := dhcp.NewAddLeaseOK()
+	return rsp
+}
+
+// Implements the PUT call to update an existing lease in a DHCP server. It
+// sends the lease4-update or lease6-update command to the server via the
+// config manager.
+func (r *RestAPI) UpdateLease(ctx context.Context, params dhcp.UpdateLeaseParams) middleware.Responder {
+	if params.Lease == nil || params.Lease.DaemonID == nil {
+		msg := "Lease and daemon ID must be specified to update a lease"
+		log.Error(msg)
+		rsp := dhcp.NewUpdateLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	daemon, code, msg := r.getLeaseDaemon(*params.Lease.DaemonID)
+	if daemon == nil {
+		rsp := dhcp.NewUpdateLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	lease, err := r.convertLeaseFromRestAPI(params.Lease, daemon)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid lease specified: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for updating the lease"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateLeaseDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea command to update the lease.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyLeaseUpdate(cctx, lease, params.Lease.ForceCreate)
+	if err != nil {
+		code, msg := getLeaseApplyErrorResponse(err, "Problem with preparing commands for updating the lease")
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewUpdateLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the command to Kea server or submit it for approval.
+	cctx, code, msg = r.commitOrScheduleConfigChange(cctx, user, nil, "lease update")
+	if code != 0 {
+		rsp := dhcp.NewUpdateLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if isConfigChangeCommitted(cctx) {
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} updated lease %s in {daemon}", lease.IPAddress), user, daemon)
+	}
+
+	rsp := dhcp.NewUpdateLeaseOK()
+	return rsp
+}
+
+// Implements the DELETE call to delete a lease from a DHCP server. It sends
+// the lease4-del or lease6-del command to the server via the config manager.
+func (r *RestAPI) DeleteLease(ctx context.Context, params dhcp.DeleteLeaseParams) middleware.Responder {
+	daemon, code, msg := r.getLeaseDaemon(params.DaemonID)
+	if daemon == nil {
+		rsp := dhcp.NewDeleteLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	ipAddress, err := validateLeaseIPAddress(params.IPAddress, daemon)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid lease specified: %s", err)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteLeaseDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Daemon:   daemon,
+		Lease: keadata.Lease{
+			IPAddress: ipAddress,
+		},
+	}
+	if params.LeaseType != nil {
+		lease.Type = *params.LeaseType
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for deleting the lease"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteLeaseDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea command to delete the lease.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyLeaseDelete(cctx, lease)
+	if err != nil {
+		code, msg := getLeaseApplyErrorResponse(err, "Problem with preparing commands for deleting the lease")
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewDeleteLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the command to Kea server or submit it for approval.
+	cctx, code, msg = r.commitOrScheduleConfigChange(cctx, user, nil, "lease deletion")
+	if code != 0 {
+		rsp := dhcp.NewDeleteLeaseDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if isConfigChangeCommitted(cctx) {
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} deleted lease %s from {daemon}", lease.IPAddress), user, daemon)
+	}
+
+	rsp := dhcp.NewDeleteLeaseOK()
+	return rsp
+}
+
+// Implements the POST call to wipe the leases from a subnet or from all
+// subnets of a DHCP server. It sends the lease4-wipe or lease6-wipe command
+// to the server via the config manager.
+func (r *RestAPI) WipeLeases(ctx context.Context, params dhcp.WipeLeasesParams) middleware.Responder {
+	daemon, code, msg := r.getLeaseDaemon(params.DaemonID)
+	if daemon == nil {
+		rsp := dhcp.NewWipeLeasesDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	var localSubnetID int64
+	if params.LocalSubnetID != nil {
+		localSubnetID = *params.LocalSubnetID
+	}
+	// Create configuration context.
+	_, user := r.SessionManager.Logged(ctx)
+	cctx, err := r.ConfigManager.CreateContext(user.ID)
+	if err != nil {
+		msg := "Problem with creating transaction context for wiping the leases"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewWipeLeasesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Create Kea command to wipe the leases.
+	cctx, err = r.ConfigManager.GetKeaModule().ApplyLeaseWipe(cctx, daemon, localSubnetID)
+	if err != nil {
+		code, msg := getLeaseApplyErrorResponse(err, "Problem with preparing commands for wiping the leases")
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewWipeLeasesDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Send the command to Kea server or submit it for approval.
+	cctx, code, msg = r.commitOrScheduleConfigChange(cctx, user, nil, "lease wipe")
+	if code != 0 {
+		rsp := dhcp.NewWipeLeasesDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if isConfigChangeCommitted(cctx) {
+		if localSubnetID != 0 {
+			r.EventCenter.AddWarningEvent(fmt.Sprintf("{user} wiped leases from subnet %d of {daemon}", localSubnetID), user, daemon)
+		} else {
+			r.EventCenter.AddWarningEvent("{user} wiped leases from all subnets of {daemon}", user, daemon)
+		}
+	}
+
+	rsp := dhcp.NewWipeLeasesOK()
+	return rsp
+}
@@ -3,17 +3,26 @@ package restservice
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	keactrl "isc.org/stork/daemonctrl/kea"
+	keadata "isc.org/stork/daemondata/kea"
 	"isc.org/stork/datamodel/daemonname"
 	"isc.org/stork/datamodel/protocoltype"
 	agentcomm "isc.org/stork/server/agentcomm"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
+	daemonsconfig "isc.org/stork/server/daemons"
+	daemonstest "isc.org/stork/server/daemons/test"
+	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
 	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktestdbmodel "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
 )
 
 // Generates a success mock response to a command fetching a DHCPv4
@@ -703,3 +712,535 @@ func TestFindLeasesByHostID(t *testing.T) {
 	require.Len(t, okRsp.Payload.Conflicts, 1)
 	require.EqualValues(t, *okRsp.Payload.Items[1].ID, okRsp.Payload.Conflicts[0])
 }
+
+// Adds a machine and a Kea daemon with the lease_cmds hook library to the
+// database. It is used in the tests modifying the leases.
+func addTestLeaseCmdsDaemon(t *testing.T, db *dbops.PgDB, name daemonname.Name) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		ID:        0,
+		Address:   "machine",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	accessPoints := []*dbmodel.AccessPoint{
+		{
+			Type:     dbmodel.AccessPointControl,
+			Address:  "localhost",
+			Port:     8000,
+			Key:      "",
+			Protocol: protocoltype.HTTPS,
+		},
+	}
+	daemon := dbmodel.NewDaemon(machine, name, true, accessPoints)
+	rootName := "Dhcp4"
+	if name == daemonname.DHCPv6 {
+		rootName = "Dhcp6"
+	}
+	config := fmt.Sprintf(`{
+		"%s": {
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_lease_cmds.so"
+				}
+			]
+		}
+	}`, rootName)
+	err = daemon.SetKeaConfigFromJSON([]byte(config))
+	require.NoError(t, err)
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+	return daemon
+}
+
+// Creates the REST API instance with the config manager and the fake
+// event center. It also creates a user session.
+func setupLeaseCmdsRestAPI(t *testing.T, db *dbops.PgDB, dbSettings *dbops.DatabaseSettings, fa *agentcommtest.FakeAgents) (*RestAPI, *storktestdbmodel.FakeEventCenter, context.Context) {
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := daemonsconfig.NewManager(&daemonstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+	require.NotNil(t, cm)
+
+	fec := &storktestdbmodel.FakeEventCenter{}
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup, fec)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := &dbmodel.SystemUser{
+		ID: 1234,
+	}
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	return rapi, fec, ctx
+}
+
+// Test that a DHCPv4 lease can be added over the REST API.
+func TestAddLease(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := dhcp.AddLeaseParams{
+		Lease: &models.EditedLease{
+			DaemonID:      &daemon.ID,
+			IPAddress:     storkutil.Ptr("192.0.2.10"),
+			HwAddress:     "01:02:03:04:05:06",
+			LocalSubnetID: 1,
+			ValidLifetime: 3600,
+			Hostname:      "client.example.org",
+		},
+	}
+	rsp := rapi.AddLease(ctx, params)
+	require.IsType(t, &dhcp.AddLeaseOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-add",
+		"service": ["dhcp4"],
+		"arguments": {
+			"ip-address": "192.0.2.10",
+			"subnet-id": 1,
+			"hw-address": "01:02:03:04:05:06",
+			"valid-lft": 3600,
+			"hostname": "client.example.org",
+			"state": 0
+		}
+	}`, string(commandMarshaled))
+
+	// The lease should be stored in the database.
+	leases, total, err := dbmodel.GetLeasesByPage(db, 0, 10, dbmodel.LeasesByPageFilters{}, "", dbmodel.SortDirAny)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Equal(t, "192.0.2.10", leases[0].IPAddress)
+
+	// The event should be recorded.
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "added lease 192.0.2.10")
+}
+
+// Test that adding a lease fails when the daemon does not exist or when
+// the specified lease is invalid.
+func TestAddLeaseError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv6)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	t.Run("non-existing daemon", func(t *testing.T) {
+		params := dhcp.AddLeaseParams{
+			Lease: &models.EditedLease{
+				DaemonID:  storkutil.Ptr(int64(12345)),
+				IPAddress: storkutil.Ptr("2001:db8:1::1"),
+			},
+		}
+		rsp := rapi.AddLease(ctx, params)
+		require.IsType(t, &dhcp.AddLeaseDefault{}, rsp)
+		defaultRsp := rsp.(*dhcp.AddLeaseDefault)
+		require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("missing DUID", func(t *testing.T) {
+		params := dhcp.AddLeaseParams{
+			Lease: &models.EditedLease{
+				DaemonID:  &daemon.ID,
+				IPAddress: storkutil.Ptr("2001:db8:1::1"),
+			},
+		}
+		rsp := rapi.AddLease(ctx, params)
+		require.IsType(t, &dhcp.AddLeaseDefault{}, rsp)
+		defaultRsp := rsp.(*dhcp.AddLeaseDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("invalid IP address", func(t *testing.T) {
+		params := dhcp.AddLeaseParams{
+			Lease: &models.EditedLease{
+				DaemonID:  &daemon.ID,
+				IPAddress: storkutil.Ptr("2001:db8:1::x"),
+				Duid:      "01:02:03:04",
+			},
+		}
+		rsp := rapi.AddLease(ctx, params)
+		require.IsType(t, &dhcp.AddLeaseDefault{}, rsp)
+		defaultRsp := rsp.(*dhcp.AddLeaseDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Contains(t, *defaultRsp.Payload.Message, "2001:db8:1::x is not a valid IP address")
+	})
+
+	t.Run("IPv4 address for DHCPv6 server", func(t *testing.T) {
+		params := dhcp.AddLeaseParams{
+			Lease: &models.EditedLease{
+				DaemonID:  &daemon.ID,
+				IPAddress: storkutil.Ptr("192.0.2.10"),
+				Duid:      "01:02:03:04",
+			},
+		}
+		rsp := rapi.AddLease(ctx, params)
+		require.IsType(t, &dhcp.AddLeaseDefault{}, rsp)
+		defaultRsp := rsp.(*dhcp.AddLeaseDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Contains(t, *defaultRsp.Payload.Message, "192.0.2.10 is not an IPv6 address")
+	})
+
+	require.Empty(t, fa.RecordedCommands)
+	require.Empty(t, fec.Events)
+}
+
+// Test that a DHCPv6 lease can be updated over the REST API.
+func TestUpdateLease(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv6)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := dhcp.UpdateLeaseParams{
+		Lease: &models.EditedLease{
+			DaemonID:      &daemon.ID,
+			IPAddress:     storkutil.Ptr("2001:db8:1::1"),
+			Duid:          "01:02:03:04",
+			Iaid:          12,
+			ValidLifetime: 3600,
+			ForceCreate:   true,
+		},
+	}
+	rsp := rapi.UpdateLease(ctx, params)
+	require.IsType(t, &dhcp.UpdateLeaseOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-update",
+		"service": ["dhcp6"],
+		"arguments": {
+			"ip-address": "2001:db8:1::1",
+			"duid": "01:02:03:04",
+			"iaid": 12,
+			"type": "IA_NA",
+			"valid-lft": 3600,
+			"preferred-lft": 0,
+			"state": 0,
+			"force-create": true
+		}
+	}`, string(commandMarshaled))
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "updated lease 2001:db8:1::1")
+}
+
+// Test that a lease can be deleted over the REST API.
+func TestDeleteLease(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv6)
+
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Lease: keadata.Lease{
+			Family:        storkutil.IPv6,
+			IPAddress:     "2001:db8:1::",
+			Type:          string(keactrl.LeaseTypePD),
+			PrefixLength:  64,
+			ValidLifetime: 3600,
+		},
+	}
+	err := dbmodel.AddLease(db, lease)
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := dhcp.DeleteLeaseParams{
+		DaemonID:  daemon.ID,
+		IPAddress: "2001:db8:1::",
+		LeaseType: storkutil.Ptr(string(keactrl.LeaseTypePD)),
+	}
+	rsp := rapi.DeleteLease(ctx, params)
+	require.IsType(t, &dhcp.DeleteLeaseOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease6-del",
+		"service": ["dhcp6"],
+		"arguments": {
+			"ip-address": "2001:db8:1::",
+			"type": "IA_PD"
+		}
+	}`, string(commandMarshaled))
+
+	returnedLease, err := dbmodel.GetLeaseByID(db, lease.ID)
+	require.NoError(t, err)
+	require.Nil(t, returnedLease)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "deleted lease 2001:db8:1::")
+}
+
+// Test that deleting a lease returns an error when Kea returns an error.
+func TestDeleteLeaseKeaError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	fa := agentcommtest.NewFakeAgents(func(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []interface{}) {
+		mockStatusError(cmdResponses)
+	}, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := dhcp.DeleteLeaseParams{
+		DaemonID:  daemon.ID,
+		IPAddress: "192.0.2.10",
+	}
+	rsp := rapi.DeleteLease(ctx, params)
+	require.IsType(t, &dhcp.DeleteLeaseDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.DeleteLeaseDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+
+	require.Empty(t, fec.Events)
+}
+
+// Test that deleting a lease with an invalid IP address or an address of
+// the wrong family is rejected before sending the command to Kea.
+func TestDeleteLeaseInvalidAddress(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	for _, address := range []string{"192.0.2", "2001:db8:1::1"} {
+		rsp := rapi.DeleteLease(ctx, dhcp.DeleteLeaseParams{
+			DaemonID:  daemon.ID,
+			IPAddress: address,
+		})
+		require.IsType(t, &dhcp.DeleteLeaseDefault{}, rsp)
+		defaultRsp := rsp.(*dhcp.DeleteLeaseDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	}
+
+	require.Empty(t, fa.RecordedCommands)
+	require.Empty(t, fec.Events)
+}
+
+// Test validating the lease IP address against the daemon family.
+func TestValidateLeaseIPAddress(t *testing.T) {
+	dhcp4 := &dbmodel.Daemon{Name: daemonname.DHCPv4}
+	dhcp6 := &dbmodel.Daemon{Name: daemonname.DHCPv6}
+
+	address, err := validateLeaseIPAddress(" 192.0.2.10 ", dhcp4)
+	require.NoError(t, err)
+	require.Equal(t, "192.0.2.10", address)
+
+	address, err = validateLeaseIPAddress("2001:DB8:1:0::1", dhcp6)
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8:1::1", address)
+
+	_, err = validateLeaseIPAddress("192.0.2.256", dhcp4)
+	require.ErrorContains(t, err, "is not a valid IP address")
+
+	_, err = validateLeaseIPAddress("192.0.2.0/24", dhcp4)
+	require.ErrorContains(t, err, "is not a valid IP address")
+
+	_, err = validateLeaseIPAddress("2001:db8:1::1", dhcp4)
+	require.ErrorContains(t, err, "is not an IPv4 address")
+
+	_, err = validateLeaseIPAddress("192.0.2.10", dhcp6)
+	require.ErrorContains(t, err, "is not an IPv6 address")
+}
+
+// Test that the leases can be wiped from a subnet over the REST API.
+func TestWipeLeases(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := dhcp.WipeLeasesParams{
+		DaemonID:      daemon.ID,
+		LocalSubnetID: storkutil.Ptr(int64(12)),
+	}
+	rsp := rapi.WipeLeases(ctx, params)
+	require.IsType(t, &dhcp.WipeLeasesOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	commandMarshaled, err := fa.RecordedCommands[0].Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "lease4-wipe",
+		"service": ["dhcp4"],
+		"arguments": {
+			"subnet-id": 12
+		}
+	}`, string(commandMarshaled))
+
+	require.Len(t, fec.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, fec.Events[0].Level)
+	require.Contains(t, fec.Events[0].Text, "wiped leases from subnet 12")
+}
+
+// Test that adding a lease is submitted for approval instead of being
+// committed when the config change approval is enabled.
+func TestAddLeaseApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.AddLease(ctx, dhcp.AddLeaseParams{
+		Lease: &models.EditedLease{
+			DaemonID:      &daemon.ID,
+			IPAddress:     storkutil.Ptr("192.0.2.10"),
+			HwAddress:     "01:02:03:04:05:06",
+			LocalSubnetID: 1,
+			ValidLifetime: 3600,
+		},
+	})
+	require.IsType(t, &dhcp.AddLeaseOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaLeaseAdd)
+
+	// The lease should not be stored until the request is approved.
+	_, total, err := dbmodel.GetLeasesByPage(db, 0, 10, dbmodel.LeasesByPageFilters{}, "", dbmodel.SortDirAny)
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+}
+
+// Test that updating a lease is submitted for approval instead of being
+// committed when the config change approval is enabled.
+func TestUpdateLeaseApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv6)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.UpdateLease(ctx, dhcp.UpdateLeaseParams{
+		Lease: &models.EditedLease{
+			DaemonID:      &daemon.ID,
+			IPAddress:     storkutil.Ptr("2001:db8:1::1"),
+			Duid:          "01:02:03:04",
+			Iaid:          12,
+			ValidLifetime: 3600,
+		},
+	})
+	require.IsType(t, &dhcp.UpdateLeaseOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaLeaseUpdate)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+}
+
+// Test that deleting a lease is submitted for approval instead of being
+// committed when the config change approval is enabled.
+func TestDeleteLeaseApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	lease := &dbmodel.Lease{
+		DaemonID: daemon.ID,
+		Lease: keadata.Lease{
+			Family:        storkutil.IPv4,
+			IPAddress:     "192.0.2.10",
+			ValidLifetime: 3600,
+		},
+	}
+	err = dbmodel.AddLease(db, lease)
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.DeleteLease(ctx, dhcp.DeleteLeaseParams{
+		DaemonID:  daemon.ID,
+		IPAddress: "192.0.2.10",
+	})
+	require.IsType(t, &dhcp.DeleteLeaseOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaLeaseDelete)
+
+	// The lease should not be deleted until the request is approved.
+	returnedLease, err := dbmodel.GetLeaseByID(db, lease.ID)
+	require.NoError(t, err)
+	require.NotNil(t, returnedLease)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+}
+
+// Test that wiping the leases is submitted for approval instead of being
+// committed when the config change approval is enabled.
+func TestWipeLeasesApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	daemon := addTestLeaseCmdsDaemon(t, db, daemonname.DHCPv4)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.WipeLeases(ctx, dhcp.WipeLeasesParams{
+		DaemonID:      daemon.ID,
+		LocalSubnetID: storkutil.Ptr(int64(12)),
+	})
+	require.IsType(t, &dhcp.WipeLeasesOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaLeaseWipe)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "submitted config change request")
+}
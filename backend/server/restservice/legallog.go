@@ -0,0 +1,125 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/agentcomm"
+	"isc.org/stork/server/daemons/kea"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Default maximum number of the forensic log entries returned by the
+// search. The log files may be large, so the search is always limited.
+const legalLogDefaultLimit int64 = 100
+
+// Converts the legal log entry found by the agent to the REST API format.
+func convertLegalLogEntryToRestAPI(entry *kea.LegalLogEntry) *models.LegalLogEntry {
+	daemonLabel := entry.Daemon.GetLabel()
+	restEntry := &models.LegalLogEntry{
+		DaemonID:    &entry.Daemon.ID,
+		DaemonLabel: &daemonLabel,
+		Action:      &entry.Action,
+		IPAddress:   entry.Address,
+		Duration:    int64(entry.Duration.Seconds()),
+		HwAddress:   entry.HWAddress,
+		ClientID:    entry.ClientID,
+		Duid:        entry.DUID,
+		Text:        &entry.Text,
+	}
+	if !entry.Timestamp.IsZero() {
+		restEntry.Timestamp = strfmt.DateTime(entry.Timestamp)
+	}
+	return restEntry
+}
+
+// Searches the forensic log files written by the Kea legal log hook of the
+// specified daemon and its HA partners. It implements the
+// /api/leases/legal-log endpoint.
+func (r *RestAPI) SearchLegalLog(ctx context.Context, params dhcp.SearchLegalLogParams) middleware.Responder {
+	if code, msg := r.checkLeaseHistoryAccess(ctx); code != http.StatusOK {
+		return dhcp.NewSearchLegalLogDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+
+	daemon, code, msg := r.getLeaseDaemon(params.DaemonID)
+	if daemon == nil {
+		return dhcp.NewSearchLegalLogDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+
+	filter := &agentcomm.LegalLogFilter{
+		Limit: legalLogDefaultLimit,
+	}
+	if params.Limit != nil && *params.Limit > 0 {
+		filter.Limit = *params.Limit
+	}
+	if params.IPAddress != nil {
+		if storkutil.ParseIP(*params.IPAddress) == nil {
+			msg := "Invalid IP address or prefix specified in the forensic log query"
+			return dhcp.NewSearchLegalLogDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+		}
+		filter.Address = *params.IPAddress
+	}
+	if params.HwAddress != nil {
+		if !storkutil.IsHexIdentifier(*params.HwAddress) {
+			msg := "Invalid hardware address specified in the forensic log query"
+			return dhcp.NewSearchLegalLogDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+		}
+		filter.HWAddress = *params.HwAddress
+	}
+	if params.Duid != nil {
+		if !storkutil.IsHexIdentifier(*params.Duid) {
+			msg := "Invalid DUID specified in the forensic log query"
+			return dhcp.NewSearchLegalLogDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+		}
+		filter.DUID = *params.Duid
+	}
+	if params.From != nil {
+		filter.From = time.Time(*params.From)
+	}
+	if params.To != nil {
+		filter.To = time.Time(*params.To)
+	}
+
+	entries, erredDaemons, err := kea.SearchLegalLog(ctx, r.DB, r.Agents, daemon, filter)
+	if err != nil {
+		msg := "Problem searching forensic logs on Kea servers due to Stork database errors"
+		log.WithError(err).Error(msg)
+		return dhcp.NewSearchLegalLogDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+	}
+
+	result := &models.LegalLogEntries{
+		Items: make([]*models.LegalLogEntry, 0, len(entries)),
+		Total: int64(len(entries)),
+	}
+	for i := range entries {
+		result.Items = append(result.Items, convertLegalLogEntryToRestAPI(&entries[i]))
+	}
+	// Record daemons for which there was an error communicating with the Kea servers.
+	for _, daemon := range erredDaemons {
+		daemonLabel := daemon.GetLabel()
+		result.ErredDaemons = append(result.ErredDaemons, &models.LeasesSearchErredDaemon{
+			ID:    &daemon.ID,
+			Label: &daemonLabel,
+		})
+	}
+	return dhcp.NewSearchLegalLogOK().WithPayload(result)
+}
@@ -0,0 +1,171 @@
+package restservice
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	keadata "isc.org/stork/daemondata/kea"
+	"isc.org/stork/server/agentcomm"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Test searching the forensic log of the Kea daemon.
+func TestSearchLegalLog(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	timestamp := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	controller := gomock.NewController(t)
+	agents := NewMockConnectedAgents(controller)
+	agents.EXPECT().SearchKeaLegalLog(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, d agentcomm.ControlledDaemon, filter *agentcomm.LegalLogFilter) iter.Seq2[*keadata.LegalLogEntry, error] {
+			require.Equal(t, daemon.ID, d.GetID())
+			require.Equal(t, &agentcomm.LegalLogFilter{
+				Address:   "192.0.2.1",
+				HWAddress: "01:02:03:04:05:06",
+				From:      timestamp,
+				Limit:     legalLogDefaultLimit,
+			}, filter)
+			return func(yield func(*keadata.LegalLogEntry, error) bool) {
+				yield(&keadata.LegalLogEntry{
+					Timestamp: timestamp,
+					Action:    "assigned",
+					Address:   "192.0.2.1",
+					Duration:  time.Hour,
+					HWAddress: "01:02:03:04:05:06",
+					Text:      "foo",
+				}, nil)
+			}
+		})
+
+	rapi, err := NewRestAPI(dbSettings, db, agents, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := exampleSuperAdmin()
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	ipAddress := "192.0.2.1"
+	hwAddress := "01:02:03:04:05:06"
+	from := strfmt.DateTime(timestamp)
+	rsp := rapi.SearchLegalLog(ctx, dhcp.SearchLegalLogParams{
+		DaemonID:  daemon.ID,
+		IPAddress: &ipAddress,
+		HwAddress: &hwAddress,
+		From:      &from,
+	})
+	require.IsType(t, &dhcp.SearchLegalLogOK{}, rsp)
+	entries := rsp.(*dhcp.SearchLegalLogOK).Payload
+	require.EqualValues(t, 1, entries.Total)
+	require.Empty(t, entries.ErredDaemons)
+	require.Len(t, entries.Items, 1)
+	entry := entries.Items[0]
+	require.Equal(t, daemon.ID, *entry.DaemonID)
+	require.Equal(t, daemon.GetLabel(), *entry.DaemonLabel)
+	require.Equal(t, "assigned", *entry.Action)
+	require.Equal(t, "192.0.2.1", entry.IPAddress)
+	require.EqualValues(t, 3600, entry.Duration)
+	require.Equal(t, "01:02:03:04:05:06", entry.HwAddress)
+	require.Equal(t, "foo", *entry.Text)
+	require.Equal(t, from, entry.Timestamp)
+}
+
+// Test that the daemons for which the forensic log search failed are
+// returned in the response.
+func TestSearchLegalLogErredDaemon(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	agents := NewMockConnectedAgents(controller)
+	agents.EXPECT().SearchKeaLegalLog(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(func(yield func(*keadata.LegalLogEntry, error) bool) {
+			yield(nil, errors.New("legal log not configured"))
+		})
+
+	rapi, err := NewRestAPI(dbSettings, db, agents, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := exampleSuperAdmin()
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	rsp := rapi.SearchLegalLog(ctx, dhcp.SearchLegalLogParams{DaemonID: daemon.ID})
+	require.IsType(t, &dhcp.SearchLegalLogOK{}, rsp)
+	entries := rsp.(*dhcp.SearchLegalLogOK).Payload
+	require.Zero(t, entries.Total)
+	require.Empty(t, entries.Items)
+	require.Len(t, entries.ErredDaemons, 1)
+	require.Equal(t, daemon.ID, *entries.ErredDaemons[0].ID)
+}
+
+// Test that the invalid forensic log queries are rejected.
+func TestSearchLegalLogInvalidParams(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	rapi, err := NewRestAPI(dbSettings, db, &storktest.FakeEventCenter{})
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := exampleSuperAdmin()
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	t.Run("non-existing daemon", func(t *testing.T) {
+		rsp := rapi.SearchLegalLog(ctx, dhcp.SearchLegalLogParams{DaemonID: daemon.ID + 1})
+		require.IsType(t, &dhcp.SearchLegalLogDefault{}, rsp)
+		require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.SearchLegalLogDefault)))
+	})
+
+	invalid := "foo"
+	for name, params := range map[string]dhcp.SearchLegalLogParams{
+		"invalid IP address":       {DaemonID: daemon.ID, IPAddress: &invalid},
+		"invalid hardware address": {DaemonID: daemon.ID, HwAddress: &invalid},
+		"invalid DUID":             {DaemonID: daemon.ID, Duid: &invalid},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rsp := rapi.SearchLegalLog(ctx, params)
+			require.IsType(t, &dhcp.SearchLegalLogDefault{}, rsp)
+			require.Equal(t, http.StatusBadRequest, getStatusCode(*rsp.(*dhcp.SearchLegalLogDefault)))
+		})
+	}
+}
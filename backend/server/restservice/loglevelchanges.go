@@ -0,0 +1,171 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+)
+
+// Converts the temporary log level change to the format used in REST API.
+func (r *RestAPI) convertKeaLogLevelChangeToRestAPI(change *dbmodel.KeaLogLevelChange) *models.KeaLogLevelChange {
+	restChange := &models.KeaLogLevelChange{
+		ID:                 change.ID,
+		DaemonID:           change.DaemonID,
+		LoggerName:         change.LoggerName,
+		Severity:           change.Severity,
+		DebugLevel:         int64(change.DebugLevel),
+		OriginalDebugLevel: int64(change.OriginalDebugLevel),
+		CreatedAt:          strfmt.DateTime(change.CreatedAt),
+		ExpiresAt:          strfmt.DateTime(change.ExpiresAt),
+	}
+	if change.OriginalSeverity != nil {
+		restChange.OriginalSeverity = *change.OriginalSeverity
+	}
+	if change.UserID != nil {
+		restChange.UserID = *change.UserID
+	}
+	if change.User != nil {
+		restChange.UserLogin = change.User.Login
+	}
+	return restChange
+}
+
+// Fetches the Kea daemon by ID. It returns the HTTP status code and the
+// error message if the daemon doesn't exist or it is not a Kea daemon.
+func (r *RestAPI) getKeaDaemon(daemonID int64) (*dbmodel.Daemon, int, string) {
+	daemon, err := dbmodel.GetKeaDaemonByID(r.DB, daemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching daemon with ID %d from db", daemonID)
+		log.WithError(err).Error(msg)
+		return nil, http.StatusInternalServerError, msg
+	}
+	if daemon == nil {
+		return nil, http.StatusNotFound, fmt.Sprintf("Cannot find daemon with ID %d", daemonID)
+	}
+	if !daemon.Name.IsKea() {
+		return nil, http.StatusBadRequest, fmt.Sprintf("Daemon with ID %d is not a Kea daemon", daemonID)
+	}
+	return daemon, 0, ""
+}
+
+// Get the temporary log level changes of the Kea daemon which have not been
+// reverted yet.
+func (r *RestAPI) GetDaemonLogLevelChanges(ctx context.Context, params services.GetDaemonLogLevelChangesParams) middleware.Responder {
+	dbChanges, err := dbmodel.GetKeaLogLevelChanges(r.DB, &params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get log level changes for daemon with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewGetDaemonLogLevelChangesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	changes := &models.KeaLogLevelChanges{
+		Items: []*models.KeaLogLevelChange{},
+		Total: int64(len(dbChanges)),
+	}
+	for i := range dbChanges {
+		changes.Items = append(changes.Items, r.convertKeaLogLevelChangeToRestAPI(&dbChanges[i]))
+	}
+	rsp := services.NewGetDaemonLogLevelChangesOK().WithPayload(changes)
+	return rsp
+}
+
+// Temporarily change the severity of the Kea logger. The change is applied
+// with the config-set command and automatically reverted when it expires.
+func (r *RestAPI) SetDaemonLogLevel(ctx context.Context, params services.SetDaemonLogLevelParams) middleware.Responder {
+	request := params.Change
+	if request == nil || request.LoggerName == nil || *request.LoggerName == "" || request.Severity == nil || request.Duration == nil {
+		msg := "Logger name, severity and duration must be specified"
+		rsp := services.NewSetDaemonLogLevelDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	duration := time.Duration(*request.Duration) * time.Minute
+	if duration <= 0 || duration > kea.MaxLogLevelChangeDuration {
+		msg := fmt.Sprintf("Duration must be between 1 and %d minutes", int64(kea.MaxLogLevelChangeDuration.Minutes()))
+		rsp := services.NewSetDaemonLogLevelDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := kea.ValidateLogLevel(*request.Severity, int(request.DebugLevel)); err != nil {
+		msg := fmt.Sprintf("Invalid log level: %s", err)
+		rsp := services.NewSetDaemonLogLevelDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	daemon, code, msg := r.getKeaDaemon(params.ID)
+	if daemon == nil {
+		rsp := services.NewSetDaemonLogLevelDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	_, user := r.SessionManager.Logged(ctx)
+	userID := user.ID
+	change, err := kea.SetTemporaryLogLevel(ctx, r.DB, r.Agents, daemon, *request.LoggerName, *request.Severity, int(request.DebugLevel), duration, &userID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with changing the severity of logger %s in daemon with ID %d: %s", *request.LoggerName, params.ID, err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewSetDaemonLogLevelDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} temporarily changed logger %s in {daemon} to %s for %d minutes",
+		change.LoggerName, kea.GetLogLevelChangeDescription(change), *request.Duration), user, daemon)
+
+	rsp := services.NewSetDaemonLogLevelOK().WithPayload(r.convertKeaLogLevelChangeToRestAPI(change))
+	return rsp
+}
+
+// Revert the temporary log level change before it expires.
+func (r *RestAPI) RevertDaemonLogLevel(ctx context.Context, params services.RevertDaemonLogLevelParams) middleware.Responder {
+	change, err := dbmodel.GetKeaLogLevelChangeByID(r.DB, params.ChangeID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get log level change with ID %d from db", params.ChangeID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRevertDaemonLogLevelDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if change == nil || change.DaemonID != params.ID {
+		msg := fmt.Sprintf("Cannot find log level change with ID %d for daemon with ID %d", params.ChangeID, params.ID)
+		rsp := services.NewRevertDaemonLogLevelDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	if err = kea.RevertTemporaryLogLevel(ctx, r.DB, r.Agents, change); err != nil {
+		msg := fmt.Sprintf("Problem with reverting the severity of logger %s in daemon with ID %d: %s", change.LoggerName, params.ID, err)
+		log.WithError(err).Error(msg)
+		rsp := services.NewRevertDaemonLogLevelDefault(http.StatusConflict).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} reverted logger %s in {daemon} to %s",
+		change.LoggerName, kea.GetLogLevelRevertDescription(change)), user, change.Daemon)
+
+	rsp := services.NewRevertDaemonLogLevelOK()
+	return rsp
+}
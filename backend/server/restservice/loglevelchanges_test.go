@@ -0,0 +1,195 @@
+package restservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	keactrl "isc.org/stork/daemonctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/services"
+	storkutil "isc.org/stork/util"
+)
+
+// Mock function responding to the config-get command with the configuration
+// including the kea-dhcp4 logger.
+func mockLogLevelConfigGet(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	response := cmdResponses[0].(*keactrl.Response)
+	*response = keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseSuccess,
+		},
+		Arguments: json.RawMessage(`{
+			"Dhcp4": {
+				"loggers": [
+					{
+						"name": "kea-dhcp4",
+						"output-options": [ { "output": "stdout" } ],
+						"severity": "INFO"
+					}
+				]
+			}
+		}`),
+	}
+}
+
+// Mock function responding to the config-set command with success.
+func mockLogLevelConfigSet(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+	response := cmdResponses[0].(*keactrl.Response)
+	*response = keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseSuccess,
+		},
+	}
+}
+
+// Test that the Kea logger severity is temporarily changed, listed and
+// reverted over the REST API.
+func TestSetAndRevertDaemonLogLevel(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewKeaFakeAgents(mockLogLevelConfigGet, mockLogLevelConfigSet, mockLogLevelConfigGet, mockLogLevelConfigSet)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.SetDaemonLogLevel(ctx, services.SetDaemonLogLevelParams{
+		ID: daemon.ID,
+		Change: &models.KeaLogLevelChangeRequest{
+			LoggerName: storkutil.Ptr("kea-dhcp4.packets"),
+			Severity:   storkutil.Ptr("DEBUG"),
+			DebugLevel: 99,
+			Duration:   storkutil.Ptr(int64(30)),
+		},
+	})
+	require.IsType(t, &services.SetDaemonLogLevelOK{}, rsp)
+	change := rsp.(*services.SetDaemonLogLevelOK).Payload
+	require.Equal(t, "kea-dhcp4.packets", change.LoggerName)
+	require.Equal(t, "DEBUG", change.Severity)
+	require.EqualValues(t, 99, change.DebugLevel)
+	require.Empty(t, change.OriginalSeverity)
+	require.NotZero(t, change.UserID)
+
+	require.Len(t, fa.RecordedCommands, 2)
+	require.EqualValues(t, keactrl.ConfigGet, fa.RecordedCommands[0].GetCommand())
+	require.EqualValues(t, keactrl.ConfigSet, fa.RecordedCommands[1].GetCommand())
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "temporarily changed logger kea-dhcp4.packets")
+	require.Contains(t, fec.Events[0].Text, "DEBUG (debug level 99) for 30 minutes")
+
+	listRsp := rapi.GetDaemonLogLevelChanges(ctx, services.GetDaemonLogLevelChangesParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.GetDaemonLogLevelChangesOK{}, listRsp)
+	changes := listRsp.(*services.GetDaemonLogLevelChangesOK).Payload
+	require.EqualValues(t, 1, changes.Total)
+	require.Len(t, changes.Items, 1)
+	require.Equal(t, change.ID, changes.Items[0].ID)
+	require.Equal(t, "test", changes.Items[0].UserLogin)
+
+	// The change must belong to the specified daemon.
+	revertRsp := rapi.RevertDaemonLogLevel(ctx, services.RevertDaemonLogLevelParams{
+		ID:       daemon.ID + 1,
+		ChangeID: change.ID,
+	})
+	require.IsType(t, &services.RevertDaemonLogLevelDefault{}, revertRsp)
+	defaultRsp := revertRsp.(*services.RevertDaemonLogLevelDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+
+	revertRsp = rapi.RevertDaemonLogLevel(ctx, services.RevertDaemonLogLevelParams{
+		ID:       daemon.ID,
+		ChangeID: change.ID,
+	})
+	require.IsType(t, &services.RevertDaemonLogLevelOK{}, revertRsp)
+
+	require.Len(t, fa.RecordedCommands, 4)
+	require.EqualValues(t, keactrl.ConfigSet, fa.RecordedCommands[3].GetCommand())
+
+	require.Len(t, fec.Events, 2)
+	require.Contains(t, fec.Events[1].Text, "reverted logger kea-dhcp4.packets")
+	require.Contains(t, fec.Events[1].Text, "to the default severity")
+
+	listRsp = rapi.GetDaemonLogLevelChanges(ctx, services.GetDaemonLogLevelChangesParams{
+		ID: daemon.ID,
+	})
+	require.IsType(t, &services.GetDaemonLogLevelChangesOK{}, listRsp)
+	require.Zero(t, listRsp.(*services.GetDaemonLogLevelChangesOK).Payload.Total)
+}
+
+// Test that invalid log level change requests are rejected.
+func TestSetDaemonLogLevelInvalid(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewKeaFakeAgents()
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	for _, testCase := range []struct {
+		name     string
+		daemonID int64
+		request  *models.KeaLogLevelChangeRequest
+		code     int
+	}{
+		{
+			name:     "no logger name",
+			daemonID: daemon.ID,
+			request: &models.KeaLogLevelChangeRequest{
+				Severity: storkutil.Ptr("DEBUG"),
+				Duration: storkutil.Ptr(int64(10)),
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "too long",
+			daemonID: daemon.ID,
+			request: &models.KeaLogLevelChangeRequest{
+				LoggerName: storkutil.Ptr("kea-dhcp4"),
+				Severity:   storkutil.Ptr("DEBUG"),
+				Duration:   storkutil.Ptr(int64(1441)),
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "debug level for non-debug severity",
+			daemonID: daemon.ID,
+			request: &models.KeaLogLevelChangeRequest{
+				LoggerName: storkutil.Ptr("kea-dhcp4"),
+				Severity:   storkutil.Ptr("INFO"),
+				DebugLevel: 10,
+				Duration:   storkutil.Ptr(int64(10)),
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "non-existing daemon",
+			daemonID: daemon.ID + 1,
+			request: &models.KeaLogLevelChangeRequest{
+				LoggerName: storkutil.Ptr("kea-dhcp4"),
+				Severity:   storkutil.Ptr("WARN"),
+				Duration:   storkutil.Ptr(int64(10)),
+			},
+			code: http.StatusNotFound,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			rsp := rapi.SetDaemonLogLevel(ctx, services.SetDaemonLogLevelParams{
+				ID:     testCase.daemonID,
+				Change: testCase.request,
+			})
+			require.IsType(t, &services.SetDaemonLogLevelDefault{}, rsp)
+			defaultRsp := rsp.(*services.SetDaemonLogLevelDefault)
+			require.Equal(t, testCase.code, getStatusCode(*defaultRsp))
+		})
+	}
+	require.Empty(t, fa.RecordedCommands)
+	require.Empty(t, fec.Events)
+}
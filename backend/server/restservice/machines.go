@@ -1467,7 +1467,8 @@ func getKeaServicesStatus(db *dbops.PgDB, daemon *dbmodel.Daemon) *models.Servic
 		}
 		ha := s.HAService
 		keaStatus := models.KeaStatus{
-			Daemon: string(ha.HAType),
+			Daemon:    string(ha.HAType),
+			ServiceID: s.ID,
 		}
 		secondaryRole := "secondary"
 		if ha.HAMode == dbmodel.HAModeHotStandby {
@@ -1608,6 +1609,93 @@ func (r *RestAPI) GetDaemonServicesStatus(ctx context.Context, params services.G
 	return rsp
 }
 
+// Performs an action changing the state of the HA service, e.g. starts the
+// maintenance or synchronizes the lease database. The command is sent to
+// the specified daemon belonging to the service.
+func (r *RestAPI) PerformHAAction(ctx context.Context, params services.PerformHAActionParams) middleware.Responder {
+	if params.HaAction == nil || params.HaAction.Action == nil || params.HaAction.DaemonID == nil {
+		msg := "Missing HA action specification in the request"
+		log.Error(msg)
+		rsp := services.NewPerformHAActionDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	action := kea.HAAction(*params.HaAction.Action)
+	daemonID := *params.HaAction.DaemonID
+
+	service, err := dbmodel.GetDetailedService(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get service with ID %d from db", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewPerformHAActionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if service == nil {
+		msg := fmt.Sprintf("Cannot find service with ID %d", params.ID)
+		rsp := services.NewPerformHAActionDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if service.HAService == nil {
+		msg := fmt.Sprintf("Service with ID %d is not an HA service", params.ID)
+		rsp := services.NewPerformHAActionDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	daemon, err := dbmodel.GetKeaDaemonByID(r.DB, daemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", daemonID)
+		log.WithError(err).Error(msg)
+		rsp := services.NewPerformHAActionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if daemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", daemonID)
+		rsp := services.NewPerformHAActionDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	_, user := r.SessionManager.Logged(ctx)
+	text, err := kea.ExecuteHAAction(ctx, r.Agents, service, daemon, action, kea.HAActionParams{
+		Scopes:    params.HaAction.Scopes,
+		MaxPeriod: params.HaAction.MaxPeriod,
+	})
+	if err != nil {
+		var notAllowed *kea.HAActionNotAllowedError
+		if errors.As(err, &notAllowed) {
+			msg := fmt.Sprintf("Rejected the request in relationship %s: %s", service.HAService.Relationship, notAllowed.Error())
+			log.WithError(err).Warn(msg)
+			rsp := services.NewPerformHAActionDefault(http.StatusConflict).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		msg := fmt.Sprintf("Failed to perform HA %s action in relationship %s", action, service.HAService.Relationship)
+		log.WithError(err).Error(msg)
+		r.EventCenter.AddErrorEvent(fmt.Sprintf("{user} failed to perform HA %s action in relationship %s on {daemon}", action, service.HAService.Relationship), user, daemon, err)
+		rsp := services.NewPerformHAActionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} performed HA %s action in relationship %s on {daemon}", action, service.HAService.Relationship), user, daemon)
+
+	rsp := services.NewPerformHAActionOK().WithPayload(&models.HAActionResponse{
+		Text: text,
+	})
+	return rsp
+}
+
 // Get statistics about daemon.
 func (r *RestAPI) GetDaemonsStats(ctx context.Context, params services.GetDaemonsStatsParams) middleware.Responder {
 	// The second argument indicates that only basic information about the daemons
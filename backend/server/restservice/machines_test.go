@@ -2367,6 +2367,7 @@ func TestRestGetDaemonServicesStatus(t *testing.T) {
 
 	// Validate the status of the first relationship.
 	status := statusList[0].Status.KeaStatus
+	require.EqualValues(t, keaServices[0].ID, status.ServiceID)
 	require.NotNil(t, status.HaServers)
 
 	haStatus := status.HaServers
@@ -2581,6 +2582,174 @@ func TestRestGetDaemonServicesStatusPassiveBackup(t *testing.T) {
 	require.Zero(t, haStatus.PrimaryServer.AnalyzedPackets)
 }
 
+// Creates an HA service with two DHCPv4 servers configured in the
+// load-balancing relationship.
+func addTestHAActionService(t *testing.T, db *dbops.PgDB) (*dbmodel.Service, *dbmodel.Daemon, *dbmodel.Daemon) {
+	config := `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_ha.so",
+					"parameters": {
+						"high-availability": [
+							{
+								"this-server-name": "%s",
+								"mode": "load-balancing",
+								"peers": [
+									{ "name": "server1", "url": "http://192.0.2.1:8000", "role": "primary" },
+									{ "name": "server2", "url": "http://192.0.2.2:8000", "role": "secondary" }
+								]
+							}
+						]
+					}
+				}
+			]
+		}
+	}`
+	var daemons []*dbmodel.Daemon
+	for _, name := range []string{"server1", "server2"} {
+		server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+		require.NoError(t, err)
+		require.NoError(t, server.Configure(fmt.Sprintf(config, name)))
+		daemon, err := server.GetDaemon()
+		require.NoError(t, err)
+		daemons = append(daemons, daemon)
+	}
+	service := &dbmodel.Service{
+		HAService: &dbmodel.BaseHAService{
+			HAType:             daemonname.DHCPv4,
+			HAMode:             dbmodel.HAModeLoadBalancing,
+			Relationship:       "server1",
+			PrimaryID:          daemons[0].ID,
+			SecondaryID:        daemons[1].ID,
+			PrimaryLastState:   dbmodel.HAStateLoadBalancing,
+			SecondaryLastState: dbmodel.HAStateLoadBalancing,
+			PrimaryReachable:   true,
+			SecondaryReachable: true,
+		},
+	}
+	require.NoError(t, dbmodel.AddService(db, service))
+	for _, daemon := range daemons {
+		require.NoError(t, dbmodel.AddDaemonToService(db, service.ID, daemon))
+	}
+	return service, daemons[0], daemons[1]
+}
+
+// Test that the HA maintenance can be started over the REST API.
+func TestPerformHAAction(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	service, primary, _ := addTestHAActionService(t, db)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := services.PerformHAActionParams{
+		ID: service.ID,
+		HaAction: &models.HAActionRequest{
+			Action:   storkutil.Ptr(string(kea.HAActionMaintenanceStart)),
+			DaemonID: storkutil.Ptr(primary.ID),
+		},
+	}
+	rsp := rapi.PerformHAAction(ctx, params)
+	require.IsType(t, &services.PerformHAActionOK{}, rsp)
+
+	require.Len(t, fa.RecordedCommands, 1)
+	require.EqualValues(t, keactrl.HAMaintenanceStart, fa.RecordedCommands[0].GetCommand())
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "performed HA maintenance-start action in relationship server1")
+	require.Equal(t, dbmodel.EvInfo, fec.Events[0].Level)
+}
+
+// Test that the HA action is rejected over the REST API when it is not
+// allowed in the current state of the HA service.
+func TestPerformHAActionNotAllowed(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	service, primary, _ := addTestHAActionService(t, db)
+	service.HAService.SecondaryReachable = false
+	require.NoError(t, dbmodel.UpdateBaseHAService(db, service.HAService))
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	params := services.PerformHAActionParams{
+		ID: service.ID,
+		HaAction: &models.HAActionRequest{
+			Action:   storkutil.Ptr(string(kea.HAActionMaintenanceStart)),
+			DaemonID: storkutil.Ptr(primary.ID),
+		},
+	}
+	rsp := rapi.PerformHAAction(ctx, params)
+	require.IsType(t, &services.PerformHAActionDefault{}, rsp)
+	defaultRsp := rsp.(*services.PerformHAActionDefault)
+	require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+	require.Contains(t, *defaultRsp.Payload.Message, "the partner is already down")
+
+	require.Empty(t, fa.RecordedCommands)
+	require.Empty(t, fec.Events)
+}
+
+// Test that the HA action fails over the REST API for non-existing service
+// or daemon and that the error event is emitted when Kea returns an error.
+func TestPerformHAActionError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	service, _, secondary := addTestHAActionService(t, db)
+
+	fa := agentcommtest.NewFakeAgents(func(callNo int, daemon agentcomm.ControlledDaemon, cmdResponses []any) {
+		mockStatusError(cmdResponses)
+	}, nil)
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	t.Run("non-existing service", func(t *testing.T) {
+		rsp := rapi.PerformHAAction(ctx, services.PerformHAActionParams{
+			ID: service.ID + 1,
+			HaAction: &models.HAActionRequest{
+				Action:   storkutil.Ptr(string(kea.HAActionSync)),
+				DaemonID: storkutil.Ptr(secondary.ID),
+			},
+		})
+		require.IsType(t, &services.PerformHAActionDefault{}, rsp)
+		defaultRsp := rsp.(*services.PerformHAActionDefault)
+		require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("non-existing daemon", func(t *testing.T) {
+		rsp := rapi.PerformHAAction(ctx, services.PerformHAActionParams{
+			ID: service.ID,
+			HaAction: &models.HAActionRequest{
+				Action:   storkutil.Ptr(string(kea.HAActionSync)),
+				DaemonID: storkutil.Ptr(secondary.ID + 100),
+			},
+		})
+		require.IsType(t, &services.PerformHAActionDefault{}, rsp)
+		defaultRsp := rsp.(*services.PerformHAActionDefault)
+		require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("Kea error", func(t *testing.T) {
+		rsp := rapi.PerformHAAction(ctx, services.PerformHAActionParams{
+			ID: service.ID,
+			HaAction: &models.HAActionRequest{
+				Action:   storkutil.Ptr(string(kea.HAActionSync)),
+				DaemonID: storkutil.Ptr(secondary.ID),
+			},
+		})
+		require.IsType(t, &services.PerformHAActionDefault{}, rsp)
+		defaultRsp := rsp.(*services.PerformHAActionDefault)
+		require.Equal(t, http.StatusInternalServerError, getStatusCode(*defaultRsp))
+
+		require.Len(t, fa.RecordedCommands, 1)
+		require.Len(t, fec.Events, 1)
+		require.Equal(t, dbmodel.EvError, fec.Events[0].Level)
+	})
+}
+
 func TestRestGetDaemonsStats(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
 	defer teardown()
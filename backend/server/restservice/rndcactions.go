@@ -0,0 +1,89 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/daemons/bind9"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/dns"
+)
+
+// Performs an rndc action on the BIND 9 server, e.g. reloads or retransfers
+// the zone in the specified view, or flushes the resolver cache. The event
+// is recorded for each performed action.
+func (r *RestAPI) PerformRndcAction(ctx context.Context, params dns.PerformRndcActionParams) middleware.Responder {
+	if params.RndcAction == nil || params.RndcAction.Action == nil {
+		msg := "Missing rndc action specification in the request"
+		log.Error(msg)
+		rsp := dns.NewPerformRndcActionDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	action := bind9.RndcAction(*params.RndcAction.Action)
+	actionParams := bind9.RndcActionParams{
+		ZoneName: params.RndcAction.ZoneName,
+		Class:    params.RndcAction.Class,
+		ViewName: params.RndcAction.ViewName,
+		Name:     params.RndcAction.Name,
+	}
+
+	daemon, err := dbmodel.GetDNSDaemonByID(r.DB, params.DaemonID)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.DaemonID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewPerformRndcActionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if daemon == nil {
+		msg := fmt.Sprintf("Cannot find daemon with ID %d", params.DaemonID)
+		rsp := dns.NewPerformRndcActionDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if daemon.Name != daemonname.Bind9 {
+		msg := fmt.Sprintf("Daemon with ID %d is not a BIND 9 daemon", params.DaemonID)
+		rsp := dns.NewPerformRndcActionDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	_, user := r.SessionManager.Logged(ctx)
+	description := action.Describe(actionParams)
+	text, err := bind9.ExecuteRndcAction(ctx, r.Agents, daemon, action, actionParams)
+	if err != nil {
+		var paramsErr *bind9.RndcActionParamsError
+		if errors.As(err, &paramsErr) {
+			msg := fmt.Sprintf("Rejected the rndc request: %s", paramsErr.Error())
+			log.WithError(err).Warn(msg)
+			rsp := dns.NewPerformRndcActionDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		msg := fmt.Sprintf("Failed to perform rndc %s", description)
+		log.WithError(err).Error(msg)
+		r.EventCenter.AddErrorEvent(fmt.Sprintf("{user} failed to perform rndc %s on {daemon}", description), user, daemon, err)
+		rsp := dns.NewPerformRndcActionDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} performed rndc %s on {daemon}", description), user, daemon)
+
+	rsp := dns.NewPerformRndcActionOK().WithPayload(&models.RndcActionResponse{
+		Text: text,
+	})
+	return rsp
+}
@@ -0,0 +1,176 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"isc.org/stork/datamodel/daemonname"
+	"isc.org/stork/server/agentcomm"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbmodeltest "isc.org/stork/server/database/model/test"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dns "isc.org/stork/server/gen/restapi/operations/dns"
+	storktest "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds a BIND 9 daemon receiving the rndc commands to the database.
+func addTestRndcActionDaemon(t *testing.T, db *dbops.PgDB) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{
+		{
+			Type:    dbmodel.AccessPointControl,
+			Address: "127.0.0.1",
+			Port:    953,
+		},
+	})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+	return daemon
+}
+
+// Creates the REST API with the mocked agents and the logged user.
+func setupRndcActionRestAPI(t *testing.T, db *dbops.PgDB, dbSettings *dbops.DatabaseSettings, agents agentcomm.ConnectedAgents) (*RestAPI, *storktest.FakeEventCenter, context.Context) {
+	fec := &storktest.FakeEventCenter{}
+	rapi, err := NewRestAPI(dbSettings, db, agents, fec)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := exampleSuperAdmin()
+	testHelperMakeUser(t, db, user, "pass")
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	return rapi, fec, ctx
+}
+
+// Test that the zone is retransferred in the view over the REST API and
+// the event is recorded.
+func TestPerformRndcAction(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestRndcActionDaemon(t, db)
+
+	controller := gomock.NewController(t)
+	agents := NewMockConnectedAgents(controller)
+	agents.EXPECT().ForwardRndcCommand(gomock.Any(), gomock.Any(), "retransfer example.com IN trusted").
+		DoAndReturn(func(ctx context.Context, d agentcomm.ControlledDaemon, command string) (*agentcomm.RndcOutput, error) {
+			require.Equal(t, daemon.ID, d.GetID())
+			return &agentcomm.RndcOutput{Output: "zone transfer requested\n"}, nil
+		})
+
+	rapi, fec, ctx := setupRndcActionRestAPI(t, db, dbSettings, agents)
+
+	rsp := rapi.PerformRndcAction(ctx, dns.PerformRndcActionParams{
+		DaemonID: daemon.ID,
+		RndcAction: &models.RndcActionRequest{
+			Action:   storkutil.Ptr("retransfer"),
+			ZoneName: "example.com",
+			ViewName: "trusted",
+		},
+	})
+	require.IsType(t, &dns.PerformRndcActionOK{}, rsp)
+	require.Equal(t, "zone transfer requested", rsp.(*dns.PerformRndcActionOK).Payload.Text)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "performed rndc retransfer of zone example.com in view trusted")
+	require.Equal(t, dbmodel.EvInfo, fec.Events[0].Level)
+}
+
+// Test that the rndc action fails over the REST API for invalid parameters,
+// wrong daemons, and that the error event is emitted when rndc fails.
+func TestPerformRndcActionError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestRndcActionDaemon(t, db)
+	keaServer, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	keaDaemon, err := keaServer.GetDaemon()
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	agents := NewMockConnectedAgents(controller)
+	agents.EXPECT().ForwardRndcCommand(gomock.Any(), gomock.Any(), "sign example.com").
+		Return(nil, errors.New("not a dynamic zone"))
+
+	rapi, fec, ctx := setupRndcActionRestAPI(t, db, dbSettings, agents)
+
+	t.Run("missing action", func(t *testing.T) {
+		rsp := rapi.PerformRndcAction(ctx, dns.PerformRndcActionParams{
+			DaemonID: daemon.ID,
+		})
+		require.IsType(t, &dns.PerformRndcActionDefault{}, rsp)
+		defaultRsp := rsp.(*dns.PerformRndcActionDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("non-existing daemon", func(t *testing.T) {
+		rsp := rapi.PerformRndcAction(ctx, dns.PerformRndcActionParams{
+			DaemonID: daemon.ID + 100,
+			RndcAction: &models.RndcActionRequest{
+				Action: storkutil.Ptr("reconfig"),
+			},
+		})
+		require.IsType(t, &dns.PerformRndcActionDefault{}, rsp)
+		defaultRsp := rsp.(*dns.PerformRndcActionDefault)
+		require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("non-BIND 9 daemon", func(t *testing.T) {
+		rsp := rapi.PerformRndcAction(ctx, dns.PerformRndcActionParams{
+			DaemonID: keaDaemon.ID,
+			RndcAction: &models.RndcActionRequest{
+				Action: storkutil.Ptr("reconfig"),
+			},
+		})
+		require.IsType(t, &dns.PerformRndcActionDefault{}, rsp)
+		defaultRsp := rsp.(*dns.PerformRndcActionDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		rsp := rapi.PerformRndcAction(ctx, dns.PerformRndcActionParams{
+			DaemonID: daemon.ID,
+			RndcAction: &models.RndcActionRequest{
+				Action: storkutil.Ptr("retransfer"),
+			},
+		})
+		require.IsType(t, &dns.PerformRndcActionDefault{}, rsp)
+		defaultRsp := rsp.(*dns.PerformRndcActionDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Contains(t, *defaultRsp.Payload.Message, "zone name must be specified")
+		require.Empty(t, fec.Events)
+	})
+
+	t.Run("rndc error", func(t *testing.T) {
+		rsp := rapi.PerformRndcAction(ctx, dns.PerformRndcActionParams{
+			DaemonID: daemon.ID,
+			RndcAction: &models.RndcActionRequest{
+				Action:   storkutil.Ptr("sign"),
+				ZoneName: "example.com",
+			},
+		})
+		require.IsType(t, &dns.PerformRndcActionDefault{}, rsp)
+		defaultRsp := rsp.(*dns.PerformRndcActionDefault)
+		require.Equal(t, http.StatusInternalServerError, getStatusCode(*defaultRsp))
+		require.Equal(t, "Failed to perform rndc sign of zone example.com", *defaultRsp.Payload.Message)
+
+		require.Len(t, fec.Events, 1)
+		require.Equal(t, dbmodel.EvError, fec.Events[0].Level)
+	})
+}
@@ -0,0 +1,247 @@
+package restservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/config"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Checks if committing the config changes requires an approval of another
+// user.
+func (r *RestAPI) isConfigChangeApprovalEnabled() (bool, error) {
+	return dbmodel.GetSettingBool(r.DB, "enable_config_change_approval")
+}
+
+// Commits the config changes queued in the transaction context, schedules
+// them if the deadline is specified, or submits them for approval if the
+// approval of the config changes is enabled. The description is used in the
+// error messages to describe the committed information. It returns the
+// updated context, HTTP error code and the error message. The returned code
+// is 0 when there is no error.
+func (r *RestAPI) commitOrScheduleConfigChange(cctx context.Context, user *dbmodel.SystemUser, deadline *strfmt.DateTime, description string) (context.Context, int, string) {
+	approvalEnabled, err := r.isConfigChangeApprovalEnabled()
+	if err != nil {
+		msg := fmt.Sprintf("Problem with checking if committing %s requires approval", description)
+		log.WithError(err).Error(msg)
+		return cctx, http.StatusInternalServerError, msg
+	}
+	if deadline == nil && !approvalEnabled {
+		cctx, err := r.ConfigManager.Commit(cctx)
+		if err != nil {
+			msg := fmt.Sprintf("Problem with committing %s: %s", description, err)
+			log.WithError(err).Error(msg)
+			return cctx, http.StatusConflict, msg
+		}
+		return cctx, 0, ""
+	}
+	var deadlineAt *time.Time
+	if deadline != nil {
+		deadlineAt = storkutil.Ptr(time.Time(*deadline).UTC())
+		if !deadlineAt.After(storkutil.UTCNow()) {
+			msg := fmt.Sprintf("Problem with scheduling %s because the deadline is not in the future", description)
+			log.Error(msg)
+			return cctx, http.StatusBadRequest, msg
+		}
+	}
+	if approvalEnabled {
+		cctx, err = r.ConfigManager.RequestApproval(cctx, deadlineAt)
+		if err != nil {
+			msg := fmt.Sprintf("Problem with submitting %s for approval: %s", description, err)
+			log.WithError(err).Error(msg)
+			return cctx, http.StatusInternalServerError, msg
+		}
+		requestID, _ := config.GetValueAsInt64(cctx, config.ConfigChangeRequestIDKey)
+		r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} submitted config change request %d for approval", requestID), user)
+		return cctx, 0, ""
+	}
+	cctx, err = r.ConfigManager.Schedule(cctx, *deadlineAt)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with scheduling %s: %s", description, err)
+		log.WithError(err).Error(msg)
+		return cctx, http.StatusInternalServerError, msg
+	}
+	changeID, _ := config.GetValueAsInt64(cctx, config.ScheduledConfigChangeIDKey)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} scheduled config change %d to be committed at %s", changeID, deadlineAt.Format(time.RFC3339)), user)
+	return cctx, 0, ""
+}
+
+// Checks if the config change has been committed in the daemons rather
+// than scheduled or submitted for approval.
+func isConfigChangeCommitted(cctx context.Context) bool {
+	if _, ok := config.GetValueAsInt64(cctx, config.ScheduledConfigChangeIDKey); ok {
+		return false
+	}
+	_, ok := config.GetValueAsInt64(cctx, config.ConfigChangeRequestIDKey)
+	return !ok
+}
+
+// Converts the scheduled config change to the format used in REST API.
+// The recipes are only included when the includeRecipes flag is set.
+func (r *RestAPI) convertScheduledConfigChangeToRestAPI(change *dbmodel.ScheduledConfigChange, includeRecipes bool) *models.ScheduledConfigChange {
+	restChange := &models.ScheduledConfigChange{
+		ID:         change.ID,
+		CreatedAt:  strfmt.DateTime(change.CreatedAt),
+		DeadlineAt: strfmt.DateTime(change.DeadlineAt),
+		UserID:     change.UserID,
+		Executed:   change.Executed,
+		Error:      change.Error,
+	}
+	if change.User != nil {
+		restChange.UserLogin = change.User.Login
+	}
+	restChange.Updates = convertConfigUpdatesToRestAPI(change.Updates, includeRecipes)
+	return restChange
+}
+
+// Converts the config updates to the format used in REST API. The recipes
+// are only included when the includeRecipes flag is set.
+func convertConfigUpdatesToRestAPI(updates []*dbmodel.ConfigUpdate, includeRecipes bool) []*models.ScheduledConfigUpdate {
+	restUpdates := []*models.ScheduledConfigUpdate{}
+	for _, update := range updates {
+		restUpdate := &models.ScheduledConfigUpdate{
+			Operation: string(update.Operation),
+			DaemonIds: update.DaemonIDs,
+		}
+		if includeRecipes && update.Recipe != nil {
+			var recipe any
+			if err := json.Unmarshal(*update.Recipe, &recipe); err == nil {
+				restUpdate.Recipe = recipe
+			}
+		}
+		restUpdates = append(restUpdates, restUpdate)
+	}
+	return restUpdates
+}
+
+// Implements the GET call to list the scheduled config changes
+// (scheduled-config-changes).
+func (r *RestAPI) GetScheduledConfigChanges(ctx context.Context, params dhcp.GetScheduledConfigChangesParams) middleware.Responder {
+	var start int64
+	if params.Start != nil {
+		start = *params.Start
+	}
+
+	var limit int64 = 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	dbChanges, total, err := dbmodel.GetScheduledConfigChangesByPage(r.DB, start, limit, params.Executed)
+	if err != nil {
+		msg := "Problem with fetching scheduled config changes from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetScheduledConfigChangesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
+	changes := &models.ScheduledConfigChanges{
+		Items: []*models.ScheduledConfigChange{},
+		Total: total,
+	}
+	for i := range dbChanges {
+		changes.Items = append(changes.Items, r.convertScheduledConfigChangeToRestAPI(&dbChanges[i], false))
+	}
+	rsp := dhcp.NewGetScheduledConfigChangesOK().WithPayload(changes)
+	return rsp
+}
+
+// Implements the GET call to get the scheduled config change by ID
+// (scheduled-config-changes/{id}).
+func (r *RestAPI) GetScheduledConfigChange(ctx context.Context, params dhcp.GetScheduledConfigChangeParams) middleware.Responder {
+	dbChange, err := dbmodel.GetScheduledConfigChangeByID(r.DB, params.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem with fetching scheduled config change with ID %d from the database", params.ID)
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetScheduledConfigChangeDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbChange == nil {
+		msg := fmt.Sprintf("Cannot find scheduled config change with ID %d", params.ID)
+		rsp := dhcp.NewGetScheduledConfigChangeDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	rsp := dhcp.NewGetScheduledConfigChangeOK().WithPayload(r.convertScheduledConfigChangeToRestAPI(dbChange, true))
+	return rsp
+}
+
+// Implements the PUT call to set a new deadline for the pending config
+// change (scheduled-config-changes/{id}).
+func (r *RestAPI) RescheduleConfigChange(ctx context.Context, params dhcp.RescheduleConfigChangeParams) middleware.Responder {
+	if params.Schedule == nil || params.Schedule.Deadline == nil {
+		msg := "Deadline of the scheduled config change not specified"
+		log.Error(msg)
+		rsp := dhcp.NewRescheduleConfigChangeDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	deadlineAt := time.Time(*params.Schedule.Deadline).UTC()
+	if !deadlineAt.After(storkutil.UTCNow()) {
+		msg := "Deadline of the scheduled config change must be in the future"
+		log.Error(msg)
+		rsp := dhcp.NewRescheduleConfigChangeDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if err := dbmodel.RescheduleConfigChange(r.DB, params.ID, deadlineAt); err != nil {
+		code := http.StatusInternalServerError
+		msg := fmt.Sprintf("Problem with rescheduling config change with ID %d", params.ID)
+		if errors.Is(err, dbmodel.ErrNotExists) {
+			code = http.StatusNotFound
+			msg = fmt.Sprintf("Cannot find pending config change with ID %d", params.ID)
+		}
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewRescheduleConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} rescheduled config change %d to be committed at %s", params.ID, deadlineAt.Format(time.RFC3339)), user)
+
+	rsp := dhcp.NewRescheduleConfigChangeOK()
+	return rsp
+}
+
+// Implements the DELETE call to cancel the pending config change
+// (scheduled-config-changes/{id}).
+func (r *RestAPI) CancelScheduledConfigChange(ctx context.Context, params dhcp.CancelScheduledConfigChangeParams) middleware.Responder {
+	if err := dbmodel.CancelScheduledConfigChange(r.DB, params.ID); err != nil {
+		code := http.StatusInternalServerError
+		msg := fmt.Sprintf("Problem with canceling config change with ID %d", params.ID)
+		if errors.Is(err, dbmodel.ErrNotExists) {
+			code = http.StatusNotFound
+			msg = fmt.Sprintf("Cannot find pending config change with ID %d", params.ID)
+		}
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewCancelScheduledConfigChangeDefault(code).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	r.EventCenter.AddInfoEvent(fmt.Sprintf("{user} canceled scheduled config change %d", params.ID), user)
+
+	rsp := dhcp.NewCancelScheduledConfigChangeOK()
+	return rsp
+}
@@ -0,0 +1,187 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storktestdbmodel "isc.org/stork/server/test/dbmodel"
+	storkutil "isc.org/stork/util"
+)
+
+// Creates the REST API with the user logged in. The user is stored in the
+// database because the scheduled config changes must refer to existing users.
+func setupScheduledConfigChangesRestAPI(t *testing.T, db *dbops.PgDB, dbSettings *dbops.DatabaseSettings, fa *agentcommtest.FakeAgents) (*RestAPI, *storktestdbmodel.FakeEventCenter, context.Context) {
+	rapi, fec, ctx := setupLeaseCmdsRestAPI(t, db, dbSettings, fa)
+
+	user := &dbmodel.SystemUser{
+		Login:    "test",
+		Lastname: "test",
+		Name:     "test",
+	}
+	_, err := dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	return rapi, fec, ctx
+}
+
+// Test that the transaction submitted with a deadline is scheduled rather
+// than committed, and that the scheduled change can be listed, fetched,
+// rescheduled and canceled.
+func TestCreateClientClassSubmitScheduled(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, fec, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginOK{}, rsp)
+	beginRsp := rsp.(*dhcp.CreateClientClassBeginOK)
+
+	deadline := strfmt.DateTime(storkutil.UTCNow().Add(time.Hour))
+	rsp = rapi.CreateClientClassSubmit(ctx, dhcp.CreateClientClassSubmitParams{
+		ID:       beginRsp.Payload.ID,
+		Deadline: &deadline,
+		ClientClass: &models.ClientClass{
+			DaemonID: daemon.ID,
+			Name:     storkutil.Ptr("baz"),
+		},
+	})
+	require.IsType(t, &dhcp.CreateClientClassSubmitOK{}, rsp)
+
+	// No commands should be sent until the deadline.
+	require.Empty(t, fa.RecordedCommands)
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "scheduled config change")
+
+	// List the scheduled changes.
+	rsp = rapi.GetScheduledConfigChanges(ctx, dhcp.GetScheduledConfigChangesParams{
+		Executed: storkutil.Ptr(false),
+	})
+	require.IsType(t, &dhcp.GetScheduledConfigChangesOK{}, rsp)
+	changes := rsp.(*dhcp.GetScheduledConfigChangesOK).Payload
+	require.EqualValues(t, 1, changes.Total)
+	require.Len(t, changes.Items, 1)
+	require.Equal(t, "test", changes.Items[0].UserLogin)
+	require.False(t, changes.Items[0].Executed)
+	require.Len(t, changes.Items[0].Updates, 1)
+	require.Equal(t, string(dbmodel.ConfigOperationKeaClientClassAdd), changes.Items[0].Updates[0].Operation)
+	require.Equal(t, []int64{daemon.ID}, changes.Items[0].Updates[0].DaemonIds)
+	require.Nil(t, changes.Items[0].Updates[0].Recipe)
+
+	// Get the scheduled change with the recipe.
+	changeID := changes.Items[0].ID
+	rsp = rapi.GetScheduledConfigChange(ctx, dhcp.GetScheduledConfigChangeParams{
+		ID: changeID,
+	})
+	require.IsType(t, &dhcp.GetScheduledConfigChangeOK{}, rsp)
+	change := rsp.(*dhcp.GetScheduledConfigChangeOK).Payload
+	require.Len(t, change.Updates, 1)
+	require.NotNil(t, change.Updates[0].Recipe)
+
+	// Reschedule the change.
+	newDeadline := strfmt.DateTime(storkutil.UTCNow().Add(2 * time.Hour))
+	rsp = rapi.RescheduleConfigChange(ctx, dhcp.RescheduleConfigChangeParams{
+		ID: changeID,
+		Schedule: &models.ConfigChangeSchedule{
+			Deadline: &newDeadline,
+		},
+	})
+	require.IsType(t, &dhcp.RescheduleConfigChangeOK{}, rsp)
+	require.Len(t, fec.Events, 2)
+	require.Contains(t, fec.Events[1].Text, "rescheduled config change")
+
+	dbChange, err := dbmodel.GetScheduledConfigChangeByID(db, changeID)
+	require.NoError(t, err)
+	require.NotNil(t, dbChange)
+	require.WithinDuration(t, time.Time(newDeadline), dbChange.DeadlineAt, time.Second)
+
+	// Cancel the change.
+	rsp = rapi.CancelScheduledConfigChange(ctx, dhcp.CancelScheduledConfigChangeParams{
+		ID: changeID,
+	})
+	require.IsType(t, &dhcp.CancelScheduledConfigChangeOK{}, rsp)
+	require.Len(t, fec.Events, 3)
+	require.Contains(t, fec.Events[2].Text, "canceled scheduled config change")
+
+	rsp = rapi.GetScheduledConfigChange(ctx, dhcp.GetScheduledConfigChangeParams{
+		ID: changeID,
+	})
+	require.IsType(t, &dhcp.GetScheduledConfigChangeDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.GetScheduledConfigChangeDefault)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+}
+
+// Test that the transaction is not scheduled when the deadline is in the past.
+func TestCreateClientClassSubmitScheduledPastDeadline(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addTestClassCmdsDaemon(t, db, "libdhcp_class_cmds.so")
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	rsp := rapi.CreateClientClassBegin(ctx, dhcp.CreateClientClassBeginParams{})
+	require.IsType(t, &dhcp.CreateClientClassBeginOK{}, rsp)
+	beginRsp := rsp.(*dhcp.CreateClientClassBeginOK)
+
+	deadline := strfmt.DateTime(storkutil.UTCNow().Add(-time.Hour))
+	rsp = rapi.CreateClientClassSubmit(ctx, dhcp.CreateClientClassSubmitParams{
+		ID:       beginRsp.Payload.ID,
+		Deadline: &deadline,
+		ClientClass: &models.ClientClass{
+			DaemonID: daemon.ID,
+			Name:     storkutil.Ptr("baz"),
+		},
+	})
+	require.IsType(t, &dhcp.CreateClientClassSubmitDefault{}, rsp)
+	defaultRsp := rsp.(*dhcp.CreateClientClassSubmitDefault)
+	require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+
+	changes, total, err := dbmodel.GetScheduledConfigChangesByPage(db, 0, 10, nil)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, changes)
+}
+
+// Test that rescheduling and canceling non-existing changes returns
+// an error.
+func TestRescheduleCancelNonExistingConfigChange(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	rapi, _, ctx := setupScheduledConfigChangesRestAPI(t, db, dbSettings, fa)
+
+	deadline := strfmt.DateTime(storkutil.UTCNow().Add(time.Hour))
+	rsp := rapi.RescheduleConfigChange(ctx, dhcp.RescheduleConfigChangeParams{
+		ID: 123,
+		Schedule: &models.ConfigChangeSchedule{
+			Deadline: &deadline,
+		},
+	})
+	require.IsType(t, &dhcp.RescheduleConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.RescheduleConfigChangeDefault)))
+
+	rsp = rapi.CancelScheduledConfigChange(ctx, dhcp.CancelScheduledConfigChangeParams{
+		ID: 123,
+	})
+	require.IsType(t, &dhcp.CancelScheduledConfigChangeDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dhcp.CancelScheduledConfigChangeDefault)))
+}
@@ -31,11 +31,18 @@ func (r *RestAPI) GetSettings(ctx context.Context, params settings.GetSettingsPa
 		GrafanaDhcp6DashboardID:      dbSettingsMap["grafana_dhcp6_dashboard_id"].(string),
 		KeaHostsPullerInterval:       dbSettingsMap["kea_hosts_puller_interval"].(int64),
 		KeaStatsPullerInterval:       dbSettingsMap["kea_stats_puller_interval"].(int64),
+		KeaD2StatsPullerInterval:     dbSettingsMap["kea_d2_stats_puller_interval"].(int64),
 		KeaStatusPullerInterval:      dbSettingsMap["kea_status_puller_interval"].(int64),
 		KeaLeasesPullerInterval:      dbSettingsMap["kea_leases_puller_interval"].(int64),
+		ZoneSyncPullerInterval:       dbSettingsMap["zone_sync_puller_interval"].(int64),
 		StatePullerInterval:          dbSettingsMap["state_puller_interval"].(int64),
 		EnableMachineRegistration:    dbSettingsMap["enable_machine_registration"].(bool),
 		EnableOnlineSoftwareVersions: dbSettingsMap["enable_online_software_versions"].(bool),
+		EnableConfigChangeApproval:   dbSettingsMap["enable_config_change_approval"].(bool),
+		UtilizationForecastHorizon:   dbSettingsMap["utilization_forecast_horizon"].(int64),
+		LeaseHistoryRetention:        dbSettingsMap["lease_history_retention"].(int64),
+		ZoneSerialLagThreshold:       dbSettingsMap["zone_serial_lag_threshold"].(int64),
+		EnableZoneSyncRefresh:        dbSettingsMap["enable_zone_sync_refresh"].(bool),
 	}
 	rsp := settings.NewGetSettingsOK().WithPayload(s)
 
@@ -54,12 +61,36 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		return rsp
 	}
 
+	// Disabling the config change approval would allow any admin to bypass
+	// the approval workflow. Only the super-admins and the approvers can
+	// change this setting.
+	approvalEnabled, err := dbmodel.GetSettingBool(r.DB, "enable_config_change_approval")
+	if err != nil {
+		msg := "Cannot get enable_config_change_approval setting"
+		log.WithError(err).Error(msg)
+		rsp := settings.NewUpdateSettingsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	approvalChanged := approvalEnabled != s.EnableConfigChangeApproval
+	if approvalChanged && (user == nil || (!user.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) &&
+		!user.InGroup(&dbmodel.SystemGroup{ID: dbmodel.ApproverGroupID}))) {
+		msg := "Approval of the config changes can only be enabled or disabled by the super-admins and the members of the approver group"
+		log.Error(msg)
+		rsp := settings.NewUpdateSettingsDefault(http.StatusForbidden).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
 	msg := "Problem updating settings"
 	errRsp := settings.NewGetSettingsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
 		Message: &msg,
 	})
 
-	err := dbmodel.SetSettingInt(r.DB, "bind9_stats_puller_interval", s.Bind9StatsPullerInterval)
+	err = dbmodel.SetSettingInt(r.DB, "bind9_stats_puller_interval", s.Bind9StatsPullerInterval)
 	if err != nil {
 		log.WithError(err).Error("Cannot update bind9_stats_puller_interval")
 		return errRsp
@@ -89,6 +120,11 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		log.WithError(err).Error("Cannot update kea_stats_puller_interval")
 		return errRsp
 	}
+	err = dbmodel.SetSettingInt(r.DB, "kea_d2_stats_puller_interval", s.KeaD2StatsPullerInterval)
+	if err != nil {
+		log.WithError(err).Error("Cannot update kea_d2_stats_puller_interval")
+		return errRsp
+	}
 	err = dbmodel.SetSettingInt(r.DB, "kea_status_puller_interval", s.KeaStatusPullerInterval)
 	if err != nil {
 		log.WithError(err).Error("Cannot update kea_status_puller_interval")
@@ -99,6 +135,11 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		log.WithError(err).Error("Cannot update kea_leases_puller_interval")
 		return errRsp
 	}
+	err = dbmodel.SetSettingInt(r.DB, "zone_sync_puller_interval", s.ZoneSyncPullerInterval)
+	if err != nil {
+		log.WithError(err).Error("Cannot update zone_sync_puller_interval")
+		return errRsp
+	}
 	err = dbmodel.SetSettingInt(r.DB, "state_puller_interval", s.StatePullerInterval)
 	if err != nil {
 		log.WithError(err).Error("Cannot update state_puller_interval")
@@ -114,8 +155,41 @@ func (r *RestAPI) UpdateSettings(ctx context.Context, params settings.UpdateSett
 		log.WithError(err).Error("Cannot update enable_online_software_versions")
 		return errRsp
 	}
+	err = dbmodel.SetSettingBool(r.DB, "enable_config_change_approval", s.EnableConfigChangeApproval)
+	if err != nil {
+		log.WithError(err).Error("Cannot update enable_config_change_approval")
+		return errRsp
+	}
+	err = dbmodel.SetSettingInt(r.DB, "utilization_forecast_horizon", s.UtilizationForecastHorizon)
+	if err != nil {
+		log.WithError(err).Error("Cannot update utilization_forecast_horizon")
+		return errRsp
+	}
+	err = dbmodel.SetSettingInt(r.DB, "lease_history_retention", s.LeaseHistoryRetention)
+	if err != nil {
+		log.WithError(err).Error("Cannot update lease_history_retention")
+		return errRsp
+	}
+	err = dbmodel.SetSettingInt(r.DB, "zone_serial_lag_threshold", s.ZoneSerialLagThreshold)
+	if err != nil {
+		log.WithError(err).Error("Cannot update zone_serial_lag_threshold")
+		return errRsp
+	}
+	err = dbmodel.SetSettingBool(r.DB, "enable_zone_sync_refresh", s.EnableZoneSyncRefresh)
+	if err != nil {
+		log.WithError(err).Error("Cannot update enable_zone_sync_refresh")
+		return errRsp
+	}
 	r.EndpointControl.SetEnabled(EndpointOpCreateNewMachine, s.EnableMachineRegistration)
 
+	if approvalChanged {
+		if s.EnableConfigChangeApproval {
+			r.EventCenter.AddInfoEvent("{user} enabled the approval of the config changes", user)
+		} else {
+			r.EventCenter.AddWarningEvent("{user} disabled the approval of the config changes", user)
+		}
+	}
+
 	rsp := settings.NewUpdateSettingsOK()
 	return rsp
 }
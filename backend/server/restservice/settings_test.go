@@ -2,6 +2,7 @@ package restservice
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,7 +27,11 @@ func TestSettings(t *testing.T) {
 	ec := NewEndpointControl()
 	rapi, err := NewRestAPI(&rSettings, dbSettings, db, fa, fec, fd, ec)
 	require.NoError(t, err)
-	ctx := context.Background()
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	// The super-admin can enable the config change approval.
+	loginDefaultAdmin(t, db, rapi, ctx)
 
 	// Initialize global settings.
 	err = dbmodel.InitializeSettings(db, 0)
@@ -41,6 +46,12 @@ func TestSettings(t *testing.T) {
 	require.Empty(t, okRsp.Payload.GrafanaURL)
 	require.Equal(t, "hRf18FvWz", okRsp.Payload.GrafanaDhcp4DashboardID)
 	require.Equal(t, "AQPHKJUGz", okRsp.Payload.GrafanaDhcp6DashboardID)
+	require.False(t, okRsp.Payload.EnableConfigChangeApproval)
+	require.EqualValues(t, 30, okRsp.Payload.UtilizationForecastHorizon)
+	require.EqualValues(t, 90, okRsp.Payload.LeaseHistoryRetention)
+	require.EqualValues(t, 600, okRsp.Payload.ZoneSyncPullerInterval)
+	require.EqualValues(t, 3600, okRsp.Payload.ZoneSerialLagThreshold)
+	require.False(t, okRsp.Payload.EnableZoneSyncRefresh)
 
 	// Update settings.
 	paramsUS := settings.UpdateSettingsParams{
@@ -51,11 +62,18 @@ func TestSettings(t *testing.T) {
 			KeaStatsPullerInterval:       4,
 			KeaStatusPullerInterval:      5,
 			KeaLeasesPullerInterval:      6,
+			KeaD2StatsPullerInterval:     7,
+			ZoneSyncPullerInterval:       8,
 			GrafanaURL:                   "http://foo:3000",
 			GrafanaDhcp4DashboardID:      "dhcp4",
 			GrafanaDhcp6DashboardID:      "dhcp6",
 			EnableMachineRegistration:    false,
 			EnableOnlineSoftwareVersions: false,
+			EnableConfigChangeApproval:   true,
+			UtilizationForecastHorizon:   7,
+			LeaseHistoryRetention:        30,
+			ZoneSerialLagThreshold:       120,
+			EnableZoneSyncRefresh:        true,
 		},
 	}
 	rsp = rapi.UpdateSettings(ctx, paramsUS)
@@ -73,6 +91,8 @@ func TestSettings(t *testing.T) {
 	require.EqualValues(t, 4, okRsp.Payload.KeaStatsPullerInterval)
 	require.EqualValues(t, 5, okRsp.Payload.KeaStatusPullerInterval)
 	require.EqualValues(t, 6, okRsp.Payload.KeaLeasesPullerInterval)
+	require.EqualValues(t, 7, okRsp.Payload.KeaD2StatsPullerInterval)
+	require.EqualValues(t, 8, okRsp.Payload.ZoneSyncPullerInterval)
 
 	require.EqualValues(t, "http://foo:3000", okRsp.Payload.GrafanaURL)
 	require.EqualValues(t, "dhcp4", okRsp.Payload.GrafanaDhcp4DashboardID)
@@ -80,4 +100,81 @@ func TestSettings(t *testing.T) {
 
 	require.False(t, okRsp.Payload.EnableMachineRegistration)
 	require.False(t, okRsp.Payload.EnableOnlineSoftwareVersions)
+	require.True(t, okRsp.Payload.EnableConfigChangeApproval)
+	require.EqualValues(t, 7, okRsp.Payload.UtilizationForecastHorizon)
+	require.EqualValues(t, 30, okRsp.Payload.LeaseHistoryRetention)
+	require.EqualValues(t, 120, okRsp.Payload.ZoneSerialLagThreshold)
+	require.True(t, okRsp.Payload.EnableZoneSyncRefresh)
+
+	// Enabling the approval is recorded.
+	require.Len(t, fec.Events, 1)
+	require.Equal(t, dbmodel.EvInfo, fec.Events[0].Level)
+	require.Contains(t, fec.Events[0].Text, "enabled the approval of the config changes")
+}
+
+// Test that only the super-admins and the approvers can enable or disable
+// the config change approval.
+func TestUpdateSettingsConfigChangeApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	fec := &storktest.FakeEventCenter{}
+	rapi, err := NewRestAPI(&RestAPISettings{}, dbSettings, db, agentcommtest.NewFakeAgents(nil, nil), fec, &storktest.FakeDispatcher{}, NewEndpointControl())
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	err = dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+	err = dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	getSettings := func() *models.Settings {
+		rsp := rapi.GetSettings(ctx, settings.GetSettingsParams{})
+		require.IsType(t, &settings.GetSettingsOK{}, rsp)
+		return rsp.(*settings.GetSettingsOK).Payload
+	}
+
+	// Log in the user belonging to the admin group only.
+	admin := &dbmodel.SystemUser{
+		Login:    "admin2",
+		Lastname: "admin2",
+		Name:     "admin2",
+		Groups: []*dbmodel.SystemGroup{
+			{ID: dbmodel.AdminGroupID},
+		},
+	}
+	_, err = dbmodel.CreateUser(db, admin)
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, admin)
+	require.NoError(t, err)
+
+	// The admin can update other settings.
+	s := getSettings()
+	s.GrafanaURL = "http://foo:3000"
+	rsp := rapi.UpdateSettings(ctx, settings.UpdateSettingsParams{Settings: s})
+	require.IsType(t, &settings.UpdateSettingsOK{}, rsp)
+
+	// The admin cannot disable the approval.
+	s = getSettings()
+	require.True(t, s.EnableConfigChangeApproval)
+	s.EnableConfigChangeApproval = false
+	s.GrafanaURL = "http://bar:3000"
+	rsp = rapi.UpdateSettings(ctx, settings.UpdateSettingsParams{Settings: s})
+	require.IsType(t, &settings.UpdateSettingsDefault{}, rsp)
+	require.Equal(t, http.StatusForbidden, getStatusCode(*rsp.(*settings.UpdateSettingsDefault)))
+	s = getSettings()
+	require.True(t, s.EnableConfigChangeApproval)
+	require.Equal(t, "http://foo:3000", s.GrafanaURL)
+	require.Empty(t, fec.Events)
+
+	// The approver can disable the approval.
+	loginApprover(t, db, rapi, ctx)
+	s.EnableConfigChangeApproval = false
+	rsp = rapi.UpdateSettings(ctx, settings.UpdateSettingsParams{Settings: s})
+	require.IsType(t, &settings.UpdateSettingsOK{}, rsp)
+	require.False(t, getSettings().EnableConfigChangeApproval)
+	require.Len(t, fec.Events, 1)
+	require.Equal(t, dbmodel.EvWarning, fec.Events[0].Level)
+	require.Contains(t, fec.Events[0].Text, "disabled the approval of the config changes")
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/daemoncfg/kea"
@@ -381,7 +382,7 @@ func (r *RestAPI) GetSharedNetwork(ctx context.Context, params dhcp.GetSharedNet
 // when there is no error. It also returns an ID of the created or modified shared
 // network. Finally, it returns an error string to be included in the HTTP response
 // or an empty string if there is no error.
-func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, transactionID int64, restSharedNetwork *models.SharedNetwork, applyFunc func(context.Context, *dbmodel.SharedNetwork) (context.Context, error)) (int, int64, string) {
+func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, transactionID int64, deadline *strfmt.DateTime, restSharedNetwork *models.SharedNetwork, applyFunc func(context.Context, *dbmodel.SharedNetwork) (context.Context, error)) (int, int64, string) {
 	// Make sure that the shared network information is present.
 	if restSharedNetwork == nil {
 		msg := "Shared network information not specified"
@@ -417,12 +418,10 @@ func (r *RestAPI) commonCreateOrUpdateSharedNetworkSubmit(ctx context.Context, t
 		log.WithError(err).Error(msg)
 		return http.StatusInternalServerError, 0, msg
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with committing shared network information: %s", err)
-		log.WithError(err).Error(msg)
-		return http.StatusConflict, 0, msg
+	// Send the commands to Kea servers or schedule them.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, deadline, "shared network information")
+	if code != 0 {
+		return code, 0, msg
 	}
 	sharedNetworkID := restSharedNetwork.ID
 	if sharedNetworkID == 0 {
@@ -516,7 +515,7 @@ func (r *RestAPI) CreateSharedNetworkBegin(ctx context.Context, params dhcp.Crea
 // Implements the POST call and commits a new shared network
 // (shared-networks/new/transaction/{id}/submit).
 func (r *RestAPI) CreateSharedNetworkSubmit(ctx context.Context, params dhcp.CreateSharedNetworkSubmitParams) middleware.Responder {
-	code, sharedNetworkID, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkAdd)
+	code, sharedNetworkID, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.Deadline, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkAdd)
 	if code != 0 {
 		// Error case.
 		rsp := dhcp.NewCreateSharedNetworkSubmitDefault(code).WithPayload(&models.APIError{
@@ -641,7 +640,7 @@ func (r *RestAPI) UpdateSharedNetworkBegin(ctx context.Context, params dhcp.Upda
 // Implements the POST call and commits an updated shared network
 // (shared-networks/{sharedNetworkId}/transaction/{id}/submit).
 func (r *RestAPI) UpdateSharedNetworkSubmit(ctx context.Context, params dhcp.UpdateSharedNetworkSubmitParams) middleware.Responder {
-	if code, _, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkUpdate); code != 0 {
+	if code, _, msg := r.commonCreateOrUpdateSharedNetworkSubmit(ctx, params.ID, params.Deadline, params.SharedNetwork, r.ConfigManager.GetKeaModule().ApplySharedNetworkUpdate); code != 0 {
 		// Error case.
 		rsp := dhcp.NewUpdateSharedNetworkSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -714,12 +713,10 @@ func (r *RestAPI) DeleteSharedNetwork(ctx context.Context, params dhcp.DeleteSha
 		})
 		return rsp
 	}
-	// Send the commands to Kea servers.
-	_, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with deleting a shared network: %s", err)
-		log.WithError(err).Error(msg)
-		rsp := dhcp.NewDeleteSharedNetworkDefault(http.StatusConflict).WithPayload(&models.APIError{
+	// Send the commands to Kea servers or submit them for approval.
+	_, code, msg := r.commitOrScheduleConfigChange(cctx, user, nil, "shared network deletion")
+	if code != 0 {
+		rsp := dhcp.NewDeleteSharedNetworkDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
 		})
 		return rsp
@@ -2551,6 +2551,85 @@ func TestDeleteSharedNetwork(t *testing.T) {
 	require.Nil(t, returnedSharedNetwork)
 }
 
+// Test that deleting a shared network is submitted for approval instead of
+// being committed when the config change approval is enabled.
+func TestDeleteSharedNetworkApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	serverConfig := `{
+		"Dhcp4": {
+			"shared-networks": [
+				{
+					"name": "foo",
+					"subnet4": [
+						{
+							"id": 1,
+							"subnet": "192.0.2.0/24"
+						}
+					]
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_subnet_cmds"
+				}
+			]
+		}
+	}`
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(serverConfig)
+	require.NoError(t, err)
+
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	err = kea.CommitDaemonsIntoDB(db,
+		[]*dbmodel.Daemon{daemon},
+		&storktest.FakeEventCenter{},
+		[]kea.DaemonStateMeta{{IsConfigChanged: true}},
+		dbmodel.NewDHCPOptionDefinitionLookup(),
+	)
+	require.NoError(t, err)
+
+	sharedNetworks, err := dbmodel.GetAllSharedNetworks(db, 4)
+	require.NoError(t, err)
+	require.Len(t, sharedNetworks, 1)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := daemons.NewManager(&daemonstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.DeleteSharedNetwork(ctx, dhcp.DeleteSharedNetworkParams{
+		ID: sharedNetworks[0].ID,
+	})
+	require.IsType(t, &dhcp.DeleteSharedNetworkOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaSharedNetworkDelete)
+
+	// The shared network should not be deleted until the request is approved.
+	returnedSharedNetwork, err := dbmodel.GetSharedNetwork(db, sharedNetworks[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returnedSharedNetwork)
+}
+
 // Test error cases for deleting a shared network.
 func TestDeleteSharedNetworkError(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -2657,6 +2736,6 @@ func TestDeleteSharedNetworkError(t *testing.T) {
 		require.IsType(t, &dhcp.DeleteSharedNetworkDefault{}, rsp)
 		defaultRsp := rsp.(*dhcp.DeleteSharedNetworkDefault)
 		require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
-		require.Equal(t, fmt.Sprintf("Problem with deleting a shared network: network4-del command to %s failed: non-success response result from Kea: 1, text: unable to communicate with the daemon", daemon1.Name), *defaultRsp.Payload.Message)
+		require.Equal(t, fmt.Sprintf("Problem with committing shared network deletion: network4-del command to %s failed: non-success response result from Kea: 1, text: unable to communicate with the daemon", daemon1.Name), *defaultRsp.Payload.Message)
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	keaconfig "isc.org/stork/daemoncfg/kea"
@@ -657,7 +658,7 @@ func (r *RestAPI) commonCreateOrUpdateNetworkBegin(ctx context.Context) ([]*mode
 // error occurs or 0 when there is no error. It also returns an ID of the
 // created or modified subnet. Finally, it returns an error string to be included
 // in the HTTP response or an empty string if there is no error.
-func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transactionID int64, restSubnet *models.Subnet, applyFunc func(context.Context, *dbmodel.Subnet) (context.Context, error)) (int, int64, string) {
+func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transactionID int64, deadline *strfmt.DateTime, restSubnet *models.Subnet, applyFunc func(context.Context, *dbmodel.Subnet) (context.Context, error)) (int, int64, string) {
 	// Make sure that the subnet information is present.
 	if restSubnet == nil {
 		msg := "Subnet information not specified"
@@ -698,12 +699,10 @@ func (r *RestAPI) commonCreateOrUpdateSubnetSubmit(ctx context.Context, transact
 		log.WithError(err).Error(msg)
 		return http.StatusInternalServerError, 0, msg
 	}
-	// Send the commands to Kea servers.
-	cctx, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with committing subnet information: %s", err)
-		log.WithError(err).Error(msg)
-		return http.StatusConflict, 0, msg
+	// Send the commands to Kea servers or schedule them.
+	cctx, code, msg := r.commitOrScheduleConfigChange(cctx, user, deadline, "subnet information")
+	if code != 0 {
+		return code, 0, msg
 	}
 	subnetID := restSubnet.ID
 	if subnetID == 0 {
@@ -801,7 +800,7 @@ func (r *RestAPI) CreateSubnetBegin(ctx context.Context, params dhcp.CreateSubne
 
 // Implements the POST call and commits a new subnet (subnets/new/transaction/{id}/submit).
 func (r *RestAPI) CreateSubnetSubmit(ctx context.Context, params dhcp.CreateSubnetSubmitParams) middleware.Responder {
-	code, subnetID, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetAdd)
+	code, subnetID, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Deadline, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetAdd)
 	if code != 0 {
 		// Error case.
 		rsp := dhcp.NewCreateSubnetSubmitDefault(code).WithPayload(&models.APIError{
@@ -919,7 +918,7 @@ func (r *RestAPI) UpdateSubnetBegin(ctx context.Context, params dhcp.UpdateSubne
 
 // Implements the POST call and commits an updated subnet (subnets/{subnetId}/transaction/{id}/submit).
 func (r *RestAPI) UpdateSubnetSubmit(ctx context.Context, params dhcp.UpdateSubnetSubmitParams) middleware.Responder {
-	if code, _, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetUpdate); code != 0 {
+	if code, _, msg := r.commonCreateOrUpdateSubnetSubmit(ctx, params.ID, params.Deadline, params.Subnet, r.ConfigManager.GetKeaModule().ApplySubnetUpdate); code != 0 {
 		// Error case.
 		rsp := dhcp.NewUpdateSubnetSubmitDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
@@ -990,12 +989,10 @@ func (r *RestAPI) DeleteSubnet(ctx context.Context, params dhcp.DeleteSubnetPara
 		})
 		return rsp
 	}
-	// Send the commands to Kea servers.
-	_, err = r.ConfigManager.Commit(cctx)
-	if err != nil {
-		msg := fmt.Sprintf("Problem with deleting a subnet: %s", err)
-		log.WithError(err).Error(msg)
-		rsp := dhcp.NewDeleteSubnetDefault(http.StatusConflict).WithPayload(&models.APIError{
+	// Send the commands to Kea servers or submit them for approval.
+	_, code, msg := r.commitOrScheduleConfigChange(cctx, user, nil, "subnet deletion")
+	if code != 0 {
+		rsp := dhcp.NewDeleteSubnetDefault(code).WithPayload(&models.APIError{
 			Message: &msg,
 		})
 		return rsp
@@ -4743,6 +4743,80 @@ func TestDeleteSubnet(t *testing.T) {
 	require.Nil(t, returnedSubnet)
 }
 
+// Test that deleting a subnet is submitted for approval instead of being
+// committed when the config change approval is enabled.
+func TestDeleteSubnetApproval(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.SetSettingBool(db, "enable_config_change_approval", true)
+	require.NoError(t, err)
+
+	serverConfig := `{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "libdhcp_subnet_cmds"
+				}
+			]
+		}
+	}`
+
+	server, err := dbmodeltest.NewKeaDHCPv4Server(db)
+	require.NoError(t, err)
+	err = server.Configure(serverConfig)
+	require.NoError(t, err)
+
+	daemon, err := server.GetDaemon()
+	require.NoError(t, err)
+
+	err = kea.CommitDaemonsIntoDB(db,
+		[]*dbmodel.Daemon{daemon},
+		&storktest.FakeEventCenter{},
+		[]kea.DaemonStateMeta{{IsConfigChanged: true}},
+		dbmodel.NewDHCPOptionDefinitionLookup(),
+	)
+	require.NoError(t, err)
+
+	subnets, err := dbmodel.GetSubnetsByPrefix(db, "192.0.2.0/24")
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	cm := daemons.NewManager(&daemonstest.ManagerAccessorsWrapper{
+		DB:        db,
+		Agents:    fa,
+		DefLookup: lookup,
+	})
+
+	rapi, err := NewRestAPI(dbSettings, db, fa, cm, lookup)
+	require.NoError(t, err)
+
+	ctx, err := rapi.SessionManager.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	loginDefaultAdmin(t, db, rapi, ctx)
+
+	rsp := rapi.DeleteSubnet(ctx, dhcp.DeleteSubnetParams{
+		ID: subnets[0].ID,
+	})
+	require.IsType(t, &dhcp.DeleteSubnetOK{}, rsp)
+
+	requireConfigChangeSubmitted(t, db, fa, dbmodel.ConfigOperationKeaSubnetDelete)
+
+	// The subnet should not be deleted until the request is approved.
+	returnedSubnet, err := dbmodel.GetSubnet(db, subnets[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, returnedSubnet)
+}
+
 // Test error cases for deleting a subnet.
 func TestDeleteSubnetError(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -4849,7 +4923,7 @@ func TestDeleteSubnetError(t *testing.T) {
 		require.IsType(t, &dhcp.DeleteSubnetDefault{}, rsp)
 		defaultRsp := rsp.(*dhcp.DeleteSubnetDefault)
 		require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
-		require.Equal(t, fmt.Sprintf("Problem with deleting a subnet: network4-subnet-del command to %s failed: non-success response result from Kea: 1, text: unable to communicate with the daemon", daemon.GetName()),
+		require.Equal(t, fmt.Sprintf("Problem with committing subnet deletion: network4-subnet-del command to %s failed: non-success response result from Kea: 1, text: unable to communicate with the daemon", daemon.GetName()),
 			*defaultRsp.Payload.Message)
 	})
 }
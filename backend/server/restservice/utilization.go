@@ -0,0 +1,142 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
+
+	"isc.org/stork/server/daemons/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/gen/models"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Converts the time pointer to the REST API format. It returns nil if the
+// time is not specified.
+func convertExhaustionTimeToRestAPI(t *time.Time) *strfmt.DateTime {
+	if t == nil {
+		return nil
+	}
+	dt := strfmt.DateTime(*t)
+	return &dt
+}
+
+// Get the utilization history of a subnet, shared network or pool.
+func (r *RestAPI) GetUtilizationHistory(ctx context.Context, params dhcp.GetUtilizationHistoryParams) middleware.Responder {
+	var start, end time.Time
+	if params.Start != nil {
+		start = time.Time(*params.Start)
+	}
+	if params.End != nil {
+		end = time.Time(*params.End)
+	}
+	samples, err := dbmodel.GetUtilizationSamples(r.DB, dbmodel.UtilizationObjectType(params.ObjectType),
+		[]int64{params.ObjectID}, start, end)
+	if err != nil {
+		msg := "Cannot get utilization history from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetUtilizationHistoryDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	history := &models.UtilizationHistory{
+		Items: []*models.UtilizationSample{},
+		Total: int64(len(samples)),
+	}
+	for _, sample := range samples {
+		history.Items = append(history.Items, &models.UtilizationSample{
+			SampledAt:       strfmt.DateTime(sample.SampledAt),
+			Resolution:      string(sample.Resolution),
+			AddrUtilization: float64(sample.AddrUtilization) * 100,
+			PdUtilization:   float64(sample.PdUtilization) * 100,
+		})
+	}
+	rsp := dhcp.NewGetUtilizationHistoryOK().WithPayload(history)
+	return rsp
+}
+
+// Get the subnets utilization forecasts. The forecasts are sorted by the
+// projected exhaustion time. The subnets whose utilization is not growing
+// are returned last.
+func (r *RestAPI) GetUtilizationForecasts(ctx context.Context, params dhcp.GetUtilizationForecastsParams) middleware.Responder {
+	var subnetIDs []int64
+	if params.SubnetID != nil {
+		subnetIDs = append(subnetIDs, *params.SubnetID)
+	}
+	now := storkutil.UTCNow()
+	forecasts, err := kea.GetSubnetUtilizationForecasts(r.DB, subnetIDs, kea.DefaultUtilizationForecastWindow, now)
+	if err != nil {
+		msg := "Cannot get utilization forecasts"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetUtilizationForecastsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	var ids []int64
+	for subnetID, forecast := range forecasts {
+		if params.Horizon != nil {
+			exhaustionAt := forecast.GetExhaustionAt()
+			if exhaustionAt == nil || exhaustionAt.After(now.Add(time.Duration(*params.Horizon)*24*time.Hour)) {
+				continue
+			}
+		}
+		ids = append(ids, subnetID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti, tj := forecasts[ids[i]].GetExhaustionAt(), forecasts[ids[j]].GetExhaustionAt()
+		switch {
+		case ti == nil && tj == nil:
+			return ids[i] < ids[j]
+		case ti == nil || tj == nil:
+			return tj == nil
+		case ti.Equal(*tj):
+			return ids[i] < ids[j]
+		default:
+			return ti.Before(*tj)
+		}
+	})
+	subnets, err := dbmodel.GetSubnetsByIDs(r.DB, ids)
+	if err != nil {
+		msg := "Cannot get subnets from the database"
+		log.WithError(err).Error(msg)
+		rsp := dhcp.NewGetUtilizationForecastsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	prefixes := make(map[int64]string)
+	for _, subnet := range subnets {
+		prefixes[subnet.ID] = subnet.Prefix
+	}
+	payload := &models.UtilizationForecasts{
+		Items: []*models.UtilizationForecast{},
+	}
+	for _, subnetID := range ids {
+		prefix, ok := prefixes[subnetID]
+		if !ok {
+			continue
+		}
+		forecast := forecasts[subnetID]
+		payload.Items = append(payload.Items, &models.UtilizationForecast{
+			SubnetID:         subnetID,
+			Subnet:           prefix,
+			AddrUtilization:  forecast.AddrUtilization * 100,
+			PdUtilization:    forecast.PdUtilization * 100,
+			AddrGrowthPerDay: forecast.AddrGrowthPerDay * 100,
+			PdGrowthPerDay:   forecast.PdGrowthPerDay * 100,
+			AddrExhaustionAt: convertExhaustionTimeToRestAPI(forecast.AddrExhaustionAt),
+			PdExhaustionAt:   convertExhaustionTimeToRestAPI(forecast.PdExhaustionAt),
+		})
+	}
+	payload.Total = int64(len(payload.Items))
+	rsp := dhcp.NewGetUtilizationForecastsOK().WithPayload(payload)
+	return rsp
+}
@@ -0,0 +1,143 @@
+package restservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	dhcp "isc.org/stork/server/gen/restapi/operations/d_h_c_p"
+	storkutil "isc.org/stork/util"
+)
+
+// Adds the hourly utilization samples of a subnet growing by the specified
+// value per day until now.
+func addTestUtilizationSamples(subnet *dbmodel.Subnet, add func(*dbmodel.UtilizationSample), initial, growthPerDay float64) {
+	now := storkutil.UTCNow().Truncate(time.Hour)
+	for i := 0; i < 24; i++ {
+		add(&dbmodel.UtilizationSample{
+			ObjectType:      dbmodel.UtilizationObjectSubnet,
+			ObjectID:        subnet.ID,
+			Resolution:      dbmodel.UtilizationResolutionHour,
+			SampledAt:       now.Add(time.Duration(i-23) * time.Hour),
+			AddrUtilization: dbmodel.Utilization(initial + growthPerDay*float64(i)/24),
+		})
+	}
+}
+
+// Test getting the utilization history of a subnet.
+func TestGetUtilizationHistory(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	subnet := &dbmodel.Subnet{
+		Prefix: "192.0.2.0/24",
+	}
+	err := dbmodel.AddSubnet(db, subnet)
+	require.NoError(t, err)
+
+	addTestUtilizationSamples(subnet, func(sample *dbmodel.UtilizationSample) {
+		_, err := db.Model(sample).Insert()
+		require.NoError(t, err)
+	}, 0.1, 0.1)
+
+	rapi, err := NewRestAPI(dbSettings, db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	rsp := rapi.GetUtilizationHistory(ctx, dhcp.GetUtilizationHistoryParams{
+		ObjectType: string(dbmodel.UtilizationObjectSubnet),
+		ObjectID:   subnet.ID,
+	})
+	require.IsType(t, &dhcp.GetUtilizationHistoryOK{}, rsp)
+	history := rsp.(*dhcp.GetUtilizationHistoryOK).Payload
+	require.EqualValues(t, 24, history.Total)
+	require.Len(t, history.Items, 24)
+	require.Equal(t, "hour", history.Items[0].Resolution)
+	require.InDelta(t, 10, history.Items[0].AddrUtilization, 0.1)
+	require.True(t, time.Time(history.Items[0].SampledAt).Before(time.Time(history.Items[23].SampledAt)))
+
+	// Limit the time range.
+	start := strfmt.DateTime(time.Time(history.Items[20].SampledAt))
+	rsp = rapi.GetUtilizationHistory(ctx, dhcp.GetUtilizationHistoryParams{
+		ObjectType: string(dbmodel.UtilizationObjectSubnet),
+		ObjectID:   subnet.ID,
+		Start:      &start,
+	})
+	require.IsType(t, &dhcp.GetUtilizationHistoryOK{}, rsp)
+	require.Len(t, rsp.(*dhcp.GetUtilizationHistoryOK).Payload.Items, 4)
+
+	// Other object.
+	rsp = rapi.GetUtilizationHistory(ctx, dhcp.GetUtilizationHistoryParams{
+		ObjectType: string(dbmodel.UtilizationObjectSharedNetwork),
+		ObjectID:   subnet.ID,
+	})
+	require.IsType(t, &dhcp.GetUtilizationHistoryOK{}, rsp)
+	require.Empty(t, rsp.(*dhcp.GetUtilizationHistoryOK).Payload.Items)
+}
+
+// Test getting the subnets utilization forecasts.
+func TestGetUtilizationForecasts(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	subnets := []dbmodel.Subnet{
+		{Prefix: "192.0.2.0/24"},
+		{Prefix: "192.0.3.0/24"},
+		{Prefix: "192.0.4.0/24"},
+	}
+	for i := range subnets {
+		err := dbmodel.AddSubnet(db, &subnets[i])
+		require.NoError(t, err)
+	}
+	insert := func(sample *dbmodel.UtilizationSample) {
+		_, err := db.Model(sample).Insert()
+		require.NoError(t, err)
+	}
+	// The first subnet runs out in about 30 days, the second one in about
+	// 5 days and the third one is not growing.
+	addTestUtilizationSamples(&subnets[0], insert, 0.7, 0.01)
+	addTestUtilizationSamples(&subnets[1], insert, 0.4, 0.1)
+	addTestUtilizationSamples(&subnets[2], insert, 0.5, 0)
+
+	rapi, err := NewRestAPI(dbSettings, db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	rsp := rapi.GetUtilizationForecasts(ctx, dhcp.GetUtilizationForecastsParams{})
+	require.IsType(t, &dhcp.GetUtilizationForecastsOK{}, rsp)
+	forecasts := rsp.(*dhcp.GetUtilizationForecastsOK).Payload
+	require.EqualValues(t, 3, forecasts.Total)
+	require.Len(t, forecasts.Items, 3)
+
+	// The forecasts should be sorted by the exhaustion time.
+	require.Equal(t, subnets[1].ID, forecasts.Items[0].SubnetID)
+	require.Equal(t, "192.0.3.0/24", forecasts.Items[0].Subnet)
+	require.InDelta(t, 10, forecasts.Items[0].AddrGrowthPerDay, 0.1)
+	require.NotNil(t, forecasts.Items[0].AddrExhaustionAt)
+	require.Nil(t, forecasts.Items[0].PdExhaustionAt)
+	require.Equal(t, subnets[0].ID, forecasts.Items[1].SubnetID)
+	require.Equal(t, subnets[2].ID, forecasts.Items[2].SubnetID)
+	require.Nil(t, forecasts.Items[2].AddrExhaustionAt)
+
+	// Only the subnets running out within a week.
+	rsp = rapi.GetUtilizationForecasts(ctx, dhcp.GetUtilizationForecastsParams{
+		Horizon: storkutil.Ptr(int64(7)),
+	})
+	require.IsType(t, &dhcp.GetUtilizationForecastsOK{}, rsp)
+	forecasts = rsp.(*dhcp.GetUtilizationForecastsOK).Payload
+	require.Len(t, forecasts.Items, 1)
+	require.Equal(t, subnets[1].ID, forecasts.Items[0].SubnetID)
+
+	// Selected subnet.
+	rsp = rapi.GetUtilizationForecasts(ctx, dhcp.GetUtilizationForecastsParams{
+		SubnetID: &subnets[2].ID,
+	})
+	require.IsType(t, &dhcp.GetUtilizationForecastsOK{}, rsp)
+	forecasts = rsp.(*dhcp.GetUtilizationForecastsOK).Payload
+	require.Len(t, forecasts.Items, 1)
+	require.Equal(t, subnets[2].ID, forecasts.Items[0].SubnetID)
+}
@@ -0,0 +1,187 @@
+package restservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/dnsop"
+	"isc.org/stork/server/gen/models"
+	"isc.org/stork/server/gen/restapi/operations/dns"
+	storkutil "isc.org/stork/util"
+)
+
+// Creates the zone statement from the zone specified over the REST API and
+// parses it with the BIND 9 configuration parser. The name is specified
+// separately because it is taken from the database when the zone is modified.
+func newZoneFromRestAPI(name string, restZone *models.ZoneProvisioning) (*bind9config.Zone, error) {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "zone %q", name)
+	if restZone.Class != "" {
+		fmt.Fprintf(&builder, " %s", restZone.Class)
+	}
+	builder.WriteString(" {")
+	if restZone.Type != nil {
+		fmt.Fprintf(&builder, " type %s;", *restZone.Type)
+	}
+	if restZone.File != "" {
+		fmt.Fprintf(&builder, " file %q;", restZone.File)
+	}
+	if len(restZone.Primaries) > 0 {
+		builder.WriteString(" primaries {")
+		for _, primary := range restZone.Primaries {
+			fmt.Fprintf(&builder, " %s;", primary)
+		}
+		builder.WriteString(" };")
+	}
+	if restZone.Options != "" {
+		fmt.Fprintf(&builder, " %s", restZone.Options)
+	}
+	builder.WriteString(" };")
+	return bind9config.NewParser().ParseZone(builder.String())
+}
+
+// Converts the zone provisioning results to the REST API format.
+func convertZoneProvisioningResultsToRestAPI(results []*dnsop.ZoneProvisioningResult) *models.ZoneProvisioningResults {
+	restResults := &models.ZoneProvisioningResults{
+		Items: []*models.ZoneProvisioningResult{},
+	}
+	for _, result := range results {
+		restResult := &models.ZoneProvisioningResult{
+			DaemonID: result.DaemonID,
+			Success:  result.Err == nil,
+		}
+		if result.Err != nil {
+			restResult.Error = result.Err.Error()
+		}
+		restResults.Items = append(restResults.Items, restResult)
+	}
+	return restResults
+}
+
+// Adds the zone to the BIND 9 servers using rndc addzone.
+func (r *RestAPI) CreateZone(ctx context.Context, params dns.CreateZoneParams) middleware.Responder {
+	if params.Zone == nil || params.Zone.Name == "" {
+		msg := "Zone name must be specified"
+		rsp := dns.NewCreateZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	zone, err := newZoneFromRestAPI(params.Zone.Name, params.Zone)
+	if err != nil {
+		msg := "Invalid zone configuration"
+		log.WithError(err).Error(msg)
+		rsp := dns.NewCreateZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	results, err := r.DNSManager.AddZone(ctx, params.Zone.DaemonIds, params.Zone.ViewName, zone, user)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to add zone %s", params.Zone.Name)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewCreateZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	rsp := dns.NewCreateZoneOK().WithPayload(convertZoneProvisioningResultsToRestAPI(results))
+	return rsp
+}
+
+// Modifies the zone on the BIND 9 servers using rndc modzone.
+func (r *RestAPI) UpdateZone(ctx context.Context, params dns.UpdateZoneParams) middleware.Responder {
+	dbZone, err := dbmodel.GetZoneByID(r.DB, params.ZoneID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching DNS zone with ID %d from db", params.ZoneID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbZone == nil {
+		msg := fmt.Sprintf("Cannot find DNS zone with ID %d", params.ZoneID)
+		rsp := dns.NewUpdateZoneDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if params.Zone == nil {
+		msg := "Zone configuration must be specified"
+		rsp := dns.NewUpdateZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	zone, err := newZoneFromRestAPI(dbZone.Name, params.Zone)
+	if err != nil {
+		msg := "Invalid zone configuration"
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	results, err := r.DNSManager.ModifyZone(ctx, params.Zone.DaemonIds, params.Zone.ViewName, zone, user)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to modify zone %s", dbZone.Name)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	rsp := dns.NewUpdateZoneOK().WithPayload(convertZoneProvisioningResultsToRestAPI(results))
+	return rsp
+}
+
+// Deletes the zone from the BIND 9 servers using rndc delzone.
+func (r *RestAPI) DeleteZone(ctx context.Context, params dns.DeleteZoneParams) middleware.Responder {
+	dbZone, err := dbmodel.GetZoneByID(r.DB, params.ZoneID, dbmodel.ZoneRelationLocalZones)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching DNS zone with ID %d from db", params.ZoneID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewDeleteZoneDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if dbZone == nil {
+		msg := fmt.Sprintf("Cannot find DNS zone with ID %d", params.ZoneID)
+		rsp := dns.NewDeleteZoneDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Use the class of the zone served by the first daemon. It is
+	// the same for all daemons serving the zone in the view.
+	var class string
+	for _, daemonID := range params.DaemonID {
+		if localZone := dbZone.GetLocalZone(daemonID, params.ViewName); localZone != nil {
+			class = localZone.Class
+			break
+		}
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	results, err := r.DNSManager.DeleteZone(ctx, params.DaemonID, params.ViewName, dbZone.Name, class, user)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to delete zone %s", dbZone.Name)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewDeleteZoneDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	rsp := dns.NewDeleteZoneOK().WithPayload(convertZoneProvisioningResultsToRestAPI(results))
+	return rsp
+}
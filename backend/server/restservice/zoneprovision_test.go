@@ -0,0 +1,232 @@
+package restservice
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	bind9config "isc.org/stork/daemoncfg/bind9"
+	"isc.org/stork/datamodel/daemonname"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/server/dnsop"
+	"isc.org/stork/server/gen/models"
+	dns "isc.org/stork/server/gen/restapi/operations/dns"
+	storkutil "isc.org/stork/util"
+)
+
+// Test that the zone specified over the REST API is converted to the
+// zone statement.
+func TestNewZoneFromRestAPI(t *testing.T) {
+	zone, err := newZoneFromRestAPI("example.com", &models.ZoneProvisioning{
+		Class:     "IN",
+		Type:      storkutil.Ptr("secondary"),
+		File:      "example.com.db",
+		Primaries: []string{"192.0.2.1", "192.0.2.2 port 5353 key xfr-key"},
+		Options:   "allow-transfer { key xfr-key; };",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "example.com", zone.Name)
+	require.Equal(t, "IN", zone.Class)
+	require.Equal(t, "secondary", zone.GetType())
+	require.NotNil(t, zone.GetAllowTransfer())
+
+	clauses, err := zone.GetFormattedClauses()
+	require.NoError(t, err)
+	require.Equal(t, `{ type secondary; file "example.com.db"; primaries { 192.0.2.1; 192.0.2.2 port 5353 key xfr-key; }; allow-transfer { key "xfr-key"; }; };`, clauses)
+}
+
+// Test that the options closing the zone statement are rejected.
+func TestNewZoneFromRestAPIInjection(t *testing.T) {
+	_, err := newZoneFromRestAPI("example.com", &models.ZoneProvisioning{
+		Type:    storkutil.Ptr("primary"),
+		File:    "example.com.db",
+		Options: `}; zone "example.org" { type primary; file "example.org.db";`,
+	})
+	require.ErrorContains(t, err, "expected exactly one zone statement")
+}
+
+// Test adding the zone over the REST API.
+func TestCreateZone(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().AddZone(gomock.Any(), []int64{1, 2}, "trusted", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*dnsop.ZoneProvisioningResult, error) {
+		require.Equal(t, "example.com", zone.Name)
+		require.Equal(t, "primary", zone.GetType())
+		return []*dnsop.ZoneProvisioningResult{
+			{DaemonID: 1},
+			{DaemonID: 2, Err: errors.New("allow-new-zones is not enabled")},
+		}, nil
+	})
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(t.Context(), "")
+	require.NoError(t, err)
+
+	params := dns.CreateZoneParams{
+		Zone: &models.ZoneProvisioning{
+			Name:      "example.com",
+			ViewName:  "trusted",
+			Type:      storkutil.Ptr("primary"),
+			File:      "example.com.db",
+			DaemonIds: []int64{1, 2},
+		},
+	}
+	rsp := rapi.CreateZone(ctx, params)
+	require.IsType(t, &dns.CreateZoneOK{}, rsp)
+	items := rsp.(*dns.CreateZoneOK).Payload.Items
+	require.Len(t, items, 2)
+	require.EqualValues(t, 1, items[0].DaemonID)
+	require.True(t, items[0].Success)
+	require.Empty(t, items[0].Error)
+	require.EqualValues(t, 2, items[1].DaemonID)
+	require.False(t, items[1].Success)
+	require.Equal(t, "allow-new-zones is not enabled", items[1].Error)
+}
+
+// Test that the invalid zone is rejected.
+func TestCreateZoneInvalid(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().AddZone(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("primary zone example.com must specify the file"))
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(t.Context(), "")
+	require.NoError(t, err)
+
+	t.Run("no name", func(t *testing.T) {
+		rsp := rapi.CreateZone(ctx, dns.CreateZoneParams{
+			Zone: &models.ZoneProvisioning{
+				Type:      storkutil.Ptr("primary"),
+				DaemonIds: []int64{1},
+			},
+		})
+		require.IsType(t, &dns.CreateZoneDefault{}, rsp)
+		defaultRsp := rsp.(*dns.CreateZoneDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Equal(t, "Zone name must be specified", *defaultRsp.Payload.Message)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		rsp := rapi.CreateZone(ctx, dns.CreateZoneParams{
+			Zone: &models.ZoneProvisioning{
+				Name:      "example.com",
+				Type:      storkutil.Ptr("primary"),
+				Options:   "allow-transfer {",
+				DaemonIds: []int64{1},
+			},
+		})
+		require.IsType(t, &dns.CreateZoneDefault{}, rsp)
+		defaultRsp := rsp.(*dns.CreateZoneDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Contains(t, *defaultRsp.Payload.Message, "Invalid zone configuration")
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		rsp := rapi.CreateZone(ctx, dns.CreateZoneParams{
+			Zone: &models.ZoneProvisioning{
+				Name:      "example.com",
+				Type:      storkutil.Ptr("primary"),
+				DaemonIds: []int64{1},
+			},
+		})
+		require.IsType(t, &dns.CreateZoneDefault{}, rsp)
+		defaultRsp := rsp.(*dns.CreateZoneDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Equal(t, "Failed to add zone example.com: primary zone example.com must specify the file", *defaultRsp.Payload.Message)
+	})
+}
+
+// Test modifying and deleting the zone stored in the database over the
+// REST API.
+func TestUpdateAndDeleteZone(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+	zone := &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{
+			{
+				DaemonID: daemon.ID,
+				View:     "trusted",
+				Class:    "CH",
+				Serial:   1,
+				Type:     "secondary",
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	}
+	err = dbmodel.AddZones(db, zone)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().ModifyZone(gomock.Any(), []int64{daemon.ID}, "trusted", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, daemonIDs []int64, viewName string, zone *bind9config.Zone, user *dbmodel.SystemUser) ([]*dnsop.ZoneProvisioningResult, error) {
+		// The name should be taken from the database.
+		require.Equal(t, "example.com", zone.Name)
+		return []*dnsop.ZoneProvisioningResult{{DaemonID: daemon.ID}}, nil
+	})
+	mockManager.EXPECT().DeleteZone(gomock.Any(), []int64{daemon.ID}, "trusted", "example.com", "CH", gomock.Any()).
+		Return([]*dnsop.ZoneProvisioningResult{{DaemonID: daemon.ID}}, nil)
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(t.Context(), "")
+	require.NoError(t, err)
+
+	rsp := rapi.UpdateZone(ctx, dns.UpdateZoneParams{
+		ZoneID: zone.ID,
+		Zone: &models.ZoneProvisioning{
+			Name:      "ignored.example.com",
+			ViewName:  "trusted",
+			Type:      storkutil.Ptr("secondary"),
+			Primaries: []string{"192.0.2.1"},
+			DaemonIds: []int64{daemon.ID},
+		},
+	})
+	require.IsType(t, &dns.UpdateZoneOK{}, rsp)
+	require.Len(t, rsp.(*dns.UpdateZoneOK).Payload.Items, 1)
+	require.True(t, rsp.(*dns.UpdateZoneOK).Payload.Items[0].Success)
+
+	rsp = rapi.DeleteZone(ctx, dns.DeleteZoneParams{
+		ZoneID:   zone.ID,
+		ViewName: "trusted",
+		DaemonID: []int64{daemon.ID},
+	})
+	require.IsType(t, &dns.DeleteZoneOK{}, rsp)
+	require.Len(t, rsp.(*dns.DeleteZoneOK).Payload.Items, 1)
+
+	// Non-existing zone.
+	rsp = rapi.DeleteZone(ctx, dns.DeleteZoneParams{
+		ZoneID:   zone.ID + 1,
+		ViewName: "trusted",
+		DaemonID: []int64{daemon.ID},
+	})
+	require.IsType(t, &dns.DeleteZoneDefault{}, rsp)
+	require.Equal(t, http.StatusNotFound, getStatusCode(*rsp.(*dns.DeleteZoneDefault)))
+}
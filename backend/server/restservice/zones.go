@@ -3,7 +3,10 @@ package restservice
 import (
 	"context"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
@@ -11,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"isc.org/stork/datamodel/daemonname"
+	dnsmodel "isc.org/stork/datamodel/dns"
 	"isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/dnsop"
@@ -40,10 +44,34 @@ func (r *RestAPI) GetZone(ctx context.Context, params dns.GetZoneParams) middlew
 		})
 		return rsp
 	}
+	fetchTimes, err := dbmodel.GetZoneInventoryFetchTimes(r.DB)
+	if err != nil {
+		msg := "Problem fetching zone inventory states from db"
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZoneDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
 	// Zone found. Convert it to the format used in REST API.
+	restZone := convertZoneToRestAPI(dbZone, fetchTimes)
+	rsp := dns.NewGetZoneOK().WithPayload(restZone)
+	return rsp
+}
+
+// Converts the zone to the format used in REST API. The serials of the
+// local zones served in the same view are compared to determine whether
+// the servers are in sync. The fetch times are the times when the zones
+// were last fetched from the daemons, indexed by the daemon IDs.
+func convertZoneToRestAPI(zone *dbmodel.Zone, fetchTimes map[int64]time.Time) *models.Zone {
+	syncs := zone.GetSyncStates(fetchTimes)
+	localZoneSyncs := make(map[*dbmodel.LocalZone]*dbmodel.LocalZoneSync)
+	for _, sync := range syncs {
+		localZoneSyncs[sync.LocalZone] = sync
+	}
 	var restLocalZones []*models.LocalZone
-	for _, localZone := range dbZone.LocalZones {
-		restLocalZones = append(restLocalZones, &models.LocalZone{
+	for _, localZone := range zone.LocalZones {
+		restLocalZone := &models.LocalZone{
 			ZoneClass:   localZone.Class,
 			DaemonID:    localZone.DaemonID,
 			DaemonLabel: localZone.Daemon.GetLabel(),
@@ -52,16 +80,21 @@ func (r *RestAPI) GetZone(ctx context.Context, params dns.GetZoneParams) middlew
 			Rpz:         localZone.RPZ,
 			View:        localZone.View,
 			ZoneType:    localZone.Type,
-		})
+		}
+		if sync, ok := localZoneSyncs[localZone]; ok {
+			restLocalZone.SyncState = string(sync.State)
+			restLocalZone.SerialLag = sync.SerialLag
+			restLocalZone.LagDuration = int64(sync.LagDuration / time.Second)
+		}
+		restLocalZones = append(restLocalZones, restLocalZone)
 	}
-	restZone := models.Zone{
-		ID:         dbZone.ID,
-		Name:       dbZone.Name,
-		Rname:      dbZone.Rname,
+	return &models.Zone{
+		ID:         zone.ID,
+		Name:       zone.Name,
+		Rname:      zone.Rname,
+		SyncState:  string(dbmodel.GetWorstZoneSyncState(syncs)),
 		LocalZones: restLocalZones,
 	}
-	rsp := dns.NewGetZoneOK().WithPayload(&restZone)
-	return rsp
 }
 
 // Returns a list DNS zones with paging.
@@ -127,28 +160,20 @@ func (r *RestAPI) GetZones(ctx context.Context, params dns.GetZonesParams) middl
 		return rsp
 	}
 
+	fetchTimes, err := dbmodel.GetZoneInventoryFetchTimes(r.DB)
+	if err != nil {
+		msg := "Failed to get zone inventory states from the database"
+		log.WithError(err).Error(msg)
+		rsp := dns.NewGetZonesDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
 	// Convert the zones to the REST API format.
 	var restZones []*models.Zone
 	for _, zone := range zones {
-		var restLocalZones []*models.LocalZone
-		for _, localZone := range zone.LocalZones {
-			restLocalZones = append(restLocalZones, &models.LocalZone{
-				ZoneClass:   localZone.Class,
-				DaemonID:    localZone.DaemonID,
-				DaemonLabel: localZone.Daemon.GetLabel(),
-				LoadedAt:    strfmt.DateTime(localZone.LoadedAt),
-				Serial:      localZone.Serial,
-				Rpz:         localZone.RPZ,
-				View:        localZone.View,
-				ZoneType:    localZone.Type,
-			})
-		}
-		restZones = append(restZones, &models.Zone{
-			ID:         zone.ID,
-			Name:       zone.Name,
-			Rname:      zone.Rname,
-			LocalZones: restLocalZones,
-		})
+		restZones = append(restZones, convertZoneToRestAPI(zone, fetchTimes))
 	}
 	// Return the zones.
 	payload := models.Zones{
@@ -391,3 +416,173 @@ func (r *RestAPI) PutZoneRRsCache(ctx context.Context, params dns.PutZoneRRsCach
 	rsp := dns.NewPutZoneRRsCacheOK().WithPayload(&payload)
 	return rsp
 }
+
+// Converts the RRs received over the REST API to the format used by the
+// DNS manager. The class defaults to IN if not specified.
+func convertRRsFromRestAPI(restRRs []*models.ZoneRR) ([]*dnsmodel.RR, error) {
+	var rrs []*dnsmodel.RR
+	for _, restRR := range restRRs {
+		if restRR == nil {
+			continue
+		}
+		rrClass := restRR.RrClass
+		if rrClass == "" {
+			rrClass = "IN"
+		}
+		rr, err := dnsmodel.NewRR(fmt.Sprintf("%s %d %s %s %s", restRR.Name, restRR.TTL, rrClass, restRR.RrType, restRR.Data))
+		if err != nil {
+			return nil, err
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+// Updates the RRs of the dynamic zone using the dynamic update (RFC 2136)
+// sent to the primary server.
+func (r *RestAPI) UpdateZoneRRs(ctx context.Context, params dns.UpdateZoneRRsParams) middleware.Responder {
+	if params.Rrs == nil {
+		msg := "No RRs to update specified"
+		log.Error(msg)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	update := &dnsop.ZoneRRsUpdate{}
+	var err error
+	if update.Delete, err = convertRRsFromRestAPI(params.Rrs.Delete); err == nil {
+		update.Add, err = convertRRsFromRestAPI(params.Rrs.Add)
+	}
+	if err != nil {
+		msg := "Failed to parse the RRs to update"
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	if len(update.Delete) == 0 && len(update.Add) == 0 {
+		msg := "No RRs to update specified"
+		log.Error(msg)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	_, user := r.SessionManager.Logged(ctx)
+	if err = r.DNSManager.UpdateZoneRRs(ctx, params.ZoneID, params.DaemonID, params.ViewName, update, user); err != nil {
+		msg := fmt.Sprintf("Failed to update RRs in zone with ID %d", params.ZoneID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewUpdateZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: storkutil.Ptr(errors.WithMessage(err, msg).Error()),
+		})
+		return rsp
+	}
+	return dns.NewUpdateZoneRRsOK()
+}
+
+// Exports the zone RRs as a master file (RFC 1035). The first chunk of
+// the RRs is received before the response is returned, so the errors
+// occurring before any RRs are sent (e.g., busy zone inventory) are
+// returned with an appropriate status code. The remaining RRs are
+// streamed to the client as they are transferred from the DNS server.
+func (r *RestAPI) ExportZoneRRs(ctx context.Context, params dns.ExportZoneRRsParams) middleware.Responder {
+	var (
+		alreadyRequestedError *dnsop.ManagerRRsAlreadyRequestedError
+		busyError             *agentcomm.ZoneInventoryBusyError
+		notInitedError        *agentcomm.ZoneInventoryNotInitedError
+		filter                *dbmodel.GetZoneRRsFilter
+	)
+	zone, err := dbmodel.GetZoneByID(r.DB, params.ZoneID)
+	if err != nil {
+		msg := fmt.Sprintf("Problem fetching DNS zone with ID %d from db", params.ZoneID)
+		log.WithError(err).Error(msg)
+		rsp := dns.NewExportZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	if zone == nil {
+		msg := fmt.Sprintf("Cannot find DNS zone with ID %d", params.ZoneID)
+		rsp := dns.NewExportZoneRRsDefault(http.StatusNotFound).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+	// Apply filtering if requested. The SOA RR is always included because
+	// it is mandatory in the master file.
+	if len(params.RrType) > 0 {
+		filter = dbmodel.NewGetZoneRRsFilterWithParams(nil, nil, params.RrType, nil)
+		filter.EnableType("SOA")
+	}
+	options := []dnsop.GetZoneRRsOption{dnsop.GetZoneRRsOptionExcludeTrailingSOA}
+	if params.Refresh != nil && *params.Refresh {
+		options = append(options, dnsop.GetZoneRRsOptionForceZoneTransfer)
+	}
+	next, stop := iter.Pull(r.DNSManager.GetZoneRRs(params.ZoneID, params.DaemonID, params.ViewName, filter, options...))
+	rrResponse, ok := next()
+	if ok && rrResponse.Err != nil {
+		stop()
+		msg := "Failed to export zone contents"
+		log.WithError(rrResponse.Err).Error(msg)
+		switch {
+		case errors.As(rrResponse.Err, &alreadyRequestedError):
+			// There is another request in progress for the same zone.
+			rsp := dns.NewExportZoneRRsDefault(http.StatusConflict).WithPayload(&models.APIError{
+				Message: storkutil.Ptr(errors.WithMessage(alreadyRequestedError, msg).Error()),
+			})
+			return rsp
+		case errors.As(rrResponse.Err, &busyError):
+			// The zone inventory is busy populating or sending zones to the server.
+			rsp := dns.NewExportZoneRRsDefault(http.StatusConflict).WithPayload(&models.APIError{
+				Message: storkutil.Ptr(errors.WithMessage(busyError, msg).Error()),
+			})
+			return rsp
+		case errors.As(rrResponse.Err, &notInitedError):
+			// The zone inventory is not initialized.
+			rsp := dns.NewExportZoneRRsDefault(http.StatusServiceUnavailable).WithPayload(&models.APIError{
+				Message: storkutil.Ptr(errors.WithMessage(notInitedError, msg).Error()),
+			})
+			return rsp
+		default:
+			// An unknown error occurred.
+			rsp := dns.NewExportZoneRRsDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: storkutil.Ptr(errors.WithMessage(rrResponse.Err, msg).Error()),
+			})
+			return rsp
+		}
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		defer stop()
+		zoneFile := dnsmodel.NewZoneFileWriter(writer, zone.Name)
+		for ; ok; rrResponse, ok = next() {
+			if rrResponse.Err != nil {
+				// The headers have been already sent, so the only way to
+				// signal the error is to abort the transfer.
+				log.WithError(rrResponse.Err).WithField("zone", zone.Name).Error("Failed to export zone contents")
+				_ = writer.CloseWithError(rrResponse.Err)
+				return
+			}
+			if err := zoneFile.WriteRRs(rrResponse.RRs); err != nil {
+				// The client has most likely closed the connection.
+				log.WithError(err).WithField("zone", zone.Name).Error("Failed to send zone contents")
+				_ = writer.CloseWithError(err)
+				return
+			}
+		}
+		_ = writer.Close()
+	}()
+
+	fileName := strings.TrimSuffix(zone.Name, ".")
+	if fileName == "" {
+		fileName = "root"
+	}
+	rsp := dns.NewExportZoneRRsOK().
+		WithContentType("text/dns").
+		WithContentDisposition(fmt.Sprintf("attachment; filename=\"%s.zone\"", fileName)).
+		WithPayload(reader)
+	return rsp
+}
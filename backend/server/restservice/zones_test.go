@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	iter "iter"
 	http "net/http"
 	"slices"
@@ -19,6 +20,7 @@ import (
 	dnsmodel "isc.org/stork/datamodel/dns"
 	"isc.org/stork/datamodel/protocoltype"
 	"isc.org/stork/server/agentcomm"
+	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
 	"isc.org/stork/server/dnsop"
@@ -458,6 +460,36 @@ func TestGetZone(t *testing.T) {
 	require.Equal(t, "Cannot find DNS zone with ID 456123", *defaultRsp.Payload.Message)
 }
 
+// Test that the sync state of the zone served by the primary and secondary
+// servers is returned in the REST API format.
+func TestConvertZoneToRestAPISyncState(t *testing.T) {
+	now := time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+	zone := &dbmodel.Zone{
+		ID:    1,
+		Name:  "example.com",
+		Rname: "com.example",
+		LocalZones: []*dbmodel.LocalZone{
+			{DaemonID: 1, Daemon: &dbmodel.Daemon{ID: 1, Name: daemonname.Bind9}, View: "_default", Class: "IN", Type: "primary", Serial: 12, LoadedAt: now.Add(-time.Hour)},
+			{DaemonID: 2, Daemon: &dbmodel.Daemon{ID: 2, Name: daemonname.Bind9}, View: "_default", Class: "IN", Type: "secondary", Serial: 10, LoadedAt: now.Add(-2 * time.Hour)},
+			{DaemonID: 3, Daemon: &dbmodel.Daemon{ID: 3, Name: daemonname.Bind9}, View: "guest", Class: "IN", Type: "primary", Serial: 1, LoadedAt: now},
+		},
+	}
+	restZone := convertZoneToRestAPI(zone, map[int64]time.Time{1: now, 2: now, 3: now})
+	require.EqualValues(t, 1, restZone.ID)
+	require.Equal(t, "lagging", restZone.SyncState)
+	require.Len(t, restZone.LocalZones, 3)
+
+	require.Equal(t, "in-sync", restZone.LocalZones[0].SyncState)
+	require.Zero(t, restZone.LocalZones[0].SerialLag)
+
+	require.Equal(t, "lagging", restZone.LocalZones[1].SyncState)
+	require.EqualValues(t, 2, restZone.LocalZones[1].SerialLag)
+	require.EqualValues(t, 3600, restZone.LocalZones[1].LagDuration)
+
+	// The zone in the other view is not compared with any other zone.
+	require.Empty(t, restZone.LocalZones[2].SyncState)
+}
+
 // Test that the HTTP InternalServerError status is returned when the
 // database query fails.
 func TestGetZonesError(t *testing.T) {
@@ -1033,3 +1065,297 @@ func TestGetZoneRRsUnknownError(t *testing.T) {
 		require.Contains(t, *defaultRsp.Payload.Message, "Failed to refresh zone contents using zone transfer")
 	})
 }
+
+// Test that the RRs are converted and passed to the DNS manager which sends
+// the dynamic update. The logged user should be passed to the manager.
+func TestUpdateZoneRRs(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().UpdateZoneRRs(gomock.Any(), int64(1), int64(2), "trusted", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, zoneID int64, daemonID int64, viewName string, update *dnsop.ZoneRRsUpdate, user *dbmodel.SystemUser) error {
+		require.Len(t, update.Delete, 1)
+		require.Equal(t, "www.example.com. 3600 IN A 192.0.2.1", update.Delete[0].GetString())
+		require.Len(t, update.Add, 1)
+		require.Equal(t, "www.example.com. 300 IN A 192.0.2.2", update.Add[0].GetString())
+		require.NotNil(t, user)
+		require.Equal(t, "john", user.Login)
+		return nil
+	})
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(t.Context(), "")
+	require.NoError(t, err)
+
+	user := &dbmodel.SystemUser{
+		Login:    "john",
+		Lastname: "White",
+		Name:     "John",
+	}
+	_, err = dbmodel.CreateUser(db, user)
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+
+	params := dns.UpdateZoneRRsParams{
+		ZoneID:   1,
+		DaemonID: 2,
+		ViewName: "trusted",
+		Rrs: &models.ZoneRRsUpdate{
+			Delete: []*models.ZoneRR{
+				{Name: "www.example.com.", TTL: 3600, RrClass: "IN", RrType: "A", Data: "192.0.2.1"},
+			},
+			Add: []*models.ZoneRR{
+				// The class should default to IN.
+				{Name: "www.example.com.", TTL: 300, RrType: "A", Data: "192.0.2.2"},
+			},
+		},
+	}
+	rsp := rapi.UpdateZoneRRs(ctx, params)
+	require.IsType(t, &dns.UpdateZoneRRsOK{}, rsp)
+}
+
+// Test that the invalid or empty update is rejected without calling the
+// DNS manager.
+func TestUpdateZoneRRsBadRequest(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("no RRs", func(t *testing.T) {
+		params := dns.UpdateZoneRRsParams{
+			ZoneID:   1,
+			DaemonID: 2,
+			ViewName: "trusted",
+			Rrs:      &models.ZoneRRsUpdate{},
+		}
+		rsp := rapi.UpdateZoneRRs(ctx, params)
+		require.IsType(t, &dns.UpdateZoneRRsDefault{}, rsp)
+		defaultRsp := rsp.(*dns.UpdateZoneRRsDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Equal(t, "No RRs to update specified", *defaultRsp.Payload.Message)
+	})
+
+	t.Run("invalid RR", func(t *testing.T) {
+		params := dns.UpdateZoneRRsParams{
+			ZoneID:   1,
+			DaemonID: 2,
+			ViewName: "trusted",
+			Rrs: &models.ZoneRRsUpdate{
+				Add: []*models.ZoneRR{
+					{Name: "www.example.com.", TTL: 300, RrType: "A", Data: "invalid"},
+				},
+			},
+		}
+		rsp := rapi.UpdateZoneRRs(ctx, params)
+		require.IsType(t, &dns.UpdateZoneRRsDefault{}, rsp)
+		defaultRsp := rsp.(*dns.UpdateZoneRRsDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Contains(t, *defaultRsp.Payload.Message, "Failed to parse the RRs to update")
+	})
+}
+
+// Test that an error returned by the DNS manager is returned to the caller.
+func TestUpdateZoneRRsError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().UpdateZoneRRs(gomock.Any(), int64(1), int64(2), "trusted", gomock.Any(), gomock.Any()).Return(&testError{})
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx, err := rapi.SessionManager.Load(t.Context(), "")
+	require.NoError(t, err)
+
+	params := dns.UpdateZoneRRsParams{
+		ZoneID:   1,
+		DaemonID: 2,
+		ViewName: "trusted",
+		Rrs: &models.ZoneRRsUpdate{
+			Delete: []*models.ZoneRR{
+				{Name: "www.example.com.", TTL: 3600, RrClass: "IN", RrType: "A", Data: "192.0.2.1"},
+			},
+		},
+	}
+	rsp := rapi.UpdateZoneRRs(ctx, params)
+	require.IsType(t, &dns.UpdateZoneRRsDefault{}, rsp)
+	defaultRsp := rsp.(*dns.UpdateZoneRRsDefault)
+	require.Equal(t, http.StatusInternalServerError, getStatusCode(*defaultRsp))
+	require.Equal(t, "Failed to update RRs in zone with ID 1: test error", *defaultRsp.Payload.Message)
+}
+
+// Adds a zone with a single local zone to the database for the export tests.
+func addTestExportZone(t *testing.T, db *dbops.PgDB) *dbmodel.Zone {
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewDaemon(machine, daemonname.Bind9, true, []*dbmodel.AccessPoint{})
+	err = dbmodel.AddDaemon(db, daemon)
+	require.NoError(t, err)
+
+	zone := &dbmodel.Zone{
+		Name: "example.com",
+		LocalZones: []*dbmodel.LocalZone{
+			{
+				DaemonID: daemon.ID,
+				View:     "_default",
+				Class:    "IN",
+				Serial:   2025071700,
+				Type:     "primary",
+				LoadedAt: time.Now().UTC(),
+			},
+		},
+	}
+	err = dbmodel.AddZones(db, zone)
+	require.NoError(t, err)
+	return zone
+}
+
+// Test that the zone is exported as a master file streamed from the
+// chunks of RRs returned by the DNS manager.
+func TestExportZoneRRs(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestExportZone(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	mockManager.EXPECT().GetZoneRRs(zone.ID, zone.LocalZones[0].DaemonID, "_default", gomock.Any(), dnsop.GetZoneRRsOptionExcludeTrailingSOA, dnsop.GetZoneRRsOptionForceZoneTransfer).DoAndReturn(func(zoneID int64, daemonID int64, viewName string, filter *dbmodel.GetZoneRRsFilter, options ...dnsop.GetZoneRRsOption) iter.Seq[*dnsop.RRResponse] {
+		// The SOA should be included when filtering by type.
+		require.NotNil(t, filter)
+		require.ElementsMatch(t, []string{"A", "SOA"}, filter.GetTypes())
+		require.Zero(t, filter.GetLimit())
+		return func(yield func(*dnsop.RRResponse) bool) {
+			for _, chunk := range [][]string{
+				{
+					"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 2025071700 1800 900 604800 86400",
+					"ns1.example.com. 300 IN A 192.0.2.1",
+				},
+				{
+					"www.example.com. 300 IN A 192.0.2.2",
+				},
+			} {
+				var rrs []*dnsmodel.RR
+				for _, rrText := range chunk {
+					rr, err := dnsmodel.NewRR(rrText)
+					require.NoError(t, err)
+					rrs = append(rrs, rr)
+				}
+				if !yield(dnsop.NewZoneTransferRRResponse(rrs)) {
+					return
+				}
+			}
+		}
+	})
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	params := dns.ExportZoneRRsParams{
+		ZoneID:   zone.ID,
+		DaemonID: zone.LocalZones[0].DaemonID,
+		ViewName: "_default",
+		RrType:   []string{"A"},
+		Refresh:  storkutil.Ptr(true),
+	}
+	rsp := rapi.ExportZoneRRs(ctx, params)
+	require.IsType(t, &dns.ExportZoneRRsOK{}, rsp)
+	rspOK := rsp.(*dns.ExportZoneRRsOK)
+	require.Equal(t, "text/dns", rspOK.ContentType)
+	require.Equal(t, `attachment; filename="example.com.zone"`, rspOK.ContentDisposition)
+
+	content, err := io.ReadAll(rspOK.Payload)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"$ORIGIN example.com.",
+		"$TTL 3600",
+		"@\t3600\tIN\tSOA\tns1.example.com. hostmaster.example.com. 2025071700 1800 900 604800 86400",
+		"ns1\t300\tIN\tA\t192.0.2.1",
+		"www\t300\tIN\tA\t192.0.2.2",
+		"",
+	}, "\n"), string(content))
+}
+
+// Test that the error returned by the DNS manager before any RRs are
+// received is returned with an appropriate status code, and the error
+// returned in the middle of the zone transfer aborts the download.
+func TestExportZoneRRsError(t *testing.T) {
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	zone := addTestExportZone(t, db)
+
+	ctrl := gomock.NewController(t)
+	mockManager := NewMockManager(ctrl)
+	gomock.InOrder(
+		mockManager.EXPECT().GetZoneRRs(gomock.Any(), gomock.Any(), gomock.Any(), nil, dnsop.GetZoneRRsOptionExcludeTrailingSOA).Return(func(yield func(*dnsop.RRResponse) bool) {
+			yield(dnsop.NewErrorRRResponse(agentcomm.NewZoneInventoryBusyError("foo")))
+		}),
+		mockManager.EXPECT().GetZoneRRs(gomock.Any(), gomock.Any(), gomock.Any(), nil, dnsop.GetZoneRRsOptionExcludeTrailingSOA).Return(func(yield func(*dnsop.RRResponse) bool) {
+			rr, err := dnsmodel.NewRR("example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 2025071700 1800 900 604800 86400")
+			require.NoError(t, err)
+			if !yield(dnsop.NewZoneTransferRRResponse([]*dnsmodel.RR{rr})) {
+				return
+			}
+			yield(dnsop.NewErrorRRResponse(&testError{}))
+		}),
+	)
+
+	settings := RestAPISettings{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, mockManager)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	params := dns.ExportZoneRRsParams{
+		ZoneID:   zone.ID,
+		DaemonID: zone.LocalZones[0].DaemonID,
+		ViewName: "_default",
+	}
+
+	t.Run("busy", func(t *testing.T) {
+		rsp := rapi.ExportZoneRRs(ctx, params)
+		require.IsType(t, &dns.ExportZoneRRsDefault{}, rsp)
+		defaultRsp := rsp.(*dns.ExportZoneRRsDefault)
+		require.Equal(t, http.StatusConflict, getStatusCode(*defaultRsp))
+		require.Contains(t, *defaultRsp.Payload.Message, "Failed to export zone contents")
+	})
+
+	t.Run("transfer error", func(t *testing.T) {
+		rsp := rapi.ExportZoneRRs(ctx, params)
+		require.IsType(t, &dns.ExportZoneRRsOK{}, rsp)
+		_, err := io.ReadAll(rsp.(*dns.ExportZoneRRsOK).Payload)
+		require.ErrorContains(t, err, "test error")
+	})
+
+	t.Run("zone not found", func(t *testing.T) {
+		params := dns.ExportZoneRRsParams{
+			ZoneID:   zone.ID + 1,
+			DaemonID: zone.LocalZones[0].DaemonID,
+			ViewName: "_default",
+		}
+		rsp := rapi.ExportZoneRRs(ctx, params)
+		require.IsType(t, &dns.ExportZoneRRsDefault{}, rsp)
+		defaultRsp := rsp.(*dns.ExportZoneRRsDefault)
+		require.Equal(t, http.StatusNotFound, getStatusCode(*defaultRsp))
+	})
+}
@@ -54,6 +54,10 @@ type StorkServer struct {
 	// Configuration manager instance. Note that it inherits some fields
 	// maintained by the server.
 	ConfigManager config.Manager
+	// Periodically commits the scheduled configuration changes.
+	ConfigChangeScheduler *daemons.ConfigChangeScheduler
+	// Periodically reverts the temporary Kea log level changes.
+	KeaLogLevelReverter *kea.LogLevelReverter
 	// Provides lookup functionality for DHCP option definitions.
 	DHCPOptionDefinitionLookup keaconfig.DHCPOptionDefinitionLookup
 	// Provides locking mechanism for daemon configurations.
@@ -155,6 +159,10 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	// Setup configuration review dispatcher.
 	ss.ReviewDispatcher = configreview.NewDispatcher(ss.DB)
 	configreview.RegisterDefaultCheckers(ss.ReviewDispatcher)
+	err = configreview.LoadRules(ss.DB, ss.ReviewDispatcher)
+	if err != nil {
+		return err
+	}
 	err = configreview.LoadAndValidateCheckerPreferences(ss.DB, ss.ReviewDispatcher)
 	if err != nil {
 		return err
@@ -203,7 +211,13 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	}
 
 	// setup kea stats puller
-	ss.Pullers.KeaStatsPuller, err = kea.NewStatsPuller(ss.DB, ss.Agents)
+	ss.Pullers.KeaStatsPuller, err = kea.NewStatsPuller(ss.DB, ss.Agents, ss.EventCenter)
+	if err != nil {
+		return err
+	}
+
+	// Setup Kea D2 stats puller.
+	ss.Pullers.KeaD2StatsPuller, err = kea.NewD2StatsPuller(ss.DB, ss.Agents)
 	if err != nil {
 		return err
 	}
@@ -226,6 +240,12 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 		return err
 	}
 
+	// Set up the puller comparing the zone serials.
+	ss.Pullers.ZoneSyncPuller, err = dnsop.NewZoneSyncPuller(ss.DB, ss.Agents, ss.DNSManager, ss.EventCenter)
+	if err != nil {
+		return err
+	}
+
 	if ss.GeneralSettings.EnableMetricsEndpoint {
 		ss.MetricsCollector, err = metrics.NewCollector(
 			metrics.NewDatabaseMetricsSource(ss.DB),
@@ -246,6 +266,18 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	// server startup.
 	ss.ConfigManager = daemons.NewManager(ss)
 
+	// Commit the scheduled configuration changes when their deadlines expire.
+	ss.ConfigChangeScheduler, err = daemons.NewConfigChangeScheduler(ss.ConfigManager)
+	if err != nil {
+		return err
+	}
+
+	// Revert the temporary Kea log level changes when they expire.
+	ss.KeaLogLevelReverter, err = kea.NewLogLevelReverter(ss.DB, ss.Agents, ss.EventCenter)
+	if err != nil {
+		return err
+	}
+
 	// Check if the machine registration endpoint should be disabled.
 	enableMachineRegistration, err := dbmodel.GetSettingBool(ss.DB, "enable_machine_registration")
 	if err != nil {
@@ -278,9 +310,13 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 		ss.Pullers.HAStatusPuller.Shutdown()
 		ss.Pullers.KeaHostsPuller.Shutdown()
 		ss.Pullers.KeaStatsPuller.Shutdown()
+		ss.Pullers.KeaD2StatsPuller.Shutdown()
 		ss.Pullers.Bind9StatsPuller.Shutdown()
 		ss.Pullers.StatePuller.Shutdown()
 		ss.Pullers.LeasesPuller.Shutdown()
+		ss.Pullers.ZoneSyncPuller.Shutdown()
+		ss.ConfigChangeScheduler.Shutdown()
+		ss.KeaLogLevelReverter.Shutdown()
 		ss.DNSManager.Shutdown()
 		if ss.MetricsCollector != nil {
 			ss.MetricsCollector.Shutdown()
@@ -327,9 +363,13 @@ func (ss *StorkServer) Shutdown(reload bool) {
 		ss.Pullers.HAStatusPuller.Shutdown()
 		ss.Pullers.KeaHostsPuller.Shutdown()
 		ss.Pullers.KeaStatsPuller.Shutdown()
+		ss.Pullers.KeaD2StatsPuller.Shutdown()
 		ss.Pullers.Bind9StatsPuller.Shutdown()
 		ss.Pullers.StatePuller.Shutdown()
 		ss.Pullers.LeasesPuller.Shutdown()
+		ss.Pullers.ZoneSyncPuller.Shutdown()
+		ss.ConfigChangeScheduler.Shutdown()
+		ss.KeaLogLevelReverter.Shutdown()
 		ss.DNSManager.Shutdown()
 		ss.Agents.Shutdown()
 		ss.EventCenter.Shutdown()
@@ -368,3 +408,8 @@ func (ss *StorkServer) GetDHCPOptionDefinitionLookup() keaconfig.DHCPOptionDefin
 func (ss *StorkServer) GetDaemonLocker() config.DaemonLocker {
 	return ss.DaemonLocker
 }
+
+// Returns an interface to the event center.
+func (ss *StorkServer) GetEventCenter() eventcenter.EventCenter {
+	return ss.EventCenter
+}
@@ -10,6 +10,8 @@ import { DurationPipe } from '../pipes/duration.pipe'
 import { AccessPointsComponent } from '../access-points/access-points.component'
 import { EventsPanelComponent } from '../events-panel/events-panel.component'
 import { Bind9DaemonControlsComponent } from '../bind9-daemon-controls/bind9-daemon-controls.component'
+import { HelpTipComponent } from '../help-tip/help-tip.component'
+import { ConfigReviewPanelComponent } from '../config-review-panel/config-review-panel.component'
 
 /**
  * Component for displaying information about a BIND9 daemon.
@@ -28,6 +30,8 @@ import { Bind9DaemonControlsComponent } from '../bind9-daemon-controls/bind9-dae
         AccessPointsComponent,
         EventsPanelComponent,
         Bind9DaemonControlsComponent,
+        HelpTipComponent,
+        ConfigReviewPanelComponent,
     ],
 })
 export class Bind9DaemonComponent {
@@ -46,6 +46,7 @@ const mockData: ConfigChecker[] = [
             'kea-dhcp-v6-daemon',
             'kea-dhcp-ddns-daemon',
             'bind9-daemon',
+            'pdns-daemon',
             'unknown',
         ],
         state: ConfigChecker.StateEnum.Disabled,
@@ -125,6 +125,8 @@ export class ConfigCheckerPreferencePickerComponent {
                 return 'fa fa-registered'
             case 'Stork agent config change':
                 return 'fa fa-hammer'
+            case 'config drift change':
+                return 'fa fa-code-branch'
             default:
                 return null
         }
@@ -171,6 +173,8 @@ export class ConfigCheckerPreferencePickerComponent {
                 return 'fa fa-dice-two'
             case 'bind9-daemon':
                 return 'fa fa-dot-circle'
+            case 'pdns-daemon':
+                return 'fa fa-circle-notch'
             default:
                 return null
         }
@@ -227,6 +231,14 @@ export class ConfigCheckerPreferencePickerComponent {
                     'via the HTTP ports exposed by the dedicated listeners ' +
                     'rather than via the Kea Control Agent.'
                 )
+            case 'ha_peer_consistency':
+                return (
+                    'This checker verifies that the High Availability peers ' +
+                    'have consistent configurations, i.e., the same subnets ' +
+                    'with the same IDs, pools and reservations, the same ' +
+                    'client classes and hook libraries, and matching peer ' +
+                    'lists in the HA hook configuration.'
+                )
             case 'address_pools_exhausted_by_reservations':
                 return 'This checker verifies that all available addresses in IP pools are not reserved for hosts.'
             case 'pd_pools_exhausted_by_reservations':
@@ -252,6 +264,61 @@ export class ConfigCheckerPreferencePickerComponent {
                     'unavailable or inaccurate due to a number overflow in ' +
                     'the statistics returned by the Kea DHCP daemon.'
                 )
+            case 'config_drift':
+                return (
+                    'This checker verifies whether the running configuration ' +
+                    'of the Kea daemon differs from its configuration file, ' +
+                    'e.g., because it was modified without writing it to disk.'
+                )
+            case 'bind9_open_recursion':
+                return (
+                    'This checker verifies whether the BIND 9 server allows ' +
+                    'recursive queries from any client.'
+                )
+            case 'bind9_allow_transfer_any':
+                return 'This checker verifies whether the BIND 9 server allows zone transfers to any client.'
+            case 'bind9_zone_transfer_without_tsig':
+                return (
+                    'This checker verifies whether the transfers of the primary ' +
+                    'zones are protected with TSIG keys rather than allowed ' +
+                    'based on the client addresses only.'
+                )
+            case 'bind9_statistics_channels_presence':
+                return (
+                    'This checker verifies that the BIND 9 configuration includes ' +
+                    'the statistics channel used by Stork to gather statistics.'
+                )
+            case 'bind9_rndc_controls_without_key':
+                return (
+                    'This checker verifies that the rndc control channels ' +
+                    'listening on non-loopback addresses require keys.'
+                )
+            case 'bind9_duplicate_zone_overlapping_views':
+                return (
+                    'This checker verifies whether the same zone is defined in ' +
+                    'multiple views with overlapping match-clients clauses.'
+                )
+            case 'pdns_api_key_missing':
+                return (
+                    'This checker verifies whether the API key is specified ' +
+                    'when the PowerDNS webserver and REST API are enabled.'
+                )
+            case 'pdns_webserver_public_without_allow_from':
+                return (
+                    'This checker verifies whether the PowerDNS webserver ' +
+                    'listening on a non-loopback address restricts the clients ' +
+                    'allowed to access it with the webserver-allow-from parameter.'
+                )
+            case 'pdns_axfr_open_to_remote':
+                return (
+                    'This checker verifies whether PowerDNS allows the zone ' +
+                    'transfers to the remote clients with the allow-axfr-ips parameter.'
+                )
+            case 'pdns_loglevel_too_low':
+                return (
+                    'This checker verifies whether the PowerDNS log level is ' +
+                    'high enough to log the zone transfers.'
+                )
             default:
                 return ''
         }
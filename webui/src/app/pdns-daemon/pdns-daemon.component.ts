@@ -4,12 +4,21 @@ import { PlaceholderPipe } from '../pipes/placeholder.pipe'
 import { DurationPipe } from '../pipes/duration.pipe'
 import { EventsPanelComponent } from '../events-panel/events-panel.component'
 import { AccessPointsComponent } from '../access-points/access-points.component'
+import { HelpTipComponent } from '../help-tip/help-tip.component'
+import { ConfigReviewPanelComponent } from '../config-review-panel/config-review-panel.component'
 
 @Component({
     selector: 'app-pdns-daemon',
     templateUrl: './pdns-daemon.component.html',
     styleUrl: './pdns-daemon.component.sass',
-    imports: [PlaceholderPipe, DurationPipe, EventsPanelComponent, AccessPointsComponent],
+    imports: [
+        PlaceholderPipe,
+        DurationPipe,
+        EventsPanelComponent,
+        AccessPointsComponent,
+        HelpTipComponent,
+        ConfigReviewPanelComponent,
+    ],
 })
 export class PdnsDaemonComponent {
     /**
@@ -59,6 +59,11 @@ describe('SettingsPageComponent', () => {
         expect(component.settingsForm.get('keaStatusPullerInterval')?.value).toBe(0)
         expect(component.settingsForm.get('enableMachineRegistration')?.value).toBeFalse()
         expect(component.settingsForm.get('enableOnlineSoftwareVersions')?.value).toBeFalse()
+        expect(component.settingsForm.get('enableConfigChangeApproval')?.value).toBeFalse()
+        expect(component.settingsForm.get('utilizationForecastHorizon')?.value).toBe(30)
+        expect(component.settingsForm.get('leaseHistoryRetention')?.value).toBe(90)
+        expect(component.settingsForm.get('zoneSerialLagThreshold')?.value).toBe(3600)
+        expect(component.settingsForm.get('enableZoneSyncRefresh')?.value).toBeFalse()
     })
 
     it('should have breadcrumbs', () => {
@@ -88,10 +93,17 @@ describe('SettingsPageComponent', () => {
             grafanaDhcp6DashboardId: 'dhcp6',
             keaHostsPullerInterval: 30,
             keaStatsPullerInterval: 31,
+            keaD2StatsPullerInterval: 34,
             keaStatusPullerInterval: 32,
             keaLeasesPullerInterval: 33,
+            zoneSyncPullerInterval: 35,
             enableMachineRegistration: true,
             enableOnlineSoftwareVersions: true,
+            enableConfigChangeApproval: true,
+            utilizationForecastHorizon: 14,
+            leaseHistoryRetention: 60,
+            zoneSerialLagThreshold: 1800,
+            enableZoneSyncRefresh: true,
         }
         spyOn(settingsApi, 'getSettings').and.returnValue(of(settings))
         component.ngOnInit()
@@ -107,10 +119,17 @@ describe('SettingsPageComponent', () => {
 
         expect(component.settingsForm.get('keaHostsPullerInterval')?.value).toBe(30)
         expect(component.settingsForm.get('keaStatsPullerInterval')?.value).toBe(31)
+        expect(component.settingsForm.get('keaD2StatsPullerInterval')?.value).toBe(34)
         expect(component.settingsForm.get('keaStatusPullerInterval')?.value).toBe(32)
         expect(component.settingsForm.get('keaLeasesPullerInterval')?.value).toBe(33)
+        expect(component.settingsForm.get('zoneSyncPullerInterval')?.value).toBe(35)
         expect(component.settingsForm.get('enableMachineRegistration')?.value).toBeTrue()
         expect(component.settingsForm.get('enableOnlineSoftwareVersions')?.value).toBeTrue()
+        expect(component.settingsForm.get('enableConfigChangeApproval')?.value).toBeTrue()
+        expect(component.settingsForm.get('utilizationForecastHorizon')?.value).toBe(14)
+        expect(component.settingsForm.get('leaseHistoryRetention')?.value).toBe(60)
+        expect(component.settingsForm.get('zoneSerialLagThreshold')?.value).toBe(1800)
+        expect(component.settingsForm.get('enableZoneSyncRefresh')?.value).toBeTrue()
     }))
 
     it('should display error message upon getting the settings', fakeAsync(() => {
@@ -145,10 +164,17 @@ describe('SettingsPageComponent', () => {
             grafanaDhcp6DashboardId: 'dhcp6',
             keaHostsPullerInterval: 30,
             keaStatsPullerInterval: 31,
+            keaD2StatsPullerInterval: 34,
             keaStatusPullerInterval: 32,
             keaLeasesPullerInterval: 33,
+            zoneSyncPullerInterval: 35,
             enableMachineRegistration: true,
             enableOnlineSoftwareVersions: true,
+            enableConfigChangeApproval: true,
+            utilizationForecastHorizon: 14,
+            leaseHistoryRetention: 60,
+            zoneSerialLagThreshold: 1800,
+            enableZoneSyncRefresh: true,
         }
         const updatedSettings: any = {
             statePullerInterval: 13,
@@ -158,10 +184,17 @@ describe('SettingsPageComponent', () => {
             grafanaDhcp6DashboardId: 'dhcp6',
             keaHostsPullerInterval: 13,
             keaStatsPullerInterval: 13,
+            keaD2StatsPullerInterval: 13,
             keaStatusPullerInterval: 13,
             keaLeasesPullerInterval: 13,
+            zoneSyncPullerInterval: 13,
             enableMachineRegistration: false,
             enableOnlineSoftwareVersions: false,
+            enableConfigChangeApproval: false,
+            utilizationForecastHorizon: 7,
+            leaseHistoryRetention: 30,
+            zoneSerialLagThreshold: 600,
+            enableZoneSyncRefresh: false,
         }
         spyOn(settingsApi, 'getSettings').and.returnValue(of(settings))
         spyOn(settingsApi, 'updateSettings').and.callThrough()
@@ -182,8 +215,10 @@ describe('SettingsPageComponent', () => {
             bind9StatsPullerInterval: null,
             keaHostsPullerInterval: null,
             keaStatsPullerInterval: null,
+            keaD2StatsPullerInterval: null,
             keaStatusPullerInterval: null,
             keaLeasesPullerInterval: null,
+            zoneSyncPullerInterval: null,
         }
         spyOn(settingsApi, 'getSettings').and.returnValue(of(settings))
         spyOn(settingsApi, 'updateSettings').and.callThrough()
@@ -26,13 +26,20 @@ interface SettingsForm {
     bind9StatsPullerInterval: FormControl<number>
     keaHostsPullerInterval: FormControl<number>
     keaStatsPullerInterval: FormControl<number>
+    keaD2StatsPullerInterval: FormControl<number>
     keaStatusPullerInterval: FormControl<number>
     keaLeasesPullerInterval: FormControl<number>
+    zoneSyncPullerInterval: FormControl<number>
     grafanaUrl: FormControl<string>
     grafanaDhcp4DashboardId: FormControl<string>
     grafanaDhcp6DashboardId: FormControl<string>
     enableMachineRegistration: FormControl<boolean>
     enableOnlineSoftwareVersions: FormControl<boolean>
+    enableConfigChangeApproval: FormControl<boolean>
+    utilizationForecastHorizon: FormControl<number>
+    leaseHistoryRetention: FormControl<number>
+    zoneSerialLagThreshold: FormControl<number>
+    enableZoneSyncRefresh: FormControl<boolean>
 }
 
 /**
@@ -120,6 +127,11 @@ export class SettingsPageComponent implements OnInit {
             formControlName: 'keaStatsPullerInterval',
             help: 'This puller refreshes statistics from the Kea servers',
         },
+        {
+            title: 'Kea DHCP-DDNS Statistics Puller Interval',
+            formControlName: 'keaD2StatsPullerInterval',
+            help: 'This puller refreshes statistics from the Kea DHCP-DDNS servers.',
+        },
         {
             title: 'Kea Status Puller Interval',
             formControlName: 'keaStatusPullerInterval',
@@ -130,6 +142,11 @@ export class SettingsPageComponent implements OnInit {
             formControlName: 'keaLeasesPullerInterval',
             help: 'This puller fetches leases from Kea servers.',
         },
+        {
+            title: 'Zone Sync Puller Interval',
+            formControlName: 'zoneSyncPullerInterval',
+            help: 'This puller compares the serials of the zones served by the DNS servers.',
+        },
     ]
 
     /**
@@ -184,13 +201,20 @@ export class SettingsPageComponent implements OnInit {
             bind9StatsPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaHostsPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaStatsPullerInterval: [0, [Validators.required, Validators.min(0)]],
+            keaD2StatsPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaStatusPullerInterval: [0, [Validators.required, Validators.min(0)]],
             keaLeasesPullerInterval: [0, [Validators.required, Validators.min(0)]],
+            zoneSyncPullerInterval: [0, [Validators.required, Validators.min(0)]],
             grafanaUrl: [''],
             grafanaDhcp4DashboardId: ['hRf18FvWz'],
             grafanaDhcp6DashboardId: ['AQPHKJUGz'],
             enableMachineRegistration: [false],
             enableOnlineSoftwareVersions: [false],
+            enableConfigChangeApproval: [false],
+            utilizationForecastHorizon: [30, [Validators.required, Validators.min(0)]],
+            leaseHistoryRetention: [90, [Validators.required, Validators.min(0)]],
+            zoneSerialLagThreshold: [3600, [Validators.required, Validators.min(0)]],
+            enableZoneSyncRefresh: [false],
         })
     }
 